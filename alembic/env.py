@@ -8,7 +8,7 @@ from sqlalchemy.ext.asyncio import async_engine_from_config
 from alembic import context
 
 # Import database configuration
-from src.feed.db.config import settings
+from common.db.config import settings
 
 # this is the Alembic Config object, which provides
 # access to the values within the .ini file in use.
@@ -16,6 +16,7 @@ revision: str = ${repr(up_revision)}
 down_revision: Union[str, Sequence[str], None] = ${repr(down_revision)}
 branch_labels: Union[str, Sequence[str], None] = ${repr(branch_labels)}
 depends_on: Union[str, Sequence[str], None] = ${repr(depends_on)}
+# Set phase = "contract" if the previous version can't run after this (src/common/migrations.py)
 
 
 def upgrade() -> None:
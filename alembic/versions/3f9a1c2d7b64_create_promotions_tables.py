@@ -0,0 +1,81 @@
+"""create_promotions_tables
+
+Revision ID: 3f9a1c2d7b64
+Revises: e253394912ce
+Create Date: 2026-01-26 11:48:09.204117
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "3f9a1c2d7b64"
+down_revision: Union[str, Sequence[str], None] = "e253394912ce"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    op.create_table(
+        "promotions",
+        sa.Column("id", sa.BigInteger, primary_key=True, autoincrement=True),
+        sa.Column(
+            "post_link",
+            sa.String(2048),
+            sa.ForeignKey("rss_posts.link", ondelete="CASCADE"),
+            nullable=False,
+        ),
+        sa.Column("sponsor", sa.String(255), nullable=False),
+        sa.Column("starts_at", sa.DateTime, nullable=False),
+        sa.Column("ends_at", sa.DateTime, nullable=False),
+        # 'top' or 'bottom' of the digest
+        sa.Column("placement", sa.String(20), nullable=False, server_default="top"),
+        # 'publisher:destination' to restrict the promotion to one output, NULL for all
+        sa.Column("target", sa.String(255), nullable=True),
+        sa.Column("max_placements", sa.Integer, nullable=True),
+        sa.Column("price_per_placement", sa.Numeric(12, 2), nullable=False, server_default="0"),
+        sa.Column("currency", sa.String(3), nullable=False, server_default="RUB"),
+        sa.Column(
+            "created_at", sa.DateTime, nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+        sa.Column(
+            "updated_at", sa.DateTime, nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+        sa.CheckConstraint("ends_at > starts_at", name="ck_promotions_dates"),
+        sa.CheckConstraint("placement IN ('top', 'bottom')", name="ck_promotions_placement"),
+    )
+    op.create_index("idx_promotions_period", "promotions", ["starts_at", "ends_at"])
+
+    # One row per digest a promotion appeared in; the basis for billing
+    op.create_table(
+        "promotion_placements",
+        sa.Column("id", sa.BigInteger, primary_key=True, autoincrement=True),
+        sa.Column(
+            "promotion_id",
+            sa.BigInteger,
+            sa.ForeignKey("promotions.id", ondelete="CASCADE"),
+            nullable=False,
+        ),
+        sa.Column("target", sa.String(255), nullable=False),
+        sa.Column(
+            "placed_at", sa.DateTime, nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+    )
+    op.create_index(
+        "idx_promotion_placements_promotion_id", "promotion_placements", ["promotion_id"]
+    )
+    op.create_index("idx_promotion_placements_placed_at", "promotion_placements", ["placed_at"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_promotion_placements_placed_at", table_name="promotion_placements")
+    op.drop_index("idx_promotion_placements_promotion_id", table_name="promotion_placements")
+    op.drop_table("promotion_placements")
+    op.drop_index("idx_promotions_period", table_name="promotions")
+    op.drop_table("promotions")
@@ -0,0 +1,30 @@
+"""add_title_to_rss_posts
+
+Revision ID: 5e8a2f4c9d13
+Revises: c71d3e9a5b20
+Create Date: 2026-01-29 16:42:51.107338
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "5e8a2f4c9d13"
+down_revision: Union[str, Sequence[str], None] = "c71d3e9a5b20"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Generated display title; the original content is left untouched
+    op.add_column("rss_posts", sa.Column("title", sa.String(200), nullable=True))
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_column("rss_posts", "title")
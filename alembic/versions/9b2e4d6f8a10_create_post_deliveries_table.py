@@ -0,0 +1,45 @@
+"""create_post_deliveries_table
+
+Revision ID: 9b2e4d6f8a10
+Revises: 3f9a1c2d7b64
+Create Date: 2026-01-28 09:31:52.660481
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "9b2e4d6f8a10"
+down_revision: Union[str, Sequence[str], None] = "3f9a1c2d7b64"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Individual posts delivered to per-post targets (e.g. a Mastodon account)
+    op.create_table(
+        "post_deliveries",
+        sa.Column(
+            "link",
+            sa.String(2048),
+            sa.ForeignKey("rss_posts.link", ondelete="CASCADE"),
+            primary_key=True,
+        ),
+        sa.Column("target", sa.String(255), primary_key=True),
+        sa.Column("external_id", sa.String(255), nullable=True),
+        sa.Column(
+            "delivered_at", sa.DateTime, nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+    )
+    op.create_index("idx_post_deliveries_target", "post_deliveries", ["target"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_post_deliveries_target", table_name="post_deliveries")
+    op.drop_table("post_deliveries")
@@ -0,0 +1,37 @@
+"""add_admission_flags_to_rss_posts
+
+Revision ID: a3d6e9f2c481
+Revises: f1c9d2a7e358
+Create Date: 2026-01-31 11:02:45.317820
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "a3d6e9f2c481"
+down_revision: Union[str, Sequence[str], None] = "f1c9d2a7e358"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+COLUMNS = ("registration_required", "tickets_required", "limited_capacity")
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Admission requirements detected in the post text
+    for name in COLUMNS:
+        op.add_column(
+            "rss_posts",
+            sa.Column(name, sa.Boolean, nullable=False, server_default=sa.text("false")),
+        )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    for name in reversed(COLUMNS):
+        op.drop_column("rss_posts", name)
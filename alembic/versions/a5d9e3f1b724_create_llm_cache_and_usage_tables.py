@@ -0,0 +1,48 @@
+"""create_llm_cache_and_usage_tables
+
+Revision ID: a5d9e3f1b724
+Revises: d2f7b4a9c806
+Create Date: 2026-02-18 09:41:27.530162
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "a5d9e3f1b724"
+down_revision: Union[str, Sequence[str], None] = "d2f7b4a9c806"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # LLM results keyed by a hash of the post text and what was asked of it,
+    # so reposted and unchanged texts are sent to the model once
+    op.create_table(
+        "llm_cache",
+        sa.Column("content_hash", sa.String(64), primary_key=True),
+        sa.Column("purpose", sa.String(50), primary_key=True),
+        sa.Column("text", sa.Text, nullable=False),
+        sa.Column("model", sa.String(100), nullable=False),
+        sa.Column(
+            "created_at", sa.DateTime, nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+    )
+    # Tokens spent per tenant (channel or external source) and day, for the daily budgets
+    op.create_table(
+        "llm_usage",
+        sa.Column("tenant", sa.String(255), primary_key=True),
+        sa.Column("day", sa.Date, primary_key=True),
+        sa.Column("tokens", sa.Integer, nullable=False, server_default="0"),
+    )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_table("llm_usage")
+    op.drop_table("llm_cache")
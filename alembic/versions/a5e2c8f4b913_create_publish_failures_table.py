@@ -0,0 +1,58 @@
+"""create_publish_failures_table
+
+Revision ID: a5e2c8f4b913
+Revises: d8b3f6e2a417
+Create Date: 2026-02-07 15:12:48.530127
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "a5e2c8f4b913"
+down_revision: Union[str, Sequence[str], None] = "d8b3f6e2a417"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Messages a publisher failed to send, kept for retries
+    op.create_table(
+        "publish_failures",
+        sa.Column("id", sa.Integer(), primary_key=True),
+        sa.Column("publisher", sa.String(50), nullable=False),
+        sa.Column("destination", sa.String(500), nullable=False),
+        sa.Column("message", sa.Text(), nullable=False),
+        # Posts marked published once the message goes out, and promotions billed
+        sa.Column("links", sa.dialects.postgresql.ARRAY(sa.Text()), nullable=False),
+        sa.Column(
+            "promotion_ids",
+            sa.dialects.postgresql.ARRAY(sa.Integer()),
+            nullable=False,
+            server_default="{}",
+        ),
+        sa.Column("error", sa.Text(), nullable=True),
+        sa.Column("attempts", sa.Integer(), nullable=False, server_default="1"),
+        sa.Column("status", sa.String(20), nullable=False, server_default="pending"),
+        sa.Column("next_attempt_at", sa.DateTime(), nullable=True),
+        sa.Column(
+            "created_at", sa.DateTime(), nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+        sa.Column(
+            "updated_at", sa.DateTime(), nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+    )
+    op.create_index(
+        "idx_publish_failures_status", "publish_failures", ["status", "next_attempt_at"]
+    )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_publish_failures_status", table_name="publish_failures")
+    op.drop_table("publish_failures")
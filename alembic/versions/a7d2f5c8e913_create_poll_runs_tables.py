@@ -0,0 +1,60 @@
+"""create_poll_runs_tables
+
+Revision ID: a7d2f5c8e913
+Revises: f3c8e2b6d419
+Create Date: 2026-02-03 11:24:09.518347
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "a7d2f5c8e913"
+down_revision: Union[str, Sequence[str], None] = "f3c8e2b6d419"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    op.create_table(
+        "poll_runs",
+        sa.Column("id", sa.Integer(), primary_key=True),
+        sa.Column(
+            "started_at", sa.DateTime(), nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+        sa.Column("finished_at", sa.DateTime(), nullable=True),
+    )
+    op.create_index("idx_poll_runs_started_at", "poll_runs", ["started_at"])
+
+    # One row per feed item seen by a run; links aren't tied to rss_posts because
+    # filtered and failed items are never stored as posts
+    op.create_table(
+        "poll_run_items",
+        sa.Column("id", sa.Integer(), primary_key=True),
+        sa.Column(
+            "run_id",
+            sa.Integer(),
+            sa.ForeignKey("poll_runs.id", ondelete="CASCADE"),
+            nullable=False,
+        ),
+        sa.Column("source", sa.String(255), nullable=False),
+        sa.Column("link", sa.String(2048), nullable=False),
+        sa.Column("outcome", sa.String(20), nullable=False),
+        sa.Column("reason", sa.Text(), nullable=True),
+    )
+    op.create_index("idx_poll_run_items_run_id", "poll_run_items", ["run_id"])
+    op.create_index("idx_poll_run_items_link", "poll_run_items", ["link"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_poll_run_items_link", table_name="poll_run_items")
+    op.drop_index("idx_poll_run_items_run_id", table_name="poll_run_items")
+    op.drop_table("poll_run_items")
+    op.drop_index("idx_poll_runs_started_at", table_name="poll_runs")
+    op.drop_table("poll_runs")
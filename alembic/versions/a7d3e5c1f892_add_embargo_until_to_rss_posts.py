@@ -0,0 +1,34 @@
+"""add_embargo_until_to_rss_posts
+
+Revision ID: a7d3e5c1f892
+Revises: f4c1e8a2b657
+Create Date: 2026-02-24 10:12:37.518204
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+from common.migrations import add_column, create_index
+
+
+# revision identifiers, used by Alembic.
+revision: str = "a7d3e5c1f892"
+down_revision: Union[str, Sequence[str], None] = "f4c1e8a2b657"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Release time of a post held under an embargo, to publish it once that passed
+    add_column("rss_posts", sa.Column("embargo_until", sa.DateTime(), nullable=True))
+    create_index("idx_rss_posts_embargo_until", "rss_posts", ["embargo_until"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_rss_posts_embargo_until", table_name="rss_posts")
+    op.drop_column("rss_posts", "embargo_until")
@@ -0,0 +1,33 @@
+"""add_categories_to_rss_posts
+
+Revision ID: a7d3e9c1f528
+Revises: f6c3a8d2b947
+Create Date: 2026-02-13 09:52:27.184630
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "a7d3e9c1f528"
+down_revision: Union[str, Sequence[str], None] = "f6c3a8d2b947"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Categories and tags the feed gave the item; NULL when it gave none
+    op.add_column(
+        "rss_posts",
+        sa.Column("categories", sa.dialects.postgresql.ARRAY(sa.Text()), nullable=True),
+    )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_column("rss_posts", "categories")
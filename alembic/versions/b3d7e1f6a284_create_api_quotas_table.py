@@ -0,0 +1,39 @@
+"""create_api_quotas_table
+
+Revision ID: b3d7e1f6a284
+Revises: a5e2c8f4b913
+Create Date: 2026-02-08 11:27:05.418362
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "b3d7e1f6a284"
+down_revision: Union[str, Sequence[str], None] = "a5e2c8f4b913"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Latest rate limit numbers of external APIs, saved after each pipeline run
+    op.create_table(
+        "api_quotas",
+        sa.Column("api", sa.String(50), primary_key=True),
+        # requests, tokens, ...
+        sa.Column("name", sa.String(50), primary_key=True),
+        sa.Column("quota_limit", sa.BigInteger(), nullable=True),
+        sa.Column("remaining", sa.BigInteger(), nullable=True),
+        sa.Column("reset_at", sa.DateTime(), nullable=True),
+        sa.Column("updated_at", sa.DateTime(), nullable=False),
+    )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_table("api_quotas")
@@ -0,0 +1,32 @@
+"""add_source_guid_to_rss_posts
+
+Revision ID: b3f8d1a6e724
+Revises: a7d3e9c1f528
+Create Date: 2026-02-14 11:07:39.518362
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "b3f8d1a6e724"
+down_revision: Union[str, Sequence[str], None] = "a7d3e9c1f528"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # "source:guid" of the feed item; NULL for posts saved before it was recorded
+    op.add_column("rss_posts", sa.Column("source_guid", sa.Text(), nullable=True))
+    op.create_index("idx_rss_posts_source_guid", "rss_posts", ["source_guid"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_rss_posts_source_guid", table_name="rss_posts")
+    op.drop_column("rss_posts", "source_guid")
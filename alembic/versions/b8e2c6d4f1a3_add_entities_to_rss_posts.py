@@ -0,0 +1,38 @@
+"""add_entities_to_rss_posts
+
+Revision ID: b8e2c6d4f1a3
+Revises: a5d9e3f1b724
+Create Date: 2026-02-19 10:12:43.618205
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "b8e2c6d4f1a3"
+down_revision: Union[str, Sequence[str], None] = "a5d9e3f1b724"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Speakers, performers and organizations named in the text; NULL when none were found
+    op.add_column(
+        "rss_posts",
+        sa.Column("performers", sa.dialects.postgresql.ARRAY(sa.Text()), nullable=True),
+    )
+    op.add_column(
+        "rss_posts",
+        sa.Column("organizations", sa.dialects.postgresql.ARRAY(sa.Text()), nullable=True),
+    )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_column("rss_posts", "organizations")
+    op.drop_column("rss_posts", "performers")
@@ -0,0 +1,32 @@
+"""add_event_format_to_rss_posts
+
+Revision ID: b8f3a5d1e729
+Revises: a3d6e9f2c481
+Create Date: 2026-01-31 17:48:09.526371
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "b8f3a5d1e729"
+down_revision: Union[str, Sequence[str], None] = "a3d6e9f2c481"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # online, offline or hybrid; NULL when the post gives no hint
+    op.add_column("rss_posts", sa.Column("event_format", sa.String(10), nullable=True))
+    op.create_index("idx_rss_posts_event_format", "rss_posts", ["event_format"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_rss_posts_event_format", table_name="rss_posts")
+    op.drop_column("rss_posts", "event_format")
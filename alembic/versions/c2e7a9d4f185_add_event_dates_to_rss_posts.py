@@ -0,0 +1,37 @@
+"""add_event_dates_to_rss_posts
+
+Revision ID: c2e7a9d4f185
+Revises: b8f3a5d1e729
+Create Date: 2026-02-01 12:34:51.208437
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "c2e7a9d4f185"
+down_revision: Union[str, Sequence[str], None] = "b8f3a5d1e729"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # First and last day mentioned in the post; NULL when the post gives no date
+    op.add_column("rss_posts", sa.Column("event_start", sa.Date(), nullable=True))
+    op.add_column("rss_posts", sa.Column("event_end", sa.Date(), nullable=True))
+    # JSON list of {"date": "2026-03-05", "time": "19:00" or null}, one per day
+    op.add_column("rss_posts", sa.Column("event_sessions", sa.Text(), nullable=True))
+    op.create_index("idx_rss_posts_event_start", "rss_posts", ["event_start"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_rss_posts_event_start", table_name="rss_posts")
+    op.drop_column("rss_posts", "event_sessions")
+    op.drop_column("rss_posts", "event_end")
+    op.drop_column("rss_posts", "event_start")
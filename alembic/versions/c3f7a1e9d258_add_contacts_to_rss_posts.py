@@ -0,0 +1,33 @@
+"""add_contacts_to_rss_posts
+
+Revision ID: c3f7a1e9d258
+Revises: b8e2c6d4f1a3
+Create Date: 2026-02-19 15:37:08.214963
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "c3f7a1e9d258"
+down_revision: Union[str, Sequence[str], None] = "b8e2c6d4f1a3"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Phone numbers (E.164) and emails found in the text; NULL when there are none
+    op.add_column(
+        "rss_posts",
+        sa.Column("contacts", sa.dialects.postgresql.ARRAY(sa.Text()), nullable=True),
+    )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_column("rss_posts", "contacts")
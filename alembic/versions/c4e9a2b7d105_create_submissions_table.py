@@ -0,0 +1,48 @@
+"""create_submissions_table
+
+Revision ID: c4e9a2b7d105
+Revises: a7d2f5c8e913
+Create Date: 2026-02-05 16:02:41.730119
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "c4e9a2b7d105"
+down_revision: Union[str, Sequence[str], None] = "a7d2f5c8e913"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Events submitted by organizers; a post is created only when a moderator approves
+    op.create_table(
+        "submissions",
+        sa.Column("id", sa.Integer(), primary_key=True),
+        sa.Column("link", sa.String(2048), nullable=False),
+        sa.Column("content", sa.Text(), nullable=False),
+        sa.Column("title", sa.String(500), nullable=True),
+        sa.Column("contact", sa.String(500), nullable=True),
+        sa.Column("partner", sa.String(255), nullable=True),
+        sa.Column("status", sa.String(20), nullable=False, server_default="pending"),
+        sa.Column("note", sa.Text(), nullable=True),
+        sa.Column(
+            "created_at", sa.DateTime(), nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+        sa.Column("reviewed_at", sa.DateTime(), nullable=True),
+    )
+    op.create_index("idx_submissions_status", "submissions", ["status"])
+    op.create_index("idx_submissions_link", "submissions", ["link"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_submissions_link", table_name="submissions")
+    op.drop_index("idx_submissions_status", table_name="submissions")
+    op.drop_table("submissions")
@@ -0,0 +1,30 @@
+"""add_summary_to_rss_posts
+
+Revision ID: c71d3e9a5b20
+Revises: 9b2e4d6f8a10
+Create Date: 2026-01-29 11:18:04.392715
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "c71d3e9a5b20"
+down_revision: Union[str, Sequence[str], None] = "9b2e4d6f8a10"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # One-sentence summary generated by the Summarizer agent
+    op.add_column("rss_posts", sa.Column("summary", sa.Text, nullable=True))
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_column("rss_posts", "summary")
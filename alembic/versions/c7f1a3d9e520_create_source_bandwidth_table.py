@@ -0,0 +1,38 @@
+"""create_source_bandwidth_table
+
+Revision ID: c7f1a3d9e520
+Revises: b3d7e1f6a284
+Create Date: 2026-02-09 10:14:52.306817
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "c7f1a3d9e520"
+down_revision: Union[str, Sequence[str], None] = "b3d7e1f6a284"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Bytes the RSS reader downloaded per source and day
+    op.create_table(
+        "source_bandwidth",
+        sa.Column("source", sa.String(255), primary_key=True),
+        sa.Column("day", sa.Date(), primary_key=True),
+        sa.Column("bytes", sa.BigInteger(), nullable=False, server_default="0"),
+        sa.Column("fetches", sa.Integer(), nullable=False, server_default="0"),
+    )
+    op.create_index("idx_source_bandwidth_day", "source_bandwidth", ["day"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_source_bandwidth_day", table_name="source_bandwidth")
+    op.drop_table("source_bandwidth")
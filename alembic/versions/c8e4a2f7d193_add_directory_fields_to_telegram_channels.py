@@ -0,0 +1,35 @@
+"""add_directory_fields_to_telegram_channels
+
+Revision ID: c8e4a2f7d193
+Revises: b3f8d1a6e724
+Create Date: 2026-02-16 14:22:51.730418
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+from common.migrations import add_column
+
+
+# revision identifiers, used by Alembic.
+revision: str = "c8e4a2f7d193"
+down_revision: Union[str, Sequence[str], None] = "b3f8d1a6e724"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Shown in the channel directory; the reader keeps the title up to date
+    add_column("telegram_channels", sa.Column("title", sa.String(500), nullable=True))
+    add_column("telegram_channels", sa.Column("avatar_url", sa.String(2048), nullable=True))
+    add_column("telegram_channels", sa.Column("category", sa.String(100), nullable=True))
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    for column in ("category", "avatar_url", "title"):
+        op.drop_column("telegram_channels", column)
@@ -0,0 +1,32 @@
+"""add_shares_to_post_deliveries
+
+Revision ID: d2f7b4a9c806
+Revises: c8e4a2f7d193
+Create Date: 2026-02-17 11:08:36.214590
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+from common.migrations import add_column
+
+
+# revision identifiers, used by Alembic.
+revision: str = "d2f7b4a9c806"
+down_revision: Union[str, Sequence[str], None] = "c8e4a2f7d193"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Times a delivered post was shared (Mastodon reblogs), refreshed by the publisher
+    add_column("post_deliveries", sa.Column("shares", sa.Integer, nullable=True))
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_column("post_deliveries", "shares")
@@ -0,0 +1,44 @@
+"""create_post_revisions_table
+
+Revision ID: d4b8e2a7c615
+Revises: c7f1a3d9e520
+Create Date: 2026-02-10 11:32:07.514920
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "d4b8e2a7c615"
+down_revision: Union[str, Sequence[str], None] = "c7f1a3d9e520"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Edits of stored posts, each as a diff against the previous revision
+    op.create_table(
+        "post_revisions",
+        sa.Column(
+            "link",
+            sa.String(2048),
+            sa.ForeignKey("rss_posts.link", ondelete="CASCADE"),
+            primary_key=True,
+        ),
+        sa.Column("revision", sa.Integer(), primary_key=True),
+        sa.Column("delta", sa.Text(), nullable=False),
+        sa.Column("content_hash", sa.String(64), nullable=False),
+        sa.Column(
+            "created_at", sa.DateTime, nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+    )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_table("post_revisions")
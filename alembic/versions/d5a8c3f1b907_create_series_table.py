@@ -0,0 +1,52 @@
+"""create_series_table
+
+Revision ID: d5a8c3f1b907
+Revises: c2e7a9d4f185
+Create Date: 2026-02-01 16:20:37.815902
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "d5a8c3f1b907"
+down_revision: Union[str, Sequence[str], None] = "c2e7a9d4f185"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Festivals and other groups of related events; the key is a hashtag like
+    # '#jazzfest' or the link of the parent announcement. parent_link has no
+    # foreign key, so series can be restored from a backup before their posts.
+    op.create_table(
+        "series",
+        sa.Column("key", sa.String(2048), primary_key=True),
+        sa.Column("name", sa.String(500), nullable=False),
+        sa.Column("parent_link", sa.String(2048), nullable=True),
+        sa.Column(
+            "created_at", sa.DateTime, nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+    )
+    op.add_column(
+        "rss_posts",
+        sa.Column(
+            "series_key",
+            sa.String(2048),
+            sa.ForeignKey("series.key", ondelete="SET NULL"),
+            nullable=True,
+        ),
+    )
+    op.create_index("idx_rss_posts_series_key", "rss_posts", ["series_key"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_rss_posts_series_key", table_name="rss_posts")
+    op.drop_column("rss_posts", "series_key")
+    op.drop_table("series")
@@ -0,0 +1,32 @@
+"""add_deadline_to_rss_posts
+
+Revision ID: d6a4b2e8c913
+Revises: c3f7a1e9d258
+Create Date: 2026-02-20 11:04:51.370628
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "d6a4b2e8c913"
+down_revision: Union[str, Sequence[str], None] = "c3f7a1e9d258"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Last day to register or apply, and which of them (registration, applications)
+    op.add_column("rss_posts", sa.Column("deadline", sa.Date(), nullable=True))
+    op.add_column("rss_posts", sa.Column("deadline_kind", sa.Text(), nullable=True))
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_column("rss_posts", "deadline_kind")
+    op.drop_column("rss_posts", "deadline")
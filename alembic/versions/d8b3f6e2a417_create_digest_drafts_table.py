@@ -0,0 +1,50 @@
+"""create_digest_drafts_table
+
+Revision ID: d8b3f6e2a417
+Revises: c4e9a2b7d105
+Create Date: 2026-02-06 10:41:17.284903
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "d8b3f6e2a417"
+down_revision: Union[str, Sequence[str], None] = "c4e9a2b7d105"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Digests waiting for a moderator when DIGEST_REVIEW is on, one per routing target
+    op.create_table(
+        "digest_drafts",
+        sa.Column("id", sa.Integer(), primary_key=True),
+        sa.Column("publisher", sa.String(50), nullable=False),
+        sa.Column("destination", sa.String(500), nullable=False),
+        # Post links in the order they are published
+        sa.Column("links", sa.dialects.postgresql.ARRAY(sa.Text()), nullable=False),
+        sa.Column("intro", sa.Text(), nullable=True),
+        sa.Column("status", sa.String(20), nullable=False, server_default="draft"),
+        # Final message, rendered when the draft is locked
+        sa.Column("text", sa.Text(), nullable=True),
+        sa.Column(
+            "created_at", sa.DateTime(), nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+        sa.Column(
+            "updated_at", sa.DateTime(), nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+        sa.Column("sent_at", sa.DateTime(), nullable=True),
+    )
+    op.create_index("idx_digest_drafts_status", "digest_drafts", ["status"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_digest_drafts_status", table_name="digest_drafts")
+    op.drop_table("digest_drafts")
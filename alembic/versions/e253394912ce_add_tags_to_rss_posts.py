@@ -0,0 +1,37 @@
+"""add_tags_to_rss_posts
+
+Revision ID: e253394912ce
+Revises: 8852fda0d953
+Create Date: 2026-01-24 14:02:37.518220
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "e253394912ce"
+down_revision: Union[str, Sequence[str], None] = "8852fda0d953"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Tags assigned by post filters
+    op.add_column(
+        "rss_posts",
+        sa.Column("tags", sa.dialects.postgresql.ARRAY(sa.String(100)), nullable=True),
+    )
+
+    # GIN index for tag containment queries
+    op.create_index("idx_rss_posts_tags", "rss_posts", ["tags"], postgresql_using="gin")
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_rss_posts_tags", table_name="rss_posts")
+    op.drop_column("rss_posts", "tags")
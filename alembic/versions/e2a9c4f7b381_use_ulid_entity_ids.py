@@ -0,0 +1,130 @@
+"""use_ulid_entity_ids
+
+Revision ID: e2a9c4f7b381
+Revises: d4b8e2a7c615
+Create Date: 2026-02-11 09:47:23.180462
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+from sqlalchemy.dialects import postgresql
+
+
+# revision identifiers, used by Alembic.
+revision: str = "e2a9c4f7b381"
+down_revision: Union[str, Sequence[str], None] = "d4b8e2a7c615"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+# Tables whose IDs the application now generates, with their creation time
+ENTITIES = [
+    ("promotions", "created_at", sa.BigInteger()),
+    ("poll_runs", "started_at", sa.Integer()),
+    ("submissions", "created_at", sa.Integer()),
+    ("digest_drafts", "created_at", sa.Integer()),
+    ("publish_failures", "created_at", sa.Integer()),
+]
+# Columns referencing them
+REFERENCES = [
+    ("promotion_placements", "promotion_id", "promotions", sa.BigInteger()),
+    ("poll_run_items", "run_id", "poll_runs", sa.Integer()),
+]
+
+CROCKFORD = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+
+def ulid(created_at, old_id: int) -> str:
+    """ULID of an existing row: its creation time, with the old ID as the randomness.
+
+    Rows created in the same millisecond keep their order. A copy of
+    common.ids.encode_ulid, so the migration doesn't depend on application code.
+    """
+    value = (int(created_at.timestamp() * 1000) << 80) | old_id
+    return "".join(CROCKFORD[(value >> shift) & 31] for shift in range(125, -1, -5))
+
+
+def renumber(mapping: dict, table: str) -> None:
+    """Change the IDs of a table and of what references it."""
+    bind = op.get_bind()
+    for old, new in mapping.items():
+        params = {"old": old, "new": new}
+        bind.execute(sa.text(f"UPDATE {table} SET id = :new WHERE id = :old"), params)
+        for referencing, column, target, _ in REFERENCES:
+            if target == table:
+                bind.execute(
+                    sa.text(f"UPDATE {referencing} SET {column} = :new WHERE {column} = :old"),
+                    params,
+                )
+        if table == "promotions":
+            bind.execute(
+                sa.text(
+                    "UPDATE publish_failures "
+                    "SET promotion_ids = array_replace(promotion_ids, CAST(:old AS varchar), :new)"
+                ),
+                params,
+            )
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    for table, column, target, _ in REFERENCES:
+        op.drop_constraint(f"{table}_{column}_fkey", table, type_="foreignkey")
+        op.alter_column(table, column, type_=sa.String(26), postgresql_using=f"{column}::text")
+    # The '{}' default can't be cast along with the column
+    op.alter_column("publish_failures", "promotion_ids", server_default=None)
+    op.alter_column(
+        "publish_failures",
+        "promotion_ids",
+        type_=postgresql.ARRAY(sa.String(26)),
+        postgresql_using="promotion_ids::varchar(26)[]",
+        server_default="{}",
+    )
+
+    bind = op.get_bind()
+    for table, created, _ in ENTITIES:
+        op.execute(f"ALTER TABLE {table} ALTER COLUMN id DROP DEFAULT")
+        op.alter_column(table, "id", type_=sa.String(26), postgresql_using="id::text")
+        op.execute(f"DROP SEQUENCE IF EXISTS {table}_id_seq")
+        rows = bind.execute(sa.text(f"SELECT id, {created} FROM {table}")).fetchall()
+        renumber({old: ulid(at, int(old)) for old, at in rows}, table)
+
+    for table, column, target, _ in REFERENCES:
+        op.create_foreign_key(
+            f"{table}_{column}_fkey", table, target, [column], ["id"], ondelete="CASCADE"
+        )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    for table, column, target, _ in REFERENCES:
+        op.drop_constraint(f"{table}_{column}_fkey", table, type_="foreignkey")
+
+    # Number the rows again in the order of their ULIDs, i.e. by creation
+    bind = op.get_bind()
+    for table, _, id_type in ENTITIES:
+        rows = bind.execute(sa.text(f"SELECT id FROM {table} ORDER BY id")).fetchall()
+        renumber({old: str(number) for number, (old,) in enumerate(rows, 1)}, table)
+        op.alter_column(table, "id", type_=id_type, postgresql_using="id::bigint")
+        op.execute(f"CREATE SEQUENCE {table}_id_seq OWNED BY {table}.id")
+        op.execute(
+            f"SELECT setval('{table}_id_seq', COALESCE((SELECT MAX(id) FROM {table}), 0) + 1, "
+            "false)"
+        )
+        op.execute(f"ALTER TABLE {table} ALTER COLUMN id SET DEFAULT nextval('{table}_id_seq')")
+
+    op.alter_column("publish_failures", "promotion_ids", server_default=None)
+    op.alter_column(
+        "publish_failures",
+        "promotion_ids",
+        type_=postgresql.ARRAY(sa.Integer()),
+        postgresql_using="promotion_ids::integer[]",
+        server_default="{}",
+    )
+    for table, column, target, id_type in REFERENCES:
+        op.alter_column(table, column, type_=id_type, postgresql_using=f"{column}::bigint")
+        op.create_foreign_key(
+            f"{table}_{column}_fkey", table, target, [column], ["id"], ondelete="CASCADE"
+        )
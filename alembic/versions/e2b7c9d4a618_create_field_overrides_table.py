@@ -0,0 +1,43 @@
+"""create_field_overrides_table
+
+Revision ID: e2b7c9d4a618
+Revises: d6a4b2e8c913
+Create Date: 2026-02-21 10:17:42.836105
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "e2b7c9d4a618"
+down_revision: Union[str, Sequence[str], None] = "d6a4b2e8c913"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Moderators' corrections of extracted fields, kept when a post is extracted again
+    op.create_table(
+        "field_overrides",
+        sa.Column(
+            "link",
+            sa.String(2048),
+            sa.ForeignKey("rss_posts.link", ondelete="CASCADE"),
+            primary_key=True,
+        ),
+        sa.Column("field", sa.String(64), primary_key=True),
+        sa.Column("value", sa.Text(), nullable=False),
+        sa.Column(
+            "created_at", sa.DateTime, nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+    )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_table("field_overrides")
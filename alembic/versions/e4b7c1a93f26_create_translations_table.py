@@ -0,0 +1,40 @@
+"""create_translations_table
+
+Revision ID: e4b7c1a93f26
+Revises: 5e8a2f4c9d13
+Create Date: 2026-01-30 10:07:14.482913
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "e4b7c1a93f26"
+down_revision: Union[str, Sequence[str], None] = "5e8a2f4c9d13"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Translation cache keyed by a hash of the source text, so edited titles and
+    # summaries are translated again and identical texts are translated once
+    op.create_table(
+        "translations",
+        sa.Column("source_hash", sa.String(64), primary_key=True),
+        sa.Column("language", sa.String(16), primary_key=True),
+        sa.Column("text", sa.Text, nullable=False),
+        sa.Column("provider", sa.String(50), nullable=False),
+        sa.Column(
+            "created_at", sa.DateTime, nullable=False, server_default=sa.text("CURRENT_TIMESTAMP")
+        ),
+    )
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_table("translations")
@@ -0,0 +1,30 @@
+"""add_event_status_to_rss_posts
+
+Revision ID: e9b1d4a7c362
+Revises: d5a8c3f1b907
+Create Date: 2026-02-02 10:41:18.937254
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "e9b1d4a7c362"
+down_revision: Union[str, Sequence[str], None] = "d5a8c3f1b907"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # cancelled or postponed; NULL for events that take place as announced
+    op.add_column("rss_posts", sa.Column("event_status", sa.String(20), nullable=True))
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_column("rss_posts", "event_status")
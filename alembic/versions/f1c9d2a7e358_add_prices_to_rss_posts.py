@@ -0,0 +1,34 @@
+"""add_prices_to_rss_posts
+
+Revision ID: f1c9d2a7e358
+Revises: e4b7c1a93f26
+Create Date: 2026-01-30 15:21:37.904156
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "f1c9d2a7e358"
+down_revision: Union[str, Sequence[str], None] = "e4b7c1a93f26"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Ticket prices extracted from the post text; 0 means free entry
+    op.add_column("rss_posts", sa.Column("price_min", sa.Numeric(12, 2), nullable=True))
+    op.add_column("rss_posts", sa.Column("price_max", sa.Numeric(12, 2), nullable=True))
+    op.add_column("rss_posts", sa.Column("price_currency", sa.String(3), nullable=True))
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_column("rss_posts", "price_currency")
+    op.drop_column("rss_posts", "price_max")
+    op.drop_column("rss_posts", "price_min")
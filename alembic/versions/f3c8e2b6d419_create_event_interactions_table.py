@@ -0,0 +1,43 @@
+"""create_event_interactions_table
+
+Revision ID: f3c8e2b6d419
+Revises: e9b1d4a7c362
+Create Date: 2026-02-02 15:06:44.172093
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+
+# revision identifiers, used by Alembic.
+revision: str = "f3c8e2b6d419"
+down_revision: Union[str, Sequence[str], None] = "e9b1d4a7c362"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Daily counters of event page views and source link clicks; no visitor data is stored
+    op.create_table(
+        "event_interactions",
+        sa.Column(
+            "link",
+            sa.String(2048),
+            sa.ForeignKey("rss_posts.link", ondelete="CASCADE"),
+            primary_key=True,
+        ),
+        sa.Column("day", sa.Date(), primary_key=True),
+        sa.Column("kind", sa.String(20), primary_key=True),
+        sa.Column("count", sa.Integer(), nullable=False, server_default="0"),
+    )
+    op.create_index("idx_event_interactions_day", "event_interactions", ["day"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_event_interactions_day", table_name="event_interactions")
+    op.drop_table("event_interactions")
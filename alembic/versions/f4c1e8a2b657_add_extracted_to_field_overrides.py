@@ -0,0 +1,34 @@
+"""add_extracted_to_field_overrides
+
+Revision ID: f4c1e8a2b657
+Revises: e2b7c9d4a618
+Create Date: 2026-02-23 09:41:18.602347
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+import sqlalchemy as sa
+
+from common.migrations import add_column, create_index
+
+
+# revision identifiers, used by Alembic.
+revision: str = "f4c1e8a2b657"
+down_revision: Union[str, Sequence[str], None] = "e2b7c9d4a618"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # What extraction gave before the correction, as JSON, to report what it gets wrong
+    add_column("field_overrides", sa.Column("extracted", sa.Text(), nullable=True))
+    create_index("idx_field_overrides_created_at", "field_overrides", ["created_at"])
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.drop_index("idx_field_overrides_created_at", table_name="field_overrides")
+    op.drop_column("field_overrides", "extracted")
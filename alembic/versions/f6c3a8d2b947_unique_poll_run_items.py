@@ -0,0 +1,44 @@
+"""unique_poll_run_items
+
+Revision ID: f6c3a8d2b947
+Revises: e2a9c4f7b381
+Create Date: 2026-02-12 10:31:45.602917
+
+"""
+
+from typing import Sequence, Union
+
+from alembic import op
+
+
+# revision identifiers, used by Alembic.
+revision: str = "f6c3a8d2b947"
+down_revision: Union[str, Sequence[str], None] = "e2a9c4f7b381"
+branch_labels: Union[str, Sequence[str], None] = None
+depends_on: Union[str, Sequence[str], None] = None
+
+
+def upgrade() -> None:
+    """Upgrade schema."""
+    # Runs used to report each warning of a feed separately; keep the first one
+    op.execute(
+        """
+        DELETE FROM poll_run_items i USING poll_run_items first
+        WHERE i.run_id = first.run_id AND i.source = first.source AND i.link = first.link
+            AND i.id > first.id
+        """
+    )
+    # Retried runs store each item once; the index also serves lookups by run
+    op.create_index(
+        "uq_poll_run_items_run_source_link",
+        "poll_run_items",
+        ["run_id", "source", "link"],
+        unique=True,
+    )
+    op.drop_index("idx_poll_run_items_run_id", table_name="poll_run_items")
+
+
+def downgrade() -> None:
+    """Downgrade schema."""
+    op.create_index("idx_poll_run_items_run_id", "poll_run_items", ["run_id"])
+    op.drop_index("uq_poll_run_items_run_source_link", table_name="poll_run_items")
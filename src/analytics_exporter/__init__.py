@@ -0,0 +1 @@
+"""Analytics Exporter Service - Streams posts and channels into ClickHouse."""
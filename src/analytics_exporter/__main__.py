@@ -0,0 +1,184 @@
+"""Entry point for Analytics Exporter service.
+
+Run with: python -m src.analytics_exporter
+"""
+
+import asyncio
+import json
+import logging
+from datetime import datetime
+from typing import Any, Dict, List
+
+from common import clock
+from common.db.session import db
+from common.db.repository import (
+    InteractionRepository,
+    RSSPostRepository,
+    TelegramChannelRepository,
+)
+from common.db.models import RSSPost, TelegramChannel
+from common.utils.links import channel_from_link
+from .clickhouse import ClickHouseClient
+from .config import analytics_exporter_settings
+
+logging.basicConfig(
+    level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
+)
+logger = logging.getLogger(__name__)
+
+
+def post_to_row(post: RSSPost) -> Dict[str, Any]:
+    """Convert an RSSPost into a ClickHouse rss_posts row."""
+    media_count = 0
+    if post.media:
+        try:
+            media_count = len(json.loads(post.media))
+        except (ValueError, TypeError):
+            media_count = len(post.media.split(","))
+
+    return {
+        "link": post.link,
+        "channel_name": channel_from_link(post.link),
+        "content": post.content,
+        "content_length": len(post.content or ""),
+        "media_count": media_count,
+        "pub_date": post.pub_date,
+        "is_published": int(post.is_published),
+        "published_at": post.published_at,
+        "created_at": post.created_at,
+        "updated_at": post.updated_at,
+    }
+
+
+def channel_to_row(channel: TelegramChannel) -> Dict[str, Any]:
+    """Convert a TelegramChannel into a ClickHouse telegram_channels row."""
+    return {
+        "channel_id": channel.channel_id,
+        "channel_name": channel.channel_name,
+        "description": channel.description or "",
+        "url": channel.url or "",
+        "created_at": channel.created_at,
+        "updated_at": channel.updated_at,
+    }
+
+
+async def export_posts(client: ClickHouseClient) -> int:
+    """
+    Export posts changed since the last run in batches.
+
+    Args:
+        client: ClickHouseClient instance
+
+    Returns:
+        Number of exported posts
+    """
+    since = await asyncio.to_thread(client.get_watermark, "rss_posts")
+    logger.info(f"Exporting posts updated after {since or 'the beginning'}")
+
+    exported = 0
+    after_link = ""
+    for _ in range(analytics_exporter_settings.max_batches):
+        posts: List[RSSPost] = await RSSPostRepository.get_updated_since(
+            since, after_link, limit=analytics_exporter_settings.batch_size
+        )
+        if not posts:
+            break
+
+        rows = [post_to_row(post) for post in posts]
+        exported += await asyncio.to_thread(client.insert, "rss_posts", rows)
+        logger.info(f"Exported batch of {len(rows)} posts")
+
+        since, after_link = posts[-1].updated_at, posts[-1].link
+        if len(posts) < analytics_exporter_settings.batch_size:
+            break
+    else:
+        logger.warning("Reached ANALYTICS_EXPORT_MAX_BATCHES, remaining posts wait for next run")
+
+    return exported
+
+
+async def export_channels(client: ClickHouseClient) -> int:
+    """
+    Export all Telegram channels (the table is small, so it is re-sent every run).
+
+    Args:
+        client: ClickHouseClient instance
+
+    Returns:
+        Number of exported channels
+    """
+    channels = await TelegramChannelRepository.get_all()
+    rows = [channel_to_row(channel) for channel in channels]
+    return await asyncio.to_thread(client.insert, "telegram_channels", rows)
+
+
+def interaction_to_row(counter: Dict[str, Any], exported_at: datetime) -> Dict[str, Any]:
+    """Convert a daily interaction counter into a ClickHouse event_interactions row."""
+    return {
+        "link": counter["link"],
+        "channel_name": channel_from_link(counter["link"]),
+        "day": counter["day"],
+        "kind": counter["kind"],
+        "count": counter["count"],
+        "exported_at": exported_at,
+    }
+
+
+async def export_interactions(client: ClickHouseClient) -> int:
+    """
+    Export event page views and clicks from the last exported day on.
+
+    Args:
+        client: ClickHouseClient instance
+
+    Returns:
+        Number of exported counters
+    """
+    last_day = await asyncio.to_thread(client.get_watermark, "event_interactions", "day")
+    since = last_day.date() if last_day else None
+    counters = await InteractionRepository.get_daily(since)
+    exported_at = clock.now()
+    rows = [interaction_to_row(counter, exported_at) for counter in counters]
+    return await asyncio.to_thread(client.insert, "event_interactions", rows)
+
+
+async def main():
+    """Main entry point for Analytics Exporter service."""
+    logger.info("Starting Analytics Exporter service...")
+
+    try:
+        analytics_exporter_settings.validate()
+
+        if not db.pool:
+            await db.connect()
+            logger.info("Connected to database")
+
+        client = ClickHouseClient(
+            url=analytics_exporter_settings.clickhouse_url,
+            database=analytics_exporter_settings.clickhouse_database,
+            user=analytics_exporter_settings.clickhouse_user,
+            password=analytics_exporter_settings.clickhouse_password,
+            timeout=analytics_exporter_settings.clickhouse_timeout,
+        )
+        await asyncio.to_thread(client.ensure_schema)
+
+        channels_exported = await export_channels(client)
+        posts_exported = await export_posts(client)
+        interactions_exported = await export_interactions(client)
+
+        print(
+            f"✓ Exported {posts_exported} posts, {channels_exported} channels and "
+            f"{interactions_exported} interaction counters to ClickHouse"
+        )
+        logger.info("Analytics Exporter service completed successfully")
+
+        return {"exported_count": posts_exported}
+
+    except Exception as e:
+        logger.error(f"Error: {e}", exc_info=True)
+        print(f"Error: {e}")
+        raise
+
+
+if __name__ == "__main__":
+    asyncio.run(main())
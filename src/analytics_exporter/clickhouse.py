@@ -0,0 +1,166 @@
+"""Minimal ClickHouse client over the HTTP interface."""
+
+import json
+import logging
+from datetime import datetime
+from typing import Any, Dict, List, Optional
+
+import requests
+
+logger = logging.getLogger(__name__)
+
+# Tables are created on demand so a fresh ClickHouse instance needs no manual setup.
+# ReplacingMergeTree keeps the row with the latest updated_at, so re-exporting a post
+# after it was published simply supersedes the previous version.
+SCHEMA = [
+    """
+    CREATE TABLE IF NOT EXISTS {database}.rss_posts (
+        link String,
+        channel_name LowCardinality(String),
+        content String,
+        content_length UInt32,
+        media_count UInt16,
+        pub_date Nullable(DateTime),
+        is_published UInt8,
+        published_at Nullable(DateTime),
+        created_at DateTime,
+        updated_at DateTime
+    )
+    ENGINE = ReplacingMergeTree(updated_at)
+    PARTITION BY toYYYYMM(created_at)
+    ORDER BY link
+    """,
+    """
+    CREATE TABLE IF NOT EXISTS {database}.telegram_channels (
+        channel_id Int64,
+        channel_name String,
+        description String,
+        url String,
+        created_at DateTime,
+        updated_at DateTime
+    )
+    ENGINE = ReplacingMergeTree(updated_at)
+    ORDER BY channel_id
+    """,
+    # Daily counters of event page views and clicks; today's keep growing, so the
+    # last exported day is sent again and the latest export of a counter wins
+    """
+    CREATE TABLE IF NOT EXISTS {database}.event_interactions (
+        link String,
+        channel_name LowCardinality(String),
+        day Date,
+        kind LowCardinality(String),
+        count UInt32,
+        exported_at DateTime
+    )
+    ENGINE = ReplacingMergeTree(exported_at)
+    PARTITION BY toYYYYMM(day)
+    ORDER BY (link, day, kind)
+    """,
+]
+
+
+class ClickHouseClient:
+    """Thin wrapper around the ClickHouse HTTP interface."""
+
+    def __init__(
+        self,
+        url: str,
+        database: str,
+        user: str = "default",
+        password: str = "",
+        timeout: int = 30,
+    ):
+        """
+        Initialize ClickHouse client.
+
+        Args:
+            url: Base URL of the ClickHouse HTTP interface
+            database: Database to create tables in
+            user: ClickHouse user
+            password: ClickHouse password
+            timeout: Request timeout in seconds
+        """
+        self.url = url.rstrip("/") + "/"
+        self.database = database
+        self.timeout = timeout
+        self.session = requests.Session()
+        self.session.headers.update({"X-ClickHouse-User": user, "X-ClickHouse-Key": password})
+
+    def execute(self, query: str, data: Optional[str] = None) -> str:
+        """
+        Execute a query and return the raw response body.
+
+        Args:
+            query: SQL query
+            data: Optional request body (e.g. rows for INSERT)
+
+        Returns:
+            Response body as text
+
+        Raises:
+            requests.RequestException: If the query fails
+        """
+        if data is None:
+            response = self.session.post(self.url, data=query.encode(), timeout=self.timeout)
+        else:
+            response = self.session.post(
+                self.url, params={"query": query}, data=data.encode(), timeout=self.timeout
+            )
+        response.raise_for_status()
+        return response.text
+
+    def ensure_schema(self) -> None:
+        """Create the database and analytics tables if they don't exist."""
+        self.execute(f"CREATE DATABASE IF NOT EXISTS {self.database}")
+        for statement in SCHEMA:
+            self.execute(statement.format(database=self.database))
+        logger.info(f"ClickHouse schema ready in database '{self.database}'")
+
+    def insert(self, table: str, rows: List[Dict[str, Any]]) -> int:
+        """
+        Insert rows using the JSONEachRow format.
+
+        Args:
+            table: Target table name (without database)
+            rows: Rows to insert
+
+        Returns:
+            Number of inserted rows
+        """
+        if not rows:
+            return 0
+
+        body = "\n".join(json.dumps(row, ensure_ascii=False, default=_json_default) for row in rows)
+        self.execute(f"INSERT INTO {self.database}.{table} FORMAT JSONEachRow", data=body)
+        return len(rows)
+
+    def get_watermark(self, table: str, column: str = "updated_at") -> Optional[datetime]:
+        """
+        Get the latest exported timestamp for a table.
+
+        Args:
+            table: Table name (without database)
+            column: Timestamp column to inspect
+
+        Returns:
+            Latest timestamp, or None if the table is empty
+        """
+        result = self.execute(
+            f"SELECT max({column}), count() FROM {self.database}.{table} FORMAT JSONEachRow"
+        ).strip()
+        if not result:
+            return None
+
+        row = json.loads(result)
+        if not int(row.get("count()", 0)):
+            return None
+
+        return datetime.fromisoformat(row[f"max({column})"])
+
+
+def _json_default(value: Any) -> Any:
+    """Serialize datetimes in the format ClickHouse expects for DateTime columns."""
+    if isinstance(value, datetime):
+        return value.strftime("%Y-%m-%d %H:%M:%S")
+    return str(value)
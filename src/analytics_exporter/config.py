@@ -0,0 +1,48 @@
+"""Configuration for Analytics Exporter service."""
+
+import os
+from dataclasses import dataclass
+from pathlib import Path
+from dotenv import load_dotenv
+
+# Load .env file if it exists
+env_path = Path(__file__).parent.parent.parent / ".env"
+if env_path.exists():
+    load_dotenv(env_path)
+
+
+@dataclass
+class AnalyticsExporterConfig:
+    """Analytics Exporter configuration settings."""
+
+    # ClickHouse HTTP interface
+    clickhouse_url: str = os.getenv("CLICKHOUSE_URL", "http://localhost:8123")
+    clickhouse_database: str = os.getenv("CLICKHOUSE_DATABASE", "event_platform")
+    clickhouse_user: str = os.getenv("CLICKHOUSE_USER", "default")
+    clickhouse_password: str = os.getenv("CLICKHOUSE_PASSWORD", "")
+    clickhouse_timeout: int = int(os.getenv("CLICKHOUSE_TIMEOUT", "30"))
+
+    # Export settings
+    batch_size: int = int(os.getenv("ANALYTICS_EXPORT_BATCH_SIZE", "500"))
+    max_batches: int = int(os.getenv("ANALYTICS_EXPORT_MAX_BATCHES", "100"))
+
+    def validate(self) -> bool:
+        """
+        Validate configuration.
+
+        Returns:
+            True if configuration is valid
+
+        Raises:
+            ValueError: If configuration is invalid
+        """
+        if not self.clickhouse_url:
+            raise ValueError("CLICKHOUSE_URL environment variable is required")
+
+        if self.batch_size < 1:
+            raise ValueError("ANALYTICS_EXPORT_BATCH_SIZE must be at least 1")
+
+        return True
+
+
+analytics_exporter_settings = AnalyticsExporterConfig()
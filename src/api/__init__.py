@@ -0,0 +1 @@
+"""HTTP API Service - Read-only JSON endpoints over the event platform database."""
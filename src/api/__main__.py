@@ -0,0 +1,107 @@
+"""Entry point for HTTP API service.
+
+Run with: python -m src.api
+"""
+
+import asyncio
+import hmac
+import logging
+from http import HTTPStatus
+from typing import Optional
+
+from common.db.session import db
+from . import (
+    digests,
+    embed,
+    grafana,
+    manual,
+    overrides,
+    polls,
+    public,
+    publish_failures,
+    quotas,
+    revisions,
+    site,
+    stats,
+    submissions,
+    webhooks,
+)
+from .config import api_settings
+from .server import HTTPServer, Request, Response, json_response
+
+logging.basicConfig(
+    level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
+)
+logger = logging.getLogger(__name__)
+
+
+def require_token(request: Request) -> Optional[Response]:
+    """Reject requests without the configured bearer token."""
+    if api_settings.site_enabled and site.is_public(request.path):
+        return None
+    if api_settings.webhook_tokens and webhooks.is_webhook(request.path):
+        return None
+    expected = f"Bearer {api_settings.api_token}"
+    provided = request.headers.get("authorization", "")
+    if not hmac.compare_digest(provided.encode(), expected.encode()):
+        return json_response({"error": "Unauthorized"}, status=HTTPStatus.UNAUTHORIZED)
+    return None
+
+
+def create_server() -> HTTPServer:
+    """Create the HTTP server with all routes registered."""
+    server = HTTPServer(
+        max_body_bytes=api_settings.max_body_bytes,
+        request_timeout=api_settings.request_timeout,
+    )
+
+    if api_settings.public_mode:
+        # Safe to expose directly: read-only, rate limited, nothing behind API_TOKEN
+        public.register(server)
+        if api_settings.submissions_enabled:
+            submissions.register_public(server)
+        if api_settings.embed_secret:
+            embed.register_public(server)
+    else:
+        if api_settings.api_token:
+            server.add_middleware(require_token)
+        else:
+            logger.warning("API_TOKEN not set, API is accessible without authentication")
+        grafana.register(server)
+        polls.register(server)
+        manual.register(server)
+        submissions.register(server)
+        digests.register(server)
+        publish_failures.register(server)
+        quotas.register(server)
+        revisions.register(server)
+        overrides.register(server)
+        stats.register(server)
+        if api_settings.webhook_tokens:
+            webhooks.register(server)
+        if api_settings.embed_secret:
+            embed.register(server)
+
+    if api_settings.site_enabled:
+        site.register(server)
+    return server
+
+
+async def main():
+    """Main entry point for HTTP API service."""
+    logger.info("Starting HTTP API service...")
+
+    try:
+        await db.connect()
+        logger.info("Connected to database")
+
+        server = create_server()
+        await server.serve(api_settings.host, api_settings.port)
+
+    finally:
+        await db.disconnect()
+        logger.info("Disconnected from database")
+
+
+if __name__ == "__main__":
+    asyncio.run(main())
@@ -0,0 +1,79 @@
+"""Configuration for HTTP API service."""
+
+import os
+from dataclasses import dataclass
+from pathlib import Path
+from dotenv import load_dotenv
+
+from common.og_images import DEFAULT_RENDER_COMMAND
+
+# Load .env file if it exists
+env_path = Path(__file__).parent.parent.parent / ".env"
+if env_path.exists():
+    load_dotenv(env_path)
+
+
+@dataclass
+class ApiConfig:
+    """HTTP API configuration settings."""
+
+    host: str = os.getenv("API_HOST", "0.0.0.0")
+    port: int = int(os.getenv("API_PORT", "8080"))
+
+    # Bearer token required on every request (empty disables auth)
+    api_token: str = os.getenv("API_TOKEN", "")
+
+    # Request limits
+    max_body_bytes: int = int(os.getenv("API_MAX_BODY_BYTES", str(1024 * 1024)))
+    request_timeout: int = int(os.getenv("API_REQUEST_TIMEOUT", "30"))
+
+    # Public event pages, sitemap.xml and robots.txt (served without API_TOKEN)
+    site_enabled: bool = os.getenv("API_SITE_ENABLED", "false").lower() == "true"
+    site_title: str = os.getenv("SITE_TITLE", "Афиша")
+    site_base_url: str = os.getenv("SITE_BASE_URL", "")
+    site_language: str = os.getenv("SITE_LANGUAGE", "ru")
+    site_days_back: int = int(os.getenv("SITE_DAYS_BACK", "30"))
+    site_max_events: int = int(os.getenv("SITE_MAX_EVENTS", "2000"))
+    site_cache_seconds: int = int(os.getenv("API_SITE_CACHE_SECONDS", "60"))
+
+    # Read-only public API without API_TOKEN (no private endpoints are registered)
+    public_mode: bool = os.getenv("API_PUBLIC_MODE", "false").lower() == "true"
+    public_rate_limit: int = int(os.getenv("API_PUBLIC_RATE_LIMIT", "60"))
+    public_cache_seconds: int = int(os.getenv("API_PUBLIC_CACHE_SECONDS", "300"))
+    public_days_back: int = int(os.getenv("API_PUBLIC_DAYS_BACK", "14"))
+    public_max_events: int = int(os.getenv("API_PUBLIC_MAX_EVENTS", "500"))
+    # Header with the client address set by a reverse proxy, e.g. X-Forwarded-For
+    public_client_ip_header: str = os.getenv("API_PUBLIC_CLIENT_IP_HEADER", "")
+
+    # Event submissions from organizers (POST /v1/submissions in public mode)
+    submissions_enabled: bool = os.getenv("API_SUBMISSIONS_ENABLED", "false").lower() == "true"
+    # Submissions per client and hour
+    submission_rate_limit: int = int(os.getenv("API_SUBMISSION_RATE_LIMIT", "5"))
+    # Partner tokens as name:token pairs, sent in the X-Submission-Token header
+    submission_tokens: str = os.getenv("API_SUBMISSION_TOKENS", "")
+    # Captcha of the public form: Cloudflare Turnstile, or hCaptcha with its siteverify URL
+    captcha_secret: str = os.getenv("API_CAPTCHA_SECRET", "")
+    captcha_verify_url: str = os.getenv(
+        "API_CAPTCHA_VERIFY_URL", "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+    )
+
+    # Posts pushed by external collectors to /webhooks/<source>, as source:token pairs
+    webhook_tokens: str = os.getenv("API_WEBHOOK_TOKENS", "")
+    webhook_max_items: int = int(os.getenv("API_WEBHOOK_MAX_ITEMS", "500"))
+
+    # Embeddable event widget (GET /v1/embed in public mode); tokens are signed with the secret
+    embed_secret: str = os.getenv("API_EMBED_SECRET", "")
+    # Public URL of the API for embed snippets, e.g. https://api.example.com
+    embed_base_url: str = os.getenv("API_EMBED_BASE_URL", "")
+
+    # Languages of cached translations exposed next to the original text
+    translation_languages: str = os.getenv("TRANSLATION_LANGUAGES", "")
+
+    # Open Graph cards rendered from SVG by an external command, cached in MEDIA_DIR/og
+    og_images_enabled: bool = os.getenv("API_OG_IMAGES_ENABLED", "false").lower() == "true"
+    media_dir: str = os.getenv("MEDIA_DIR", "media")
+    og_render_command: str = os.getenv("OG_IMAGE_RENDER_COMMAND", DEFAULT_RENDER_COMMAND)
+    og_render_timeout: int = int(os.getenv("OG_IMAGE_TIMEOUT", "10"))
+
+
+api_settings = ApiConfig()
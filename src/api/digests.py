@@ -0,0 +1,136 @@
+"""Digest curation.
+
+With DIGEST_REVIEW=true digests wait as drafts (see digest_publisher.drafts)
+until a moderator sends them:
+
+- GET  /digest-drafts                 drafts waiting to be sent
+- GET  /digest-drafts/<id>            a draft with its posts and the message preview
+- POST /digest-drafts/<id>            {"links": [...], "intro": "..."}  reorder or remove
+                                      posts and set the intro note
+- POST /digest-drafts/<id>/lock       render the final message; no more edits
+- POST /digest-drafts/<id>/send       publish a locked draft
+- POST /digest-drafts/<id>/discard    drop a draft; its posts go into the next digest
+
+Edits can only reorder or remove the posts of a draft, not add new ones.
+"""
+
+import logging
+from http import HTTPStatus
+from typing import Tuple
+
+from common.db.models import DigestDraft
+from common.ids import parse_id
+from common.db.repository import DigestDraftRepository
+from .server import HTTPError, HTTPServer, Request, Response, json_response
+
+logger = logging.getLogger(__name__)
+
+PREFIX = "/digest-drafts/"
+ACTIONS = ("lock", "send", "discard")
+
+
+async def find_draft(request: Request) -> Tuple[DigestDraft, str]:
+    """Find the draft a path refers to, and the action after its ID."""
+    draft_id, _, action = request.path[len(PREFIX) :].partition("/")
+    draft_id = parse_id(draft_id)
+    if not draft_id or (action and action not in ACTIONS):
+        raise HTTPError(HTTPStatus.NOT_FOUND, "Not found")
+    draft = await DigestDraftRepository.get_by_id(draft_id)
+    if draft is None:
+        raise HTTPError(HTTPStatus.NOT_FOUND, f"Draft #{draft_id} not found")
+    return draft, action
+
+
+def expect_status(draft: DigestDraft, status: str) -> None:
+    """Reject acting on a draft that has moved on."""
+    if draft.status != status:
+        raise HTTPError(HTTPStatus.CONFLICT, f"Draft #{draft.id} is {draft.status}")
+
+
+async def list_drafts(request: Request) -> Response:
+    """List drafts waiting to be sent, newest first."""
+    # Imported here, like webhooks do, to keep the publisher's entry point out of API startup
+    from digest_publisher import drafts
+
+    open_drafts = await DigestDraftRepository.get_by_status(drafts.OPEN_STATUSES)
+    return json_response([draft.to_dict() for draft in open_drafts])
+
+
+async def show_draft(request: Request) -> Response:
+    """Show a draft with its posts and the message it would be sent as."""
+    from digest_publisher import drafts
+
+    draft, action = await find_draft(request)
+    if action:
+        raise HTTPError(HTTPStatus.NOT_FOUND, "Not found")
+    data = draft.to_dict()
+    data["posts"] = [post.to_dict() for post in await drafts.load_posts(draft)]
+    data["preview"] = await drafts.preview(draft)
+    return json_response(data)
+
+
+async def edit_draft(draft: DigestDraft, data: object, max_intro_length: int) -> Response:
+    """Reorder or remove the posts of a draft and set its intro."""
+    expect_status(draft, "draft")
+    if not isinstance(data, dict):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "Body must be a JSON object")
+
+    links = data.get("links", draft.links)
+    if not isinstance(links, list) or not all(isinstance(link, str) for link in links):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'links' must be a list of post links")
+    unknown = [link for link in links if link not in draft.links]
+    if unknown or len(set(links)) != len(links):
+        raise HTTPError(
+            HTTPStatus.BAD_REQUEST, "'links' may only reorder or remove the draft's posts"
+        )
+
+    intro = data.get("intro", draft.intro)
+    if intro is not None and not isinstance(intro, str):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'intro' must be a string")
+    intro = (intro or "").strip() or None
+    if intro and len(intro) > max_intro_length:
+        raise HTTPError(
+            HTTPStatus.BAD_REQUEST, f"'intro' must be at most {max_intro_length} characters"
+        )
+
+    if not await DigestDraftRepository.update(draft.id, links, intro):
+        raise HTTPError(HTTPStatus.CONFLICT, f"Draft #{draft.id} was locked meanwhile")
+    return json_response((await DigestDraftRepository.get_by_id(draft.id)).to_dict())
+
+
+async def act(request: Request) -> Response:
+    """Edit, lock, send or discard a draft."""
+    from digest_publisher import drafts
+
+    draft, action = await find_draft(request)
+    if not action:
+        return await edit_draft(draft, request.json(), drafts.MAX_INTRO_LENGTH)
+
+    if action == "lock":
+        expect_status(draft, "draft")
+        try:
+            done = await drafts.lock(draft)
+        except ValueError as e:
+            raise HTTPError(HTTPStatus.UNPROCESSABLE_ENTITY, str(e))
+    elif action == "send":
+        expect_status(draft, "locked")
+        try:
+            done = await drafts.send(draft)
+        except Exception as e:
+            logger.error(f"Failed to send draft #{draft.id}: {e}", exc_info=True)
+            raise HTTPError(HTTPStatus.BAD_GATEWAY, f"Failed to publish: {e}")
+    else:
+        if draft.status not in drafts.OPEN_STATUSES:
+            expect_status(draft, "draft")
+        done = await drafts.discard(draft)
+
+    if not done:
+        raise HTTPError(HTTPStatus.CONFLICT, f"Draft #{draft.id} was changed meanwhile")
+    return json_response((await DigestDraftRepository.get_by_id(draft.id)).to_dict())
+
+
+def register(server: HTTPServer) -> None:
+    """Register digest curation routes."""
+    server.add_route("GET", "/digest-drafts", list_drafts)
+    server.add_prefix_route("GET", PREFIX, show_draft)
+    server.add_prefix_route("POST", PREFIX, act)
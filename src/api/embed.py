@@ -0,0 +1,256 @@
+"""Embeddable event widget.
+
+Local sites can show the city's upcoming events with a snippet that loads
+the list in an iframe:
+
+    <script src="https://api.example.com/v1/embed.js?token=TOKEN" async></script>
+
+- GET /v1/embed?token=      upcoming events as a small HTML page (the iframe)
+- GET /v1/embed.js?token=   script inserting the iframe where it's placed
+
+Both are public (API_PUBLIC_MODE). What a widget shows is encoded in its
+token (channel, tag, online, max_price, limit, title) and signed with
+API_EMBED_SECRET, so a site can't change the filters or show more events
+than it was given. Tokens are made on the private instance:
+
+- POST /embed-tokens  {"tag": "concert", "limit": 5, "title": "Концерты"}
+"""
+
+import base64
+import hashlib
+import hmac
+import json
+from dataclasses import asdict, dataclass
+from decimal import Decimal
+from html import escape
+from http import HTTPStatus
+from typing import List, Optional
+from urllib.parse import quote
+
+from common import clock
+from common.db.models import RSSPost
+from common.db.session import reads_from_replicas
+from common.event_dates import EventSchedule
+from common.pages import event_path, event_title, event_when
+from .config import api_settings
+from .public import PREFIX, cached_body, filter_events, load_events, not_modified, responses
+from .server import HTTPError, HTTPServer, Request, Response, json_response
+
+EMBED_PATH = f"{PREFIX}/embed"
+SCRIPT_PATH = f"{PREFIX}/embed.js"
+
+MAX_EVENTS = 50
+MAX_TITLE_LENGTH = 100
+
+STYLE = """\
+body { font-family: system-ui, sans-serif; margin: 0; padding: 0.5rem; color: #222;
+       font-size: 0.95rem; line-height: 1.4; }
+h2 { font-size: 1.1rem; margin: 0 0 0.5rem; }
+ul { list-style: none; margin: 0; padding: 0; }
+li { padding: 0.4rem 0; border-bottom: 1px solid #eee; }
+a { color: #2b88d8; text-decoration: none; }
+.meta { display: block; color: #666; font-size: 0.85rem; }
+"""
+
+
+@dataclass
+class WidgetParams:
+    """Filters and title of a widget, as encoded in its token."""
+
+    channel: str = ""
+    tag: str = ""
+    online: Optional[bool] = None
+    max_price: Optional[float] = None
+    limit: int = 10
+    title: str = ""
+
+    @staticmethod
+    def from_dict(data: dict) -> "WidgetParams":
+        """
+        Create WidgetParams from a token payload or request body.
+
+        Raises:
+            ValueError: If a parameter is invalid
+        """
+        if not isinstance(data, dict):
+            raise ValueError("Parameters must be a JSON object")
+        unknown = set(data) - set(WidgetParams.__dataclass_fields__)
+        if unknown:
+            raise ValueError(f"Unknown parameters: {', '.join(sorted(unknown))}")
+        params = WidgetParams(**data)
+        for name in ("channel", "tag", "title"):
+            if not isinstance(getattr(params, name), str):
+                raise ValueError(f"'{name}' must be a string")
+        if len(params.title) > MAX_TITLE_LENGTH:
+            raise ValueError(f"'title' must be at most {MAX_TITLE_LENGTH} characters")
+        if params.online is not None and not isinstance(params.online, bool):
+            raise ValueError("'online' must be true or false")
+        if params.max_price is not None and (
+            isinstance(params.max_price, bool)
+            or not isinstance(params.max_price, (int, float))
+            or params.max_price < 0
+        ):
+            raise ValueError("'max_price' must be a non-negative number")
+        if isinstance(params.limit, bool) or not isinstance(params.limit, int):
+            raise ValueError("'limit' must be an integer")
+        if not 1 <= params.limit <= MAX_EVENTS:
+            raise ValueError(f"'limit' must be between 1 and {MAX_EVENTS}")
+        return params
+
+
+def _b64encode(data: bytes) -> str:
+    return base64.urlsafe_b64encode(data).rstrip(b"=").decode()
+
+
+def _b64decode(text: str) -> bytes:
+    return base64.urlsafe_b64decode(text + "=" * (-len(text) % 4))
+
+
+def _signature(payload: str, secret: str) -> str:
+    digest = hmac.new(secret.encode(), payload.encode(), hashlib.sha256).digest()
+    return _b64encode(digest)
+
+
+def sign(params: WidgetParams, secret: str) -> str:
+    """Encode widget parameters as a signed token."""
+    # Only set parameters are encoded, to keep tokens short
+    data = {name: value for name, value in asdict(params).items() if value not in (None, "")}
+    payload = _b64encode(json.dumps(data, separators=(",", ":"), sort_keys=True).encode())
+    return f"{payload}.{_signature(payload, secret)}"
+
+
+def verify(token: str, secret: str) -> Optional[WidgetParams]:
+    """Decode a token, or None if it wasn't signed with the secret or is malformed."""
+    payload, _, signature = token.partition(".")
+    expected = _signature(payload, secret).encode()
+    if not secret or not hmac.compare_digest(signature.encode(), expected):
+        return None
+    try:
+        return WidgetParams.from_dict(json.loads(_b64decode(payload)))
+    except ValueError:
+        return None
+
+
+def upcoming_events(posts: List[RSSPost], params: WidgetParams) -> List[RSSPost]:
+    """Events matching a widget's filters that haven't ended, soonest first."""
+    max_price = Decimal(str(params.max_price)) if params.max_price is not None else None
+    matching = filter_events(
+        posts, params.channel, params.tag, len(posts), max_price=max_price, online=params.online
+    )
+    today = clock.today()
+    schedules = [(EventSchedule.from_post(post), post) for post in matching]
+    upcoming = [(s, post) for s, post in schedules if s and s.end >= today]
+    upcoming.sort(key=lambda item: item[0].start)
+    return [post for _, post in upcoming[: params.limit]]
+
+
+def event_url(post: RSSPost) -> str:
+    """Where a widget entry links to: the event page if the site is public, else the source."""
+    if api_settings.site_enabled and api_settings.site_base_url:
+        return f"{api_settings.site_base_url.rstrip('/')}/{event_path(post)}"
+    return post.link
+
+
+def render_widget(posts: List[RSSPost], params: WidgetParams) -> str:
+    """Render the widget page."""
+    title = params.title or api_settings.site_title
+    items = "".join(
+        f'\n    <li><span class="meta">{escape(event_when(post))}</span>'
+        f'<a href="{escape(event_url(post))}">{escape(event_title(post))}</a></li>'
+        for post in posts
+    )
+    body = f"  <ul>{items}\n  </ul>\n" if posts else "  <p>Ближайших событий нет.</p>\n"
+    # Links open in the embedding site's window, not inside the frame
+    return (
+        f'<!DOCTYPE html>\n<html lang="{escape(api_settings.site_language)}">\n<head>\n'
+        f'  <meta charset="utf-8">\n'
+        f"  <title>{escape(title)}</title>\n"
+        f'  <base target="_blank">\n'
+        f"  <style>\n{STYLE}</style>\n"
+        f"</head>\n<body>\n"
+        f"  <h2>{escape(title)}</h2>\n"
+        f"{body}"
+        f"</body>\n</html>\n"
+    )
+
+
+def render_script(frame_url: str, title: str) -> str:
+    """Render the script that puts the widget iframe where it's included."""
+    return (
+        "(function () {\n"
+        "  var script = document.currentScript;\n"
+        '  var frame = document.createElement("iframe");\n'
+        f"  frame.src = {json.dumps(frame_url)};\n"
+        f"  frame.title = {json.dumps(title)};\n"
+        '  frame.loading = "lazy";\n'
+        '  frame.style.width = "100%";\n'
+        '  frame.style.border = "0";\n'
+        '  frame.height = script.getAttribute("data-height") || "480";\n'
+        "  script.parentNode.insertBefore(frame, script);\n"
+        "})();\n"
+    )
+
+
+def base_url(request: Request) -> str:
+    """Public URL of the API, from API_EMBED_BASE_URL or the Host header."""
+    if api_settings.embed_base_url:
+        return api_settings.embed_base_url.rstrip("/")
+    scheme = request.headers.get("x-forwarded-proto", "http")
+    return f"{scheme}://{request.headers.get('host', 'localhost')}"
+
+
+def widget_params(request: Request) -> WidgetParams:
+    """Parameters of the token a request carries."""
+    params = verify(request.query.get("token", ""), api_settings.embed_secret)
+    if params is None:
+        raise HTTPError(HTTPStatus.FORBIDDEN, "Invalid embed token")
+    return params
+
+
+async def widget(request: Request) -> Response:
+    """Upcoming events of a widget."""
+    params = widget_params(request)
+    key = json.dumps([EMBED_PATH, request.query["token"]])
+    response = responses.get(key)
+    if response is None:
+        posts = upcoming_events((await load_events()).posts, params)
+        response = cached_body(render_widget(posts, params).encode(), "text/html; charset=utf-8")
+        responses.set(key, response)
+    return not_modified(request, response)
+
+
+async def script(request: Request) -> Response:
+    """Script embedding a widget."""
+    params = widget_params(request)
+    frame_url = f"{base_url(request)}{EMBED_PATH}?token={quote(request.query['token'])}"
+    body = render_script(frame_url, params.title or api_settings.site_title)
+    return cached_body(body.encode(), "text/javascript; charset=utf-8")
+
+
+async def create_token(request: Request) -> Response:
+    """Sign widget parameters and return the token with embed snippets."""
+    try:
+        params = WidgetParams.from_dict(request.json())
+    except (TypeError, ValueError) as e:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, str(e))
+    token = sign(params, api_settings.embed_secret)
+    data = {"token": token, "params": asdict(params)}
+    if api_settings.embed_base_url:
+        url = api_settings.embed_base_url.rstrip("/")
+        data["script"] = f'<script src="{url}{SCRIPT_PATH}?token={token}" async></script>'
+        data["iframe"] = (
+            f'<iframe src="{url}{EMBED_PATH}?token={token}" title="{escape(params.title)}" '
+            f'width="100%" height="480" style="border:0" loading="lazy"></iframe>'
+        )
+    return json_response(data, status=HTTPStatus.CREATED)
+
+
+def register_public(server: HTTPServer) -> None:
+    """Register widget routes of the public API."""
+    server.add_route("GET", EMBED_PATH, reads_from_replicas(widget))
+    server.add_route("GET", SCRIPT_PATH, script)
+
+
+def register(server: HTTPServer) -> None:
+    """Register the token signing route."""
+    server.add_route("POST", "/embed-tokens", create_token)
@@ -0,0 +1,122 @@
+"""Grafana JSON datasource endpoints.
+
+Implements the protocol used by the Grafana "JSON" / "SimpleJSON" datasource
+plugins, so dashboards can chart posts per day per channel without direct
+database access:
+
+- GET  /grafana           health check
+- POST /grafana/search    list available metrics (SimpleJSON)
+- POST /grafana/metrics   list available metrics (JSON datasource)
+- POST /grafana/query     time series for the requested metrics
+"""
+
+from collections import defaultdict
+from datetime import datetime, timezone
+from http import HTTPStatus
+from typing import Dict, List
+
+from common.db.repository import RSSPostRepository
+from .server import HTTPError, HTTPServer, Request, Response, json_response
+
+# Metric name -> whether it counts published posts (by published_at)
+METRICS = {
+    "posts_per_day": False,
+    "published_per_day": True,
+}
+
+
+def parse_grafana_time(value: str) -> datetime:
+    """
+    Parse a Grafana range timestamp into a naive UTC datetime.
+
+    Args:
+        value: ISO 8601 timestamp like '2026-01-10T10:00:00.000Z'
+
+    Returns:
+        Timezone-naive datetime (rss_posts stores naive timestamps)
+    """
+    try:
+        dt = datetime.fromisoformat(value.replace("Z", "+00:00"))
+    except (ValueError, AttributeError):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"Invalid range timestamp: {value}")
+    if dt.tzinfo:
+        dt = dt.astimezone(timezone.utc)
+    return dt.replace(tzinfo=None)
+
+
+def build_series(rows: List[dict], channel: str = "") -> List[dict]:
+    """
+    Convert daily count rows into Grafana time series (one per channel).
+
+    Args:
+        rows: Rows from RSSPostRepository.get_daily_counts_by_channel
+        channel: Only include this channel if set
+
+    Returns:
+        List of {"target": channel, "datapoints": [[count, epoch_ms], ...]}
+    """
+    datapoints: Dict[str, List[list]] = defaultdict(list)
+    for row in rows:
+        if channel and row["channel_name"] != channel:
+            continue
+        timestamp_ms = int(row["day"].replace(tzinfo=timezone.utc).timestamp() * 1000)
+        datapoints[row["channel_name"]].append([row["count"], timestamp_ms])
+
+    return [{"target": name, "datapoints": points} for name, points in sorted(datapoints.items())]
+
+
+async def health(request: Request) -> Response:
+    """Datasource connection test."""
+    return json_response({"status": "ok"})
+
+
+async def search(request: Request) -> Response:
+    """List metric names usable as query targets."""
+    return json_response(list(METRICS))
+
+
+async def metrics(request: Request) -> Response:
+    """List metrics in the JSON datasource plugin format."""
+    return json_response([{"label": name, "value": name} for name in METRICS])
+
+
+async def query(request: Request) -> Response:
+    """Return time series for each requested target.
+
+    A target may be scoped to a single channel with 'metric:channel',
+    e.g. 'posts_per_day:centralbank_russia'.
+    """
+    payload = request.json()
+    if not isinstance(payload, dict):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "Query must be a JSON object")
+    time_range = payload.get("range") or {}
+    if not isinstance(time_range, dict) or "from" not in time_range or "to" not in time_range:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "Query must include range.from and range.to")
+    targets = payload.get("targets") or []
+    if not isinstance(targets, list) or not all(isinstance(t, dict) for t in targets):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "targets must be a list of objects")
+
+    start_date = parse_grafana_time(time_range["from"])
+    end_date = parse_grafana_time(time_range["to"])
+
+    series = []
+    for target in targets:
+        name = target.get("target") or target.get("metric") or ""
+        metric, _, channel = str(name).partition(":")
+        if metric not in METRICS:
+            raise HTTPError(HTTPStatus.BAD_REQUEST, f"Unknown metric: {metric}")
+
+        rows = await RSSPostRepository.get_daily_counts_by_channel(
+            start_date, end_date, only_published=METRICS[metric]
+        )
+        series.extend(build_series(rows, channel))
+
+    return json_response(series)
+
+
+def register(server: HTTPServer) -> None:
+    """Register Grafana datasource routes."""
+    server.add_route("GET", "/grafana", health)
+    server.add_route("POST", "/grafana/search", search)
+    server.add_route("POST", "/grafana/metrics", metrics)
+    server.add_route("POST", "/grafana/query", query)
@@ -0,0 +1,106 @@
+"""Events created by hand.
+
+Private (behind API_TOKEN) endpoint for moderators to add an event that no
+channel posted:
+
+- POST /manual-events  {"link": ..., "content": ..., "title": ..., "pub_date": ...,
+                        "tags": [...], "media_urls": [...]}
+
+The event is stored like a polled post from the 'manual' source: the same
+rules and WASM filters run on it, prices, admission, format and dates are
+detected in `content`, and it's summarized, published in the next digest and
+exported like any other post. `link` is the event's own page (organizer,
+tickets); it's shown as the source and identifies the event.
+"""
+
+from datetime import datetime
+from http import HTTPStatus
+from typing import List, Optional
+
+from common import clock
+from common.db.models import RSSPost
+from common.db.repository import RSSPostRepository
+from common.models.feed import RSSItem
+from common.rules import load_rules
+from rss_reader.core.filters import RuleFilter, load_filters
+from rss_reader.core.ingest import apply_filters, build_post, store_post
+from .server import HTTPError, HTTPServer, Request, Response, json_response
+
+SOURCE_NAME = "manual"
+MANUAL_TAG = "manual"
+
+
+def string_list(data: dict, name: str) -> List[str]:
+    """Read an optional list of strings from the request body."""
+    value = data.get(name) or []
+    if not isinstance(value, list) or not all(isinstance(v, str) for v in value):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"'{name}' must be a list of strings")
+    return value
+
+
+def parse_event(data: dict) -> RSSItem:
+    """Validate a manual event and convert it to a feed item."""
+    if not isinstance(data, dict):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "Body must be a JSON object")
+    link = data.get("link")
+    if not isinstance(link, str) or not link.startswith(("http://", "https://")):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'link' must be an http(s) URL of the event")
+    content = data.get("content")
+    if not isinstance(content, str) or not content.strip():
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'content' must describe the event")
+
+    pub_date = data.get("pub_date") or clock.now().isoformat()
+    try:
+        datetime.fromisoformat(str(pub_date).replace("Z", "+00:00"))
+    except ValueError:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'pub_date' must be an ISO 8601 timestamp")
+
+    return RSSItem(
+        link=link,
+        description=content,
+        pub_date=str(pub_date),
+        media_urls=string_list(data, "media_urls"),
+    )
+
+
+async def add_event(
+    source_name: str, item: RSSItem, tags: List[str], title: Optional[str] = None
+) -> RSSPost:
+    """
+    Filter and store an event that came in through the API.
+
+    Raises:
+        HTTPError: 409 if an event with the link exists, 422 if a filter drops it
+    """
+    if await RSSPostRepository.get_by_link(item.link):
+        raise HTTPError(HTTPStatus.CONFLICT, f"An event with link {item.link} exists")
+
+    decision = await apply_filters(source_name, item, [RuleFilter(load_rules()), load_filters()])
+    if not decision.keep:
+        raise HTTPError(HTTPStatus.UNPROCESSABLE_ENTITY, f"Dropped by {decision.reason}")
+
+    post = await build_post(item, list(dict.fromkeys([*tags, *decision.tags])))
+    await store_post(post)
+    if title:
+        # The summarizer keeps a title that's already set
+        await RSSPostRepository.update_summary(post.link, None, title)
+        post.title = title
+    return post
+
+
+async def create_event(request: Request) -> Response:
+    """Create an event by hand."""
+    data = request.json()
+    item = parse_event(data)
+    tags = string_list(data, "tags")
+    title = data.get("title")
+    if title is not None and not isinstance(title, str):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'title' must be a string")
+
+    post = await add_event(SOURCE_NAME, item, [MANUAL_TAG, *tags], title)
+    return json_response(post.to_dict(), status=HTTPStatus.CREATED)
+
+
+def register(server: HTTPServer) -> None:
+    """Register manual event routes."""
+    server.add_route("POST", "/manual-events", create_event)
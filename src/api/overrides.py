@@ -0,0 +1,159 @@
+"""Corrections of extracted fields.
+
+Prices, dates, format and the other fields worked out from a post's text
+(see rss_reader.core.extraction) are sometimes wrong. Moderators correct
+them with private (behind API_TOKEN) endpoints:
+
+- GET  /posts/fields?link=URL                    each field's value, the extracted
+                                                 one and whether it was corrected
+- POST /posts/fields         {"link": ..., "field": "price_min", "value": "500"}
+- POST /posts/fields/revert  {"link": ..., "field": "price_min"}  back to the
+                                                 extracted value
+- GET  /posts/fields/report[?days=30&examples=3&suggest=true]
+                                                 fields corrected most often, by
+                                                 channel, and suggested keywords
+
+Values are given in the JSON form of an extraction baseline: prices as
+strings, dates and times in ISO 8601, null for none. A correction is kept
+when the post is extracted again, e.g. when an import updates its text.
+The report (see rss_reader.core.corrections) tells which extractors need
+work first.
+"""
+
+import json
+from dataclasses import replace
+from datetime import timedelta
+from http import HTTPStatus
+from typing import Any, Dict, Tuple
+
+from common import clock
+from common.db.models import FieldOverride, RSSPost
+from common.db.repository import FieldOverrideRepository, RSSPostRepository
+from rss_reader.core.corrections import suggest_keywords, summarize_corrections
+from rss_reader.core.extraction import comparable, extract_fields, from_comparable
+from .polls import int_param
+from .server import HTTPError, HTTPServer, Request, Response, json_response
+
+
+async def get_post(link: Any) -> RSSPost:
+    """The post a request is about."""
+    if not isinstance(link, str) or not link:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'link' must be the link of a post")
+    post = await RSSPostRepository.get_by_link(link)
+    if not post:
+        raise HTTPError(HTTPStatus.NOT_FOUND, f"No post with link {link}")
+    return post
+
+
+async def fields_view(post: RSSPost) -> Dict[str, Any]:
+    """Current and extracted value of every extracted field of a post."""
+    extracted = extract_fields(post.content, post.pub_date)
+    current = comparable({name: getattr(post, name) for name in extracted})
+    extracted = comparable(extracted)
+    overrides = await FieldOverrideRepository.get_by_link(post.link)
+    corrected = {override.field for override in overrides}
+    return {
+        "link": post.link,
+        "fields": {
+            name: {
+                "value": current[name],
+                "extracted": extracted[name],
+                "overridden": name in corrected,
+            }
+            for name in extracted
+        },
+    }
+
+
+def field_param(data: Any) -> Tuple[Any, str]:
+    """Link and field name of a correction request."""
+    if not isinstance(data, dict):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "Body must be a JSON object")
+    name = data.get("field")
+    if not isinstance(name, str) or not name:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'field' must name an extracted field")
+    return data.get("link"), name
+
+
+async def show_fields(request: Request) -> Response:
+    """Show the extracted fields of a post."""
+    post = await get_post(request.query.get("link"))
+    return json_response(await fields_view(post))
+
+
+async def override_field(request: Request) -> Response:
+    """Correct an extracted field of a post."""
+    data = request.json()
+    link, name = field_param(data)
+    post = await get_post(link)
+    if "value" not in data:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'value' is required, null for none")
+    try:
+        value = from_comparable(name, data["value"])
+    except ValueError as e:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, str(e))
+
+    # Stored like in a baseline, so a price given as a number is a string too
+    stored = comparable({name: value})[name]
+    extracted = comparable(extract_fields(post.content, post.pub_date))[name]
+    await FieldOverrideRepository.set(
+        FieldOverride(
+            post.link,
+            name,
+            json.dumps(stored, ensure_ascii=False),
+            json.dumps(extracted, ensure_ascii=False),
+        )
+    )
+    post = replace(post, **{name: value}, updated_at=clock.now())
+    await RSSPostRepository.upsert(post)
+    return json_response(await fields_view(post))
+
+
+async def revert_field(request: Request) -> Response:
+    """Drop the correction of a field, going back to the value extracted from the text."""
+    link, name = field_param(request.json())
+    post = await get_post(link)
+    extracted = extract_fields(post.content, post.pub_date)
+    if name not in extracted:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"'{name}' is not an extracted field")
+    if not await FieldOverrideRepository.delete(post.link, name):
+        raise HTTPError(HTTPStatus.NOT_FOUND, f"{name} of {post.link} wasn't corrected")
+
+    post = replace(post, **{name: extracted[name]}, updated_at=clock.now())
+    await RSSPostRepository.upsert(post)
+    return json_response(await fields_view(post))
+
+
+async def report(request: Request) -> Response:
+    """Summarize the corrections of the last days."""
+    days = min(int_param(request, "days", 30), 366)
+    examples = min(int_param(request, "examples", 3), 20)
+    since = clock.now() - timedelta(days=days)
+    overrides = await FieldOverrideRepository.get_since(since)
+    data = {
+        "days": days,
+        "corrections": len(overrides),
+        "fields": [stats.to_dict() for stats in summarize_corrections(overrides, examples)],
+    }
+
+    if request.query.get("suggest", "true").lower() == "true":
+        texts = {}
+        for link in {override.link for override in overrides}:
+            post = await RSSPostRepository.get_by_link(link)
+            if post:
+                texts[link] = post.content
+        recent = await RSSPostRepository.get_by_date_range(
+            since, clock.now(), limit=2000, only_unpublished=False
+        )
+        background = [post.content for post in recent if post.link not in texts]
+        suggestions = suggest_keywords(overrides, texts, background)
+        data["suggestions"] = [suggestion.to_dict() for suggestion in suggestions]
+    return json_response(data)
+
+
+def register(server: HTTPServer) -> None:
+    """Register field correction routes."""
+    server.add_route("GET", "/posts/fields", show_fields)
+    server.add_route("POST", "/posts/fields", override_field)
+    server.add_route("POST", "/posts/fields/revert", revert_field)
+    server.add_route("GET", "/posts/fields/report", report)
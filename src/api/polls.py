@@ -0,0 +1,79 @@
+"""Poll run report endpoints.
+
+Private (behind API_TOKEN) views of what the RSS reader did with each feed
+item, for finding out why a post never showed up:
+
+- GET /poll-runs                             latest runs with counts per outcome
+- GET /poll-runs/items?run=ID[&outcome=]     items of a run
+- GET /poll-runs/history?link=URL            what recent runs did with one post
+- GET /poll-runs/bandwidth[?days=&source=]   bytes downloaded per source and day
+"""
+
+from datetime import timedelta
+from http import HTTPStatus
+
+from common import clock
+from common.db.repository import BandwidthRepository, PollRunRepository
+from common.ids import parse_id
+from .server import HTTPError, HTTPServer, Request, Response, json_response
+
+MAX_LIMIT = 200
+
+
+def int_param(request: Request, name: str, default: int = 0) -> int:
+    """Read a positive integer query parameter."""
+    value = request.query.get(name)
+    if value is None and default:
+        return default
+    try:
+        number = int(value or "")
+    except ValueError:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"'{name}' must be an integer")
+    if number < 1:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"'{name}' must be positive")
+    return number
+
+
+async def runs(request: Request) -> Response:
+    """List the latest poll runs."""
+    limit = min(int_param(request, "limit", 20), MAX_LIMIT)
+    return json_response([run.to_dict() for run in await PollRunRepository.get_recent(limit)])
+
+
+async def items(request: Request) -> Response:
+    """List the items of a poll run, optionally only those with one outcome."""
+    run_id = parse_id(request.query.get("run") or "")
+    if not run_id:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'run' must be a poll run ID")
+    outcome = request.query.get("outcome") or None
+    return json_response(
+        [item.to_dict() for item in await PollRunRepository.get_items(run_id, outcome)]
+    )
+
+
+async def history(request: Request) -> Response:
+    """List what recent poll runs did with a post."""
+    link = request.query.get("link")
+    if not link:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "Query must include 'link'")
+    limit = min(int_param(request, "limit", 20), MAX_LIMIT)
+    return json_response(
+        [item.to_dict() for item in await PollRunRepository.get_history(link, limit)]
+    )
+
+
+async def bandwidth(request: Request) -> Response:
+    """List the bytes downloaded per source and day, biggest first."""
+    days = min(int_param(request, "days", 7), 366)
+    since = clock.now().date() - timedelta(days=days - 1)
+    source = request.query.get("source") or None
+    totals = await BandwidthRepository.get_since(since, source)
+    return json_response([total.to_dict() for total in totals])
+
+
+def register(server: HTTPServer) -> None:
+    """Register poll run report routes."""
+    server.add_route("GET", "/poll-runs", runs)
+    server.add_route("GET", "/poll-runs/items", items)
+    server.add_route("GET", "/poll-runs/history", history)
+    server.add_route("GET", "/poll-runs/bandwidth", bandwidth)
@@ -0,0 +1,511 @@
+"""Read-only public API.
+
+Enabled with API_PUBLIC_MODE=true. In this mode the service exposes only
+what is safe to put directly on the internet, without API_TOKEN:
+
+- GET /v1/health               health check
+- GET /v1/events               approved events (?channel=, ?tag=, ?online=, ?min_price=,
+                               ?max_price=, ?performer=, ?organization=, ?limit=)
+- GET /v1/events/<slug>        a single approved event
+- GET /v1/events.ics           approved events as an iCalendar feed (?lang=)
+- GET /v1/events.rss           approved events as an RSS 2.0 feed
+- GET /v1/events.atom          approved events as an Atom feed
+- GET /v1/channels             channel directory with post frequency and latest event
+                               (?category=)
+
+Only posts that passed classification and were published in a digest are
+served, from the last API_PUBLIC_DAYS_BACK days. Every client is limited to
+API_PUBLIC_RATE_LIMIT requests per minute, and responses are cached in
+memory for API_PUBLIC_CACHE_SECONDS with matching Cache-Control and ETag
+headers, so most requests never reach the database. Translations into
+TRANSLATION_LANGUAGES are included when the Summarizer has cached them.
+"""
+
+import hashlib
+import json
+import time
+from datetime import timedelta
+from decimal import Decimal, InvalidOperation
+from http import HTTPStatus
+from typing import Callable, Dict, List, Optional, Tuple
+
+from common import clock
+from common.db.models import RSSPost, Series
+from common.db.repository import RSSPostRepository, TelegramChannelRepository
+from common.db.session import reads_from_replicas
+from common.entities import mentions
+from common.event_dates import EventSchedule
+from common.event_format import is_offline, is_online
+from common.feed_writer import events_channel, render_atom, render_rss
+from common.ics import render_calendar
+from common.pages import event_path
+from common.series import load_series
+from common.translations import load_translations, parse_languages
+from common.utils.links import channel_from_link, post_slug
+from .config import api_settings
+from .server import HTTPError, HTTPServer, Request, Response
+from .site import EventCache
+
+PREFIX = "/v1"
+EVENTS_PATH = f"{PREFIX}/events"
+CALENDAR_PATH = f"{PREFIX}/events.ics"
+RSS_PATH = f"{PREFIX}/events.rss"
+ATOM_PATH = f"{PREFIX}/events.atom"
+CHANNELS_PATH = f"{PREFIX}/channels"
+
+MAX_LIMIT = 100
+DEFAULT_LIMIT = 50
+
+# Days of posts the post frequency of channels is computed over
+CHANNEL_ACTIVITY_DAYS = 28
+
+
+class RateLimiter:
+    """Fixed-window request counter per client."""
+
+    def __init__(
+        self, limit: int, window_seconds: float = 60, clock: Callable[[], float] = time.monotonic
+    ):
+        self.limit = limit
+        self.window_seconds = window_seconds
+        self.clock = clock
+        self.windows: Dict[str, Tuple[float, int]] = {}
+
+    def check(self, client: str) -> Optional[float]:
+        """
+        Count a request from a client.
+
+        Returns:
+            None if the request is allowed, otherwise seconds until the window resets
+        """
+        now = self.clock()
+        start, count = self.windows.get(client, (now, 0))
+        if now - start >= self.window_seconds:
+            start, count = now, 0
+        if count >= self.limit:
+            return self.window_seconds - (now - start)
+        self.windows[client] = (start, count + 1)
+
+        # Forget clients whose window is over, so the table doesn't grow unbounded
+        if len(self.windows) > 10000:
+            self.windows = {
+                key: value
+                for key, value in self.windows.items()
+                if now - value[0] < self.window_seconds
+            }
+        return None
+
+
+class ResponseCache:
+    """Rendered responses kept for a fixed TTL."""
+
+    def __init__(
+        self,
+        ttl_seconds: float,
+        max_entries: int = 1000,
+        clock: Callable[[], float] = time.monotonic,
+    ):
+        self.ttl_seconds = ttl_seconds
+        self.max_entries = max_entries
+        self.clock = clock
+        self.entries: Dict[str, Tuple[float, Response]] = {}
+
+    def get(self, key: str) -> Optional[Response]:
+        """Get a cached response that hasn't expired."""
+        entry = self.entries.get(key)
+        if entry is None or entry[0] <= self.clock():
+            return None
+        return entry[1]
+
+    def set(self, key: str, response: Response) -> None:
+        """Cache a response."""
+        now = self.clock()
+        if len(self.entries) >= self.max_entries:
+            self.entries = {key: entry for key, entry in self.entries.items() if entry[0] > now}
+            if len(self.entries) >= self.max_entries:
+                self.entries.pop(next(iter(self.entries)))
+        self.entries[key] = (now + self.ttl_seconds, response)
+
+
+limiter = RateLimiter(api_settings.public_rate_limit)
+responses = ResponseCache(api_settings.public_cache_seconds)
+cache = EventCache(api_settings.public_cache_seconds)
+
+
+def languages() -> List[str]:
+    """Languages with translations exposed by the API."""
+    return parse_languages(api_settings.translation_languages)
+
+
+def client_address(request: Request) -> str:
+    """
+    Address a request is rate limited by.
+
+    Behind a reverse proxy, API_PUBLIC_CLIENT_IP_HEADER names the header the
+    proxy puts the client address into; the last entry is the one the proxy
+    saw, earlier ones are supplied by the client.
+    """
+    header = api_settings.public_client_ip_header.lower()
+    if header and request.headers.get(header):
+        return request.headers[header].split(",")[-1].strip()
+    return request.client
+
+
+def rate_limit(request: Request) -> Optional[Response]:
+    """Reject clients that exceeded the request limit."""
+    retry_after = limiter.check(client_address(request))
+    if retry_after is None:
+        return None
+    return Response(
+        status=HTTPStatus.TOO_MANY_REQUESTS,
+        body=json.dumps({"error": "Too many requests"}).encode(),
+        headers={"Retry-After": str(max(1, int(retry_after + 0.999)))},
+    )
+
+
+def event_to_dict(
+    post: RSSPost,
+    translations: Optional[Dict[str, dict]] = None,
+    series: Optional[Series] = None,
+) -> dict:
+    """Public representation of an event (no internal fields)."""
+    schedule = EventSchedule.from_post(post)
+    data = {
+        "id": post_slug(post.link),
+        "title": post.title or "",
+        "summary": post.summary or "",
+        "content": post.content,
+        "channel": channel_from_link(post.link),
+        "link": post.link,
+        "date": post.pub_date.isoformat() if post.pub_date else None,
+        "dates": (
+            {
+                "start": schedule.start.isoformat(),
+                "end": schedule.end.isoformat(),
+                "sessions": [session.to_dict() for session in schedule.sessions],
+            }
+            if schedule
+            else None
+        ),
+        "tags": post.tags or [],
+        "performers": post.performers or [],
+        "organizations": post.organizations or [],
+        "contacts": post.contacts or [],
+        "deadline": (
+            {"date": post.deadline.isoformat(), "kind": post.deadline_kind or "registration"}
+            if post.deadline
+            else None
+        ),
+        "images": post.media_urls(),
+        "price": (
+            {
+                "min": float(post.price_min),
+                "max": float(post.price_max),
+                "currency": post.price_currency,
+            }
+            if post.price_min is not None
+            else None
+        ),
+        "format": post.event_format,
+        "status": post.event_status or "scheduled",
+        "series": (
+            {"id": series.key, "name": series.name, "events": series.event_count}
+            if series
+            else None
+        ),
+        "registration_required": post.registration_required,
+        "tickets_required": post.tickets_required,
+        "limited_capacity": post.limited_capacity,
+        "translations": translations or {},
+    }
+    if api_settings.site_enabled and api_settings.site_base_url:
+        data["url"] = f"{api_settings.site_base_url.rstrip('/')}/{event_path(post)}"
+    return data
+
+
+def cached_json(data: object) -> Response:
+    """Build a JSON response with public caching headers and an ETag."""
+    return cached_body(json.dumps(data, ensure_ascii=False, default=str).encode())
+
+
+def cached_body(body: bytes, content_type: str = "application/json; charset=utf-8") -> Response:
+    """Build a response with public caching headers and an ETag."""
+    return Response(
+        status=HTTPStatus.OK,
+        body=body,
+        content_type=content_type,
+        headers={
+            "Cache-Control": f"public, max-age={api_settings.public_cache_seconds}",
+            "ETag": f'"{hashlib.sha256(body).hexdigest()[:32]}"',
+            "Access-Control-Allow-Origin": "*",
+        },
+    )
+
+
+def not_modified(request: Request, response: Response) -> Response:
+    """Answer 304 when the client already has the current version."""
+    if request.headers.get("if-none-match") == response.headers.get("ETag"):
+        return Response(status=HTTPStatus.NOT_MODIFIED, headers=response.headers)
+    return response
+
+
+async def load_events() -> EventCache:
+    """Get approved events, reloading them from the database when the cache is stale."""
+    if not cache.is_fresh():
+        start_date = clock.now() - timedelta(days=api_settings.public_days_back)
+        posts = await RSSPostRepository.get_published_since(
+            start_date, api_settings.public_max_events
+        )
+        cache.set(posts, await load_series(posts))
+    return cache
+
+
+def parse_limit(value: Optional[str]) -> int:
+    """Parse the limit query parameter."""
+    if value is None:
+        return DEFAULT_LIMIT
+    try:
+        limit = int(value)
+    except ValueError:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"Invalid limit: {value}")
+    if not 1 <= limit <= MAX_LIMIT:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"limit must be between 1 and {MAX_LIMIT}")
+    return limit
+
+
+def parse_bool(name: str, value: Optional[str]) -> Optional[bool]:
+    """Parse a true/false query parameter."""
+    if value is None:
+        return None
+    if value.lower() in ("true", "1", "yes"):
+        return True
+    if value.lower() in ("false", "0", "no"):
+        return False
+    raise HTTPError(HTTPStatus.BAD_REQUEST, f"Invalid {name}: {value}")
+
+
+def parse_price(name: str, value: Optional[str]) -> Optional[Decimal]:
+    """Parse a price query parameter."""
+    if value is None:
+        return None
+    try:
+        price = Decimal(value)
+    except InvalidOperation:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"Invalid {name}: {value}")
+    if not price.is_finite() or price < 0:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"Invalid {name}: {value}")
+    return price
+
+
+def filter_events(
+    posts: List[RSSPost],
+    channel: str = "",
+    tag: str = "",
+    limit: int = DEFAULT_LIMIT,
+    min_price: Optional[Decimal] = None,
+    max_price: Optional[Decimal] = None,
+    online: Optional[bool] = None,
+    performer: str = "",
+    organization: str = "",
+) -> List[RSSPost]:
+    """
+    Filter events by channel, tag, price, format and the people and organizations named.
+
+    online=True keeps online and hybrid events, online=False keeps events
+    that can be attended in person (offline and hybrid).
+
+    max_price keeps events whose cheapest ticket costs at most that much
+    (max_price=0 keeps free events), min_price keeps events with a ticket of
+    at least that price. Events without a known price are dropped by either.
+
+    performer and organization keep events naming one whose name contains
+    them, so a surname finds the events of a speaker.
+    """
+    result = []
+    for post in posts:
+        if channel and channel_from_link(post.link) != channel:
+            continue
+        if tag and tag.lower() not in (t.lower() for t in post.tags or []):
+            continue
+        if (min_price is not None or max_price is not None) and post.price_min is None:
+            continue
+        if max_price is not None and post.price_min > max_price:
+            continue
+        if min_price is not None and post.price_max < min_price:
+            continue
+        if online is True and not is_online(post.event_format):
+            continue
+        if online is False and not is_offline(post.event_format):
+            continue
+        if performer and not mentions(post.performers or [], performer):
+            continue
+        if organization and not mentions(post.organizations or [], organization):
+            continue
+        result.append(post)
+        if len(result) >= limit:
+            break
+    return result
+
+
+async def health(request: Request) -> Response:
+    """Health check."""
+    return cached_json({"status": "ok"})
+
+
+async def events(request: Request) -> Response:
+    """List approved events."""
+    channel = request.query.get("channel", "")
+    tag = request.query.get("tag", "")
+    limit = parse_limit(request.query.get("limit"))
+    min_price = parse_price("min_price", request.query.get("min_price"))
+    max_price = parse_price("max_price", request.query.get("max_price"))
+    online = parse_bool("online", request.query.get("online"))
+    performer = request.query.get("performer", "")
+    organization = request.query.get("organization", "")
+
+    # Key on parsed parameters only, so unknown parameters can't be used to bypass the cache
+    key = json.dumps(
+        [EVENTS_PATH, channel, tag, limit, min_price, max_price, online, performer, organization],
+        default=str,
+    )
+    response = responses.get(key)
+    if response is None:
+        events = await load_events()
+        posts = filter_events(
+            events.posts,
+            channel,
+            tag,
+            limit,
+            min_price,
+            max_price,
+            online,
+            performer,
+            organization,
+        )
+        translations = await load_translations(posts, languages())
+        response = cached_json(
+            {
+                "events": [
+                    event_to_dict(
+                        post, translations[post.link], events.series.get(post.series_key or "")
+                    )
+                    for post in posts
+                ]
+            }
+        )
+        responses.set(key, response)
+    return not_modified(request, response)
+
+
+async def event(request: Request) -> Response:
+    """Get a single approved event by its id."""
+    slug = request.path[len(EVENTS_PATH) + 1 :]
+    key = json.dumps([EVENTS_PATH, slug])
+    response = responses.get(key)
+    if response is None:
+        events = await load_events()
+        post = next((post for post in events.posts if post_slug(post.link) == slug), None)
+        if post is None:
+            raise HTTPError(HTTPStatus.NOT_FOUND, "Event not found")
+        translations = await load_translations([post], languages())
+        series = events.series.get(post.series_key or "")
+        response = cached_json(event_to_dict(post, translations[post.link], series))
+        responses.set(key, response)
+    return not_modified(request, response)
+
+
+async def calendar(request: Request) -> Response:
+    """Approved events as an iCalendar feed."""
+    language = request.query.get("lang", "")
+    if language and language not in languages():
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"Unsupported language: {language}")
+
+    key = json.dumps([CALENDAR_PATH, language])
+    response = responses.get(key)
+    if response is None:
+        posts = (await load_events()).posts
+        translations = await load_translations(posts, [language] if language else [])
+        body = render_calendar(posts, api_settings.site_title, translations, language)
+        response = cached_body(body.encode(), "text/calendar; charset=utf-8")
+        responses.set(key, response)
+    return not_modified(request, response)
+
+
+async def feed(request: Request) -> Response:
+    """Approved events as an RSS 2.0 or Atom feed, items linking to the event pages if served."""
+    host = request.headers.get("host", "")
+    scheme = request.headers.get("x-forwarded-proto", "http")
+    self_url = f"{scheme}://{host}{request.path}" if host else ""
+    key = json.dumps([request.path, self_url])
+    response = responses.get(key)
+    if response is None:
+        site_url = api_settings.site_base_url if api_settings.site_enabled else ""
+        channel = events_channel((await load_events()).posts, api_settings.site_title, site_url)
+        channel.language = api_settings.site_language
+        if request.path == ATOM_PATH:
+            body = render_atom(channel, self_url)
+            response = cached_body(body, "application/atom+xml; charset=utf-8")
+        else:
+            body = render_rss(channel, self_url)
+            response = cached_body(body, "application/rss+xml; charset=utf-8")
+        responses.set(key, response)
+    return not_modified(request, response)
+
+
+async def channel_directory(category: str = "") -> List[dict]:
+    """Public representation of the channels, optionally of one category only."""
+    since = clock.now() - timedelta(days=CHANNEL_ACTIVITY_DAYS)
+    activity = {
+        row["channel_name"]: row for row in await RSSPostRepository.get_channel_activity(since)
+    }
+    result = []
+    for channel in await TelegramChannelRepository.get_all():
+        if category and (channel.category or "").lower() != category.lower():
+            continue
+        row = activity.get(channel.channel_name, {})
+        last_link = row.get("last_event_link")
+        last_date = row.get("last_event_date")
+        result.append(
+            {
+                "name": channel.channel_name,
+                "title": channel.title or channel.channel_name,
+                "avatar": channel.avatar_url,
+                "category": channel.category,
+                "url": channel.url or f"https://t.me/{channel.channel_name}",
+                "posts_per_week": round(row.get("count", 0) * 7 / CHANNEL_ACTIVITY_DAYS, 1),
+                "last_event": (
+                    {
+                        "id": post_slug(last_link),
+                        "title": row.get("last_event_title") or "",
+                        "link": last_link,
+                        "date": last_date.isoformat() if last_date else None,
+                    }
+                    if last_link
+                    else None
+                ),
+            }
+        )
+    return result
+
+
+async def channels(request: Request) -> Response:
+    """List channels for the channel directory."""
+    category = request.query.get("category", "")
+    key = json.dumps([CHANNELS_PATH, category.lower()])
+    response = responses.get(key)
+    if response is None:
+        response = cached_json({"channels": await channel_directory(category)})
+        responses.set(key, response)
+    return not_modified(request, response)
+
+
+def register(server: HTTPServer) -> None:
+    """Register public API routes and the rate limit."""
+    server.add_middleware(rate_limit)
+    server.add_route("GET", f"{PREFIX}/health", health)
+    server.add_route("GET", EVENTS_PATH, reads_from_replicas(events))
+    server.add_route("GET", CALENDAR_PATH, reads_from_replicas(calendar))
+    server.add_route("GET", RSS_PATH, reads_from_replicas(feed))
+    server.add_route("GET", ATOM_PATH, reads_from_replicas(feed))
+    server.add_prefix_route("GET", f"{EVENTS_PATH}/", reads_from_replicas(event))
+    server.add_route("GET", CHANNELS_PATH, reads_from_replicas(channels))
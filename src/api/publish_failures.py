@@ -0,0 +1,81 @@
+"""Messages that failed to publish.
+
+Digests and priority posts that a publisher couldn't send are queued and
+retried on a schedule (see digest_publisher.recovery). These routes show
+the queue and let an operator act on it:
+
+- GET  /publish-failures[?status=pending|failed|sent|discarded]
+- POST /publish-failures/<id>/retry    send the message now
+- POST /publish-failures/<id>/discard  give up; its posts go into the next digest
+
+A manual retry works on failures whose retries are used up, too.
+"""
+
+import logging
+from http import HTTPStatus
+
+from common.db.models import PublishFailure
+from common.db.repository import PublishFailureRepository
+from common.ids import parse_id
+from digest_publisher import recovery
+from digest_publisher.publishers import get_publisher
+from .polls import MAX_LIMIT, int_param
+from .server import HTTPError, HTTPServer, Request, Response, json_response
+
+logger = logging.getLogger(__name__)
+
+PREFIX = "/publish-failures/"
+STATUSES = ("pending", "sending", "failed", "sent", "discarded")
+
+
+def failure_view(failure: PublishFailure) -> dict:
+    """A failure as returned by the API."""
+    data = failure.to_dict()
+    # Webhook destinations are credentials, so only their label is shown
+    data["target"] = get_publisher(failure.publisher).label(data.pop("destination"))
+    return data
+
+
+async def list_failures(request: Request) -> Response:
+    """List failures with a status, newest first; open ones by default."""
+    status = request.query.get("status")
+    if status is not None and status not in STATUSES:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"'status' must be one of {', '.join(STATUSES)}")
+    limit = min(int_param(request, "limit", 100), MAX_LIMIT)
+    statuses = [status] if status else recovery.OPEN_STATUSES
+    failures = await PublishFailureRepository.get_by_status(statuses, limit)
+    return json_response([failure_view(failure) for failure in failures])
+
+
+async def act(request: Request) -> Response:
+    """Retry or discard a failure."""
+    failure_id, _, action = request.path[len(PREFIX) :].partition("/")
+    failure_id = parse_id(failure_id)
+    if not failure_id or action not in ("retry", "discard"):
+        raise HTTPError(HTTPStatus.NOT_FOUND, "Not found")
+    failure = await PublishFailureRepository.get_by_id(failure_id)
+    if failure is None:
+        raise HTTPError(HTTPStatus.NOT_FOUND, f"Failure #{failure_id} not found")
+    if failure.status not in recovery.RETRYABLE_STATUSES:
+        raise HTTPError(HTTPStatus.CONFLICT, f"Failure #{failure.id} is {failure.status}")
+
+    if action == "discard":
+        if not await PublishFailureRepository.set_status(
+            failure.id, "discarded", [failure.status]
+        ):
+            raise HTTPError(HTTPStatus.CONFLICT, f"Failure #{failure.id} was changed meanwhile")
+        logger.info(f"Discarded failed message #{failure.id}")
+        return json_response(failure_view(await PublishFailureRepository.get_by_id(failure.id)))
+
+    sent = await recovery.retry(failure)
+    if sent is None:
+        raise HTTPError(HTTPStatus.CONFLICT, f"Failure #{failure.id} was changed meanwhile")
+    failure = await PublishFailureRepository.get_by_id(failure.id)
+    status = HTTPStatus.OK if sent else HTTPStatus.BAD_GATEWAY
+    return json_response(failure_view(failure), status=status)
+
+
+def register(server: HTTPServer) -> None:
+    """Register publish failure routes."""
+    server.add_route("GET", "/publish-failures", list_failures)
+    server.add_prefix_route("POST", PREFIX, act)
@@ -0,0 +1,28 @@
+"""Quotas of external APIs.
+
+The pipeline saves the rate limits its agents last saw in the responses of
+Telegram, OpenAI, webhooks and Mastodon (see common.quotas):
+
+- GET /quotas  every quota, with whether it's low
+"""
+
+from common import clock
+from common.db.repository import QuotaRepository
+from common.quotas import is_low
+from .server import HTTPServer, Request, Response, json_response
+
+
+async def list_quotas(request: Request) -> Response:
+    """List the saved quotas by API and name."""
+    now = clock.now()
+    return json_response(
+        [
+            {**quota.to_dict(), "low": is_low(quota, now)}
+            for quota in await QuotaRepository.get_all()
+        ]
+    )
+
+
+def register(server: HTTPServer) -> None:
+    """Register quota routes."""
+    server.add_route("GET", "/quotas", list_quotas)
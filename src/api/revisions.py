@@ -0,0 +1,56 @@
+"""Edits of stored posts.
+
+The reader keeps every edit a source makes to a stored post as a diff
+against the previous revision (see common.revisions):
+
+- GET /posts/revisions?link=URL               revisions of a post (0 is the stored text)
+- GET /posts/revisions?link=URL&revision=N    text of the post at revision N
+"""
+
+from http import HTTPStatus
+
+from common.db.repository import PostRevisionRepository, RSSPostRepository
+from common.revisions import content_hash, rebuild
+from .server import HTTPError, HTTPServer, Request, Response, json_response
+
+
+async def list_revisions(request: Request) -> Response:
+    """List the revisions of a post, or rebuild the text of one of them."""
+    link = request.query.get("link")
+    if not link:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "Query must include 'link'")
+    post = await RSSPostRepository.get_by_link(link)
+    if not post:
+        raise HTTPError(HTTPStatus.NOT_FOUND, f"No post with link {link}")
+    revisions = await PostRevisionRepository.get_by_link(link)
+
+    number = request.query.get("revision")
+    if number is None:
+        stored = {"revision": 0, "content_hash": content_hash(post.content)}
+        return json_response(
+            [{**stored, "created_at": post.created_at, "delta_size": 0}]
+            + [
+                {
+                    "revision": revision.revision,
+                    "content_hash": revision.content_hash,
+                    "created_at": revision.created_at,
+                    "delta_size": len(revision.delta),
+                }
+                for revision in revisions
+            ]
+        )
+
+    try:
+        number = int(number)
+    except ValueError:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'revision' must be an integer")
+    try:
+        content = rebuild(post, revisions, number)
+    except ValueError as error:
+        raise HTTPError(HTTPStatus.NOT_FOUND, str(error))
+    return json_response({"link": link, "revision": number, "content": content})
+
+
+def register(server: HTTPServer) -> None:
+    """Register post revision routes."""
+    server.add_route("GET", "/posts/revisions", list_revisions)
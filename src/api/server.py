@@ -0,0 +1,205 @@
+"""Minimal asyncio HTTP/1.1 server with JSON helpers.
+
+The API only needs a handful of JSON endpoints, so a small server on top of
+asyncio streams keeps the service free of extra web framework dependencies.
+"""
+
+import asyncio
+import json
+import logging
+from dataclasses import dataclass, field
+from http import HTTPStatus
+from typing import Any, Awaitable, Callable, Dict, Optional, Tuple
+from urllib.parse import parse_qs, urlsplit
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class Request:
+    """Parsed HTTP request."""
+
+    method: str
+    path: str
+    query: Dict[str, str] = field(default_factory=dict)
+    headers: Dict[str, str] = field(default_factory=dict)
+    body: bytes = b""
+    client: str = ""
+
+    def json(self) -> Any:
+        """Decode the request body as JSON (empty body decodes to an empty dict)."""
+        if not self.body:
+            return {}
+        try:
+            return json.loads(self.body)
+        except ValueError as e:
+            raise HTTPError(HTTPStatus.BAD_REQUEST, f"Invalid JSON body: {e}")
+
+
+@dataclass
+class Response:
+    """HTTP response."""
+
+    status: int = HTTPStatus.OK
+    body: bytes = b""
+    content_type: str = "application/json; charset=utf-8"
+    headers: Dict[str, str] = field(default_factory=dict)
+
+
+class HTTPError(Exception):
+    """Error that is rendered as a JSON error response."""
+
+    def __init__(self, status: int, message: str):
+        super().__init__(message)
+        self.status = status
+        self.message = message
+
+
+def json_response(data: Any, status: int = HTTPStatus.OK) -> Response:
+    """Build a JSON response."""
+    body = json.dumps(data, ensure_ascii=False, default=str).encode()
+    return Response(status=status, body=body)
+
+
+Handler = Callable[[Request], Awaitable[Response]]
+
+
+class HTTPServer:
+    """Tiny router + HTTP/1.1 server (one request per connection)."""
+
+    def __init__(self, max_body_bytes: int = 1024 * 1024, request_timeout: int = 30):
+        """
+        Initialize HTTP server.
+
+        Args:
+            max_body_bytes: Maximum accepted request body size
+            request_timeout: Seconds allowed to read a request and produce a response
+        """
+        self.routes: Dict[Tuple[str, str], Handler] = {}
+        self.prefix_routes: Dict[Tuple[str, str], Handler] = {}
+        self.middlewares: list[Callable[[Request], Optional[Response]]] = []
+        self.max_body_bytes = max_body_bytes
+        self.request_timeout = request_timeout
+
+    def add_route(self, method: str, path: str, handler: Handler) -> None:
+        """Register a handler for an exact method and path."""
+        self.routes[(method.upper(), path)] = handler
+
+    def add_prefix_route(self, method: str, prefix: str, handler: Handler) -> None:
+        """Register a handler for every path under a prefix (exact routes take precedence)."""
+        self.prefix_routes[(method.upper(), prefix)] = handler
+
+    def _find_handler(self, method: str, path: str) -> Optional[Handler]:
+        handler = self.routes.get((method, path))
+        if handler is not None:
+            return handler
+        matches = [
+            (prefix, handler)
+            for (route_method, prefix), handler in self.prefix_routes.items()
+            if route_method == method and path.startswith(prefix)
+        ]
+        return max(matches, key=lambda match: len(match[0]))[1] if matches else None
+
+    def add_middleware(self, middleware: Callable[[Request], Optional[Response]]) -> None:
+        """Register a check that may short-circuit a request by returning a Response."""
+        self.middlewares.append(middleware)
+
+    async def dispatch(self, request: Request) -> Response:
+        """Route a request to its handler."""
+        for middleware in self.middlewares:
+            response = middleware(request)
+            if response is not None:
+                return response
+
+        handler = self._find_handler(request.method, request.path)
+        if handler is None:
+            if any(path == request.path for _, path in self.routes) or any(
+                request.path.startswith(prefix) for _, prefix in self.prefix_routes
+            ):
+                raise HTTPError(HTTPStatus.METHOD_NOT_ALLOWED, "Method not allowed")
+            raise HTTPError(HTTPStatus.NOT_FOUND, "Not found")
+
+        return await handler(request)
+
+    async def _read_request(self, reader: asyncio.StreamReader, client: str) -> Request:
+        """Read and parse a single HTTP request."""
+        head = await reader.readuntil(b"\r\n\r\n")
+        lines = head.decode("latin-1").split("\r\n")
+        try:
+            method, target, _ = lines[0].split(" ", 2)
+        except ValueError:
+            raise HTTPError(HTTPStatus.BAD_REQUEST, "Malformed request line")
+
+        headers = {}
+        for line in lines[1:]:
+            if ":" in line:
+                name, value = line.split(":", 1)
+                headers[name.strip().lower()] = value.strip()
+
+        content_length = headers.get("content-length") or "0"
+        # int() would also take "-1", "+1" or " 1_0 "
+        if not (content_length.isascii() and content_length.isdigit()):
+            raise HTTPError(HTTPStatus.BAD_REQUEST, "Invalid Content-Length")
+        length = int(content_length)
+        if length > self.max_body_bytes:
+            raise HTTPError(HTTPStatus.REQUEST_ENTITY_TOO_LARGE, "Request body too large")
+        body = await reader.readexactly(length) if length else b""
+
+        url = urlsplit(target)
+        query = {key: values[-1] for key, values in parse_qs(url.query).items()}
+        return Request(
+            method=method.upper(),
+            path=url.path or "/",
+            query=query,
+            headers=headers,
+            body=body,
+            client=client,
+        )
+
+    async def _handle_connection(
+        self, reader: asyncio.StreamReader, writer: asyncio.StreamWriter
+    ) -> None:
+        """Serve one request on an accepted connection."""
+        peer = writer.get_extra_info("peername")
+        client = peer[0] if peer else ""
+        try:
+            async with asyncio.timeout(self.request_timeout):
+                try:
+                    request = await self._read_request(reader, client)
+                    response = await self.dispatch(request)
+                except HTTPError as e:
+                    response = json_response({"error": e.message}, status=e.status)
+                except (asyncio.IncompleteReadError, asyncio.LimitOverrunError):
+                    return
+                except Exception as e:
+                    logger.error(f"Unhandled error while serving request: {e}", exc_info=True)
+                    response = json_response(
+                        {"error": "Internal server error"}, status=HTTPStatus.INTERNAL_SERVER_ERROR
+                    )
+                await self._write_response(writer, response)
+        except TimeoutError:
+            logger.warning(f"Request from {client} timed out")
+        finally:
+            writer.close()
+
+    @staticmethod
+    async def _write_response(writer: asyncio.StreamWriter, response: Response) -> None:
+        """Serialize a response onto the connection."""
+        status = HTTPStatus(response.status)
+        headers = {
+            "Content-Type": response.content_type,
+            "Content-Length": str(len(response.body)),
+            "Connection": "close",
+            **response.headers,
+        }
+        head = f"HTTP/1.1 {status.value} {status.phrase}\r\n"
+        head += "".join(f"{name}: {value}\r\n" for name, value in headers.items())
+        writer.write(head.encode("latin-1") + b"\r\n" + response.body)
+        await writer.drain()
+
+    async def serve(self, host: str, port: int) -> None:
+        """Listen on host:port until cancelled."""
+        server = await asyncio.start_server(self._handle_connection, host, port)
+        logger.info(f"HTTP API listening on http://{host}:{port}")
+        async with server:
+            await server.serve_forever()
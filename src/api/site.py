@@ -0,0 +1,225 @@
+"""Public event pages.
+
+Serves the same pages as `python -m src.export site`, rendered on demand
+from the event store, plus crawler endpoints:
+
+- GET /, /index.html      calendar of events
+- GET /events/<slug>.html event page with Open Graph tags and Event JSON-LD
+- GET /go/<slug>          redirect to the source post, counted as a click
+- GET /og/<slug>.png      Open Graph card for an event (API_OG_IMAGES_ENABLED)
+- GET /style.css          stylesheet
+- GET /sitemap.xml        all event pages, for search engines
+- GET /robots.txt         points crawlers at the sitemap
+
+These routes are public: they are exempt from API_TOKEN. Page views and
+clicks are counted per event and day for `python -m src.export interests`;
+nothing about the visitor is stored.
+"""
+
+import logging
+import time
+from datetime import timedelta
+from http import HTTPStatus
+from typing import Callable, Dict, List, Optional
+
+from common import clock
+from common.db.models import RSSPost, Series
+from common.db.repository import InteractionRepository, RSSPostRepository
+from common.db.session import reads_from_replicas
+from common.og_images import OGImageError, OGImageRenderer, card_details
+from common.pages import (
+    STYLE,
+    SiteSettings,
+    event_path,
+    event_title,
+    event_when,
+    og_image_path,
+    render_event_page,
+    render_index,
+    render_robots,
+    render_sitemap,
+)
+from common.series import load_series
+from common.utils.links import post_slug
+from .config import api_settings
+from .server import HTTPError, HTTPServer, Request, Response
+
+logger = logging.getLogger(__name__)
+
+PUBLIC_PATHS = {"/", "/index.html", "/style.css", "/sitemap.xml", "/robots.txt"}
+EVENTS_PREFIX = "/events/"
+OG_PREFIX = "/og/"
+GO_PREFIX = "/go/"
+
+
+def is_public(path: str) -> bool:
+    """Check whether a path is served without authentication."""
+    return path in PUBLIC_PATHS or path.startswith((EVENTS_PREFIX, OG_PREFIX, GO_PREFIX))
+
+
+class EventCache:
+    """Recent events, reloaded at most once per TTL."""
+
+    def __init__(self, ttl_seconds: float, clock: Callable[[], float] = time.monotonic):
+        self.ttl_seconds = ttl_seconds
+        self.clock = clock
+        self.loaded_at: Optional[float] = None
+        self.posts: List[RSSPost] = []
+        self.series: Dict[str, Series] = {}
+        self.by_path: Dict[str, RSSPost] = {}
+
+    def set(self, posts: List[RSSPost], series: Optional[Dict[str, Series]] = None) -> None:
+        """Replace cached events and their series."""
+        self.posts = posts
+        self.series = series or {}
+        self.by_path = {}
+        for post in posts:
+            self.by_path[f"/{event_path(post)}"] = post
+            self.by_path[f"/{og_image_path(post)}"] = post
+            self.by_path[f"{GO_PREFIX}{post_slug(post.link)}"] = post
+        self.loaded_at = self.clock()
+
+    def is_fresh(self) -> bool:
+        """Check whether cached events can be used without reloading."""
+        return self.loaded_at is not None and self.clock() - self.loaded_at < self.ttl_seconds
+
+
+cache = EventCache(api_settings.site_cache_seconds)
+renderer = OGImageRenderer(
+    api_settings.media_dir, api_settings.og_render_command, api_settings.og_render_timeout
+)
+
+
+async def load_events() -> EventCache:
+    """Get recent events, reloading them from the database when the cache is stale."""
+    if not cache.is_fresh():
+        end_date = clock.now()
+        start_date = end_date - timedelta(days=api_settings.site_days_back)
+        posts = await RSSPostRepository.get_by_date_range(
+            start_date, end_date, limit=api_settings.site_max_events, only_unpublished=False
+        )
+        cache.set(posts, await load_series(posts))
+    return cache
+
+
+def site_for(request: Request) -> SiteSettings:
+    """
+    Site settings for a request.
+
+    Without SITE_BASE_URL, absolute URLs are built from the Host header (and
+    X-Forwarded-Proto behind a proxy).
+    """
+    base_url = api_settings.site_base_url
+    host = request.headers.get("host", "")
+    if not base_url and host:
+        scheme = request.headers.get("x-forwarded-proto", "http")
+        base_url = f"{scheme}://{host}"
+    return SiteSettings(
+        title=api_settings.site_title,
+        base_url=base_url,
+        language=api_settings.site_language,
+        og_images=api_settings.og_images_enabled,
+    )
+
+
+def text_response(body: str, content_type: str) -> Response:
+    """Build a text response with a short public cache lifetime."""
+    return Response(
+        status=HTTPStatus.OK,
+        body=body.encode(),
+        content_type=f"{content_type}; charset=utf-8",
+        headers={"Cache-Control": f"public, max-age={api_settings.site_cache_seconds}"},
+    )
+
+
+async def count_interaction(post: RSSPost, kind: str) -> None:
+    """Count a view or click; failures never break the page."""
+    try:
+        await InteractionRepository.record(post.link, kind)
+    except Exception as e:
+        logger.warning(f"Failed to count {kind} of {post.link}: {e}")
+
+
+async def index(request: Request) -> Response:
+    """Calendar of events."""
+    events = await load_events()
+    return text_response(
+        render_index(events.posts, site_for(request), events.series), "text/html"
+    )
+
+
+async def event_page(request: Request) -> Response:
+    """Page of a single event."""
+    events = await load_events()
+    post = events.by_path.get(request.path)
+    if post is None or not request.path.startswith(EVENTS_PREFIX):
+        raise HTTPError(HTTPStatus.NOT_FOUND, "Event not found")
+    await count_interaction(post, "view")
+    series = events.series.get(post.series_key or "")
+    source = f"..{GO_PREFIX}{post_slug(post.link)}"
+    return text_response(
+        render_event_page(post, site_for(request), series, source_url=source), "text/html"
+    )
+
+
+async def go(request: Request) -> Response:
+    """Redirect to the source post of an event."""
+    events = await load_events()
+    post = events.by_path.get(request.path)
+    if post is None or not request.path.startswith(GO_PREFIX):
+        raise HTTPError(HTTPStatus.NOT_FOUND, "Event not found")
+    await count_interaction(post, "click")
+    return Response(
+        status=HTTPStatus.FOUND,
+        headers={"Location": post.link, "Cache-Control": "no-store"},
+    )
+
+
+async def og_image(request: Request) -> Response:
+    """Open Graph card of a single event, rendered once and cached in media storage."""
+    events = await load_events()
+    post = events.by_path.get(request.path)
+    if post is None or not request.path.startswith(OG_PREFIX):
+        raise HTTPError(HTTPStatus.NOT_FOUND, "Event not found")
+
+    try:
+        path = await renderer.render(post, event_title(post), card_details(post, event_when(post)))
+    except OGImageError as e:
+        logger.error(f"Failed to render Open Graph image for {post.link}: {e}")
+        raise HTTPError(HTTPStatus.SERVICE_UNAVAILABLE, "Image is not available")
+
+    return Response(
+        status=HTTPStatus.OK,
+        body=path.read_bytes(),
+        content_type="image/png",
+        headers={"Cache-Control": "public, max-age=86400"},
+    )
+
+
+async def stylesheet(request: Request) -> Response:
+    """Site stylesheet."""
+    return text_response(STYLE, "text/css")
+
+
+async def sitemap(request: Request) -> Response:
+    """sitemap.xml with all event pages."""
+    events = await load_events()
+    return text_response(render_sitemap(events.posts, site_for(request)), "application/xml")
+
+
+async def robots(request: Request) -> Response:
+    """robots.txt pointing at the sitemap."""
+    return text_response(render_robots(site_for(request)), "text/plain")
+
+
+def register(server: HTTPServer) -> None:
+    """Register public site routes."""
+    server.add_route("GET", "/", reads_from_replicas(index))
+    server.add_route("GET", "/index.html", reads_from_replicas(index))
+    server.add_route("GET", "/style.css", stylesheet)
+    server.add_route("GET", "/sitemap.xml", reads_from_replicas(sitemap))
+    server.add_route("GET", "/robots.txt", robots)
+    server.add_prefix_route("GET", EVENTS_PREFIX, reads_from_replicas(event_page))
+    server.add_prefix_route("GET", GO_PREFIX, reads_from_replicas(go))
+    if api_settings.og_images_enabled:
+        server.add_prefix_route("GET", OG_PREFIX, reads_from_replicas(og_image))
@@ -0,0 +1,28 @@
+"""Event reach statistics.
+
+Private (behind API_TOKEN) totals of how far each event got, summed over
+its event page and every post that announced it (reblogs of the Mastodon
+statuses):
+
+- GET /stats/reach[?days=&limit=]   events of the last days, widest reach first
+"""
+
+from datetime import timedelta
+
+from common import clock
+from common.db.repository import InteractionRepository
+from .polls import MAX_LIMIT, int_param
+from .server import HTTPServer, Request, Response, json_response
+
+
+async def reach(request: Request) -> Response:
+    """List the reach of the events published in the last days."""
+    days = min(int_param(request, "days", 30), 366)
+    limit = min(int_param(request, "limit", 50), MAX_LIMIT)
+    since = clock.now() - timedelta(days=days)
+    return json_response(await InteractionRepository.get_reach(since, limit))
+
+
+def register(server: HTTPServer) -> None:
+    """Register statistics routes."""
+    server.add_route("GET", "/stats/reach", reach)
@@ -0,0 +1,202 @@
+"""Events submitted by organizers.
+
+With API_SUBMISSIONS_ENABLED=true the public API accepts events from
+organizers:
+
+- POST /v1/submissions  {"link": ..., "content": ..., "title": ..., "contact": ...,
+                         "captcha": ...}
+
+A submission needs either a partner token (X-Submission-Token, one per
+partner in API_SUBMISSION_TOKENS) or a solved captcha (API_CAPTCHA_SECRET),
+and every client may submit API_SUBMISSION_RATE_LIMIT events per hour.
+Submissions aren't posts yet: they wait in a moderation queue, reviewed with
+private (behind API_TOKEN) endpoints:
+
+- GET  /submissions[?status=pending|approved|rejected]
+- POST /submissions/<id>/approve
+- POST /submissions/<id>/reject  {"note": ...}
+
+An approved submission is stored like a manual event, from the 'submitted'
+source and tagged 'submitted', so rules can treat organizers' own
+announcements differently.
+"""
+
+import asyncio
+import json
+import logging
+from http import HTTPStatus
+from typing import Optional
+
+import requests
+
+from common.db.models import Submission
+from common.db.repository import SubmissionRepository
+from common.ids import parse_id
+from common.models.feed import RSSItem
+from .config import api_settings
+from .manual import add_event
+from .polls import MAX_LIMIT, int_param
+from .public import PREFIX, RateLimiter, client_address
+from .server import HTTPError, HTTPServer, Request, Response, json_response
+from .tokens import token_owner
+
+logger = logging.getLogger(__name__)
+
+SUBMISSIONS_PATH = f"{PREFIX}/submissions"
+SOURCE_NAME = "submitted"
+SUBMITTED_TAG = "submitted"
+STATUSES = ("pending", "approved", "rejected")
+
+MAX_CONTENT_LENGTH = 5000
+MAX_FIELD_LENGTH = 500
+
+limiter = RateLimiter(api_settings.submission_rate_limit, window_seconds=3600)
+
+
+async def verify_captcha(response: str, client: str) -> bool:
+    """Check a captcha response with the provider's siteverify endpoint."""
+    try:
+        result = await asyncio.to_thread(
+            requests.post,
+            api_settings.captcha_verify_url,
+            data={"secret": api_settings.captcha_secret, "response": response, "remoteip": client},
+            timeout=10,
+        )
+        result.raise_for_status()
+        return result.json().get("success") is True
+    except Exception as e:
+        logger.error(f"Captcha verification failed: {e}")
+        return False
+
+
+def text_field(data: dict, name: str, max_length: int, required: bool = False) -> Optional[str]:
+    """Read a string field of a submission."""
+    value = data.get(name)
+    if isinstance(value, str):
+        value = value.strip()
+    if value is None or value == "":
+        if required:
+            raise HTTPError(HTTPStatus.BAD_REQUEST, f"'{name}' is required")
+        return None
+    if not isinstance(value, str):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"'{name}' must be a string")
+    if len(value) > max_length:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"'{name}' must be at most {max_length} characters")
+    return value
+
+
+def parse_submission(data: dict) -> Submission:
+    """Validate a submission."""
+    if not isinstance(data, dict):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "Body must be a JSON object")
+    link = text_field(data, "link", MAX_FIELD_LENGTH, required=True)
+    if not link.startswith(("http://", "https://")):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'link' must be an http(s) URL of the event")
+    return Submission(
+        link=link,
+        content=text_field(data, "content", MAX_CONTENT_LENGTH, required=True),
+        title=text_field(data, "title", MAX_FIELD_LENGTH),
+        contact=text_field(data, "contact", MAX_FIELD_LENGTH),
+    )
+
+
+def too_many_requests(retry_after: float) -> Response:
+    """Build a 429 response."""
+    return Response(
+        status=HTTPStatus.TOO_MANY_REQUESTS,
+        body=json.dumps({"error": "Too many submissions"}).encode(),
+        headers={"Retry-After": str(max(1, int(retry_after + 0.999)))},
+    )
+
+
+async def submit(request: Request) -> Response:
+    """Queue an organizer's event for moderation."""
+    data = request.json()
+    token = request.headers.get("x-submission-token", "")
+    partner = token_owner(token, api_settings.submission_tokens) if token else None
+    if token and partner is None:
+        raise HTTPError(HTTPStatus.FORBIDDEN, "Unknown submission token")
+
+    # Partners are limited by name, everyone else by address
+    retry_after = limiter.check(f"partner:{partner}" if partner else client_address(request))
+    if retry_after is not None:
+        return too_many_requests(retry_after)
+
+    if partner is None:
+        captcha = data.get("captcha") if isinstance(data, dict) else None
+        if not api_settings.captcha_secret or not isinstance(captcha, str) or not captcha:
+            raise HTTPError(HTTPStatus.FORBIDDEN, "A submission token or captcha is required")
+        if not await verify_captcha(captcha, client_address(request)):
+            raise HTTPError(HTTPStatus.FORBIDDEN, "Captcha verification failed")
+
+    submission = parse_submission(data)
+    submission.partner = partner
+    if await SubmissionRepository.is_pending(submission.link):
+        raise HTTPError(HTTPStatus.CONFLICT, "This event is already waiting for moderation")
+
+    submission_id = await SubmissionRepository.create(submission)
+    logger.info(f"Queued submission #{submission_id} of {submission.link}")
+    return json_response({"id": submission_id, "status": "pending"}, status=HTTPStatus.ACCEPTED)
+
+
+async def list_submissions(request: Request) -> Response:
+    """List submissions with a status, oldest first."""
+    status = request.query.get("status", "pending")
+    if status not in STATUSES:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, f"'status' must be one of {', '.join(STATUSES)}")
+    limit = min(int_param(request, "limit", 100), MAX_LIMIT)
+    submissions = await SubmissionRepository.get_by_status(status, limit)
+    return json_response([submission.to_dict() for submission in submissions])
+
+
+async def pending_submission(path: str, action: str) -> Submission:
+    """Find the pending submission a review path refers to."""
+    submission_id = parse_id(path[len("/submissions/") : -len(action) - 1])
+    if not submission_id:
+        raise HTTPError(HTTPStatus.NOT_FOUND, "Not found")
+    submission = await SubmissionRepository.get_by_id(submission_id)
+    if submission is None:
+        raise HTTPError(HTTPStatus.NOT_FOUND, f"Submission #{submission_id} not found")
+    if submission.status != "pending":
+        raise HTTPError(HTTPStatus.CONFLICT, f"Submission #{submission_id} is {submission.status}")
+    return submission
+
+
+async def approve(submission: Submission) -> Response:
+    """Store a submission as an event."""
+    item = RSSItem(
+        link=submission.link,
+        description=submission.content,
+        pub_date=submission.created_at.isoformat(),
+    )
+    post = await add_event(SOURCE_NAME, item, [SUBMITTED_TAG], submission.title)
+    await SubmissionRepository.review(submission.id, "approved")
+    logger.info(f"Approved submission #{submission.id} as {post.link}")
+    return json_response(post.to_dict(), status=HTTPStatus.CREATED)
+
+
+async def review(request: Request) -> Response:
+    """Approve or reject a submission."""
+    if request.path.endswith("/approve"):
+        return await approve(await pending_submission(request.path, "approve"))
+    if not request.path.endswith("/reject"):
+        raise HTTPError(HTTPStatus.NOT_FOUND, "Not found")
+
+    submission = await pending_submission(request.path, "reject")
+    data = request.json()
+    note = data.get("note") if isinstance(data, dict) else None
+    if note is not None and not isinstance(note, str):
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "'note' must be a string")
+    await SubmissionRepository.review(submission.id, "rejected", note or None)
+    return json_response({"id": submission.id, "status": "rejected"})
+
+
+def register_public(server: HTTPServer) -> None:
+    """Register the submission route of the public API."""
+    server.add_route("POST", SUBMISSIONS_PATH, submit)
+
+
+def register(server: HTTPServer) -> None:
+    """Register moderation routes."""
+    server.add_route("GET", "/submissions", list_submissions)
+    server.add_prefix_route("POST", "/submissions/", review)
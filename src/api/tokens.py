@@ -0,0 +1,28 @@
+"""Named tokens for clients other than the admin.
+
+Partners and collectors get a token each, configured as comma-separated
+name:token pairs, e.g. API_SUBMISSION_TOKENS=club:s3cret,museum:t0ken.
+"""
+
+import hmac
+from typing import Dict, Optional
+
+
+def parse_tokens(pairs: str) -> Dict[str, str]:
+    """Names by token from name:token pairs."""
+    tokens = {}
+    for pair in pairs.split(","):
+        name, _, token = pair.strip().partition(":")
+        if name and token:
+            tokens[token] = name
+    return tokens
+
+
+def token_owner(token: str, pairs: str) -> Optional[str]:
+    """Name a token belongs to, or None for an unknown token."""
+    owner = None
+    for known, name in parse_tokens(pairs).items():
+        # Compare with every token, so timing doesn't reveal which one matched
+        if hmac.compare_digest(token.encode(), known.encode()):
+            owner = name
+    return owner
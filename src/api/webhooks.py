@@ -0,0 +1,104 @@
+"""Posts pushed by external collectors.
+
+Scrapers that run elsewhere can push what they collected instead of being
+polled as external sources:
+
+- POST /webhooks/<source>  a post object or a list of them, in the external
+                           source format (see rss_reader.core.external)
+
+Each source has its own token in API_WEBHOOK_TOKENS, sent in the
+X-Webhook-Token header; these routes don't use API_TOKEN, so collectors
+never get the admin token. Pushed posts are saved like polled ones: the
+same filters, deduplication by link and detection apply, and each push is
+recorded as a poll run, so its outcomes show up in /poll-runs.
+"""
+
+import logging
+from http import HTTPStatus
+from typing import List
+
+from common.db.repository import PollRunRepository
+from common.models.feed import RSSItem
+from common.rules import load_rules
+from rss_reader.core.external import parse_item
+from rss_reader.core.filters import RuleFilter, load_filters
+from rss_reader.core.report import PollReport
+from .config import api_settings
+from .server import HTTPError, HTTPServer, Request, Response, json_response
+from .tokens import token_owner
+
+logger = logging.getLogger(__name__)
+
+PREFIX = "/webhooks/"
+
+
+def parse_items(data: object) -> List[RSSItem]:
+    """Validate pushed posts and convert them to feed items."""
+    posts = data if isinstance(data, list) else [data]
+    if not posts:
+        raise HTTPError(HTTPStatus.BAD_REQUEST, "No posts in the body")
+    if len(posts) > api_settings.webhook_max_items:
+        raise HTTPError(
+            HTTPStatus.REQUEST_ENTITY_TOO_LARGE,
+            f"At most {api_settings.webhook_max_items} posts per request",
+        )
+    items = []
+    for number, post in enumerate(posts):
+        link = post.get("link") if isinstance(post, dict) else None
+        if not isinstance(link, str) or not link.startswith(("http://", "https://")):
+            raise HTTPError(HTTPStatus.BAD_REQUEST, f"Post {number}: 'link' must be an http(s) URL")
+        content = post.get("content", post.get("description", ""))
+        media_urls = post.get("media_urls") or []
+        if not isinstance(content, str):
+            raise HTTPError(HTTPStatus.BAD_REQUEST, f"Post {number}: 'content' must be a string")
+        if not isinstance(media_urls, list) or not all(isinstance(u, str) for u in media_urls):
+            raise HTTPError(
+                HTTPStatus.BAD_REQUEST, f"Post {number}: 'media_urls' must be a list of strings"
+            )
+        items.append(parse_item(post))
+    return items
+
+
+async def receive(request: Request) -> Response:
+    """Save posts pushed by a collector."""
+    # Imported here, like the pipeline does, to keep the reader's entry point out of API startup
+    from rss_reader.__main__ import save_items, save_report
+
+    source = request.path[len(PREFIX) :]
+    token = request.headers.get("x-webhook-token", "")
+    if not source or not token or token_owner(token, api_settings.webhook_tokens) != source:
+        raise HTTPError(HTTPStatus.UNAUTHORIZED, "Invalid webhook token")
+
+    items = parse_items(request.json())
+    report = PollReport()
+    run_id = await PollRunRepository.start()
+    await save_items(source, items, [RuleFilter(load_rules()), load_filters()], report)
+    await save_report(run_id, report)
+    # The report of a delivery is stored like a poll run's, under its own ID
+    counts = ", ".join(f"{outcome}: {count}" for outcome, count in report.counts().items())
+    logger.info(
+        f"Webhook {source}: delivery {run_id} with {len(items)} posts ({counts or 'none'})"
+    )
+    for item in report.items:
+        logger.debug(f"Webhook {source}: delivery {run_id}: {item.link} {item.outcome}")
+
+    return json_response(
+        {
+            "run": run_id,
+            "counts": report.counts(),
+            "items": [
+                {"link": item.link, "outcome": item.outcome, "reason": item.reason}
+                for item in report.items
+            ],
+        }
+    )
+
+
+def is_webhook(path: str) -> bool:
+    """Check whether a path is a webhook, authenticated by its own token."""
+    return path.startswith(PREFIX)
+
+
+def register(server: HTTPServer) -> None:
+    """Register webhook routes."""
+    server.add_prefix_route("POST", PREFIX, receive)
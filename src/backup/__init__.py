@@ -0,0 +1 @@
+"""Backup Service - Dumps and restores platform data as portable archives."""
@@ -0,0 +1,178 @@
+"""Entry point for Backup service.
+
+Run with:
+    python -m src.backup backup [--out PATH]     # Dump data into an archive
+    python -m src.backup restore PATH            # Load data from an archive
+"""
+
+import argparse
+import asyncio
+import logging
+import sys
+
+from common import clock
+from common.db.session import db
+from common.db.repository import (
+    FieldOverrideRepository,
+    PostRevisionRepository,
+    RSSPostRepository,
+    SeriesRepository,
+    TelegramChannelRepository,
+)
+from .archive import BackupReader, BackupWriter
+
+logging.basicConfig(
+    level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
+)
+logger = logging.getLogger(__name__)
+
+BATCH_SIZE = 1000
+
+
+def parse_args():
+    """Parse command line arguments."""
+    parser = argparse.ArgumentParser(
+        description="Back up and restore event platform data",
+    )
+    subparsers = parser.add_subparsers(dest="command", required=True)
+
+    backup_parser = subparsers.add_parser("backup", help="Dump data into a portable archive")
+    backup_parser.add_argument(
+        "--out",
+        metavar="PATH",
+        default=f"event-platform-backup-{clock.now():%Y%m%d-%H%M%S}.tar.gz",
+        help="Archive path (default: event-platform-backup-<timestamp>.tar.gz)",
+    )
+
+    restore_parser = subparsers.add_parser("restore", help="Load data from an archive")
+    restore_parser.add_argument("path", metavar="PATH", help="Archive to restore")
+    restore_parser.add_argument(
+        "--dry-run",
+        action="store_true",
+        help="Validate the archive and print counts without writing to the database",
+    )
+
+    return parser.parse_args()
+
+
+async def backup(path: str) -> dict:
+    """
+    Dump channels, series, posts and their corrections and revisions into an archive.
+
+    Args:
+        path: Archive path
+
+    Returns:
+        Record counts per archive file
+    """
+    writer = BackupWriter(path)
+
+    for channel in await TelegramChannelRepository.get_all():
+        writer.add_channel(channel)
+
+    for series in await SeriesRepository.get_all():
+        writer.add_series(series)
+
+    since, after_link = None, ""
+    while True:
+        posts = await RSSPostRepository.get_updated_since(since, after_link, limit=BATCH_SIZE)
+        for post in posts:
+            writer.add_post(post)
+        links = [post.link for post in posts]
+        for override in await FieldOverrideRepository.get_by_links(links):
+            writer.add_override(override)
+        for revision in await PostRevisionRepository.get_by_links(links):
+            writer.add_revision(revision)
+        if len(posts) < BATCH_SIZE:
+            break
+        since, after_link = posts[-1].updated_at, posts[-1].link
+
+    writer.close()
+    logger.info(f"Backup written to {path}: {writer.counts}")
+    return writer.counts
+
+
+async def restore(path: str, dry_run: bool = False) -> dict:
+    """
+    Load channels, series, posts and their corrections and revisions from an
+    archive, overwriting existing records.
+
+    Args:
+        path: Archive path
+        dry_run: Only count records, don't write them
+
+    Returns:
+        Number of restored channels, series, posts, corrections and revisions
+    """
+    reader = BackupReader(path)
+    logger.info(f"Restoring backup created at {reader.manifest.get('created_at')}")
+
+    counts = {"channels": 0, "series": 0, "posts": 0, "overrides": 0, "revisions": 0}
+    try:
+        for channel in reader.channels():
+            if not dry_run:
+                await TelegramChannelRepository.upsert(channel)
+            counts["channels"] += 1
+
+        # Before posts, which reference them
+        for series in reader.series():
+            if not dry_run:
+                await SeriesRepository.upsert(series)
+            counts["series"] += 1
+
+        for post in reader.posts():
+            if not dry_run:
+                await RSSPostRepository.upsert(post)
+            counts["posts"] += 1
+
+        # After posts, which they belong to
+        for override in reader.overrides():
+            if not dry_run:
+                await FieldOverrideRepository.set(override)
+            counts["overrides"] += 1
+
+        for revision in reader.revisions():
+            if not dry_run:
+                await PostRevisionRepository.add(revision)
+            counts["revisions"] += 1
+    finally:
+        reader.close()
+
+    logger.info(f"{'Validated' if dry_run else 'Restored'} {counts} from {path}")
+    return counts
+
+
+async def main():
+    """Main entry point for Backup service."""
+    args = parse_args()
+
+    try:
+        if not db.pool:
+            await db.connect()
+            logger.info("Connected to database")
+
+        if args.command == "backup":
+            counts = await backup(args.out)
+            print(f"✓ Backup saved to {args.out}")
+            for name, count in counts.items():
+                print(f"  {name}: {count}")
+        else:
+            counts = await restore(args.path, dry_run=args.dry_run)
+            print(f"✓ {'Validated' if args.dry_run else 'Restored'} {args.path}")
+            print(f"  Channels: {counts['channels']}")
+            print(f"  Series: {counts['series']}")
+            print(f"  Posts: {counts['posts']}")
+            print(f"  Corrections: {counts['overrides']}")
+            print(f"  Revisions: {counts['revisions']}")
+
+    except Exception as e:
+        logger.error(f"Error: {e}", exc_info=True)
+        print(f"Error: {e}", file=sys.stderr)
+        sys.exit(1)
+
+    finally:
+        await db.disconnect()
+
+
+if __name__ == "__main__":
+    asyncio.run(main())
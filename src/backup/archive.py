@@ -0,0 +1,204 @@
+"""Portable backup archive format.
+
+A backup is a gzip-compressed tarball with one JSON Lines file per table:
+
+    manifest.json             format version, creation time, record counts
+    telegram_channels.jsonl   sources
+    series.jsonl              series the posts belong to
+    rss_posts.jsonl           posts including publication state
+    field_overrides.jsonl     moderators' corrections of extracted fields
+    post_revisions.jsonl      edits of stored posts
+    media_manifest.jsonl      media URLs referenced by each post
+"""
+
+import io
+import json
+import tarfile
+import tempfile
+from dataclasses import fields
+from datetime import date, datetime
+from decimal import Decimal
+from typing import IO, Any, Dict, Iterable, Iterator, List, Optional
+
+from common import clock
+from common.db.models import FieldOverride, PostRevision, RSSPost, Series, TelegramChannel
+
+FORMAT_VERSION = 1
+
+MANIFEST_FILE = "manifest.json"
+CHANNELS_FILE = "telegram_channels.jsonl"
+SERIES_FILE = "series.jsonl"
+POSTS_FILE = "rss_posts.jsonl"
+OVERRIDES_FILE = "field_overrides.jsonl"
+REVISIONS_FILE = "post_revisions.jsonl"
+MEDIA_FILE = "media_manifest.jsonl"
+
+DATETIME_FIELDS = {"pub_date", "published_at", "created_at", "updated_at", "embargo_until"}
+DATE_FIELDS = {"event_start", "event_end", "deadline"}
+DECIMAL_FIELDS = {"price_min", "price_max"}
+
+
+def _encode(value: Any) -> Any:
+    """JSON encoder for datetimes, dates and decimals."""
+    if isinstance(value, (datetime, date)):
+        return value.isoformat()
+    if isinstance(value, Decimal):
+        return str(value)
+    raise TypeError(f"Object of type {type(value).__name__} is not JSON serializable")
+
+
+def _decode_record(record: Dict[str, Any]) -> Dict[str, Any]:
+    """Turn ISO timestamps, dates and decimal strings back into their types."""
+    for key in DATETIME_FIELDS & record.keys():
+        if record[key]:
+            record[key] = datetime.fromisoformat(record[key])
+    for key in DATE_FIELDS & record.keys():
+        if record[key]:
+            record[key] = date.fromisoformat(record[key])
+    for key in DECIMAL_FIELDS & record.keys():
+        if record[key] is not None:
+            record[key] = Decimal(record[key])
+    return record
+
+
+def media_urls(post: RSSPost) -> List[str]:
+    """Return media URLs stored on a post (JSON list or legacy comma-separated)."""
+    if not post.media:
+        return []
+    try:
+        urls = json.loads(post.media)
+        return urls if isinstance(urls, list) else [str(urls)]
+    except ValueError:
+        return [url for url in post.media.split(",") if url]
+
+
+class BackupWriter:
+    """Streams records into a backup archive."""
+
+    def __init__(self, path: str):
+        self.path = path
+        self.counts: Dict[str, int] = {}
+        # Tables are spooled to temporary files so large databases don't fill memory
+        self._buffers: Dict[str, IO[bytes]] = {}
+
+    def _write(self, name: str, record: Dict[str, Any]) -> None:
+        if name not in self._buffers:
+            self._buffers[name] = tempfile.TemporaryFile()
+        buffer = self._buffers[name]
+        line = json.dumps(record, ensure_ascii=False, default=_encode) + "\n"
+        buffer.write(line.encode("utf-8"))
+        self.counts[name] = self.counts.get(name, 0) + 1
+
+    def add_channel(self, channel: TelegramChannel) -> None:
+        """Add a channel to the archive."""
+        self._write(CHANNELS_FILE, channel.to_dict())
+
+    def add_series(self, series: Series) -> None:
+        """Add a series to the archive."""
+        self._write(SERIES_FILE, series.to_dict())
+
+    def add_post(self, post: RSSPost) -> None:
+        """Add a post (and its media manifest entry) to the archive."""
+        self._write(POSTS_FILE, post.to_dict())
+        urls = media_urls(post)
+        if urls:
+            self._write(MEDIA_FILE, {"link": post.link, "media_urls": urls})
+
+    def add_override(self, override: FieldOverride) -> None:
+        """Add a moderator's correction to the archive."""
+        self._write(OVERRIDES_FILE, override.to_dict())
+
+    def add_revision(self, revision: PostRevision) -> None:
+        """Add a post revision to the archive."""
+        self._write(REVISIONS_FILE, revision.to_dict())
+
+    def close(self) -> None:
+        """Write manifest and all buffered tables to disk."""
+        manifest = {
+            "format_version": FORMAT_VERSION,
+            "created_at": clock.now().isoformat(),
+            "counts": self.counts,
+        }
+        with tarfile.open(self.path, "w:gz") as tar:
+            _add_file(tar, MANIFEST_FILE, io.BytesIO(json.dumps(manifest, indent=2).encode()))
+            for name in (
+                CHANNELS_FILE,
+                SERIES_FILE,
+                POSTS_FILE,
+                OVERRIDES_FILE,
+                REVISIONS_FILE,
+                MEDIA_FILE,
+            ):
+                _add_file(tar, name, self._buffers.get(name) or io.BytesIO())
+
+        for buffer in self._buffers.values():
+            buffer.close()
+
+
+def _add_file(tar: tarfile.TarFile, name: str, fileobj: IO[bytes]) -> None:
+    info = tarfile.TarInfo(name)
+    info.size = fileobj.seek(0, io.SEEK_END)
+    info.mtime = int(clock.now().timestamp())
+    fileobj.seek(0)
+    tar.addfile(info, fileobj)
+
+
+class BackupReader:
+    """Reads records from a backup archive."""
+
+    def __init__(self, path: str):
+        self.path = path
+        self._tar = tarfile.open(path, "r:gz")
+        self.manifest = json.loads(self._read(MANIFEST_FILE) or b"{}")
+
+        version = self.manifest.get("format_version")
+        if version != FORMAT_VERSION:
+            raise ValueError(f"Unsupported backup format version: {version}")
+
+    def _read(self, name: str) -> Optional[bytes]:
+        try:
+            member = self._tar.getmember(name)
+        except KeyError:
+            return None
+        handle = self._tar.extractfile(member)
+        return handle.read() if handle else None
+
+    def _records(self, name: str) -> Iterator[Dict[str, Any]]:
+        data = self._read(name) or b""
+        for line in data.decode("utf-8").splitlines():
+            if line.strip():
+                yield _decode_record(json.loads(line))
+
+    def channels(self) -> Iterable[TelegramChannel]:
+        """Iterate channels stored in the archive."""
+        names = {f.name for f in fields(TelegramChannel)}
+        for record in self._records(CHANNELS_FILE):
+            yield TelegramChannel(**{k: v for k, v in record.items() if k in names})
+
+    def series(self) -> Iterable[Series]:
+        """Iterate series stored in the archive (none in archives made before series)."""
+        names = {f.name for f in fields(Series)}
+        for record in self._records(SERIES_FILE):
+            yield Series(**{k: v for k, v in record.items() if k in names})
+
+    def posts(self) -> Iterable[RSSPost]:
+        """Iterate posts stored in the archive."""
+        names = {f.name for f in fields(RSSPost)}
+        for record in self._records(POSTS_FILE):
+            yield RSSPost(**{k: v for k, v in record.items() if k in names})
+
+    def overrides(self) -> Iterable[FieldOverride]:
+        """Iterate corrections stored in the archive (none in archives made before them)."""
+        names = {f.name for f in fields(FieldOverride)}
+        for record in self._records(OVERRIDES_FILE):
+            yield FieldOverride(**{k: v for k, v in record.items() if k in names})
+
+    def revisions(self) -> Iterable[PostRevision]:
+        """Iterate post revisions stored in the archive (none in archives made before them)."""
+        names = {f.name for f in fields(PostRevision)}
+        for record in self._records(REVISIONS_FILE):
+            yield PostRevision(**{k: v for k, v in record.items() if k in names})
+
+    def close(self) -> None:
+        """Close the underlying archive."""
+        self._tar.close()
@@ -0,0 +1,76 @@
+"""Admission requirements mentioned in announcements.
+
+Detects whether an event needs registration, needs a ticket or has limited
+capacity ("по регистрации", "вход по билетам", "мест ограничено"), so
+readers can be warned to sign up or buy early.
+"""
+
+import re
+from dataclasses import dataclass
+from typing import List
+
+from common.db.models import RSSPost
+
+REGISTRATION_REGEX = re.compile(
+    r"по\s+(?:предварительной\s+)?(?:регистрации|записи)"
+    r"|(?:обязательная|необходима|нужна|требуется)\s+(?:предварительная\s+)?(?:регистрация|запись)"
+    r"|(?:регистрация|запись)\s+(?:обязательна|по\s+ссылке)"
+    r"|предварительная\s+(?:регистрация|запись)"
+    r"|зарегистрир\w+",
+    re.IGNORECASE,
+)
+TICKETS_REGEX = re.compile(
+    r"по\s+билетам"
+    r"|билеты\s+(?:в\s+продаже|можно\s+купить|по\s+ссылке|на\s+сайте|в\s+кассах)"
+    r"|(?:купить|приобрести)\s+билет\w*",
+    re.IGNORECASE,
+)
+LIMITED_CAPACITY_REGEX = re.compile(
+    r"мест\s+ограничено"
+    r"|ограниченное\s+(?:количество|число)\s+мест"
+    r"|мест\s+(?:мало|немного)"
+    r"|осталось\s+(?:всего\s+)?\d+\s+мест",
+    re.IGNORECASE,
+)
+
+WARNINGS = {
+    "registration_required": "нужна регистрация",
+    "tickets_required": "вход по билетам",
+    "limited_capacity": "количество мест ограничено",
+}
+
+
+@dataclass
+class Admission:
+    """Admission requirements of an event."""
+
+    registration_required: bool = False
+    tickets_required: bool = False
+    limited_capacity: bool = False
+
+    @classmethod
+    def from_post(cls, post: RSSPost) -> "Admission":
+        """Admission flags stored on a post."""
+        return cls(post.registration_required, post.tickets_required, post.limited_capacity)
+
+    def warnings(self) -> List[str]:
+        """Human-readable warnings for the requirements that apply."""
+        return [text for name, text in WARNINGS.items() if getattr(self, name)]
+
+
+def detect_admission(content: str) -> Admission:
+    """
+    Detect admission requirements in a post.
+
+    Args:
+        content: Post text
+
+    Returns:
+        Admission flags
+    """
+    text = " ".join((content or "").split())
+    return Admission(
+        registration_required=bool(REGISTRATION_REGEX.search(text)),
+        tickets_required=bool(TICKETS_REGEX.search(text)),
+        limited_capacity=bool(LIMITED_CAPACITY_REGEX.search(text)),
+    )
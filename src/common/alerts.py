@@ -0,0 +1,187 @@
+"""Operator alerts for pipeline failures.
+
+Alerts are sent to a Telegram chat (usually a DM with the operator) and/or a
+Slack incoming webhook:
+
+    ALERT_TELEGRAM_CHAT_ID=123456789      # uses TELEGRAM_BOT_TOKEN
+    ALERT_SLACK_WEBHOOK_URL=https://hooks.slack.com/services/...
+
+Every alert has a key such as 'source_failure:mediarzn'. An alert with the
+same key is sent at most once per ALERT_COOLDOWN_MINUTES; repeats within the
+cooldown are counted and reported with the next alert. Resolving a key (the
+source recovered) resets it, so the next failure is reported immediately.
+State is kept in ALERT_STATE_FILE so cooldowns survive restarts.
+"""
+
+import asyncio
+import json
+import logging
+import os
+import time
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Callable, Dict, List, Optional
+
+import requests
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class AlertSettings:
+    """Alerting configuration."""
+
+    telegram_bot_token: str = os.getenv("TELEGRAM_BOT_TOKEN", "")
+    telegram_chat_id: str = os.getenv("ALERT_TELEGRAM_CHAT_ID", "")
+    slack_webhook_url: str = os.getenv("ALERT_SLACK_WEBHOOK_URL", "")
+    cooldown_minutes: int = int(os.getenv("ALERT_COOLDOWN_MINUTES", "60"))
+    state_file: str = os.getenv("ALERT_STATE_FILE", ".alert_state.json")
+
+    # Thresholds
+    error_spike_threshold: int = int(os.getenv("ALERT_ERROR_SPIKE_THRESHOLD", "10"))
+    backlog_threshold: int = int(os.getenv("ALERT_BACKLOG_THRESHOLD", "500"))
+    # Share of sampled recent posts with leftover HTML that means the cleaner broke
+    drift_ratio: float = float(os.getenv("ALERT_DRIFT_RATIO", "0.05"))
+    drift_sample_size: int = int(os.getenv("ALERT_DRIFT_SAMPLE_SIZE", "200"))
+    drift_hours: int = int(os.getenv("ALERT_DRIFT_HOURS", "24"))
+
+
+alert_settings = AlertSettings()
+
+
+@dataclass
+class Alert:
+    """A single operator alert."""
+
+    key: str
+    message: str
+    severity: str = "error"
+
+
+class TelegramSink:
+    """Send alerts to a Telegram chat via the Bot API."""
+
+    def __init__(self, bot_token: str, chat_id: str, timeout: int = 10):
+        self.url = f"https://api.telegram.org/bot{bot_token}/sendMessage"
+        self.chat_id = chat_id
+        self.timeout = timeout
+
+    def send(self, text: str) -> None:
+        response = requests.post(
+            self.url,
+            json={"chat_id": self.chat_id, "text": text, "disable_web_page_preview": True},
+            timeout=self.timeout,
+        )
+        response.raise_for_status()
+
+
+class SlackSink:
+    """Send alerts to a Slack incoming webhook."""
+
+    def __init__(self, webhook_url: str, timeout: int = 10):
+        self.webhook_url = webhook_url
+        self.timeout = timeout
+
+    def send(self, text: str) -> None:
+        response = requests.post(self.webhook_url, json={"text": text}, timeout=self.timeout)
+        response.raise_for_status()
+
+
+SEVERITY_ICONS = {"error": "🚨", "warning": "⚠️", "info": "ℹ️"}
+
+
+class AlertManager:
+    """Deliver alerts with per-key deduplication and cooldown."""
+
+    def __init__(
+        self,
+        sinks: List,
+        cooldown_seconds: float = 3600,
+        state_file: Optional[str] = None,
+        clock: Callable[[], float] = time.time,
+    ):
+        """
+        Args:
+            sinks: Objects with a send(text) method
+            cooldown_seconds: Minimum interval between alerts with the same key
+            state_file: JSON file persisting cooldown state (in-memory if None)
+            clock: Time source, for tests
+        """
+        self.sinks = sinks
+        self.cooldown_seconds = cooldown_seconds
+        self.state_path = Path(state_file) if state_file else None
+        self.clock = clock
+        self._state: Dict[str, dict] = self._load_state()
+
+    @staticmethod
+    def from_settings(settings: AlertSettings = alert_settings) -> "AlertManager":
+        """Create an AlertManager from environment settings."""
+        sinks = []
+        if settings.telegram_bot_token and settings.telegram_chat_id:
+            sinks.append(TelegramSink(settings.telegram_bot_token, settings.telegram_chat_id))
+        if settings.slack_webhook_url:
+            sinks.append(SlackSink(settings.slack_webhook_url))
+        return AlertManager(sinks, settings.cooldown_minutes * 60, settings.state_file)
+
+    def _load_state(self) -> Dict[str, dict]:
+        if not self.state_path or not self.state_path.exists():
+            return {}
+        try:
+            return json.loads(self.state_path.read_text(encoding="utf-8"))
+        except (OSError, ValueError) as e:
+            logger.warning(f"Ignoring unreadable alert state {self.state_path}: {e}")
+            return {}
+
+    def _save_state(self) -> None:
+        if not self.state_path:
+            return
+        try:
+            self.state_path.write_text(json.dumps(self._state), encoding="utf-8")
+        except OSError as e:
+            logger.warning(f"Failed to save alert state {self.state_path}: {e}")
+
+    async def notify(self, alert: Alert) -> bool:
+        """
+        Send an alert unless one with the same key was sent during the cooldown.
+
+        Delivery failures are logged and never raised, so alerting can't break
+        the pipeline.
+
+        Args:
+            alert: Alert to send
+
+        Returns:
+            True if the alert was delivered to at least one sink
+        """
+        now = self.clock()
+        entry = self._state.get(alert.key)
+
+        if entry and now - entry["last_sent"] < self.cooldown_seconds:
+            entry["suppressed"] = entry.get("suppressed", 0) + 1
+            self._save_state()
+            logger.info(f"Alert {alert.key} suppressed (cooldown)")
+            return False
+
+        text = f"{SEVERITY_ICONS.get(alert.severity, '🚨')} {alert.message}"
+        if entry and entry.get("suppressed"):
+            text += f"\n(+{entry['suppressed']} repeats since the last alert)"
+
+        logger.warning(f"Alert {alert.key}: {alert.message}")
+        delivered = False
+        for sink in self.sinks:
+            try:
+                await asyncio.to_thread(sink.send, text)
+                delivered = True
+            except Exception as e:
+                logger.error(f"Failed to deliver alert via {type(sink).__name__}: {e}")
+
+        if delivered:
+            self._state[alert.key] = {"last_sent": now, "suppressed": 0}
+            self._save_state()
+        return delivered
+
+    def resolve(self, key: str) -> None:
+        """Forget an alert key once the problem is gone."""
+        if self._state.pop(key, None) is not None:
+            logger.info(f"Alert {key} resolved")
+            self._save_state()
@@ -0,0 +1,83 @@
+"""Time source shared by the services.
+
+Code that depends on the current time or waits asks the clock instead of
+calling datetime.now() or asyncio.sleep(), so tests and the demo can pin or
+fast-forward time with set_clock(FakeClock(...)). Timestamps written by
+Postgres itself (CURRENT_TIMESTAMP) still follow the database server.
+"""
+
+import asyncio
+from datetime import date, datetime, timedelta
+from typing import List
+
+
+class Clock:
+    """Wall clock of the running process."""
+
+    def now(self) -> datetime:
+        """Current local time, naive like the database timestamps."""
+        return datetime.now()
+
+    def today(self) -> date:
+        """Current local date."""
+        return self.now().date()
+
+    async def sleep(self, seconds: float) -> None:
+        """Wait for the given number of seconds."""
+        await asyncio.sleep(seconds)
+
+
+class FakeClock(Clock):
+    """Clock that stands still until advanced; sleeping advances it instantly."""
+
+    def __init__(self, start: datetime):
+        self.current = start
+        self.sleeps: List[float] = []
+
+    def now(self) -> datetime:
+        return self.current
+
+    def advance(self, delta: timedelta) -> None:
+        """Move the clock forward."""
+        self.current += delta
+
+    async def sleep(self, seconds: float) -> None:
+        self.sleeps.append(seconds)
+        self.advance(timedelta(seconds=seconds))
+        # Still let other tasks run, as a real sleep would
+        await asyncio.sleep(0)
+
+
+_clock: Clock = Clock()
+
+
+def get_clock() -> Clock:
+    """The clock in use."""
+    return _clock
+
+
+def set_clock(clock: Clock) -> Clock:
+    """
+    Replace the clock in use.
+
+    Returns:
+        The previous clock, to restore it afterwards
+    """
+    global _clock
+    previous, _clock = _clock, clock
+    return previous
+
+
+def now() -> datetime:
+    """Current time of the clock in use."""
+    return _clock.now()
+
+
+def today() -> date:
+    """Current date of the clock in use."""
+    return _clock.today()
+
+
+async def sleep(seconds: float) -> None:
+    """Wait on the clock in use."""
+    await _clock.sleep(seconds)
@@ -0,0 +1,75 @@
+"""Contact phone numbers and emails mentioned in announcements.
+
+Phone numbers are stored in E.164 form ("+79101234567") whichever way they
+were written ("8 (910) 123-45-67", "+7 910 123 45 67"), emails in lower case,
+so the same contact found twice is kept once. Short city numbers
+("28-14-88") are left alone, they can't be told apart from other numbers.
+"""
+
+import re
+from typing import List
+
+# Russian numbers with the +7 or 8 prefix, and other ones in international form
+RUSSIAN_PHONE_REGEX = re.compile(
+    r"(?<![\w+])(?:\+7|8)[\s-]?\(?\d{3}\)?[\s-]?\d{3}[\s-]?\d{2}[\s-]?\d{2}(?![\d-])"
+)
+INTERNATIONAL_PHONE_REGEX = re.compile(
+    r"(?<![\w+])\+(?!7)\d{1,3}(?:[\s-]?\(?\d{2,4}\)?){2,5}(?!\d)"
+)
+EMAIL_REGEX = re.compile(r"(?<![\w.+-])[\w.+-]+@[\w-]+(?:\.[\w-]+)*\.[a-zA-Zа-яА-Я]{2,}")
+
+
+def normalize_phone(phone: str) -> str:
+    """E.164 form of a phone number: '8 (910) 123-45-67' -> '+79101234567'."""
+    digits = re.sub(r"\D", "", phone)
+    if phone.lstrip().startswith("8") and len(digits) == 11:
+        digits = "7" + digits[1:]
+    return f"+{digits}"
+
+
+def format_phone(phone: str) -> str:
+    """Readable form of a normalized phone number: '+7 910 123-45-67'."""
+    digits = phone[1:]
+    if phone.startswith("+7") and len(digits) == 11:
+        return f"+7 {digits[1:4]} {digits[4:7]}-{digits[7:9]}-{digits[9:]}"
+    return phone
+
+
+def is_email(contact: str) -> bool:
+    return "@" in contact
+
+
+def contact_href(contact: str) -> str:
+    """tel: or mailto: link of a stored contact."""
+    return f"mailto:{contact}" if is_email(contact) else f"tel:{contact}"
+
+
+def contact_label(contact: str) -> str:
+    """How a stored contact is shown."""
+    return contact if is_email(contact) else format_phone(contact)
+
+
+def extract_contacts(content: str) -> List[str]:
+    """
+    Find the phone numbers and emails in a post.
+
+    Args:
+        content: Post text
+
+    Returns:
+        Normalized contacts in the order they first appear
+    """
+    text = content or ""
+    found = [(m.start(), normalize_phone(m.group())) for m in RUSSIAN_PHONE_REGEX.finditer(text)]
+    found += [
+        (m.start(), normalize_phone(m.group()))
+        for m in INTERNATIONAL_PHONE_REGEX.finditer(text)
+        if 8 <= len(re.sub(r"\D", "", m.group())) <= 15
+    ]
+    found += [(m.start(), m.group().lower()) for m in EMAIL_REGEX.finditer(text)]
+
+    contacts = []
+    for _, contact in sorted(found):
+        if contact not in contacts:
+            contacts.append(contact)
+    return contacts
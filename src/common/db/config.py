@@ -9,12 +9,19 @@ env_path = Path(__file__).parent.parent.parent.parent / ".env"
 if env_path.exists():
     load_dotenv(env_path)
 
+# DSN of the in-memory backend (common/db/memory.py)
+MEMORY_DSN = "memory://"
+
 
 class Settings:
     def __init__(self):
         self.dsn = os.getenv("DATABASE_DSN")
         if self.dsn is None:
             raise EnvironmentError("DATABASE_DSN not set")
+        # Read replicas, nearest first; reads that may be stale are sent to them
+        self.replica_dsns = [
+            dsn.strip() for dsn in os.getenv("DATABASE_REPLICA_DSNS", "").split(",") if dsn.strip()
+        ]
 
     def get_dsn(self) -> str:
         return self.dsn
@@ -0,0 +1,990 @@
+"""In-memory storage backend.
+
+Keeps channels, posts and everything attached to them in process memory, so
+tests, demos and one-off pipeline runs work without Postgres. Selected with
+DATABASE_DSN=memory://, which makes db.connect() install it in place of the
+Postgres repositories; data is lost when the process exits and isn't shared
+between processes.
+"""
+
+from collections import Counter
+from dataclasses import dataclass, field, fields, replace
+from datetime import date, datetime, timedelta
+from typing import Dict, List, Optional, Set, Tuple
+
+from common import clock
+from common.ids import new_id
+
+from .models import (
+    DigestDraft,
+    FieldOverride,
+    PollRun,
+    PollRunItem,
+    PostRevision,
+    Promotion,
+    PublishFailure,
+    Quota,
+    RSSPost,
+    Series,
+    SourceBandwidth,
+    Submission,
+    TelegramChannel,
+)
+from .storage import POSTGRES, Storage
+
+@dataclass
+class MemoryStore:
+    """Rows of all tables."""
+
+    channels: Dict[int, TelegramChannel] = field(default_factory=dict)
+    posts: Dict[str, RSSPost] = field(default_factory=dict)
+    promotions: Dict[str, Promotion] = field(default_factory=dict)
+    # (promotion id, target, placed at)
+    placements: List[Tuple[str, str, datetime]] = field(default_factory=list)
+    # (link, target) -> (external id, delivered at, shares)
+    deliveries: Dict[Tuple[str, str], Tuple[Optional[str], datetime, Optional[int]]] = field(
+        default_factory=dict
+    )
+    # (source hash, language) -> text
+    translations: Dict[Tuple[str, str], str] = field(default_factory=dict)
+    # (content hash, purpose) -> result
+    llm_cache: Dict[Tuple[str, str], str] = field(default_factory=dict)
+    # (tenant, day) -> tokens
+    llm_usage: Dict[Tuple[str, date], int] = field(default_factory=dict)
+    series: Dict[str, Series] = field(default_factory=dict)
+    # (link, day, kind) -> count
+    interactions: Dict[Tuple[str, date, str], int] = field(default_factory=dict)
+    poll_runs: Dict[str, PollRun] = field(default_factory=dict)
+    poll_run_items: List[PollRunItem] = field(default_factory=list)
+    submissions: Dict[str, Submission] = field(default_factory=dict)
+    digest_drafts: Dict[str, DigestDraft] = field(default_factory=dict)
+    publish_failures: Dict[str, PublishFailure] = field(default_factory=dict)
+    # (api, name) -> quota
+    quotas: Dict[Tuple[str, str], Quota] = field(default_factory=dict)
+    # (source, day) -> daily total
+    bandwidth: Dict[Tuple[str, date], SourceBandwidth] = field(default_factory=dict)
+    # (link, revision) -> edit of a post
+    revisions: Dict[Tuple[str, int], PostRevision] = field(default_factory=dict)
+    # (link, field) -> moderator's correction
+    overrides: Dict[Tuple[str, str], FieldOverride] = field(default_factory=dict)
+
+
+store = MemoryStore()
+
+
+def reset() -> None:
+    """Drop all data."""
+    global store
+    store = MemoryStore()
+
+
+def _copy(post: RSSPost) -> RSSPost:
+    # Callers may modify returned posts, as they may ones read from the database
+    return replace(
+        post,
+        tags=list(post.tags) if post.tags else None,
+        categories=list(post.categories) if post.categories else None,
+        performers=list(post.performers) if post.performers else None,
+        organizations=list(post.organizations) if post.organizations else None,
+        contacts=list(post.contacts) if post.contacts else None,
+    )
+
+
+def _copy_failure(failure: PublishFailure) -> PublishFailure:
+    return replace(failure, links=list(failure.links), promotion_ids=list(failure.promotion_ids))
+
+
+def _channel_name(link: str) -> str:
+    # split_part(link, '/', 4)
+    parts = link.split("/")
+    return parts[3] if len(parts) > 3 else ""
+
+
+class MemoryTelegramChannelRepository:
+    """In-memory TelegramChannelRepository."""
+
+    @staticmethod
+    async def get_all() -> List[TelegramChannel]:
+        return [replace(c) for c in sorted(store.channels.values(), key=lambda c: c.channel_name)]
+
+    @staticmethod
+    async def get_by_id(channel_id: int) -> Optional[TelegramChannel]:
+        channel = store.channels.get(channel_id)
+        return replace(channel) if channel else None
+
+    @staticmethod
+    async def get_by_name(channel_name: str) -> Optional[TelegramChannel]:
+        for channel in store.channels.values():
+            if channel.channel_name == channel_name:
+                return replace(channel)
+        return None
+
+    @staticmethod
+    async def create(channel: TelegramChannel) -> int:
+        if channel.channel_id in store.channels:
+            raise ValueError(f"Channel {channel.channel_id} already exists")
+        now = clock.now()
+        store.channels[channel.channel_id] = replace(channel, created_at=now, updated_at=now)
+        return channel.channel_id
+
+    @staticmethod
+    async def update(channel: TelegramChannel) -> None:
+        existing = store.channels.get(channel.channel_id)
+        if existing:
+            store.channels[channel.channel_id] = replace(
+                existing,
+                channel_name=channel.channel_name,
+                description=channel.description,
+                url=channel.url,
+                title=channel.title,
+                avatar_url=channel.avatar_url,
+                category=channel.category,
+                updated_at=clock.now(),
+            )
+
+    @staticmethod
+    async def set_title(channel_id: int, title: str) -> None:
+        existing = store.channels.get(channel_id)
+        if existing and existing.title != title:
+            store.channels[channel_id] = replace(existing, title=title, updated_at=clock.now())
+
+    @staticmethod
+    async def upsert(channel: TelegramChannel) -> None:
+        now = clock.now()
+        existing = store.channels.get(channel.channel_id)
+        created_at = existing.created_at if existing else channel.created_at or now
+        store.channels[channel.channel_id] = replace(
+            channel, created_at=created_at, updated_at=channel.updated_at or now
+        )
+
+    @staticmethod
+    async def delete(channel_id: int) -> None:
+        store.channels.pop(channel_id, None)
+
+
+class MemoryRSSPostRepository:
+    """In-memory RSSPostRepository."""
+
+    @staticmethod
+    async def create(post: RSSPost) -> str:
+        if post.link in store.posts:
+            raise ValueError(f"Post {post.link} already exists")
+        now = clock.now()
+        store.posts[post.link] = replace(
+            _copy(post),
+            is_published=False,
+            published_at=None,
+            created_at=now,
+            updated_at=now,
+            summary=None,
+            title=None,
+            embargo_until=None,
+        )
+        return post.link
+
+    @staticmethod
+    async def upsert(post: RSSPost) -> None:
+        now = clock.now()
+        existing = store.posts.get(post.link)
+        created_at = existing.created_at if existing else post.created_at or now
+        store.posts[post.link] = replace(
+            _copy(post), created_at=created_at, updated_at=post.updated_at or now
+        )
+
+    @staticmethod
+    async def get_by_link(link: str) -> Optional[RSSPost]:
+        post = store.posts.get(link)
+        return _copy(post) if post else None
+
+    @staticmethod
+    async def get_by_source_guid(source_guid: str) -> Optional[RSSPost]:
+        posts = [post for post in store.posts.values() if post.source_guid == source_guid]
+        posts.sort(key=lambda post: (post.created_at, post.link))
+        return _copy(posts[0]) if posts else None
+
+    @staticmethod
+    async def get_all(limit: int = 100, offset: int = 0) -> List[RSSPost]:
+        # Newest first; posts created at the same moment in reverse insertion order
+        posts = sorted(reversed(store.posts.values()), key=lambda p: p.created_at, reverse=True)
+        return [_copy(post) for post in posts[offset : offset + limit]]
+
+    @staticmethod
+    async def get_by_date_range(
+        start_date: datetime, end_date: datetime, limit: int = 1000, only_unpublished: bool = True
+    ) -> List[RSSPost]:
+        posts = [
+            post
+            for post in store.posts.values()
+            if post.pub_date
+            and start_date <= post.pub_date <= end_date
+            and not (only_unpublished and post.is_published)
+        ]
+        posts.sort(key=lambda p: p.pub_date, reverse=True)
+        return [_copy(post) for post in posts[:limit]]
+
+    @staticmethod
+    async def get_released(
+        start_date: datetime, end_date: datetime, limit: int = 1000, only_unpublished: bool = True
+    ) -> List[RSSPost]:
+        posts = [
+            post
+            for post in store.posts.values()
+            if post.embargo_until
+            and start_date <= post.embargo_until <= end_date
+            and not (only_unpublished and post.is_published)
+        ]
+        posts.sort(key=lambda p: p.embargo_until, reverse=True)
+        return [_copy(post) for post in posts[:limit]]
+
+    @staticmethod
+    async def set_embargo(link: str, until: datetime) -> None:
+        post = store.posts.get(link)
+        if post:
+            post.embargo_until = until
+
+    @staticmethod
+    async def get_updated_since(
+        since: Optional[datetime], after_link: str = "", limit: int = 500
+    ) -> List[RSSPost]:
+        posts = sorted(store.posts.values(), key=lambda p: (p.updated_at, p.link))
+        if since is not None:
+            posts = [post for post in posts if (post.updated_at, post.link) > (since, after_link)]
+        return [_copy(post) for post in posts[:limit]]
+
+    @staticmethod
+    async def delete(link: str) -> None:
+        if store.posts.pop(link, None) is None:
+            return
+        # ON DELETE CASCADE
+        for key in [key for key in store.deliveries if key[0] == link]:
+            del store.deliveries[key]
+        for key in [key for key in store.interactions if key[0] == link]:
+            del store.interactions[key]
+        for key in [key for key in store.revisions if key[0] == link]:
+            del store.revisions[key]
+        for key in [key for key in store.overrides if key[0] == link]:
+            del store.overrides[key]
+        for promotion in [p for p in store.promotions.values() if p.post_link == link]:
+            await MemoryPromotionRepository.delete(promotion.id)
+
+    @staticmethod
+    async def get_stats() -> dict:
+        week_ago = clock.now() - timedelta(days=7)
+        posts = store.posts.values()
+        return {
+            "total": len(posts),
+            "recent": sum(1 for post in posts if post.pub_date and post.pub_date >= week_ago),
+        }
+
+    @staticmethod
+    async def get_daily_counts_by_channel(
+        start_date: datetime, end_date: datetime, only_published: bool = False
+    ) -> List[dict]:
+        counts: Counter = Counter()
+        for post in store.posts.values():
+            moment = post.published_at if only_published else post.pub_date
+            if moment and start_date <= moment <= end_date:
+                day = datetime.combine(moment.date(), datetime.min.time())
+                counts[(day, _channel_name(post.link))] += 1
+        return [
+            {"day": day, "channel_name": channel_name, "count": number}
+            for (day, channel_name), number in sorted(counts.items())
+        ]
+
+    @staticmethod
+    async def get_channel_activity(since: datetime) -> List[dict]:
+        posts_by_channel: Dict[str, List[RSSPost]] = {}
+        for post in store.posts.values():
+            posts_by_channel.setdefault(_channel_name(post.link), []).append(post)
+
+        result = []
+        for name in sorted(posts_by_channel):
+            posts = posts_by_channel[name]
+            # ORDER BY pub_date DESC NULLS LAST, link DESC
+            events = sorted(
+                (post for post in posts if post.is_published),
+                key=lambda p: (p.pub_date is not None, p.pub_date or datetime.min, p.link),
+            )
+            last = events[-1] if events else None
+            result.append(
+                {
+                    "channel_name": name,
+                    "count": sum(1 for p in posts if p.pub_date and p.pub_date >= since),
+                    "last_event_link": last.link if last else None,
+                    "last_event_title": last.title if last else None,
+                    "last_event_date": last.pub_date if last else None,
+                }
+            )
+        return result
+
+    @staticmethod
+    async def get_published_since(start_date: datetime, limit: int = 500) -> List[RSSPost]:
+        posts = [
+            post
+            for post in store.posts.values()
+            if post.is_published and post.pub_date and post.pub_date >= start_date
+        ]
+        posts.sort(key=lambda p: p.pub_date, reverse=True)
+        return [_copy(post) for post in posts[:limit]]
+
+    @staticmethod
+    async def count_unpublished() -> int:
+        return sum(1 for post in store.posts.values() if not post.is_published)
+
+    @staticmethod
+    async def get_without_summary(limit: int = 100) -> List[RSSPost]:
+        posts = [p for p in store.posts.values() if p.summary is None or p.title is None]
+        # NULLS LAST
+        posts.sort(key=lambda p: (p.pub_date is not None, p.pub_date or datetime.min), reverse=True)
+        return [_copy(post) for post in posts[:limit]]
+
+    @staticmethod
+    async def update_summary(link: str, summary: str, title: str) -> None:
+        post = store.posts.get(link)
+        if post:
+            post.summary, post.title, post.updated_at = summary, title, clock.now()
+
+    @staticmethod
+    async def exists_by_link(link: str) -> bool:
+        return link in store.posts
+
+    @staticmethod
+    async def get_recent_posts_excluding(
+        days: int, exclude_links: List[str], limit: int = 1000
+    ) -> List[RSSPost]:
+        since = clock.now() - timedelta(days=days)
+        excluded = set(exclude_links or [])
+        posts = [
+            post
+            for post in store.posts.values()
+            if post.is_published
+            and post.pub_date
+            and post.pub_date >= since
+            and post.link not in excluded
+        ]
+        posts.sort(key=lambda p: p.pub_date, reverse=True)
+        return [_copy(post) for post in posts[:limit]]
+
+    @staticmethod
+    async def mark_as_published(links: List[str]) -> int:
+        now = clock.now()
+        updated = 0
+        for link in set(links):
+            post = store.posts.get(link)
+            if post:
+                post.is_published, post.published_at, post.updated_at = True, now, now
+                updated += 1
+        return updated
+
+    @staticmethod
+    async def set_event_status(link: str, status: Optional[str]) -> bool:
+        post = store.posts.get(link)
+        if post is None:
+            return False
+        post.event_status, post.updated_at = status, clock.now()
+        return True
+
+
+class MemoryPromotionRepository:
+    """In-memory PromotionRepository."""
+
+    @staticmethod
+    def _with_placements(promotion: Promotion) -> Promotion:
+        placements = sum(1 for p in store.placements if p[0] == promotion.id)
+        return replace(promotion, placements=placements)
+
+    @staticmethod
+    async def create(promotion: Promotion) -> str:
+        promotion_id = new_id()
+        now = clock.now()
+        store.promotions[promotion_id] = replace(
+            promotion, id=promotion_id, placements=0, created_at=now, updated_at=now
+        )
+        return promotion_id
+
+    @staticmethod
+    async def get_all() -> List[Promotion]:
+        promotions = sorted(store.promotions.values(), key=lambda p: p.starts_at, reverse=True)
+        return [MemoryPromotionRepository._with_placements(p) for p in promotions]
+
+    @staticmethod
+    async def get_active(at: datetime) -> List[Promotion]:
+        promotions = sorted(store.promotions.values(), key=lambda p: (p.starts_at, p.id))
+        with_placements = [MemoryPromotionRepository._with_placements(p) for p in promotions]
+        return [p for p in with_placements if p.is_active(at)]
+
+    @staticmethod
+    async def record_placement(promotion_id: str, target: str) -> None:
+        if promotion_id not in store.promotions:
+            raise ValueError(f"Promotion {promotion_id} does not exist")
+        store.placements.append((promotion_id, target, clock.now()))
+
+    @staticmethod
+    async def get_report(start_date: datetime, end_date: datetime) -> List[dict]:
+        placements = Counter(
+            promotion_id
+            for promotion_id, _, placed_at in store.placements
+            if start_date <= placed_at < end_date
+        )
+        promotions = sorted(
+            (store.promotions[promotion_id] for promotion_id in placements),
+            key=lambda p: (p.sponsor, p.id),
+        )
+        return [
+            {
+                "id": p.id,
+                "sponsor": p.sponsor,
+                "post_link": p.post_link,
+                "starts_at": p.starts_at,
+                "ends_at": p.ends_at,
+                "price_per_placement": p.price_per_placement,
+                "currency": p.currency,
+                "placements": placements[p.id],
+                "amount": placements[p.id] * p.price_per_placement,
+            }
+            for p in promotions
+        ]
+
+    @staticmethod
+    async def delete(promotion_id: str) -> None:
+        store.promotions.pop(promotion_id, None)
+        store.placements[:] = [p for p in store.placements if p[0] != promotion_id]
+
+
+class MemoryPostDeliveryRepository:
+    """In-memory PostDeliveryRepository."""
+
+    @staticmethod
+    async def get_delivered_links(target: str, links: List[str]) -> Set[str]:
+        return {link for link in links if (link, target) in store.deliveries}
+
+    @staticmethod
+    async def record(link: str, target: str, external_id: Optional[str] = None) -> None:
+        if link not in store.posts:
+            raise ValueError(f"Post {link} does not exist")
+        store.deliveries[(link, target)] = (external_id, clock.now(), None)
+
+    @staticmethod
+    async def get_recent(target: str, since: datetime) -> List[Tuple[str, str]]:
+        recent = sorted(
+            (delivered_at, link, external_id)
+            for (link, name), (external_id, delivered_at, _) in store.deliveries.items()
+            if name == target and delivered_at >= since and external_id is not None
+        )
+        return [(link, external_id) for _, link, external_id in recent]
+
+    @staticmethod
+    async def set_shares(link: str, target: str, shares: int) -> None:
+        if (link, target) in store.deliveries:
+            external_id, delivered_at, _ = store.deliveries[(link, target)]
+            store.deliveries[(link, target)] = (external_id, delivered_at, shares)
+
+
+class MemoryTranslationRepository:
+    """In-memory TranslationRepository."""
+
+    @staticmethod
+    async def get_many(source_hashes: List[str], language: str) -> Dict[str, str]:
+        return {
+            source_hash: store.translations[(source_hash, language)]
+            for source_hash in source_hashes
+            if (source_hash, language) in store.translations
+        }
+
+    @staticmethod
+    async def save(source_hash: str, language: str, text: str, provider: str) -> None:
+        store.translations[(source_hash, language)] = text
+
+
+class MemoryLLMCacheRepository:
+    """In-memory LLMCacheRepository."""
+
+    @staticmethod
+    async def get_many(content_hashes: List[str], purpose: str) -> Dict[str, str]:
+        return {
+            content_hash: store.llm_cache[(content_hash, purpose)]
+            for content_hash in content_hashes
+            if (content_hash, purpose) in store.llm_cache
+        }
+
+    @staticmethod
+    async def save(content_hash: str, purpose: str, text: str, model: str) -> None:
+        store.llm_cache[(content_hash, purpose)] = text
+
+
+class MemoryLLMUsageRepository:
+    """In-memory LLMUsageRepository."""
+
+    @staticmethod
+    async def add(tenant: str, day: date, tokens: int) -> None:
+        store.llm_usage[(tenant, day)] = store.llm_usage.get((tenant, day), 0) + tokens
+
+    @staticmethod
+    async def get_day(day: date) -> Dict[str, int]:
+        usage = store.llm_usage.items()
+        return {tenant: tokens for (tenant, used_on), tokens in usage if used_on == day}
+
+
+class MemorySeriesRepository:
+    """In-memory SeriesRepository."""
+
+    @staticmethod
+    def _with_count(series: Series) -> Series:
+        events = sum(1 for post in store.posts.values() if post.series_key == series.key)
+        return replace(series, event_count=events)
+
+    @staticmethod
+    async def create(series: Series) -> None:
+        if series.key not in store.series:
+            store.series[series.key] = replace(series, event_count=0, created_at=clock.now())
+
+    @staticmethod
+    async def upsert(series: Series) -> None:
+        existing = store.series.get(series.key)
+        created_at = existing.created_at if existing else series.created_at or clock.now()
+        store.series[series.key] = replace(series, event_count=0, created_at=created_at)
+
+    @staticmethod
+    async def get_all() -> List[Series]:
+        series = sorted(store.series.values(), key=lambda s: s.created_at)
+        return [MemorySeriesRepository._with_count(s) for s in series]
+
+    @staticmethod
+    async def add_post(link: str, key: str) -> None:
+        post = store.posts.get(link)
+        if post and post.series_key is None:
+            post.series_key = key
+
+    @staticmethod
+    async def get_many(keys: List[str]) -> Dict[str, Series]:
+        return {
+            key: MemorySeriesRepository._with_count(store.series[key])
+            for key in keys
+            if key in store.series
+        }
+
+
+class MemoryInteractionRepository:
+    """In-memory InteractionRepository."""
+
+    @staticmethod
+    async def record(link: str, kind: str) -> None:
+        if link not in store.posts:
+            raise ValueError(f"Post {link} does not exist")
+        key = (link, clock.today(), kind)
+        store.interactions[key] = store.interactions.get(key, 0) + 1
+
+    @staticmethod
+    async def get_totals(start_date: date, end_date: date) -> List[dict]:
+        totals: Dict[str, dict] = {}
+        for (link, day, kind), number in store.interactions.items():
+            if start_date <= day < end_date:
+                tags = store.posts[link].tags
+                row = totals.setdefault(
+                    link,
+                    {
+                        "link": link,
+                        "tags": list(tags) if tags else None,
+                        "views": None,
+                        "clicks": None,
+                    },
+                )
+                # SUM() of no rows is NULL
+                column = "views" if kind == "view" else "clicks" if kind == "click" else None
+                if column:
+                    row[column] = (row[column] or 0) + number
+        return [totals[link] for link in sorted(totals)]
+
+    @staticmethod
+    async def get_reach(since: datetime, limit: int = 50) -> List[dict]:
+        rows: Dict[str, dict] = {}
+
+        def row(link: str) -> dict:
+            return rows.setdefault(
+                link,
+                {"link": link, "posts": 0, "shares": 0, "views": 0, "clicks": 0, "reach": 0},
+            )
+
+        for (link, _), (_, _, shares) in store.deliveries.items():
+            row(link)["posts"] += 1
+            row(link)["shares"] += shares or 0
+        for (link, _, kind), number in store.interactions.items():
+            totals = row(link)
+            if kind in ("view", "click"):
+                totals[f"{kind}s"] += number
+        reach = [
+            dict(totals, reach=totals["views"] + totals["shares"])
+            for link, totals in rows.items()
+            if store.posts[link].pub_date and store.posts[link].pub_date >= since
+        ]
+        reach.sort(key=lambda totals: (-totals["reach"], totals["link"]))
+        return reach[:limit]
+
+    @staticmethod
+    async def get_daily(since: Optional[date] = None) -> List[dict]:
+        rows = [
+            {"link": link, "day": day, "kind": kind, "count": number}
+            for (link, day, kind), number in store.interactions.items()
+            if since is None or day >= since
+        ]
+        return sorted(rows, key=lambda row: (row["day"], row["link"], row["kind"]))
+
+
+class MemoryPollRunRepository:
+    """In-memory PollRunRepository."""
+
+    @staticmethod
+    async def start(run_id: Optional[str] = None) -> str:
+        if run_id in store.poll_runs:
+            store.poll_runs[run_id].finished_at = None
+            return run_id
+        run_id = run_id or new_id()
+        store.poll_runs[run_id] = PollRun(id=run_id, started_at=clock.now())
+        return run_id
+
+    @staticmethod
+    async def add_items(run_id: str, items: List[PollRunItem]) -> None:
+        if run_id not in store.poll_runs:
+            raise ValueError(f"Poll run {run_id} does not exist")
+        positions = {
+            (item.source, item.link): number
+            for number, item in enumerate(store.poll_run_items)
+            if item.run_id == run_id
+        }
+        for item in items:
+            stored = replace(item, run_id=run_id, started_at=None)
+            position = positions.get((item.source, item.link))
+            if position is None:
+                positions[(item.source, item.link)] = len(store.poll_run_items)
+                store.poll_run_items.append(stored)
+            else:
+                store.poll_run_items[position] = stored
+
+    @staticmethod
+    async def finish(run_id: str, items: List[PollRunItem]) -> None:
+        keys = {(item.source, item.link) for item in items}
+        store.poll_run_items = [
+            item
+            for item in store.poll_run_items
+            if item.run_id != run_id or (item.source, item.link) in keys
+        ]
+        await MemoryPollRunRepository.add_items(run_id, items)
+        store.poll_runs[run_id].finished_at = clock.now()
+
+    @staticmethod
+    async def get_recent(limit: int = 20) -> List[PollRun]:
+        runs = sorted(store.poll_runs.values(), key=lambda r: r.id, reverse=True)[:limit]
+        return [
+            replace(
+                run,
+                counts=dict(
+                    Counter(item.outcome for item in store.poll_run_items if item.run_id == run.id)
+                ),
+            )
+            for run in runs
+        ]
+
+    @staticmethod
+    async def get_items(run_id: str, outcome: Optional[str] = None) -> List[PollRunItem]:
+        return [
+            replace(item, started_at=store.poll_runs[run_id].started_at)
+            for item in store.poll_run_items
+            if item.run_id == run_id and (outcome is None or item.outcome == outcome)
+        ]
+
+    @staticmethod
+    async def get_history(link: str, limit: int = 20) -> List[PollRunItem]:
+        items = [item for item in store.poll_run_items if item.link == link]
+        # Items are appended run by run, so the last ones are the newest
+        return [
+            replace(item, started_at=store.poll_runs[item.run_id].started_at)
+            for item in reversed(items)
+        ][:limit]
+
+    @staticmethod
+    async def delete_before(before: datetime) -> int:
+        old = {run_id for run_id, run in store.poll_runs.items() if run.started_at < before}
+        for run_id in old:
+            del store.poll_runs[run_id]
+        store.poll_run_items = [item for item in store.poll_run_items if item.run_id not in old]
+        return len(old)
+
+
+class MemorySubmissionRepository:
+    """In-memory SubmissionRepository."""
+
+    @staticmethod
+    async def create(submission: Submission) -> str:
+        submission_id = new_id()
+        store.submissions[submission_id] = replace(
+            submission,
+            id=submission_id,
+            status="pending",
+            note=None,
+            created_at=clock.now(),
+            reviewed_at=None,
+        )
+        return submission_id
+
+    @staticmethod
+    async def get_by_id(submission_id: str) -> Optional[Submission]:
+        submission = store.submissions.get(submission_id)
+        return replace(submission) if submission else None
+
+    @staticmethod
+    async def get_by_status(status: str = "pending", limit: int = 100) -> List[Submission]:
+        submissions = [s for s in store.submissions.values() if s.status == status]
+        return [replace(s) for s in sorted(submissions, key=lambda s: s.id)][:limit]
+
+    @staticmethod
+    async def is_pending(link: str) -> bool:
+        return any(s.link == link and s.status == "pending" for s in store.submissions.values())
+
+    @staticmethod
+    async def review(submission_id: str, status: str, note: Optional[str] = None) -> bool:
+        submission = store.submissions.get(submission_id)
+        if submission is None or submission.status != "pending":
+            return False
+        submission.status, submission.note, submission.reviewed_at = status, note, clock.now()
+        return True
+
+
+class MemoryDigestDraftRepository:
+    """In-memory DigestDraftRepository."""
+
+    @staticmethod
+    async def create(draft: DigestDraft) -> str:
+        draft_id = new_id()
+        now = clock.now()
+        store.digest_drafts[draft_id] = DigestDraft(
+            id=draft_id,
+            publisher=draft.publisher,
+            destination=draft.destination,
+            links=list(draft.links),
+            intro=draft.intro,
+            created_at=now,
+            updated_at=now,
+        )
+        return draft_id
+
+    @staticmethod
+    async def get_by_id(draft_id: str) -> Optional[DigestDraft]:
+        draft = store.digest_drafts.get(draft_id)
+        return replace(draft, links=list(draft.links)) if draft else None
+
+    @staticmethod
+    async def get_by_status(statuses: List[str], limit: int = 100) -> List[DigestDraft]:
+        drafts = [d for d in store.digest_drafts.values() if d.status in statuses]
+        drafts.sort(key=lambda d: d.id, reverse=True)
+        return [replace(d, links=list(d.links)) for d in drafts[:limit]]
+
+    @staticmethod
+    async def update(draft_id: str, links: List[str], intro: Optional[str]) -> bool:
+        draft = store.digest_drafts.get(draft_id)
+        if draft is None or draft.status != "draft":
+            return False
+        draft.links, draft.intro, draft.updated_at = list(links), intro, clock.now()
+        return True
+
+    @staticmethod
+    async def set_status(
+        draft_id: str, status: str, expected: str, text: Optional[str] = None
+    ) -> bool:
+        draft = store.digest_drafts.get(draft_id)
+        if draft is None or draft.status != expected:
+            return False
+        draft.status, draft.updated_at = status, clock.now()
+        if text is not None:
+            draft.text = text
+        if status == "sent":
+            draft.sent_at = draft.updated_at
+        return True
+
+
+class MemoryPublishFailureRepository:
+    """In-memory PublishFailureRepository."""
+
+    @staticmethod
+    async def create(failure: PublishFailure) -> str:
+        failure_id = new_id()
+        now = clock.now()
+        store.publish_failures[failure_id] = replace(
+            _copy_failure(failure),
+            id=failure_id,
+            created_at=now,
+            updated_at=now,
+        )
+        return failure_id
+
+    @staticmethod
+    async def get_by_id(failure_id: str) -> Optional[PublishFailure]:
+        failure = store.publish_failures.get(failure_id)
+        return _copy_failure(failure) if failure else None
+
+    @staticmethod
+    async def get_by_status(statuses: List[str], limit: int = 100) -> List[PublishFailure]:
+        failures = [f for f in store.publish_failures.values() if f.status in statuses]
+        failures.sort(key=lambda f: f.id, reverse=True)
+        return [_copy_failure(f) for f in failures[:limit]]
+
+    @staticmethod
+    async def get_due(at: datetime, limit: int = 100) -> List[PublishFailure]:
+        failures = [
+            f
+            for f in store.publish_failures.values()
+            if f.status == "pending" and f.next_attempt_at is not None and f.next_attempt_at <= at
+        ]
+        failures.sort(key=lambda f: (f.next_attempt_at, f.id))
+        return [_copy_failure(f) for f in failures[:limit]]
+
+    @staticmethod
+    async def set_status(failure_id: str, status: str, expected: List[str]) -> bool:
+        failure = store.publish_failures.get(failure_id)
+        if failure is None or failure.status not in expected:
+            return False
+        failure.status, failure.updated_at = status, clock.now()
+        return True
+
+    @staticmethod
+    async def record_attempt(
+        failure_id: str,
+        status: str,
+        error: Optional[str] = None,
+        next_attempt_at: Optional[datetime] = None,
+    ) -> bool:
+        failure = store.publish_failures.get(failure_id)
+        if failure is None or failure.status != "sending":
+            return False
+        failure.status, failure.next_attempt_at = status, next_attempt_at
+        if error is not None:
+            failure.error = error
+        failure.attempts += 1
+        failure.updated_at = clock.now()
+        return True
+
+
+class MemoryQuotaRepository:
+    """In-memory QuotaRepository."""
+
+    @staticmethod
+    async def upsert(quota: Quota) -> None:
+        store.quotas[(quota.api, quota.name)] = replace(quota)
+
+    @staticmethod
+    async def get_all() -> List[Quota]:
+        return [replace(store.quotas[key]) for key in sorted(store.quotas)]
+
+
+class MemoryBandwidthRepository:
+    """In-memory BandwidthRepository."""
+
+    @staticmethod
+    async def add(source: str, day: date, size: int, fetches: int = 1) -> None:
+        total = store.bandwidth.setdefault((source, day), SourceBandwidth(source, day))
+        total.bytes += size
+        total.fetches += fetches
+
+    @staticmethod
+    async def get_since(since: date, source: Optional[str] = None) -> List[SourceBandwidth]:
+        totals = [
+            replace(total)
+            for (name, day), total in store.bandwidth.items()
+            if day >= since and (source is None or name == source)
+        ]
+        totals.sort(key=lambda t: t.source)
+        totals.sort(key=lambda t: (t.day, t.bytes), reverse=True)
+        return totals
+
+
+class MemoryPostRevisionRepository:
+    """In-memory PostRevisionRepository."""
+
+    @staticmethod
+    async def add(revision: PostRevision) -> bool:
+        if revision.link not in store.posts:
+            raise ValueError(f"Post {revision.link} does not exist")
+        key = (revision.link, revision.revision)
+        if key in store.revisions:
+            return False
+        store.revisions[key] = replace(revision, created_at=revision.created_at or clock.now())
+        return True
+
+    @staticmethod
+    async def get_by_link(link: str) -> List[PostRevision]:
+        return [replace(store.revisions[key]) for key in sorted(store.revisions) if key[0] == link]
+
+    @staticmethod
+    async def get_by_links(links: List[str]) -> List[PostRevision]:
+        wanted = set(links)
+        return [
+            replace(store.revisions[key]) for key in sorted(store.revisions) if key[0] in wanted
+        ]
+
+
+class MemoryFieldOverrideRepository:
+    """In-memory FieldOverrideRepository."""
+
+    @staticmethod
+    async def set(override: FieldOverride) -> None:
+        if override.link not in store.posts:
+            raise ValueError(f"Post {override.link} does not exist")
+        store.overrides[(override.link, override.field)] = replace(
+            override, created_at=override.created_at or clock.now()
+        )
+
+    @staticmethod
+    async def get_by_link(link: str) -> List[FieldOverride]:
+        return [replace(store.overrides[key]) for key in sorted(store.overrides) if key[0] == link]
+
+    @staticmethod
+    async def get_by_links(links: List[str]) -> List[FieldOverride]:
+        wanted = set(links)
+        return [
+            replace(store.overrides[key]) for key in sorted(store.overrides) if key[0] in wanted
+        ]
+
+    @staticmethod
+    async def get_since(since: datetime) -> List[FieldOverride]:
+        found = [replace(o) for o in store.overrides.values() if o.created_at >= since]
+        return sorted(found, key=lambda o: (o.created_at, o.link, o.field))
+
+    @staticmethod
+    async def delete(link: str, field: str) -> bool:
+        return store.overrides.pop((link, field), None) is not None
+
+
+MEMORY = Storage(
+    name="memory",
+    channels=MemoryTelegramChannelRepository,
+    posts=MemoryRSSPostRepository,
+    promotions=MemoryPromotionRepository,
+    deliveries=MemoryPostDeliveryRepository,
+    translations=MemoryTranslationRepository,
+    llm_cache=MemoryLLMCacheRepository,
+    llm_usage=MemoryLLMUsageRepository,
+    series=MemorySeriesRepository,
+    interactions=MemoryInteractionRepository,
+    poll_runs=MemoryPollRunRepository,
+    submissions=MemorySubmissionRepository,
+    digest_drafts=MemoryDigestDraftRepository,
+    publish_failures=MemoryPublishFailureRepository,
+    quotas=MemoryQuotaRepository,
+    bandwidth=MemoryBandwidthRepository,
+    revisions=MemoryPostRevisionRepository,
+    overrides=MemoryFieldOverrideRepository,
+)
+
+def install() -> None:
+    """Replace the methods of the Postgres repositories with the in-memory ones.
+
+    Code imports the repository classes directly, so the classes themselves
+    are switched rather than passed around.
+    """
+    for repository in fields(Storage):
+        if repository.name == "name":
+            continue
+        target = getattr(POSTGRES, repository.name)
+        source = getattr(MEMORY, repository.name)
+        for method, value in vars(source).items():
+            if isinstance(value, staticmethod) and not method.startswith("_"):
+                setattr(target, method, value)
@@ -1,9 +1,12 @@
 """Data models for RSS posts (dataclass representations)."""
 
-from dataclasses import dataclass, asdict
-from datetime import datetime
-from typing import Optional
-from email.utils import parsedate_to_datetime
+import json
+from dataclasses import dataclass, asdict, field
+from datetime import date, datetime
+from decimal import Decimal
+from typing import Dict, List, Optional
+
+from common.utils.dates import parse_feed_date
 
 
 @dataclass
@@ -16,6 +19,11 @@ class TelegramChannel:
     url: Optional[str] = None
     created_at: Optional[datetime] = None
     updated_at: Optional[datetime] = None
+    # Display name, taken from the channel's feed
+    title: Optional[str] = None
+    avatar_url: Optional[str] = None
+    # Section of the channel directory, set by operators
+    category: Optional[str] = None
 
     def to_dict(self) -> dict:
         """Convert to dictionary."""
@@ -31,6 +39,9 @@ class TelegramChannel:
             url=row.get("url"),
             created_at=row.get("created_at"),
             updated_at=row.get("updated_at"),
+            title=row.get("title"),
+            avatar_url=row.get("avatar_url"),
+            category=row.get("category"),
         )
 
 
@@ -46,6 +57,35 @@ class RSSPost:
     published_at: Optional[datetime] = None
     created_at: Optional[datetime] = None
     updated_at: Optional[datetime] = None
+    tags: Optional[List[str]] = None
+    summary: Optional[str] = None
+    title: Optional[str] = None
+    price_min: Optional[Decimal] = None
+    price_max: Optional[Decimal] = None
+    price_currency: Optional[str] = None
+    registration_required: bool = False
+    tickets_required: bool = False
+    limited_capacity: bool = False
+    event_format: Optional[str] = None
+    event_start: Optional[date] = None
+    event_end: Optional[date] = None
+    event_sessions: Optional[str] = None
+    series_key: Optional[str] = None
+    event_status: Optional[str] = None
+    # Categories the feed gave the item
+    categories: Optional[List[str]] = None
+    # Source name and stable ID of the feed item, to recognize it after its link changes
+    source_guid: Optional[str] = None
+    # Speakers and performers, and organizations named in the text
+    performers: Optional[List[str]] = None
+    organizations: Optional[List[str]] = None
+    # Phone numbers (E.164) and emails found in the text
+    contacts: Optional[List[str]] = None
+    # Last day to register or apply, and which of them (see common.event_dates.DEADLINE_KINDS)
+    deadline: Optional[date] = None
+    deadline_kind: Optional[str] = None
+    # Release time of an embargo the post was held under (see common.embargo)
+    embargo_until: Optional[datetime] = None
 
     def __post_init__(self):
         """Parse pub_date string into datetime if needed."""
@@ -56,36 +96,25 @@ class RSSPost:
     def _parse_datetime(date_str: str) -> datetime:
         """Parse datetime from string.
 
-        Supports:
-        - ISO 8601: '2026-01-10T10:00:00Z'
-        - RFC 2822: 'Thu, 08 Jan 2026 06:42:01 +0000'
+        Supports RFC 2822 ('Thu, 08 Jan 2026 06:42:01 +0000'), ISO 8601
+        ('2026-01-10T10:00:00Z') and the fallback formats of common.utils.dates.
 
         Returns timezone-naive datetime for PostgreSQL TIMESTAMP compatibility.
+
+        Raises:
+            UnparseableDate: If no format matches
         """
-        dt = None
+        return parse_feed_date(date_str)
 
-        # Try RFC 2822 format first (common in RSS feeds)
+    def media_urls(self) -> List[str]:
+        """Media URLs stored on the post (JSON list, or comma-separated in old rows)."""
+        if not self.media:
+            return []
         try:
-            dt = parsedate_to_datetime(date_str)
+            urls = json.loads(self.media)
         except (ValueError, TypeError):
-            pass
-
-        # Try ISO 8601 format
-        if dt is None:
-            try:
-                dt = datetime.fromisoformat(date_str.replace("Z", "+00:00"))
-            except (ValueError, TypeError):
-                pass
-
-        # If all fail, raise error
-        if dt is None:
-            raise ValueError(f"Unable to parse datetime from: {date_str}")
-
-        # Convert to timezone-naive (PostgreSQL TIMESTAMP expects naive datetimes)
-        if dt.tzinfo is not None:
-            dt = dt.replace(tzinfo=None)
-
-        return dt
+            urls = self.media.split(",")
+        return [url.strip() for url in urls if isinstance(url, str) and url.strip()]
 
     def to_dict(self) -> dict:
         """Convert to dictionary."""
@@ -103,4 +132,395 @@ class RSSPost:
             published_at=row.get("published_at"),
             created_at=row.get("created_at"),
             updated_at=row.get("updated_at"),
+            tags=list(row["tags"]) if row.get("tags") else None,
+            summary=row.get("summary"),
+            title=row.get("title"),
+            price_min=row.get("price_min"),
+            price_max=row.get("price_max"),
+            price_currency=row.get("price_currency"),
+            registration_required=row.get("registration_required") or False,
+            tickets_required=row.get("tickets_required") or False,
+            limited_capacity=row.get("limited_capacity") or False,
+            event_format=row.get("event_format"),
+            event_start=row.get("event_start"),
+            event_end=row.get("event_end"),
+            event_sessions=row.get("event_sessions"),
+            series_key=row.get("series_key"),
+            event_status=row.get("event_status"),
+            categories=list(row["categories"]) if row.get("categories") else None,
+            source_guid=row.get("source_guid"),
+            performers=list(row["performers"]) if row.get("performers") else None,
+            organizations=list(row["organizations"]) if row.get("organizations") else None,
+            contacts=list(row["contacts"]) if row.get("contacts") else None,
+            deadline=row.get("deadline"),
+            deadline_kind=row.get("deadline_kind"),
+            embargo_until=row.get("embargo_until"),
+        )
+
+
+@dataclass
+class Promotion:
+    """Dataclass representation of a sponsored post."""
+
+    post_link: str
+    sponsor: str
+    starts_at: datetime
+    ends_at: datetime
+    placement: str = "top"
+    target: Optional[str] = None
+    max_placements: Optional[int] = None
+    price_per_placement: Decimal = Decimal("0")
+    currency: str = "RUB"
+    # ULID, see common.ids
+    id: Optional[str] = None
+    placements: int = 0
+    created_at: Optional[datetime] = None
+    updated_at: Optional[datetime] = None
+
+    def is_active(self, at: datetime) -> bool:
+        """Check whether the promotion can be placed at the given time."""
+        if not (self.starts_at <= at < self.ends_at):
+            return False
+        return self.max_placements is None or self.placements < self.max_placements
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "Promotion":
+        """Create Promotion from database row."""
+        return Promotion(
+            id=row["id"],
+            post_link=row["post_link"],
+            sponsor=row["sponsor"],
+            starts_at=row["starts_at"],
+            ends_at=row["ends_at"],
+            placement=row.get("placement", "top"),
+            target=row.get("target"),
+            max_placements=row.get("max_placements"),
+            price_per_placement=row.get("price_per_placement") or Decimal("0"),
+            currency=row.get("currency", "RUB"),
+            placements=row.get("placements") or 0,
+            created_at=row.get("created_at"),
+            updated_at=row.get("updated_at"),
+        )
+
+
+@dataclass
+class Series:
+    """Dataclass representation of a festival or another group of related events."""
+
+    key: str
+    name: str
+    parent_link: Optional[str] = None
+    event_count: int = 0
+    created_at: Optional[datetime] = None
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "Series":
+        """Create Series from database row."""
+        return Series(
+            key=row["key"],
+            name=row["name"],
+            parent_link=row.get("parent_link"),
+            event_count=row.get("event_count") or 0,
+            created_at=row.get("created_at"),
+        )
+
+
+@dataclass
+class PollRun:
+    """Dataclass representation of one run of the RSS reader."""
+
+    # ULID, see common.ids
+    id: str
+    started_at: datetime
+    finished_at: Optional[datetime] = None
+    # Outcome -> number of items, see rss_reader.core.report
+    counts: Dict[str, int] = field(default_factory=dict)
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "PollRun":
+        """Create PollRun from database row."""
+        counts = row.get("counts") or {}
+        if isinstance(counts, str):
+            counts = json.loads(counts)
+        return PollRun(
+            id=row["id"],
+            started_at=row["started_at"],
+            finished_at=row.get("finished_at"),
+            counts=counts,
+        )
+
+
+@dataclass
+class PollRunItem:
+    """What a poll run did with one feed item."""
+
+    source: str
+    # Post link, or the feed URL when the whole source failed
+    link: str
+    outcome: str
+    reason: Optional[str] = None
+    run_id: Optional[str] = None
+    started_at: Optional[datetime] = None
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "PollRunItem":
+        """Create PollRunItem from database row."""
+        return PollRunItem(
+            source=row["source"],
+            link=row["link"],
+            outcome=row["outcome"],
+            reason=row.get("reason"),
+            run_id=row.get("run_id"),
+            started_at=row.get("started_at"),
+        )
+
+
+@dataclass
+class Submission:
+    """An event submitted by an organizer, waiting for a moderator."""
+
+    link: str
+    content: str
+    title: Optional[str] = None
+    # How moderators can reach the organizer; never published
+    contact: Optional[str] = None
+    # Partner whose token was used, None for submissions through the captcha form
+    partner: Optional[str] = None
+    # pending, approved or rejected
+    status: str = "pending"
+    # Moderator's reason for a rejection
+    note: Optional[str] = None
+    # ULID, see common.ids
+    id: Optional[str] = None
+    created_at: Optional[datetime] = None
+    reviewed_at: Optional[datetime] = None
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "Submission":
+        """Create Submission from database row."""
+        return Submission(
+            id=row["id"],
+            link=row["link"],
+            content=row["content"],
+            title=row.get("title"),
+            contact=row.get("contact"),
+            partner=row.get("partner"),
+            status=row.get("status", "pending"),
+            note=row.get("note"),
+            created_at=row.get("created_at"),
+            reviewed_at=row.get("reviewed_at"),
+        )
+
+
+@dataclass
+class DigestDraft:
+    """A digest waiting for a moderator before it is sent."""
+
+    publisher: str
+    destination: str
+    # Post links in the order they are published
+    links: List[str] = field(default_factory=list)
+    intro: Optional[str] = None
+    # draft (editable), locked (rendered, ready to send), sent or discarded
+    status: str = "draft"
+    # Final message, rendered when the draft is locked
+    text: Optional[str] = None
+    # ULID, see common.ids
+    id: Optional[str] = None
+    created_at: Optional[datetime] = None
+    updated_at: Optional[datetime] = None
+    sent_at: Optional[datetime] = None
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "DigestDraft":
+        """Create DigestDraft from database row."""
+        return DigestDraft(
+            id=row["id"],
+            publisher=row["publisher"],
+            destination=row["destination"],
+            links=list(row.get("links") or []),
+            intro=row.get("intro"),
+            status=row.get("status", "draft"),
+            text=row.get("text"),
+            created_at=row.get("created_at"),
+            updated_at=row.get("updated_at"),
+            sent_at=row.get("sent_at"),
+        )
+
+
+@dataclass
+class PublishFailure:
+    """A message a publisher failed to send, waiting to be retried."""
+
+    publisher: str
+    destination: str
+    message: str
+    # Posts to mark published once the message goes out
+    links: List[str] = field(default_factory=list)
+    # Promotions placed in the message, billed once it goes out
+    promotion_ids: List[str] = field(default_factory=list)
+    error: Optional[str] = None
+    attempts: int = 1
+    # pending (retried on schedule), sending, failed (retries exhausted), sent or discarded
+    status: str = "pending"
+    next_attempt_at: Optional[datetime] = None
+    # ULID, see common.ids
+    id: Optional[str] = None
+    created_at: Optional[datetime] = None
+    updated_at: Optional[datetime] = None
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "PublishFailure":
+        """Create PublishFailure from database row."""
+        return PublishFailure(
+            id=row["id"],
+            publisher=row["publisher"],
+            destination=row["destination"],
+            message=row["message"],
+            links=list(row.get("links") or []),
+            promotion_ids=list(row.get("promotion_ids") or []),
+            error=row.get("error"),
+            attempts=row.get("attempts", 1),
+            status=row.get("status", "pending"),
+            next_attempt_at=row.get("next_attempt_at"),
+            created_at=row.get("created_at"),
+            updated_at=row.get("updated_at"),
+        )
+
+
+@dataclass
+class Quota:
+    """Rate limit of an external API, as last reported in its response headers."""
+
+    api: str
+    # requests, tokens, ...
+    name: str
+    limit: Optional[int] = None
+    remaining: Optional[int] = None
+    reset_at: Optional[datetime] = None
+    updated_at: Optional[datetime] = None
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "Quota":
+        """Create Quota from database row."""
+        return Quota(
+            api=row["api"],
+            name=row["name"],
+            limit=row.get("quota_limit"),
+            remaining=row.get("remaining"),
+            reset_at=row.get("reset_at"),
+            updated_at=row.get("updated_at"),
+        )
+
+
+@dataclass
+class SourceBandwidth:
+    """Bytes the RSS reader downloaded from one source on one day."""
+
+    source: str
+    day: date
+    bytes: int = 0
+    fetches: int = 0
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "SourceBandwidth":
+        """Create SourceBandwidth from database row."""
+        return SourceBandwidth(
+            source=row["source"],
+            day=row["day"],
+            bytes=row.get("bytes") or 0,
+            fetches=row.get("fetches") or 0,
+        )
+
+
+@dataclass
+class PostRevision:
+    """An edit of a stored post, as a diff against its previous revision (see common.revisions)."""
+
+    link: str
+    # 1 for the first edit; revision 0 is the content stored on the post
+    revision: int
+    delta: str
+    # SHA-256 of the full text of the revision
+    content_hash: str
+    created_at: Optional[datetime] = None
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "PostRevision":
+        """Create PostRevision from database row."""
+        return PostRevision(
+            link=row["link"],
+            revision=row["revision"],
+            delta=row["delta"],
+            content_hash=row["content_hash"],
+            created_at=row.get("created_at"),
+        )
+
+
+@dataclass
+class FieldOverride:
+    """A moderator's correction of a field extracted from a post's text."""
+
+    link: str
+    # Name of the RSSPost field, one of those extract_fields works out
+    field: str
+    # The corrected value as JSON, like in an extraction baseline
+    value: str
+    # What extraction gave before, in the same form
+    extracted: Optional[str] = None
+    created_at: Optional[datetime] = None
+
+    def to_dict(self) -> dict:
+        """Convert to dictionary."""
+        return asdict(self)
+
+    @staticmethod
+    def from_row(row: dict) -> "FieldOverride":
+        """Create FieldOverride from database row."""
+        return FieldOverride(
+            link=row["link"],
+            field=row["field"],
+            value=row["value"],
+            extracted=row.get("extracted"),
+            created_at=row.get("created_at"),
         )
@@ -1,9 +1,25 @@
 """Repository layer for RSS posts database operations."""
 
-from typing import List, Optional
-from datetime import datetime
+from typing import Dict, List, Optional, Set, Tuple
+from datetime import date, datetime
+
+from common.ids import new_id
 from .session import db
-from .models import RSSPost, TelegramChannel
+from .models import (
+    DigestDraft,
+    FieldOverride,
+    PollRun,
+    PollRunItem,
+    PostRevision,
+    Promotion,
+    PublishFailure,
+    Quota,
+    RSSPost,
+    Series,
+    SourceBandwidth,
+    Submission,
+    TelegramChannel,
+)
 
 
 class TelegramChannelRepository:
@@ -45,8 +61,8 @@ class TelegramChannelRepository:
         """
         query = """
             INSERT INTO telegram_channels (
-                channel_id, channel_name, description, url
-            ) VALUES ($1, $2, $3, $4)
+                channel_id, channel_name, description, url, title, avatar_url, category
+            ) VALUES ($1, $2, $3, $4, $5, $6, $7)
             RETURNING channel_id
         """
         channel_id = await db.fetchval(
@@ -55,6 +71,9 @@ class TelegramChannelRepository:
             channel.channel_name,
             channel.description,
             channel.url,
+            channel.title,
+            channel.avatar_url,
+            channel.category,
         )
         return channel_id
 
@@ -66,6 +85,9 @@ class TelegramChannelRepository:
             SET channel_name = $2,
                 description = $3,
                 url = $4,
+                title = $5,
+                avatar_url = $6,
+                category = $7,
                 updated_at = CURRENT_TIMESTAMP
             WHERE channel_id = $1
         """
@@ -75,6 +97,56 @@ class TelegramChannelRepository:
             channel.channel_name,
             channel.description,
             channel.url,
+            channel.title,
+            channel.avatar_url,
+            channel.category,
+        )
+
+    @staticmethod
+    async def set_title(channel_id: int, title: str) -> None:
+        """Store the display name a channel's feed gives it."""
+        query = """
+            UPDATE telegram_channels
+            SET title = $2, updated_at = CURRENT_TIMESTAMP
+            WHERE channel_id = $1 AND title IS DISTINCT FROM $2
+        """
+        await db.execute(query, channel_id, title)
+
+    @staticmethod
+    async def upsert(channel: TelegramChannel) -> None:
+        """Insert a channel or overwrite the existing one with the same ID.
+
+        Timestamps are preserved when present, so restored channels keep their history.
+        """
+        query = """
+            INSERT INTO telegram_channels (
+                channel_id, channel_name, description, url, created_at, updated_at,
+                title, avatar_url, category
+            ) VALUES (
+                $1, $2, $3, $4,
+                COALESCE($5, CURRENT_TIMESTAMP), COALESCE($6, CURRENT_TIMESTAMP),
+                $7, $8, $9
+            )
+            ON CONFLICT (channel_id) DO UPDATE
+            SET channel_name = EXCLUDED.channel_name,
+                description = EXCLUDED.description,
+                url = EXCLUDED.url,
+                updated_at = EXCLUDED.updated_at,
+                title = EXCLUDED.title,
+                avatar_url = EXCLUDED.avatar_url,
+                category = EXCLUDED.category
+        """
+        await db.execute(
+            query,
+            channel.channel_id,
+            channel.channel_name,
+            channel.description,
+            channel.url,
+            channel.created_at,
+            channel.updated_at,
+            channel.title,
+            channel.avatar_url,
+            channel.category,
         )
 
     @staticmethod
@@ -99,8 +171,14 @@ class RSSPostRepository:
         """
         query = """
             INSERT INTO rss_posts (
-                link, content, pub_date, media
-            ) VALUES ($1, $2, $3, $4)
+                link, content, pub_date, media, tags, price_min, price_max, price_currency,
+                registration_required, tickets_required, limited_capacity, event_format,
+                event_start, event_end, event_sessions, series_key, event_status, categories,
+                source_guid, performers, organizations, contacts, deadline, deadline_kind
+            ) VALUES (
+                $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+                $19, $20, $21, $22, $23, $24
+            )
             RETURNING link
         """
         link = await db.fetchval(
@@ -109,9 +187,117 @@ class RSSPostRepository:
             post.content,
             post.pub_date,
             post.media,
+            post.tags,
+            post.price_min,
+            post.price_max,
+            post.price_currency,
+            post.registration_required,
+            post.tickets_required,
+            post.limited_capacity,
+            post.event_format,
+            post.event_start,
+            post.event_end,
+            post.event_sessions,
+            post.series_key,
+            post.event_status,
+            post.categories,
+            post.source_guid,
+            post.performers,
+            post.organizations,
+            post.contacts,
+            post.deadline,
+            post.deadline_kind,
         )
         return link
 
+    @staticmethod
+    async def upsert(post: RSSPost) -> None:
+        """Insert a post or overwrite the existing one with the same link.
+
+        Unlike create(), all fields including publication state and timestamps
+        are written, which makes it suitable for restoring backups.
+        """
+        query = """
+            INSERT INTO rss_posts (
+                link, content, pub_date, media, is_published, published_at,
+                created_at, updated_at, tags, summary, title,
+                price_min, price_max, price_currency,
+                registration_required, tickets_required, limited_capacity, event_format,
+                event_start, event_end, event_sessions, series_key, event_status, categories,
+                source_guid, performers, organizations, contacts, deadline, deadline_kind,
+                embargo_until
+            ) VALUES (
+                $1, $2, $3, $4, $5, $6,
+                COALESCE($7, CURRENT_TIMESTAMP), COALESCE($8, CURRENT_TIMESTAMP), $9, $10, $11,
+                $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27,
+                $28, $29, $30, $31
+            )
+            ON CONFLICT (link) DO UPDATE
+            SET content = EXCLUDED.content,
+                pub_date = EXCLUDED.pub_date,
+                media = EXCLUDED.media,
+                is_published = EXCLUDED.is_published,
+                published_at = EXCLUDED.published_at,
+                updated_at = EXCLUDED.updated_at,
+                tags = EXCLUDED.tags,
+                summary = EXCLUDED.summary,
+                title = EXCLUDED.title,
+                price_min = EXCLUDED.price_min,
+                price_max = EXCLUDED.price_max,
+                price_currency = EXCLUDED.price_currency,
+                registration_required = EXCLUDED.registration_required,
+                tickets_required = EXCLUDED.tickets_required,
+                limited_capacity = EXCLUDED.limited_capacity,
+                event_format = EXCLUDED.event_format,
+                event_start = EXCLUDED.event_start,
+                event_end = EXCLUDED.event_end,
+                event_sessions = EXCLUDED.event_sessions,
+                series_key = EXCLUDED.series_key,
+                event_status = EXCLUDED.event_status,
+                categories = EXCLUDED.categories,
+                source_guid = EXCLUDED.source_guid,
+                performers = EXCLUDED.performers,
+                organizations = EXCLUDED.organizations,
+                contacts = EXCLUDED.contacts,
+                deadline = EXCLUDED.deadline,
+                deadline_kind = EXCLUDED.deadline_kind,
+                embargo_until = EXCLUDED.embargo_until
+        """
+        await db.execute(
+            query,
+            post.link,
+            post.content,
+            post.pub_date,
+            post.media,
+            post.is_published,
+            post.published_at,
+            post.created_at,
+            post.updated_at,
+            post.tags,
+            post.summary,
+            post.title,
+            post.price_min,
+            post.price_max,
+            post.price_currency,
+            post.registration_required,
+            post.tickets_required,
+            post.limited_capacity,
+            post.event_format,
+            post.event_start,
+            post.event_end,
+            post.event_sessions,
+            post.series_key,
+            post.event_status,
+            post.categories,
+            post.source_guid,
+            post.performers,
+            post.organizations,
+            post.contacts,
+            post.deadline,
+            post.deadline_kind,
+            post.embargo_until,
+        )
+
     @staticmethod
     async def get_by_link(link: str) -> Optional[RSSPost]:
         """Get post by link (URL)."""
@@ -119,6 +305,15 @@ class RSSPostRepository:
         row = await db.fetchrow(query, link)
         return RSSPost.from_row(row) if row else None
 
+    @staticmethod
+    async def get_by_source_guid(source_guid: str) -> Optional[RSSPost]:
+        """Get the oldest post saved for a feed item (see RSSPost.source_guid)."""
+        query = """
+            SELECT * FROM rss_posts WHERE source_guid = $1 ORDER BY created_at, link LIMIT 1
+        """
+        row = await db.fetchrow(query, source_guid)
+        return RSSPost.from_row(row) if row else None
+
     @staticmethod
     async def get_all(
         limit: int = 100,
@@ -172,6 +367,72 @@ class RSSPostRepository:
         rows = await db.fetch(query, start_date, end_date, limit)
         return [RSSPost.from_row(row) for row in rows]
 
+    @staticmethod
+    async def get_released(
+        start_date: datetime, end_date: datetime, limit: int = 1000, only_unpublished: bool = True
+    ) -> List[RSSPost]:
+        """Get posts whose embargo ended within a date range, however old they are.
+
+        Args:
+            start_date: Start date
+            end_date: End date
+            limit: Maximum number of posts to return
+            only_unpublished: If True, only return unpublished posts
+
+        Returns:
+            List of RSSPost instances, the latest released first
+        """
+        published = "AND is_published = false" if only_unpublished else ""
+        query = f"""
+            SELECT * FROM rss_posts
+            WHERE embargo_until >= $1 AND embargo_until <= $2
+            {published}
+            ORDER BY embargo_until DESC
+            LIMIT $3
+        """
+        rows = await db.fetch(query, start_date, end_date, limit)
+        return [RSSPost.from_row(row) for row in rows]
+
+    @staticmethod
+    async def set_embargo(link: str, until: datetime) -> None:
+        """Record the release time of the embargo a post is held under."""
+        query = "UPDATE rss_posts SET embargo_until = $2 WHERE link = $1"
+        await db.execute(query, link, until)
+
+    @staticmethod
+    async def get_updated_since(
+        since: Optional[datetime], after_link: str = "", limit: int = 500
+    ) -> List[RSSPost]:
+        """Get posts updated after a (updated_at, link) position, oldest first.
+
+        Keyset pagination keeps batches stable even when many posts share
+        the same updated_at timestamp.
+
+        Args:
+            since: Exclusive lower bound for updated_at (None for all posts)
+            after_link: Link of the last post already seen at `since`
+            limit: Maximum number of posts to return
+
+        Returns:
+            List of RSSPost instances
+        """
+        if since is None:
+            query = """
+                SELECT * FROM rss_posts
+                ORDER BY updated_at ASC, link ASC
+                LIMIT $1
+            """
+            rows = await db.fetch(query, limit)
+        else:
+            query = """
+                SELECT * FROM rss_posts
+                WHERE (updated_at, link) > ($1, $2)
+                ORDER BY updated_at ASC, link ASC
+                LIMIT $3
+            """
+            rows = await db.fetch(query, since, after_link, limit)
+        return [RSSPost.from_row(row) for row in rows]
+
     @staticmethod
     async def delete(link: str) -> None:
         """Delete a post."""
@@ -193,6 +454,121 @@ class RSSPostRepository:
             "recent": row["recent"] or 0,
         }
 
+    @staticmethod
+    async def get_daily_counts_by_channel(
+        start_date: datetime, end_date: datetime, only_published: bool = False
+    ) -> List[dict]:
+        """Count posts per day per channel.
+
+        The channel is taken from the post link (https://t.me/<channel>/<id>).
+
+        Args:
+            start_date: Start date
+            end_date: End date
+            only_published: If True, count published posts by published_at
+
+        Returns:
+            List of dicts with 'day', 'channel_name' and 'count' keys
+        """
+        date_column = "published_at" if only_published else "pub_date"
+        query = f"""
+            SELECT
+                date_trunc('day', {date_column}) AS day,
+                split_part(link, '/', 4) AS channel_name,
+                COUNT(*) AS count
+            FROM rss_posts
+            WHERE {date_column} >= $1 AND {date_column} <= $2
+            GROUP BY day, channel_name
+            ORDER BY day ASC, channel_name ASC
+        """
+        rows = await db.fetch(query, start_date, end_date)
+        return [dict(row) for row in rows]
+
+    @staticmethod
+    async def get_channel_activity(since: datetime) -> List[dict]:
+        """Count each channel's posts and find its latest published event.
+
+        The channel is taken from the post link (https://t.me/<channel>/<id>).
+
+        Args:
+            since: Earliest pub_date of the counted posts
+
+        Returns:
+            Dicts with 'channel_name', 'count' (posts since the date), and
+            'last_event_link', 'last_event_title' and 'last_event_date' (None if
+            the channel has no published event), ordered by channel name
+        """
+        query = """
+            SELECT
+                split_part(link, '/', 4) AS channel_name,
+                COUNT(*) FILTER (WHERE pub_date >= $1) AS count,
+                (ARRAY_AGG(link ORDER BY pub_date DESC NULLS LAST, link DESC)
+                    FILTER (WHERE is_published))[1] AS last_event_link,
+                (ARRAY_AGG(title ORDER BY pub_date DESC NULLS LAST, link DESC)
+                    FILTER (WHERE is_published))[1] AS last_event_title,
+                MAX(pub_date) FILTER (WHERE is_published) AS last_event_date
+            FROM rss_posts
+            GROUP BY channel_name
+            ORDER BY channel_name ASC
+        """
+        rows = await db.fetch(query, since)
+        return [dict(row) for row in rows]
+
+    @staticmethod
+    async def get_published_since(start_date: datetime, limit: int = 500) -> List[RSSPost]:
+        """Get posts that passed classification and were published in a digest.
+
+        Args:
+            start_date: Earliest pub_date to include
+            limit: Maximum number of posts to return
+
+        Returns:
+            List of RSSPost instances, newest first
+        """
+        query = """
+            SELECT * FROM rss_posts
+            WHERE is_published = true AND pub_date >= $1
+            ORDER BY pub_date DESC
+            LIMIT $2
+        """
+        rows = await db.fetch(query, start_date, limit)
+        return [RSSPost.from_row(row) for row in rows]
+
+    @staticmethod
+    async def count_unpublished() -> int:
+        """Count posts waiting to be published."""
+        query = "SELECT COUNT(*) FROM rss_posts WHERE is_published = false"
+        return await db.fetchval(query) or 0
+
+    @staticmethod
+    async def get_without_summary(limit: int = 100) -> List[RSSPost]:
+        """Get the newest posts that have no summary or title yet.
+
+        Args:
+            limit: Maximum number of posts to return
+
+        Returns:
+            List of RSSPost instances
+        """
+        query = """
+            SELECT * FROM rss_posts
+            WHERE summary IS NULL OR title IS NULL
+            ORDER BY pub_date DESC NULLS LAST
+            LIMIT $1
+        """
+        rows = await db.fetch(query, limit)
+        return [RSSPost.from_row(row) for row in rows]
+
+    @staticmethod
+    async def update_summary(link: str, summary: str, title: str) -> None:
+        """Store the generated summary and title of a post."""
+        query = """
+            UPDATE rss_posts
+            SET summary = $2, title = $3, updated_at = CURRENT_TIMESTAMP
+            WHERE link = $1
+        """
+        await db.execute(query, link, summary, title)
+
     @staticmethod
     async def exists_by_link(link: str) -> bool:
         """Check if post with given link exists."""
@@ -246,3 +622,1001 @@ class RSSPostRepository:
         result = await db.execute(query, links)
         # Extract number of rows updated from result string like "UPDATE 5"
         return int(result.split()[-1]) if result else 0
+
+    @staticmethod
+    async def set_event_status(link: str, status: Optional[str]) -> bool:
+        """Mark an event as cancelled or postponed.
+
+        Args:
+            link: Post link
+            status: 'cancelled', 'postponed', or None for a regular event
+
+        Returns:
+            True if the post exists
+        """
+        query = """
+            UPDATE rss_posts
+            SET event_status = $2,
+                updated_at = CURRENT_TIMESTAMP
+            WHERE link = $1
+        """
+        result = await db.execute(query, link, status)
+        return bool(result) and result.split()[-1] != "0"
+
+
+class PromotionRepository:
+    """Repository for sponsored post operations."""
+
+    # Promotions with the number of digests they have been placed in
+    SELECT_WITH_PLACEMENTS = """
+        SELECT p.*, COUNT(pp.id) AS placements
+        FROM promotions p
+        LEFT JOIN promotion_placements pp ON pp.promotion_id = p.id
+    """
+
+    @staticmethod
+    async def create(promotion: Promotion) -> str:
+        """Create a new promotion.
+
+        Args:
+            promotion: Promotion dataclass instance
+
+        Returns:
+            ID of created promotion
+        """
+        query = """
+            INSERT INTO promotions (
+                id, post_link, sponsor, starts_at, ends_at, placement, target,
+                max_placements, price_per_placement, currency
+            ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+            RETURNING id
+        """
+        return await db.fetchval(
+            query,
+            new_id(),
+            promotion.post_link,
+            promotion.sponsor,
+            promotion.starts_at,
+            promotion.ends_at,
+            promotion.placement,
+            promotion.target,
+            promotion.max_placements,
+            promotion.price_per_placement,
+            promotion.currency,
+        )
+
+    @staticmethod
+    async def get_all() -> List[Promotion]:
+        """Get all promotions, newest first."""
+        query = f"""
+            {PromotionRepository.SELECT_WITH_PLACEMENTS}
+            GROUP BY p.id
+            ORDER BY p.starts_at DESC
+        """
+        rows = await db.fetch(query)
+        return [Promotion.from_row(row) for row in rows]
+
+    @staticmethod
+    async def get_active(at: datetime) -> List[Promotion]:
+        """Get promotions running at the given time that still have placements left.
+
+        Args:
+            at: Point in time
+
+        Returns:
+            List of Promotion instances, oldest start first
+        """
+        query = f"""
+            {PromotionRepository.SELECT_WITH_PLACEMENTS}
+            WHERE p.starts_at <= $1 AND p.ends_at > $1
+            GROUP BY p.id
+            HAVING p.max_placements IS NULL OR COUNT(pp.id) < p.max_placements
+            ORDER BY p.starts_at ASC, p.id ASC
+        """
+        rows = await db.fetch(query, at)
+        return [Promotion.from_row(row) for row in rows]
+
+    @staticmethod
+    async def record_placement(promotion_id: str, target: str) -> None:
+        """Record that a promotion was included in a published digest."""
+        query = "INSERT INTO promotion_placements (promotion_id, target) VALUES ($1, $2)"
+        await db.execute(query, promotion_id, target)
+
+    @staticmethod
+    async def get_report(start_date: datetime, end_date: datetime) -> List[dict]:
+        """Summarize placements per promotion for billing.
+
+        Args:
+            start_date: Start of the billing period
+            end_date: End of the billing period (exclusive)
+
+        Returns:
+            List of dicts with promotion fields, 'placements' and 'amount'
+        """
+        query = """
+            SELECT
+                p.id, p.sponsor, p.post_link, p.starts_at, p.ends_at,
+                p.price_per_placement, p.currency,
+                COUNT(pp.id) AS placements,
+                COUNT(pp.id) * p.price_per_placement AS amount
+            FROM promotions p
+            JOIN promotion_placements pp ON pp.promotion_id = p.id
+            WHERE pp.placed_at >= $1 AND pp.placed_at < $2
+            GROUP BY p.id
+            ORDER BY p.sponsor ASC, p.id ASC
+        """
+        rows = await db.fetch(query, start_date, end_date)
+        return [dict(row) for row in rows]
+
+    @staticmethod
+    async def delete(promotion_id: str) -> None:
+        """Delete a promotion and its placement history."""
+        query = "DELETE FROM promotions WHERE id = $1"
+        await db.execute(query, promotion_id)
+
+
+class PostDeliveryRepository:
+    """Repository for tracking posts delivered to per-post targets."""
+
+    @staticmethod
+    async def get_delivered_links(target: str, links: List[str]) -> Set[str]:
+        """Get which of the given posts were already delivered to a target.
+
+        Args:
+            target: Target name, e.g. 'mastodon:@events@mastodon.social'
+            links: Post links to check
+
+        Returns:
+            Set of delivered links
+        """
+        query = "SELECT link FROM post_deliveries WHERE target = $1 AND link = ANY($2)"
+        rows = await db.fetch(query, target, links)
+        return {row["link"] for row in rows}
+
+    @staticmethod
+    async def record(link: str, target: str, external_id: Optional[str] = None) -> None:
+        """Record a delivered post."""
+        query = """
+            INSERT INTO post_deliveries (link, target, external_id)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (link, target) DO UPDATE
+            SET external_id = EXCLUDED.external_id,
+                delivered_at = CURRENT_TIMESTAMP,
+                shares = NULL
+        """
+        await db.execute(query, link, target, external_id)
+
+    @staticmethod
+    async def get_recent(target: str, since: datetime) -> List[Tuple[str, str]]:
+        """Get the posts delivered to a target since a time, with their external IDs.
+
+        Returns:
+            List of (link, external ID), oldest delivery first; deliveries
+            without an external ID are left out
+        """
+        query = """
+            SELECT link, external_id FROM post_deliveries
+            WHERE target = $1 AND delivered_at >= $2 AND external_id IS NOT NULL
+            ORDER BY delivered_at ASC, link ASC
+        """
+        rows = await db.fetch(query, target, since)
+        return [(row["link"], row["external_id"]) for row in rows]
+
+    @staticmethod
+    async def set_shares(link: str, target: str, shares: int) -> None:
+        """Store how many times a delivered post was shared."""
+        query = "UPDATE post_deliveries SET shares = $3 WHERE link = $1 AND target = $2"
+        await db.execute(query, link, target, shares)
+
+
+class TranslationRepository:
+    """Repository for cached translations of post titles and summaries."""
+
+    @staticmethod
+    async def get_many(source_hashes: List[str], language: str) -> Dict[str, str]:
+        """Get cached translations.
+
+        Args:
+            source_hashes: Hashes of the source texts
+            language: Target language code, e.g. 'en'
+
+        Returns:
+            Source hash -> translated text, for the cached ones
+        """
+        query = """
+            SELECT source_hash, text FROM translations
+            WHERE language = $1 AND source_hash = ANY($2)
+        """
+        rows = await db.fetch(query, language, source_hashes)
+        return {row["source_hash"]: row["text"] for row in rows}
+
+    @staticmethod
+    async def save(source_hash: str, language: str, text: str, provider: str) -> None:
+        """Store a translation."""
+        query = """
+            INSERT INTO translations (source_hash, language, text, provider)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT (source_hash, language) DO UPDATE
+            SET text = EXCLUDED.text,
+                provider = EXCLUDED.provider,
+                created_at = CURRENT_TIMESTAMP
+        """
+        await db.execute(query, source_hash, language, text, provider)
+
+
+class LLMCacheRepository:
+    """Repository for cached LLM results of post texts."""
+
+    @staticmethod
+    async def get_many(content_hashes: List[str], purpose: str) -> Dict[str, str]:
+        """Get cached results.
+
+        Args:
+            content_hashes: Hashes of the post texts
+            purpose: What was asked of the model, e.g. 'summary:160'
+
+        Returns:
+            Content hash -> result, for the cached ones
+        """
+        query = """
+            SELECT content_hash, text FROM llm_cache
+            WHERE purpose = $1 AND content_hash = ANY($2)
+        """
+        rows = await db.fetch(query, purpose, content_hashes)
+        return {row["content_hash"]: row["text"] for row in rows}
+
+    @staticmethod
+    async def save(content_hash: str, purpose: str, text: str, model: str) -> None:
+        """Store a result."""
+        query = """
+            INSERT INTO llm_cache (content_hash, purpose, text, model)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT (content_hash, purpose) DO UPDATE
+            SET text = EXCLUDED.text,
+                model = EXCLUDED.model,
+                created_at = CURRENT_TIMESTAMP
+        """
+        await db.execute(query, content_hash, purpose, text, model)
+
+
+class LLMUsageRepository:
+    """Repository for LLM tokens spent per tenant and day."""
+
+    @staticmethod
+    async def add(tenant: str, day: date, tokens: int) -> None:
+        """Add tokens to a tenant's total for a day."""
+        query = """
+            INSERT INTO llm_usage (tenant, day, tokens)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (tenant, day) DO UPDATE SET tokens = llm_usage.tokens + EXCLUDED.tokens
+        """
+        await db.execute(query, tenant, day, tokens)
+
+    @staticmethod
+    async def get_day(day: date) -> Dict[str, int]:
+        """Get the tokens each tenant spent on a day."""
+        rows = await db.fetch("SELECT tenant, tokens FROM llm_usage WHERE day = $1", day)
+        return {row["tenant"]: row["tokens"] for row in rows}
+
+
+class SeriesRepository:
+    """Repository for festivals and other series of related events."""
+
+    @staticmethod
+    async def create(series: Series) -> None:
+        """Create a series unless one with the same key exists, keeping the existing name."""
+        query = """
+            INSERT INTO series (key, name, parent_link)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (key) DO NOTHING
+        """
+        await db.execute(query, series.key, series.name, series.parent_link)
+
+    @staticmethod
+    async def upsert(series: Series) -> None:
+        """Insert a series or overwrite the existing one with the same key."""
+        query = """
+            INSERT INTO series (key, name, parent_link, created_at)
+            VALUES ($1, $2, $3, COALESCE($4, CURRENT_TIMESTAMP))
+            ON CONFLICT (key) DO UPDATE
+            SET name = EXCLUDED.name,
+                parent_link = EXCLUDED.parent_link
+        """
+        await db.execute(query, series.key, series.name, series.parent_link, series.created_at)
+
+    @staticmethod
+    async def get_all() -> List[Series]:
+        """Get all series with the number of their events."""
+        query = """
+            SELECT s.*, COUNT(p.link) AS event_count
+            FROM series s
+            LEFT JOIN rss_posts p ON p.series_key = s.key
+            GROUP BY s.key
+            ORDER BY s.created_at ASC
+        """
+        rows = await db.fetch(query)
+        return [Series.from_row(row) for row in rows]
+
+    @staticmethod
+    async def add_post(link: str, key: str) -> None:
+        """Add a post to a series unless it already belongs to one."""
+        query = "UPDATE rss_posts SET series_key = $2 WHERE link = $1 AND series_key IS NULL"
+        await db.execute(query, link, key)
+
+    @staticmethod
+    async def get_many(keys: List[str]) -> Dict[str, Series]:
+        """Get series with the number of their events.
+
+        Args:
+            keys: Series keys
+
+        Returns:
+            Key -> Series, for the existing ones
+        """
+        query = """
+            SELECT s.*, COUNT(p.link) AS event_count
+            FROM series s
+            LEFT JOIN rss_posts p ON p.series_key = s.key
+            WHERE s.key = ANY($1)
+            GROUP BY s.key
+        """
+        rows = await db.fetch(query, keys)
+        return {row["key"]: Series.from_row(row) for row in rows}
+
+
+class InteractionRepository:
+    """Repository for aggregated event page views and clicks."""
+
+    @staticmethod
+    async def record(link: str, kind: str) -> None:
+        """Count an interaction with an event today.
+
+        Args:
+            link: Post link
+            kind: 'view' or 'click'
+        """
+        query = """
+            INSERT INTO event_interactions (link, day, kind, count)
+            VALUES ($1, CURRENT_DATE, $2, 1)
+            ON CONFLICT (link, day, kind) DO UPDATE
+            SET count = event_interactions.count + 1
+        """
+        await db.execute(query, link, kind)
+
+    @staticmethod
+    async def get_totals(start_date: date, end_date: date) -> List[dict]:
+        """Sum interactions per event for a period.
+
+        Args:
+            start_date: First day of the period
+            end_date: Day after the period
+
+        Returns:
+            List of dicts with 'link', 'tags', 'views' and 'clicks'
+        """
+        query = """
+            SELECT
+                p.link, p.tags,
+                SUM(i.count) FILTER (WHERE i.kind = 'view') AS views,
+                SUM(i.count) FILTER (WHERE i.kind = 'click') AS clicks
+            FROM event_interactions i
+            JOIN rss_posts p ON p.link = i.link
+            WHERE i.day >= $1 AND i.day < $2
+            GROUP BY p.link
+            ORDER BY p.link ASC
+        """
+        rows = await db.fetch(query, start_date, end_date)
+        return [dict(row) for row in rows]
+
+    @staticmethod
+    async def get_reach(since: datetime, limit: int = 50) -> List[dict]:
+        """Sum the views and shares of events across everything that announced them.
+
+        Views and clicks are those of the event pages, shares those of the
+        posts delivered to per-post targets; reach is views plus shares.
+
+        Args:
+            since: Oldest publication date of the events
+            limit: Maximum number of events
+
+        Returns:
+            List of dicts with 'link', 'posts' (deliveries), 'shares', 'views',
+            'clicks' and 'reach', widest reach first; events nobody saw are left out
+        """
+        query = """
+            SELECT
+                p.link,
+                COALESCE(d.posts, 0) AS posts,
+                COALESCE(d.shares, 0) AS shares,
+                COALESCE(i.views, 0) AS views,
+                COALESCE(i.clicks, 0) AS clicks,
+                COALESCE(i.views, 0) + COALESCE(d.shares, 0) AS reach
+            FROM rss_posts p
+            LEFT JOIN (
+                SELECT link, COUNT(*) AS posts, SUM(shares) AS shares
+                FROM post_deliveries GROUP BY link
+            ) d ON d.link = p.link
+            LEFT JOIN (
+                SELECT
+                    link,
+                    SUM(count) FILTER (WHERE kind = 'view') AS views,
+                    SUM(count) FILTER (WHERE kind = 'click') AS clicks
+                FROM event_interactions GROUP BY link
+            ) i ON i.link = p.link
+            WHERE p.pub_date >= $1 AND (d.link IS NOT NULL OR i.link IS NOT NULL)
+            ORDER BY reach DESC, p.link ASC
+            LIMIT $2
+        """
+        rows = await db.fetch(query, since, limit)
+        return [dict(row) for row in rows]
+
+    @staticmethod
+    async def get_daily(since: Optional[date] = None) -> List[dict]:
+        """Get the daily counters from a day on.
+
+        Args:
+            since: First day (None for all days)
+
+        Returns:
+            List of dicts with 'link', 'day', 'kind' and 'count', oldest day first
+        """
+        query = """
+            SELECT link, day, kind, count FROM event_interactions
+            WHERE $1::date IS NULL OR day >= $1
+            ORDER BY day ASC, link ASC, kind ASC
+        """
+        rows = await db.fetch(query, since)
+        return [dict(row) for row in rows]
+
+
+class PollRunRepository:
+    """Repository for per-item reports of RSS reader runs."""
+
+    @staticmethod
+    async def start(run_id: Optional[str] = None) -> str:
+        """Record the start of a run, or the retry of one that didn't finish.
+
+        Args:
+            run_id: ID of the run to retry; a new run if None
+
+        Returns:
+            Run ID
+        """
+        query = """
+            INSERT INTO poll_runs (id) VALUES ($1)
+            ON CONFLICT (id) DO UPDATE SET finished_at = NULL
+            RETURNING id
+        """
+        return await db.fetchval(query, run_id or new_id())
+
+    @staticmethod
+    async def add_items(run_id: str, items: List[PollRunItem]) -> None:
+        """Store what a run did with some items, replacing what it stored for them before.
+
+        Items are keyed by source and link, which must be unique among the items.
+        """
+        query = """
+            INSERT INTO poll_run_items (run_id, source, link, outcome, reason)
+            SELECT $1::text, * FROM unnest($2::text[], $3::text[], $4::text[], $5::text[])
+            ON CONFLICT (run_id, source, link) DO UPDATE SET
+                outcome = EXCLUDED.outcome, reason = EXCLUDED.reason
+        """
+        await db.execute(
+            query,
+            run_id,
+            [item.source for item in items],
+            [item.link for item in items],
+            [item.outcome for item in items],
+            [item.reason for item in items],
+        )
+
+    @staticmethod
+    async def finish(run_id: str, items: List[PollRunItem]) -> None:
+        """Store what a run did with each item and mark it finished.
+
+        Items an earlier attempt of the run stored and this one didn't report
+        (e.g. errors of a source that worked when retried) are deleted.
+        """
+        query = """
+            WITH items AS (
+                SELECT * FROM unnest($2::text[], $3::text[], $4::text[], $5::text[])
+                    AS t (source, link, outcome, reason)
+            ), stale AS (
+                DELETE FROM poll_run_items i
+                WHERE i.run_id = $1 AND NOT EXISTS (
+                    SELECT 1 FROM items WHERE items.source = i.source AND items.link = i.link
+                )
+            )
+            INSERT INTO poll_run_items (run_id, source, link, outcome, reason)
+            SELECT $1::text, * FROM items
+            ON CONFLICT (run_id, source, link) DO UPDATE SET
+                outcome = EXCLUDED.outcome, reason = EXCLUDED.reason
+        """
+        await db.execute(
+            query,
+            run_id,
+            [item.source for item in items],
+            [item.link for item in items],
+            [item.outcome for item in items],
+            [item.reason for item in items],
+        )
+        query = "UPDATE poll_runs SET finished_at = CURRENT_TIMESTAMP WHERE id = $1"
+        await db.execute(query, run_id)
+
+    @staticmethod
+    async def get_recent(limit: int = 20) -> List[PollRun]:
+        """Get the latest runs, newest first, with the number of items per outcome."""
+        query = """
+            SELECT r.*, COALESCE(
+                (SELECT json_object_agg(outcome, n) FROM (
+                    SELECT outcome, COUNT(*) AS n FROM poll_run_items
+                    WHERE run_id = r.id GROUP BY outcome
+                ) c),
+                '{}'
+            ) AS counts
+            FROM poll_runs r
+            ORDER BY r.id DESC
+            LIMIT $1
+        """
+        rows = await db.fetch(query, limit)
+        return [PollRun.from_row(row) for row in rows]
+
+    @staticmethod
+    async def get_items(run_id: str, outcome: Optional[str] = None) -> List[PollRunItem]:
+        """Get the items of a run in the order they were processed.
+
+        Args:
+            run_id: Run ID
+            outcome: Only items with this outcome if set
+        """
+        query = """
+            SELECT i.*, r.started_at
+            FROM poll_run_items i
+            JOIN poll_runs r ON r.id = i.run_id
+            WHERE i.run_id = $1 AND ($2::text IS NULL OR i.outcome = $2)
+            ORDER BY i.id ASC
+        """
+        rows = await db.fetch(query, run_id, outcome)
+        return [PollRunItem.from_row(row) for row in rows]
+
+    @staticmethod
+    async def get_history(link: str, limit: int = 20) -> List[PollRunItem]:
+        """Get what recent runs did with a post, newest first."""
+        query = """
+            SELECT i.*, r.started_at
+            FROM poll_run_items i
+            JOIN poll_runs r ON r.id = i.run_id
+            WHERE i.link = $1
+            ORDER BY i.run_id DESC
+            LIMIT $2
+        """
+        rows = await db.fetch(query, link, limit)
+        return [PollRunItem.from_row(row) for row in rows]
+
+    @staticmethod
+    async def delete_before(before: datetime) -> int:
+        """Delete runs started before a time, with their items.
+
+        Returns:
+            Number of deleted runs
+        """
+        query = "DELETE FROM poll_runs WHERE started_at < $1"
+        result = await db.execute(query, before)
+        return int(result.split()[-1]) if result else 0
+
+
+class SubmissionRepository:
+    """Repository for events submitted by organizers."""
+
+    @staticmethod
+    async def create(submission: Submission) -> str:
+        """Queue a submission for moderation.
+
+        Returns:
+            Submission ID
+        """
+        query = """
+            INSERT INTO submissions (id, link, content, title, contact, partner)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            RETURNING id
+        """
+        return await db.fetchval(
+            query,
+            new_id(),
+            submission.link,
+            submission.content,
+            submission.title,
+            submission.contact,
+            submission.partner,
+        )
+
+    @staticmethod
+    async def get_by_id(submission_id: str) -> Optional[Submission]:
+        """Get a submission by ID."""
+        query = "SELECT * FROM submissions WHERE id = $1"
+        row = await db.fetchrow(query, submission_id)
+        return Submission.from_row(row) if row else None
+
+    @staticmethod
+    async def get_by_status(status: str = "pending", limit: int = 100) -> List[Submission]:
+        """Get submissions with a status, oldest first."""
+        query = """
+            SELECT * FROM submissions
+            WHERE status = $1
+            ORDER BY id ASC
+            LIMIT $2
+        """
+        rows = await db.fetch(query, status, limit)
+        return [Submission.from_row(row) for row in rows]
+
+    @staticmethod
+    async def is_pending(link: str) -> bool:
+        """Check whether an event with this link is waiting for moderation."""
+        query = "SELECT EXISTS(SELECT 1 FROM submissions WHERE link = $1 AND status = 'pending')"
+        return await db.fetchval(query, link)
+
+    @staticmethod
+    async def review(submission_id: str, status: str, note: Optional[str] = None) -> bool:
+        """Approve or reject a pending submission.
+
+        Returns:
+            False if the submission doesn't exist or was reviewed already
+        """
+        query = """
+            UPDATE submissions
+            SET status = $2, note = $3, reviewed_at = CURRENT_TIMESTAMP
+            WHERE id = $1 AND status = 'pending'
+        """
+        result = await db.execute(query, submission_id, status, note)
+        return bool(result) and result.split()[-1] != "0"
+
+
+class DigestDraftRepository:
+    """Repository for digests held for moderation."""
+
+    @staticmethod
+    async def create(draft: DigestDraft) -> str:
+        """Create a draft.
+
+        Returns:
+            Draft ID
+        """
+        query = """
+            INSERT INTO digest_drafts (id, publisher, destination, links, intro)
+            VALUES ($1, $2, $3, $4, $5)
+            RETURNING id
+        """
+        return await db.fetchval(
+            query, new_id(), draft.publisher, draft.destination, draft.links, draft.intro
+        )
+
+    @staticmethod
+    async def get_by_id(draft_id: str) -> Optional[DigestDraft]:
+        """Get a draft by ID."""
+        query = "SELECT * FROM digest_drafts WHERE id = $1"
+        row = await db.fetchrow(query, draft_id)
+        return DigestDraft.from_row(row) if row else None
+
+    @staticmethod
+    async def get_by_status(statuses: List[str], limit: int = 100) -> List[DigestDraft]:
+        """Get drafts with any of the statuses, newest first."""
+        query = """
+            SELECT * FROM digest_drafts
+            WHERE status = ANY($1::text[])
+            ORDER BY id DESC
+            LIMIT $2
+        """
+        rows = await db.fetch(query, statuses, limit)
+        return [DigestDraft.from_row(row) for row in rows]
+
+    @staticmethod
+    async def update(draft_id: str, links: List[str], intro: Optional[str]) -> bool:
+        """Change the posts, their order and the intro of an editable draft.
+
+        Returns:
+            False if the draft doesn't exist or isn't editable anymore
+        """
+        query = """
+            UPDATE digest_drafts
+            SET links = $2, intro = $3, updated_at = CURRENT_TIMESTAMP
+            WHERE id = $1 AND status = 'draft'
+        """
+        result = await db.execute(query, draft_id, links, intro)
+        return bool(result) and result.split()[-1] != "0"
+
+    @staticmethod
+    async def set_status(
+        draft_id: str, status: str, expected: str, text: Optional[str] = None
+    ) -> bool:
+        """Move a draft from one status to another.
+
+        Args:
+            draft_id: Draft ID
+            status: New status
+            expected: Status the draft must have, so concurrent moderators can't both act
+            text: Final message to store (kept when None)
+
+        Returns:
+            False if the draft doesn't exist or doesn't have the expected status
+        """
+        query = """
+            UPDATE digest_drafts
+            SET status = $2,
+                text = COALESCE($4, text),
+                updated_at = CURRENT_TIMESTAMP,
+                sent_at = CASE WHEN $2 = 'sent' THEN CURRENT_TIMESTAMP ELSE sent_at END
+            WHERE id = $1 AND status = $3
+        """
+        result = await db.execute(query, draft_id, status, expected, text)
+        return bool(result) and result.split()[-1] != "0"
+
+
+class PublishFailureRepository:
+    """Repository for messages waiting to be published again."""
+
+    @staticmethod
+    async def create(failure: PublishFailure) -> str:
+        """Queue a failed message.
+
+        Returns:
+            Failure ID
+        """
+        query = """
+            INSERT INTO publish_failures (id, publisher, destination, message, links,
+                                          promotion_ids, error, attempts, status, next_attempt_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+            RETURNING id
+        """
+        return await db.fetchval(
+            query,
+            new_id(),
+            failure.publisher,
+            failure.destination,
+            failure.message,
+            failure.links,
+            failure.promotion_ids,
+            failure.error,
+            failure.attempts,
+            failure.status,
+            failure.next_attempt_at,
+        )
+
+    @staticmethod
+    async def get_by_id(failure_id: str) -> Optional[PublishFailure]:
+        """Get a failure by ID."""
+        query = "SELECT * FROM publish_failures WHERE id = $1"
+        row = await db.fetchrow(query, failure_id)
+        return PublishFailure.from_row(row) if row else None
+
+    @staticmethod
+    async def get_by_status(statuses: List[str], limit: int = 100) -> List[PublishFailure]:
+        """Get failures with any of the statuses, newest first."""
+        query = """
+            SELECT * FROM publish_failures
+            WHERE status = ANY($1::text[])
+            ORDER BY id DESC
+            LIMIT $2
+        """
+        rows = await db.fetch(query, statuses, limit)
+        return [PublishFailure.from_row(row) for row in rows]
+
+    @staticmethod
+    async def get_due(at: datetime, limit: int = 100) -> List[PublishFailure]:
+        """Get pending failures whose next attempt is due, earliest first."""
+        query = """
+            SELECT * FROM publish_failures
+            WHERE status = 'pending' AND next_attempt_at <= $1
+            ORDER BY next_attempt_at, id
+            LIMIT $2
+        """
+        rows = await db.fetch(query, at, limit)
+        return [PublishFailure.from_row(row) for row in rows]
+
+    @staticmethod
+    async def set_status(failure_id: str, status: str, expected: List[str]) -> bool:
+        """Move a failure to a status if it has one of the expected ones.
+
+        Returns:
+            False if the failure doesn't exist or was moved on by someone else
+        """
+        query = """
+            UPDATE publish_failures
+            SET status = $2, updated_at = CURRENT_TIMESTAMP
+            WHERE id = $1 AND status = ANY($3::text[])
+        """
+        result = await db.execute(query, failure_id, status, expected)
+        return bool(result) and result.split()[-1] != "0"
+
+    @staticmethod
+    async def record_attempt(
+        failure_id: str,
+        status: str,
+        error: Optional[str] = None,
+        next_attempt_at: Optional[datetime] = None,
+    ) -> bool:
+        """Record the outcome of a retry of a failure that is being sent.
+
+        Args:
+            failure_id: Failure ID
+            status: sent, or pending/failed if the retry failed too
+            error: Error of the retry (kept when None)
+            next_attempt_at: When to retry next
+
+        Returns:
+            False if the failure isn't being sent
+        """
+        query = """
+            UPDATE publish_failures
+            SET status = $2,
+                error = COALESCE($3, error),
+                next_attempt_at = $4,
+                attempts = attempts + 1,
+                updated_at = CURRENT_TIMESTAMP
+            WHERE id = $1 AND status = 'sending'
+        """
+        result = await db.execute(query, failure_id, status, error, next_attempt_at)
+        return bool(result) and result.split()[-1] != "0"
+
+
+class QuotaRepository:
+    """Repository for rate limits of external APIs."""
+
+    @staticmethod
+    async def upsert(quota: Quota) -> None:
+        """Store the latest numbers of a quota."""
+        query = """
+            INSERT INTO api_quotas (api, name, quota_limit, remaining, reset_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            ON CONFLICT (api, name) DO UPDATE SET
+                quota_limit = EXCLUDED.quota_limit,
+                remaining = EXCLUDED.remaining,
+                reset_at = EXCLUDED.reset_at,
+                updated_at = EXCLUDED.updated_at
+        """
+        await db.execute(
+            query,
+            quota.api,
+            quota.name,
+            quota.limit,
+            quota.remaining,
+            quota.reset_at,
+            quota.updated_at,
+        )
+
+    @staticmethod
+    async def get_all() -> List[Quota]:
+        """Get all quotas, by API and name."""
+        query = "SELECT * FROM api_quotas ORDER BY api, name"
+        rows = await db.fetch(query)
+        return [Quota.from_row(row) for row in rows]
+
+
+class BandwidthRepository:
+    """Repository for bytes downloaded per source and day."""
+
+    @staticmethod
+    async def add(source: str, day: date, size: int, fetches: int = 1) -> None:
+        """Add downloaded bytes to a source's total for a day."""
+        query = """
+            INSERT INTO source_bandwidth (source, day, bytes, fetches)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT (source, day) DO UPDATE SET
+                bytes = source_bandwidth.bytes + EXCLUDED.bytes,
+                fetches = source_bandwidth.fetches + EXCLUDED.fetches
+        """
+        await db.execute(query, source, day, size, fetches)
+
+    @staticmethod
+    async def get_since(since: date, source: Optional[str] = None) -> List[SourceBandwidth]:
+        """Get daily totals from a day on, newest day first and the biggest sources first.
+
+        Args:
+            since: First day
+            source: Only this source if set
+        """
+        query = """
+            SELECT * FROM source_bandwidth
+            WHERE day >= $1 AND ($2::text IS NULL OR source = $2)
+            ORDER BY day DESC, bytes DESC, source ASC
+        """
+        rows = await db.fetch(query, since, source)
+        return [SourceBandwidth.from_row(row) for row in rows]
+
+
+class PostRevisionRepository:
+    """Repository for the edits of stored posts."""
+
+    @staticmethod
+    async def add(revision: PostRevision) -> bool:
+        """Store a revision of a post.
+
+        Returns:
+            False if the post already has a revision with that number
+        """
+        query = """
+            INSERT INTO post_revisions (link, revision, delta, content_hash, created_at)
+            VALUES ($1, $2, $3, $4, COALESCE($5, CURRENT_TIMESTAMP))
+            ON CONFLICT (link, revision) DO NOTHING
+            RETURNING revision
+        """
+        added = await db.fetchval(
+            query,
+            revision.link,
+            revision.revision,
+            revision.delta,
+            revision.content_hash,
+            revision.created_at,
+        )
+        return added is not None
+
+    @staticmethod
+    async def get_by_link(link: str) -> List[PostRevision]:
+        """Get the revisions of a post, oldest first."""
+        query = "SELECT * FROM post_revisions WHERE link = $1 ORDER BY revision"
+        rows = await db.fetch(query, link)
+        return [PostRevision.from_row(row) for row in rows]
+
+    @staticmethod
+    async def get_by_links(links: List[str]) -> List[PostRevision]:
+        """Get the revisions of several posts, by link and oldest first."""
+        query = "SELECT * FROM post_revisions WHERE link = ANY($1) ORDER BY link, revision"
+        rows = await db.fetch(query, links)
+        return [PostRevision.from_row(row) for row in rows]
+
+
+class FieldOverrideRepository:
+    """Repository for moderators' corrections of extracted fields."""
+
+    @staticmethod
+    async def set(override: FieldOverride) -> None:
+        """Store a correction, replacing an earlier one of the same field."""
+        query = """
+            INSERT INTO field_overrides (link, field, value, extracted, created_at)
+            VALUES ($1, $2, $3, $4, COALESCE($5, CURRENT_TIMESTAMP))
+            ON CONFLICT (link, field) DO UPDATE
+            SET value = EXCLUDED.value,
+                extracted = EXCLUDED.extracted,
+                created_at = EXCLUDED.created_at
+        """
+        await db.execute(
+            query,
+            override.link,
+            override.field,
+            override.value,
+            override.extracted,
+            override.created_at,
+        )
+
+    @staticmethod
+    async def get_by_link(link: str) -> List[FieldOverride]:
+        """Get the corrections of a post, by field name."""
+        query = "SELECT * FROM field_overrides WHERE link = $1 ORDER BY field"
+        rows = await db.fetch(query, link)
+        return [FieldOverride.from_row(row) for row in rows]
+
+    @staticmethod
+    async def get_by_links(links: List[str]) -> List[FieldOverride]:
+        """Get the corrections of several posts, by link and field name."""
+        query = "SELECT * FROM field_overrides WHERE link = ANY($1) ORDER BY link, field"
+        rows = await db.fetch(query, links)
+        return [FieldOverride.from_row(row) for row in rows]
+
+    @staticmethod
+    async def get_since(since: datetime) -> List[FieldOverride]:
+        """Get the corrections made since a time, oldest first."""
+        query = """
+            SELECT * FROM field_overrides WHERE created_at >= $1
+            ORDER BY created_at, link, field
+        """
+        rows = await db.fetch(query, since)
+        return [FieldOverride.from_row(row) for row in rows]
+
+    @staticmethod
+    async def delete(link: str, field: str) -> bool:
+        """Drop a correction.
+
+        Returns:
+            False if the field wasn't corrected
+        """
+        query = "DELETE FROM field_overrides WHERE link = $1 AND field = $2 RETURNING field"
+        return await db.fetchval(query, link, field) is not None
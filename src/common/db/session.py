@@ -1,31 +1,81 @@
-"""Database connection and session management using asyncpg."""
+"""Database connection and session management using asyncpg.
+
+Writes always go to the primary (DATABASE_DSN). Reads made inside
+db.prefer_replicas(), or by functions wrapped with reads_from_replicas(), go
+to the first reachable replica of DATABASE_REPLICA_DSNS instead, so heavy
+public read traffic doesn't slow down ingestion. A replica that can't be
+reached is skipped for REPLICA_RETRY_SECONDS; without one the primary serves
+the read.
+"""
+
+import functools
+import logging
+from contextlib import contextmanager
+from contextvars import ContextVar
+from datetime import datetime, timedelta
+from typing import Any, Awaitable, Callable, Dict, List, Optional
 
 import asyncpg
-from typing import Optional
-from .config import settings
+
+from common import clock
+from common.faults import faults
+from .config import MEMORY_DSN, settings
 from .schema import CREATE_POSTS_TABLE, CREATE_INDEXES
 
+logger = logging.getLogger(__name__)
+
+REPLICA_RETRY_SECONDS = 30
+
+# Whether the current task's reads may be served by a replica
+_prefer_replicas: ContextVar[bool] = ContextVar("prefer_replicas", default=False)
+
 
 class Database:
     """Database connection manager."""
 
     def __init__(self):
         self.pool: Optional[asyncpg.Pool] = None
+        self.replicas: List[asyncpg.Pool] = []
+        # Replica index -> time until which it is skipped after a connection failure
+        self.replica_down_until: Dict[int, datetime] = {}
 
     async def connect(self) -> None:
-        """Create connection pool."""
+        """Create connection pools."""
+        if settings.get_dsn().startswith(MEMORY_DSN):
+            # Imported here, the memory backend imports the repositories
+            from .memory import install
+
+            install()
+            return
         self.pool = await asyncpg.create_pool(
             dsn=settings.get_dsn(),
             min_size=5,
             max_size=20,
             command_timeout=60,
         )
+        # Replica pools connect on first use, so an unreachable one doesn't stop the service
+        self.replicas = [
+            await asyncpg.create_pool(dsn=dsn, min_size=0, max_size=20, command_timeout=60)
+            for dsn in settings.replica_dsns
+        ]
 
     async def disconnect(self) -> None:
-        """Close connection pool."""
+        """Close connection pools."""
+        for replica in self.replicas:
+            await replica.close()
+        self.replicas = []
         if self.pool:
             await self.pool.close()
 
+    @contextmanager
+    def prefer_replicas(self):
+        """Let replicas serve the reads made in the block (and in tasks it starts)."""
+        token = _prefer_replicas.set(True)
+        try:
+            yield
+        finally:
+            _prefer_replicas.reset(token)
+
     async def init_schema(self) -> None:
         """Initialize database schema."""
         if not self.pool:
@@ -37,38 +87,61 @@ class Database:
             # Create indexes
             await conn.execute(CREATE_INDEXES)
 
-    async def execute(self, query: str, *args) -> None:
-        """Execute a query without returning results."""
+    async def execute(self, query: str, *args) -> str:
+        """Execute a query and return its status, like 'UPDATE 5'."""
         if not self.pool:
             raise RuntimeError("Database not connected. Call connect() first.")
 
         async with self.pool.acquire() as conn:
-            await conn.execute(query, *args)
+            return await faults.call_db("execute", lambda: conn.execute(query, *args))
 
     async def fetch(self, query: str, *args) -> list:
         """Fetch multiple rows."""
-        if not self.pool:
-            raise RuntimeError("Database not connected. Call connect() first.")
-
-        async with self.pool.acquire() as conn:
-            return await conn.fetch(query, *args)
+        return await self._read("fetch", lambda conn: conn.fetch(query, *args))
 
     async def fetchrow(self, query: str, *args) -> Optional[dict]:
         """Fetch a single row."""
-        if not self.pool:
-            raise RuntimeError("Database not connected. Call connect() first.")
-
-        async with self.pool.acquire() as conn:
-            return await conn.fetchrow(query, *args)
+        return await self._read("fetchrow", lambda conn: conn.fetchrow(query, *args))
 
     async def fetchval(self, query: str, *args):
         """Fetch a single value."""
+        return await self._read("fetchval", lambda conn: conn.fetchval(query, *args))
+
+    async def _read(self, method: str, call: Callable[[Any], Awaitable[Any]]) -> Any:
+        """Make a read on a replica if the task prefers them and one is reachable."""
         if not self.pool:
             raise RuntimeError("Database not connected. Call connect() first.")
 
+        if _prefer_replicas.get():
+            for index, replica in enumerate(self.replicas):
+                if self.replica_down_until.get(index, datetime.min) > clock.now():
+                    continue
+                try:
+                    async with replica.acquire() as conn:
+                        return await faults.call_db(method, lambda: call(conn))
+                except (OSError, asyncpg.PostgresConnectionError) as e:
+                    logger.warning(
+                        f"Replica {index + 1} unreachable, skipping it for "
+                        f"{REPLICA_RETRY_SECONDS}s: {e}"
+                    )
+                    self.replica_down_until[index] = clock.now() + timedelta(
+                        seconds=REPLICA_RETRY_SECONDS
+                    )
+
         async with self.pool.acquire() as conn:
-            return await conn.fetchval(query, *args)
+            return await faults.call_db(method, lambda: call(conn))
 
 
 # Global database instance
 db = Database()
+
+
+def reads_from_replicas(func: Callable[..., Awaitable[Any]]) -> Callable[..., Awaitable[Any]]:
+    """Wrap an async function so replicas may serve its reads, like public API handlers."""
+
+    @functools.wraps(func)
+    async def wrapper(*args, **kwargs):
+        with db.prefer_replicas():
+            return await func(*args, **kwargs)
+
+    return wrapper
@@ -0,0 +1,74 @@
+"""Storage backends.
+
+A backend is a set of repositories with the methods and behavior of the
+Postgres ones in repository.py. New backends are checked against the same
+expectations with tests/storagetest.py.
+"""
+
+from dataclasses import dataclass
+
+from .repository import (
+    BandwidthRepository,
+    DigestDraftRepository,
+    FieldOverrideRepository,
+    InteractionRepository,
+    LLMCacheRepository,
+    LLMUsageRepository,
+    PollRunRepository,
+    PostRevisionRepository,
+    PostDeliveryRepository,
+    PromotionRepository,
+    PublishFailureRepository,
+    QuotaRepository,
+    RSSPostRepository,
+    SeriesRepository,
+    SubmissionRepository,
+    TelegramChannelRepository,
+    TranslationRepository,
+)
+
+
+@dataclass(frozen=True)
+class Storage:
+    """Repositories of one storage backend."""
+
+    name: str
+    channels: type
+    posts: type
+    promotions: type
+    deliveries: type
+    translations: type
+    llm_cache: type
+    llm_usage: type
+    series: type
+    interactions: type
+    poll_runs: type
+    submissions: type
+    digest_drafts: type
+    publish_failures: type
+    quotas: type
+    bandwidth: type
+    revisions: type
+    overrides: type
+
+
+POSTGRES = Storage(
+    name="postgres",
+    channels=TelegramChannelRepository,
+    posts=RSSPostRepository,
+    promotions=PromotionRepository,
+    deliveries=PostDeliveryRepository,
+    translations=TranslationRepository,
+    llm_cache=LLMCacheRepository,
+    llm_usage=LLMUsageRepository,
+    series=SeriesRepository,
+    interactions=InteractionRepository,
+    poll_runs=PollRunRepository,
+    submissions=SubmissionRepository,
+    digest_drafts=DigestDraftRepository,
+    publish_failures=PublishFailureRepository,
+    quotas=QuotaRepository,
+    bandwidth=BandwidthRepository,
+    revisions=PostRevisionRepository,
+    overrides=FieldOverrideRepository,
+)
@@ -0,0 +1,107 @@
+"""Detection of feed format changes that break content cleaning.
+
+Posts are stored as the text clean_content makes of the HTML the bridges
+serve. When a bridge changes its markup, the regexes stop matching and tags,
+class attributes and entities leak into the stored text, unnoticed until
+someone reads a digest. A random sample of recent posts is checked for such
+leftovers; an alert goes out when their share reaches the threshold and is
+resolved once it drops again.
+"""
+
+import logging
+import random
+import re
+from collections import Counter
+from dataclasses import dataclass, field
+from datetime import timedelta
+from typing import List, Optional
+
+from common import clock
+from common.alerts import Alert, AlertManager, alert_settings
+from common.db.models import RSSPost
+from common.db.repository import RSSPostRepository
+from common.utils.links import channel_from_link
+
+logger = logging.getLogger(__name__)
+
+ALERT_KEY = "content_drift:html"
+
+# Tags, attributes and entities clean_content should have removed
+LEFTOVER_HTML = re.compile(r"</?[a-zA-Z][\w-]*[\s/>]|\b(?:class|style|href)=|&(?:[a-z]+|#\d+);")
+
+# Fewer posts say nothing about the ratio
+MIN_SAMPLE = 20
+
+# Posts the sample is drawn from
+MAX_CANDIDATES = 1000
+
+
+def has_leftover_html(text: str) -> bool:
+    """Whether cleaned text still contains HTML markup."""
+    return bool(LEFTOVER_HTML.search(text or ""))
+
+
+@dataclass
+class DriftSample:
+    """Result of checking a sample of posts."""
+
+    total: int = 0
+    # Links of the posts with leftover HTML
+    marked: List[str] = field(default_factory=list)
+
+    @property
+    def ratio(self) -> float:
+        return len(self.marked) / self.total if self.total else 0.0
+
+    def channels(self, limit: int = 3) -> List[str]:
+        """Channels with the most marked posts."""
+        counts = Counter(channel_from_link(link) or link for link in self.marked)
+        return [name for name, _ in counts.most_common(limit)]
+
+
+def sample_posts(
+    posts: List[RSSPost], size: int, rng: Optional[random.Random] = None
+) -> DriftSample:
+    """Check a random sample of at most `size` posts for leftover HTML."""
+    rng = rng or random.Random()
+    sample = rng.sample(posts, min(size, len(posts)))
+    return DriftSample(
+        total=len(sample),
+        marked=[post.link for post in sample if has_leftover_html(post.content)],
+    )
+
+
+async def check_content_drift(alerts: AlertManager) -> Optional[DriftSample]:
+    """
+    Sample the posts of the last ALERT_DRIFT_HOURS and alert on leftover HTML.
+
+    Returns:
+        The checked sample, or None if there were too few recent posts
+    """
+    settings = alert_settings
+    now = clock.now()
+    posts = await RSSPostRepository.get_by_date_range(
+        now - timedelta(hours=settings.drift_hours),
+        now,
+        limit=MAX_CANDIDATES,
+        only_unpublished=False,
+    )
+    sample = sample_posts(posts, settings.drift_sample_size)
+    if sample.total < MIN_SAMPLE:
+        logger.info(f"Skipped the content drift check: only {sample.total} recent posts")
+        return None
+
+    logger.info(f"{len(sample.marked)} of {sample.total} sampled posts contain leftover HTML")
+    if sample.ratio >= settings.drift_ratio:
+        await alerts.notify(
+            Alert(
+                ALERT_KEY,
+                f"RSS Reader: {len(sample.marked)} of {sample.total} recent posts "
+                f"({sample.ratio:.0%}) contain leftover HTML, mostly from "
+                f"{', '.join(sample.channels())}; the bridge markup may have changed",
+                severity="warning",
+            )
+        )
+    else:
+        alerts.resolve(ALERT_KEY)
+    return sample
@@ -0,0 +1,109 @@
+"""Publish embargoes.
+
+Some announcements must not go out before an official time stated in the
+post itself, e.g. a presale post saying "старт продаж 5 марта в 12:00".
+Embargo rules in RULES_FILE select such posts by category (tags, channel,
+text); the publishers hold a selected post until the release time found
+after the rule's phrase. A post with no recognizable release time isn't held.
+"""
+
+import logging
+import re
+from dataclasses import replace
+from datetime import datetime, time
+from typing import List, Optional, Tuple
+
+from common.db.models import RSSPost
+from common.event_dates import DATE_REGEX, TIME_REGEX, TIME_WINDOW, month_number, resolve_date
+from common.rules import RuleSet, post_variables
+from common.utils.links import channel_from_link
+
+logger = logging.getLogger(__name__)
+
+# Phrases introducing the start of ticket sales
+SALES_START_REGEX = re.compile(
+    r"(?:старт|начало|открытие)\s+(?:пред)?продаж"
+    r"|(?:пред)?продаж[аи]\s+(?:стартует|стартуют|начн[её]тся|начнутся|откро[ею]тся)"
+    r"|в\s+продаже\s+с",
+    re.IGNORECASE,
+)
+
+
+def release_time(
+    content: str, reference: datetime, pattern: Optional[re.Pattern] = None
+) -> Optional[datetime]:
+    """
+    Find the release time stated in a post.
+
+    Args:
+        content: Post text
+        reference: Publication time, used to infer the year
+        pattern: Phrase introducing the release time (default: start of ticket sales)
+
+    Returns:
+        Date and time following the first phrase with a date (midnight if no
+        time is given), or None
+    """
+    text = " ".join((content or "").split())
+    for phrase in (pattern or SALES_START_REGEX).finditer(text):
+        end = phrase.end() + TIME_WINDOW
+        match = DATE_REGEX.search(text, phrase.end(), end)
+        if not match:
+            continue
+        day = resolve_date(int(match["day"]), month_number(match["month"]), reference.date())
+        if not day:
+            continue
+        clock_match = TIME_REGEX.search(text, phrase.end(), end)
+        at = time(int(clock_match["hour"]), int(clock_match["minute"])) if clock_match else time()
+        return datetime.combine(day, at)
+    return None
+
+
+def embargoed_until(post: RSSPost, rules: RuleSet, now: datetime) -> Optional[datetime]:
+    """
+    Time until which a post must not be published.
+
+    Args:
+        post: Post to publish
+        rules: Loaded rules with embargoes
+        now: Current time
+
+    Returns:
+        Release time if the post is under an embargo that hasn't ended, else None
+    """
+    variables = post_variables(
+        channel_from_link(post.link),
+        post.link,
+        post.content,
+        post.pub_date,
+        tags=post.tags,
+        event_format=post.event_format,
+        categories=post.categories,
+    )
+    embargo = rules.embargo(variables)
+    if not embargo:
+        return None
+    release = release_time(post.content, post.pub_date or now, embargo.pattern)
+    return release if release and release > now else None
+
+
+def hold_embargoed(
+    posts: List[RSSPost], rules: RuleSet, now: datetime
+) -> Tuple[List[RSSPost], List[RSSPost]]:
+    """
+    Split posts into ones that can be published now and ones under embargo.
+
+    Returns:
+        Tuple of (ready, held), both in the original order; held posts are
+        copies with embargo_until set, for the caller to store with
+        RSSPostRepository.set_embargo
+    """
+    ready, held = [], []
+    for post in posts:
+        until = embargoed_until(post, rules, now) if rules.embargoes else None
+        if until:
+            logger.info(f"Holding {post.link} until {until:%Y-%m-%d %H:%M} (embargo)")
+            held.append(replace(post, embargo_until=until))
+        else:
+            ready.append(post)
+    return ready, held
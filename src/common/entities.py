@@ -0,0 +1,107 @@
+"""People and organizations named in announcements.
+
+Speakers and performers are the names after a role ("спикер: Анна Смирнова",
+"выступит Иван Петров и Ольга Белова", "speaker John Smith") and the groups
+after one ("выступит группа «Кино»"). Organizations are the quoted names of
+museums, foundations, companies and the like ("в музее «Гараж»", "ООО
+«Ромашка»") and the names after organizer phrases ("организатор — Яндекс",
+"при поддержке «Сбера»"). Names are kept as written, so inflected ones stay
+inflected; searches match a part of a name, like a surname.
+"""
+
+import re
+from dataclasses import dataclass, field
+from typing import Iterable, List
+
+# A capitalized word, possibly hyphenated, like "Анна" or "Римский-Корсаков"
+_WORD = r"[А-ЯЁA-Z][а-яёa-z]+(?:-[А-ЯЁA-Z][а-яёa-z]+)?"
+# "Иван Петров", "Анна Мария Смирнова", "И. Петров", "И.И. Петров"
+_NAME = rf"(?:[А-ЯЁA-Z]\.\s?(?:[А-ЯЁA-Z]\.\s?)?{_WORD}|{_WORD}(?:\s+{_WORD}){{1,2}})"
+_NAME_LIST = rf"{_NAME}(?:\s*(?:,|\bи\b|\band\b|&)\s*{_NAME})*"
+_QUOTED = r"[«\"“]([^»\"”\n]{2,60})[»\"”]"
+
+PERSON_ROLES = (
+    r"спикер\w*|лектор\w*|ведущ(?:ий|ая|ие|его|ей)|модератор\w*|исполнител\w+|солист\w*"
+    r"|дириж[её]р\w*|пианист\w*|скрипач\w*|гост(?:ь|и|ья)|при\s+участии"
+    r"|выступ(?:ает|ят|ит|ают|ление)|расскаж(?:ет|ут)|прочита(?:ет|ют)|прочт[её]т"
+    r"|speakers?|hosted\s+by|featuring|feat\."
+)
+GROUP_TYPES = r"групп\w+|ансамбл\w+|оркестр\w*|хор\w*|коллектив\w*|трио|квартет\w*|band"
+ORGANIZATION_TYPES = (
+    r"ООО|ОАО|ПАО|АО|АНО|НКО|фонд\w*|музе\w+|театр\w*|центр\w*|библиотек\w+|галере\w+"
+    r"|клуб\w*|компани\w+|университет\w*|издательств\w+|ассоциаци\w+|студи\w+|кинотеатр\w*"
+    r"|филармони\w+|дом\w*\s+культуры|ДК|школ\w+|парк\w*"
+)
+ORGANIZER_PHRASES = (
+    r"организатор\w*|организу(?:ет|ют)|при\s+поддержке|партн[её]р\w*|прово(?:дит|дят)"
+    r"|organi[sz]ed\s+by"
+)
+
+# Roles match in any case, the names after them only capitalized. Up to
+# three lowercase words may stand between: "выступит известный пианист Иван Петров"
+_FILLER = r"(?:\s+[а-яёa-z]+){0,3}?"
+_SEPARATOR = r"\s*[:—–-]?\s*"
+PERSON_REGEX = re.compile(rf"(?i:\b(?:{PERSON_ROLES})){_FILLER}{_SEPARATOR}({_NAME_LIST})")
+GROUP_REGEX = re.compile(
+    rf"(?i:\b(?:{PERSON_ROLES})){_FILLER}\s+(?i:(?:{GROUP_TYPES}))\s+{_QUOTED}"
+)
+ORGANIZATION_REGEX = re.compile(rf"(?i:(?<![\w-])(?:{ORGANIZATION_TYPES}))\s+{_QUOTED}")
+ORGANIZER_REGEX = re.compile(
+    rf"(?i:\b(?:{ORGANIZER_PHRASES})){_FILLER}{_SEPARATOR}"
+    rf"(?:{_QUOTED}|({_WORD}(?:\s+{_WORD}){{0,2}}))"
+)
+_NAME_REGEX = re.compile(_NAME)
+
+
+@dataclass
+class Entities:
+    """People and organizations named in a post, in the order they are first named."""
+
+    performers: List[str] = field(default_factory=list)
+    organizations: List[str] = field(default_factory=list)
+
+
+def _unique(names: Iterable[str]) -> List[str]:
+    seen, result = set(), []
+    for name in names:
+        name = " ".join(name.split()).strip(" .,")
+        if name and name.lower() not in seen:
+            seen.add(name.lower())
+            result.append(name)
+    return result
+
+
+def extract_entities(content: str) -> Entities:
+    """
+    Find the speakers, performers and organizations a post names.
+
+    Args:
+        content: Post text
+
+    Returns:
+        Entities, with empty lists when nothing was found
+    """
+    text = content or ""
+    # (position, name) pairs, sorted to keep the order of the text
+    people = [
+        (match.start(1) + name.start(), name.group())
+        for match in PERSON_REGEX.finditer(text)
+        for name in _NAME_REGEX.finditer(match.group(1))
+    ]
+    people += [(match.start(1), match.group(1)) for match in GROUP_REGEX.finditer(text)]
+    organizations = [
+        (match.start(1), match.group(1)) for match in ORGANIZATION_REGEX.finditer(text)
+    ]
+    for match in ORGANIZER_REGEX.finditer(text):
+        group = 1 if match.group(1) else 2
+        organizations.append((match.start(group), match.group(group)))
+    return Entities(
+        performers=_unique(name for _, name in sorted(people)),
+        organizations=_unique(name for _, name in sorted(organizations)),
+    )
+
+
+def mentions(names: Iterable[str], query: str) -> bool:
+    """Whether any of the names contains the query, ignoring case ("петров" in "Иван Петров")."""
+    query = " ".join(query.split()).lower()
+    return any(query in " ".join(name.split()).lower() for name in names)
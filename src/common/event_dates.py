@@ -0,0 +1,305 @@
+"""Event dates and multi-day schedules.
+
+Announcements are published ahead of the event, so the publication time is
+only a fallback. Dates in the text — "15 марта в 19:00", "5–7 марта",
+"с 30 марта по 2 апреля", "5, 6 и 8 марта" — are parsed into per-day
+sessions with an optional start time. The year is taken from the
+publication date and moved forward for dates that would otherwise be long
+past ("10 января" in a December post).
+
+Deadlines for registrations and applications ("регистрация до 20 ноября",
+"приём заявок по 15.11", "дедлайн — 1 декабря") are parsed separately and
+aren't taken for event dates.
+"""
+
+import json
+import re
+from dataclasses import dataclass, field
+from datetime import date, datetime, time, timedelta
+from typing import List, Optional, Tuple
+
+from common import clock
+from common.db.models import RSSPost
+
+# Checked in order, so 'март' comes before 'ма' (май, мая)
+MONTH_STEMS = [
+    "январ",
+    "феврал",
+    "март",
+    "апрел",
+    "ма",
+    "июн",
+    "июл",
+    "август",
+    "сентябр",
+    "октябр",
+    "ноябр",
+    "декабр",
+]
+
+# Ranges longer than this are stored as start and end only (exhibitions)
+MAX_SESSIONS = 31
+# Dates further in the past than this are taken to be next year
+PAST_DAYS = 60
+# How far after a date its start time may be mentioned
+TIME_WINDOW = 80
+
+DAY = r"(?<![\d.:])(?:[12]\d|3[01]|0?[1-9])(?![\d.:])"
+
+
+def month_pattern(name: str) -> str:
+    """Pattern of a month name in the genitive case, in a named group."""
+    return (
+        rf"(?P<{name}>(?:январ|феврал|март|апрел|июн|июл|август|сентябр|октябр|ноябр|декабр)"
+        r"[а-я]*|ма[йя])(?!\w)"
+    )
+
+
+RANGE_REGEX = re.compile(
+    rf"(?:с\s+)?(?P<start>{DAY})(?:\s+{month_pattern('start_month')})?\s*(?:[-–—]|по|до)\s*"
+    rf"(?P<end>{DAY})\s+{month_pattern('end_month')}",
+    re.IGNORECASE,
+)
+LIST_REGEX = re.compile(
+    rf"(?P<days>{DAY}(?:\s*(?:,|и)\s*{DAY})+)\s+{month_pattern('month')}", re.IGNORECASE
+)
+DATE_REGEX = re.compile(rf"(?P<day>{DAY})\s+{month_pattern('month')}", re.IGNORECASE)
+TIME_REGEX = re.compile(r"(?<![\d:])(?P<hour>[01]?\d|2[0-3]):(?P<minute>[0-5]\d)(?![\d:])")
+
+REGISTRATION_WORDS = r"регистрац\w*|запис\w*|зарегистрир\w*"
+APPLICATION_WORDS = (
+    r"заяв\w*|при[её]м\w*\s+работ|тезис\w*|анкет\w*|call\s+for\s+(?:papers|proposals)|cfp"
+)
+# "регистрация открыта до 20 ноября", "заявки принимаются по 15.11.2026",
+# "дедлайн — 1 декабря": a word of the kind, a few more words, then the date
+DEADLINE_REGEX = re.compile(
+    rf"(?:(?P<kind>{REGISTRATION_WORDS}|{APPLICATION_WORDS})[^.!?\d]{{0,40}}?\s(?:до|по)"
+    r"|(?:дедлайн|deadline)\w*[^.!?\d]{0,30}?)\s*[:—–-]?\s*"
+    rf"(?:(?P<day>{DAY})\s+{month_pattern('month')}"
+    r"|(?P<numeric_day>[0-3]?\d)\.(?P<numeric_month>[01]?\d)(?:\.(?P<year>(?:20)?\d\d))?(?![\d.]))",
+    re.IGNORECASE,
+)
+DEADLINE_KINDS = {"registration": "регистрация", "applications": "приём заявок"}
+
+
+@dataclass
+class Session:
+    """One day of an event, with the start time if the post gives it."""
+
+    day: date
+    start: Optional[time] = None
+
+    def starts_at(self) -> datetime:
+        """Start of the session (midnight for all-day sessions)."""
+        return datetime.combine(self.day, self.start or time())
+
+    def to_dict(self) -> dict:
+        """Convert to a JSON-serializable dictionary."""
+        return {
+            "date": self.day.isoformat(),
+            "time": self.start.strftime("%H:%M") if self.start else None,
+        }
+
+
+@dataclass
+class EventSchedule:
+    """Days an event takes place on."""
+
+    start: date
+    end: date
+    sessions: List[Session] = field(default_factory=list)
+
+    @property
+    def is_multi_day(self) -> bool:
+        """Whether the event spans more than one day."""
+        return self.end > self.start
+
+    @property
+    def has_times(self) -> bool:
+        """Whether any session has a start time."""
+        return any(session.start for session in self.sessions)
+
+    @property
+    def is_continuous(self) -> bool:
+        """Whether the event runs every day from start to end."""
+        return not self.sessions or len(self.sessions) == (self.end - self.start).days + 1
+
+    def sessions_json(self) -> Optional[str]:
+        """Sessions as stored in rss_posts.event_sessions."""
+        if not self.sessions:
+            return None
+        return json.dumps([session.to_dict() for session in self.sessions])
+
+    @classmethod
+    def from_post(cls, post: RSSPost) -> Optional["EventSchedule"]:
+        """Schedule stored on a post, or None if the post has no parsed dates."""
+        if not post.event_start:
+            return None
+        try:
+            items = json.loads(post.event_sessions) if post.event_sessions else []
+        except (ValueError, TypeError):
+            items = []
+        sessions = [
+            Session(
+                day=date.fromisoformat(item["date"]),
+                start=time.fromisoformat(item["time"]) if item.get("time") else None,
+            )
+            for item in items
+            if isinstance(item, dict) and item.get("date")
+        ]
+        end = post.event_end or post.event_start
+        return cls(start=post.event_start, end=end, sessions=sessions)
+
+
+@dataclass
+class Deadline:
+    """Last day to sign up or apply for an event."""
+
+    day: date
+    # A DEADLINE_KINDS key
+    kind: str = "registration"
+
+    @classmethod
+    def from_post(cls, post: RSSPost) -> Optional["Deadline"]:
+        """Deadline stored on a post."""
+        if not post.deadline:
+            return None
+        return cls(post.deadline, post.deadline_kind or "registration")
+
+
+def month_number(name: str) -> int:
+    """Month number of a month name like 'марта'."""
+    name = name.lower()
+    for number, stem in enumerate(MONTH_STEMS, start=1):
+        if name.startswith(stem):
+            return number
+    raise ValueError(f"Unknown month: {name}")
+
+
+def resolve_date(day: int, month: int, reference: date) -> Optional[date]:
+    """Date of a day and month without a year, relative to the publication date."""
+    for year in (reference.year, reference.year + 1):
+        try:
+            value = date(year, month, day)
+        except ValueError:
+            # 29 февраля of a non-leap year, 31 апреля
+            continue
+        if value >= reference - timedelta(days=PAST_DAYS):
+            return value
+    return None
+
+
+def _deadline_day(match: re.Match, today: date) -> Optional[date]:
+    if match["day"]:
+        return resolve_date(int(match["day"]), month_number(match["month"]), today)
+    day, month = int(match["numeric_day"]), int(match["numeric_month"])
+    if not match["year"]:
+        return resolve_date(day, month, today) if 1 <= month <= 12 else None
+    year = int(match["year"])
+    try:
+        return date(year if year > 100 else 2000 + year, month, day)
+    except ValueError:
+        return None
+
+
+def parse_deadline(content: str, reference: Optional[datetime] = None) -> Optional[Deadline]:
+    """
+    Find the registration or application deadline of an event.
+
+    Args:
+        content: Post text
+        reference: Publication time, used to infer the year (defaults to the clock)
+
+    Returns:
+        The earliest deadline the post names, None if it names none
+    """
+    text = " ".join((content or "").split())
+    today = (reference or clock.now()).date()
+    deadlines = []
+    for match in DEADLINE_REGEX.finditer(text):
+        day = _deadline_day(match, today)
+        if day:
+            registration = re.search(REGISTRATION_WORDS, match.group(), re.IGNORECASE)
+            deadlines.append(Deadline(day, "registration" if registration else "applications"))
+    return min(deadlines, key=lambda deadline: deadline.day) if deadlines else None
+
+
+def _session_time(text: str, start: int, end: int) -> Optional[time]:
+    match = TIME_REGEX.search(text, start, min(end, start + TIME_WINDOW))
+    return time(int(match["hour"]), int(match["minute"])) if match else None
+
+
+def parse_schedule(
+    content: str, reference: Optional[datetime] = None
+) -> Optional[EventSchedule]:
+    """
+    Parse the days and start times of an event.
+
+    Args:
+        content: Post text
+        reference: Publication time, used to infer the year (defaults to the clock)
+
+    Returns:
+        EventSchedule, or None if the post mentions no date
+    """
+    text = " ".join((content or "").split())
+    today = (reference or clock.now()).date()
+    # (position, end of match, days)
+    found: List[Tuple[int, int, List[date]]] = []
+    ranges: List[Tuple[date, date]] = []
+    # Deadline dates aren't days of the event
+    taken: List[Tuple[int, int]] = [match.span() for match in DEADLINE_REGEX.finditer(text)]
+
+    def overlaps(match: re.Match) -> bool:
+        return any(match.start() < end and start < match.end() for start, end in taken)
+
+    for match in RANGE_REGEX.finditer(text):
+        end_month = month_number(match["end_month"])
+        start_month = month_number(match["start_month"]) if match["start_month"] else end_month
+        first = resolve_date(int(match["start"]), start_month, today)
+        if not first:
+            continue
+        last = resolve_date(int(match["end"]), end_month, first)
+        if not last or last < first:
+            continue
+        taken.append(match.span())
+        ranges.append((first, last))
+        days = (last - first).days + 1
+        if days <= MAX_SESSIONS:
+            found.append(
+                (match.start(), match.end(), [first + timedelta(days=n) for n in range(days)])
+            )
+
+    for match in LIST_REGEX.finditer(text):
+        if overlaps(match):
+            continue
+        month = month_number(match["month"])
+        days = [resolve_date(int(day), month, today) for day in re.findall(r"\d+", match["days"])]
+        taken.append(match.span())
+        found.append((match.start(), match.end(), [day for day in days if day]))
+
+    for match in DATE_REGEX.finditer(text):
+        if overlaps(match):
+            continue
+        day = resolve_date(int(match["day"]), month_number(match["month"]), today)
+        if day:
+            taken.append(match.span())
+            found.append((match.start(), match.end(), [day]))
+
+    found.sort(key=lambda item: item[0])
+    sessions: dict = {}
+    for index, (_, end, days) in enumerate(found):
+        next_start = found[index + 1][0] if index + 1 < len(found) else len(text)
+        start_time = _session_time(text, end, next_start)
+        for day in days:
+            if day not in sessions or (start_time and not sessions[day].start):
+                sessions[day] = Session(day, start_time)
+
+    days = list(sessions) + [day for span in ranges for day in span]
+    if not days:
+        return None
+    return EventSchedule(
+        start=min(days),
+        end=max(days),
+        sessions=[sessions[day] for day in sorted(sessions)],
+    )
@@ -0,0 +1,69 @@
+"""Online, offline and hybrid event classification.
+
+Links to video platforms and words like "онлайн" or "вебинар" mark an
+event as online; addresses and venue words ("ул.", "проспект", "в клубе")
+mark it as offline. Events with both, such as a concert with a live
+stream, are hybrid.
+"""
+
+import re
+from typing import Optional
+
+ONLINE = "online"
+OFFLINE = "offline"
+HYBRID = "hybrid"
+FORMATS = (ONLINE, OFFLINE, HYBRID)
+
+# schema.org eventAttendanceMode values
+ATTENDANCE_MODES = {
+    ONLINE: "https://schema.org/OnlineEventAttendanceMode",
+    OFFLINE: "https://schema.org/OfflineEventAttendanceMode",
+    HYBRID: "https://schema.org/MixedEventAttendanceMode",
+}
+
+LABELS = {ONLINE: "онлайн", OFFLINE: "офлайн", HYBRID: "онлайн и офлайн"}
+
+ONLINE_REGEX = re.compile(
+    r"(?:zoom\.us|meet\.google\.com|teams\.microsoft\.com|teams\.live\.com|youtube\.com/live"
+    r"|youtu\.be|twitch\.tv|vk\.com/video|vkvideo\.ru|rutube\.ru|telemost\.yandex"
+    r"|(?<!\w)(?:онлайн|online|вебинар\w*|трансляци\w+|стрим\w*|в\s+zoom|zoom-?конференци\w+))",
+    re.IGNORECASE,
+)
+OFFLINE_REGEX = re.compile(
+    r"(?<!\w)(?:ул\.|улиц[аеы]|пр-т|просп(?:ект|\.)|пер\.|переулок|наб\.|набережн\w+"
+    r"|пл\.|площад[ьи]|шоссе|бульвар|б-р|д\.\s*\d+|адрес\w*|место\s+проведения|офлайн|offline"
+    r"|в\s+(?:клубе|баре|кафе|театре|музее|библиотеке|парке|галерее|кинотеатре|филармонии|дк)"
+    r")(?!\w)",
+    re.IGNORECASE,
+)
+
+
+def classify_format(content: str) -> Optional[str]:
+    """
+    Classify an event as online, offline or hybrid.
+
+    Args:
+        content: Post text
+
+    Returns:
+        'online', 'offline', 'hybrid', or None if the post gives no hint
+    """
+    online = bool(ONLINE_REGEX.search(content or ""))
+    offline = bool(OFFLINE_REGEX.search(content or ""))
+    if online and offline:
+        return HYBRID
+    if online:
+        return ONLINE
+    if offline:
+        return OFFLINE
+    return None
+
+
+def is_online(event_format: Optional[str]) -> bool:
+    """Check whether an event can be attended online."""
+    return event_format in (ONLINE, HYBRID)
+
+
+def is_offline(event_format: Optional[str]) -> bool:
+    """Check whether an event can be attended in person."""
+    return event_format in (OFFLINE, HYBRID)
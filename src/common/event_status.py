@@ -0,0 +1,52 @@
+"""Cancelled and postponed events.
+
+Organizers announce changes in a new post ("концерт отменяется",
+"спектакль переносится на 12 апреля"), usually linking to the original
+announcement. The status is set on the new post and on the announcement it
+links to, so event pages, calendars and the public API show the change.
+"""
+
+import re
+from typing import Optional
+
+CANCELLED = "cancelled"
+POSTPONED = "postponed"
+
+# schema.org eventStatus values
+SCHEMA_STATUSES = {
+    CANCELLED: "https://schema.org/EventCancelled",
+    POSTPONED: "https://schema.org/EventPostponed",
+}
+# iCalendar VEVENT STATUS values
+ICS_STATUSES = {CANCELLED: "CANCELLED", POSTPONED: "TENTATIVE"}
+LABELS = {CANCELLED: "Событие отменено", POSTPONED: "Событие перенесено"}
+
+CANCELLED_REGEX = re.compile(
+    r"(?<!\w)(?:отмен(?:яется|яются|ен[аоы]?|ён|ил[иа]?"
+    r"|а\s+(?:концерта|спектакля|события|мероприятия))"
+    r"|не\s+состоится|не\s+состоятся|cancell?ed)(?!\w)",
+    re.IGNORECASE,
+)
+POSTPONED_REGEX = re.compile(
+    r"(?<!\w)(?:перенос(?:ится|ятся)|перенесен[аоы]?|перенесён"
+    r"|перенос\s+(?:концерта|спектакля|даты)|postponed)(?!\w)",
+    re.IGNORECASE,
+)
+
+
+def detect_status(content: str) -> Optional[str]:
+    """
+    Detect whether a post announces a cancellation or a new date.
+
+    Args:
+        content: Post text
+
+    Returns:
+        'cancelled', 'postponed', or None for regular announcements
+    """
+    text = content or ""
+    if CANCELLED_REGEX.search(text):
+        return CANCELLED
+    if POSTPONED_REGEX.search(text):
+        return POSTPONED
+    return None
@@ -0,0 +1,193 @@
+"""Fault injection for resilience testing.
+
+Retries, the publish failure queue and the alerts only prove themselves when
+something breaks. With the FAULTS environment variable set, HTTP requests
+(everything sent through requests) and database calls are slowed down,
+failed or cut short at random, so that machinery can be watched at work in a
+staging environment. Entries are comma-separated:
+
+    FAULTS="http=delay:3@20%,http[rss-bridge.example]=error:503@10%,http=partial@5%,db=error@2%"
+
+- `target` is `http` or `db`, optionally scoped to a host (`http[host]`) or
+  to a database method (`db[fetch]`, also execute/fetchrow/fetchval)
+- `delay:SECONDS` waits before the call
+- `error` fails the call: a connection error, or for HTTP with a status
+  (`error:503`) a response with that status
+- `partial:FRACTION` keeps that share (half by default) of an HTTP body or
+  of the rows a database fetch returns
+- `@N%` is the chance of the fault on each call (every call by default)
+
+FAULTS_SEED makes the random choices repeatable. Nothing is injected when
+FAULTS is empty, which is the default; never set it in production.
+"""
+
+import logging
+import os
+import random
+import re
+import time
+from dataclasses import dataclass
+from typing import Any, Awaitable, Callable, List, Optional
+from urllib.parse import urlsplit
+
+import requests
+
+from common import clock
+
+logger = logging.getLogger(__name__)
+
+ENTRY_REGEX = re.compile(
+    r"^(?P<target>http|db)(?:\[(?P<scope>[^\]]+)\])?="
+    r"(?P<kind>delay|error|partial)(?::(?P<arg>[^@]+))?(?:@(?P<rate>[\d.]+)%)?$"
+)
+
+
+class InjectedFault(ConnectionError):
+    """Raised for an injected database error."""
+
+
+@dataclass
+class Fault:
+    """One configured fault."""
+
+    target: str
+    kind: str
+    # Host or database method it is limited to, if any
+    scope: Optional[str] = None
+    arg: Optional[float] = None
+    # Chance of the fault on each call, 0 to 100
+    rate: float = 100.0
+
+    @staticmethod
+    def parse(entry: str) -> "Fault":
+        """
+        Parse one entry of the FAULTS format.
+
+        Raises:
+            ValueError: If the entry is not recognized
+        """
+        match = ENTRY_REGEX.match(entry)
+        if not match:
+            raise ValueError(f"Invalid fault entry: {entry}")
+        rate = float(match.group("rate") or 100)
+        if not 0 <= rate <= 100:
+            raise ValueError(f"Fault rate out of range: {entry}")
+        arg = match.group("arg")
+        return Fault(
+            target=match.group("target"),
+            kind=match.group("kind"),
+            scope=match.group("scope"),
+            arg=float(arg.rstrip("s")) if arg else None,
+            rate=rate,
+        )
+
+    def describe(self) -> str:
+        """The fault as configured, for logs and errors."""
+        scope = f"[{self.scope}]" if self.scope else ""
+        arg = f":{self.arg:g}" if self.arg is not None else ""
+        return f"{self.target}{scope}={self.kind}{arg}@{self.rate:g}%"
+
+
+class FaultInjector:
+    """Parsed fault configuration, deciding which faults hit each call."""
+
+    def __init__(self, spec: str = "", seed: Optional[int] = None):
+        """
+        Args:
+            spec: Faults in the FAULTS format
+            seed: Seed of the random choices, for repeatable runs
+        """
+        self.faults = [Fault.parse(entry.strip()) for entry in spec.split(",") if entry.strip()]
+        self.random = random.Random(seed)
+
+    @classmethod
+    def from_env(cls) -> "FaultInjector":
+        """Load faults from the FAULTS and FAULTS_SEED environment variables."""
+        seed = os.getenv("FAULTS_SEED")
+        return cls(os.getenv("FAULTS", ""), int(seed) if seed else None)
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self.faults)
+
+    def pick(self, target: str, scope: Optional[str] = None) -> List[Fault]:
+        """Faults that hit one call, each one drawn by its rate."""
+        hit = [
+            fault
+            for fault in self.faults
+            if fault.target == target
+            and fault.scope in (None, scope)
+            and self.random.uniform(0, 100) < fault.rate
+        ]
+        for fault in hit:
+            logger.warning(f"Injecting fault {fault.describe()} ({scope or target})")
+        return hit
+
+    def wrap_send(self, send: Callable) -> Callable:
+        """Wrap requests.Session.send to inject the HTTP faults."""
+
+        def send_with_faults(session, request, **kwargs):
+            faults = self.pick("http", urlsplit(request.url).hostname)
+            for fault in faults:
+                if fault.kind == "delay":
+                    time.sleep(fault.arg or 1)
+            for fault in faults:
+                if fault.kind == "error" and fault.arg is None:
+                    raise requests.ConnectionError(
+                        f"Injected fault {fault.describe()}", request=request
+                    )
+                if fault.kind == "error":
+                    return _error_response(request, int(fault.arg))
+
+            response = send(session, request, **kwargs)
+            for fault in faults:
+                if fault.kind == "partial":
+                    content = response.content
+                    response._content = content[: int(len(content) * _fraction(fault))]
+            return response
+
+        send_with_faults.injects_faults = True
+        return send_with_faults
+
+    async def call_db(self, method: str, call: Callable[[], Awaitable[Any]]) -> Any:
+        """Make a database call, delaying, failing or cutting it short."""
+        faults = self.pick("db", method)
+        for fault in faults:
+            if fault.kind == "delay":
+                await clock.sleep(fault.arg or 1)
+        for fault in faults:
+            if fault.kind == "error":
+                raise InjectedFault(f"Injected fault {fault.describe()}")
+
+        result = await call()
+        for fault in faults:
+            if fault.kind == "partial" and isinstance(result, list):
+                result = result[: int(len(result) * _fraction(fault))]
+        return result
+
+
+def _fraction(fault: Fault) -> float:
+    return 0.5 if fault.arg is None else min(max(fault.arg, 0.0), 1.0)
+
+
+def _error_response(request, status: int) -> requests.Response:
+    response = requests.Response()
+    response.status_code = status
+    response.reason = "Injected fault"
+    response._content = b""
+    response.url = request.url
+    response.request = request
+    return response
+
+
+def install_http(injector: FaultInjector) -> None:
+    """Route every requests session through the injector, once."""
+    if not getattr(requests.Session.send, "injects_faults", False):
+        requests.Session.send = injector.wrap_send(requests.Session.send)
+
+
+# Global fault injector
+faults = FaultInjector.from_env()
+if faults.enabled:
+    logger.warning(f"Fault injection enabled: {', '.join(f.describe() for f in faults.faults)}")
+    install_http(faults)
@@ -0,0 +1,116 @@
+"""Feature flags for gating experimental pipeline stages.
+
+Flags are configured with the FEATURE_FLAGS environment variable as a
+comma-separated list of `name=value` entries. A value is either a switch
+(on/off/true/false/1/0) or a rollout percentage (e.g. `25%`). An entry can be
+scoped to a single tenant, channel or chat with `name[scope]=value`; scoped
+entries take precedence over the global one:
+
+    FEATURE_FLAGS="llm_summary=25%,llm_summary[mediarzn]=on,channel_polling[spam]=off"
+
+Percentage rollouts hash the flag name together with the scope, so a given
+scope keeps the same decision between runs and grows monotonically as the
+percentage is raised.
+"""
+
+import hashlib
+import logging
+import os
+import re
+from typing import Dict, Optional, Tuple
+
+logger = logging.getLogger(__name__)
+
+ENTRY_REGEX = re.compile(r"^(?P<name>[\w.-]+)(?:\[(?P<scope>[^\]]+)\])?=(?P<value>.+)$")
+
+TRUE_VALUES = {"on", "true", "1", "yes"}
+FALSE_VALUES = {"off", "false", "0", "no"}
+
+
+def parse_value(value: str) -> int:
+    """
+    Parse a flag value into a rollout percentage.
+
+    Args:
+        value: Switch or percentage string
+
+    Returns:
+        Rollout percentage between 0 and 100
+
+    Raises:
+        ValueError: If the value is not recognized
+    """
+    value = value.strip().lower()
+    if value in TRUE_VALUES:
+        return 100
+    if value in FALSE_VALUES:
+        return 0
+    if value.endswith("%"):
+        percentage = int(value[:-1])
+        if not 0 <= percentage <= 100:
+            raise ValueError(f"Rollout percentage out of range: {value}")
+        return percentage
+    raise ValueError(f"Invalid feature flag value: {value}")
+
+
+def rollout_bucket(name: str, scope: str) -> int:
+    """Deterministically map a flag/scope pair to a bucket in [0, 100)."""
+    digest = hashlib.sha256(f"{name}:{scope}".encode()).digest()
+    return int.from_bytes(digest[:4], "big") % 100
+
+
+class FeatureFlags:
+    """Parsed feature flag configuration."""
+
+    def __init__(self, spec: str = ""):
+        """
+        Initialize feature flags.
+
+        Args:
+            spec: Flag specification in the FEATURE_FLAGS format
+        """
+        self.flags: Dict[Tuple[str, Optional[str]], int] = {}
+        for entry in spec.split(","):
+            entry = entry.strip()
+            if not entry:
+                continue
+            match = ENTRY_REGEX.match(entry)
+            if not match:
+                raise ValueError(f"Invalid feature flag entry: {entry}")
+            key = (match.group("name"), match.group("scope"))
+            self.flags[key] = parse_value(match.group("value"))
+
+    @classmethod
+    def from_env(cls) -> "FeatureFlags":
+        """Load flags from the FEATURE_FLAGS environment variable."""
+        return cls(os.getenv("FEATURE_FLAGS", ""))
+
+    def is_enabled(self, name: str, scope: Optional[str] = None, default: bool = False) -> bool:
+        """
+        Check whether a feature is enabled.
+
+        Args:
+            name: Flag name
+            scope: Tenant, channel or chat the decision applies to
+            default: Result when the flag is not configured
+
+        Returns:
+            True if the feature is enabled for the scope
+        """
+        percentage = None
+        if scope is not None:
+            percentage = self.flags.get((name, scope))
+        if percentage is None:
+            percentage = self.flags.get((name, None))
+        if percentage is None:
+            return default
+
+        if percentage >= 100:
+            return True
+        if percentage <= 0:
+            return False
+        return rollout_bucket(name, scope or "") < percentage
+
+
+# Global feature flags instance
+feature_flags = FeatureFlags.from_env()
@@ -0,0 +1,227 @@
+"""RSS 2.0 and Atom rendering of feeds.
+
+The reverse of the RSS reader's parser: an RSSChannel, parsed from a feed or
+built from stored events with events_channel, is written back as XML that
+the parser reads into the same items. Descriptions are plain text, so they
+go out as escaped HTML with line breaks kept.
+
+Dates are rewritten in the format of the document from whatever the parser
+understands. Like stored timestamps they have no time zone, so they are
+written with the "unknown offset" of RFC 822 and RFC 3339 (-0000, -00:00).
+"""
+
+import mimetypes
+from datetime import datetime
+from email.utils import format_datetime
+from html import escape
+from typing import List, Optional
+from urllib.parse import urljoin
+from xml.etree import ElementTree as ET
+
+from common import clock
+from common.db.models import RSSPost
+from common.models.feed import RSSChannel, RSSItem
+from common.pages import event_path, event_title
+from common.utils.dates import UnparseableDate, parse_feed_date
+from common.utils.links import channel_from_link
+
+ATOM = "http://www.w3.org/2005/Atom"
+MEDIA = "http://search.yahoo.com/mrss/"
+ITUNES = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+
+ET.register_namespace("atom", ATOM)
+ET.register_namespace("media", MEDIA)
+ET.register_namespace("itunes", ITUNES)
+
+# Length of titles made from the description of items without one
+TITLE_LENGTH = 90
+
+
+def events_channel(posts: List[RSSPost], title: str, site_url: str = "") -> RSSChannel:
+    """
+    Channel of events, newest first as given.
+
+    Args:
+        posts: Events to include
+        title: Channel title
+        site_url: Base URL of the site; items link to their event pages when
+            it's set and to the source post otherwise
+
+    Returns:
+        RSSChannel whose items keep the source post link as their GUID
+    """
+    site = site_url.rstrip("/") + "/" if site_url else ""
+    items = [
+        RSSItem(
+            link=urljoin(site, event_path(post)) if site else post.link,
+            title=event_title(post),
+            description=post.summary or post.content or "",
+            pub_date=format_datetime(post.pub_date) if post.pub_date else None,
+            media_urls=post.media_urls(),
+            categories=list(post.tags or []),
+            guid=post.link,
+            author=channel_from_link(post.link) or None,
+        )
+        for post in posts
+    ]
+    return RSSChannel(title=title, link=site or "", description=title, items=items)
+
+
+def render_rss(channel: RSSChannel, self_url: str = "") -> bytes:
+    """
+    Render a channel as an RSS 2.0 document.
+
+    Args:
+        channel: Channel to render
+        self_url: URL the feed is served at, linked with atom:link rel="self"
+
+    Returns:
+        UTF-8 encoded XML
+    """
+    rss = ET.Element("rss", version="2.0")
+    element = ET.SubElement(rss, "channel")
+    ET.SubElement(element, "title").text = channel.title
+    ET.SubElement(element, "link").text = channel.link
+    ET.SubElement(element, "description").text = channel.description
+    if self_url:
+        ET.SubElement(
+            element, f"{{{ATOM}}}link", href=self_url, rel="self", type="application/rss+xml"
+        )
+    if channel.language:
+        ET.SubElement(element, "language").text = channel.language
+    if channel.last_build_date:
+        ET.SubElement(element, "lastBuildDate").text = _rfc822(channel.last_build_date)
+
+    for item in channel.items:
+        _rss_item(ET.SubElement(element, "item"), item)
+    return ET.tostring(rss, encoding="utf-8", xml_declaration=True)
+
+
+def _rss_item(element: ET.Element, item: RSSItem) -> None:
+    if item.title:
+        ET.SubElement(element, "title").text = item.title
+    if item.link:
+        ET.SubElement(element, "link").text = item.link
+    ET.SubElement(element, "description").text = _html(item.description)
+    if item.guid:
+        permalink = "true" if item.guid_is_permalink else "false"
+        ET.SubElement(element, "guid", isPermaLink=permalink).text = item.guid
+    pub_date = _rfc822(item.pub_date) if item.pub_date else None
+    if pub_date:
+        ET.SubElement(element, "pubDate").text = pub_date
+    if item.author:
+        ET.SubElement(element, "author").text = item.author
+    if item.comments:
+        ET.SubElement(element, "comments").text = item.comments
+    for category in item.categories:
+        ET.SubElement(element, "category").text = category
+
+    podcast = item.podcast
+    for url in item.media_urls:
+        # The parser reads the episode image into the media URLs
+        if not podcast or url != podcast.image:
+            ET.SubElement(element, f"{{{MEDIA}}}content", url=url)
+    if not podcast:
+        return
+    if podcast.audio_url:
+        audio_type = mimetypes.guess_type(podcast.audio_url)[0] or ""
+        ET.SubElement(
+            element,
+            "enclosure",
+            url=podcast.audio_url,
+            type=audio_type if audio_type.startswith("audio/") else "audio/mpeg",
+            length="0",
+        )
+    if podcast.image:
+        ET.SubElement(element, f"{{{ITUNES}}}image", href=podcast.image)
+    fields = {
+        "title": podcast.title,
+        "duration": podcast.duration,
+        "season": podcast.season,
+        "episode": podcast.episode,
+        "episodeType": podcast.episode_type,
+        "explicit": None if podcast.explicit is None else str(podcast.explicit).lower(),
+    }
+    for name, value in fields.items():
+        if value is not None:
+            ET.SubElement(element, f"{{{ITUNES}}}{name}").text = str(value)
+
+
+def render_atom(channel: RSSChannel, self_url: str = "") -> bytes:
+    """
+    Render a channel as an Atom document.
+
+    Args:
+        channel: Channel to render
+        self_url: URL the feed is served at; it is also the feed ID, falling
+            back to the channel link
+
+    Returns:
+        UTF-8 encoded XML
+    """
+    # Unprefixed tags in the Atom namespace
+    feed = ET.Element("feed", xmlns=ATOM)
+    if channel.language:
+        feed.set("{http://www.w3.org/XML/1998/namespace}lang", channel.language)
+    ET.SubElement(feed, "title").text = channel.title
+    if channel.description:
+        ET.SubElement(feed, "subtitle").text = channel.description
+    # The parser takes the first link as the channel link
+    if channel.link:
+        ET.SubElement(feed, "link", href=channel.link, rel="alternate")
+    if self_url:
+        ET.SubElement(feed, "link", href=self_url, rel="self")
+    ET.SubElement(feed, "id").text = self_url or channel.link
+
+    dates = [_date(item.pub_date) for item in channel.items if item.pub_date]
+    updated = _date(channel.last_build_date) if channel.last_build_date else None
+    updated = updated or max((date for date in dates if date), default=None) or clock.now()
+    ET.SubElement(feed, "updated").text = _rfc3339(updated)
+
+    for item in channel.items:
+        _atom_entry(ET.SubElement(feed, "entry"), item, updated)
+    return ET.tostring(feed, encoding="utf-8", xml_declaration=True)
+
+
+def _atom_entry(element: ET.Element, item: RSSItem, feed_updated: datetime) -> None:
+    title = item.title or " ".join(item.description.split())[:TITLE_LENGTH]
+    ET.SubElement(element, "title").text = title
+    if item.link:
+        ET.SubElement(element, "link", href=item.link, rel="alternate")
+    if item.comments:
+        ET.SubElement(element, "link", href=item.comments, rel="replies")
+    for url in item.media_urls:
+        ET.SubElement(element, "link", href=url, rel="enclosure")
+    ET.SubElement(element, "id").text = item.id()
+
+    published = _date(item.pub_date) if item.pub_date else None
+    if published:
+        ET.SubElement(element, "published").text = _rfc3339(published)
+    ET.SubElement(element, "updated").text = _rfc3339(published or feed_updated)
+    if item.author:
+        author = ET.SubElement(element, "author")
+        ET.SubElement(author, "name").text = item.author
+    for category in item.categories:
+        ET.SubElement(element, "category", term=category)
+    content = ET.SubElement(element, "content", type="html")
+    content.text = _html(item.description)
+
+
+def _html(text: str) -> str:
+    return "<br>".join(escape(line) for line in text.split("\n"))
+
+
+def _date(value: str) -> Optional[datetime]:
+    try:
+        return parse_feed_date(value)
+    except UnparseableDate:
+        return None
+
+
+def _rfc822(value: str) -> Optional[str]:
+    date = _date(value)
+    return format_datetime(date) if date else None
+
+
+def _rfc3339(date: datetime) -> str:
+    return date.replace(microsecond=0).isoformat() + "-00:00"
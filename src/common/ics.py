@@ -0,0 +1,231 @@
+"""iCalendar (RFC 5545) export of events.
+
+Events with dates parsed from the text become one VEVENT per session when
+start times are known, or a single all-day VEVENT spanning the whole range
+(DTEND is the day after the last one). Without parsed dates, as on the event
+pages, the publication time is used as the start of a one-hour event. Times
+are written as floating local times because stored timestamps have no time
+zone.
+
+When a language is requested, SUMMARY and DESCRIPTION use the cached
+translation and fall back to the original text; the original is appended
+to the description so both languages are available. Phone numbers and
+emails found in the text become CONTACT properties.
+
+A registration or application deadline becomes an entry of its own, on the
+last day, with an alarm DEADLINE_REMINDER before it; the event keeps its
+UID and times, so calendars tell the two apart.
+"""
+
+from datetime import date, datetime, timedelta, timezone
+from typing import Dict, List, Optional, Tuple
+
+from common.contacts import contact_label
+from common.db.models import RSSPost
+from common.event_dates import Deadline, EventSchedule
+from common.event_status import ICS_STATUSES
+from common.pages import deadline_label, event_description, event_title
+from common.utils.links import post_slug
+
+PRODID = "-//event-platform//events//RU"
+DURATION = timedelta(hours=1)
+# When calendar apps remind of a deadline: at the start of the day before
+DEADLINE_REMINDER = "-P1D"
+
+
+def escape_text(value: str) -> str:
+    """Escape a TEXT property value."""
+    return (
+        value.replace("\\", "\\\\")
+        .replace(";", "\\;")
+        .replace(",", "\\,")
+        .replace("\r\n", "\\n")
+        .replace("\n", "\\n")
+    )
+
+
+def fold_line(line: str) -> str:
+    """Fold a content line into chunks of at most 75 octets."""
+    encoded = line.encode()
+    if len(encoded) <= 75:
+        return line
+
+    chunks = []
+    start = 0
+    limit = 75
+    while start < len(encoded):
+        end = min(start + limit, len(encoded))
+        # Never split a multi-byte UTF-8 character
+        while end < len(encoded) and (encoded[end] & 0xC0) == 0x80:
+            end -= 1
+        chunks.append(encoded[start:end].decode())
+        start = end
+        # Continuation lines start with a space, which counts towards the limit
+        limit = 74
+    return "\r\n ".join(chunks)
+
+
+def format_datetime(value: datetime) -> str:
+    """Format a floating local DATE-TIME value."""
+    return value.strftime("%Y%m%dT%H%M%S")
+
+
+def format_date(value: date) -> str:
+    """Format a DATE value."""
+    return value.strftime("%Y%m%d")
+
+
+def event_times(post: RSSPost) -> List[Tuple[str, List[str]]]:
+    """
+    Occurrences of an event as (UID suffix, DTSTART and DTEND lines).
+
+    The suffix is empty for single occurrences, so their UID doesn't change
+    when dates are parsed later.
+    """
+    schedule = EventSchedule.from_post(post)
+    if not schedule:
+        if not post.pub_date:
+            return []
+        start = post.pub_date
+        end = start + DURATION
+        return [("", [f"DTSTART:{format_datetime(start)}", f"DTEND:{format_datetime(end)}"])]
+
+    if not schedule.has_times and schedule.is_continuous:
+        end = schedule.end + timedelta(days=1)
+        return [
+            (
+                "",
+                [
+                    f"DTSTART;VALUE=DATE:{format_date(schedule.start)}",
+                    f"DTEND;VALUE=DATE:{format_date(end)}",
+                ],
+            )
+        ]
+
+    occurrences = []
+    for session in schedule.sessions:
+        suffix = f"-{format_date(session.day)}" if len(schedule.sessions) > 1 else ""
+        if session.start:
+            start = session.starts_at()
+            lines = [
+                f"DTSTART:{format_datetime(start)}",
+                f"DTEND:{format_datetime(start + DURATION)}",
+            ]
+        else:
+            end = session.day + timedelta(days=1)
+            lines = [
+                f"DTSTART;VALUE=DATE:{format_date(session.day)}",
+                f"DTEND;VALUE=DATE:{format_date(end)}",
+            ]
+        occurrences.append((suffix, lines))
+    return occurrences
+
+
+def render_event(
+    post: RSSPost, translations: Optional[Dict[str, dict]] = None, language: str = ""
+) -> List[str]:
+    """
+    Render a post as VEVENT content lines, one VEVENT per occurrence.
+
+    Args:
+        post: Event post (posts without dates or pub_date are skipped)
+        translations: Language -> {"title": ..., "summary": ...} for the post
+        language: Language of SUMMARY and DESCRIPTION (empty for the original)
+
+    Returns:
+        Unfolded content lines
+    """
+    occurrences = event_times(post)
+    deadline = Deadline.from_post(post)
+    if not occurrences and not deadline:
+        return []
+
+    title = event_title(post)
+    description = event_description(post, max_length=1000)
+    translated = (translations or {}).get(language, {}) if language else {}
+    if translated.get("title"):
+        title = translated["title"]
+    if translated.get("summary"):
+        description = f"{translated['summary']}\n\n{description}"
+
+    properties = []
+    if translated.get("title"):
+        properties.append(f"SUMMARY;LANGUAGE={language}:{escape_text(title)}")
+    else:
+        properties.append(f"SUMMARY:{escape_text(title)}")
+    properties += [
+        f"DESCRIPTION:{escape_text(description)}",
+        f"URL:{post.link}",
+    ]
+    if post.tags:
+        properties.append(f"CATEGORIES:{','.join(escape_text(tag) for tag in post.tags)}")
+    properties += [f"CONTACT:{escape_text(contact_label(c))}" for c in post.contacts or []]
+    # Calendar apps drop or mark cancelled events on the next refresh
+    properties.append(f"STATUS:{ICS_STATUSES.get(post.event_status or '', 'CONFIRMED')}")
+
+    stamp = datetime.now(timezone.utc).strftime("%Y%m%dT%H%M%SZ")
+    lines = []
+    for suffix, times in occurrences:
+        lines += [
+            "BEGIN:VEVENT",
+            f"UID:{post_slug(post.link)}{suffix}@event-platform",
+            f"DTSTAMP:{stamp}",
+            *times,
+            *properties,
+            "END:VEVENT",
+        ]
+    if deadline:
+        lines += render_deadline(post, deadline, title, stamp)
+    return lines
+
+
+def render_deadline(post: RSSPost, deadline: Deadline, title: str, stamp: str) -> List[str]:
+    """VEVENT of the deadline of an event: a free all-day entry with its own alarm."""
+    label = deadline_label(deadline)
+    return [
+        "BEGIN:VEVENT",
+        f"UID:{post_slug(post.link)}-deadline@event-platform",
+        f"DTSTAMP:{stamp}",
+        f"DTSTART;VALUE=DATE:{format_date(deadline.day)}",
+        f"DTEND;VALUE=DATE:{format_date(deadline.day + timedelta(days=1))}",
+        f"SUMMARY:{escape_text(f'{label}: {title}')}",
+        f"URL:{post.link}",
+        "TRANSP:TRANSPARENT",
+        "BEGIN:VALARM",
+        "ACTION:DISPLAY",
+        f"DESCRIPTION:{escape_text(label)}",
+        f"TRIGGER:{DEADLINE_REMINDER}",
+        "END:VALARM",
+        "END:VEVENT",
+    ]
+
+
+def render_calendar(
+    posts: List[RSSPost],
+    name: str,
+    translations: Optional[Dict[str, Dict[str, dict]]] = None,
+    language: str = "",
+) -> str:
+    """
+    Render posts as an iCalendar file.
+
+    Args:
+        posts: Event posts
+        name: Calendar name shown by calendar apps
+        translations: Link -> language -> translated title and summary
+        language: Language to render (empty for the original)
+
+    Returns:
+        iCalendar text with CRLF line endings
+    """
+    lines = [
+        "BEGIN:VCALENDAR",
+        "VERSION:2.0",
+        f"PRODID:{PRODID}",
+        "CALSCALE:GREGORIAN",
+        f"X-WR-CALNAME:{escape_text(name)}",
+    ]
+    for post in posts:
+        lines += render_event(post, (translations or {}).get(post.link), language)
+    lines.append("END:VCALENDAR")
+    return "".join(f"{fold_line(line)}\r\n" for line in lines)
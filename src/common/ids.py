@@ -0,0 +1,98 @@
+"""IDs of stored entities.
+
+Promotions, poll runs, submissions, digest drafts and publish failures are
+identified by ULIDs (https://github.com/ulid/spec) generated in the
+application instead of database sequences: 26 Crockford base32 characters,
+a millisecond timestamp followed by randomness. They sort by creation time,
+don't collide across databases or backups restored elsewhere, and can be
+handed to other services, the API and exports as they are.
+
+The generator is pluggable like the clock: code asks new_id(), and tests
+can install a generator of their own with set_generator().
+"""
+
+import re
+import secrets
+import threading
+from datetime import datetime
+from typing import Optional
+
+from common import clock
+
+CROCKFORD = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+# 128 bits in 26 characters: the first one is at most 7
+ULID_PATTERN = re.compile(r"[0-7][0-9A-HJKMNP-TV-Z]{25}")
+RANDOM_BITS = 80
+
+
+def encode_ulid(timestamp_ms: int, randomness: int) -> str:
+    """ULID of a millisecond Unix timestamp and 80 bits of randomness."""
+    value = (timestamp_ms << RANDOM_BITS) | randomness
+    return "".join(CROCKFORD[(value >> shift) & 31] for shift in range(125, -1, -5))
+
+
+def parse_id(value: str) -> Optional[str]:
+    """
+    Read an ID taken from a URL or a request.
+
+    Returns:
+        The ID in its canonical upper case, or None if it isn't a ULID
+    """
+    value = value.strip().upper()
+    return value if ULID_PATTERN.fullmatch(value) else None
+
+
+class IdGenerator:
+    """Source of entity IDs."""
+
+    def new(self, at: Optional[datetime] = None) -> str:
+        """A new ID, created at the given time (the clock's current time by default)."""
+        raise NotImplementedError
+
+
+class UlidGenerator(IdGenerator):
+    """
+    Monotonic ULIDs: IDs created within the same millisecond increment the
+    randomness of the previous one, so they keep their order too.
+    """
+
+    def __init__(self):
+        self._last_ms = -1
+        self._last_random = 0
+        self._lock = threading.Lock()
+
+    def new(self, at: Optional[datetime] = None) -> str:
+        timestamp_ms = int((at or clock.now()).timestamp() * 1000)
+        with self._lock:
+            if timestamp_ms <= self._last_ms and self._last_random < (1 << RANDOM_BITS) - 1:
+                # Same millisecond, or the clock went back
+                timestamp_ms, randomness = self._last_ms, self._last_random + 1
+            else:
+                randomness = secrets.randbits(RANDOM_BITS)
+            self._last_ms, self._last_random = timestamp_ms, randomness
+        return encode_ulid(timestamp_ms, randomness)
+
+
+_generator: IdGenerator = UlidGenerator()
+
+
+def get_generator() -> IdGenerator:
+    """The generator in use."""
+    return _generator
+
+
+def set_generator(generator: IdGenerator) -> IdGenerator:
+    """
+    Replace the generator in use.
+
+    Returns:
+        The previous generator, to restore it afterwards
+    """
+    global _generator
+    previous, _generator = _generator, generator
+    return previous
+
+
+def new_id(at: Optional[datetime] = None) -> str:
+    """A new ID from the generator in use."""
+    return _generator.new(at)
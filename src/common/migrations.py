@@ -0,0 +1,179 @@
+"""Expand/contract schema migrations for rolling deploys.
+
+During a rolling deploy old and new versions of the services run against
+the same database, so a schema change is split in two phases:
+
+- expand migrations only add: nullable columns or ones with a server
+  default, tables, indexes built concurrently, backfills. Both versions work
+  with the schema before and after them.
+- contract migrations remove what only the old version used: they drop or
+  rename columns, add NOT NULL constraints and the like. They run once no
+  instance of the old version is left.
+
+A migration declares its phase with a module-level `phase = "contract"`;
+migrations without one are expand migrations. Deploys run
+
+    uv run -m src.common.migrations expand     # before rolling out the new version
+    uv run -m src.common.migrations contract   # once every instance runs it
+
+`expand` applies pending migrations up to the first contract one, which
+leaves the schema compatible with both versions; `status` lists what is
+pending. The helpers below keep expand migrations compatible.
+"""
+
+import argparse
+import asyncio
+import sys
+from pathlib import Path
+from typing import List, Optional, Sequence
+
+import asyncpg
+import sqlalchemy as sa
+from alembic import command, op
+from alembic.config import Config
+from alembic.script import ScriptDirectory
+
+from common.db.config import settings
+
+EXPAND = "expand"
+CONTRACT = "contract"
+
+ALEMBIC_INI = Path(__file__).resolve().parents[2] / "alembic.ini"
+
+
+def phase_of(migration) -> str:
+    """Phase a migration script declares, expand by default."""
+    return getattr(migration.module, "phase", EXPAND)
+
+
+def pending_migrations(script, current: Optional[str]) -> List:
+    """
+    Migrations after the current revision up to the head, oldest first.
+
+    Args:
+        script: Alembic ScriptDirectory
+        current: Revision the database is at, None for an empty one
+
+    Raises:
+        ValueError: If the current revision isn't one of the scripts
+    """
+    pending = []
+    revision = script.get_current_head()
+    while revision != current:
+        if revision is None:
+            raise ValueError(f"Database revision {current} is not in the migration history")
+        migration = script.get_revision(revision)
+        pending.append(migration)
+        revision = migration.down_revision
+    return pending[::-1]
+
+
+def expand_target(script, current: Optional[str]) -> Optional[str]:
+    """Last revision before the first pending contract migration (the current one if none)."""
+    target = current
+    for migration in pending_migrations(script, current):
+        if phase_of(migration) == CONTRACT:
+            break
+        target = migration.revision
+    return target
+
+
+def add_column(table: str, column: sa.Column) -> None:
+    """
+    Add a column the old version can ignore.
+
+    Raises:
+        ValueError: For a NOT NULL column without a server default, which
+            would break the inserts of the old version
+    """
+    if not column.nullable and column.server_default is None:
+        raise ValueError(
+            f"{table}.{column.name} must be nullable or have a server default; "
+            "add NOT NULL in a contract migration"
+        )
+    op.add_column(table, column)
+
+
+def create_index(name: str, table: str, columns: Sequence[str], unique: bool = False) -> None:
+    """Build an index without locking the table against writes."""
+    with op.get_context().autocommit_block():
+        op.create_index(
+            name,
+            table,
+            list(columns),
+            unique=unique,
+            postgresql_concurrently=True,
+            if_not_exists=True,
+        )
+
+
+def backfill(table: str, assignments: str, where: str, batch_size: int = 10000) -> int:
+    """
+    Update rows in batches, each committed on its own so locks stay short.
+
+    Args:
+        table: Table to update
+        assignments: SET clause, like "categories = '{}'"
+        where: Condition matching the rows still to update; it must stop
+            matching a row once the row is updated
+        batch_size: Rows per batch
+
+    Returns:
+        Number of updated rows
+    """
+    query = sa.text(
+        f"UPDATE {table} SET {assignments} WHERE ctid IN "
+        f"(SELECT ctid FROM {table} WHERE {where} LIMIT {int(batch_size)})"
+    )
+    updated = 0
+    with op.get_context().autocommit_block():
+        while True:
+            count = op.get_bind().execute(query).rowcount
+            updated += count
+            if count < batch_size:
+                return updated
+
+
+async def current_revision() -> Optional[str]:
+    """Revision the database is at, None if no migration ran yet."""
+    conn = await asyncpg.connect(settings.get_dsn())
+    try:
+        return await conn.fetchval("SELECT version_num FROM alembic_version")
+    except asyncpg.UndefinedTableError:
+        return None
+    finally:
+        await conn.close()
+
+
+def parse_args():
+    """Parse command line arguments."""
+    parser = argparse.ArgumentParser(description="Run expand or contract schema migrations")
+    parser.add_argument("phase", choices=(EXPAND, CONTRACT, "status"))
+    return parser.parse_args()
+
+
+def main() -> int:
+    args = parse_args()
+    config = Config(str(ALEMBIC_INI))
+    script = ScriptDirectory.from_config(config)
+    # Before alembic runs its own event loop
+    current = asyncio.run(current_revision())
+    pending = pending_migrations(script, current)
+
+    if args.phase == "status":
+        print(f"Current revision: {current or 'none'}")
+        for migration in pending:
+            print(f"{migration.revision}\t{phase_of(migration)}\t{migration.doc}")
+        return 0
+
+    target = expand_target(script, current) if args.phase == EXPAND else "head"
+    if not pending or target == current:
+        waiting = f", {len(pending)} waiting for contract" if pending else ""
+        print(f"Nothing to migrate{waiting}")
+        return 0
+    command.upgrade(config, target)
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
@@ -1,9 +1,33 @@
 """Data models for RSS feeds."""
 
 from dataclasses import dataclass, asdict
+from datetime import datetime, timedelta, timezone
 from typing import List, Optional
 import json
 
+from common.utils.dates import UnparseableDate, parse_feed_date
+
+# Values of RSS <skipDays>, by datetime.weekday()
+DAY_NAMES = ("Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday")
+
+
+@dataclass
+class PodcastEpisode:
+    """iTunes podcast fields of an item."""
+
+    # Length in seconds
+    duration: Optional[int] = None
+    image: Optional[str] = None
+    season: Optional[int] = None
+    episode: Optional[int] = None
+    # full, trailer or bonus
+    episode_type: Optional[str] = None
+    # Episode title without the show name or numbering
+    title: Optional[str] = None
+    explicit: Optional[bool] = None
+    # URL of the audio enclosure
+    audio_url: Optional[str] = None
+
 
 @dataclass
 class RSSItem:
@@ -12,11 +36,30 @@ class RSSItem:
     link: str
     description: str
     pub_date: Optional[str] = None
+    # Headline the feed gives the item, on one line
+    title: Optional[str] = None
     media_urls: List[str] = None
+    # Categories and tags the feed gives the item, in the feed's order
+    categories: List[str] = None
+    # Identifier the feed gives the item; unlike the link it survives edits
+    guid: Optional[str] = None
+    # Whether the GUID is the item's permanent URL
+    guid_is_permalink: bool = False
+    author: Optional[str] = None
+    # URL of the item's comments page
+    comments: Optional[str] = None
+    # Set for podcast episodes
+    podcast: Optional[PodcastEpisode] = None
 
     def __post_init__(self):
         if self.media_urls is None:
             self.media_urls = []
+        if self.categories is None:
+            self.categories = []
+
+    def id(self) -> str:
+        """Stable identifier of the item: its GUID, or its link if it has none."""
+        return self.guid or self.link
 
     def to_dict(self) -> dict:
         """Convert to dictionary."""
@@ -27,6 +70,18 @@ class RSSItem:
         return json.dumps(self.to_dict(), indent=2, default=str)
 
 
+@dataclass
+class ChannelImage:
+    """Logo or avatar of a feed."""
+
+    url: str
+    title: Optional[str] = None
+    # Page the image links to, usually the site
+    link: Optional[str] = None
+    width: Optional[int] = None
+    height: Optional[int] = None
+
+
 @dataclass
 class RSSChannel:
     """Represents RSS feed metadata."""
@@ -34,13 +89,84 @@ class RSSChannel:
     title: str
     link: str
     description: str
+    # Language tag like "ru" or "en-US"
     language: Optional[str] = None
     last_build_date: Optional[str] = None
     items: List[RSSItem] = None
+    # Problems with items that were skipped, the rest of the feed is still parsed
+    warnings: List[str] = None
+    # URL the feed names as its own, and the WebSub hubs it announces updates to
+    self_url: Optional[str] = None
+    hubs: List[str] = None
+    # Minutes the feed may be cached before it's fetched again (RSS <ttl>)
+    ttl: Optional[int] = None
+    # GMT hours (0-23) and days (DAY_NAMES) the feed asks not to be fetched in
+    skip_hours: List[int] = None
+    skip_days: List[str] = None
+    image: Optional[ChannelImage] = None
 
     def __post_init__(self):
         if self.items is None:
             self.items = []
+        if self.warnings is None:
+            self.warnings = []
+        if self.hubs is None:
+            self.hubs = []
+        if self.skip_hours is None:
+            self.skip_hours = []
+        if self.skip_days is None:
+            self.skip_days = []
+
+    def suggested_poll_interval(self, now: datetime, default: timedelta) -> timedelta:
+        """
+        Time to wait before fetching the feed again, as the feed asks.
+
+        Args:
+            now: Current time; naive times are taken as UTC
+            default: Interval to use when the feed asks for nothing, and the
+                shortest one returned
+
+        Returns:
+            The longer of default and the feed's ttl, stretched to the start
+            of the next hour the feed doesn't skip
+        """
+        if now.tzinfo is not None:
+            now = now.astimezone(timezone.utc).replace(tzinfo=None)
+        at = now + max(default, timedelta(minutes=self.ttl or 0))
+        # A week of hours is enough to pass every skip; a feed skipping all of them is ignored
+        for _ in range(7 * 24):
+            if at.hour not in self.skip_hours and DAY_NAMES[at.weekday()] not in self.skip_days:
+                return at - now
+            at = at.replace(minute=0, second=0, microsecond=0) + timedelta(hours=1)
+        return max(default, timedelta(minutes=self.ttl or 0))
+
+    def items_since(
+        self, since: Optional[datetime] = None, limit: Optional[int] = None
+    ) -> List[RSSItem]:
+        """
+        Items published at or after a time, in feed order.
+
+        Args:
+            since: Earliest publication time (naive, like parse_feed_date returns);
+                items without a readable date are kept, they can't be told apart
+                from new ones
+            limit: Items to return at most
+
+        Returns:
+            The matching items
+        """
+        items = []
+        for item in self.items:
+            if limit is not None and len(items) >= limit:
+                break
+            if since and item.pub_date:
+                try:
+                    if parse_feed_date(item.pub_date) < since:
+                        continue
+                except UnparseableDate:
+                    pass
+            items.append(item)
+        return items
 
     def to_dict(self) -> dict:
         """Convert to dictionary."""
@@ -0,0 +1,252 @@
+"""Open Graph preview images for event pages.
+
+Each event gets a 1200x630 card with its title, date and source drawn over
+the poster (the first image of the post). The card is composed as SVG and
+rasterized to PNG by an external renderer, since link preview crawlers
+don't accept SVG:
+
+    OG_IMAGE_RENDER_COMMAND="rsvg-convert -w {width} -h {height} -f png -o {output} {input}"
+
+Rendered images are cached in MEDIA_DIR/og. The file name includes a hash
+of everything drawn on the card, so an edited post gets a new image while
+unchanged posts are rendered once.
+"""
+
+import asyncio
+import base64
+import hashlib
+import logging
+import os
+import shlex
+import tempfile
+from pathlib import Path
+from typing import List, Optional, Tuple
+from xml.sax.saxutils import escape
+
+import requests
+
+from common.db.models import RSSPost
+from common.utils.links import channel_from_link, post_slug
+
+logger = logging.getLogger(__name__)
+
+# {input} (SVG), {output} (PNG), {width} and {height} are substituted per run
+DEFAULT_RENDER_COMMAND = "rsvg-convert -w {width} -h {height} -f png -o {output} {input}"
+
+WIDTH = 1200
+HEIGHT = 630
+
+# Bump to re-render every cached image after changing the layout
+LAYOUT_VERSION = 1
+
+
+class OGImageError(Exception):
+    """Raised when an image can't be rendered."""
+
+
+def wrap_text(text: str, max_chars: int, max_lines: int) -> List[str]:
+    """
+    Wrap text into lines of at most max_chars, ending with '…' if it doesn't fit.
+
+    Args:
+        text: Text to wrap
+        max_chars: Maximum characters per line
+        max_lines: Maximum number of lines
+
+    Returns:
+        List of lines
+    """
+    lines: List[str] = []
+    current = ""
+    for word in text.split():
+        while len(word) > max_chars:
+            if current:
+                lines.append(current)
+                current = ""
+            lines.append(word[: max_chars - 1] + "-")
+            word = word[max_chars - 1 :]
+        candidate = f"{current} {word}" if current else word
+        if len(candidate) > max_chars:
+            lines.append(current)
+            current = word
+        else:
+            current = candidate
+    if current:
+        lines.append(current)
+
+    if len(lines) > max_lines:
+        lines = lines[:max_lines]
+        lines[-1] = lines[-1][: max_chars - 1].rstrip(" ,.;:") + "…"
+    return lines
+
+
+def render_svg(title: str, details: List[str], poster: Optional[Tuple[bytes, str]] = None) -> str:
+    """
+    Compose the card as SVG.
+
+    Args:
+        title: Event title
+        details: Lines under the title (date, place)
+        poster: Optional (image bytes, content type) drawn as the background
+
+    Returns:
+        SVG document
+    """
+    background = '<rect width="100%" height="100%" fill="#1d2a3a"/>'
+    if poster:
+        data, content_type = poster
+        href = f"data:{content_type};base64,{base64.b64encode(data).decode()}"
+        background += (
+            f'\n  <image href="{href}" width="{WIDTH}" height="{HEIGHT}" '
+            f'preserveAspectRatio="xMidYMid slice"/>'
+        )
+
+    title_lines = wrap_text(title, 28, 3)
+    y = HEIGHT - 90 - 62 * len(details) - 72 * (len(title_lines) - 1)
+    text = []
+    for line in title_lines:
+        text.append(
+            f'  <text x="64" y="{y}" font-size="64" font-weight="bold" fill="#fff">'
+            f"{escape(line)}</text>"
+        )
+        y += 72
+    y += 8
+    for line in details:
+        text.append(f'  <text x="64" y="{y}" font-size="40" fill="#dce6f0">{escape(line)}</text>')
+        y += 54
+
+    return (
+        f'<svg xmlns="http://www.w3.org/2000/svg" width="{WIDTH}" height="{HEIGHT}" '
+        f'viewBox="0 0 {WIDTH} {HEIGHT}" font-family="DejaVu Sans, Arial, sans-serif">\n'
+        f"  <defs>\n"
+        f'    <linearGradient id="shade" x1="0" y1="0" x2="0" y2="1">\n'
+        f'      <stop offset="0" stop-color="#000" stop-opacity="0.1"/>\n'
+        f'      <stop offset="1" stop-color="#000" stop-opacity="0.8"/>\n'
+        f"    </linearGradient>\n"
+        f"  </defs>\n"
+        f"  {background}\n"
+        f'  <rect width="100%" height="100%" fill="url(#shade)"/>\n'
+        + "\n".join(text)
+        + "\n</svg>\n"
+    )
+
+
+class OGImageRenderer:
+    """Render and cache Open Graph images."""
+
+    def __init__(
+        self,
+        media_dir: str,
+        render_command: str = DEFAULT_RENDER_COMMAND,
+        timeout: float = 10,
+        max_poster_bytes: int = 5 * 1024 * 1024,
+    ):
+        """
+        Args:
+            media_dir: Media storage directory (images go to <media_dir>/og)
+            render_command: SVG -> PNG command (see DEFAULT_RENDER_COMMAND)
+            timeout: Seconds allowed for downloading the poster and for rendering
+            max_poster_bytes: Posters larger than this are not used
+        """
+        self.cache_dir = Path(media_dir) / "og"
+        self.render_command = render_command
+        self.timeout = timeout
+        self.max_poster_bytes = max_poster_bytes
+
+    def cache_path(self, post: RSSPost, title: str, details: List[str]) -> Path:
+        """Path of the cached image for the post as currently drawn."""
+        images = post.media_urls()
+        key = "\n".join([str(LAYOUT_VERSION), title, *details, images[0] if images else ""])
+        digest = hashlib.sha256(key.encode()).hexdigest()[:12]
+        return self.cache_dir / f"{post_slug(post.link)}-{digest}.png"
+
+    def _download_poster(self, url: str) -> Optional[Tuple[bytes, str]]:
+        try:
+            with requests.get(url, stream=True, timeout=self.timeout) as response:
+                response.raise_for_status()
+                content_type = response.headers.get("Content-Type", "").split(";")[0].strip()
+                if content_type not in ("image/jpeg", "image/png", "image/webp", "image/gif"):
+                    return None
+                data = b""
+                for chunk in response.iter_content(64 * 1024):
+                    data += chunk
+                    if len(data) > self.max_poster_bytes:
+                        return None
+                return data, content_type
+        except requests.RequestException as e:
+            logger.warning(f"Failed to download poster {url}: {e}")
+            return None
+
+    async def _rasterize(self, svg: str, output: Path) -> None:
+        with tempfile.TemporaryDirectory() as tmp:
+            source = Path(tmp) / "card.svg"
+            rendered = Path(tmp) / "card.png"
+            source.write_text(svg, encoding="utf-8")
+            command = shlex.split(
+                self.render_command.format(
+                    input=shlex.quote(str(source)),
+                    output=shlex.quote(str(rendered)),
+                    width=WIDTH,
+                    height=HEIGHT,
+                )
+            )
+            try:
+                process = await asyncio.create_subprocess_exec(
+                    *command, stdout=asyncio.subprocess.DEVNULL, stderr=asyncio.subprocess.PIPE
+                )
+            except OSError as e:
+                raise OGImageError(f"Failed to start image renderer: {e}")
+
+            try:
+                _, stderr = await asyncio.wait_for(process.communicate(), self.timeout)
+            except asyncio.TimeoutError:
+                process.kill()
+                await process.wait()
+                raise OGImageError(f"Rendering timed out after {self.timeout}s")
+
+            if process.returncode != 0 or not rendered.exists():
+                message = stderr.decode("utf-8", errors="replace").strip()[:200]
+                raise OGImageError(f"Renderer exited with status {process.returncode}: {message}")
+
+            # Move into place atomically so concurrent requests never see a partial file
+            output.parent.mkdir(parents=True, exist_ok=True)
+            partial = output.with_suffix(".tmp")
+            partial.write_bytes(rendered.read_bytes())
+            os.replace(partial, output)
+
+    async def render(self, post: RSSPost, title: str, details: List[str]) -> Path:
+        """
+        Get the image for a post, rendering it if it isn't cached yet.
+
+        Args:
+            post: Event post
+            title: Title drawn on the card
+            details: Lines under the title
+
+        Returns:
+            Path of the PNG file
+
+        Raises:
+            OGImageError: If rendering fails
+        """
+        path = self.cache_path(post, title, details)
+        if path.exists():
+            return path
+
+        images = post.media_urls()
+        poster = await asyncio.to_thread(self._download_poster, images[0]) if images else None
+        await self._rasterize(render_svg(title, details, poster), path)
+
+        # Drop images rendered for older versions of the post
+        for old in self.cache_dir.glob(f"{post_slug(post.link)}-{'?' * 12}.png"):
+            if old != path:
+                old.unlink(missing_ok=True)
+
+        logger.info(f"Rendered Open Graph image for {post.link}")
+        return path
+
+
+def card_details(post: RSSPost, date_text: str) -> List[str]:
+    """Lines drawn under the title: the date and the source channel."""
+    channel = channel_from_link(post.link)
+    return [line for line in (date_text, f"@{channel}" if channel else "") if line]
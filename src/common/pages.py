@@ -0,0 +1,514 @@
+"""HTML pages for events.
+
+Renders the event store as a small website: an index with month calendars
+linking to every event, and one page per event with Open Graph tags for
+link previews and schema.org Event JSON-LD for search engines. Pages only
+use relative links, so the output can be served from any static host or
+path; the base URL is only needed for the absolute URLs that Open Graph,
+canonical links and sitemap.xml require.
+"""
+
+import calendar
+import json
+from collections import Counter, defaultdict
+from dataclasses import dataclass
+from datetime import date
+from html import escape
+from typing import Any, Dict, Iterable, List, Optional, Tuple
+from xml.sax.saxutils import escape as xml_escape
+
+from common.admission import Admission
+from common.contacts import contact_href, contact_label
+from common.db.models import RSSPost, Series
+from common.event_dates import DEADLINE_KINDS, Deadline, EventSchedule
+from common.event_format import ATTENDANCE_MODES, LABELS
+from common.event_status import LABELS as STATUS_LABELS, SCHEMA_STATUSES
+from common.series import group_by_series, plural_events
+from common.utils.links import channel_from_link, post_slug
+
+MONTHS = [
+    "Январь",
+    "Февраль",
+    "Март",
+    "Апрель",
+    "Май",
+    "Июнь",
+    "Июль",
+    "Август",
+    "Сентябрь",
+    "Октябрь",
+    "Ноябрь",
+    "Декабрь",
+]
+MONTHS_GENITIVE = [
+    "января",
+    "февраля",
+    "марта",
+    "апреля",
+    "мая",
+    "июня",
+    "июля",
+    "августа",
+    "сентября",
+    "октября",
+    "ноября",
+    "декабря",
+]
+WEEKDAYS = ["Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"]
+
+STYLE = """\
+body { font-family: system-ui, sans-serif; max-width: 760px; margin: 0 auto; padding: 1rem;
+       color: #222; line-height: 1.5; }
+a { color: #2b88d8; }
+.calendar { border-collapse: collapse; margin: 0 0 1.5rem; }
+.calendar th, .calendar td { width: 2.5rem; height: 2rem; text-align: center; }
+.calendar td a { display: block; border-radius: 50%; background: #e3f0fb; text-decoration: none; }
+.day h3 { margin-bottom: 0.25rem; }
+.day ul { list-style: none; padding: 0; margin-top: 0; }
+.meta { color: #666; font-size: 0.9rem; }
+.notice { background: #fff4e0; border-radius: 4px; padding: 0.5rem 0.75rem; }
+.event img { max-width: 100%; height: auto; border-radius: 4px; }
+"""
+
+
+@dataclass
+class SiteSettings:
+    """Site-wide settings."""
+
+    title: str = "Афиша"
+    base_url: str = ""
+    language: str = "ru"
+    # Point og:image at generated cards (og/<slug>.png) instead of the poster
+    og_images: bool = False
+
+    def url(self, path: str) -> str:
+        """Absolute URL of a page, or an empty string without a base URL."""
+        if not self.base_url:
+            return ""
+        return f"{self.base_url.rstrip('/')}/{path}"
+
+
+def truncate(text: str, max_length: int) -> str:
+    """Shorten text to max_length characters on a word boundary."""
+    text = " ".join(text.split())
+    if len(text) <= max_length:
+        return text
+    cut = text.rfind(" ", 0, max_length - 1)
+    return text[: cut if cut > max_length // 2 else max_length - 1].rstrip(" ,.;:") + "…"
+
+
+def event_path(post: RSSPost) -> str:
+    """Path of an event page relative to the site root."""
+    return f"events/{post_slug(post.link)}.html"
+
+
+def event_title(post: RSSPost, max_length: int = 90) -> str:
+    """Title of an event: its generated title, or the first non-empty line of the post."""
+    if post.title:
+        return truncate(post.title, max_length)
+    for line in (post.content or "").splitlines():
+        if line.strip():
+            return truncate(line, max_length)
+    return channel_from_link(post.link) or post.link
+
+
+def og_image_path(post: RSSPost) -> str:
+    """Path of an event's generated Open Graph image relative to the site root."""
+    return f"og/{post_slug(post.link)}.png"
+
+
+def event_description(post: RSSPost, max_length: int = 200) -> str:
+    """Short description of an event for previews (its summary when available)."""
+    return truncate(post.summary or post.content or "", max_length)
+
+
+def format_date(day: date) -> str:
+    """Format a date like '14 октября 2026'."""
+    return f"{day.day} {MONTHS_GENITIVE[day.month - 1]} {day.year}"
+
+
+def event_json_ld(post: RSSPost, site: SiteSettings) -> Dict[str, Any]:
+    """
+    Build schema.org Event structured data for a post.
+
+    Posts carry no venue, so the source channel is used as the organizer. The
+    publication time is the start date when no date was found in the text.
+
+    Args:
+        post: Event post
+        site: Site settings
+
+    Returns:
+        JSON-LD object
+    """
+    data: Dict[str, Any] = {
+        "@context": "https://schema.org",
+        "@type": "Event",
+        "name": event_title(post),
+        "description": event_description(post, 500),
+        "eventStatus": SCHEMA_STATUSES.get(
+            post.event_status or "", "https://schema.org/EventScheduled"
+        ),
+    }
+    if post.event_format in ATTENDANCE_MODES:
+        data["eventAttendanceMode"] = ATTENDANCE_MODES[post.event_format]
+    schedule = EventSchedule.from_post(post)
+    if schedule:
+        first = schedule.sessions[0] if schedule.sessions else None
+        data["startDate"] = (
+            first.starts_at().isoformat() if first and first.start else schedule.start.isoformat()
+        )
+        if schedule.is_multi_day:
+            data["endDate"] = schedule.end.isoformat()
+    elif post.pub_date:
+        data["startDate"] = post.pub_date.isoformat()
+    url = site.url(event_path(post))
+    if url:
+        data["url"] = url
+    images = post.media_urls()
+    if images:
+        data["image"] = images
+    if post.performers:
+        data["performer"] = [{"@type": "Person", "name": name} for name in post.performers]
+    channel = channel_from_link(post.link)
+    if channel:
+        data["organizer"] = {"@type": "Organization", "name": channel, "url": post.link}
+    return data
+
+
+def _json_ld(data: Dict[str, Any]) -> str:
+    """Serialize JSON-LD for a <script> tag, escaping characters that could end it early."""
+    text = json.dumps(data, ensure_ascii=False)
+    return text.replace("<", "\\u003c").replace(">", "\\u003e").replace("&", "\\u0026")
+
+
+def format_schedule(schedule: EventSchedule) -> str:
+    """Format event days like '5–7 марта 2026, 19:00' or '30 марта – 2 апреля 2026'."""
+    start, end = schedule.start, schedule.end
+    if not schedule.is_multi_day:
+        text = format_date(start)
+    elif (start.year, start.month) == (end.year, end.month):
+        text = f"{start.day}–{format_date(end)}"
+    elif start.year == end.year:
+        text = f"{start.day} {MONTHS_GENITIVE[start.month - 1]} – {format_date(end)}"
+    else:
+        text = f"{format_date(start)} – {format_date(end)}"
+
+    # A single start time is shown only when all days share it
+    times = {session.start for session in schedule.sessions}
+    if len(times) == 1 and None not in times:
+        text += f", {times.pop():%H:%M}"
+    return text
+
+
+def deadline_label(deadline: Deadline) -> str:
+    """Format a deadline like 'Регистрация до 20 ноября 2026'."""
+    kind = DEADLINE_KINDS.get(deadline.kind, DEADLINE_KINDS["registration"])
+    return f"{kind.capitalize()} до {format_date(deadline.day)}"
+
+
+def event_when(post: RSSPost) -> str:
+    """Format the event time like '14 октября 2026, 19:30' or '5–7 марта 2026'."""
+    schedule = EventSchedule.from_post(post)
+    if schedule:
+        return format_schedule(schedule)
+    if not post.pub_date:
+        return ""
+    return f"{format_date(post.pub_date.date())}, {post.pub_date:%H:%M}"
+
+
+def _head(
+    title: str,
+    site: SiteSettings,
+    root: str,
+    meta: Iterable[Tuple[str, str]],
+    canonical: str = "",
+    structured_data: Optional[Dict[str, Any]] = None,
+) -> str:
+    """Render the document head with Open Graph meta tags."""
+    tags = "".join(
+        f'\n  <meta property="{escape(name)}" content="{escape(value)}">'
+        for name, value in meta
+        if value
+    )
+    if canonical:
+        tags += f'\n  <link rel="canonical" href="{escape(canonical)}">'
+    if structured_data:
+        tags += f'\n  <script type="application/ld+json">{_json_ld(structured_data)}</script>'
+    return (
+        f'<!DOCTYPE html>\n<html lang="{escape(site.language)}">\n<head>\n'
+        f'  <meta charset="utf-8">\n'
+        f'  <meta name="viewport" content="width=device-width, initial-scale=1">\n'
+        f"  <title>{escape(title)}</title>{tags}\n"
+        f'  <link rel="stylesheet" href="{root}style.css">\n'
+        f"</head>\n"
+    )
+
+
+def render_event_page(
+    post: RSSPost, site: SiteSettings, series: Optional[Series] = None, source_url: str = ""
+) -> str:
+    """
+    Render the page of a single event.
+
+    Args:
+        post: Event post
+        site: Site settings
+        series: Festival or series the event belongs to
+        source_url: Link to the source post (defaults to the post link)
+
+    Returns:
+        HTML document
+    """
+    title = event_title(post)
+    images = post.media_urls()
+    url = site.url(event_path(post))
+    image = images[0] if images else ""
+    if site.og_images and site.base_url:
+        image = site.url(og_image_path(post))
+    meta = [
+        ("og:type", "article"),
+        ("og:site_name", site.title),
+        ("og:title", title),
+        ("og:description", event_description(post)),
+        ("og:url", url),
+        ("og:image", image),
+        ("og:locale", "ru_RU" if site.language == "ru" else site.language),
+    ]
+
+    head = _head(
+        f"{title} — {site.title}",
+        site,
+        "../",
+        meta,
+        canonical=url,
+        structured_data=event_json_ld(post, site),
+    )
+
+    when = event_when(post)
+    channel = channel_from_link(post.link)
+    details = " · ".join(
+        part for part in (when, LABELS.get(post.event_format or "", ""), channel) if part
+    )
+    paragraphs = "".join(
+        f"\n    <p>{escape(paragraph).replace(chr(10), '<br>')}</p>"
+        for paragraph in (post.content or "").split("\n\n")
+        if paragraph.strip()
+    )
+    pictures = "".join(f'\n    <img src="{escape(src)}" alt="" loading="lazy">' for src in images)
+    warnings = Admission.from_post(post).warnings()
+    notice = (
+        f'\n    <p class="notice">⚠️ {escape(", ".join(warnings).capitalize())}</p>'
+        if warnings
+        else ""
+    )
+    deadline = Deadline.from_post(post)
+    if deadline:
+        notice += f'\n    <p class="notice">⏳ {escape(deadline_label(deadline))}</p>'
+    if post.event_status in STATUS_LABELS:
+        notice = f'\n    <p class="notice">❌ {STATUS_LABELS[post.event_status]}</p>' + notice
+    contacts = ", ".join(
+        f'<a href="{escape(contact_href(contact))}">{escape(contact_label(contact))}</a>'
+        for contact in post.contacts or []
+    )
+    if contacts:
+        contacts = f'\n    <p class="meta">Контакты: {contacts}</p>'
+    part_of = (
+        f'\n    <p class="meta">Часть серии «{escape(series.name)}» · '
+        f"{plural_events(series.event_count)}</p>"
+        if series
+        else ""
+    )
+
+    return (
+        f"{head}<body>\n"
+        f'  <p><a href="../index.html">← {escape(site.title)}</a></p>\n'
+        f'  <article class="event">\n'
+        f"    <h1>{escape(title)}</h1>\n"
+        f'    <p class="meta">{escape(details)}</p>{part_of}{notice}{pictures}{paragraphs}'
+        f"{contacts}\n"
+        f'    <p><a href="{escape(source_url or post.link)}">Источник</a></p>\n'
+        f"  </article>\n"
+        f"</body>\n</html>\n"
+    )
+
+
+def render_month(year: int, month: int, days_with_events: Iterable[date]) -> str:
+    """Render a month calendar where days with events link to their section."""
+    active = set(days_with_events)
+    rows = []
+    for week in calendar.Calendar().monthdatescalendar(year, month):
+        cells = []
+        for day in week:
+            if day.month != month:
+                cells.append("<td></td>")
+            elif day in active:
+                cells.append(f'<td><a href="#day-{day.isoformat()}">{day.day}</a></td>')
+            else:
+                cells.append(f"<td>{day.day}</td>")
+        rows.append(f"      <tr>{''.join(cells)}</tr>")
+
+    header = "".join(f"<th>{name}</th>" for name in WEEKDAYS)
+    return (
+        f'  <table class="calendar">\n'
+        f"    <caption>{MONTHS[month - 1]} {year}</caption>\n"
+        f"    <thead><tr>{header}</tr></thead>\n"
+        f"    <tbody>\n" + "\n".join(rows) + "\n    </tbody>\n  </table>\n"
+    )
+
+
+def group_by_day(posts: Iterable[RSSPost]) -> Dict[date, List[RSSPost]]:
+    """Group posts by publication day, in chronological order."""
+    days: Dict[date, List[RSSPost]] = defaultdict(list)
+    for post in sorted((p for p in posts if p.pub_date), key=lambda p: p.pub_date):
+        days[post.pub_date.date()].append(post)
+    return dict(days)
+
+
+def render_index(
+    posts: List[RSSPost], site: SiteSettings, series: Optional[Dict[str, Series]] = None
+) -> str:
+    """
+    Render the calendar page listing all events.
+
+    Events of a series with several events on the page are listed under the
+    series, and each day links to the series instead of repeating them.
+
+    Args:
+        posts: Event posts (posts without a date are skipped)
+        site: Site settings
+        series: Key -> Series of the posts
+
+    Returns:
+        HTML document
+    """
+    days = group_by_day(posts)
+    meta = [
+        ("og:type", "website"),
+        ("og:site_name", site.title),
+        ("og:title", site.title),
+        ("og:url", site.url("index.html")),
+    ]
+
+    months = sorted({(day.year, day.month) for day in days})
+    calendars = "".join(render_month(year, month, days) for year, month in months)
+
+    dated = [post for day_posts in days.values() for post in day_posts]
+    groups, _ = group_by_series(dated, series or {})
+    anchors = {group.key: f"series-{number}" for number, (group, _) in enumerate(groups, 1)}
+    series_items = []
+    for group, group_posts in groups:
+        events = "".join(
+            f'\n          <li><span class="meta">{escape(event_when(post))}</span> '
+            f'<a href="{event_path(post)}">{escape(event_title(post))}</a></li>'
+            for post in group_posts
+        )
+        series_items.append(
+            f'\n      <li id="{anchors[group.key]}"><b>{escape(group.name)}</b>: '
+            f"{plural_events(len(group_posts))}\n        <ul>{events}\n        </ul>\n      </li>"
+        )
+    series_section = (
+        f'  <section class="series">\n'
+        f"    <h2>Фестивали и циклы</h2>\n"
+        f"    <ul>{''.join(series_items)}\n    </ul>\n"
+        f"  </section>\n"
+        if groups
+        else ""
+    )
+
+    sections = []
+    for day, day_posts in days.items():
+        items = ""
+        counts = Counter(post.series_key for post in day_posts if post.series_key in anchors)
+        for post in day_posts:
+            key = post.series_key
+            if key not in anchors:
+                items += (
+                    f'\n      <li><span class="meta">{post.pub_date:%H:%M}</span> '
+                    f'<a href="{event_path(post)}">{escape(event_title(post))}</a></li>'
+                )
+            elif key in counts:
+                # One line per series and day, linking to the series list
+                items += (
+                    f'\n      <li><a href="#{anchors[key]}">{escape(series[key].name)}</a>: '
+                    f"{plural_events(counts.pop(key))}</li>"
+                )
+        sections.append(
+            f'  <section class="day" id="day-{day.isoformat()}">\n'
+            f"    <h3>{format_date(day)}</h3>\n"
+            f"    <ul>{items}\n    </ul>\n"
+            f"  </section>\n"
+        )
+
+    empty = "" if days else "  <p>Событий пока нет.</p>\n"
+    return (
+        f"{_head(site.title, site, '', meta)}<body>\n"
+        f"  <h1>{escape(site.title)}</h1>\n"
+        f"{calendars}{series_section}{''.join(sections)}{empty}"
+        f"</body>\n</html>\n"
+    )
+
+
+def render_sitemap(posts: List[RSSPost], site: SiteSettings) -> str:
+    """
+    Render sitemap.xml for the index and all event pages.
+
+    Args:
+        posts: Event posts
+        site: Site settings (the sitemap protocol requires absolute URLs)
+
+    Returns:
+        XML document
+    """
+    modified = [post.updated_at or post.pub_date for post in posts]
+    entries = [(site.url(""), max(filter(None, modified), default=None))]
+    entries += zip((site.url(event_path(post)) for post in posts), modified)
+
+    urls = []
+    for loc, modified in entries:
+        lastmod = f"<lastmod>{modified:%Y-%m-%d}</lastmod>" if modified else ""
+        urls.append(f"  <url><loc>{xml_escape(loc)}</loc>{lastmod}</url>")
+    return (
+        '<?xml version="1.0" encoding="UTF-8"?>\n'
+        '<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">\n'
+        + "\n".join(urls)
+        + "\n</urlset>\n"
+    )
+
+
+def render_robots(site: SiteSettings) -> str:
+    """Render robots.txt pointing crawlers at the sitemap."""
+    robots = "User-agent: *\nAllow: /\n"
+    if site.base_url:
+        robots += f"Sitemap: {site.url('sitemap.xml')}\n"
+    return robots
+
+
+def build_site(
+    posts: List[RSSPost], site: SiteSettings, series: Optional[Dict[str, Series]] = None
+) -> Dict[str, str]:
+    """
+    Render every page of the site.
+
+    sitemap.xml is only written when a base URL is set.
+
+    Args:
+        posts: Event posts
+        site: Site settings
+        series: Key -> Series of the posts
+
+    Returns:
+        Dict of relative path -> file contents
+    """
+    files = {
+        "index.html": render_index(posts, site, series),
+        "style.css": STYLE,
+        "robots.txt": render_robots(site),
+    }
+    if site.base_url:
+        files["sitemap.xml"] = render_sitemap(posts, site)
+    for post in posts:
+        files[event_path(post)] = render_event_page(
+            post, site, (series or {}).get(post.series_key or "")
+        )
+    return files
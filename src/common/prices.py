@@ -0,0 +1,226 @@
+"""Ticket price extraction.
+
+Announcements state prices in many ways: "500 ₽", "1 500 руб.", "1.5к",
+"от 700 до 1200 р.", "полторы тысячи рублей", "вход свободный". Number
+words are first rewritten into digits, then amounts are taken where a
+currency or a price keyword ("цена", "билеты", "вход") makes it clear they
+are prices and not dates, times or age limits.
+"""
+
+import re
+from dataclasses import dataclass
+from decimal import Decimal
+from typing import List, Optional, Tuple
+
+DEFAULT_CURRENCY = "RUB"
+
+UNITS = {
+    "ноль": 0,
+    "один": 1,
+    "одна": 1,
+    "два": 2,
+    "две": 2,
+    "три": 3,
+    "четыре": 4,
+    "пять": 5,
+    "шесть": 6,
+    "семь": 7,
+    "восемь": 8,
+    "девять": 9,
+    "десять": 10,
+    "одиннадцать": 11,
+    "двенадцать": 12,
+    "тринадцать": 13,
+    "четырнадцать": 14,
+    "пятнадцать": 15,
+    "шестнадцать": 16,
+    "семнадцать": 17,
+    "восемнадцать": 18,
+    "девятнадцать": 19,
+    "двадцать": 20,
+    "тридцать": 30,
+    "сорок": 40,
+    "пятьдесят": 50,
+    "шестьдесят": 60,
+    "семьдесят": 70,
+    "восемьдесят": 80,
+    "девяносто": 90,
+    "сто": 100,
+    "двести": 200,
+    "триста": 300,
+    "четыреста": 400,
+    "пятьсот": 500,
+    "шестьсот": 600,
+    "семьсот": 700,
+    "восемьсот": 800,
+    "девятьсот": 900,
+    "полтора": Decimal("1.5"),
+    "полторы": Decimal("1.5"),
+}
+THOUSANDS = {"тысяча", "тысячи", "тысяч", "тыщ", "тыщи"}
+
+NUMBER_WORDS_REGEX = re.compile(
+    r"(?<!\w)(?:(?:"
+    + "|".join(sorted(list(UNITS) + list(THOUSANDS), key=len, reverse=True))
+    + r"|с половиной)(?!\w)\s*)+",
+    re.IGNORECASE,
+)
+
+CURRENCIES = [
+    ("RUB", r"₽|руб\w*\.?|р\.|rub\b"),
+    ("USD", r"\$|usd\b|долл\w*"),
+    ("EUR", r"€|eur\b|евро\b"),
+]
+CURRENCY = "|".join(f"(?P<{code}>{pattern})" for code, pattern in CURRENCIES)
+
+# Thousands may be separated by a space or a no-break space: '1 500'
+NUMBER = r"\d{1,3}(?:[ \u00a0]\d{3})+(?!\d)|\d+(?:[.,]\d+)?"
+MULTIPLIER = r"[кk](?!\w)|\s?тыс\.?(?!\w)|\s?тысяч\w*"
+
+
+def amount_pattern(name: str) -> str:
+    """Pattern of an amount with an optional thousands multiplier, in named groups."""
+    return rf"(?P<{name}>{NUMBER})(?P<{name}_k>{MULTIPLIER})?"
+
+
+# What follows a number that is not a price: times, age limits, dates and counts
+NOT_PRICE = (
+    r"(?![:.,]?\d|\s?\+|\s?(?:янв|фев|мар|апр|ма[йя]|июн|июл|авг|сен|окт|ноя|дек|лет|год"
+    r"|чел|мест|мин|час|ч\b|шт|%))"
+)
+
+RANGE_REGEX = re.compile(
+    rf"(?:от\s+)?{amount_pattern('low')}\s*(?:[-–—]|до)\s*"
+    rf"{amount_pattern('high')}\s*(?:{CURRENCY})",
+    re.IGNORECASE,
+)
+SUFFIX_REGEX = re.compile(rf"{amount_pattern('amount')}\s*(?:{CURRENCY})", re.IGNORECASE)
+PREFIX_REGEX = re.compile(
+    rf"(?:(?P<USD>\$)|(?P<EUR>€)|(?P<RUB>₽))\s?{amount_pattern('amount')}", re.IGNORECASE
+)
+KEYWORD_REGEX = re.compile(
+    r"(?:цена|стоимость|билеты?|вход|взнос|донат)\s*(?:[:—–-]\s*)?(?:от\s+)?"
+    rf"{amount_pattern('amount')}(?!\w){NOT_PRICE}",
+    re.IGNORECASE,
+)
+FREE_REGEX = re.compile(
+    r"(?<!\w)(?:бесплатн\w*|вход\s+(?:свободный|free)|free\s+entry|свободный\s+вход)",
+    re.IGNORECASE,
+)
+
+
+@dataclass
+class PriceRange:
+    """Lowest and highest price of an event in one currency."""
+
+    min: Decimal
+    max: Decimal
+    currency: str = DEFAULT_CURRENCY
+
+
+def _words_to_number(words: str) -> Optional[Decimal]:
+    """Convert Russian number words like 'две с половиной тысячи' into a number."""
+    total = Decimal(0)
+    current = Decimal(0)
+    seen = False
+    for word in re.findall(r"с половиной|\w+", words.lower()):
+        if word in THOUSANDS:
+            total += (current or 1) * 1000
+            current = Decimal(0)
+            seen = True
+        elif word == "с половиной":
+            current += Decimal("0.5")
+        elif word in UNITS:
+            current += Decimal(UNITS[word])
+            seen = True
+    if not seen:
+        return None
+    return total + current
+
+
+def normalize_numbers(text: str) -> str:
+    """Rewrite number words into digits, e.g. 'полторы тысячи рублей' -> '1500 рублей'."""
+
+    def replace(match: re.Match) -> str:
+        value = _words_to_number(match.group(0))
+        if value is None:
+            return match.group(0)
+        trailing = match.group(0)[len(match.group(0).rstrip()) :]
+        return f"{value.normalize():f}{trailing}"
+
+    return NUMBER_WORDS_REGEX.sub(replace, text)
+
+
+def parse_amount(number: str, multiplier: Optional[str] = None) -> Decimal:
+    """Parse '1 500', '1,5' or '1.5' with an optional thousands multiplier ('к', 'тыс')."""
+    value = Decimal(re.sub(r"[ \u00a0]", "", number).replace(",", "."))
+    if multiplier:
+        value *= 1000
+    return value
+
+
+def _currency(match: re.Match) -> str:
+    for code, _ in CURRENCIES:
+        if match.groupdict().get(code):
+            return code
+    return DEFAULT_CURRENCY
+
+
+def extract_prices(content: str) -> List[Tuple[Decimal, str]]:
+    """
+    Find ticket prices in a post.
+
+    Args:
+        content: Post text
+
+    Returns:
+        (amount, currency) pairs in order of appearance; free entry is a zero amount
+    """
+    text = normalize_numbers(content or "")
+    found: List[Tuple[int, Decimal, str]] = []
+    taken: List[Tuple[int, int]] = []
+
+    def overlaps(match: re.Match) -> bool:
+        return any(match.start() < end and start < match.end() for start, end in taken)
+
+    for match in RANGE_REGEX.finditer(text):
+        currency = _currency(match)
+        found.append((match.start(), parse_amount(match["low"], match["low_k"]), currency))
+        found.append((match.start(), parse_amount(match["high"], match["high_k"]), currency))
+        taken.append(match.span())
+
+    for regex in (SUFFIX_REGEX, PREFIX_REGEX, KEYWORD_REGEX):
+        for match in regex.finditer(text):
+            if overlaps(match):
+                continue
+            amount = parse_amount(match["amount"], match["amount_k"])
+            found.append((match.start(), amount, _currency(match)))
+            taken.append(match.span())
+
+    for match in FREE_REGEX.finditer(text):
+        found.append((match.start(), Decimal(0), DEFAULT_CURRENCY))
+
+    return [(amount, currency) for _, amount, currency in sorted(found, key=lambda f: f[0])]
+
+
+def price_range(content: str) -> Optional[PriceRange]:
+    """
+    Get the price range of an event.
+
+    Prices in other currencies than the most frequent one are ignored, since
+    they are usually a conversion of the same ticket.
+
+    Args:
+        content: Post text
+
+    Returns:
+        PriceRange, or None if the post states no price
+    """
+    prices = extract_prices(content)
+    if not prices:
+        return None
+
+    paid = [currency for amount, currency in prices if amount > 0]
+    currency = max(set(paid), key=paid.count) if paid else DEFAULT_CURRENCY
+    amounts = [amount for amount, code in prices if code == currency or amount == 0]
+    return PriceRange(min=min(amounts), max=max(amounts), currency=currency)
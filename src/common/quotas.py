@@ -0,0 +1,253 @@
+"""Quotas of external APIs.
+
+OpenAI, Discord, Mastodon and most other APIs report what's left of their
+rate limits in response headers (x-ratelimit-remaining-requests,
+X-RateLimit-Remaining, ...), and Telegram asks a bot that floods it to
+retry after a while. The tracker keeps the latest numbers of every API
+the process talks to, so that:
+
+- a warning is logged when a quota drops below QUOTA_WARN_RATIO of its limit;
+- publishers pace their sends while a quota is low and wait for the reset
+  once it's used up, instead of failing (see throttle);
+- the pipeline saves the numbers after each run, for GET /quotas, and
+  alerts operators about the low ones.
+"""
+
+import logging
+import os
+import re
+from dataclasses import dataclass
+from datetime import datetime, timedelta
+from typing import Dict, List, Mapping, Optional, Tuple, Union
+
+from common import clock
+from common.alerts import Alert, AlertManager
+from common.db.models import Quota
+from common.db.repository import QuotaRepository
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class QuotaSettings:
+    """Quota tracking configuration."""
+
+    # A quota is low below this share of its limit
+    warn_ratio: float = float(os.getenv("QUOTA_WARN_RATIO", "0.1"))
+    # Longest a publisher waits for a quota; longer waits fail the send
+    max_wait_seconds: float = float(os.getenv("QUOTA_MAX_WAIT_SECONDS", "60"))
+
+
+quota_settings = QuotaSettings()
+
+HEADER_PATTERN = re.compile(r"x-ratelimit-(limit|remaining|reset)(?:-([a-z0-9-]+))?$")
+DURATION_PATTERN = re.compile(r"(?:\d+(?:\.\d+)?(?:ms|h|m|s))+")
+DURATION_PART = re.compile(r"(\d+(?:\.\d+)?)(ms|h|m|s)")
+UNIT_SECONDS = {"ms": 0.001, "s": 1, "m": 60, "h": 3600}
+
+
+class QuotaExhausted(Exception):
+    """Raised when an API's quota won't reset soon enough to wait for it."""
+
+    def __init__(self, api: str, seconds: float):
+        super().__init__(f"The {api} quota is used up for {seconds:.0f} more seconds")
+        self.api = api
+        # Like Telegram's RetryAfter, so a failed publish is retried after the reset
+        self.retry_after = seconds
+
+
+def parse_reset(value: str, now: datetime) -> Optional[datetime]:
+    """
+    Parse when a quota resets.
+
+    Accepts durations as OpenAI sends them ('6m0s', '20ms'), seconds from
+    now, Unix timestamps and ISO 8601 timestamps.
+    """
+    value = value.strip()
+    try:
+        number = float(value)
+    except ValueError:
+        number = None
+    if number is not None:
+        # Too large to be a number of seconds from now
+        if number > 10**9:
+            return datetime.fromtimestamp(number)
+        return now + timedelta(seconds=number)
+
+    if DURATION_PATTERN.fullmatch(value):
+        seconds = sum(float(n) * UNIT_SECONDS[unit] for n, unit in DURATION_PART.findall(value))
+        return now + timedelta(seconds=seconds)
+
+    try:
+        moment = datetime.fromisoformat(value.replace("Z", "+00:00"))
+    except ValueError:
+        return None
+    # Naive local time, like the clock
+    return moment.astimezone().replace(tzinfo=None) if moment.tzinfo else moment
+
+
+def parse_headers(api: str, headers: Mapping[str, str], now: datetime) -> List[Quota]:
+    """
+    Read the rate limit headers of a response.
+
+    Args:
+        api: Name of the API
+        headers: Response headers
+        now: Time of the response
+
+    Returns:
+        Quotas reported in the headers, one per name (requests, tokens, ...)
+    """
+    quotas: Dict[str, Quota] = {}
+    for header, value in headers.items():
+        match = HEADER_PATTERN.match(header.lower())
+        if not match:
+            continue
+        field, name = match.group(1), match.group(2) or "requests"
+        if field == "reset" and name == "after":
+            # Discord's seconds until the reset of its main limit
+            name = "requests"
+        quota = quotas.setdefault(name, Quota(api, name, updated_at=now))
+        if field == "reset":
+            quota.reset_at = parse_reset(value, now) or quota.reset_at
+            continue
+        try:
+            setattr(quota, field, int(float(value)))
+        except ValueError:
+            continue
+    return [q for q in quotas.values() if q.limit is not None or q.remaining is not None]
+
+
+def is_low(quota: Quota, now: datetime, ratio: Optional[float] = None) -> bool:
+    """Check whether a quota is used up or below the warning share of its limit."""
+    ratio = quota_settings.warn_ratio if ratio is None else ratio
+    if quota.remaining is None or (quota.reset_at is not None and quota.reset_at <= now):
+        return False
+    if quota.remaining <= 0:
+        return True
+    return bool(quota.limit) and quota.remaining / quota.limit <= ratio
+
+
+class QuotaTracker:
+    """Latest quotas of the APIs one process talks to."""
+
+    def __init__(self):
+        self.quotas: Dict[Tuple[str, str], Quota] = {}
+
+    def _update(self, quota: Quota) -> None:
+        key = (quota.api, quota.name)
+        previous = self.quotas.get(key)
+        self.quotas[key] = quota
+        if is_low(quota, quota.updated_at) and not (
+            previous and is_low(previous, quota.updated_at)
+        ):
+            logger.warning(
+                f"The {quota.api} {quota.name} quota is running out: "
+                f"{quota.remaining} of {quota.limit or '?'} left until {quota.reset_at}"
+            )
+
+    def observe(self, api: str, headers: Mapping[str, str]) -> None:
+        """Record the rate limit headers of a response from an API."""
+        now = clock.now()
+        for quota in parse_headers(api, headers, now):
+            self._update(quota)
+        retry_after = {k.lower(): v for k, v in headers.items()}.get("retry-after")
+        if retry_after:
+            reset_at = parse_reset(retry_after, now)
+            if reset_at:
+                self.block(api, reset_at - now)
+
+    def block(self, api: str, retry_after: Union[float, timedelta]) -> None:
+        """Record that an API refuses calls for a while (HTTP 429, Telegram flood control)."""
+        if not isinstance(retry_after, timedelta):
+            retry_after = timedelta(seconds=retry_after)
+        now = clock.now()
+        previous = self.quotas.get((api, "requests"))
+        limit = previous.limit if previous else None
+        self._update(Quota(api, "requests", limit, 0, now + retry_after, now))
+
+    def get(self, api: str) -> List[Quota]:
+        """Quotas of an API."""
+        return [quota for (quota_api, _), quota in sorted(self.quotas.items()) if quota_api == api]
+
+    def all(self) -> List[Quota]:
+        """Quotas of all APIs, by API and name."""
+        return [self.quotas[key] for key in sorted(self.quotas)]
+
+    def wait_seconds(self, api: str) -> float:
+        """
+        How long to wait before calling an API.
+
+        Until the reset if a quota is used up; while one is low, the time
+        left is spread over the remaining calls.
+        """
+        now = clock.now()
+        wait = 0.0
+        for quota in self.get(api):
+            if not is_low(quota, now) or quota.reset_at is None:
+                continue
+            until_reset = (quota.reset_at - now).total_seconds()
+            wait = max(wait, until_reset / (quota.remaining + 1))
+        return wait
+
+    def reset(self) -> None:
+        """Forget all quotas."""
+        self.quotas.clear()
+
+
+tracker = QuotaTracker()
+
+
+async def throttle(api: str) -> None:
+    """
+    Wait until an API may be called, as its quotas allow.
+
+    Raises:
+        QuotaExhausted: If that takes longer than QUOTA_MAX_WAIT_SECONDS
+    """
+    seconds = tracker.wait_seconds(api)
+    if seconds > quota_settings.max_wait_seconds:
+        raise QuotaExhausted(api, seconds)
+    if seconds > 0:
+        logger.info(f"Waiting {seconds:.1f}s for the {api} quota")
+        await clock.sleep(seconds)
+
+
+def tracking_http_client(api: str):
+    """HTTP client for the OpenAI SDK that records the quotas reported by its responses."""
+    from openai import DefaultAsyncHttpxClient
+
+    async def observe(response) -> None:
+        tracker.observe(api, response.headers)
+
+    return DefaultAsyncHttpxClient(event_hooks={"response": [observe]})
+
+
+async def save_quotas(alerts: Optional[AlertManager] = None) -> List[Quota]:
+    """
+    Store the tracked quotas and alert operators about the low ones.
+
+    Args:
+        alerts: AlertManager to notify, if any
+
+    Returns:
+        Quotas that are low
+    """
+    now = clock.now()
+    low = []
+    for quota in tracker.all():
+        await QuotaRepository.upsert(quota)
+        key = f"quota_low:{quota.api}:{quota.name}"
+        if is_low(quota, now):
+            low.append(quota)
+            if alerts:
+                message = (
+                    f"Quota: {quota.remaining} of {quota.limit or '?'} {quota.api} "
+                    f"{quota.name} left until {quota.reset_at:%Y-%m-%d %H:%M}"
+                    if quota.reset_at
+                    else f"Quota: {quota.remaining} {quota.api} {quota.name} left"
+                )
+                await alerts.notify(Alert(key, message, severity="warning"))
+        elif alerts:
+            alerts.resolve(key)
+    return low
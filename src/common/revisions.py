@@ -0,0 +1,131 @@
+"""Edits of stored posts.
+
+Channels edit their announcements after posting them (a new date, a venue,
+a typo), some of them dozens of times. The stored post keeps the text it was
+saved with (revision 0); every edit the reader sees is stored as a diff
+against the previous revision, so a frequently edited channel costs a few
+bytes per edit instead of a full copy.
+
+A delta is a JSON list of operations applied to the previous text from its
+start: a positive number keeps that many characters, a negative one drops
+them and a string inserts itself.
+"""
+
+import hashlib
+import json
+import re
+from difflib import SequenceMatcher
+from typing import List, Optional, Union
+
+from common.db.models import PostRevision, RSSPost
+from common.db.repository import PostRevisionRepository
+
+# Words and the whitespace between them; diffing them is much cheaper than characters
+TOKEN = re.compile(r"\s+|\S+")
+
+Operation = Union[int, str]
+
+
+def content_hash(text: str) -> str:
+    """SHA-256 of a revision's text."""
+    return hashlib.sha256(text.encode()).hexdigest()
+
+
+def diff(old: str, new: str) -> str:
+    """Delta that turns one text into another."""
+    old_tokens, new_tokens = TOKEN.findall(old), TOKEN.findall(new)
+    matcher = SequenceMatcher(None, old_tokens, new_tokens, autojunk=False)
+    operations: List[Operation] = []
+    for tag, i1, i2, j1, j2 in matcher.get_opcodes():
+        removed = sum(len(token) for token in old_tokens[i1:i2])
+        if tag == "equal":
+            operations.append(removed)
+            continue
+        if removed:
+            operations.append(-removed)
+        if j2 > j1:
+            operations.append("".join(new_tokens[j1:j2]))
+    # Whatever is left of the old text is kept, so trailing keeps aren't stored
+    while operations and isinstance(operations[-1], int) and operations[-1] > 0:
+        operations.pop()
+    return json.dumps(operations, ensure_ascii=False, separators=(",", ":"))
+
+
+def patch(old: str, delta: str) -> str:
+    """
+    Apply a delta to the text it was computed from.
+
+    Raises:
+        ValueError: If the delta is malformed or doesn't fit the text
+    """
+    operations = json.loads(delta)
+    if not isinstance(operations, list):
+        raise ValueError("Delta must be a list of operations")
+    parts = []
+    position = 0
+    for operation in operations:
+        if isinstance(operation, str):
+            parts.append(operation)
+        elif isinstance(operation, int) and operation > 0:
+            if position + operation > len(old):
+                raise ValueError("Delta keeps more than the text has")
+            parts.append(old[position : position + operation])
+            position += operation
+        elif isinstance(operation, int) and operation < 0:
+            position -= operation
+            if position > len(old):
+                raise ValueError("Delta drops more than the text has")
+        else:
+            raise ValueError(f"Invalid delta operation: {operation!r}")
+    return "".join(parts) + old[position:]
+
+
+def rebuild(post: RSSPost, revisions: List[PostRevision], number: Optional[int] = None) -> str:
+    """
+    Text of a post at one of its revisions.
+
+    Args:
+        post: The stored post (revision 0)
+        revisions: Its revisions, oldest first
+        number: Revision to rebuild; the latest if None
+
+    Raises:
+        ValueError: If the post has no such revision
+    """
+    latest = revisions[-1].revision if revisions else 0
+    number = latest if number is None else number
+    if not 0 <= number <= latest:
+        raise ValueError(f"Post has no revision {number}")
+    text = post.content
+    for revision in revisions:
+        if revision.revision > number:
+            break
+        text = patch(text, revision.delta)
+    return text
+
+
+async def record_edit(post: RSSPost, content: str) -> Optional[int]:
+    """
+    Store a post's edited text as a new revision, unless it's the latest one already.
+
+    Args:
+        post: The stored post
+        content: Text the source has now
+
+    Returns:
+        Number of the new revision, or None if nothing changed
+    """
+    revisions = await PostRevisionRepository.get_by_link(post.link)
+    latest_hash = revisions[-1].content_hash if revisions else content_hash(post.content)
+    new_hash = content_hash(content)
+    if new_hash == latest_hash:
+        return None
+    number = len(revisions) + 1
+    revision = PostRevision(
+        link=post.link,
+        revision=number,
+        delta=diff(rebuild(post, revisions), content),
+        content_hash=new_hash,
+    )
+    # A concurrent run stored the same edit first
+    return number if await PostRevisionRepository.add(revision) else None
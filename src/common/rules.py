@@ -0,0 +1,614 @@
+"""Expression language for pipeline rules.
+
+Rules are written in a subset of CEL (Common Expression Language), e.g.:
+
+    post.channel == "mediarzn" && post.content.contains("концерт")
+
+Supported syntax:
+
+- literals: "strings", 'strings', 42, 1.5, true, false, null, [lists]
+- variables and fields: post.channel, post.tags[0]
+- operators: ! - * / % + - < <= > >= == != in && || ?:
+- methods: s.contains(x), s.startsWith(x), s.endsWith(x), s.matches(regex),
+  s.lowerAscii(), x.size()
+- functions: size(x), has(post.field)
+
+Expressions are compiled once when configuration is loaded. Syntax errors,
+unknown variables/fields and unknown functions are reported at that point
+with the offending position, so a broken rule fails the deploy instead of
+silently misrouting posts at runtime.
+"""
+
+import json
+import logging
+import operator
+import os
+import re
+import string
+from dataclasses import dataclass, field
+from typing import Any, Callable, Dict, List, Optional, Set
+
+logger = logging.getLogger(__name__)
+
+# Fields available on the `post` variable in pipeline rules
+POST_FIELDS = {
+    "channel",
+    "link",
+    "content",
+    "pub_date",
+    "media_urls",
+    "tags",
+    "format",
+    "categories",
+}
+DEFAULT_SCHEMA: Dict[str, Set[str]] = {"post": POST_FIELDS}
+
+FILTER_ACTIONS = ("drop", "tag")
+
+TOKEN_PATTERN = re.compile(
+    r"""
+    (?P<ws>\s+)
+  | (?P<number>\d+\.\d+|\d+)
+  | (?P<string>"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')
+  | (?P<name>[A-Za-z_][A-Za-z0-9_]*)
+  | (?P<op>&&|\|\||==|!=|<=|>=|[-+*/%<>!?:.,()\[\]])
+    """,
+    re.VERBOSE,
+)
+
+BINARY_PRECEDENCE = {
+    "||": 1,
+    "&&": 2,
+    "==": 3,
+    "!=": 3,
+    "<": 3,
+    "<=": 3,
+    ">": 3,
+    ">=": 3,
+    "in": 3,
+    "+": 4,
+    "-": 4,
+    "*": 5,
+    "/": 5,
+    "%": 5,
+}
+
+BINARY_OPERATORS: Dict[str, Callable[[Any, Any], Any]] = {
+    "==": operator.eq,
+    "!=": operator.ne,
+    "<": operator.lt,
+    "<=": operator.le,
+    ">": operator.gt,
+    ">=": operator.ge,
+    "in": lambda a, b: a in b,
+    "+": operator.add,
+    "-": operator.sub,
+    "*": operator.mul,
+    "/": lambda a, b: a // b if isinstance(a, int) and isinstance(b, int) else a / b,
+    "%": operator.mod,
+}
+
+# CEL's lowerAscii() leaves letters outside A-Z as they are
+ASCII_LOWER = str.maketrans(string.ascii_uppercase, string.ascii_lowercase)
+
+METHODS: Dict[str, Callable[..., Any]] = {
+    "contains": lambda s, x: x in s,
+    "startsWith": lambda s, x: s.startswith(x),
+    "endsWith": lambda s, x: s.endswith(x),
+    "matches": lambda s, pattern: re.search(pattern, s) is not None,
+    "lowerAscii": lambda s: s.translate(ASCII_LOWER),
+    "size": lambda s: len(s),
+}
+
+METHOD_ARITY = {
+    "contains": 1,
+    "startsWith": 1,
+    "endsWith": 1,
+    "matches": 1,
+    "lowerAscii": 0,
+    "size": 0,
+}
+
+ESCAPES = {"n": "\n", "t": "\t", "r": "\r", "\\": "\\", '"': '"', "'": "'"}
+
+Evaluator = Callable[[Dict[str, Any]], Any]
+
+
+class RuleError(ValueError):
+    """Raised when an expression is invalid or fails to evaluate."""
+
+
+@dataclass
+class Token:
+    kind: str
+    value: str
+    position: int
+
+
+def tokenize(source: str) -> List[Token]:
+    """Split an expression into tokens."""
+    tokens = []
+    position = 0
+    while position < len(source):
+        match = TOKEN_PATTERN.match(source, position)
+        if not match:
+            raise RuleError(f"Unexpected character {source[position]!r} at {position}")
+        kind = match.lastgroup
+        if kind != "ws":
+            tokens.append(Token(kind, match.group(), position))
+        position = match.end()
+    tokens.append(Token("end", "", len(source)))
+    return tokens
+
+
+def unquote(literal: str) -> str:
+    """Decode a quoted string literal."""
+    body = literal[1:-1]
+    result = []
+    i = 0
+    while i < len(body):
+        char = body[i]
+        if char == "\\" and i + 1 < len(body):
+            i += 1
+            result.append(ESCAPES.get(body[i], body[i]))
+        else:
+            result.append(char)
+        i += 1
+    return "".join(result)
+
+
+class Parser:
+    """Recursive descent parser compiling tokens into evaluator closures."""
+
+    def __init__(self, source: str, schema: Dict[str, Set[str]]):
+        self.source = source
+        self.schema = schema
+        self.tokens = tokenize(source)
+        self.index = 0
+
+    @property
+    def current(self) -> Token:
+        return self.tokens[self.index]
+
+    def error(self, message: str, token: Optional[Token] = None) -> RuleError:
+        token = token or self.current
+        return RuleError(f"{message} at position {token.position} in: {self.source}")
+
+    def advance(self) -> Token:
+        token = self.current
+        self.index += 1
+        return token
+
+    def accept(self, value: str) -> bool:
+        if self.current.kind in ("op", "name") and self.current.value == value:
+            self.index += 1
+            return True
+        return False
+
+    def expect(self, value: str) -> None:
+        if not self.accept(value):
+            found = self.current.value or "end of expression"
+            raise self.error(f"Expected '{value}', found '{found}'")
+
+    def parse(self) -> Evaluator:
+        evaluator = self.parse_ternary()
+        if self.current.kind != "end":
+            raise self.error(f"Unexpected '{self.current.value}'")
+        return evaluator
+
+    def parse_ternary(self) -> Evaluator:
+        condition = self.parse_binary(1)
+        if not self.accept("?"):
+            return condition
+        if_true = self.parse_ternary()
+        self.expect(":")
+        if_false = self.parse_ternary()
+        return lambda env: if_true(env) if condition(env) else if_false(env)
+
+    def parse_binary(self, min_precedence: int) -> Evaluator:
+        left = self.parse_unary()
+        while True:
+            op = self.current.value
+            precedence = BINARY_PRECEDENCE.get(op)
+            if self.current.kind not in ("op", "name") or not precedence:
+                return left
+            if precedence < min_precedence:
+                return left
+            self.advance()
+            right = self.parse_binary(precedence + 1)
+            left = self.make_binary(op, left, right)
+
+    @staticmethod
+    def make_binary(op: str, left: Evaluator, right: Evaluator) -> Evaluator:
+        if op == "&&":
+            return lambda env: bool(left(env)) and bool(right(env))
+        if op == "||":
+            return lambda env: bool(left(env)) or bool(right(env))
+        func = BINARY_OPERATORS[op]
+        return lambda env: func(left(env), right(env))
+
+    def parse_unary(self) -> Evaluator:
+        if self.accept("!"):
+            operand = self.parse_unary()
+            return lambda env: not operand(env)
+        if self.accept("-"):
+            operand = self.parse_unary()
+            return lambda env: -operand(env)
+        return self.parse_postfix()
+
+    def parse_postfix(self) -> Evaluator:
+        token = self.current
+        if token.kind == "name" and token.value in self.schema:
+            self.advance()
+            return self.parse_member_chain(token.value)
+
+        evaluator = self.parse_primary()
+        while True:
+            if self.accept("."):
+                evaluator = self.parse_method(evaluator)
+            elif self.accept("["):
+                evaluator = self.parse_index(evaluator)
+            else:
+                return evaluator
+
+    def parse_member_chain(self, variable: str) -> Evaluator:
+        """Parse `variable.field...`, validating the field against the schema."""
+
+        def get_variable(env: Dict[str, Any]) -> Any:
+            return env[variable]
+
+        evaluator: Evaluator = get_variable
+        fields = self.schema[variable]
+        checked_field = False
+        while True:
+            if self.accept("."):
+                name_token = self.advance()
+                if name_token.kind != "name":
+                    raise self.error("Expected field name", name_token)
+                if self.current.value == "(":
+                    evaluator = self.parse_call(evaluator, name_token)
+                elif not checked_field:
+                    if name_token.value not in fields:
+                        raise self.error(
+                            f"Unknown field '{variable}.{name_token.value}'", name_token
+                        )
+                    evaluator = self.make_field(evaluator, name_token.value)
+                    checked_field = True
+                else:
+                    evaluator = self.make_field(evaluator, name_token.value)
+            elif self.accept("["):
+                evaluator = self.parse_index(evaluator)
+            else:
+                return evaluator
+
+    def parse_method(self, target: Evaluator) -> Evaluator:
+        name_token = self.advance()
+        if name_token.kind != "name":
+            raise self.error("Expected method name", name_token)
+        if self.current.value != "(":
+            return self.make_field(target, name_token.value)
+        return self.parse_call(target, name_token)
+
+    def parse_call(self, target: Evaluator, name_token: Token) -> Evaluator:
+        name = name_token.value
+        if name not in METHODS:
+            raise self.error(f"Unknown method '{name}'", name_token)
+        args = self.parse_arguments()
+        if len(args) != METHOD_ARITY[name]:
+            raise self.error(
+                f"Method '{name}' takes {METHOD_ARITY[name]} argument(s), got {len(args)}",
+                name_token,
+            )
+        method = METHODS[name]
+        return lambda env: method(target(env), *(arg(env) for arg in args))
+
+    def parse_arguments(self) -> List[Evaluator]:
+        self.expect("(")
+        args = []
+        if not self.accept(")"):
+            args.append(self.parse_ternary())
+            while self.accept(","):
+                args.append(self.parse_ternary())
+            self.expect(")")
+        return args
+
+    def parse_index(self, target: Evaluator) -> Evaluator:
+        key = self.parse_ternary()
+        self.expect("]")
+        return lambda env: target(env)[key(env)]
+
+    @staticmethod
+    def make_field(target: Evaluator, name: str) -> Evaluator:
+        def get_field(env: Dict[str, Any]) -> Any:
+            value = target(env)
+            # Only the keys of the variables, never Python attributes like __class__
+            if not isinstance(value, dict):
+                raise RuleError(f"{type(value).__name__} has no field '{name}'")
+            return value.get(name)
+
+        return get_field
+
+    def parse_primary(self) -> Evaluator:
+        token = self.advance()
+
+        if token.kind == "number":
+            value = float(token.value) if "." in token.value else int(token.value)
+            return lambda env: value
+        if token.kind == "string":
+            text = unquote(token.value)
+            return lambda env: text
+        if token.kind == "op" and token.value == "(":
+            evaluator = self.parse_ternary()
+            self.expect(")")
+            return evaluator
+        if token.kind == "op" and token.value == "[":
+            items = []
+            if not self.accept("]"):
+                items.append(self.parse_ternary())
+                while self.accept(","):
+                    items.append(self.parse_ternary())
+                self.expect("]")
+            return lambda env: [item(env) for item in items]
+        if token.kind == "name":
+            constants = {"true": True, "false": False, "null": None}
+            if token.value in constants:
+                constant = constants[token.value]
+                return lambda env: constant
+            if token.value == "size":
+                args = self.parse_arguments()
+                if len(args) != 1:
+                    raise self.error("size() takes 1 argument", token)
+                return lambda env: len(args[0](env))
+            if token.value == "has":
+                args = self.parse_arguments()
+                if len(args) != 1:
+                    raise self.error("has() takes 1 argument", token)
+                return lambda env: args[0](env) not in (None, "", [])
+            raise self.error(f"Unknown variable '{token.value}'", token)
+
+        raise self.error(f"Unexpected '{token.value or 'end of expression'}'", token)
+
+
+class Expression:
+    """A compiled rule expression."""
+
+    def __init__(self, source: str, schema: Optional[Dict[str, Set[str]]] = None):
+        """
+        Compile an expression.
+
+        Args:
+            source: Expression text
+            schema: Variables and their fields (default: `post` with POST_FIELDS)
+
+        Raises:
+            RuleError: If the expression is invalid
+        """
+        if not source or not source.strip():
+            raise RuleError("Expression is empty")
+        self.source = source
+        self._evaluate = Parser(source, schema or DEFAULT_SCHEMA).parse()
+
+    def __repr__(self):
+        return f"Expression({self.source!r})"
+
+    def evaluate(self, variables: Dict[str, Any]) -> Any:
+        """
+        Evaluate the expression.
+
+        Args:
+            variables: Values for the schema variables, e.g. {"post": {...}}
+
+        Returns:
+            Expression result
+
+        Raises:
+            RuleError: If evaluation fails (e.g. type mismatch)
+        """
+        try:
+            return self._evaluate(variables)
+        except RuleError:
+            raise
+        except Exception as e:
+            raise RuleError(f"Failed to evaluate {self.source!r}: {e}")
+
+    def matches(self, variables: Dict[str, Any]) -> bool:
+        """Evaluate the expression as a condition."""
+        return bool(self.evaluate(variables))
+
+
+def compile_expression(source: str, schema: Optional[Dict[str, Set[str]]] = None) -> Expression:
+    """Compile an expression (see Expression)."""
+    return Expression(source, schema)
+
+
+def post_variables(
+    channel: str,
+    link: str,
+    content: str,
+    pub_date: Any = None,
+    media_urls: Optional[List[str]] = None,
+    tags: Optional[List[str]] = None,
+    event_format: Optional[str] = None,
+    categories: Optional[List[str]] = None,
+) -> Dict[str, Any]:
+    """Build rule variables for a post."""
+    return {
+        "post": {
+            "channel": channel,
+            "link": link,
+            "content": content or "",
+            "pub_date": str(pub_date) if pub_date else "",
+            "media_urls": media_urls or [],
+            "tags": tags or [],
+            "format": event_format or "",
+            "categories": categories or [],
+        }
+    }
+
+
+@dataclass
+class FilterRule:
+    """Drop or tag posts matching an expression."""
+
+    name: str
+    when: Expression
+    action: str = "drop"
+    tags: List[str] = field(default_factory=list)
+
+    @staticmethod
+    def from_dict(data: dict) -> "FilterRule":
+        """Create FilterRule from a configuration entry."""
+        name = data.get("name") or data.get("when", "")
+        action = data.get("action", "drop")
+        if action not in FILTER_ACTIONS:
+            raise RuleError(f"Rule '{name}': action must be one of {FILTER_ACTIONS}")
+        try:
+            when = compile_expression(data.get("when", ""))
+        except RuleError as e:
+            raise RuleError(f"Rule '{name}': {e}")
+        return FilterRule(name=name, when=when, action=action, tags=list(data.get("tags") or []))
+
+
+@dataclass
+class Route:
+    """Send posts matching an expression to a publisher destination.
+
+    An empty destination means the publisher's default (e.g. SLACK_WEBHOOK_URL).
+    """
+
+    name: str
+    when: Expression
+    publisher: str
+    destination: str
+
+    @staticmethod
+    def from_dict(data: dict) -> "Route":
+        """Create Route from a configuration entry."""
+        name = data.get("name") or data.get("when", "")
+        if not data.get("publisher"):
+            raise RuleError(f"Route '{name}' requires a publisher")
+        try:
+            when = compile_expression(data.get("when", ""))
+        except RuleError as e:
+            raise RuleError(f"Route '{name}': {e}")
+        return Route(
+            name=name,
+            when=when,
+            publisher=data["publisher"],
+            destination=str(data.get("destination") or ""),
+        )
+
+
+@dataclass
+class Embargo:
+    """Hold posts matching an expression until the release time stated in them.
+
+    `pattern` is a regex for the phrase that introduces the release time; the
+    default one (see common.embargo) finds ticket sale starts.
+    """
+
+    name: str
+    when: Expression
+    pattern: Optional[re.Pattern] = None
+
+    @staticmethod
+    def from_dict(data: dict) -> "Embargo":
+        """Create Embargo from a configuration entry."""
+        name = data.get("name") or data.get("when", "")
+        try:
+            when = compile_expression(data.get("when", ""))
+        except RuleError as e:
+            raise RuleError(f"Embargo '{name}': {e}")
+        pattern = None
+        if data.get("pattern"):
+            try:
+                pattern = re.compile(data["pattern"], re.IGNORECASE)
+            except re.error as e:
+                raise RuleError(f"Embargo '{name}': invalid pattern: {e}")
+        return Embargo(name=name, when=when, pattern=pattern)
+
+
+@dataclass
+class RuleSet:
+    """Rules loaded from RULES_FILE."""
+
+    filters: List[FilterRule] = field(default_factory=list)
+    routes: List[Route] = field(default_factory=list)
+    embargoes: List[Embargo] = field(default_factory=list)
+
+    def __len__(self) -> int:
+        return len(self.filters) + len(self.routes) + len(self.embargoes)
+
+    def route(self, variables: Dict[str, Any]) -> Optional[Route]:
+        """
+        Find the first route matching a post.
+
+        Args:
+            variables: Rule variables (see post_variables)
+
+        Returns:
+            Matching Route, or None if the post should go to the default target
+        """
+        for route in self.routes:
+            try:
+                if route.when.matches(variables):
+                    return route
+            except RuleError as e:
+                logger.error(f"Route {route.name} failed: {e}")
+        return None
+
+    def embargo(self, variables: Dict[str, Any]) -> Optional[Embargo]:
+        """
+        Find the first embargo applying to a post.
+
+        Args:
+            variables: Rule variables (see post_variables)
+
+        Returns:
+            Matching Embargo, or None
+        """
+        for embargo in self.embargoes:
+            try:
+                if embargo.when.matches(variables):
+                    return embargo
+            except RuleError as e:
+                logger.error(f"Embargo {embargo.name} failed: {e}")
+        return None
+
+
+def load_rules(path: Optional[str] = None) -> RuleSet:
+    """
+    Load and compile pipeline rules.
+
+    The file is JSON:
+
+        {"filters": [{"name": "no_ads", "when": "post.content.contains(\"#реклама\")",
+                      "action": "drop"}],
+         "routes": [{"name": "concerts", "when": "\"concert\" in post.tags",
+                     "publisher": "telegram", "destination": "@city_concerts"}],
+         "embargoes": [{"name": "presales", "when": "\"presale\" in post.tags"}]}
+
+    Args:
+        path: Rules file (default: RULES_FILE env var)
+
+    Returns:
+        RuleSet (empty if not configured)
+
+    Raises:
+        RuleError: If any rule fails to compile
+    """
+    path = path or os.getenv("RULES_FILE", "")
+    if not path:
+        return RuleSet()
+
+    with open(path, "r", encoding="utf-8") as f:
+        config = json.load(f)
+
+    if not isinstance(config, dict):
+        raise RuleError(f"{path} must contain a JSON object")
+
+    return RuleSet(
+        filters=[FilterRule.from_dict(entry) for entry in config.get("filters") or []],
+        routes=[Route.from_dict(entry) for entry in config.get("routes") or []],
+        embargoes=[Embargo.from_dict(entry) for entry in config.get("embargoes") or []],
+    )
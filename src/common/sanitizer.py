@@ -0,0 +1,152 @@
+"""Profanity masking for public output.
+
+Stored posts are never modified; the sanitizer is applied to the final
+message right before it is published. Whether a target is sanitized is a
+per-tenant policy set with the profanity_filter feature flag, scoped by the
+publisher target label:
+
+    FEATURE_FLAGS="profanity_filter=on,profanity_filter[telegram:@adults_only]=off"
+
+Words are read from PROFANITY_WORDS_FILE (one per line, '#' starts a
+comment) or default to a built-in list. An entry ending with '*' matches
+every word starting with it, which covers inflected forms:
+
+    бля*
+    сука
+
+Matched words keep their first letter and the rest is replaced with
+PROFANITY_MASK_CHAR ('сука' -> 'с***').
+"""
+
+import logging
+import os
+import re
+from pathlib import Path
+from typing import Iterable, Optional
+
+from common.features import FeatureFlags, feature_flags
+
+logger = logging.getLogger(__name__)
+
+# Stems of common Russian and English obscenities
+DEFAULT_WORDS = [
+    "хуй*",
+    "хуе*",
+    "хуё*",
+    "хуя*",
+    "пизд*",
+    "ебан*",
+    "ебат*",
+    "ебал*",
+    "ебуч*",
+    "ёбан*",
+    "бля*",
+    "сука",
+    "суки",
+    "сучк*",
+    "мудак*",
+    "мудил*",
+    "пидор*",
+    "пидар*",
+    "fuck*",
+    "shit*",
+]
+
+FLAG_NAME = "profanity_filter"
+
+TAG_REGEX = re.compile(r"(<[^>]+>|&[#\w]+;)")
+
+
+class Sanitizer:
+    """Mask configured words in text."""
+
+    def __init__(self, words: Iterable[str], mask_char: str = "*"):
+        """
+        Args:
+            words: Words to mask; a trailing '*' matches any continuation
+            mask_char: Character replacing hidden letters
+        """
+        patterns = []
+        for word in words:
+            word = word.strip().lower()
+            if not word:
+                continue
+            if word.endswith("*"):
+                patterns.append(re.escape(word[:-1].replace("ё", "е")) + r"\w*")
+            else:
+                patterns.append(re.escape(word.replace("ё", "е")))
+        self.mask_char = mask_char
+        self.regex = (
+            re.compile(r"(?<!\w)(?:" + "|".join(patterns) + r")(?!\w)", re.IGNORECASE)
+            if patterns
+            else None
+        )
+
+    @classmethod
+    def from_file(cls, path: str, mask_char: str = "*") -> "Sanitizer":
+        """Load words from a file with one word per line."""
+        lines = Path(path).read_text(encoding="utf-8").splitlines()
+        words = [line.split("#", 1)[0] for line in lines]
+        return cls(words, mask_char)
+
+    def mask(self, word: str) -> str:
+        """Hide all letters of a word but the first."""
+        return word[0] + self.mask_char * (len(word) - 1)
+
+    def sanitize(self, text: str) -> str:
+        """Mask words in plain text."""
+        if not self.regex or not text:
+            return text
+        # 'ё' and 'е' are used interchangeably, so match on a normalized copy
+        normalized = text.replace("ё", "е").replace("Ё", "Е")
+        result = []
+        position = 0
+        for match in self.regex.finditer(normalized):
+            result.append(text[position : match.start()])
+            result.append(self.mask(text[match.start() : match.end()]))
+            position = match.end()
+        result.append(text[position:])
+        return "".join(result)
+
+    def sanitize_html(self, html: str) -> str:
+        """Mask words in HTML, leaving tags, attributes and entities untouched."""
+        parts = TAG_REGEX.split(html)
+        return "".join(part if i % 2 else self.sanitize(part) for i, part in enumerate(parts))
+
+
+_sanitizer: Optional[Sanitizer] = None
+
+
+def get_sanitizer() -> Sanitizer:
+    """Get the sanitizer configured by PROFANITY_WORDS_FILE and PROFANITY_MASK_CHAR."""
+    global _sanitizer
+    if _sanitizer is None:
+        path = os.getenv("PROFANITY_WORDS_FILE", "")
+        mask_char = os.getenv("PROFANITY_MASK_CHAR", "*")
+        if path:
+            _sanitizer = Sanitizer.from_file(path, mask_char)
+        else:
+            _sanitizer = Sanitizer(DEFAULT_WORDS, mask_char)
+        logger.info(f"Loaded profanity word list from {path or 'built-in defaults'}")
+    return _sanitizer
+
+
+def sanitize_for(
+    target: str, text: str, html: bool = False, flags: Optional[FeatureFlags] = None
+) -> str:
+    """
+    Sanitize output for a publisher target if its policy asks for it.
+
+    Args:
+        target: Publisher target label, e.g. 'telegram:@city_events'
+        text: Message to publish
+        html: Whether the message is HTML
+        flags: Feature flags holding the policy (FEATURE_FLAGS by default)
+
+    Returns:
+        Masked text, or the text unchanged when the filter is off for the target
+    """
+    if not (flags or feature_flags).is_enabled(FLAG_NAME, target):
+        return text
+    sanitizer = get_sanitizer()
+    return sanitizer.sanitize_html(text) if html else sanitizer.sanitize(text)
@@ -0,0 +1,146 @@
+"""Festivals and other series of related events.
+
+A post belongs to a series when it carries a festival-like hashtag
+("#джазфест2026", "#неделя_науки") or links to an earlier announcement of
+the same channel ("программа фестиваля — t.me/club/120"); in the latter case
+the announcement is added to the series as well. Digests and the site use
+series to show "Фестиваль X: 12 событий" instead of a flat list.
+"""
+
+import re
+from typing import Dict, List, Optional, Tuple
+
+from common.db.models import RSSPost, Series
+from common.db.repository import RSSPostRepository, SeriesRepository
+from common.utils.links import channel_from_link
+
+HASHTAG_REGEX = re.compile(r"(?<![\w#])#(\w{3,64})")
+# Hashtags that name a festival or a cycle rather than a topic like #концерт
+SERIES_HASHTAG_REGEX = re.compile(
+    r"фест|fest|форум|forum|недел|week|марафон|marathon|биеннале|biennale|сезон|season"
+    r"|цикл|series|дни_|days",
+    re.IGNORECASE,
+)
+POST_LINK_REGEX = re.compile(r"(?:https?://)?t\.me/(?:s/)?(\w+)/(\d+)")
+
+# Groups smaller than this are shown as regular events
+MIN_EVENTS = 2
+
+
+def hashtag_name(tag: str) -> str:
+    """Display name of a hashtag: '#неделя_науки' -> 'Неделя науки'."""
+    name = tag.lstrip("#").replace("_", " ").strip()
+    return name[:1].upper() + name[1:]
+
+
+def parent_link(link: str, content: str) -> Optional[str]:
+    """Link to an earlier announcement of the same channel mentioned in a post."""
+    channel = channel_from_link(link)
+    for match in POST_LINK_REGEX.finditer(content or ""):
+        parent = f"https://t.me/{match.group(1)}/{match.group(2)}"
+        if match.group(1) == channel and parent != link:
+            return parent
+    return None
+
+
+def detect_series(link: str, content: str) -> Optional[Series]:
+    """
+    Find the series a post belongs to.
+
+    Args:
+        link: Post link
+        content: Post text
+
+    Returns:
+        Series keyed by hashtag or parent link (the name of a parent series is
+        empty until the parent is looked up), or None
+    """
+    for match in HASHTAG_REGEX.finditer(content or ""):
+        if SERIES_HASHTAG_REGEX.search(match.group(1)):
+            return Series(key=f"#{match.group(1).lower()}", name=hashtag_name(match.group(1)))
+
+    parent = parent_link(link, content)
+    return Series(key=parent, name="", parent_link=parent) if parent else None
+
+
+def parent_name(parent: RSSPost) -> str:
+    """Series name taken from the parent announcement."""
+    if parent.title:
+        return parent.title
+    first_line = (parent.content or "").strip().split("\n")[0]
+    return first_line[:200] or parent.link
+
+
+async def assign_series(post: RSSPost) -> Optional[str]:
+    """
+    Create the series of a new post and add its parent announcement to it.
+
+    Must run before the post is saved, since the post references the series.
+
+    Args:
+        post: New post
+
+    Returns:
+        Series key for the post, or None
+    """
+    series = detect_series(post.link, post.content)
+    if series is None:
+        return None
+
+    if series.parent_link:
+        parent = await RSSPostRepository.get_by_link(series.parent_link)
+        if parent is None:
+            # Links to posts that were never read don't make a series
+            return None
+        if parent.series_key:
+            # The announcement already belongs to a festival
+            return parent.series_key
+        series.name = parent_name(parent)
+        await SeriesRepository.create(series)
+        await SeriesRepository.add_post(parent.link, series.key)
+        return series.key
+
+    await SeriesRepository.create(series)
+    return series.key
+
+
+async def load_series(posts: List[RSSPost]) -> Dict[str, Series]:
+    """Get the series of the given posts, keyed by series key."""
+    keys = list({post.series_key for post in posts if post.series_key})
+    return await SeriesRepository.get_many(keys) if keys else {}
+
+
+def group_by_series(
+    posts: List[RSSPost], series: Dict[str, Series], min_events: int = MIN_EVENTS
+) -> Tuple[List[Tuple[Series, List[RSSPost]]], List[RSSPost]]:
+    """
+    Split posts into series groups and standalone posts.
+
+    Args:
+        posts: Posts in display order
+        series: Key -> Series for the posts' series
+        min_events: Smallest group shown as a series
+
+    Returns:
+        (series, posts) groups in order of their first post, and the other posts
+    """
+    groups: Dict[str, List[RSSPost]] = {}
+    for post in posts:
+        if post.series_key in series:
+            groups.setdefault(post.series_key, []).append(post)
+
+    grouped = [(series[key], items) for key, items in groups.items() if len(items) >= min_events]
+    keys = {group.key for group, _ in grouped}
+    standalone = [post for post in posts if post.series_key not in keys]
+    return grouped, standalone
+
+
+def plural_events(count: int) -> str:
+    """'1 событие', '3 события', '12 событий'."""
+    if count % 10 == 1 and count % 100 != 11:
+        word = "событие"
+    elif 2 <= count % 10 <= 4 and not 12 <= count % 100 <= 14:
+        word = "события"
+    else:
+        word = "событий"
+    return f"{count} {word}"
@@ -0,0 +1,191 @@
+"""Translation of event titles and summaries.
+
+Translations are produced by a pluggable provider and cached in the
+translations table by a hash of the source text, so a title or summary is
+sent to the provider once per language and edited texts are translated
+again. The Summarizer agent fills the cache; the API and calendar exports
+only read it.
+
+Providers:
+
+- openai          OpenAI chat model (OPENAI_API_KEY, TRANSLATION_MODEL)
+- libretranslate  LibreTranslate server (LIBRETRANSLATE_URL, LIBRETRANSLATE_API_KEY)
+"""
+
+import asyncio
+import hashlib
+import json
+import logging
+from typing import Dict, List
+
+import requests
+from openai import AsyncOpenAI
+
+from common.db.models import RSSPost
+from common.db.repository import TranslationRepository
+
+logger = logging.getLogger(__name__)
+
+LANGUAGE_NAMES = {"en": "English", "de": "German", "fr": "French", "es": "Spanish"}
+
+
+class TranslationError(Exception):
+    """Raised when a provider returns no usable translation."""
+
+
+def text_hash(text: str) -> str:
+    """Cache key of a source text."""
+    return hashlib.sha256(text.encode()).hexdigest()
+
+
+def parse_languages(value: str) -> List[str]:
+    """Parse a comma-separated list of language codes like 'en,de'."""
+    return [code.strip().lower() for code in value.split(",") if code.strip()]
+
+
+class Translator:
+    """Base class for translation providers."""
+
+    name = ""
+
+    async def translate(self, texts: List[str], language: str) -> List[str]:
+        """
+        Translate texts.
+
+        Args:
+            texts: Source texts (usually Russian)
+            language: Target language code
+
+        Returns:
+            Translations in the same order
+
+        Raises:
+            TranslationError: If the provider returns no usable translation
+        """
+        raise NotImplementedError
+
+
+class OpenAITranslator(Translator):
+    """Translate with an OpenAI chat model, one request per batch."""
+
+    name = "openai"
+
+    def __init__(self, client: AsyncOpenAI, model: str):
+        self.client = client
+        self.model = model
+
+    async def translate(self, texts: List[str], language: str) -> List[str]:
+        language_name = LANGUAGE_NAMES.get(language, language)
+        response = await self.client.chat.completions.create(
+            model=self.model,
+            messages=[
+                {
+                    "role": "system",
+                    "content": (
+                        f"Translate each string of the JSON array to {language_name}. "
+                        "Keep names of venues and performers. Reply with a JSON array of "
+                        "the same length and nothing else."
+                    ),
+                },
+                {"role": "user", "content": json.dumps(texts, ensure_ascii=False)},
+            ],
+            temperature=0,
+        )
+        content = (response.choices[0].message.content or "").strip()
+        # Models sometimes wrap the reply in a Markdown code block
+        content = content.removeprefix("```json").removeprefix("```").removesuffix("```")
+        try:
+            translations = json.loads(content)
+        except ValueError:
+            raise TranslationError(f"Invalid JSON from {self.model}: {content[:100]}")
+        if not isinstance(translations, list) or len(translations) != len(texts):
+            raise TranslationError(f"Expected {len(texts)} translations from {self.model}")
+        return [str(text).strip() for text in translations]
+
+
+class LibreTranslateTranslator(Translator):
+    """Translate with a LibreTranslate server."""
+
+    name = "libretranslate"
+
+    def __init__(self, base_url: str, api_key: str = "", timeout: int = 30):
+        self.base_url = base_url.rstrip("/")
+        self.api_key = api_key
+        self.timeout = timeout
+
+    async def translate(self, texts: List[str], language: str) -> List[str]:
+        payload = {"q": texts, "source": "auto", "target": language, "format": "text"}
+        if self.api_key:
+            payload["api_key"] = self.api_key
+        response = await asyncio.to_thread(
+            requests.post, f"{self.base_url}/translate", json=payload, timeout=self.timeout
+        )
+        response.raise_for_status()
+        translations = response.json().get("translatedText")
+        if not isinstance(translations, list) or len(translations) != len(texts):
+            raise TranslationError(f"Expected {len(texts)} translations from LibreTranslate")
+        return translations
+
+
+async def translate_cached(
+    translator: Translator, texts: List[str], language: str, batch_size: int = 20
+) -> int:
+    """
+    Translate texts that are not cached yet.
+
+    Args:
+        translator: Translation provider
+        texts: Source texts; empty and duplicate texts are skipped
+        language: Target language code
+        batch_size: Texts per provider request
+
+    Returns:
+        Number of newly translated texts
+    """
+    unique = list(dict.fromkeys(text for text in texts if text))
+    cached = await TranslationRepository.get_many([text_hash(text) for text in unique], language)
+    missing = [text for text in unique if text_hash(text) not in cached]
+
+    translated = 0
+    for start in range(0, len(missing), batch_size):
+        batch = missing[start : start + batch_size]
+        try:
+            results = await translator.translate(batch, language)
+        except Exception as e:
+            # Uncached texts are retried on the next run
+            logger.warning(f"Failed to translate {len(batch)} texts to {language}: {e}")
+            continue
+        for text, result in zip(batch, results):
+            if result:
+                await TranslationRepository.save(text_hash(text), language, result, translator.name)
+                translated += 1
+    return translated
+
+
+async def load_translations(
+    posts: List[RSSPost], languages: List[str]
+) -> Dict[str, Dict[str, dict]]:
+    """
+    Get cached translations of post titles and summaries.
+
+    Args:
+        posts: Posts to look up
+        languages: Target language codes
+
+    Returns:
+        Link -> language -> {"title": ..., "summary": ...}; missing parts are empty strings
+    """
+    texts = {text for post in posts for text in (post.title, post.summary) if text}
+    hashes = [text_hash(text) for text in texts]
+    result: Dict[str, Dict[str, dict]] = {post.link: {} for post in posts}
+    if not hashes:
+        return result
+
+    for language in languages:
+        cached = await TranslationRepository.get_many(hashes, language)
+        for post in posts:
+            title = cached.get(text_hash(post.title)) if post.title else None
+            summary = cached.get(text_hash(post.summary)) if post.summary else None
+            if title or summary:
+                result[post.link][language] = {"title": title or "", "summary": summary or ""}
+    return result
@@ -0,0 +1,87 @@
+"""Parsing of the publication dates found in feeds.
+
+RSS asks for RFC 822 dates and Atom for RFC 3339, but feeds in the wild send
+anything from '10.01.2026 12:00' to 'January 10, 2026'. Both standards are
+tried first, then FALLBACK_FORMATS in order.
+"""
+
+import re
+from datetime import datetime
+from email.utils import parsedate_to_datetime
+from typing import Optional
+
+# strptime layouts tried when neither RFC 822 nor ISO 8601 parsing works
+FALLBACK_FORMATS = [
+    "%a, %d %b %Y %H:%M:%S %z",
+    "%a, %d %b %Y %H:%M %z",
+    "%Y-%m-%d %H:%M:%S %z",
+    "%Y-%m-%d %H:%M %z",
+    "%Y/%m/%d %H:%M:%S",
+    "%Y/%m/%d %H:%M",
+    "%Y/%m/%d",
+    "%d.%m.%Y %H:%M:%S",
+    "%d.%m.%Y %H:%M",
+    "%d.%m.%Y",
+    "%d %b %Y %H:%M:%S",
+    "%d %b %Y %H:%M",
+    "%d %b %Y",
+    "%d %B %Y %H:%M",
+    "%d %B %Y",
+    "%B %d, %Y %H:%M",
+    "%B %d, %Y",
+    "%b %d, %Y",
+]
+
+# '+03:00' at the end, which strptime's %z only accepts without the colon on some layouts
+OFFSET_WITH_COLON = re.compile(r"([+-]\d{2}):(\d{2})$")
+
+
+class UnparseableDate(ValueError):
+    """Raised when a date matches none of the known formats."""
+
+    def __init__(self, value: str):
+        super().__init__(f"Unable to parse datetime from: {value}")
+        self.value = value
+
+
+def parse_feed_date(value: str) -> datetime:
+    """
+    Parse a publication date from a feed.
+
+    Args:
+        value: Date as found in the feed
+
+    Returns:
+        Timezone-naive datetime (PostgreSQL TIMESTAMP expects naive datetimes)
+
+    Raises:
+        UnparseableDate: If no format matches
+    """
+    value = " ".join(value.split())
+    dt = _parse(value)
+    if dt is None:
+        raise UnparseableDate(value)
+    if dt.tzinfo is not None:
+        dt = dt.replace(tzinfo=None)
+    return dt
+
+
+def _parse(value: str) -> Optional[datetime]:
+    # RFC 2822 first (common in RSS feeds)
+    try:
+        return parsedate_to_datetime(value)
+    except (ValueError, TypeError, IndexError):
+        pass
+
+    try:
+        return datetime.fromisoformat(value.replace("Z", "+00:00"))
+    except ValueError:
+        pass
+
+    normalized = OFFSET_WITH_COLON.sub(r"\1\2", value)
+    for layout in FALLBACK_FORMATS:
+        try:
+            return datetime.strptime(normalized, layout)
+        except ValueError:
+            continue
+    return None
@@ -0,0 +1,40 @@
+"""Helpers for Telegram post links."""
+
+import hashlib
+import re
+from urllib.parse import urlparse
+
+
+def channel_from_link(link: str) -> str:
+    """
+    Extract the Telegram channel name from a post link.
+
+    Args:
+        link: Post URL such as 'https://t.me/centralbank_russia/3235'
+
+    Returns:
+        Channel name, or an empty string if the link has no path
+    """
+    parts = [part for part in urlparse(link).path.split("/") if part]
+    if parts and parts[0] == "s":
+        parts = parts[1:]
+    return parts[0] if parts else ""
+
+
+def post_slug(link: str) -> str:
+    """
+    Build a stable, URL-safe identifier for a post.
+
+    Args:
+        link: Post URL such as 'https://t.me/centralbank_russia/3235'
+
+    Returns:
+        Slug such as 'centralbank_russia-3235'
+    """
+    parts = [part for part in urlparse(link).path.split("/") if part]
+    if parts and parts[0] == "s":
+        parts = parts[1:]
+    slug = re.sub(r"[^A-Za-z0-9_-]+", "-", "-".join(parts)).strip("-").lower()
+    if not slug or len(slug) > 80:
+        return hashlib.sha256(link.encode()).hexdigest()[:16]
+    return slug
@@ -2,10 +2,13 @@
 
 from urllib.parse import urlencode
 
+# The public instance, used unless others are configured
+DEFAULT_BRIDGE_URL = "https://rss-bridge.org/bridge01/"
+
 
 def build_rss_bridge_url(
     channel_name: str,
-    base_url: str = "https://rss-bridge.org/bridge01/",
+    base_url: str = DEFAULT_BRIDGE_URL,
     bridge: str = "TelegramBridge",
     format: str = "Mrss",
 ) -> str:
@@ -0,0 +1 @@
+"""Demo - Runs the platform on sample posts without a database or Docker."""
@@ -0,0 +1,129 @@
+"""Entry point for the demo.
+
+Run with: python -m src.demo [--port 8080] [--no-browser] [--now 2026-03-01T12:00]
+
+Reads announcements from a bundled sample feed into the in-memory backend,
+summarizes them with the rule-based summarizer and serves the event site and
+public API on localhost. Needs no database, Docker or API keys, and nothing
+is kept after exit. --now pins the clock to show the platform at another time.
+"""
+
+import argparse
+import asyncio
+import logging
+import os
+import secrets
+import sys
+import webbrowser
+from datetime import datetime
+from typing import Optional
+
+logging.basicConfig(
+    level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
+)
+logger = logging.getLogger(__name__)
+
+
+def configure(host: str, port: int) -> None:
+    """
+    Point the services at the in-memory backend and the local site.
+
+    Settings are read when their modules are imported, so this runs before
+    any of them is.
+    """
+    os.environ["DATABASE_DSN"] = "memory://"
+    os.environ["API_HOST"] = host
+    os.environ["API_PORT"] = str(port)
+    os.environ["API_SITE_ENABLED"] = "true"
+    os.environ["API_PUBLIC_MODE"] = "true"
+    os.environ["SITE_BASE_URL"] = f"http://{host}:{port}"
+    os.environ.setdefault("SITE_TITLE", "Афиша (демо)")
+    # Nothing private is served in public mode, but don't leave a token empty
+    os.environ["API_TOKEN"] = secrets.token_urlsafe(16)
+
+
+async def load_sample(feed_url: str) -> int:
+    """
+    Read the sample feed and prepare its posts like the pipeline would.
+
+    Returns:
+        Number of saved posts
+    """
+    from common.db.repository import RSSPostRepository
+    from rss_reader.__main__ import save_items
+    from rss_reader.core.parser import RSSParser
+    from summarizer.config import summarizer_settings
+    from summarizer.summarize import rule_summary
+    from summarizer.titles import generate_title
+
+    feed = RSSParser().parse_url(feed_url)
+    saved, _, _, _, errors = await save_items("demo", feed.items)
+    if errors:
+        logger.warning(f"{errors} sample posts failed to save")
+
+    for post in await RSSPostRepository.get_without_summary(len(feed.items)):
+        await RSSPostRepository.update_summary(
+            post.link,
+            rule_summary(post.content, summarizer_settings.max_length),
+            generate_title(post.content, summarizer_settings.title_max_length),
+        )
+    # The public API only lists posts that went out in a digest
+    await RSSPostRepository.mark_as_published([item.link for item in feed.items])
+    return saved
+
+
+async def run(host: str, port: int, open_browser: bool, now: Optional[datetime] = None) -> None:
+    """Load the sample posts and serve the site until interrupted."""
+    from api.__main__ import create_server
+    from common.clock import FakeClock, set_clock
+    from common.db.session import db
+    from .sample import FeedServer
+
+    if now:
+        set_clock(FakeClock(now))
+    await db.connect()
+    feed_server = FeedServer(host)
+    feed_server.start()
+    try:
+        saved = await load_sample(feed_server.url)
+    finally:
+        feed_server.stop()
+
+    url = f"http://{host}:{port}/"
+    print(f"✓ Loaded {saved} sample posts")
+    print(f"  Site:       {url}")
+    print(f"  Public API: {url}v1/events")
+    print("Press Ctrl+C to stop.")
+    if open_browser:
+        asyncio.get_running_loop().call_later(0.5, webbrowser.open, url)
+
+    await create_server().serve(host, port)
+
+
+def main():
+    """Main entry point for the demo."""
+    parser = argparse.ArgumentParser(description="Run the platform on sample posts")
+    parser.add_argument("--host", default="127.0.0.1", help="Address to serve the site on")
+    parser.add_argument("--port", type=int, default=8080, help="Port to serve the site on")
+    parser.add_argument(
+        "--no-browser", action="store_true", help="Don't open the site in a browser"
+    )
+    parser.add_argument(
+        "--now",
+        type=datetime.fromisoformat,
+        help="Pretend it's this time, e.g. 2026-03-01T12:00 (default: the real time)",
+    )
+    args = parser.parse_args()
+
+    configure(args.host, args.port)
+    try:
+        asyncio.run(run(args.host, args.port, not args.no_browser, args.now))
+    except KeyboardInterrupt:
+        print("\nDemo stopped.")
+    except OSError as e:
+        print(f"✗ Demo failed: {e}", file=sys.stderr)
+        sys.exit(1)
+
+
+if __name__ == "__main__":
+    main()
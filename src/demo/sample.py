@@ -0,0 +1,175 @@
+"""Sample feed for the demo.
+
+Announcements of a few made-up channels, dated relative to today so the site
+always has upcoming events. Between them they cover what the reader detects:
+prices, registration, online events, multi-day festivals, series and
+cancellations.
+"""
+
+import threading
+import xml.etree.ElementTree as ET
+from dataclasses import dataclass
+from datetime import date, datetime, timedelta
+from email.utils import format_datetime
+from html import escape
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from typing import List, Tuple
+
+from common import clock
+from common.pages import MONTHS_GENITIVE
+
+
+@dataclass
+class SamplePost:
+    """Announcement published `hours_ago`, mentioning days `days` from today."""
+
+    channel: str
+    number: int
+    hours_ago: int
+    days: Tuple[int, ...]
+    text: str
+
+
+SAMPLE_POSTS = [
+    SamplePost(
+        "demo_jazz_club",
+        101,
+        30,
+        (3,),
+        "Вечер джаза с квартетом «Синяя птица»\n\n"
+        "{0} в 19:30 в клубе «Подвал», ул. Почтовая, 12.\n"
+        "Билеты от 800 до 1500 ₽, вход по билетам. Мест ограничено!",
+    ),
+    SamplePost(
+        "demo_jazz_club",
+        102,
+        20,
+        (8, 9, 10),
+        "Джазовый фестиваль «Три ночи» #джазфест\n\n"
+        "С {0} по {2} на набережной: 12 коллективов, фудкорт и ярмарка пластинок.\n"
+        "Программа по дням — в следующих постах.",
+    ),
+    SamplePost(
+        "demo_jazz_club",
+        103,
+        19,
+        (8,),
+        "#джазфест Первая ночь: {0} в 20:00 открывает фестиваль биг-бэнд филармонии.\n"
+        "Вход свободный.",
+    ),
+    SamplePost(
+        "demo_jazz_club",
+        104,
+        18,
+        (9,),
+        "#джазфест Вторая ночь: {0} в 21:00 — джем-сешн, приносите инструменты.\n"
+        "Вход 300 ₽.",
+    ),
+    SamplePost(
+        "demo_science",
+        201,
+        12,
+        (5,),
+        "Онлайн-лекция «Как устроены чёрные дыры»\n\n"
+        "{0} в 18:00, трансляция на YouTube: youtube.com/live/demo\n"
+        "Бесплатно, по предварительной регистрации.",
+    ),
+    SamplePost(
+        "demo_science",
+        202,
+        10,
+        (12, 14, 16),
+        "Неделя науки в библиотеке #неделя_науки\n\n"
+        "{0}, {1} и {2} в 17:00: опыты для детей и лекции для взрослых.\n"
+        "Вход свободный, запись обязательна.",
+    ),
+    SamplePost(
+        "demo_theatre",
+        301,
+        8,
+        (6,),
+        "Спектакль «Чайка» в театре на Соборной\n\n"
+        "{0} в 19:00. Билеты в кассах театра, от 500 ₽.",
+    ),
+    SamplePost(
+        "demo_theatre",
+        302,
+        2,
+        (),
+        "Спектакль отменён по болезни актёра, билеты можно вернуть в кассе.\n"
+        "t.me/demo_theatre/301",
+    ),
+]
+
+
+def human_date(day: date) -> str:
+    """'15 марта' for a day."""
+    return f"{day.day} {MONTHS_GENITIVE[day.month - 1]}"
+
+
+def sample_items(now: datetime) -> List[dict]:
+    """Sample posts as feed items, newest first."""
+    items = []
+    for post in SAMPLE_POSTS:
+        days = [human_date(now.date() + timedelta(days=offset)) for offset in post.days]
+        items.append(
+            {
+                "link": f"https://t.me/{post.channel}/{post.number}",
+                "pub_date": now - timedelta(hours=post.hours_ago),
+                "text": post.text.format(*days),
+            }
+        )
+    return sorted(items, key=lambda item: item["pub_date"], reverse=True)
+
+
+def build_feed(now: datetime) -> bytes:
+    """Render the sample posts as an RSS 2.0 feed, like the ones RSS Bridge serves."""
+    rss = ET.Element("rss", version="2.0")
+    channel = ET.SubElement(rss, "channel")
+    ET.SubElement(channel, "title").text = "Demo channels"
+    ET.SubElement(channel, "link").text = "https://t.me/s/demo"
+    ET.SubElement(channel, "description").text = "Sample announcements"
+    for item in sample_items(now):
+        element = ET.SubElement(channel, "item")
+        ET.SubElement(element, "title").text = item["text"].split("\n")[0]
+        ET.SubElement(element, "link").text = item["link"]
+        ET.SubElement(element, "guid", isPermaLink="true").text = item["link"]
+        ET.SubElement(element, "pubDate").text = format_datetime(item["pub_date"])
+        html = "<br>".join(escape(line) for line in item["text"].split("\n"))
+        ET.SubElement(element, "description").text = (
+            f'<div class="tgme_widget_message_text">{html}</div>'
+        )
+    return ET.tostring(rss, encoding="utf-8", xml_declaration=True)
+
+
+class FeedServer:
+    """Serves the sample feed on a local port from a background thread."""
+
+    def __init__(self, host: str = "127.0.0.1", port: int = 0):
+        feed = build_feed(clock.now())
+
+        class Handler(BaseHTTPRequestHandler):
+            def do_GET(self):
+                self.send_response(200)
+                self.send_header("Content-Type", "application/rss+xml; charset=utf-8")
+                self.send_header("Content-Length", str(len(feed)))
+                self.end_headers()
+                self.wfile.write(feed)
+
+            def log_message(self, format, *args):
+                pass
+
+        self.server = ThreadingHTTPServer((host, port), Handler)
+        self.thread = threading.Thread(target=self.server.serve_forever, daemon=True)
+
+    @property
+    def url(self) -> str:
+        host, port = self.server.server_address[:2]
+        return f"http://{host}:{port}/feed.xml"
+
+    def start(self) -> None:
+        self.thread.start()
+
+    def stop(self) -> None:
+        self.server.shutdown()
+        self.server.server_close()
@@ -5,19 +5,30 @@ Run with: python -m src.digest_publisher
 
 import asyncio
 import logging
-from typing import List, Dict
+from html import escape
+from typing import List, Dict, Optional, Tuple
 from datetime import datetime, timedelta
 from collections import defaultdict
 
-from telegram import Bot
-from telegram.constants import ParseMode
-from telegram.error import TelegramError, NetworkError
 from openai import AsyncOpenAI
 
+from common import clock
 from common.db.session import db
-from common.db.repository import RSSPostRepository
-from common.db.models import RSSPost
+from common.db.repository import PromotionRepository, RSSPostRepository
+from common.admission import Admission
+from common.embargo import hold_embargoed
+from common.event_status import LABELS as STATUS_LABELS
+from common.db.models import Promotion, RSSPost, Series
+from common.pages import event_when
+from common.quotas import tracking_http_client
+from common.rules import RuleSet, load_rules, post_variables
+from common.sanitizer import sanitize_for
+from common.series import group_by_series, load_series
+from common.utils.links import channel_from_link
 from .config import digest_publisher_settings
+from .promotions import apply_promotions, select_promotions
+from .publishers import DEFAULT_PUBLISHER, get_publisher
+from .recovery import queue_failure, queued_links, retry_due
 
 logging.basicConfig(
     level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
@@ -25,13 +36,16 @@ logging.basicConfig(
 logger = logging.getLogger(__name__)
 
 
-def prepare_posts_for_prompt(posts: List[RSSPost], section_title: str = "Posts") -> str:
+def prepare_posts_for_prompt(
+    posts: List[RSSPost], section_title: str = "Posts", series: Optional[Dict[str, Series]] = None
+) -> str:
     """
     Prepare posts in a format suitable for OpenAI prompt, grouped by day.
 
     Args:
         posts: List of RSSPost objects
         section_title: Title for this section of posts
+        series: Key -> Series of the posts, listed before the days
 
     Returns:
         Formatted string with all posts grouped by day
@@ -56,6 +70,14 @@ def prepare_posts_for_prompt(posts: List[RSSPost], section_title: str = "Posts")
 
     formatted_posts = [f"\n=== {section_title} ==="]
 
+    groups, _ = group_by_series(posts, series or {})
+    if groups:
+        formatted_posts.append("\nSeries:")
+        for group, group_posts in groups:
+            formatted_posts.append(
+                f"- {group.name}: {len(group_posts)} posts, {group.event_count} events in total"
+            )
+
     post_counter = 1
     for date_key in sorted_dates:
         day_posts = posts_by_date[date_key]
@@ -76,6 +98,27 @@ def prepare_posts_for_prompt(posts: List[RSSPost], section_title: str = "Posts")
             if post.pub_date:
                 post_info.append(f"Time: {post.pub_date.strftime('%H:%M')}")
 
+            if post.title:
+                post_info.append(f"Title: {post.title}")
+
+            if post.summary:
+                post_info.append(f"Summary: {post.summary}")
+
+            if post.event_start:
+                post_info.append(f"Dates: {event_when(post)}")
+
+            if series and post.series_key in series:
+                post_info.append(f"Series: {series[post.series_key].name}")
+
+            if post.event_format:
+                post_info.append(f"Format: {post.event_format}")
+
+            warnings = Admission.from_post(post).warnings()
+            if post.event_status in STATUS_LABELS:
+                warnings.insert(0, STATUS_LABELS[post.event_status].lower())
+            if warnings:
+                post_info.append(f"Notes: {', '.join(warnings)}")
+
             if post.content:
                 # Truncate very long content
                 content = post.content[:1000] + "..." if len(post.content) > 1000 else post.content
@@ -116,8 +159,10 @@ async def generate_ai_digest(posts: List[RSSPost], client: AsyncOpenAI) -> str:
     )
     logger.info(f"Found {len(previous_posts)} previous posts to include as context")
 
+    series = await load_series(posts)
+
     # Prepare posts for the prompt
-    posts_content = prepare_posts_for_prompt(posts, "CURRENT Posts to Summarize")
+    posts_content = prepare_posts_for_prompt(posts, "CURRENT Posts to Summarize", series)
     previous_posts_content = prepare_posts_for_prompt(
         previous_posts, "PREVIOUS Posts (Already Published - DO NOT REPEAT)"
     )
@@ -134,6 +179,9 @@ async def generate_ai_digest(posts: List[RSSPost], client: AsyncOpenAI) -> str:
 3. Внутри каждого дня группируйте связанные темы для логичного повествования.
 4. Используйте эмодзи для улучшения восприятия (📰 🔥 💡 ⚡ 🏆 📅).
 5. Пишите кратко и понятно.
+6. Если у поста есть пометки (Notes), предупредите о них (например, "⚠️ Нужна регистрация").
+7. Многодневные события (Dates) упоминайте один раз, с диапазоном дат (например, "5–7 марта").
+8. События одной серии (Series) объедините под одним заголовком: "🎪 Фестиваль X: 12 событий".
 
 # ВАЖНО: Анти-дублирование
 - Вам предоставлены ПРЕДЫДУЩИЕ посты — они УЖЕ были опубликованы.
@@ -230,9 +278,9 @@ def format_post_for_telegram(post: RSSPost) -> str:
     """
     lines = []
 
-    # Extract title from link or use first line of content
-    title = post.link.split("/")[-1].replace("-", " ").replace("_", " ")[:100]
-    if len(title) < 10 and post.content:
+    # Prefer the generated title, then the link slug or the first line of content
+    title = post.title or post.link.split("/")[-1].replace("-", " ").replace("_", " ")[:100]
+    if len(title) < 10 and post.content and not post.title:
         title = post.content.split("\n")[0][:100]
 
     title = escape_markdown_v2(title)
@@ -242,7 +290,19 @@ def format_post_for_telegram(post: RSSPost) -> str:
         date_str = escape_markdown_v2(post.pub_date.strftime("%Y-%m-%d %H:%M"))
         lines.append(f"🕐 {date_str}")
 
-    if post.content:
+    if post.event_start:
+        lines.append(f"📅 {escape_markdown_v2(event_when(post))}")
+
+    if post.event_status in STATUS_LABELS:
+        lines.append(escape_markdown_v2(f"❌ {STATUS_LABELS[post.event_status]}"))
+
+    warnings = Admission.from_post(post).warnings()
+    if warnings:
+        lines.append(escape_markdown_v2(f"⚠️ {', '.join(warnings).capitalize()}"))
+
+    if post.summary:
+        lines.append(f"\n{escape_markdown_v2(post.summary)}")
+    elif post.content:
         # Truncate long content for Telegram
         content = post.content[:300] + "..." if len(post.content) > 300 else post.content
         content = escape_markdown_v2(content)
@@ -280,67 +340,173 @@ def create_digest(posts: List[RSSPost]) -> str:
     return "\n".join(lines)
 
 
-async def publish_to_telegram(message: str):
+def route_posts(posts: List[RSSPost], rules: RuleSet) -> Dict[Tuple[str, str], List[RSSPost]]:
     """
-    Publish message to Telegram.
+    Group posts by publisher target according to routing rules.
+
+    Posts that match no route go to the default Telegram chat.
 
     Args:
-        message: Message to publish (plain text, no markdown)
+        posts: List of RSSPost objects
+        rules: Loaded rules with routes
+
+    Returns:
+        Dict of (publisher, destination) -> posts, in order of first appearance
+    """
+    groups: Dict[Tuple[str, str], List[RSSPost]] = defaultdict(list)
+    for post in posts:
+        variables = post_variables(
+            channel_from_link(post.link),
+            post.link,
+            post.content,
+            post.pub_date,
+            tags=post.tags,
+            event_format=post.event_format,
+            categories=post.categories,
+        )
+        route = rules.route(variables)
+        if route:
+            destination = route.destination or get_publisher(route.publisher).default_destination
+            target = (route.publisher, destination)
+        else:
+            target = (DEFAULT_PUBLISHER, get_publisher(DEFAULT_PUBLISHER).default_destination)
+        groups[target].append(post)
+    return dict(groups)
 
-    Raises:
-        ValueError: If bot token or chat ID not configured
-        TelegramError: If sending message fails
+
+async def get_unpublished_posts(start_date: datetime, end_date: datetime) -> List[RSSPost]:
     """
-    bot_token = digest_publisher_settings.telegram_bot_token
-    chat_id = digest_publisher_settings.telegram_chat_id
+    Load unpublished posts of a date range.
 
-    if not bot_token:
-        logger.warning("TELEGRAM_BOT_TOKEN not set, printing to console instead")
-        print("\n" + "=" * 80)
-        print("TELEGRAM DIGEST (BOT TOKEN NOT CONFIGURED)")
-        print("=" * 80)
-        print(message)
-        print("=" * 80)
-        return
+    Posts held under an embargo count from their release time instead of
+    their publication date, which may be weeks before the range.
 
-    if not chat_id:
-        raise ValueError("TELEGRAM_CHAT_ID environment variable is required")
+    Returns:
+        Posts published in the range, latest first, then the ones released in it
+    """
+    posts = await RSSPostRepository.get_by_date_range(start_date, end_date)
+    loaded = {post.link for post in posts}
+    released = await RSSPostRepository.get_released(start_date, end_date)
+    return posts + [post for post in released if post.link not in loaded]
 
-    try:
-        bot = Bot(token=bot_token)
-
-        # Split message if it exceeds Telegram's limit (4096 characters)
-        max_length = 4000  # Leave some margin
-        if len(message) <= max_length:
-            await bot.send_message(
-                chat_id=chat_id,
-                text=message,
-                parse_mode=ParseMode.HTML,
-                disable_web_page_preview=True,
-            )
-            logger.info(f"Successfully sent digest to Telegram chat {chat_id}")
+
+async def get_active_promotions(at: datetime) -> List[Tuple[Promotion, RSSPost]]:
+    """
+    Load running promotions together with their posts.
+
+    Args:
+        at: Point in time
+
+    Returns:
+        List of (promotion, post) pairs in priority order
+    """
+    active = []
+    for promotion in await PromotionRepository.get_active(at):
+        post = await RSSPostRepository.get_by_link(promotion.post_link)
+        if post:
+            active.append((promotion, post))
         else:
-            # Split into multiple messages
-            parts = [message[i : i + max_length] for i in range(0, len(message), max_length)]
-            for i, part in enumerate(parts, 1):
-                await bot.send_message(
-                    chat_id=chat_id,
-                    text=part,
-                    parse_mode=ParseMode.HTML,
-                    disable_web_page_preview=True,
-                )
-                logger.info(f"Sent part {i}/{len(parts)} to Telegram")
-                # Small delay between messages
-                if i < len(parts):
-                    await asyncio.sleep(0.5)
-
-    except NetworkError as e:
-        logger.error(f"Network error connecting to Telegram: {e}")
-        logger.error("Check your internet connection, proxy settings, or firewall")
-        raise
-    except TelegramError as e:
-        logger.error(f"Failed to send message to Telegram: {e}")
-        raise
+            logger.warning(f"Promotion {promotion.id} refers to missing post {promotion.post_link}")
+    return active
+
+
+def finish_digest(
+    digest: str, sponsored: List[Promotion], promoted_posts: Dict[str, RSSPost], target: str
+) -> str:
+    """Add sponsored blocks to a digest and mask it for its target."""
+    digest = apply_promotions(
+        digest,
+        [(promotion, promoted_posts[promotion.post_link]) for promotion in sponsored],
+        digest_publisher_settings.promotions_label,
+    )
+    # Stored posts stay intact; only the published copy is masked
+    return sanitize_for(target, digest, html=True)
+
+
+async def record_digest(links: List[str], sponsored: List[Promotion], target: str) -> None:
+    """Mark the posts of a sent digest as published and bill its placements."""
+    updated_count = await RSSPostRepository.mark_as_published(links)
+    logger.info(f"Marked {updated_count} posts as published")
+
+    # Placements are what sponsors are billed for
+    for promotion in sponsored:
+        await PromotionRepository.record_placement(promotion.id, target)
+    if sponsored:
+        await RSSPostRepository.mark_as_published([p.post_link for p in sponsored])
+
+
+def format_post_html(post: RSSPost) -> str:
+    """
+    Format a single post as a Telegram HTML message, for posts published outside a digest.
+
+    Args:
+        post: RSSPost object
+
+    Returns:
+        HTML message
+    """
+    title = post.title or post.content.split("\n")[0][:100]
+    lines = [f"⚡ <b>{escape(title)}</b>"]
+    if post.event_start:
+        lines.append(f"📅 {escape(event_when(post))}")
+    if post.event_status in STATUS_LABELS:
+        lines.append(f"❌ {escape(STATUS_LABELS[post.event_status])}")
+    lines.append("")
+    lines.append(escape(post.summary or post.content))
+    lines.append(f'<a href="{escape(post.link, quote=True)}">Подробнее</a>')
+    return "\n".join(lines)
+
+
+async def publish_now(links: List[str]) -> Dict[str, int]:
+    """
+    Publish posts one message each, right away, instead of waiting for the next digest.
+
+    Used by the priority lane. Posts go to their routing targets and are marked
+    published, so the digest doesn't repeat them.
+
+    Args:
+        links: Links of the posts to publish; published or missing ones are skipped
+
+    Returns:
+        Dict with 'published_count'
+    """
+    rules = load_rules()
+    retried = await retry_due()
+    if retried:
+        logger.info(f"Sent {retried} queued messages")
+
+    queued = await queued_links()
+    posts = []
+    for link in links:
+        post = await RSSPostRepository.get_by_link(link)
+        if post and not post.is_published and post.link not in queued:
+            posts.append(post)
+    # Held posts stay unpublished and go out with a digest after the embargo
+    posts, held = hold_embargoed(posts, rules, clock.now())
+    for post in held:
+        await RSSPostRepository.set_embargo(post.link, post.embargo_until)
+
+    published_count = 0
+    failed_targets = []
+    for (publisher_name, destination), group in route_posts(posts, rules).items():
+        publisher = get_publisher(publisher_name)
+        target = publisher.label(destination)
+        for post in group:
+            message = sanitize_for(target, format_post_html(post), html=True)
+            try:
+                await publisher.publish(message, destination)
+            except Exception as e:
+                logger.error(f"Failed to publish {post.link} to {target}: {e}", exc_info=True)
+                await queue_failure(publisher_name, destination, message, [post.link], [], e)
+                failed_targets.append(target)
+                break
+            await RSSPostRepository.mark_as_published([post.link])
+            published_count += 1
+
+    logger.info(f"Published {published_count} priority posts")
+    if failed_targets:
+        raise RuntimeError(f"Failed to publish to: {', '.join(failed_targets)}")
+    return {"published_count": published_count}
 
 
 async def main():
@@ -352,15 +518,40 @@ async def main():
             await db.connect()
             logger.info("Connected to database")
 
-        # Initialize OpenAI client
-        client = AsyncOpenAI(api_key=digest_publisher_settings.openai_api_key)
+        # Load routing rules and make sure every target publisher exists
+        rules = load_rules()
+        for route in rules.routes:
+            get_publisher(route.publisher)
+
+        # Messages that failed before go out first
+        retried = await retry_due()
+        if retried:
+            logger.info(f"Sent {retried} queued messages")
 
         # Get posts from the configured time range
-        end_date = datetime.now()
+        end_date = clock.now()
         start_date = end_date - timedelta(days=digest_publisher_settings.days_back)
 
         logger.info(f"Fetching posts from {start_date} to {end_date}")
-        posts = await RSSPostRepository.get_by_date_range(start_date, end_date)
+        posts = await get_unpublished_posts(start_date, end_date)
+
+        # Posts of queued messages go out when those are retried
+        queued = await queued_links()
+        posts = [post for post in posts if post.link not in queued]
+
+        # Sponsored posts are placed verbatim instead of being summarized
+        promotions = await get_active_promotions(end_date)
+        promoted_posts = {promotion.post_link: post for promotion, post in promotions}
+        posts = [post for post in posts if post.link not in promoted_posts]
+        if promotions:
+            logger.info(f"Found {len(promotions)} active promotions")
+
+        # Posts under embargo stay unpublished until their release time
+        posts, held = hold_embargoed(posts, rules, end_date)
+        for post in held:
+            await RSSPostRepository.set_embargo(post.link, post.embargo_until)
+        if held:
+            logger.info(f"Holding {len(held)} posts under embargo")
 
         if not posts:
             logger.info("No recent posts found")
@@ -374,20 +565,69 @@ async def main():
             )
             posts = posts[: digest_publisher_settings.max_posts]
 
-        # Generate AI digest
-        digest = await generate_ai_digest(posts, client)
+        if digest_publisher_settings.review:
+            # Imported here: drafts builds on this module
+            from .drafts import create_drafts
+
+            draft_count = await create_drafts(posts, rules)
+            logger.info(f"Created {draft_count} digest drafts for review")
+            return {"published_count": 0, "draft_count": draft_count}
+
+        # Initialize OpenAI client
+        client = AsyncOpenAI(
+            api_key=digest_publisher_settings.openai_api_key,
+            http_client=tracking_http_client("openai"),
+        )
+
+        # One digest per routing target
+        groups = route_posts(posts, rules)
+        published_count = 0
+        failed_targets = []
+
+        for (publisher_name, destination), group in groups.items():
+            publisher = get_publisher(publisher_name)
+            target = publisher.label(destination)
+            logger.info(f"Publishing {len(group)} posts to {target}")
+            sponsored = select_promotions(
+                [promotion for promotion, _ in promotions],
+                target,
+                digest_publisher_settings.promotions_max_per_digest,
+            )
+            try:
+                # Generate AI digest
+                digest = await generate_ai_digest(group, client)
+                digest = finish_digest(digest, sponsored, promoted_posts, target)
+            except Exception as e:
+                logger.error(f"Failed to generate digest for {target}: {e}", exc_info=True)
+                failed_targets.append(target)
+                continue
+
+            links = [post.link for post in group]
+            try:
+                await publisher.publish(digest, destination)
+            except Exception as e:
+                logger.error(f"Failed to publish digest to {target}: {e}", exc_info=True)
+                # The generated digest is kept, so a retry sends the same message
+                await queue_failure(
+                    publisher_name,
+                    destination,
+                    digest,
+                    links + [promotion.post_link for promotion in sponsored],
+                    [promotion.id for promotion in sponsored],
+                    e,
+                )
+                failed_targets.append(target)
+                continue
 
-        # Publish to Telegram
-        await publish_to_telegram(digest)
+            await record_digest(links, sponsored, target)
+            published_count += len(group)
 
-        # Mark posts as published after successful publication
-        post_links = [post.link for post in posts]
-        updated_count = await RSSPostRepository.mark_as_published(post_links)
-        logger.info(f"Marked {updated_count} posts as published")
+        if failed_targets:
+            raise RuntimeError(f"Failed to publish to: {', '.join(failed_targets)}")
 
-        logger.info(f"Successfully published AI digest with {len(posts)} posts")
+        logger.info(f"Successfully published AI digests with {published_count} posts")
 
-        return {"published_count": len(posts)}
+        return {"published_count": published_count}
 
     except ValueError as e:
         # Handle configuration errors
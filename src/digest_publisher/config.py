@@ -3,6 +3,7 @@
 import os
 from dataclasses import dataclass
 from pathlib import Path
+from typing import List
 from dotenv import load_dotenv
 
 # Load .env file if it exists
@@ -25,9 +26,33 @@ class DigestPublisherConfig:
     telegram_bot_token: str = os.getenv("TELEGRAM_BOT_TOKEN", "")
     telegram_chat_id: str = os.getenv("TELEGRAM_CHAT_ID", "")
 
+    # Webhook publishers
+    slack_webhook_url: str = os.getenv("SLACK_WEBHOOK_URL", "")
+    discord_webhook_url: str = os.getenv("DISCORD_WEBHOOK_URL", "")
+    webhook_timeout: int = int(os.getenv("DIGEST_PUBLISHER_WEBHOOK_TIMEOUT", "10"))
+
+    # Matrix publisher
+    matrix_homeserver_url: str = os.getenv("MATRIX_HOMESERVER_URL", "")
+    matrix_access_token: str = os.getenv("MATRIX_ACCESS_TOKEN", "")
+    matrix_room_id: str = os.getenv("MATRIX_ROOM_ID", "")
+
     # Digest settings
     days_back: int = int(os.getenv("DIGEST_PUBLISHER_DAYS_BACK", "7"))
     max_posts: int = int(os.getenv("DIGEST_PUBLISHER_MAX_POSTS", "50"))
+    # Hold digests as drafts for moderators to curate and send through the API
+    review: bool = os.getenv("DIGEST_REVIEW", "false").lower() == "true"
+
+    # Sponsored posts
+    promotions_max_per_digest: int = int(os.getenv("PROMOTIONS_MAX_PER_DIGEST", "1"))
+    promotions_label: str = os.getenv("PROMOTIONS_LABEL", "Реклама")
+
+    # Failed publishes: minutes before each retry, then only retried by hand
+    publish_retry_minutes: str = os.getenv("PUBLISH_RETRY_MINUTES", "5,30,120,720")
+
+    @property
+    def retry_delays(self) -> List[int]:
+        """Minutes to wait before each retry of a failed publish."""
+        return [int(m) for m in self.publish_retry_minutes.split(",") if m.strip()]
 
     def validate(self) -> bool:
         """
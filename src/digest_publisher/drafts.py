@@ -0,0 +1,152 @@
+"""Digests held for moderators.
+
+With DIGEST_REVIEW=true the publisher doesn't send digests: it stores one
+draft per routing target, and moderators curate it through the API before
+it goes out. A draft moves through these statuses:
+
+- draft: posts can be reordered or removed and an intro note added
+- locked: the final message is rendered and stored, ready to be sent
+- sent or discarded
+
+Curated digests are rendered post by post, like the priority lane, instead
+of being summarized by the AI: the summary regroups posts by day and would
+not keep the order the moderator chose. Sponsored posts are placed when a
+draft is sent, so the promotions running at that moment are billed.
+"""
+
+import logging
+from html import escape
+from typing import List
+
+from common import clock
+from common.db.models import DigestDraft, RSSPost
+from common.db.repository import DigestDraftRepository, RSSPostRepository
+from common.rules import RuleSet
+from .__main__ import (
+    finish_digest,
+    format_post_html,
+    get_active_promotions,
+    record_digest,
+    route_posts,
+)
+from .config import digest_publisher_settings
+from .promotions import select_promotions
+from .publishers import get_publisher
+
+logger = logging.getLogger(__name__)
+
+# Drafts whose posts are not available for new drafts
+OPEN_STATUSES = ["draft", "locked"]
+
+MAX_INTRO_LENGTH = 1000
+
+
+async def create_drafts(posts: List[RSSPost], rules: RuleSet) -> int:
+    """
+    Store a draft per routing target of the posts.
+
+    Args:
+        posts: Posts of the digest
+        rules: Routing rules
+
+    Returns:
+        Number of drafts created; posts already in an open draft are left out
+    """
+    drafted = set()
+    for draft in await DigestDraftRepository.get_by_status(OPEN_STATUSES):
+        drafted.update(draft.links)
+
+    count = 0
+    for (publisher_name, destination), group in route_posts(posts, rules).items():
+        links = [post.link for post in group if post.link not in drafted]
+        if not links:
+            continue
+        draft = DigestDraft(publisher=publisher_name, destination=destination, links=links)
+        draft_id = await DigestDraftRepository.create(draft)
+        logger.info(f"Created draft #{draft_id} with {len(links)} posts for {publisher_name}")
+        count += 1
+    return count
+
+
+async def load_posts(draft: DigestDraft) -> List[RSSPost]:
+    """Posts of a draft in its order, without ones deleted or published meanwhile."""
+    posts = []
+    for link in draft.links:
+        post = await RSSPostRepository.get_by_link(link)
+        if post and not post.is_published:
+            posts.append(post)
+    return posts
+
+
+def render_digest(intro: str, posts: List[RSSPost]) -> str:
+    """Render a curated digest as a Telegram HTML message."""
+    blocks = [escape(intro)] if intro else []
+    blocks.extend(format_post_html(post) for post in posts)
+    return "\n\n".join(blocks)
+
+
+async def preview(draft: DigestDraft) -> str:
+    """The message a draft would be sent as."""
+    if draft.text is not None:
+        return draft.text
+    return render_digest(draft.intro, await load_posts(draft))
+
+
+async def lock(draft: DigestDraft) -> bool:
+    """
+    Render a draft and lock it for sending.
+
+    Returns:
+        False if the draft was changed by someone else meanwhile
+
+    Raises:
+        ValueError: If none of the draft's posts can be published anymore
+    """
+    posts = await load_posts(draft)
+    if not posts:
+        raise ValueError(f"Draft #{draft.id} has no posts left to publish")
+    text = render_digest(draft.intro, posts)
+    return await DigestDraftRepository.set_status(draft.id, "locked", "draft", text)
+
+
+async def send(draft: DigestDraft) -> bool:
+    """
+    Publish a locked draft and mark its posts as published.
+
+    Returns:
+        False if the draft was sent or discarded by someone else meanwhile
+    """
+    # Claimed before publishing, so two moderators can't send it twice
+    if not await DigestDraftRepository.set_status(draft.id, "sent", "locked"):
+        return False
+
+    publisher = get_publisher(draft.publisher)
+    target = publisher.label(draft.destination)
+    promotions = await get_active_promotions(clock.now())
+    promoted_posts = {promotion.post_link: post for promotion, post in promotions}
+    sponsored = select_promotions(
+        [promotion for promotion, _ in promotions],
+        target,
+        digest_publisher_settings.promotions_max_per_digest,
+    )
+    try:
+        message = finish_digest(draft.text, sponsored, promoted_posts, target)
+        await publisher.publish(message, draft.destination)
+    except Exception:
+        # Back to locked, so the moderator can send it again
+        await DigestDraftRepository.set_status(draft.id, "locked", "sent")
+        raise
+
+    await record_digest(draft.links, sponsored, target)
+    logger.info(f"Sent draft #{draft.id} to {target}")
+    return True
+
+
+async def discard(draft: DigestDraft) -> bool:
+    """
+    Drop a draft; its posts can go into the next digest.
+
+    Returns:
+        False if the draft was changed by someone else meanwhile
+    """
+    return await DigestDraftRepository.set_status(draft.id, "discarded", draft.status)
@@ -0,0 +1,203 @@
+"""Convert Telegram HTML digests to other platforms' formats.
+
+Digests are generated as Telegram HTML (<b>, <i>, <a href>). Slack and
+Discord use their own markdown dialects and structured payloads, so the
+digest is converted and split into Slack blocks / Discord embeds here.
+Matrix accepts the same HTML tags but needs explicit line breaks and a
+plain text fallback.
+"""
+
+import re
+from html import unescape
+from typing import Callable, List
+
+TAG_PATTERN = re.compile(
+    r"<(/?)(b|strong|i|em|u|s|code|pre|a)(?:\s+href=\"([^\"]*)\")?\s*>", re.IGNORECASE
+)
+
+# Slack limits: 3000 characters per section text, 50 blocks per message
+SLACK_SECTION_LIMIT = 3000
+SLACK_MAX_BLOCKS = 50
+
+# Discord limits: 4096 characters per embed description, 10 embeds and
+# 6000 characters of embed text per message
+DISCORD_EMBED_LIMIT = 4096
+DISCORD_MAX_EMBEDS = 10
+DISCORD_MESSAGE_LIMIT = 6000
+DISCORD_EMBED_COLOR = 0x2B88D8
+
+
+def _convert(
+    html: str, marks: dict, link: Callable[[str, str], str], text: Callable[[str], str]
+) -> str:
+    """Replace supported HTML tags using per-platform markers.
+
+    Args:
+        html: Telegram HTML
+        marks: Tag name -> platform marker (used for both opening and closing tags)
+        link: Builds a platform link from (url, converted text)
+        text: Converts unescaped plain text for the platform
+    """
+    result = []
+    link_stack = []
+    position = 0
+
+    def add_text(segment: str) -> None:
+        # Drop unsupported tags, decode entities, then apply platform escaping
+        result.append(text(unescape(re.sub(r"<[^>]+>", "", segment))))
+
+    for match in TAG_PATTERN.finditer(html):
+        add_text(html[position : match.start()])
+        position = match.end()
+        closing, tag, href = match.group(1), match.group(2).lower(), match.group(3)
+
+        if tag == "a":
+            if not closing:
+                link_stack.append((len(result), unescape(href or "")))
+            elif link_stack:
+                start, url = link_stack.pop()
+                label = "".join(result[start:])
+                del result[start:]
+                result.append(link(url, label))
+            continue
+
+        result.append(marks.get(tag, ""))
+
+    add_text(html[position:])
+    return "".join(result)
+
+
+def slack_escape(text: str) -> str:
+    """Escape the characters Slack mrkdwn treats as control characters."""
+    return text.replace("&", "&amp;").replace("<", "&lt;").replace(">", "&gt;")
+
+
+def html_to_slack(html: str) -> str:
+    """Convert Telegram HTML to Slack mrkdwn."""
+    marks = {"b": "*", "strong": "*", "i": "_", "em": "_", "s": "~", "code": "`", "pre": "```"}
+    return _convert(
+        html, marks, lambda url, label: f"<{url}|{label}>" if url else label, slack_escape
+    )
+
+
+def html_to_discord(html: str) -> str:
+    """Convert Telegram HTML to Discord markdown."""
+    marks = {
+        "b": "**",
+        "strong": "**",
+        "i": "*",
+        "em": "*",
+        "u": "__",
+        "s": "~~",
+        "code": "`",
+        "pre": "```",
+    }
+    return _convert(
+        html, marks, lambda url, label: f"[{label}]({url})" if url else label, lambda t: t
+    )
+
+
+def html_to_plain(html: str) -> str:
+    """Convert Telegram HTML to plain text, keeping link URLs."""
+    return _convert(
+        html, {}, lambda url, label: f"{label} ({url})" if url and url != label else label, str
+    )
+
+
+def html_to_matrix(html: str) -> str:
+    """Convert Telegram HTML to Matrix formatted_body HTML (newlines become <br>)."""
+    return html.replace("\n", "<br>\n")
+
+
+def split_text(text: str, limit: int) -> List[str]:
+    """
+    Split text into chunks no longer than limit, preferring paragraph breaks.
+
+    Args:
+        text: Text to split
+        limit: Maximum chunk length
+
+    Returns:
+        List of non-empty chunks
+    """
+    chunks: List[str] = []
+    current = ""
+    for paragraph in text.split("\n\n"):
+        while len(paragraph) > limit:
+            if current:
+                chunks.append(current)
+                current = ""
+            cut = paragraph.rfind("\n", 0, limit)
+            cut = cut if cut > 0 else limit
+            chunks.append(paragraph[:cut])
+            paragraph = paragraph[cut:].lstrip("\n")
+
+        candidate = f"{current}\n\n{paragraph}" if current else paragraph
+        if len(candidate) > limit:
+            chunks.append(current)
+            current = paragraph
+        else:
+            current = candidate
+
+    if current.strip():
+        chunks.append(current)
+    return [chunk for chunk in chunks if chunk.strip()]
+
+
+def slack_blocks(html: str, title: str = "News Digest") -> List[List[dict]]:
+    """
+    Build Slack Block Kit messages for a digest.
+
+    Args:
+        html: Digest in Telegram HTML
+        title: Header text
+
+    Returns:
+        List of block lists, one per message to send
+    """
+    sections = [
+        {"type": "section", "text": {"type": "mrkdwn", "text": chunk}}
+        for chunk in split_text(html_to_slack(html), SLACK_SECTION_LIMIT)
+    ]
+    messages = []
+    per_message = SLACK_MAX_BLOCKS - 1
+    for i in range(0, len(sections), per_message):
+        blocks = sections[i : i + per_message]
+        if i == 0:
+            blocks = [{"type": "header", "text": {"type": "plain_text", "text": title}}] + blocks
+        messages.append(blocks)
+    return messages
+
+
+def discord_embeds(html: str, title: str = "News Digest") -> List[List[dict]]:
+    """
+    Build Discord embeds for a digest.
+
+    Args:
+        html: Digest in Telegram HTML
+        title: Title of the first embed
+
+    Returns:
+        List of embed lists, one per message to send
+    """
+    embeds = [
+        {"description": chunk, "color": DISCORD_EMBED_COLOR}
+        for chunk in split_text(html_to_discord(html), DISCORD_EMBED_LIMIT)
+    ]
+    if embeds:
+        embeds[0]["title"] = title
+
+    messages: List[List[dict]] = []
+    size = 0
+    for embed in embeds:
+        embed_size = len(embed["description"]) + len(embed.get("title", ""))
+        if (
+            not messages
+            or len(messages[-1]) >= DISCORD_MAX_EMBEDS
+            or size + embed_size > DISCORD_MESSAGE_LIMIT
+        ):
+            messages.append([])
+            size = 0
+        messages[-1].append(embed)
+        size += embed_size
+    return messages
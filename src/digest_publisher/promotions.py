@@ -0,0 +1,69 @@
+"""Placement of sponsored posts in digests.
+
+Sponsored posts are not passed to the AI summarizer: they are rendered
+verbatim with an advertising label and placed at the top or bottom of the
+digest, so sponsors get exactly the placement they paid for.
+"""
+
+from html import escape
+from typing import List, Tuple
+
+from common.db.models import Promotion, RSSPost
+
+MAX_PROMOTION_LENGTH = 500
+
+
+def select_promotions(promotions: List[Promotion], target: str, limit: int) -> List[Promotion]:
+    """
+    Pick promotions for a digest target.
+
+    Args:
+        promotions: Active promotions, in priority order
+        target: 'publisher:destination' of the digest
+        limit: Maximum number of sponsored posts per digest
+
+    Returns:
+        Promotions to place (untargeted ones and ones targeting this output)
+    """
+    eligible = [p for p in promotions if not p.target or p.target == target]
+    return eligible[: max(limit, 0)]
+
+
+def render_promotion(promotion: Promotion, post: RSSPost, label: str) -> str:
+    """
+    Render a sponsored post as a Telegram HTML block.
+
+    Args:
+        promotion: Promotion being placed
+        post: The promoted post
+        label: Advertising label, e.g. 'Реклама'
+
+    Returns:
+        HTML block
+    """
+    content = post.content or ""
+    if len(content) > MAX_PROMOTION_LENGTH:
+        content = content[:MAX_PROMOTION_LENGTH].rstrip() + "..."
+
+    return (
+        f"📢 <b>{escape(label)}</b> · {escape(promotion.sponsor)}\n"
+        f"{escape(content)}\n"
+        f'<a href="{escape(post.link, quote=True)}">Подробнее</a>'
+    )
+
+
+def apply_promotions(digest: str, placed: List[Tuple[Promotion, RSSPost]], label: str) -> str:
+    """
+    Insert sponsored blocks into a digest according to their placement.
+
+    Args:
+        digest: Generated digest
+        placed: (promotion, post) pairs to place
+        label: Advertising label
+
+    Returns:
+        Digest with sponsored blocks
+    """
+    top = [render_promotion(p, post, label) for p, post in placed if p.placement == "top"]
+    bottom = [render_promotion(p, post, label) for p, post in placed if p.placement != "top"]
+    return "\n\n".join(top + [digest] + bottom)
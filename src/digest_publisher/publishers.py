@@ -0,0 +1,318 @@
+"""Publisher targets for digests.
+
+A publisher delivers a digest message to a destination on some platform
+(a Telegram chat, a Slack channel, ...). Routing rules pick the publisher by
+name, so every publisher registered in PUBLISHERS can be used as a route
+target.
+"""
+
+import asyncio
+import hashlib
+import logging
+from typing import Dict, Type
+from urllib.parse import quote
+
+import requests
+from telegram import Bot
+from telegram.constants import ParseMode
+from telegram.error import TelegramError, NetworkError, RetryAfter
+
+from common.quotas import throttle, tracker
+from .config import digest_publisher_settings
+from .formatting import (
+    discord_embeds,
+    html_to_matrix,
+    html_to_plain,
+    slack_blocks,
+    split_text,
+)
+
+logger = logging.getLogger(__name__)
+
+
+class Publisher:
+    """Base class for digest publishers."""
+
+    name = ""
+
+    @property
+    def default_destination(self) -> str:
+        """Destination used for posts that don't match any route."""
+        return ""
+
+    def label(self, destination: str) -> str:
+        """Human-readable 'publisher:destination' used in logs and promotion targets."""
+        return f"{self.name}:{destination or 'default'}"
+
+    async def publish(self, message: str, destination: str) -> None:
+        """
+        Publish a digest.
+
+        Args:
+            message: Digest text (Telegram HTML)
+            destination: Platform-specific destination (chat ID, channel, ...)
+        """
+        raise NotImplementedError
+
+
+class TelegramPublisher(Publisher):
+    """Publish digests to Telegram chats via the Bot API."""
+
+    name = "telegram"
+
+    @property
+    def default_destination(self) -> str:
+        return digest_publisher_settings.telegram_chat_id
+
+    async def publish(self, message: str, destination: str) -> None:
+        """
+        Publish message to Telegram.
+
+        Args:
+            message: Message to publish (Telegram HTML)
+            destination: Telegram chat/channel ID
+
+        Raises:
+            ValueError: If chat ID not configured
+            TelegramError: If sending message fails
+            QuotaExhausted: If flood control won't let messages through soon enough
+        """
+        bot_token = digest_publisher_settings.telegram_bot_token
+        chat_id = destination
+
+        if not bot_token:
+            logger.warning("TELEGRAM_BOT_TOKEN not set, printing to console instead")
+            print("\n" + "=" * 80)
+            print(f"TELEGRAM DIGEST FOR {chat_id or 'DEFAULT CHAT'} (BOT TOKEN NOT CONFIGURED)")
+            print("=" * 80)
+            print(message)
+            print("=" * 80)
+            return
+
+        if not chat_id:
+            raise ValueError("TELEGRAM_CHAT_ID environment variable is required")
+
+        try:
+            bot = Bot(token=bot_token)
+
+            # Split message if it exceeds Telegram's limit (4096 characters)
+            max_length = 4000  # Leave some margin
+            if len(message) <= max_length:
+                await throttle(self.name)
+                await bot.send_message(
+                    chat_id=chat_id,
+                    text=message,
+                    parse_mode=ParseMode.HTML,
+                    disable_web_page_preview=True,
+                )
+                logger.info(f"Successfully sent digest to Telegram chat {chat_id}")
+            else:
+                # Split into multiple messages
+                parts = [message[i : i + max_length] for i in range(0, len(message), max_length)]
+                for i, part in enumerate(parts, 1):
+                    await throttle(self.name)
+                    await bot.send_message(
+                        chat_id=chat_id,
+                        text=part,
+                        parse_mode=ParseMode.HTML,
+                        disable_web_page_preview=True,
+                    )
+                    logger.info(f"Sent part {i}/{len(parts)} to Telegram")
+                    # Small delay between messages
+                    if i < len(parts):
+                        await asyncio.sleep(0.5)
+
+        except RetryAfter as e:
+            # Flood control: later sends wait until Telegram accepts them again
+            tracker.block(self.name, e.retry_after)
+            logger.error(f"Telegram flood control, retry in {e.retry_after}: {e}")
+            raise
+        except NetworkError as e:
+            logger.error(f"Network error connecting to Telegram: {e}")
+            logger.error("Check your internet connection, proxy settings, or firewall")
+            raise
+        except TelegramError as e:
+            logger.error(f"Failed to send message to Telegram: {e}")
+            raise
+
+
+class WebhookPublisher(Publisher):
+    """Base for publishers that POST JSON payloads to an incoming webhook."""
+
+    def build_payloads(self, message: str) -> list:
+        """Convert a digest into the JSON payloads to post, in order."""
+        raise NotImplementedError
+
+    def label(self, destination: str) -> str:
+        # Webhook URLs are credentials, so never log or store them
+        if not destination or destination == self.default_destination:
+            return f"{self.name}:default"
+        return f"{self.name}:{hashlib.sha256(destination.encode()).hexdigest()[:10]}"
+
+    async def publish(self, message: str, destination: str) -> None:
+        """
+        Publish message to a webhook.
+
+        Args:
+            message: Message to publish (Telegram HTML)
+            destination: Webhook URL
+
+        Raises:
+            ValueError: If no webhook URL is configured
+            requests.HTTPError: If the webhook rejects a payload
+            QuotaExhausted: If the webhook's rate limit won't reset soon enough
+        """
+        if not destination:
+            raise ValueError(f"No webhook URL configured for {self.name} publisher")
+
+        payloads = self.build_payloads(message)
+        for i, payload in enumerate(payloads, 1):
+            await throttle(self.name)
+            response = await asyncio.to_thread(
+                requests.post,
+                destination,
+                json=payload,
+                timeout=digest_publisher_settings.webhook_timeout,
+            )
+            tracker.observe(self.name, response.headers)
+            response.raise_for_status()
+            logger.info(f"Sent part {i}/{len(payloads)} to {self.name}")
+            # Stay well below webhook rate limits
+            if i < len(payloads):
+                await asyncio.sleep(1)
+
+
+class SlackPublisher(WebhookPublisher):
+    """Publish digests to Slack incoming webhooks using Block Kit."""
+
+    name = "slack"
+
+    @property
+    def default_destination(self) -> str:
+        return digest_publisher_settings.slack_webhook_url
+
+    def build_payloads(self, message: str) -> list:
+        # `text` is the notification fallback shown where blocks can't be rendered
+        return [{"text": "News Digest", "blocks": blocks} for blocks in slack_blocks(message)]
+
+
+class DiscordPublisher(WebhookPublisher):
+    """Publish digests to Discord webhooks using embeds."""
+
+    name = "discord"
+
+    @property
+    def default_destination(self) -> str:
+        return digest_publisher_settings.discord_webhook_url
+
+    def build_payloads(self, message: str) -> list:
+        return [{"embeds": embeds} for embeds in discord_embeds(message)]
+
+
+class MatrixPublisher(Publisher):
+    """Publish digests to Matrix rooms via the client-server API."""
+
+    name = "matrix"
+
+    # Events are limited to 64 KiB; keep each message well below that
+    max_length = 20000
+
+    @property
+    def default_destination(self) -> str:
+        return digest_publisher_settings.matrix_room_id
+
+    def _request(self, method: str, path: str, **kwargs) -> dict:
+        response = requests.request(
+            method,
+            f"{digest_publisher_settings.matrix_homeserver_url.rstrip('/')}{path}",
+            headers={"Authorization": f"Bearer {digest_publisher_settings.matrix_access_token}"},
+            timeout=digest_publisher_settings.webhook_timeout,
+            **kwargs,
+        )
+        response.raise_for_status()
+        return response.json()
+
+    def resolve_room(self, room: str) -> str:
+        """Resolve a room alias like '#events:matrix.org' to a room ID."""
+        if not room.startswith("#"):
+            return room
+        data = self._request("GET", f"/_matrix/client/v3/directory/room/{quote(room)}")
+        return data["room_id"]
+
+    def build_messages(self, message: str) -> list:
+        """Convert a digest into m.room.message event contents."""
+        return [
+            {
+                "msgtype": "m.text",
+                "body": html_to_plain(part),
+                "format": "org.matrix.custom.html",
+                "formatted_body": html_to_matrix(part),
+            }
+            for part in split_text(message, self.max_length)
+        ]
+
+    @staticmethod
+    def transaction_id(room_id: str, message: str, part: int) -> str:
+        """Transaction ID of a part of a message, the same each time it's sent."""
+        return hashlib.sha256(f"{room_id}\n{part}\n{message}".encode()).hexdigest()[:32]
+
+    def _send(self, room_id: str, content: dict, txn_id: str) -> None:
+        # The homeserver sends an event once per transaction ID, so resending a
+        # message, e.g. by the recovery queue, doesn't repeat the parts that went out
+        path = f"/_matrix/client/v3/rooms/{quote(room_id)}/send/m.room.message/{txn_id}"
+        self._request("PUT", path, json=content)
+
+    async def publish(self, message: str, destination: str) -> None:
+        """
+        Publish message to a Matrix room.
+
+        Args:
+            message: Message to publish (Telegram HTML)
+            destination: Room ID ('!abc:server') or alias ('#room:server')
+
+        Raises:
+            ValueError: If the homeserver, token or room is not configured
+            requests.HTTPError: If the homeserver rejects the request
+        """
+        settings = digest_publisher_settings
+        if not settings.matrix_homeserver_url or not settings.matrix_access_token:
+            raise ValueError("MATRIX_HOMESERVER_URL and MATRIX_ACCESS_TOKEN are required")
+        if not destination:
+            raise ValueError("MATRIX_ROOM_ID environment variable is required")
+
+        room_id = await asyncio.to_thread(self.resolve_room, destination)
+        contents = self.build_messages(message)
+        for i, content in enumerate(contents, 1):
+            txn_id = self.transaction_id(room_id, message, i)
+            await asyncio.to_thread(self._send, room_id, content, txn_id)
+            logger.info(f"Sent part {i}/{len(contents)} to Matrix room {destination}")
+            if i < len(contents):
+                await asyncio.sleep(0.5)
+
+
+PUBLISHERS: Dict[str, Type[Publisher]] = {
+    TelegramPublisher.name: TelegramPublisher,
+    SlackPublisher.name: SlackPublisher,
+    DiscordPublisher.name: DiscordPublisher,
+    MatrixPublisher.name: MatrixPublisher,
+}
+
+DEFAULT_PUBLISHER = TelegramPublisher.name
+
+
+def get_publisher(name: str) -> Publisher:
+    """
+    Create a publisher by name.
+
+    Args:
+        name: Publisher name as used in routing rules
+
+    Returns:
+        Publisher instance
+
+    Raises:
+        ValueError: If no publisher is registered under that name
+    """
+    if name not in PUBLISHERS:
+        raise ValueError(f"Unknown publisher '{name}', expected one of {sorted(PUBLISHERS)}")
+    return PUBLISHERS[name]()
@@ -0,0 +1,157 @@
+"""Recovery of failed publishes.
+
+When a publisher fails to send a digest or a priority post (Telegram flood
+control, network errors, a webhook that is down), the message is kept in a
+queue instead of being dropped. Every digest and priority lane run first
+retries the failures that are due, waiting PUBLISH_RETRY_MINUTES between
+attempts, or longer if Telegram asks to; once those run out, a failure
+stays queued until it's retried or discarded through the API.
+
+Posts of a queued message are left out of new digests, so a retry can't
+publish them twice.
+"""
+
+import logging
+from datetime import datetime, timedelta
+from typing import List, Optional, Set
+
+from common import clock
+from common.db.models import PublishFailure
+from common.db.repository import PromotionRepository, PublishFailureRepository, RSSPostRepository
+from .config import digest_publisher_settings
+from .publishers import get_publisher
+
+logger = logging.getLogger(__name__)
+
+# Failures whose posts are still waiting to go out
+OPEN_STATUSES = ["pending", "sending", "failed"]
+# Failures that can be retried
+RETRYABLE_STATUSES = ["pending", "failed"]
+
+
+def describe(error: Exception) -> str:
+    """Error message to keep with a failure."""
+    return str(error) or type(error).__name__
+
+
+def retry_after(error: Exception) -> Optional[timedelta]:
+    """How long a publisher asked to wait before trying again (Telegram's flood control)."""
+    value = getattr(error, "retry_after", None)
+    if isinstance(value, timedelta):
+        return value
+    if isinstance(value, (int, float)) and not isinstance(value, bool):
+        return timedelta(seconds=value)
+    return None
+
+
+def next_attempt(attempts: int, error: Exception, now: datetime) -> Optional[datetime]:
+    """
+    When to retry a message after its attempts failed.
+
+    Args:
+        attempts: Attempts made so far, including the original publish
+        error: Error of the last attempt
+        now: Time of the last attempt
+
+    Returns:
+        Time of the next attempt, or None if the retries are used up
+    """
+    delays = digest_publisher_settings.retry_delays
+    if attempts > len(delays):
+        return None
+    delay = timedelta(minutes=delays[attempts - 1])
+    return now + max(delay, retry_after(error) or delay)
+
+
+async def queue_failure(
+    publisher_name: str,
+    destination: str,
+    message: str,
+    links: List[str],
+    promotion_ids: List[str],
+    error: Exception,
+) -> int:
+    """
+    Keep a message that failed to publish for retries.
+
+    Args:
+        publisher_name: Publisher of the message
+        destination: Where it was sent
+        message: The message
+        links: Posts to mark published once it goes out
+        promotion_ids: Promotions to bill once it goes out
+        error: Why it failed
+
+    Returns:
+        Failure ID
+    """
+    next_attempt_at = next_attempt(1, error, clock.now())
+    failure = PublishFailure(
+        publisher=publisher_name,
+        destination=destination,
+        message=message,
+        links=links,
+        promotion_ids=promotion_ids,
+        error=describe(error),
+        status="pending" if next_attempt_at else "failed",
+        next_attempt_at=next_attempt_at,
+    )
+    failure_id = await PublishFailureRepository.create(failure)
+    target = get_publisher(publisher_name).label(destination)
+    logger.warning(f"Queued failed message #{failure_id} to {target}, retry at {next_attempt_at}")
+    return failure_id
+
+
+async def queued_links() -> Set[str]:
+    """Links of posts in messages waiting to be retried."""
+    links = set()
+    for failure in await PublishFailureRepository.get_by_status(OPEN_STATUSES):
+        links.update(failure.links)
+    return links
+
+
+async def retry(failure: PublishFailure) -> Optional[bool]:
+    """
+    Send a queued message again, scheduling the next attempt if it fails.
+
+    Returns:
+        True if the message went out, False if it failed again, None if
+        someone else is retrying or has discarded it meanwhile
+    """
+    # Claimed first, so a scheduled and a manual retry can't both send it
+    if not await PublishFailureRepository.set_status(failure.id, "sending", [failure.status]):
+        return None
+
+    publisher = get_publisher(failure.publisher)
+    target = publisher.label(failure.destination)
+    try:
+        await publisher.publish(failure.message, failure.destination)
+    except Exception as e:
+        next_attempt_at = next_attempt(failure.attempts + 1, e, clock.now())
+        status = "pending" if next_attempt_at else "failed"
+        await PublishFailureRepository.record_attempt(
+            failure.id, status, describe(e), next_attempt_at
+        )
+        logger.error(f"Retry of message #{failure.id} to {target} failed: {e}")
+        return False
+
+    await PublishFailureRepository.record_attempt(failure.id, "sent")
+    await RSSPostRepository.mark_as_published(failure.links)
+    for promotion_id in failure.promotion_ids:
+        await PromotionRepository.record_placement(promotion_id, target)
+    logger.info(f"Sent queued message #{failure.id} to {target}")
+    return True
+
+
+async def retry_due() -> int:
+    """
+    Retry the queued messages that are due.
+
+    Returns:
+        Number of messages that went out
+    """
+    sent_count = 0
+    for failure in await PublishFailureRepository.get_due(clock.now()):
+        if await retry(failure):
+            sent_count += 1
+    return sent_count
@@ -33,6 +33,7 @@ JSON response format:
     USER_PROMPT_TEMPLATE = """Link: {link}
 Content: {content}
 Date: {pub_date}
+Categories: {categories}
 
 Is this an event?"""
 
@@ -66,6 +67,7 @@ Is this an event?"""
                     link=post.link,
                     content=content,
                     pub_date=post.pub_date.isoformat() if post.pub_date else "Unknown",
+                    categories=", ".join(post.categories or []) or "None",
                 )
 
                 # Create a unique custom_id using hash of the link
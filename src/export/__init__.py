@@ -0,0 +1 @@
+"""Export Service - Renders the event store into a static website."""
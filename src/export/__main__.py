@@ -0,0 +1,230 @@
+"""Entry point for Export service.
+
+Run with:
+    python -m src.export site --out ./public     # Render a static HTML site
+    python -m src.export ics --out events.ics    # Write an iCalendar file
+    python -m src.export interests [--month M]   # Monthly interest report
+"""
+
+import argparse
+import asyncio
+import logging
+import sys
+from datetime import timedelta
+from pathlib import Path
+
+from common import clock
+from common.db.session import db
+from common.db.repository import InteractionRepository, RSSPostRepository
+from common.ics import render_calendar
+from common.pages import SiteSettings, build_site
+from common.series import load_series
+from common.translations import load_translations
+from .config import export_settings
+from .interests import month_range, previous_month, report_rows, write_csv
+
+logging.basicConfig(
+    level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
+)
+logger = logging.getLogger(__name__)
+
+
+def parse_args():
+    """Parse command line arguments."""
+    parser = argparse.ArgumentParser(
+        description="Export event platform data",
+    )
+    subparsers = parser.add_subparsers(dest="command", required=True)
+
+    site_parser = subparsers.add_parser("site", help="Render a static HTML site")
+    site_parser.add_argument(
+        "--out", metavar="DIR", default="./public", help="Output directory (default: ./public)"
+    )
+    site_parser.add_argument(
+        "--days",
+        type=int,
+        default=export_settings.site_days_back,
+        help=f"Include events from the last N days (default: {export_settings.site_days_back})",
+    )
+    site_parser.add_argument(
+        "--base-url",
+        default="",
+        help="Public URL of the site, used for Open Graph and canonical links "
+        "(default: SITE_BASE_URL)",
+    )
+
+    ics_parser = subparsers.add_parser("ics", help="Write events as an iCalendar file")
+    ics_parser.add_argument(
+        "--out", metavar="FILE", default="events.ics", help="Output file (default: events.ics)"
+    )
+    ics_parser.add_argument(
+        "--days",
+        type=int,
+        default=export_settings.site_days_back,
+        help=f"Include events from the last N days (default: {export_settings.site_days_back})",
+    )
+    ics_parser.add_argument(
+        "--lang",
+        default="",
+        help="Use cached translations into this language, e.g. 'en' (default: original)",
+    )
+
+    interests_parser = subparsers.add_parser(
+        "interests", help="Monthly report of event views and clicks per category"
+    )
+    interests_parser.add_argument(
+        "--month",
+        default=previous_month(clock.today()),
+        help="Month to report, e.g. 2026-01 (default: last month)",
+    )
+    interests_parser.add_argument(
+        "--min-events",
+        type=int,
+        default=export_settings.interests_min_events,
+        help="Merge categories with fewer events into 'other' "
+        f"(default: {export_settings.interests_min_events})",
+    )
+    interests_parser.add_argument("--csv", metavar="PATH", help="Write the report as CSV")
+
+    return parser.parse_args()
+
+
+def write_site(files: dict, out_dir: Path) -> int:
+    """
+    Write rendered files, removing event pages that are no longer generated.
+
+    Args:
+        files: Relative path -> contents
+        out_dir: Output directory
+
+    Returns:
+        Number of written files
+    """
+    stale = set((out_dir / "events").glob("*.html"))
+    for path, contents in files.items():
+        target = out_dir / path
+        target.parent.mkdir(parents=True, exist_ok=True)
+        target.write_text(contents, encoding="utf-8")
+        stale.discard(target)
+
+    for path in stale:
+        path.unlink()
+    if stale:
+        logger.info(f"Removed {len(stale)} stale event pages")
+    return len(files)
+
+
+async def export_site(out_dir: Path, days: int, site: SiteSettings) -> int:
+    """
+    Render events from the last N days into a static site.
+
+    Args:
+        out_dir: Output directory
+        days: Number of days to include
+        site: Site settings
+
+    Returns:
+        Number of exported events
+    """
+    end_date = clock.now()
+    start_date = end_date - timedelta(days=days)
+    posts = await RSSPostRepository.get_by_date_range(
+        start_date, end_date, limit=export_settings.site_max_events, only_unpublished=False
+    )
+
+    written = write_site(build_site(posts, site, await load_series(posts)), out_dir)
+    logger.info(f"Wrote {written} files for {len(posts)} events to {out_dir}")
+    return len(posts)
+
+
+async def export_ics(out_file: Path, days: int, language: str = "") -> int:
+    """
+    Write events from the last N days as an iCalendar file.
+
+    Args:
+        out_file: Output file
+        days: Number of days to include
+        language: Language of cached translations to use (empty for the original)
+
+    Returns:
+        Number of exported events
+    """
+    end_date = clock.now()
+    start_date = end_date - timedelta(days=days)
+    posts = await RSSPostRepository.get_by_date_range(
+        start_date, end_date, limit=export_settings.site_max_events, only_unpublished=False
+    )
+
+    translations = await load_translations(posts, [language] if language else [])
+    calendar = render_calendar(posts, export_settings.site_title, translations, language)
+    out_file.parent.mkdir(parents=True, exist_ok=True)
+    # Keep CRLF line endings required by RFC 5545
+    out_file.write_bytes(calendar.encode())
+    logger.info(f"Wrote {len(posts)} events to {out_file}")
+    return len(posts)
+
+
+async def export_interests(month: str, min_events: int, csv_path: str = "") -> int:
+    """
+    Print the interest report of a month and optionally save it as CSV.
+
+    Args:
+        month: Month like '2026-01'
+        min_events: Smallest number of events reported as a separate category
+        csv_path: CSV output path (empty to only print)
+
+    Returns:
+        Number of report rows
+    """
+    start, end = month_range(month)
+    rows = report_rows(await InteractionRepository.get_totals(start, end), min_events)
+
+    if csv_path:
+        with open(csv_path, "w", encoding="utf-8", newline="") as f:
+            write_csv(rows, f)
+        print(f"✓ Report saved to {csv_path}")
+
+    print(f"Interests in {month}:")
+    for row in rows:
+        print(
+            f"  {row['category']} / {row['channel']}: {row['events']} events, "
+            f"{row['views']} views, {row['clicks']} clicks"
+        )
+    return len(rows)
+
+
+async def main():
+    """Main entry point for Export service."""
+    args = parse_args()
+
+    try:
+        if not db.pool:
+            await db.connect()
+            logger.info("Connected to database")
+
+        if args.command == "site":
+            site = export_settings.site(args.base_url)
+            if not site.base_url:
+                logger.warning("SITE_BASE_URL not set, pages will have no Open Graph URLs")
+
+            count = await export_site(Path(args.out), args.days, site)
+            print(f"✓ Exported {count} events to {args.out}")
+
+        elif args.command == "ics":
+            count = await export_ics(Path(args.out), args.days, args.lang)
+            print(f"✓ Exported {count} events to {args.out}")
+
+        elif args.command == "interests":
+            await export_interests(args.month, args.min_events, args.csv or "")
+
+    except Exception as e:
+        logger.error(f"Error: {e}", exc_info=True)
+        print(f"Error: {e}", file=sys.stderr)
+        sys.exit(1)
+
+    finally:
+        await db.disconnect()
+
+
+if __name__ == "__main__":
+    asyncio.run(main())
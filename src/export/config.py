@@ -0,0 +1,39 @@
+"""Configuration for Export service."""
+
+import os
+from dataclasses import dataclass
+from pathlib import Path
+from dotenv import load_dotenv
+
+from common.pages import SiteSettings
+
+# Load .env file if it exists
+env_path = Path(__file__).parent.parent.parent / ".env"
+if env_path.exists():
+    load_dotenv(env_path)
+
+
+@dataclass
+class ExportConfig:
+    """Export configuration settings."""
+
+    # Static site
+    site_title: str = os.getenv("SITE_TITLE", "Афиша")
+    site_base_url: str = os.getenv("SITE_BASE_URL", "")
+    site_language: str = os.getenv("SITE_LANGUAGE", "ru")
+    site_days_back: int = int(os.getenv("SITE_DAYS_BACK", "30"))
+    site_max_events: int = int(os.getenv("SITE_MAX_EVENTS", "2000"))
+
+    # Interest reports
+    interests_min_events: int = int(os.getenv("INTERESTS_MIN_EVENTS", "3"))
+
+    def site(self, base_url: str = "") -> SiteSettings:
+        """Site settings, optionally overriding the base URL."""
+        return SiteSettings(
+            title=self.site_title,
+            base_url=base_url or self.site_base_url,
+            language=self.site_language,
+        )
+
+
+export_settings = ExportConfig()
@@ -0,0 +1,91 @@
+"""Monthly interest reports for organizers.
+
+Page views and source link clicks are only stored as daily counters per
+event, so reports contain no visitor data. They are aggregated per category
+(the tags assigned by routing rules) and channel; categories with fewer
+events than the threshold are merged into "other" so single events can't be
+told apart.
+"""
+
+import csv
+from collections import defaultdict
+from datetime import date
+from typing import Dict, List, TextIO, Tuple
+
+from common.utils.links import channel_from_link
+
+REPORT_COLUMNS = ["category", "channel", "events", "views", "clicks", "click_rate"]
+OTHER = "other"
+
+
+def month_range(month: str) -> Tuple[date, date]:
+    """
+    First day of a month and of the month after it.
+
+    Args:
+        month: Month like '2026-02'
+
+    Returns:
+        (start, end) with end exclusive
+    """
+    start = date.fromisoformat(f"{month}-01")
+    end = date(start.year + start.month // 12, start.month % 12 + 1, 1)
+    return start, end
+
+
+def previous_month(today: date) -> str:
+    """The month before the given day, like '2026-01'."""
+    year, month = (today.year, today.month - 1) if today.month > 1 else (today.year - 1, 12)
+    return f"{year:04d}-{month:02d}"
+
+
+def report_rows(totals: List[dict], min_events: int = 3) -> List[dict]:
+    """
+    Aggregate per-event totals into report rows.
+
+    An event with several tags counts towards each of them.
+
+    Args:
+        totals: Rows from InteractionRepository.get_totals
+        min_events: Smallest number of events reported as a separate row
+
+    Returns:
+        Rows keyed by REPORT_COLUMNS, busiest first
+    """
+    groups: Dict[Tuple[str, str], List[dict]] = defaultdict(list)
+    for row in totals:
+        channel = channel_from_link(row["link"])
+        for category in row["tags"] or [OTHER]:
+            groups[(category, channel)].append(row)
+
+    merged: Dict[Tuple[str, str], List[dict]] = defaultdict(list)
+    for (category, channel), rows in groups.items():
+        if len(rows) < min_events:
+            category, channel = OTHER, OTHER
+        merged[(category, channel)].extend(rows)
+
+    report = []
+    for (category, channel), rows in merged.items():
+        # Merged rows may list an event under several of its tags
+        events = {row["link"]: row for row in rows}.values()
+        views = sum(row["views"] or 0 for row in events)
+        clicks = sum(row["clicks"] or 0 for row in events)
+        report.append(
+            {
+                "category": category,
+                "channel": channel,
+                "events": len(events),
+                "views": views,
+                "clicks": clicks,
+                "click_rate": f"{clicks / views:.3f}" if views else "0.000",
+            }
+        )
+    # "other" goes last, whatever its size
+    return sorted(report, key=lambda row: (row["category"] == OTHER, -row["views"]))
+
+
+def write_csv(rows: List[dict], out: TextIO) -> None:
+    """Write report rows as CSV."""
+    writer = csv.DictWriter(out, fieldnames=REPORT_COLUMNS)
+    writer.writeheader()
+    writer.writerows(rows)
@@ -1,6 +0,0 @@
-"""Entry point for python -m feed."""
-
-from .cli import main
-
-if __name__ == "__main__":
-    main()
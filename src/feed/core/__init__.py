@@ -1,6 +0,0 @@
-"""Core module initialization."""
-
-from .parser import RSSParser
-from .fetcher import FeedFetcher
-
-__all__ = ["RSSParser", "FeedFetcher"]
@@ -1,8 +0,0 @@
-"""Database layer for RSS parser."""
-
-from .config import settings
-from .session import db
-from .models import RSSPost, Event
-from .repository import RSSPostRepository, EventRepository
-
-__all__ = ["settings", "db", "RSSPost", "Event", "RSSPostRepository", "EventRepository"]
@@ -1,233 +0,0 @@
-"""Data models for RSS posts (dataclass representations)."""
-
-from dataclasses import dataclass, asdict
-from datetime import datetime
-from typing import Optional
-from email.utils import parsedate_to_datetime
-from decimal import Decimal
-import json
-
-
-@dataclass
-class TelegramChannel:
-    """Dataclass representation of a Telegram channel."""
-
-    channel_id: int
-    channel_name: str
-    description: Optional[str] = None
-    url: Optional[str] = None
-    created_at: Optional[datetime] = None
-    updated_at: Optional[datetime] = None
-
-    def to_dict(self) -> dict:
-        """Convert to dictionary."""
-        return asdict(self)
-
-    @staticmethod
-    def from_row(row: dict) -> "TelegramChannel":
-        """Create TelegramChannel from database row."""
-        return TelegramChannel(
-            channel_id=row["channel_id"],
-            channel_name=row["channel_name"],
-            description=row.get("description"),
-            url=row.get("url"),
-            created_at=row.get("created_at"),
-            updated_at=row.get("updated_at"),
-        )
-
-
-@dataclass
-class RSSPost:
-    """Dataclass representation of an RSS post."""
-
-    link: str
-    content: str
-    pub_date: Optional[datetime] = None
-    media: Optional[str] = None
-    is_processed: bool = False
-    is_event: Optional[bool] = None
-    classification_data: Optional[dict] = None
-    created_at: Optional[datetime] = None
-    updated_at: Optional[datetime] = None
-    classified_at: Optional[datetime] = None
-
-    def __post_init__(self):
-        """Parse pub_date string into datetime if needed."""
-        if self.pub_date and isinstance(self.pub_date, str):
-            self.pub_date = self._parse_datetime(self.pub_date)
-
-    @staticmethod
-    def _parse_datetime(date_str: str) -> datetime:
-        """Parse datetime from string.
-
-        Supports:
-        - ISO 8601: '2026-01-10T10:00:00Z'
-        - RFC 2822: 'Thu, 08 Jan 2026 06:42:01 +0000'
-
-        Returns timezone-naive datetime for PostgreSQL TIMESTAMP compatibility.
-        """
-        dt = None
-
-        # Try RFC 2822 format first (common in RSS feeds)
-        try:
-            dt = parsedate_to_datetime(date_str)
-        except (ValueError, TypeError):
-            pass
-
-        # Try ISO 8601 format
-        if dt is None:
-            try:
-                dt = datetime.fromisoformat(date_str.replace("Z", "+00:00"))
-            except (ValueError, TypeError):
-                pass
-
-        # If all fail, raise error
-        if dt is None:
-            raise ValueError(f"Unable to parse datetime from: {date_str}")
-
-        # Convert to timezone-naive (PostgreSQL TIMESTAMP expects naive datetimes)
-        if dt.tzinfo is not None:
-            dt = dt.replace(tzinfo=None)
-
-        return dt
-
-    def to_dict(self) -> dict:
-        """Convert to dictionary."""
-        return asdict(self)
-
-    @staticmethod
-    def from_row(row: dict) -> "RSSPost":
-        """Create RSSPost from database row.
-
-        Note: asyncpg returns JSONB fields as already-parsed dictionaries/lists,
-        but if they come as strings, we parse them.
-        """
-
-        # Helper to parse JSON if it's a string, otherwise return as-is
-        def parse_json(value):
-            if value is None:
-                return None
-            if isinstance(value, str):
-                return json.loads(value)
-            return value
-
-        return RSSPost(
-            link=row["link"],
-            content=row["content"],
-            pub_date=row.get("pub_date"),
-            media=row.get("media"),
-            is_processed=row.get("is_processed", False),
-            is_event=row.get("is_event"),
-            classification_data=parse_json(row.get("classification_data")),
-            created_at=row.get("created_at"),
-            updated_at=row.get("updated_at"),
-            classified_at=row.get("classified_at"),
-        )
-
-
-@dataclass
-class OpenAIRequestLog:
-    """Dataclass representation of an OpenAI request log."""
-
-    request_type: str  # 'batch', 'completion', etc.
-    model: str
-    endpoint: str
-    status: str = "pending"  # 'pending', 'completed', 'failed'
-    id: Optional[int] = None
-    batch_id: Optional[str] = None
-    custom_id: Optional[str] = None
-    request_data: Optional[dict] = None
-    response_data: Optional[dict] = None
-    status_code: Optional[int] = None
-    tokens_used: Optional[int] = None
-    cost_estimate: Optional[Decimal] = None
-    error_message: Optional[str] = None
-    post_link: Optional[str] = None
-    created_at: Optional[datetime] = None
-    updated_at: Optional[datetime] = None
-    completed_at: Optional[datetime] = None
-
-    def to_dict(self) -> dict:
-        """Convert to dictionary."""
-        return asdict(self)
-
-    @staticmethod
-    def from_row(row: dict) -> "OpenAIRequestLog":
-        """Create OpenAIRequestLog from database row."""
-
-        # Helper to parse JSON if it's a string, otherwise return as-is
-        def parse_json(value):
-            if value is None:
-                return None
-            if isinstance(value, str):
-                return json.loads(value)
-            return value
-
-        return OpenAIRequestLog(
-            id=row.get("id"),
-            batch_id=row.get("batch_id"),
-            custom_id=row.get("custom_id"),
-            request_type=row["request_type"],
-            model=row["model"],
-            endpoint=row["endpoint"],
-            request_data=parse_json(row.get("request_data")),
-            response_data=parse_json(row.get("response_data")),
-            status=row["status"],
-            status_code=row.get("status_code"),
-            tokens_used=row.get("tokens_used"),
-            cost_estimate=row.get("cost_estimate"),
-            error_message=row.get("error_message"),
-            post_link=row.get("post_link"),
-            created_at=row.get("created_at"),
-            updated_at=row.get("updated_at"),
-            completed_at=row.get("completed_at"),
-        )
-
-
-@dataclass
-class Event:
-    """Dataclass representation of an event."""
-
-    post_link: str
-    title: Optional[str] = None
-    summary: Optional[str] = None
-    event_date: Optional[datetime] = None
-    event_date_is_approximate: bool = False
-    location: Optional[str] = None
-    event_type: Optional[str] = None
-    confidence: Optional[Decimal] = None
-    additional_data: Optional[dict] = None
-    id: Optional[int] = None
-    created_at: Optional[datetime] = None
-    updated_at: Optional[datetime] = None
-
-    def to_dict(self) -> dict:
-        """Convert to dictionary."""
-        return asdict(self)
-
-    @staticmethod
-    def from_row(row: dict) -> "Event":
-        """Create Event from database row."""
-
-        # Helper to parse JSON if it's a string, otherwise return as-is
-        def parse_json(value):
-            if value is None:
-                return None
-            if isinstance(value, str):
-                return json.loads(value)
-            return value
-
-        return Event(
-            id=row.get("id"),
-            post_link=row["post_link"],
-            title=row.get("title"),
-            summary=row.get("summary"),
-            event_date=row.get("event_date"),
-            event_date_is_approximate=row.get("event_date_is_approximate", False),
-            location=row.get("location"),
-            event_type=row.get("event_type"),
-            confidence=row.get("confidence"),
-            additional_data=parse_json(row.get("additional_data")),
-            created_at=row.get("created_at"),
-            updated_at=row.get("updated_at"),
-        )
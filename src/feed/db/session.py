@@ -1,74 +0,0 @@
-"""Database connection and session management using asyncpg."""
-
-import asyncpg
-from typing import Optional
-from .config import settings
-from .schema import CREATE_POSTS_TABLE, CREATE_INDEXES
-
-
-class Database:
-    """Database connection manager."""
-
-    def __init__(self):
-        self.pool: Optional[asyncpg.Pool] = None
-
-    async def connect(self) -> None:
-        """Create connection pool."""
-        self.pool = await asyncpg.create_pool(
-            dsn=settings.get_dsn(),
-            min_size=5,
-            max_size=20,
-            command_timeout=60,
-        )
-
-    async def disconnect(self) -> None:
-        """Close connection pool."""
-        if self.pool:
-            await self.pool.close()
-
-    async def init_schema(self) -> None:
-        """Initialize database schema."""
-        if not self.pool:
-            raise RuntimeError("Database not connected. Call connect() first.")
-
-        async with self.pool.acquire() as conn:
-            # Create tables
-            await conn.execute(CREATE_POSTS_TABLE)
-            # Create indexes
-            await conn.execute(CREATE_INDEXES)
-
-    async def execute(self, query: str, *args) -> None:
-        """Execute a query without returning results."""
-        if not self.pool:
-            raise RuntimeError("Database not connected. Call connect() first.")
-
-        async with self.pool.acquire() as conn:
-            await conn.execute(query, *args)
-
-    async def fetch(self, query: str, *args) -> list:
-        """Fetch multiple rows."""
-        if not self.pool:
-            raise RuntimeError("Database not connected. Call connect() first.")
-
-        async with self.pool.acquire() as conn:
-            return await conn.fetch(query, *args)
-
-    async def fetchrow(self, query: str, *args) -> Optional[dict]:
-        """Fetch a single row."""
-        if not self.pool:
-            raise RuntimeError("Database not connected. Call connect() first.")
-
-        async with self.pool.acquire() as conn:
-            return await conn.fetchrow(query, *args)
-
-    async def fetchval(self, query: str, *args):
-        """Fetch a single value."""
-        if not self.pool:
-            raise RuntimeError("Database not connected. Call connect() first.")
-
-        async with self.pool.acquire() as conn:
-            return await conn.fetchval(query, *args)
-
-
-# Global database instance
-db = Database()
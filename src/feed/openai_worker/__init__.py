@@ -1,6 +0,0 @@
-"""OpenAI integration for event classification."""
-
-from .worker import OpenAIWorker
-from .batch_processor import BatchProcessor
-
-__all__ = ["OpenAIWorker", "BatchProcessor"]
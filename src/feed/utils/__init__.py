@@ -1,5 +0,0 @@
-"""Utils module initialization."""
-
-from .html import strip_html
-
-__all__ = ["strip_html"]
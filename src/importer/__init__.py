@@ -0,0 +1 @@
+"""Importer Service - Bulk imports of partner event tables (CSV/XLSX)."""
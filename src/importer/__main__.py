@@ -0,0 +1,81 @@
+"""Entry point for Importer service.
+
+Run with:
+    python -m src.importer FILE --mapping MAPPING.json [--dry-run] [--report PATH]
+"""
+
+import argparse
+import asyncio
+import logging
+import sys
+
+from common.db.session import db
+from common.rules import load_rules
+from rss_reader.core.filters import RuleFilter, load_filters
+from .events import ImportReport, import_rows
+from .mapping import MappingError, load_mapping
+from .table import read_table
+
+logging.basicConfig(
+    level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
+)
+logger = logging.getLogger(__name__)
+
+
+def parse_args():
+    """Parse command line arguments."""
+    parser = argparse.ArgumentParser(description="Import a partner's table of events")
+    parser.add_argument("file", metavar="FILE", help="CSV or XLSX file")
+    parser.add_argument("--mapping", required=True, help="Column mapping (JSON)")
+    parser.add_argument(
+        "--dry-run", action="store_true", help="Validate and report without storing"
+    )
+    parser.add_argument("--report", metavar="PATH", help="Write the row report as CSV")
+    return parser.parse_args()
+
+
+def print_report(report: ImportReport) -> None:
+    """Print import counts and the rows that were not imported."""
+    for row in report.rejected:
+        print(f"  line {row.line} ({row.external_id or 'no ID'}): {row.status}, {row.message}")
+    counts = ", ".join(f"{status}: {count}" for status, count in report.counts().items())
+    print(f"Rows: {len(report.rows)} ({counts or 'none'})")
+
+
+async def main():
+    """Main entry point for Importer service."""
+    args = parse_args()
+
+    try:
+        mapping = load_mapping(args.mapping)
+        header, rows = read_table(args.file, mapping.delimiter)
+        missing = mapping.missing_columns(header)
+        if missing:
+            raise MappingError(f"Columns not found in {args.file}: {', '.join(missing)}")
+
+        if not db.pool:
+            await db.connect()
+            logger.info("Connected to database")
+
+        filters = [RuleFilter(load_rules()), load_filters()]
+        report = await import_rows(rows, mapping, filters, dry_run=args.dry_run)
+
+        if args.report:
+            with open(args.report, "w", encoding="utf-8", newline="") as f:
+                report.write_csv(f)
+            print(f"✓ Report saved to {args.report}")
+        print_report(report)
+        if args.dry_run:
+            print("Dry run, nothing was stored.")
+
+    except Exception as e:
+        logger.error(f"Error: {e}", exc_info=True)
+        print(f"Error: {e}", file=sys.stderr)
+        sys.exit(1)
+
+    finally:
+        await db.disconnect()
+
+
+if __name__ == "__main__":
+    asyncio.run(main())
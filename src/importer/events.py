@@ -0,0 +1,203 @@
+"""Importing table rows as events.
+
+Rows become posts of the mapping's source and go through the same filters
+and detection as polled posts (see rss_reader.core.ingest). Every row gets a
+line in the import report, so partners can be told exactly which rows were
+rejected and why.
+"""
+
+import csv
+import logging
+from dataclasses import dataclass, field, replace
+from datetime import datetime
+from typing import Dict, List, Optional, TextIO
+
+from common import clock
+from common.db.repository import RSSPostRepository
+from common.models.feed import RSSItem
+from rss_reader.core.filters import PostFilter
+from rss_reader.core.ingest import apply_filters, build_post, store_post
+from .mapping import ColumnMapping
+
+logger = logging.getLogger(__name__)
+
+CREATED = "created"
+UPDATED = "updated"
+UNCHANGED = "unchanged"
+FILTERED = "filtered"
+INVALID = "invalid"
+
+REPORT_COLUMNS = ["line", "external_id", "status", "link", "message"]
+
+
+class RowError(Exception):
+    """Raised for a row that can't be imported."""
+
+
+@dataclass
+class RowResult:
+    """What the import did with one row."""
+
+    # Line in the file, counting the header as line 1
+    line: int
+    external_id: str
+    status: str
+    link: Optional[str] = None
+    message: str = ""
+
+
+@dataclass
+class ImportReport:
+    """Results of all rows of an import."""
+
+    rows: List[RowResult] = field(default_factory=list)
+
+    def counts(self) -> Dict[str, int]:
+        """Number of rows per status."""
+        counts: Dict[str, int] = {}
+        for row in self.rows:
+            counts[row.status] = counts.get(row.status, 0) + 1
+        return counts
+
+    @property
+    def rejected(self) -> List[RowResult]:
+        """Rows that were not imported."""
+        return [row for row in self.rows if row.status in (FILTERED, INVALID)]
+
+    def write_csv(self, out: TextIO) -> None:
+        """Write the results as CSV, to send back to the partner."""
+        writer = csv.DictWriter(out, fieldnames=REPORT_COLUMNS)
+        writer.writeheader()
+        for row in self.rows:
+            writer.writerow({column: getattr(row, column) or "" for column in REPORT_COLUMNS})
+
+
+@dataclass
+class ParsedRow:
+    """A valid row, ready to be stored."""
+
+    item: RSSItem
+    title: Optional[str]
+    tags: List[str]
+
+
+def parse_row(row: Dict[str, str], mapping: ColumnMapping) -> ParsedRow:
+    """
+    Validate a row and convert it to a feed item.
+
+    Raises:
+        RowError: If required values are missing or malformed
+    """
+    link = mapping.event_link(row)
+    if not link:
+        raise RowError(f"'{mapping.external_id}' is empty")
+
+    content = "\n".join(row[column] for column in mapping.content if row.get(column))
+    if not content.strip():
+        raise RowError(f"{', '.join(repr(c) for c in mapping.content)} are all empty")
+
+    pub_date = row.get(mapping.pub_date) if mapping.pub_date else None
+    if pub_date:
+        try:
+            datetime.fromisoformat(pub_date.replace("Z", "+00:00"))
+        except ValueError:
+            raise RowError(f"'{mapping.pub_date}' must be an ISO 8601 date/time: {pub_date}")
+
+    tags = list(mapping.extra_tags)
+    if mapping.tags:
+        tags += [tag.strip().lower() for tag in row.get(mapping.tags, "").split(",")]
+
+    return ParsedRow(
+        item=RSSItem(link=link, description=content, pub_date=pub_date or clock.now().isoformat()),
+        title=(row.get(mapping.title) or None) if mapping.title else None,
+        tags=list(dict.fromkeys(tag for tag in tags if tag)),
+    )
+
+
+async def import_row(
+    parsed: ParsedRow, mapping: ColumnMapping, filters: List[PostFilter], dry_run: bool
+) -> str:
+    """
+    Store a parsed row as a new post or update the post imported from it before.
+
+    Returns:
+        Status of the row
+    """
+    item = parsed.item
+    existing = await RSSPostRepository.get_by_link(item.link)
+    # Without a title column the title is the summarizer's, so only the text counts
+    if (
+        existing
+        and existing.content == item.description
+        and (parsed.title is None or existing.title == parsed.title)
+    ):
+        return UNCHANGED
+
+    decision = await apply_filters(mapping.source, item, filters)
+    if not decision.keep:
+        raise RowError(f"dropped by {decision.reason}")
+    if dry_run:
+        return UPDATED if existing else CREATED
+
+    post = await build_post(item, list(dict.fromkeys(parsed.tags + decision.tags)))
+    if not existing:
+        await store_post(post)
+        if parsed.title:
+            await RSSPostRepository.update_summary(post.link, None, parsed.title)
+        return CREATED
+
+    # Publication state stays; the summary is redone for the new text
+    await RSSPostRepository.upsert(
+        replace(
+            post,
+            pub_date=existing.pub_date,
+            is_published=existing.is_published,
+            published_at=existing.published_at,
+            created_at=existing.created_at,
+            updated_at=clock.now(),
+            title=parsed.title,
+        )
+    )
+    return UPDATED
+
+
+async def import_rows(
+    rows: List[Dict[str, str]],
+    mapping: ColumnMapping,
+    filters: Optional[List[PostFilter]] = None,
+    dry_run: bool = False,
+) -> ImportReport:
+    """
+    Import table rows as events.
+
+    Args:
+        rows: Rows keyed by column name
+        mapping: Column mapping of the table
+        filters: Rule and WASM filters to apply
+        dry_run: Validate and report without storing anything
+
+    Returns:
+        ImportReport with one result per row
+    """
+    report = ImportReport()
+    seen: Dict[str, int] = {}
+
+    for line, row in enumerate(rows, start=2):
+        external_id = row.get(mapping.external_id, "")
+        link = None
+        try:
+            if external_id in seen:
+                raise RowError(f"duplicate ID, first seen on line {seen[external_id]}")
+            parsed = parse_row(row, mapping)
+            link = parsed.item.link
+            seen[external_id] = line
+            status = await import_row(parsed, mapping, filters or [], dry_run)
+            report.rows.append(RowResult(line, external_id, status, link))
+        except RowError as e:
+            status = FILTERED if str(e).startswith("dropped by") else INVALID
+            report.rows.append(RowResult(line, external_id, status, link, str(e)))
+        except Exception as e:
+            logger.error(f"Failed to import line {line} ({external_id}): {e}", exc_info=True)
+            report.rows.append(RowResult(line, external_id, INVALID, link, str(e)))
+
+    return report
@@ -0,0 +1,104 @@
+"""Column mapping of a partner's event table.
+
+Partners export events with their own column names, so each one gets a JSON
+mapping file:
+
+    {"source": "philharmonic",
+     "external_id": "ID",
+     "link": "https://philharmonic.example/events/{external_id}",
+     "title": "Название",
+     "content": ["Название", "Дата и время", "Площадка", "Описание", "Цена"],
+     "tags": "Жанр",
+     "extra_tags": ["partner"],
+     "delimiter": ";"}
+
+`content` columns are joined line by line into the post text, which is where
+dates, prices and admission are detected, as in a channel announcement.
+`link` is the event's page on the partner's site; being built from the
+external ID, it identifies the event, so importing the same table again
+updates its events instead of adding copies.
+"""
+
+import json
+from dataclasses import dataclass, field
+from typing import Dict, List, Optional
+from urllib.parse import quote
+
+
+class MappingError(Exception):
+    """Raised when a mapping file is invalid."""
+
+
+@dataclass
+class ColumnMapping:
+    """How the columns of a partner's table map to post fields."""
+
+    source: str
+    external_id: str
+    content: List[str]
+    link: str
+    title: str = ""
+    tags: str = ""
+    pub_date: str = ""
+    extra_tags: List[str] = field(default_factory=list)
+    delimiter: str = ","
+
+    @staticmethod
+    def from_dict(data: Dict) -> "ColumnMapping":
+        """Create ColumnMapping from a configuration object."""
+        if not isinstance(data, dict):
+            raise MappingError("Mapping must be a JSON object")
+        for key in ("source", "external_id", "content", "link"):
+            if not data.get(key):
+                raise MappingError(f"Mapping requires '{key}'")
+        content = data["content"]
+        if isinstance(content, str):
+            content = [content]
+        mapping = ColumnMapping(
+            source=str(data["source"]),
+            external_id=str(data["external_id"]),
+            content=[str(column) for column in content],
+            link=str(data["link"]),
+            title=str(data.get("title") or ""),
+            tags=str(data.get("tags") or ""),
+            pub_date=str(data.get("pub_date") or ""),
+            extra_tags=[str(tag) for tag in data.get("extra_tags") or []],
+            delimiter=str(data.get("delimiter") or ","),
+        )
+        if "{external_id}" not in mapping.link:
+            raise MappingError("'link' must contain {external_id}")
+        try:
+            mapping.link.format(external_id="")
+        except (KeyError, IndexError, ValueError):
+            raise MappingError("'link' may contain no placeholders but {external_id}")
+        return mapping
+
+    def columns(self) -> List[str]:
+        """All columns the mapping reads."""
+        columns = [self.external_id, *self.content]
+        columns += [column for column in (self.title, self.tags, self.pub_date) if column]
+        return list(dict.fromkeys(columns))
+
+    def missing_columns(self, header: List[str]) -> List[str]:
+        """Columns of the mapping absent from a table header."""
+        return [column for column in self.columns() if column not in header]
+
+    def event_link(self, row: Dict[str, str]) -> Optional[str]:
+        """Link identifying the event of a row."""
+        external_id = row.get(self.external_id)
+        return self.link.format(external_id=quote(external_id, safe="")) if external_id else None
+
+
+def load_mapping(path: str) -> ColumnMapping:
+    """
+    Load a mapping file.
+
+    Raises:
+        MappingError: If the mapping is invalid
+    """
+    with open(path, "r", encoding="utf-8") as f:
+        try:
+            data = json.load(f)
+        except ValueError as e:
+            raise MappingError(f"{path} is not valid JSON: {e}")
+    return ColumnMapping.from_dict(data)
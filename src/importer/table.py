@@ -0,0 +1,58 @@
+"""Reading partner tables."""
+
+import csv
+from pathlib import Path
+from typing import Dict, List, Tuple
+
+
+def read_csv(path: str, delimiter: str = ",") -> Tuple[List[str], List[Dict[str, str]]]:
+    """
+    Read a CSV file with a header row.
+
+    A UTF-8 byte order mark, as left by Excel, is skipped.
+
+    Returns:
+        Tuple of (header, rows)
+    """
+    with open(path, "r", encoding="utf-8-sig", newline="") as f:
+        reader = csv.DictReader(f, delimiter=delimiter)
+        rows = [{key: (value or "").strip() for key, value in row.items() if key} for row in reader]
+        return list(reader.fieldnames or []), rows
+
+
+def read_xlsx(path: str) -> Tuple[List[str], List[Dict[str, str]]]:
+    """
+    Read the first sheet of an XLSX workbook with a header row.
+
+    Needs the optional openpyxl package.
+
+    Returns:
+        Tuple of (header, rows)
+    """
+    try:
+        from openpyxl import load_workbook
+    except ImportError:
+        raise RuntimeError("Reading XLSX files needs openpyxl: pip install openpyxl")
+
+    sheet = load_workbook(path, read_only=True, data_only=True).worksheets[0]
+    values = list(sheet.iter_rows(values_only=True))
+    if not values:
+        return [], []
+    header = [str(cell).strip() if cell is not None else "" for cell in values[0]]
+    rows = []
+    for cells in values[1:]:
+        if all(cell is None for cell in cells):
+            continue
+        row = {}
+        for name, cell in zip(header, cells):
+            if name:
+                row[name] = str(cell).strip() if cell is not None else ""
+        rows.append(row)
+    return header, rows
+
+
+def read_table(path: str, delimiter: str = ",") -> Tuple[List[str], List[Dict[str, str]]]:
+    """Read a CSV or XLSX table, by file extension."""
+    if Path(path).suffix.lower() == ".xlsx":
+        return read_xlsx(path)
+    return read_csv(path, delimiter)
@@ -0,0 +1 @@
+"""Mastodon Publisher Service - Posts selected events to a Mastodon account."""
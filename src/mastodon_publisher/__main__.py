@@ -0,0 +1,262 @@
+"""Entry point for Mastodon Publisher service.
+
+Run with: python -m src.mastodon_publisher
+"""
+
+import asyncio
+import hashlib
+import logging
+import mimetypes
+from datetime import datetime, timedelta
+from typing import List, Optional, Tuple
+from urllib.parse import urlparse
+
+import requests
+
+from common import clock
+from common.db.session import db
+from common.db.repository import PostDeliveryRepository, RSSPostRepository
+from common.db.models import RSSPost
+from common.embargo import hold_embargoed
+from common.quotas import QuotaExhausted, throttle
+from common.rules import Expression, compile_expression, load_rules, post_variables
+from common.sanitizer import sanitize_for
+from common.utils.links import channel_from_link
+from .client import MastodonClient
+from .config import mastodon_publisher_settings
+
+logging.basicConfig(
+    level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
+)
+logger = logging.getLogger(__name__)
+
+# Mastodon counts every link as 23 characters regardless of its length
+URL_LENGTH = 23
+
+
+def variables_for(post: RSSPost) -> dict:
+    """Build rule variables for a post."""
+    return post_variables(
+        channel_from_link(post.link),
+        post.link,
+        post.content,
+        post.pub_date,
+        post.media_urls(),
+        post.tags,
+        post.event_format,
+        post.categories,
+    )
+
+
+def select_posts(
+    posts: List[RSSPost], select: Expression, sensitive: Expression
+) -> List[Tuple[RSSPost, bool]]:
+    """
+    Pick posts to publish and flag the ones that need a content warning.
+
+    Args:
+        posts: Candidate posts
+        select: Expression a post must match to be published
+        sensitive: Expression marking 18+ posts
+
+    Returns:
+        List of (post, is_sensitive) in the original order
+    """
+    selected = []
+    for post in posts:
+        variables = variables_for(post)
+        try:
+            if select.matches(variables):
+                selected.append((post, sensitive.matches(variables)))
+        except Exception as e:
+            logger.error(f"Failed to evaluate Mastodon rules for {post.link}: {e}")
+    return selected
+
+
+def build_status(content: str, link: str, max_chars: int = 500) -> str:
+    """
+    Build status text: the post content, truncated to fit, followed by its link.
+
+    Args:
+        content: Post content
+        link: Post link
+        max_chars: Instance character limit
+
+    Returns:
+        Status text
+    """
+    text = (content or "").strip()
+    budget = max_chars - URL_LENGTH - 2
+    if len(text) > budget:
+        cut = text.rfind(" ", 0, budget - 1)
+        text = text[: cut if cut > budget // 2 else budget - 1].rstrip() + "…"
+    return f"{text}\n\n{link}" if text else link
+
+
+def download_media(url: str, max_bytes: int, timeout: int) -> Optional[Tuple[bytes, str, str]]:
+    """
+    Download an image for re-upload.
+
+    Args:
+        url: Image URL
+        max_bytes: Maximum file size
+        timeout: Request timeout in seconds
+
+    Returns:
+        Tuple of (data, filename, content_type), or None if the file is not an
+        image or is too large
+    """
+    with requests.get(url, stream=True, timeout=timeout) as response:
+        response.raise_for_status()
+        content_type = response.headers.get("Content-Type", "").split(";")[0].strip()
+        if not content_type.startswith("image/"):
+            logger.info(f"Skipping non-image media {url} ({content_type or 'unknown type'})")
+            return None
+
+        data = b""
+        for chunk in response.iter_content(64 * 1024):
+            data += chunk
+            if len(data) > max_bytes:
+                logger.info(f"Skipping media {url}: larger than {max_bytes} bytes")
+                return None
+
+    filename = urlparse(url).path.rsplit("/", 1)[-1] or "image"
+    if "." not in filename:
+        filename += mimetypes.guess_extension(content_type) or ""
+    return data, filename, content_type
+
+
+def upload_images(client: MastodonClient, post: RSSPost) -> List[str]:
+    """Upload a post's images, skipping the ones that fail."""
+    settings = mastodon_publisher_settings
+    media_ids = []
+    for url in post.media_urls()[: settings.max_media]:
+        try:
+            downloaded = download_media(url, settings.max_media_bytes, settings.timeout)
+            if downloaded:
+                media_ids.append(client.upload_media(*downloaded))
+        except Exception as e:
+            logger.warning(f"Failed to attach {url} to {post.link}: {e}")
+    return media_ids
+
+
+def publish_post(client: MastodonClient, post: RSSPost, sensitive: bool, target: str) -> str:
+    """
+    Publish one post as a status.
+
+    Returns:
+        ID of the created status
+    """
+    settings = mastodon_publisher_settings
+    text = build_status(post.content, post.link, settings.max_chars)
+    status = client.post_status(
+        sanitize_for(target, text),
+        media_ids=upload_images(client, post),
+        sensitive=sensitive,
+        spoiler_text=settings.spoiler_text if sensitive else "",
+        visibility=settings.visibility,
+        idempotency_key=hashlib.sha256(f"{target}|{post.link}".encode()).hexdigest(),
+    )
+    return status["id"]
+
+
+async def refresh_shares(client: MastodonClient, target: str, since: datetime) -> int:
+    """
+    Store the reblog counts of the statuses posted since a time.
+
+    Returns:
+        Number of statuses counted
+    """
+    counted = 0
+    for link, status_id in await PostDeliveryRepository.get_recent(target, since):
+        try:
+            await throttle("mastodon")
+        except QuotaExhausted as e:
+            logger.warning(f"Stopped counting Mastodon shares: {e}")
+            break
+        try:
+            status = await asyncio.to_thread(client.get_status, status_id)
+        except requests.RequestException as e:
+            logger.warning(f"Failed to count the shares of {link} on Mastodon: {e}")
+            continue
+        await PostDeliveryRepository.set_shares(link, target, status.get("reblogs_count") or 0)
+        counted += 1
+    return counted
+
+
+async def main():
+    """Main entry point for Mastodon Publisher service."""
+    logger.info("Starting Mastodon Publisher service...")
+
+    try:
+        settings = mastodon_publisher_settings
+        settings.validate()
+        select = compile_expression(settings.select)
+        sensitive = compile_expression(settings.sensitive)
+
+        if not db.pool:
+            await db.connect()
+            logger.info("Connected to database")
+
+        target = f"mastodon:{urlparse(settings.base_url).netloc}"
+        end_date = clock.now()
+        start_date = end_date - timedelta(days=settings.days_back)
+        posts = await RSSPostRepository.get_by_date_range(
+            start_date, end_date, only_unpublished=False
+        )
+        # Held posts count from their release, they may be older than the range
+        loaded = {post.link for post in posts}
+        released = await RSSPostRepository.get_released(
+            start_date, end_date, only_unpublished=False
+        )
+        posts += [post for post in released if post.link not in loaded]
+
+        delivered = await PostDeliveryRepository.get_delivered_links(
+            target, [post.link for post in posts]
+        )
+        candidates = [post for post in reversed(posts) if post.link not in delivered]
+        candidates, held = hold_embargoed(candidates, load_rules(), end_date)
+        for post in held:
+            await RSSPostRepository.set_embargo(post.link, post.embargo_until)
+        selected = select_posts(candidates, select, sensitive)[: settings.max_posts]
+        logger.info(f"Selected {len(selected)} of {len(candidates)} new posts for Mastodon")
+
+        client = MastodonClient(settings.base_url, settings.access_token, settings.timeout)
+        posted = 0
+        for post, is_sensitive in selected:
+            try:
+                await throttle("mastodon")
+            except QuotaExhausted as e:
+                # The rest stay undelivered and go out on a later run
+                logger.error(f"Stopped posting to Mastodon: {e}")
+                break
+            try:
+                status_id = await asyncio.to_thread(
+                    publish_post, client, post, is_sensitive, target
+                )
+            except Exception as e:
+                logger.error(f"Failed to post {post.link} to Mastodon: {e}")
+                continue
+            await PostDeliveryRepository.record(post.link, target, status_id)
+            posted += 1
+
+        failed = len(selected) - posted
+        print(f"✓ Posted {posted} posts to Mastodon")
+        if settings.share_count_days:
+            since = end_date - timedelta(days=settings.share_count_days)
+            counted = await refresh_shares(client, target, since)
+            logger.info(f"Refreshed the share counts of {counted} statuses")
+        if failed:
+            raise RuntimeError(f"Failed to post {failed} posts to Mastodon")
+
+        logger.info("Mastodon Publisher service completed successfully")
+        return {"posted_count": posted}
+
+    except Exception as e:
+        logger.error(f"Error: {e}", exc_info=True)
+        print(f"Error: {e}")
+        raise
+
+
+if __name__ == "__main__":
+    asyncio.run(main())
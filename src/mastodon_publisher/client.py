@@ -0,0 +1,130 @@
+"""Minimal Mastodon REST API client."""
+
+import logging
+import time
+from typing import List, Optional
+
+import requests
+
+from common.quotas import tracker
+
+logger = logging.getLogger(__name__)
+
+
+class MastodonClient:
+    """Thin wrapper around the Mastodon statuses and media endpoints."""
+
+    def __init__(self, base_url: str, access_token: str, timeout: int = 30):
+        """
+        Initialize Mastodon client.
+
+        Args:
+            base_url: Instance URL, e.g. 'https://mastodon.social'
+            access_token: Application access token
+            timeout: Request timeout in seconds
+        """
+        self.base_url = base_url.rstrip("/")
+        self.timeout = timeout
+        self.session = requests.Session()
+        self.session.headers["Authorization"] = f"Bearer {access_token}"
+
+    def _request(self, method: str, path: str, **kwargs) -> requests.Response:
+        response = self.session.request(
+            method, f"{self.base_url}{path}", timeout=self.timeout, **kwargs
+        )
+        tracker.observe("mastodon", response.headers)
+        response.raise_for_status()
+        return response
+
+    def upload_media(
+        self,
+        data: bytes,
+        filename: str,
+        content_type: str,
+        description: str = "",
+        wait_seconds: float = 30,
+    ) -> str:
+        """
+        Upload an image attachment.
+
+        Large files are processed asynchronously (HTTP 202, then 206 while
+        polling), so this waits until the attachment can be used in a status.
+
+        Args:
+            data: File contents
+            filename: File name sent with the upload
+            content_type: MIME type, e.g. 'image/jpeg'
+            description: Alt text
+            wait_seconds: Maximum time to wait for processing
+
+        Returns:
+            Media attachment ID
+
+        Raises:
+            requests.HTTPError: If the instance rejects the upload
+            TimeoutError: If processing doesn't finish in time
+        """
+        response = self._request(
+            "POST",
+            "/api/v2/media",
+            files={"file": (filename, data, content_type)},
+            data={"description": description[:1500]} if description else None,
+        )
+        media = response.json()
+
+        deadline = time.monotonic() + wait_seconds
+        while response.status_code in (202, 206):
+            if time.monotonic() > deadline:
+                raise TimeoutError(f"Media {media['id']} is still processing")
+            time.sleep(1)
+            response = self._request("GET", f"/api/v1/media/{media['id']}")
+            media = response.json()
+
+        return media["id"]
+
+    def post_status(
+        self,
+        text: str,
+        media_ids: Optional[List[str]] = None,
+        sensitive: bool = False,
+        spoiler_text: str = "",
+        visibility: str = "public",
+        idempotency_key: str = "",
+    ) -> dict:
+        """
+        Publish a status.
+
+        Args:
+            text: Status text
+            media_ids: Uploaded attachment IDs
+            sensitive: Mark media as sensitive
+            spoiler_text: Content warning shown instead of the text
+            visibility: public, unlisted, private or direct
+            idempotency_key: Makes retries of the same status safe
+
+        Returns:
+            Created status as returned by the API
+
+        Raises:
+            requests.HTTPError: If the instance rejects the status
+        """
+        payload = {
+            "status": text,
+            "media_ids": media_ids or [],
+            "sensitive": sensitive,
+            "visibility": visibility,
+        }
+        if spoiler_text:
+            payload["spoiler_text"] = spoiler_text
+
+        headers = {"Idempotency-Key": idempotency_key} if idempotency_key else {}
+        return self._request("POST", "/api/v1/statuses", json=payload, headers=headers).json()
+
+    def get_status(self, status_id: str) -> dict:
+        """
+        Fetch a status, with its reblog and favourite counts.
+
+        Raises:
+            requests.HTTPError: If the status can't be read (e.g. it was deleted)
+        """
+        return self._request("GET", f"/api/v1/statuses/{status_id}").json()
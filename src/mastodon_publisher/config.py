@@ -0,0 +1,67 @@
+"""Configuration for Mastodon Publisher service."""
+
+import os
+from dataclasses import dataclass
+from pathlib import Path
+from dotenv import load_dotenv
+
+# Load .env file if it exists
+env_path = Path(__file__).parent.parent.parent / ".env"
+if env_path.exists():
+    load_dotenv(env_path)
+
+
+@dataclass
+class MastodonPublisherConfig:
+    """Mastodon Publisher configuration settings."""
+
+    # Mastodon API (the token needs the write:statuses, write:media and read:statuses scopes)
+    base_url: str = os.getenv("MASTODON_BASE_URL", "")
+    access_token: str = os.getenv("MASTODON_ACCESS_TOKEN", "")
+    timeout: int = int(os.getenv("MASTODON_TIMEOUT", "30"))
+
+    # Selection: rule expressions over post.* (see RULES_FILE)
+    select: str = os.getenv("MASTODON_SELECT", "")
+    sensitive: str = os.getenv(
+        "MASTODON_SENSITIVE", '"18+" in post.tags || post.content.contains("18+")'
+    )
+
+    # Status settings
+    spoiler_text: str = os.getenv("MASTODON_SPOILER_TEXT", "18+")
+    visibility: str = os.getenv("MASTODON_VISIBILITY", "public")
+    max_chars: int = int(os.getenv("MASTODON_MAX_CHARS", "500"))
+    max_media: int = int(os.getenv("MASTODON_MAX_MEDIA", "4"))
+    max_media_bytes: int = int(os.getenv("MASTODON_MAX_MEDIA_MB", "8")) * 1024 * 1024
+
+    # Posts to consider per run
+    days_back: int = int(os.getenv("MASTODON_DAYS_BACK", "1"))
+    max_posts: int = int(os.getenv("MASTODON_MAX_POSTS", "10"))
+    # Days to keep refreshing the share counts of posted statuses (0 to not count them)
+    share_count_days: int = int(os.getenv("MASTODON_SHARE_COUNT_DAYS", "14"))
+
+    def validate(self) -> bool:
+        """
+        Validate configuration.
+
+        Returns:
+            True if configuration is valid
+
+        Raises:
+            ValueError: If configuration is invalid
+        """
+        if not self.base_url or not self.access_token:
+            raise ValueError("MASTODON_BASE_URL and MASTODON_ACCESS_TOKEN are required")
+
+        if not self.select:
+            raise ValueError("MASTODON_SELECT expression is required")
+
+        if self.visibility not in ("public", "unlisted", "private", "direct"):
+            raise ValueError("MASTODON_VISIBILITY must be public, unlisted, private or direct")
+
+        if not 0 <= self.max_media <= 4:
+            raise ValueError("MASTODON_MAX_MEDIA must be between 0 and 4")
+
+        return True
+
+
+mastodon_publisher_settings = MastodonPublisherConfig()
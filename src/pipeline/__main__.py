@@ -3,6 +3,7 @@
 Run with:
     python -m src.pipeline                    # Run pipeline once
     python -m src.pipeline --schedule         # Run on schedule
+    python -m src.pipeline --priority         # Run the priority lane once
     python -m src.pipeline --help             # Show help
 """
 
@@ -28,6 +29,9 @@ Examples:
   # Run on schedule (reads PIPELINE_INTERVAL_MINUTES from env)
   python -m src.pipeline --schedule
 
+  # Read and publish the priority sources only
+  PRIORITY_SOURCES=city_emergency python -m src.pipeline --priority
+
   # Run with custom interval
   python -m src.pipeline --schedule --interval 30
 
@@ -47,16 +51,29 @@ Environment Variables:
   PIPELINE_MAX_RETRIES         Max retry attempts (default: 3)
   PIPELINE_RETRY_DELAY         Delay between retries in seconds (default: 30)
   PIPELINE_LOG_LEVEL           Log level (default: INFO)
+  PRIORITY_SOURCES             Comma-separated channels/sources published right away
+  PRIORITY_INTERVAL_MINUTES    Priority lane interval between scheduled runs (default: 5)
   
   # Agent Timeouts (seconds)
   RSS_READER_TIMEOUT           RSS Reader timeout (default: 300)
   SUMMARIZER_TIMEOUT           Summarizer timeout (default: 180)
   DIGEST_PUBLISHER_TIMEOUT     Digest Publisher timeout (default: 120)
+  ANALYTICS_EXPORTER_TIMEOUT   Analytics Exporter timeout (default: 300)
+  MASTODON_PUBLISHER_TIMEOUT   Mastodon Publisher timeout (default: 300)
   
   # Agent Control
   SKIP_RSS_READER              Skip RSS Reader (default: false)
   SKIP_SUMMARIZER              Skip Summarizer (default: false)
   SKIP_DIGEST_PUBLISHER        Skip Digest Publisher (default: false)
+  ANALYTICS_EXPORT_ENABLED     Export posts to ClickHouse (default: false)
+  MASTODON_ENABLED             Post selected events to Mastodon (default: false)
+  RULES_FILE                   JSON file with expression rules, validated at startup
+
+  # Operator Alerts
+  ALERT_TELEGRAM_CHAT_ID       Telegram chat for alerts (uses TELEGRAM_BOT_TOKEN)
+  ALERT_SLACK_WEBHOOK_URL      Slack incoming webhook for alerts
+  ALERT_COOLDOWN_MINUTES       Minimum interval between identical alerts (default: 60)
+  ALERT_BACKLOG_THRESHOLD      Alert when this many posts are unpublished (default: 500)
   
   # Database
   DB_POOL_SIZE                 Database connection pool size (default: 10)
@@ -84,6 +101,12 @@ Environment Variables:
         help="Interval between runs in minutes (default: from env or 60)",
     )
 
+    parser.add_argument(
+        "--priority",
+        action="store_true",
+        help="Run the priority lane (PRIORITY_SOURCES) once instead of the full pipeline",
+    )
+
     parser.add_argument(
         "--skip-rss-reader",
         action="store_true",
@@ -174,7 +197,14 @@ async def main():
 
     # Run pipeline
     try:
-        if config.schedule_enabled or args.schedule:
+        if args.priority:
+            if not config.priority_source_names:
+                print("Configuration error: PRIORITY_SOURCES is not set", file=sys.stderr)
+                sys.exit(1)
+            results = await orchestrator.run_priority_lane()
+            if any(r.status.value == "failed" for r in results):
+                sys.exit(1)
+        elif config.schedule_enabled or args.schedule:
             await orchestrator.run_scheduled()
         else:
             results = await orchestrator.run_pipeline()
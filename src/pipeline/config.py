@@ -2,6 +2,9 @@
 
 import os
 from dataclasses import dataclass
+from typing import Set
+
+from common.rules import load_rules
 
 
 @dataclass
@@ -12,9 +15,16 @@ class PipelineConfig:
     run_interval_minutes: int = int(os.getenv("PIPELINE_INTERVAL_MINUTES", "60"))
     schedule_enabled: bool = os.getenv("PIPELINE_SCHEDULE_ENABLED", "false").lower() == "true"
 
+    # Priority lane: sources read and published on their own between scheduled runs
+    priority_sources: str = os.getenv("PRIORITY_SOURCES", "")
+    priority_interval_minutes: int = int(os.getenv("PRIORITY_INTERVAL_MINUTES", "5"))
+
     # Agent timeouts (in seconds)
     rss_reader_timeout: int = int(os.getenv("RSS_READER_TIMEOUT", "300"))
+    summarizer_timeout: int = int(os.getenv("SUMMARIZER_TIMEOUT", "180"))
     digest_publisher_timeout: int = int(os.getenv("DIGEST_PUBLISHER_TIMEOUT", "120"))
+    analytics_exporter_timeout: int = int(os.getenv("ANALYTICS_EXPORTER_TIMEOUT", "300"))
+    mastodon_publisher_timeout: int = int(os.getenv("MASTODON_PUBLISHER_TIMEOUT", "300"))
 
     # Retry settings
     max_retries: int = int(os.getenv("PIPELINE_MAX_RETRIES", "3"))
@@ -22,8 +32,18 @@ class PipelineConfig:
 
     # Agent control
     skip_rss_reader: bool = os.getenv("SKIP_RSS_READER", "false").lower() == "true"
+    skip_summarizer: bool = os.getenv("SKIP_SUMMARIZER", "false").lower() == "true"
     skip_digest_publisher: bool = os.getenv("SKIP_DIGEST_PUBLISHER", "false").lower() == "true"
 
+    # Optional sinks
+    analytics_export_enabled: bool = (
+        os.getenv("ANALYTICS_EXPORT_ENABLED", "false").lower() == "true"
+    )
+    mastodon_enabled: bool = os.getenv("MASTODON_ENABLED", "false").lower() == "true"
+
+    # Expression rules (validated at startup)
+    rules_file: str = os.getenv("RULES_FILE", "")
+
     # Logging
     log_level: str = os.getenv("PIPELINE_LOG_LEVEL", "INFO")
 
@@ -36,6 +56,9 @@ class PipelineConfig:
         if self.run_interval_minutes < 1:
             raise ValueError("run_interval_minutes must be at least 1")
 
+        if self.priority_interval_minutes < 1:
+            raise ValueError("priority_interval_minutes must be at least 1")
+
         if self.max_retries < 0:
             raise ValueError("max_retries must be non-negative")
 
@@ -46,6 +69,15 @@ class PipelineConfig:
         if self.log_level.upper() not in valid_log_levels:
             raise ValueError(f"log_level must be one of {valid_log_levels}")
 
+        # Compile rules up front so a typo fails the pipeline before any agent runs
+        if self.rules_file:
+            load_rules(self.rules_file)
+
+    @property
+    def priority_source_names(self) -> Set[str]:
+        """Channel and external source names of the priority lane."""
+        return {name.strip() for name in self.priority_sources.split(",") if name.strip()}
+
     def __post_init__(self):
         """Validate config after initialization."""
         self.validate()
@@ -3,10 +3,11 @@
 import asyncio
 import logging
 import sys
-from datetime import datetime
 from typing import Dict, List, Optional, Any
 from enum import Enum
 
+from common import clock
+from common.ids import new_id
 from .config import PipelineConfig
 
 
@@ -36,7 +37,7 @@ class AgentResult:
         self.duration = duration
         self.error = error
         self.metadata = metadata or {}
-        self.timestamp = datetime.now()
+        self.timestamp = clock.now()
 
     def __repr__(self):
         return f"AgentResult({self.agent_name}, {self.status.value}, duration={self.duration}s)"
@@ -106,7 +107,7 @@ class PipelineOrchestrator:
                     self.logger.info(
                         f"🔄 Retrying {agent_name} (attempt {attempt + 2}/{self.config.max_retries + 1})"
                     )
-                    await asyncio.sleep(self.config.retry_delay_seconds)
+                    await clock.sleep(self.config.retry_delay_seconds)
                 else:
                     duration = asyncio.get_event_loop().time() - start_time
                     return AgentResult(
@@ -121,21 +122,29 @@ class PipelineOrchestrator:
                     self.logger.info(
                         f"🔄 Retrying {agent_name} (attempt {attempt + 2}/{self.config.max_retries + 1})"
                     )
-                    await asyncio.sleep(self.config.retry_delay_seconds)
+                    await clock.sleep(self.config.retry_delay_seconds)
                 else:
                     duration = asyncio.get_event_loop().time() - start_time
                     return AgentResult(
                         agent_name, AgentStatus.FAILED, duration=duration, error=error_msg
                     )
 
-    async def _run_rss_reader(self) -> Dict[str, Any]:
-        """Run the RSS Reader agent."""
+    async def _run_rss_reader(self, run_id: Optional[str] = None) -> Dict[str, Any]:
+        """Run the RSS Reader agent; retries pass the same poll run ID."""
         from rss_reader.__main__ import main as rss_reader_main
 
         async with asyncio.timeout(self.config.rss_reader_timeout):
-            result = await rss_reader_main()
+            result = await rss_reader_main(run_id=run_id)
             return {"posts_saved": result.get("saved_count", 0)} if result else {}
 
+    async def _run_summarizer(self) -> Dict[str, Any]:
+        """Run the Summarizer agent."""
+        from summarizer.__main__ import main as summarizer_main
+
+        async with asyncio.timeout(self.config.summarizer_timeout):
+            result = await summarizer_main()
+            return {"posts_summarized": result.get("summarized_count", 0)} if result else {}
+
     async def _run_digest_publisher(self) -> Dict[str, Any]:
         """Run the Digest Publisher agent."""
         from digest_publisher.__main__ import main as publisher_main
@@ -144,6 +153,103 @@ class PipelineOrchestrator:
             result = await publisher_main()
             return {"digests_published": result.get("published_count", 0)} if result else {}
 
+    async def _run_analytics_exporter(self) -> Dict[str, Any]:
+        """Run the Analytics Exporter agent."""
+        from analytics_exporter.__main__ import main as exporter_main
+
+        async with asyncio.timeout(self.config.analytics_exporter_timeout):
+            result = await exporter_main()
+            return {"posts_exported": result.get("exported_count", 0)} if result else {}
+
+    async def _run_mastodon_publisher(self) -> Dict[str, Any]:
+        """Run the Mastodon Publisher agent."""
+        from mastodon_publisher.__main__ import main as mastodon_main
+
+        async with asyncio.timeout(self.config.mastodon_publisher_timeout):
+            result = await mastodon_main()
+            return {"posts_posted": result.get("posted_count", 0)} if result else {}
+
+    async def _run_priority_reader(self, context: Dict[str, Any]) -> Dict[str, Any]:
+        """Read the priority sources, noting the new posts."""
+        from rss_reader.__main__ import main as rss_reader_main
+
+        async with asyncio.timeout(self.config.rss_reader_timeout):
+            result = (
+                await rss_reader_main(self.config.priority_source_names, context["run_id"]) or {}
+            )
+            context["links"] = result.get("saved_links", [])
+            return {"posts_saved": len(context["links"])}
+
+    async def _run_priority_summarizer(self, context: Dict[str, Any]) -> Dict[str, Any]:
+        """Summarize the new priority posts."""
+        from summarizer.__main__ import main as summarizer_main
+
+        async with asyncio.timeout(self.config.summarizer_timeout):
+            result = await summarizer_main(context["links"])
+            return {"posts_summarized": result.get("summarized_count", 0)} if result else {}
+
+    async def _run_priority_publisher(self, context: Dict[str, Any]) -> Dict[str, Any]:
+        """Publish the new priority posts without waiting for a digest."""
+        from digest_publisher.__main__ import publish_now
+
+        async with asyncio.timeout(self.config.digest_publisher_timeout):
+            result = await publish_now(context["links"])
+            return {"posts_published": result.get("published_count", 0)}
+
+    async def run_priority_lane(self) -> List[AgentResult]:
+        """Read, summarize and publish posts of the priority sources right away.
+
+        Returns:
+            List of AgentResult objects for each agent
+        """
+        from common.db.session import db
+
+        await db.connect()
+        results = []
+        # Retries of the reader continue the same poll run instead of starting over
+        context: Dict[str, Any] = {"links": [], "run_id": new_id()}
+
+        result = await self._run_agent_with_retry(
+            "PriorityReader", lambda: self._run_priority_reader(context)
+        )
+        results.append(result)
+
+        # Nothing new is the usual case; don't touch the other agents then
+        if result.status == AgentStatus.SUCCESS and context["links"]:
+            result = await self._run_agent_with_retry(
+                "PrioritySummarizer",
+                lambda: self._run_priority_summarizer(context),
+                self.config.skip_summarizer,
+            )
+            results.append(result)
+
+            result = await self._run_agent_with_retry(
+                "PriorityPublisher",
+                lambda: self._run_priority_publisher(context),
+                self.config.skip_digest_publisher,
+            )
+            results.append(result)
+
+        self.results = results
+        await self._send_alerts()
+        await db.disconnect()
+        return results
+
+    async def _wait_for_next_run(self) -> None:
+        """Sleep until the next scheduled run, running the priority lane meanwhile."""
+        remaining = self.config.run_interval_minutes * 60
+        step = self.config.priority_interval_minutes * 60
+        while remaining > 0:
+            if not self.config.priority_source_names or step >= remaining:
+                await clock.sleep(remaining)
+                return
+            await clock.sleep(step)
+            remaining -= step
+            try:
+                await self.run_priority_lane()
+            except Exception as e:
+                self.logger.error(f"💥 Priority lane failed: {e}", exc_info=True)
+
     async def run_pipeline(self) -> List[AgentResult]:
         """Execute the full pipeline.
 
@@ -164,26 +270,49 @@ class PipelineOrchestrator:
         pipeline_start = asyncio.get_event_loop().time()
         self.results = []
 
-        # Agent 1: RSS Reader
+        # Agent 1: RSS Reader (retries continue the same poll run instead of starting over)
+        run_id = new_id()
         result = await self._run_agent_with_retry(
-            "RSSReader", self._run_rss_reader, self.config.skip_rss_reader
+            "RSSReader", lambda: self._run_rss_reader(run_id), self.config.skip_rss_reader
         )
         self.results.append(result)
 
         # Stop pipeline if critical agent failed
         if result.status == AgentStatus.FAILED:
             self.logger.error("⛔ RSSReader failed - stopping pipeline")
+            await self._send_alerts()
             return self.results
 
-        # Agent 2: Digest Publisher
+        # Agent 2: Summarizer (digests fall back to post content without summaries)
+        result = await self._run_agent_with_retry(
+            "Summarizer", self._run_summarizer, self.config.skip_summarizer
+        )
+        self.results.append(result)
+
+        # Agent 3: Digest Publisher
         result = await self._run_agent_with_retry(
             "DigestPublisher", self._run_digest_publisher, self.config.skip_digest_publisher
         )
         self.results.append(result)
 
+        # Agent 4: Analytics Exporter (optional sink, never stops the pipeline)
+        result = await self._run_agent_with_retry(
+            "AnalyticsExporter",
+            self._run_analytics_exporter,
+            not self.config.analytics_export_enabled,
+        )
+        self.results.append(result)
+
+        # Agent 5: Mastodon Publisher (optional, never stops the pipeline)
+        result = await self._run_agent_with_retry(
+            "MastodonPublisher", self._run_mastodon_publisher, not self.config.mastodon_enabled
+        )
+        self.results.append(result)
+
         # Summary
         pipeline_duration = asyncio.get_event_loop().time() - pipeline_start
         self._print_summary(pipeline_duration)
+        await self._send_alerts()
 
         # Disconnect from database
         from common.db.session import db
@@ -193,6 +322,49 @@ class PipelineOrchestrator:
 
         return self.results
 
+    async def _send_alerts(self):
+        """Notify operators about failed agents, low quotas, leftover HTML and a growing backlog."""
+        from common.alerts import Alert, AlertManager, alert_settings
+        from common.db.repository import RSSPostRepository
+        from common.drift import check_content_drift
+        from common.quotas import save_quotas
+
+        alerts = AlertManager.from_settings()
+
+        for result in self.results:
+            key = f"agent_failed:{result.agent_name}"
+            if result.status == AgentStatus.FAILED:
+                await alerts.notify(Alert(key, f"Pipeline: {result.error}"))
+            elif result.status == AgentStatus.SUCCESS:
+                alerts.resolve(key)
+
+        try:
+            await save_quotas(alerts)
+        except Exception as e:
+            self.logger.error(f"Failed to save API quotas: {e}")
+
+        try:
+            await check_content_drift(alerts)
+        except Exception as e:
+            self.logger.error(f"Failed to check posts for leftover HTML: {e}")
+
+        try:
+            backlog = await RSSPostRepository.count_unpublished()
+        except Exception as e:
+            self.logger.error(f"Failed to check publish backlog: {e}")
+            return
+
+        if backlog >= alert_settings.backlog_threshold:
+            await alerts.notify(
+                Alert(
+                    "backlog:rss_posts",
+                    f"Pipeline: {backlog} posts are waiting to be published",
+                    severity="warning",
+                )
+            )
+        else:
+            alerts.resolve("backlog:rss_posts")
+
     def _print_summary(self, duration: float):
         """Print execution summary."""
         self.logger.info("=" * 80)
@@ -233,12 +405,17 @@ class PipelineOrchestrator:
         self.logger.info(
             f"📅 Starting scheduled pipeline (interval: {self.config.run_interval_minutes}m)"
         )
+        if self.config.priority_source_names:
+            self.logger.info(
+                f"⚡ Priority lane every {self.config.priority_interval_minutes}m for: "
+                f"{', '.join(sorted(self.config.priority_source_names))}"
+            )
 
         while True:
             try:
                 await self.run_pipeline()
                 self.logger.info(f"⏰ Next run in {self.config.run_interval_minutes} minutes")
-                await asyncio.sleep(self.config.run_interval_minutes * 60)
+                await self._wait_for_next_run()
 
             except KeyboardInterrupt:
                 self.logger.info("⏹️  Stopping scheduled pipeline")
@@ -247,4 +424,4 @@ class PipelineOrchestrator:
             except Exception as e:
                 self.logger.error(f"💥 Unexpected error in scheduled pipeline: {e}", exc_info=True)
                 self.logger.info(f"⏰ Retrying in {self.config.run_interval_minutes} minutes")
-                await asyncio.sleep(self.config.run_interval_minutes * 60)
+                await clock.sleep(self.config.run_interval_minutes * 60)
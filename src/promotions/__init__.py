@@ -0,0 +1 @@
+"""Promotions Service - Manages sponsored posts and billing reports."""
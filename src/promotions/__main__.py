@@ -0,0 +1,171 @@
+"""Entry point for Promotions service.
+
+Run with:
+    python -m src.promotions add LINK --sponsor NAME --start DATE --end DATE
+    python -m src.promotions list [--active]
+    python -m src.promotions report --from DATE --to DATE [--csv PATH]
+    python -m src.promotions delete ID
+"""
+
+import argparse
+import asyncio
+import logging
+import sys
+from datetime import datetime
+from decimal import Decimal
+
+from common import clock
+from common.db.session import db
+from common.db.repository import PromotionRepository, RSSPostRepository
+from common.db.models import Promotion
+from .report import report_rows, totals_by_sponsor, write_csv
+
+logging.basicConfig(
+    level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
+)
+logger = logging.getLogger(__name__)
+
+
+def parse_args():
+    """Parse command line arguments."""
+    parser = argparse.ArgumentParser(description="Manage sponsored posts")
+    subparsers = parser.add_subparsers(dest="command", required=True)
+
+    add_parser = subparsers.add_parser("add", help="Mark a post as sponsored")
+    add_parser.add_argument("link", metavar="LINK", help="Link of a stored post")
+    add_parser.add_argument("--sponsor", required=True, help="Sponsor name")
+    add_parser.add_argument(
+        "--start", required=True, type=datetime.fromisoformat, help="Start (ISO date/time)"
+    )
+    add_parser.add_argument(
+        "--end", required=True, type=datetime.fromisoformat, help="End, exclusive (ISO date/time)"
+    )
+    add_parser.add_argument(
+        "--placement",
+        choices=["top", "bottom"],
+        default="top",
+        help="Position in the digest (default: top)",
+    )
+    add_parser.add_argument(
+        "--target",
+        metavar="PUBLISHER:DESTINATION",
+        help="Only place in this output, e.g. telegram:@city_concerts (default: all)",
+    )
+    add_parser.add_argument("--max-placements", type=int, help="Stop after N digests")
+    add_parser.add_argument(
+        "--price", type=Decimal, default=Decimal("0"), help="Price per placement"
+    )
+    add_parser.add_argument("--currency", default="RUB", help="Currency code (default: RUB)")
+
+    list_parser = subparsers.add_parser("list", help="List promotions")
+    list_parser.add_argument("--active", action="store_true", help="Only running promotions")
+
+    report_parser = subparsers.add_parser("report", help="Billing report for a period")
+    report_parser.add_argument(
+        "--from", dest="start", required=True, type=datetime.fromisoformat, help="Period start"
+    )
+    report_parser.add_argument(
+        "--to", dest="end", required=True, type=datetime.fromisoformat, help="Period end"
+    )
+    report_parser.add_argument("--csv", metavar="PATH", help="Write the report as CSV")
+
+    delete_parser = subparsers.add_parser("delete", help="Delete a promotion")
+    delete_parser.add_argument("id", type=int, help="Promotion ID")
+
+    return parser.parse_args()
+
+
+async def add(args) -> int:
+    """Create a promotion from CLI arguments."""
+    if args.end <= args.start:
+        raise ValueError("--end must be after --start")
+    if not await RSSPostRepository.exists_by_link(args.link):
+        raise ValueError(f"Post not found: {args.link}")
+
+    promotion = Promotion(
+        post_link=args.link,
+        sponsor=args.sponsor,
+        starts_at=args.start,
+        ends_at=args.end,
+        placement=args.placement,
+        target=args.target,
+        max_placements=args.max_placements,
+        price_per_placement=args.price,
+        currency=args.currency.upper(),
+    )
+    return await PromotionRepository.create(promotion)
+
+
+async def list_promotions(active_only: bool) -> None:
+    """Print promotions."""
+    if active_only:
+        promotions = await PromotionRepository.get_active(clock.now())
+    else:
+        promotions = await PromotionRepository.get_all()
+
+    if not promotions:
+        print("No promotions found.")
+        return
+
+    for p in promotions:
+        limit = f"/{p.max_placements}" if p.max_placements is not None else ""
+        print(
+            f"#{p.id} {p.sponsor}: {p.post_link}\n"
+            f"  {p.starts_at:%Y-%m-%d %H:%M} → {p.ends_at:%Y-%m-%d %H:%M}, "
+            f"{p.placement}, target: {p.target or 'all'}, "
+            f"placements: {p.placements}{limit}, "
+            f"{p.price_per_placement} {p.currency}/placement"
+        )
+
+
+async def report(start: datetime, end: datetime, csv_path: str = "") -> None:
+    """Print a billing report and optionally save it as CSV."""
+    rows = report_rows(await PromotionRepository.get_report(start, end))
+
+    if csv_path:
+        with open(csv_path, "w", encoding="utf-8", newline="") as f:
+            write_csv(rows, f)
+        print(f"✓ Report saved to {csv_path}")
+
+    print(f"Placements from {start:%Y-%m-%d} to {end:%Y-%m-%d}:")
+    for row in rows:
+        print(
+            f"  #{row['promotion_id']} {row['sponsor']}: {row['placements']} × "
+            f"{row['price_per_placement']} = {row['amount']} {row['currency']}"
+        )
+    for sponsor, amounts in totals_by_sponsor(rows).items():
+        total = ", ".join(f"{amount:.2f} {currency}" for currency, amount in amounts.items())
+        print(f"💰 {sponsor}: {total}")
+
+
+async def main():
+    """Main entry point for Promotions service."""
+    args = parse_args()
+
+    try:
+        if not db.pool:
+            await db.connect()
+            logger.info("Connected to database")
+
+        if args.command == "add":
+            promotion_id = await add(args)
+            print(f"✓ Created promotion #{promotion_id}")
+        elif args.command == "list":
+            await list_promotions(args.active)
+        elif args.command == "report":
+            await report(args.start, args.end, args.csv)
+        else:
+            await PromotionRepository.delete(args.id)
+            print(f"✓ Deleted promotion #{args.id}")
+
+    except Exception as e:
+        logger.error(f"Error: {e}", exc_info=True)
+        print(f"Error: {e}", file=sys.stderr)
+        sys.exit(1)
+
+    finally:
+        await db.disconnect()
+
+
+if __name__ == "__main__":
+    asyncio.run(main())
@@ -0,0 +1,67 @@
+"""Billing reports for sponsored posts."""
+
+import csv
+from collections import defaultdict
+from decimal import Decimal
+from typing import Dict, List, TextIO
+
+REPORT_COLUMNS = [
+    "promotion_id",
+    "sponsor",
+    "post_link",
+    "starts_at",
+    "ends_at",
+    "placements",
+    "price_per_placement",
+    "amount",
+    "currency",
+]
+
+
+def report_rows(rows: List[dict]) -> List[dict]:
+    """
+    Normalize repository report rows into billing rows.
+
+    Args:
+        rows: Rows from PromotionRepository.get_report
+
+    Returns:
+        Rows keyed by REPORT_COLUMNS
+    """
+    return [
+        {
+            "promotion_id": row["id"],
+            "sponsor": row["sponsor"],
+            "post_link": row["post_link"],
+            "starts_at": row["starts_at"].isoformat(),
+            "ends_at": row["ends_at"].isoformat(),
+            "placements": row["placements"],
+            "price_per_placement": f"{Decimal(row['price_per_placement']):.2f}",
+            "amount": f"{Decimal(row['amount']):.2f}",
+            "currency": row["currency"],
+        }
+        for row in rows
+    ]
+
+
+def totals_by_sponsor(rows: List[dict]) -> Dict[str, Dict[str, Decimal]]:
+    """
+    Sum billing amounts per sponsor and currency.
+
+    Args:
+        rows: Rows from report_rows
+
+    Returns:
+        {sponsor: {currency: amount}}
+    """
+    totals: Dict[str, Dict[str, Decimal]] = defaultdict(lambda: defaultdict(Decimal))
+    for row in rows:
+        totals[row["sponsor"]][row["currency"]] += Decimal(row["amount"])
+    return {sponsor: dict(amounts) for sponsor, amounts in totals.items()}
+
+
+def write_csv(rows: List[dict], out: TextIO) -> None:
+    """Write billing rows as CSV."""
+    writer = csv.DictWriter(out, fieldnames=REPORT_COLUMNS)
+    writer.writeheader()
+    writer.writerows(rows)
@@ -1,18 +1,44 @@
 """Entry point for RSS Reader service.
 
-Run with: python -m src.rss_reader
+Run with: python -m src.rss_reader [--run-id POLL_RUN_ID]
 """
 
 import asyncio
 import logging
-import json
-from typing import Tuple
+import os
+import sys
+from collections import Counter
+from dataclasses import replace
+from datetime import timedelta
+from typing import Collection, List, Optional, Tuple
+from urllib.parse import parse_qs, urlsplit
 
 from common.db.session import db
-from common.db.repository import RSSPostRepository, TelegramChannelRepository
-from common.db.models import RSSPost, TelegramChannel
-from common.utils.rss_bridge import build_rss_bridge_url
-from .core.parser import RSSParser
+from common import clock
+from common.db.repository import (
+    PollRunRepository,
+    RSSPostRepository,
+    TelegramChannelRepository,
+)
+from common.db.models import TelegramChannel
+from common.alerts import Alert, AlertManager, alert_settings
+from common.features import feature_flags
+from common.ids import parse_id
+from common.rules import load_rules
+from common.models.feed import RSSItem
+from common.revisions import record_edit
+from .core.bandwidth import BandwidthMeter
+from .core.bridges import BridgePool
+from .core.external import load_external_sources
+from .core.filters import PostFilter, RuleFilter, load_filters
+from .core.ingest import apply_filters, build_post, source_guid, store_post
+from .core.fetcher import FeedNotModified
+from .core.parser import ParseOptions, RSSParser
+from .core.preview import build_preview_url
+from .core.recordings import Recordings
+from .core import report as outcomes
+from .core.report import PollReport
+from .core.source import Source, TelegramSource
 
 logging.basicConfig(
     level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
@@ -20,78 +46,237 @@ logging.basicConfig(
 logger = logging.getLogger(__name__)
 
 
-async def process_channel(
-    channel: TelegramChannel, parser: RSSParser
-) -> Tuple[str, int, int, int, int]:
+async def save_items(
+    source_name: str,
+    items: List[RSSItem],
+    filters: Optional[List[PostFilter]] = None,
+    report: Optional[PollReport] = None,
+) -> Tuple[int, int, int, int, int]:
     """
-    Process a single Telegram channel.
+    Save parsed items as posts, skipping empty, filtered and already stored ones.
 
     Args:
-        channel: TelegramChannel instance
-        parser: RSSParser instance
+        source_name: Name of the source the items came from
+        items: Parsed feed items
+        filters: Rule and WASM filters to apply before saving
+        report: Report to record the outcome of each item in
 
     Returns:
-        Tuple of (channel_name, saved_count, skipped_count, empty_count, error_count)
+        Tuple of (saved_count, skipped_count, empty_count, filtered_count, error_count)
     """
     saved_count = 0
     skipped_count = 0
     error_count = 0
     empty_count = 0
+    filtered_count = 0
+    report = report or PollReport()
+
+    for item in items:
+        try:
+            # Skip if content is empty
+            if not item.description or not item.description.strip():
+                logger.debug(f"Skipping item with empty content: {item.link}")
+                empty_count += 1
+                report.add(source_name, item.link, outcomes.EMPTY)
+                continue
+
+            # Decided by an earlier attempt of this run
+            previous = report.recorded_outcome(source_name, item.link)
+            if previous:
+                report.add(source_name, item.link, previous.outcome, previous.reason)
+                if previous.outcome == outcomes.NEW:
+                    saved_count += 1
+                elif previous.outcome == outcomes.EMPTY:
+                    empty_count += 1
+                elif previous.outcome == outcomes.FILTERED:
+                    filtered_count += 1
+                else:
+                    skipped_count += 1
+                continue
+
+            # Check if item already exists; its link may have changed since, its ID doesn't
+            existing = await RSSPostRepository.get_by_link(item.link)
+            if not existing and item.guid:
+                existing = await RSSPostRepository.get_by_source_guid(
+                    source_guid(source_name, item)
+                )
+            if existing:
+                logger.debug(f"Skipping existing item: {item.link}")
+                skipped_count += 1
+                revision = await record_edit(existing, item.description)
+                if revision:
+                    reason = f"edited after it was saved (revision {revision})"
+                    report.add(source_name, item.link, outcomes.UPDATED, reason)
+                else:
+                    report.add(source_name, item.link, outcomes.DUPLICATE)
+                continue
+
+            # Run rule and user-defined filters; any of them may drop the post
+            decision = await apply_filters(source_name, item, filters)
+            if not decision.keep:
+                filtered_count += 1
+                report.add(source_name, item.link, outcomes.FILTERED, decision.reason)
+                continue
+
+            # Save to database
+            await store_post(await build_post(item, decision.tags, source_name))
+            saved_count += 1
+            report.add(source_name, item.link, outcomes.NEW)
+            logger.debug(f"Saved: {item.link}")
+
+        except Exception as e:
+            logger.error(f"Failed to save item {item.link} from {source_name}: {e}")
+            error_count += 1
+            report.add(source_name, item.link, outcomes.ERROR, str(e))
+
+    return (saved_count, skipped_count, empty_count, filtered_count, error_count)
 
+
+async def process_source(
+    source: Source,
+    filters: Optional[List[PostFilter]] = None,
+    report: Optional[PollReport] = None,
+) -> Tuple[str, int, int, int, int, int, int]:
+    """
+    Fetch a single source and save its items.
+
+    Args:
+        source: Telegram channel, external source or any other Source
+        filters: Rule and WASM filters to apply before saving
+        report: Report to record the outcome of each item in
+
+    Returns:
+        Tuple of (source_name, saved_count, skipped_count, empty_count, filtered_count,
+        error_count, warning_count); warnings are problems of a fetch that didn't fail it
+    """
     try:
-        # Build RSS bridge URL for the channel
-        rss_url = build_rss_bridge_url(channel.channel_name)
-        logger.info(f"Processing channel: {channel.channel_name} ({rss_url})")
-
-        # Parse the RSS feed
-        feed = parser.parse_url(rss_url)
-        logger.info(
-            f"✓ Channel: {channel.channel_name} - Feed: {feed.title} - Items: {len(feed.items)}"
-        )
+        items = await source.fetch()
+        logger.info(f"✓ Source: {source.name} - Items: {len(items)}")
+
+        for warning in source.warnings:
+            logger.warning(f"Source {source.name}: {warning}")
+        if report and source.warnings:
+            report.add(source.name, source.location, outcomes.WARNING, "; ".join(source.warnings))
 
         # Save items to database
-        for item in feed.items:
-            try:
-                # Skip if content is empty
-                if not item.description or not item.description.strip():
-                    logger.debug(f"Skipping item with empty content: {item.link}")
-                    empty_count += 1
-                    continue
+        try:
+            *counts, error_count = await save_items(source.name, items, filters, report)
+        except asyncio.CancelledError:
+            # The run timed out; its retry must get the items again, not a 304
+            source.forget()
+            raise
+        if error_count:
+            # Otherwise a 304 next run would hide the items that failed to save
+            source.forget()
+        if report:
+            await report.checkpoint(source.name)
+        return (source.name, *counts, error_count, len(source.warnings))
+
+    except FeedNotModified:
+        logger.info(f"✓ Source: {source.name} - not modified since the last run")
+        return (source.name, 0, 0, 0, 0, 0, 0)
+    except Exception as e:
+        logger.error(f"Failed to process source {source.name}: {e}", exc_info=True)
+        if report:
+            report.add(source.name, source.location, outcomes.ERROR, str(e))
+            await report.checkpoint(source.name)
+        return (source.name, 0, 0, 0, 0, 1, 0)
+
+
+def taken_over_result(
+    source: str, report: PollReport
+) -> Tuple[str, int, int, int, int, int, int]:
+    """Result of a source done by an earlier attempt of the run, from its stored outcomes."""
+    counts = Counter(item.outcome for item in report.take_over(source))
+    return (
+        source,
+        counts[outcomes.NEW],
+        counts[outcomes.DUPLICATE] + counts[outcomes.UPDATED],
+        counts[outcomes.EMPTY],
+        counts[outcomes.FILTERED],
+        0,
+        counts[outcomes.WARNING],
+    )
+
+
+def recorded_channels(
+    recordings: Recordings, known: List[TelegramChannel]
+) -> List[TelegramChannel]:
+    """
+    Channels with recorded feeds that aren't in the database.
 
-                # Check if item already exists
-                existing = await RSSPostRepository.get_by_link(item.link)
-                if existing:
-                    logger.debug(f"Skipping existing item: {item.link}")
-                    skipped_count += 1
-                    continue
-
-                # Convert RSSItem to RSSPost
-                media_json = json.dumps(item.media_urls) if item.media_urls else None
-                post = RSSPost(
-                    link=item.link,
-                    content=item.description,
-                    pub_date=item.pub_date,
-                    media=media_json,
-                )
+    Lets recordings from one environment be replayed in another, e.g. against
+    the in-memory backend.
+    """
+    names = {channel.channel_name for channel in known}
+    channels = []
+    for url in recordings.recorded_urls():
+        name = parse_qs(urlsplit(url).query).get("username", [""])[0]
+        if name and name not in names:
+            names.add(name)
+            channels.append(TelegramChannel(channel_id=0, channel_name=name))
+    return channels
 
-                # Save to database
-                await RSSPostRepository.create(post)
-                saved_count += 1
-                logger.debug(f"Saved: {item.link}")
 
-            except Exception as e:
-                logger.error(f"Failed to save item {item.link}: {e}")
-                error_count += 1
+async def save_report(run_id: str, report: PollReport) -> None:
+    """
+    Store the report of a run and drop reports older than POLL_REPORT_KEEP_DAYS.
 
+    A failure here is logged rather than raised: the posts are already saved.
+    """
+    keep_days = int(os.getenv("POLL_REPORT_KEEP_DAYS", "14"))
+    try:
+        await PollRunRepository.finish(run_id, report.items)
+        deleted = await PollRunRepository.delete_before(clock.now() - timedelta(days=keep_days))
+        if deleted:
+            logger.info(f"Deleted {deleted} poll run reports older than {keep_days} days")
     except Exception as e:
-        logger.error(f"Failed to process channel {channel.channel_name}: {e}", exc_info=True)
-        error_count += 1
+        logger.error(f"Failed to save poll run report {run_id}: {e}")
+
+
+async def alert_on_failures(results: list) -> None:
+    """
+    Notify operators about failed sources and spikes of save errors.
+
+    Parser warnings don't count: a source that was read with an item skipped
+    didn't fail.
+
+    Args:
+        results: Per-source result tuples from process_source
+    """
+    alerts = AlertManager.from_settings()
+    total_errors = 0
+
+    for result in results:
+        if isinstance(result, Exception):
+            continue
+        name, saved, skipped, empty, filtered, errors, _ = result
+        total_errors += errors
+
+        key = f"source_failure:{name}"
+        # A failed fetch yields a single error and nothing else
+        if errors and not (saved or skipped or empty or filtered):
+            await alerts.notify(Alert(key, f"RSS Reader: source '{name}' failed to fetch"))
+        else:
+            alerts.resolve(key)
+
+    if total_errors >= alert_settings.error_spike_threshold:
+        await alerts.notify(
+            Alert("error_spike:rss_reader", f"RSS Reader: {total_errors} errors in the last run")
+        )
+    else:
+        alerts.resolve("error_spike:rss_reader")
 
-    return (channel.channel_name, saved_count, skipped_count, empty_count, error_count)
 
+async def main(sources: Optional[Collection[str]] = None, run_id: Optional[str] = None):
+    """
+    Main entry point for RSS Reader service.
 
-async def main():
-    """Main entry point for RSS Reader service."""
+    Args:
+        sources: Only read these channels and external sources (e.g. the priority lane)
+        run_id: Poll run to retry after a crash; sources it finished are taken over
+            and the items it decided keep their outcome. A new run if None
+    """
     logger.info("Starting RSS Reader service...")
 
     try:
@@ -99,29 +284,106 @@ async def main():
             await db.connect()
             logger.info("Connected to database")
 
-        # Fetch all Telegram channels
+        # Fetch all Telegram channels and externally provided sources
         channels = await TelegramChannelRepository.get_all()
-
-        if not channels:
+        external_sources = load_external_sources()
+        recordings = Recordings.from_env()
+        if recordings and recordings.replaying:
+            logger.info(f"Replaying fetches recorded in {recordings.directory}")
+            channels += recorded_channels(recordings, channels)
+        elif recordings:
+            logger.info(f"Recording fetches to {recordings.directory}")
+        if sources is not None:
+            channels = [channel for channel in channels if channel.channel_name in sources]
+            external_sources = [source for source in external_sources if source.name in sources]
+
+        if not channels and not external_sources:
             logger.warning("No Telegram channels found in database")
             print("No Telegram channels found in database. Please add channels first.")
             return
 
+        # New or noisy sources can be rolled out / paused per channel via feature flags
+        disabled = [
+            channel.channel_name
+            for channel in channels
+            if not feature_flags.is_enabled(
+                "channel_polling", scope=channel.channel_name, default=True
+            )
+        ]
+        if disabled:
+            logger.info(f"Polling disabled by feature flags for: {', '.join(disabled)}")
+            channels = [channel for channel in channels if channel.channel_name not in disabled]
+
         logger.info(f"Found {len(channels)} Telegram channels to process")
         print(f"Processing {len(channels)} Telegram channels...\n")
 
-        # Create parser instance
-        parser = RSSParser()
-
-        # Process all channels in parallel
-        tasks = [process_channel(channel, parser) for channel in channels]
+        # Create parser instance and load filters (cheap expression rules run first)
+        bridges = BridgePool.from_env()
+        feed_urls = {
+            url: c.channel_name for c in channels for url in bridges.feed_urls(c.channel_name)
+        }
+        feed_urls.update({build_preview_url(c.channel_name): c.channel_name for c in channels})
+        meter = BandwidthMeter(feed_urls)
+        parser = RSSParser(
+            timeout=float(os.getenv("FEED_TIMEOUT_SECONDS", "10")),
+            recordings=recordings,
+            deadline=float(os.getenv("FEED_DEADLINE_SECONDS", "60")) or None,
+            user_agent=os.getenv("FEED_USER_AGENT") or None,
+            meter=meter,
+            conditional=os.getenv("FEED_CONDITIONAL_GET", "true").lower() == "true",
+            lenient=os.getenv("FEED_LENIENT", "false").lower() == "true",
+            options=ParseOptions(
+                strict=os.getenv("FEED_STRICT", "false").lower() == "true",
+                max_items=int(os.getenv("FEED_MAX_ITEMS", "0")),
+                max_field_length=int(os.getenv("FEED_MAX_FIELD_LENGTH", "0")),
+            ),
+        )
+        filters = [RuleFilter(load_rules()), load_filters()]
+        since_hours = float(os.getenv("FEED_SINCE_HOURS", "0"))
+        since = clock.now() - timedelta(hours=since_hours) if since_hours else None
+        # Preview pages aren't recorded, so replays only read the bridge's feeds
+        fallback = os.getenv("FEED_PREVIEW_FALLBACK", "true").lower() == "true"
+        fallback = fallback and not (recordings and recordings.replaying)
+
+        # Process all channels in parallel, noting what happens to each item
+        recorded = await PollRunRepository.get_items(run_id) if run_id else []
+        run_id = await PollRunRepository.start(run_id)
+        report = PollReport(run_id, recorded)
+        done = report.completed_sources
+        if recorded:
+            logger.info(f"Retrying poll run {run_id}: {len(done)} sources were done already")
+        polled: List[Source] = [
+            TelegramSource(channel, parser, since, fallback, bridges) for channel in channels
+        ]
+        polled += [
+            replace(source, recordings=recordings, meter=meter) for source in external_sources
+        ]
+        tasks = [
+            process_source(source, filters, report) for source in polled if source.name not in done
+        ]
         results = await asyncio.gather(*tasks, return_exceptions=True)
+        results += [
+            taken_over_result(source.name, report) for source in polled if source.name in done
+        ]
+        await save_report(run_id, report)
+        try:
+            await meter.save(clock.now().date())
+        except Exception as e:
+            logger.error(f"Failed to save bandwidth: {e}")
+        compress_days = int(os.getenv("FETCH_RECORDING_COMPRESS_DAYS", "7"))
+        if recordings and not recordings.replaying and compress_days:
+            try:
+                recordings.compress_older_than(compress_days)
+            except OSError as e:
+                logger.error(f"Failed to compress recordings: {e}")
 
         # Calculate totals and display summary
         total_saved = 0
         total_skipped = 0
         total_empty = 0
+        total_filtered = 0
         total_errors = 0
+        total_warnings = 0
 
         print("\n" + "=" * 80)
         print("SUMMARY BY CHANNEL")
@@ -133,19 +395,25 @@ async def main():
                 total_errors += 1
                 continue
 
-            channel_name, saved, skipped, empty, errors = result
+            channel_name, saved, skipped, empty, filtered, errors, warnings = result
             total_saved += saved
             total_skipped += skipped
             total_empty += empty
+            total_filtered += filtered
             total_errors += errors
+            total_warnings += warnings
 
             print(f"\n{channel_name}:")
             print(f"  ✓ Saved: {saved}")
             print(f"  ⊘ Skipped (already exists): {skipped}")
             if empty > 0:
                 print(f"  ⊘ Skipped (empty content): {empty}")
+            if filtered > 0:
+                print(f"  ⊘ Dropped by filters: {filtered}")
             if errors > 0:
                 print(f"  ✗ Errors: {errors}")
+            if warnings > 0:
+                print(f"  ⚠ Warnings: {warnings}")
 
         print("\n" + "=" * 80)
         print("OVERALL TOTALS")
@@ -154,14 +422,31 @@ async def main():
         print(f"⊘ Total Skipped (already exists): {total_skipped}")
         if total_empty > 0:
             print(f"⊘ Total Skipped (empty content): {total_empty}")
+        if total_filtered > 0:
+            print(f"⊘ Total Dropped by filters: {total_filtered}")
         if total_errors > 0:
             print(f"✗ Total Errors: {total_errors}")
+        if total_warnings > 0:
+            print(f"⚠ Total Warnings: {total_warnings}")
         print(f"📡 Channels Processed: {len(channels)}")
+        if external_sources:
+            print(f"🔌 External Sources Processed: {len(external_sources)}")
+        if len(bridges.base_urls) > 1 or any(h.failures for h in bridges.report()):
+            for health in bridges.report():
+                state = "up" if health.is_up(clock.now()) else f"down until {health.down_until}"
+                print(
+                    f"🌉 Bridge {health.base_url}: {state}, "
+                    f"{health.successes} ok / {health.failures} failed"
+                )
+        print(f"🔎 Item report: poll run {run_id}")
         print("=" * 80)
 
+        await alert_on_failures(results)
+
         logger.info("RSS Reader service completed successfully")
 
-        return {"saved_count": total_saved}
+        saved_links = [item.link for item in report.items if item.outcome == outcomes.NEW]
+        return {"saved_count": total_saved, "saved_links": saved_links}
 
     except Exception as e:
         logger.error(f"Error: {e}", exc_info=True)
@@ -169,5 +454,14 @@ async def main():
         raise
 
 
+def parse_run_id(argv: List[str]) -> Optional[str]:
+    """Run ID of `--run-id ID`, to retry a run that crashed."""
+    if not argv:
+        return None
+    if len(argv) != 2 or argv[0] != "--run-id" or not parse_id(argv[1]):
+        raise SystemExit("Usage: python -m src.rss_reader [--run-id POLL_RUN_ID]")
+    return parse_id(argv[1])
+
+
 if __name__ == "__main__":
-    asyncio.run(main())
+    asyncio.run(main(run_id=parse_run_id(sys.argv[1:])))
@@ -0,0 +1,85 @@
+"""Check that rss-bridge still serves Telegram channels in the expected format.
+
+Fetches a known channel through the bridge, checks the feed against the
+expectations of core/contract.py and appends the result to a JSON lines
+file. Expectations that held in the previous result but fail now are
+reported as format drift. Run nightly with:
+    uv run -m src.rss_reader.contract [--channel NAME] [--results FILE]
+"""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+from typing import Optional
+
+import requests
+
+from common import clock
+from common.utils.rss_bridge import DEFAULT_BRIDGE_URL, build_rss_bridge_url
+
+from .core.contract import EXPECTATIONS, ContractResult, check_bridge_feed
+from .core.fetcher import FeedFetcher
+
+DEFAULT_CHANNEL = "centralbank_russia"
+DEFAULT_BRIDGE = DEFAULT_BRIDGE_URL
+
+
+def last_result(path: Path, channel: str) -> Optional[ContractResult]:
+    """Latest result recorded for a channel, None if there is none."""
+    if not path.exists():
+        return None
+    previous = None
+    for line in path.read_text(encoding="utf-8").splitlines():
+        if line.strip():
+            result = ContractResult.from_dict(json.loads(line))
+            if result.channel == channel:
+                previous = result
+    return previous
+
+
+def save_result(path: Path, result: ContractResult) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    with path.open("a", encoding="utf-8") as file:
+        file.write(json.dumps(result.to_dict(), ensure_ascii=False) + "\n")
+
+
+def parse_args():
+    """Parse command line arguments."""
+    parser = argparse.ArgumentParser(description="Check the rss-bridge feed format")
+    parser.add_argument("--channel", default=DEFAULT_CHANNEL, help="Telegram channel to fetch")
+    parser.add_argument("--bridge", default=DEFAULT_BRIDGE, help="Base URL of the bridge")
+    parser.add_argument(
+        "--results",
+        type=Path,
+        default=Path("bridge-contract.jsonl"),
+        help="JSON lines file the results are appended to",
+    )
+    return parser.parse_args()
+
+
+def main() -> int:
+    args = parse_args()
+    url = build_rss_bridge_url(args.channel, base_url=args.bridge)
+    try:
+        content = FeedFetcher(timeout=30).fetch(url)
+    except requests.RequestException as e:
+        print(f"{url}: {e}", file=sys.stderr)
+        return 1
+
+    previous = last_result(args.results, args.channel)
+    result = check_bridge_feed(content, args.channel, clock.now())
+    save_result(args.results, result)
+
+    print(f"{args.channel}: {result.items} items")
+    for name in EXPECTATIONS:
+        failure = result.failures.get(name)
+        print(f"{'FAIL' if failure else 'ok'}\t{name}\t{failure or ''}".rstrip())
+    drift = result.new_failures(previous)
+    if drift and previous:
+        print(f"Newly failing since {previous.checked_at:%Y-%m-%d %H:%M}: {', '.join(drift)}")
+    return 0 if result.passed else 1
+
+
+if __name__ == "__main__":
+    sys.exit(main())
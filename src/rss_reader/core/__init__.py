@@ -2,5 +2,6 @@
 
 from .parser import RSSParser
 from .fetcher import FeedFetcher
+from .discovery import discover
 
-__all__ = ["RSSParser", "FeedFetcher"]
+__all__ = ["RSSParser", "FeedFetcher", "discover"]
@@ -0,0 +1,52 @@
+"""Bytes downloaded per source.
+
+Each run counts what every feed and external source returned and adds it to
+the source's total for the day, so a misconfigured feed that sends megabytes
+on every poll stands out in GET /poll-runs/bandwidth.
+"""
+
+import logging
+import threading
+from collections import defaultdict
+from datetime import date
+from typing import Dict, List, Optional
+
+from common.db.repository import BandwidthRepository
+
+logger = logging.getLogger(__name__)
+
+
+class BandwidthMeter:
+    """Bytes and fetches per source over one run; safe to use from fetcher threads."""
+
+    def __init__(self, sources_by_url: Optional[Dict[str, str]] = None):
+        """
+        Args:
+            sources_by_url: Source name of each feed URL; other URLs are counted under the URL
+        """
+        self.sources_by_url = dict(sources_by_url or {})
+        self.bytes: Dict[str, int] = defaultdict(int)
+        self.fetches: Dict[str, int] = defaultdict(int)
+        self._lock = threading.Lock()
+
+    def record(self, source: str, size: int) -> None:
+        """Count a response from a source."""
+        with self._lock:
+            self.bytes[source] += size
+            self.fetches[source] += 1
+
+    def record_url(self, url: str, size: int) -> None:
+        """Count a response from a feed URL."""
+        self.record(self.sources_by_url.get(url, url), size)
+
+    def sources(self) -> List[str]:
+        """Sources with responses, biggest first."""
+        return sorted(self.bytes, key=lambda source: (-self.bytes[source], source))
+
+    async def save(self, day: date) -> None:
+        """Add the counts to the daily totals of the sources."""
+        for source in self.sources():
+            await BandwidthRepository.add(source, day, self.bytes[source], self.fetches[source])
+        if self.bytes:
+            total = sum(self.bytes.values())
+            logger.info(f"Downloaded {total} bytes from {len(self.bytes)} sources")
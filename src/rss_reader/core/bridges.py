@@ -0,0 +1,141 @@
+"""A pool of rss-bridge instances with failover.
+
+Public instances rate-limit aggressively and go down now and then, so the
+reader can be given several (RSS_BRIDGE_URLS, self-hosted ones included).
+A channel's feed is fetched from its preferred instance; when that fails,
+from the next one. An instance that fails is left out for
+RSS_BRIDGE_COOLDOWN_SECONDS, twice as long after each further failure in a
+row, and is tried again afterwards; a success makes it healthy again.
+
+Health is kept for the lifetime of the process, so runs of the pipeline
+share it. With RSS_BRIDGE_ROTATE=true the channels are spread over the
+instances instead of all preferring the first one; a channel keeps its
+instance between runs, so conditional requests still work.
+"""
+
+import logging
+import os
+import zlib
+from dataclasses import asdict, dataclass
+from datetime import datetime, timedelta
+from typing import Dict, List, Optional
+
+from common import clock
+from common.utils.rss_bridge import DEFAULT_BRIDGE_URL, build_rss_bridge_url
+
+logger = logging.getLogger(__name__)
+
+# A failing instance is left out for at most this many cooldowns
+MAX_BACKOFF = 16
+
+
+@dataclass
+class BridgeHealth:
+    """How an instance has been doing."""
+
+    base_url: str
+    successes: int = 0
+    failures: int = 0
+    # Failures since the last success
+    failures_in_row: int = 0
+    last_error: Optional[str] = None
+    down_until: Optional[datetime] = None
+
+    def is_up(self, now: datetime) -> bool:
+        return self.down_until is None or self.down_until <= now
+
+    def to_dict(self) -> dict:
+        return asdict(self)
+
+
+# Base URL -> health, shared by the pools of the process
+_health: Dict[str, BridgeHealth] = {}
+
+
+def reset_health() -> None:
+    """Forget how the instances have been doing."""
+    _health.clear()
+
+
+class BridgePool:
+    """rss-bridge instances to fetch Telegram channels from, in order of preference."""
+
+    def __init__(
+        self,
+        base_urls: Optional[List[str]] = None,
+        cooldown: float = 300,
+        rotate: bool = False,
+    ):
+        """
+        Args:
+            base_urls: Base URLs of the instances (rss-bridge.org if None or empty)
+            cooldown: Seconds to leave out an instance after its first failure in a row
+            rotate: Spread the channels over the instances instead of preferring the first
+        """
+        self.base_urls = list(dict.fromkeys(base_urls or [])) or [DEFAULT_BRIDGE_URL]
+        self.cooldown = cooldown
+        self.rotate = rotate
+        # Feed URL -> base URL it was built from; matching prefixes would take
+        # http://bridge2/ feeds for http://bridge's
+        self._base_urls: Dict[str, str] = {}
+
+    @staticmethod
+    def from_env() -> "BridgePool":
+        """Pool configured by RSS_BRIDGE_URLS, RSS_BRIDGE_COOLDOWN_SECONDS and RSS_BRIDGE_ROTATE."""
+        urls = [url.strip() for url in os.getenv("RSS_BRIDGE_URLS", "").split(",")]
+        return BridgePool(
+            base_urls=[url for url in urls if url],
+            cooldown=float(os.getenv("RSS_BRIDGE_COOLDOWN_SECONDS", "300")),
+            rotate=os.getenv("RSS_BRIDGE_ROTATE", "false").lower() == "true",
+        )
+
+    def health(self, base_url: str) -> BridgeHealth:
+        return _health.setdefault(base_url, BridgeHealth(base_url))
+
+    def feed_urls(self, channel_name: str) -> List[str]:
+        """Feed URLs of a channel on every instance, in the order to try them."""
+        start = zlib.crc32(channel_name.encode()) % len(self.base_urls) if self.rotate else 0
+        ordered = self.base_urls[start:] + self.base_urls[:start]
+        now = clock.now()
+        up = [url for url in ordered if self.health(url).is_up(now)]
+        # When all are down, the one that comes back first is tried anyway
+        down = sorted(
+            (url for url in ordered if url not in up), key=lambda url: self.health(url).down_until
+        )
+        feed_urls = []
+        for base_url in up + down:
+            feed_url = build_rss_bridge_url(channel_name, base_url=base_url)
+            self._base_urls[feed_url] = base_url
+            feed_urls.append(feed_url)
+        return feed_urls
+
+    def base_url_of(self, feed_url: str) -> Optional[str]:
+        """Instance a feed URL of feed_urls() points to."""
+        return self._base_urls.get(feed_url)
+
+    def record_success(self, feed_url: str) -> None:
+        base_url = self.base_url_of(feed_url)
+        if not base_url:
+            return
+        health = self.health(base_url)
+        if health.failures_in_row:
+            logger.info(f"RSS bridge {base_url} is back")
+        health.successes += 1
+        health.failures_in_row = 0
+        health.down_until = None
+
+    def record_failure(self, feed_url: str, error: Exception) -> None:
+        base_url = self.base_url_of(feed_url)
+        if not base_url:
+            return
+        health = self.health(base_url)
+        health.failures += 1
+        health.failures_in_row += 1
+        health.last_error = str(error)
+        backoff = self.cooldown * min(2 ** (health.failures_in_row - 1), MAX_BACKOFF)
+        health.down_until = clock.now() + timedelta(seconds=backoff)
+        logger.warning(f"RSS bridge {base_url} failed, leaving it out for {backoff:g}s: {error}")
+
+    def report(self) -> List[BridgeHealth]:
+        """Health of the pool's instances, in the configured order."""
+        return [self.health(url) for url in self.base_urls]
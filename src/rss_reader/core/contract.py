@@ -0,0 +1,155 @@
+"""Structural expectations of the feeds rss-bridge serves for Telegram channels.
+
+Everything downstream relies on the shape of those feeds: post links name
+the channel and the message, descriptions carry Telegram's widget markup that
+clean_content knows how to strip, dates are RFC 822. The bridge can change any
+of it with an update, and the first sign would otherwise be broken posts.
+check_bridge_feed tells which expectations a fetched feed still meets; the
+nightly contract run (src/rss_reader/contract.py) compares them with its
+previous run to report what newly broke.
+"""
+
+import re
+from dataclasses import dataclass, field
+from datetime import datetime
+from typing import Dict, List, Optional
+from xml.etree import ElementTree as ET
+
+from common.drift import has_leftover_html
+from common.utils.dates import UnparseableDate, parse_feed_date
+
+from .parser import RSSParser
+
+# Class of the element holding the message text in Telegram's web widget
+MESSAGE_TEXT_CLASS = "tgme_widget_message_text"
+
+EXPECTATIONS = (
+    "rss_root",
+    "channel_title",
+    "channel_link",
+    "items",
+    "item_links",
+    "guids",
+    "pub_dates",
+    "descriptions",
+    "message_markup",
+    "cleaned_text",
+)
+
+
+@dataclass
+class ContractResult:
+    """Outcome of checking one feed."""
+
+    channel: str
+    checked_at: datetime
+    items: int = 0
+    # Expectation name to a description of what broke it
+    failures: Dict[str, str] = field(default_factory=dict)
+
+    @property
+    def passed(self) -> bool:
+        return not self.failures
+
+    def new_failures(self, previous: Optional["ContractResult"]) -> List[str]:
+        """Expectations that fail now but held in the previous result (all failing if none)."""
+        before = previous.failures if previous else {}
+        return [name for name in self.failures if name not in before]
+
+    def to_dict(self) -> dict:
+        return {
+            "channel": self.channel,
+            "checked_at": self.checked_at.isoformat(),
+            "items": self.items,
+            "failures": self.failures,
+        }
+
+    @staticmethod
+    def from_dict(data: dict) -> "ContractResult":
+        return ContractResult(
+            channel=data["channel"],
+            checked_at=datetime.fromisoformat(data["checked_at"]),
+            items=data.get("items", 0),
+            failures=dict(data.get("failures") or {}),
+        )
+
+
+def check_bridge_feed(content: str, channel: str, checked_at: datetime) -> ContractResult:
+    """
+    Check a TelegramBridge feed of a channel against the expectations.
+
+    Args:
+        content: Feed as fetched from the bridge
+        channel: Telegram channel name the feed was requested for
+        checked_at: Time of the fetch
+
+    Returns:
+        ContractResult listing the expectations the feed breaks
+    """
+    result = ContractResult(channel=channel, checked_at=checked_at)
+    failures = result.failures
+    try:
+        root = ET.fromstring(content)
+    except ET.ParseError as e:
+        failures["rss_root"] = f"Not XML: {e}"
+        return result
+    if root.tag != "rss" or root.get("version") != "2.0":
+        failures["rss_root"] = f"Root element is {root.tag} version {root.get('version')}"
+        return result
+    element = root.find("channel")
+    if element is None:
+        failures["rss_root"] = "No channel element"
+        return result
+
+    title = element.findtext("title") or ""
+    if f"@{channel}" not in title:
+        failures["channel_title"] = f"Title {title!r} doesn't name @{channel}"
+    link = (element.findtext("link") or "").strip()
+    if link != f"https://t.me/s/{channel}":
+        failures["channel_link"] = f"Channel link is {link!r}"
+
+    raw_items = element.findall("item")
+    result.items = len(raw_items)
+    if not raw_items:
+        failures["items"] = "The feed has no items"
+        return result
+
+    item_link = re.compile(rf"^https://t\.me/{re.escape(channel)}/\d+$", re.IGNORECASE)
+    checks = {
+        "item_links": lambda item: item_link.match((item.findtext("link") or "").strip()),
+        "guids": lambda item: (item.findtext("guid") or "").strip()
+        == (item.findtext("link") or "").strip(),
+        "pub_dates": lambda item: _parses(item.findtext("pubDate")),
+        "descriptions": lambda item: (item.findtext("description") or "").strip(),
+    }
+    for name, check in checks.items():
+        broken = [item for item in raw_items if not check(item)]
+        if broken:
+            failures[name] = f"{len(broken)} of {len(raw_items)} items, e.g. {_describe(broken[0])}"
+
+    if not any(MESSAGE_TEXT_CLASS in (item.findtext("description") or "") for item in raw_items):
+        failures["message_markup"] = f"No description contains {MESSAGE_TEXT_CLASS}"
+
+    # What the reader would store
+    parsed = RSSParser(strict_dates=False).parse_content(content)
+    leftover = [item for item in parsed.items if has_leftover_html(item.description)]
+    if leftover:
+        failures["cleaned_text"] = (
+            f"{len(leftover)} of {len(parsed.items)} items keep HTML after cleaning, "
+            f"e.g. {leftover[0].link}"
+        )
+    return result
+
+
+def _parses(value: Optional[str]) -> bool:
+    if not value:
+        return False
+    try:
+        parse_feed_date(value)
+    except UnparseableDate:
+        return False
+    return True
+
+
+def _describe(item: ET.Element) -> str:
+    return (item.findtext("link") or item.findtext("title") or "an item without a link").strip()
@@ -0,0 +1,155 @@
+"""What moderators correct in the extracted fields.
+
+Every correction (common.db.models.FieldOverride) says that an extractor got
+a field of a post wrong. Counted by field and channel, they show which
+extractors and which channels' wording need work first. Corrections of a
+field to the same value whose posts share words that are rare elsewhere
+suggest a keyword the extractor is missing, e.g. "трансл" (трансляция) for
+posts corrected to the online format; each suggestion comes with a rule
+expression (see common.rules) matching the posts.
+"""
+
+import json
+import re
+from collections import Counter
+from dataclasses import dataclass, field
+from typing import Any, Dict, Iterable, List, Optional, Tuple
+
+from common.db.models import FieldOverride
+from common.utils.links import channel_from_link
+
+# Words that are long enough to say something about a post
+WORD_REGEX = re.compile(r"[^\W\d_]{4,}")
+# Letters a word is cut to, so inflected forms count as one ("трансляция", "трансляцию")
+STEM_LENGTH = 6
+
+
+@dataclass
+class CorrectionStats:
+    """Corrections of one field in the posts of one channel."""
+
+    field: str
+    channel: str
+    count: int = 0
+    # (link, extracted value, corrected value) of the latest corrections
+    examples: List[Tuple[str, Any, Any]] = field(default_factory=list)
+
+    def to_dict(self) -> dict:
+        return {
+            "field": self.field,
+            "channel": self.channel,
+            "count": self.count,
+            "examples": [
+                {"link": link, "extracted": extracted, "corrected": corrected}
+                for link, extracted, corrected in self.examples
+            ],
+        }
+
+
+@dataclass
+class KeywordSuggestion:
+    """Words shared by the posts whose field was corrected to the same value."""
+
+    field: str
+    value: Any
+    keywords: List[str]
+    # Corrected posts with at least one of the keywords
+    posts: int
+
+    @property
+    def when(self) -> str:
+        """Rule expression matching posts with any of the keywords."""
+        return f'post.content.matches("(?i)({"|".join(self.keywords)})")'
+
+    def to_dict(self) -> dict:
+        return {
+            "field": self.field,
+            "value": self.value,
+            "keywords": self.keywords,
+            "posts": self.posts,
+            "when": self.when,
+        }
+
+
+def _json(value: Optional[str]) -> Any:
+    return json.loads(value) if value else None
+
+
+def summarize_corrections(
+    overrides: Iterable[FieldOverride], examples: int = 3
+) -> List[CorrectionStats]:
+    """
+    Count corrections by field and channel.
+
+    Args:
+        overrides: Corrections, oldest first
+        examples: Latest corrections to keep as examples of each group
+
+    Returns:
+        Groups with the most corrections first
+    """
+    groups: Dict[Tuple[str, str], CorrectionStats] = {}
+    for override in overrides:
+        channel = channel_from_link(override.link)
+        stats = groups.setdefault(
+            (override.field, channel), CorrectionStats(override.field, channel)
+        )
+        stats.count += 1
+        example = (override.link, _json(override.extracted), _json(override.value))
+        stats.examples = [example, *stats.examples][:examples]
+    return sorted(groups.values(), key=lambda s: (-s.count, s.field, s.channel))
+
+
+def _words(text: str) -> set:
+    return {word.lower()[:STEM_LENGTH] for word in WORD_REGEX.findall(text or "")}
+
+
+def suggest_keywords(
+    overrides: Iterable[FieldOverride],
+    texts: Dict[str, str],
+    background: Iterable[str],
+    min_posts: int = 2,
+    max_keywords: int = 5,
+) -> List[KeywordSuggestion]:
+    """
+    Suggest keywords for the values moderators keep correcting fields to.
+
+    A word is suggested for a value when at least half of the posts corrected
+    to it contain it, and at most one in ten of the other posts. Keywords are
+    the first letters of the words (see STEM_LENGTH), matched as prefixes.
+
+    Args:
+        overrides: Corrections
+        texts: Text of the corrected posts, by link
+        background: Texts of other posts, to tell words specific to the
+            corrected posts from common ones
+        min_posts: Corrections of a field to a value needed for a suggestion
+        max_keywords: Keywords to suggest per value at most
+
+    Returns:
+        Suggestions, the ones backed by the most posts first
+    """
+    # (field, value as JSON) -> words of each corrected post
+    corrected: Dict[Tuple[str, str], List[set]] = {}
+    for override in overrides:
+        if override.link in texts and _json(override.value) not in (None, False, []):
+            key = (override.field, override.value)
+            corrected.setdefault(key, []).append(_words(texts[override.link]))
+
+    other = [_words(text) for text in background]
+    other_counts = Counter(word for words in other for word in words)
+    suggestions = []
+    for (name, value), posts in corrected.items():
+        if len(posts) < min_posts:
+            continue
+        counts = Counter(word for words in posts for word in words)
+        shares = {
+            word: count / len(posts) - other_counts[word] / max(len(other), 1)
+            for word, count in counts.items()
+            if count * 2 >= len(posts) and other_counts[word] * 10 <= len(other)
+        }
+        keywords = sorted(shares, key=lambda word: (-shares[word], word))[:max_keywords]
+        if keywords:
+            matched = sum(1 for words in posts if words & set(keywords))
+            suggestions.append(KeywordSuggestion(name, json.loads(value), keywords, matched))
+    return sorted(suggestions, key=lambda s: (-s.posts, s.field, str(s.value)))
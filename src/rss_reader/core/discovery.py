@@ -0,0 +1,109 @@
+"""Feed autodiscovery.
+
+Organizers usually share a website, not its feed. Sites advertise their
+feeds in the page head:
+
+    <link rel="alternate" type="application/rss+xml" title="News" href="/feed">
+
+discover() fetches a page and returns the RSS, Atom and JSON Feed URLs it
+links to, ready to be added to the sources configuration.
+"""
+
+import logging
+from dataclasses import dataclass
+from html.parser import HTMLParser
+from typing import List, Optional
+from urllib.parse import urljoin, urlsplit
+
+from .fetcher import FEED_START, FeedFetcher
+
+logger = logging.getLogger(__name__)
+
+# Link types of the feed formats the parser reads
+FEED_TYPES = {
+    "application/rss+xml": "rss",
+    "application/atom+xml": "atom",
+    "application/feed+json": "json",
+}
+
+
+@dataclass
+class FeedLink:
+    """A feed a page links to."""
+
+    url: str
+    # rss, atom or json
+    format: str
+    title: Optional[str] = None
+
+
+class _FeedLinkParser(HTMLParser):
+    """Collects the feed <link> tags and the <base> of a page."""
+
+    def __init__(self):
+        super().__init__(convert_charrefs=True)
+        self.base: Optional[str] = None
+        self.links: List[FeedLink] = []
+
+    def handle_starttag(self, tag, attrs):
+        attributes = {name: value or "" for name, value in attrs}
+        if tag == "base" and self.base is None and attributes.get("href"):
+            self.base = attributes["href"]
+            return
+        if tag != "link" or "alternate" not in attributes.get("rel", "").lower().split():
+            return
+        # Parameters like "; charset=utf-8" don't change the format
+        link_type = attributes.get("type", "").split(";")[0].strip().lower()
+        href = attributes.get("href", "").strip()
+        if link_type in FEED_TYPES and href:
+            title = " ".join(attributes.get("title", "").split()) or None
+            self.links.append(FeedLink(url=href, format=FEED_TYPES[link_type], title=title))
+
+
+def find_feed_links(page: str, url: str) -> List[FeedLink]:
+    """
+    Feeds an HTML page links to.
+
+    Args:
+        page: The page's HTML
+        url: URL the page was fetched from, to resolve relative links against
+
+    Returns:
+        Feed links with absolute URLs, in the page's order and without duplicates
+    """
+    parser = _FeedLinkParser()
+    parser.feed(page)
+    parser.close()
+    base = urljoin(url, parser.base) if parser.base else url
+
+    found = {}
+    for link in parser.links:
+        link.url = urljoin(base, link.url)
+        if urlsplit(link.url).scheme in ("http", "https"):
+            found.setdefault(link.url, link)
+    return list(found.values())
+
+
+def discover(url: str, fetcher: Optional[FeedFetcher] = None) -> List[FeedLink]:
+    """
+    Find the feeds of a website.
+
+    Args:
+        url: Page to look at, usually the site's home page
+        fetcher: Fetcher to get the page with (a default one if None)
+
+    Returns:
+        Feeds the page links to; the URL itself if it's a feed already
+
+    Raises:
+        requests.RequestException: If the page can't be fetched
+    """
+    page = (fetcher or FeedFetcher()).fetch_page(url)
+    if FEED_START.match(page):
+        return [FeedLink(url=url, format="atom" if "<feed" in page[:1000] else "rss")]
+    if page.lstrip().startswith("{") and "jsonfeed.org" in page[:1000]:
+        return [FeedLink(url=url, format="json")]
+
+    links = find_feed_links(page, url)
+    logger.info(f"Found {len(links)} feeds on {url}")
+    return links
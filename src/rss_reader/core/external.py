@@ -0,0 +1,211 @@
+"""External sources implemented as subprocesses.
+
+Third parties can add sources without forking the repository by providing an
+executable that writes posts to stdout as JSON Lines, one object per post:
+
+    {"link": "https://example.com/post/1", "content": "<p>Text</p>",
+     "pub_date": "2026-01-10T10:00:00Z", "media_urls": ["https://..."]}
+
+`link` and `content` are required; `content` may contain HTML and is cleaned
+like RSS descriptions. An optional `categories` list of strings is kept like
+the categories of feed items, and an optional `guid` identifies the post when
+its link changes. Anything written to stderr is logged. The process must
+exit with status 0, otherwise the run is treated as a failure.
+
+Sources are configured in a JSON file referenced by EXTERNAL_SOURCES_FILE:
+
+    [{"name": "vk_city", "command": ["/opt/sources/vk", "--group", "city"], "timeout": 60}]
+"""
+
+import asyncio
+import json
+import logging
+import os
+from dataclasses import dataclass, field
+from typing import Dict, List, Optional
+
+from common.models.feed import RSSItem
+from common.utils.html import clean_content, extract_media_urls
+from .bandwidth import BandwidthMeter
+from .recordings import ProcessRecording, Recordings
+from .source import Source
+
+logger = logging.getLogger(__name__)
+
+
+class ExternalSourceError(Exception):
+    """Raised when an external source process fails."""
+
+
+@dataclass
+class ExternalSource(Source):
+    """A source backed by an external executable."""
+
+    name: str
+    command: List[str]
+    timeout: int = 60
+    env: Dict[str, str] = field(default_factory=dict)
+    # Where fetch saves the output to or replays it from, and counts its bytes
+    recordings: Optional[Recordings] = field(default=None, repr=False, compare=False)
+    meter: Optional[BandwidthMeter] = field(default=None, repr=False, compare=False)
+
+    @property
+    def location(self) -> str:
+        return f"external:{self.name}"
+
+    @staticmethod
+    def from_dict(data: dict) -> "ExternalSource":
+        """Create ExternalSource from a configuration entry."""
+        if not data.get("name"):
+            raise ValueError("External source requires a name")
+        command = data.get("command")
+        if isinstance(command, str):
+            command = [command]
+        if not command:
+            raise ValueError(f"External source '{data['name']}' requires a command")
+        return ExternalSource(
+            name=data["name"],
+            command=list(command),
+            timeout=int(data.get("timeout", 60)),
+            env={k: str(v) for k, v in (data.get("env") or {}).items()},
+        )
+
+    async def fetch(
+        self, recordings: Optional[Recordings] = None, meter: Optional[BandwidthMeter] = None
+    ) -> List[RSSItem]:
+        """
+        Run the source process and parse its output.
+
+        Args:
+            recordings: Recordings to save the output to or replay it from instead of
+                the source's own
+            meter: Meter to count the output's bytes with instead of the source's own
+
+        Returns:
+            List of RSSItem parsed from stdout
+
+        Raises:
+            ExternalSourceError: If the process fails, times out, or emits invalid lines
+        """
+        recordings = recordings or self.recordings
+        meter = meter or self.meter
+        if recordings and recordings.replaying:
+            logger.info(f"Replaying external source {self.name}")
+            output = recordings.load_process(self.name)
+        else:
+            output = await self._run()
+            if meter:
+                meter.record(self.name, len(output.stdout.encode()))
+            if recordings:
+                recordings.save_process(output)
+
+        for line in output.stderr.splitlines():
+            logger.info(f"[{self.name}] {line}")
+
+        if output.returncode != 0:
+            raise ExternalSourceError(f"Exited with status {output.returncode}")
+
+        return parse_output(output.stdout)
+
+    async def _run(self) -> ProcessRecording:
+        """Run the source process and collect its output."""
+        logger.info(f"Running external source {self.name}: {' '.join(self.command)}")
+        try:
+            process = await asyncio.create_subprocess_exec(
+                *self.command,
+                stdout=asyncio.subprocess.PIPE,
+                stderr=asyncio.subprocess.PIPE,
+                env={**os.environ, **self.env},
+            )
+        except OSError as e:
+            raise ExternalSourceError(f"Failed to start {self.command[0]}: {e}")
+
+        try:
+            stdout, stderr = await asyncio.wait_for(process.communicate(), self.timeout)
+        except asyncio.TimeoutError:
+            process.kill()
+            await process.wait()
+            raise ExternalSourceError(f"Timed out after {self.timeout}s")
+
+        try:
+            text = stdout.decode("utf-8")
+        except UnicodeDecodeError as e:
+            raise ExternalSourceError(
+                f"{self.command[0]} printed invalid UTF-8 at byte {e.start} of its output"
+            )
+        return ProcessRecording(
+            name=self.name,
+            returncode=process.returncode,
+            stdout=text,
+            stderr=stderr.decode("utf-8", errors="replace"),
+        )
+
+
+def parse_item(data: dict) -> RSSItem:
+    """Convert a post object (see the module docstring) to a feed item."""
+    content = data.get("content") or data.get("description") or ""
+    media_urls = list(data.get("media_urls") or [])
+    for url in extract_media_urls(content):
+        if url not in media_urls:
+            media_urls.append(url)
+
+    return RSSItem(
+        link=data["link"],
+        description=clean_content(content),
+        pub_date=data.get("pub_date"),
+        media_urls=media_urls,
+        categories=[name for name in data.get("categories") or [] if isinstance(name, str)],
+        guid=str(data["guid"]) if data.get("guid") else None,
+    )
+
+
+def parse_output(output: str) -> List[RSSItem]:
+    """
+    Parse JSON Lines emitted by an external source.
+
+    Args:
+        output: Process stdout
+
+    Returns:
+        List of RSSItem
+
+    Raises:
+        ExternalSourceError: If a line is not a valid post object
+    """
+    items = []
+    for line_number, line in enumerate(output.splitlines(), 1):
+        if not line.strip():
+            continue
+        try:
+            data = json.loads(line)
+        except ValueError as e:
+            raise ExternalSourceError(f"Line {line_number}: invalid JSON: {e}")
+
+        if not isinstance(data, dict) or not data.get("link"):
+            raise ExternalSourceError(f"Line {line_number}: post must be an object with a link")
+
+        items.append(parse_item(data))
+    return items
+
+
+def load_external_sources(path: Optional[str] = None) -> List[ExternalSource]:
+    """
+    Load external source definitions.
+
+    Args:
+        path: JSON config file (default: EXTERNAL_SOURCES_FILE env var)
+
+    Returns:
+        List of configured sources (empty if not configured)
+    """
+    path = path or os.getenv("EXTERNAL_SOURCES_FILE", "")
+    if not path:
+        return []
+
+    with open(path, "r", encoding="utf-8") as f:
+        entries = json.load(f)
+
+    if not isinstance(entries, list):
+        raise ValueError(f"{path} must contain a JSON list of sources")
+
+    return [ExternalSource.from_dict(entry) for entry in entries]
@@ -0,0 +1,203 @@
+"""Fields extracted from the text of posts, and diffs of them between code versions.
+
+Prices, admission, format, status, dates, deadlines, contacts and the people
+and organizations named are worked out from a post's text when it is stored.
+A change to one of the extractors changes them for every post stored after
+the deploy, so before deploying one, the extraction of the old code is saved
+as a baseline over the stored posts and compared with that of the new code
+(src/rss_reader/extract_diff.py).
+
+Moderators can correct a field a post got wrong (common.db.models.FieldOverride);
+the correction is kept in the JSON form of a baseline and wins over what is
+extracted when the post is stored again.
+"""
+
+import json
+from dataclasses import dataclass, field
+from datetime import date, datetime
+from decimal import Decimal, InvalidOperation
+from pathlib import Path
+from typing import Any, Dict, List, Optional, Tuple
+
+from common.admission import detect_admission
+from common.contacts import extract_contacts
+from common.entities import extract_entities
+from common.event_dates import parse_deadline, parse_schedule
+from common.event_format import classify_format
+from common.event_status import detect_status
+from common.prices import price_range
+
+BASELINE_FILE = "extraction.jsonl"
+
+# Type of each extracted field, which its JSON form is read back to
+FIELD_TYPES: Dict[str, type] = {
+    "price_min": Decimal,
+    "price_max": Decimal,
+    "price_currency": str,
+    "registration_required": bool,
+    "tickets_required": bool,
+    "limited_capacity": bool,
+    "event_format": str,
+    "event_status": str,
+    "event_start": datetime,
+    "event_end": datetime,
+    "event_sessions": str,
+    "performers": list,
+    "organizations": list,
+    "contacts": list,
+    "deadline": date,
+    "deadline_kind": str,
+}
+
+
+def extract_fields(text: str, pub_date: Optional[datetime] = None) -> Dict[str, Any]:
+    """
+    Work out the RSSPost fields that depend on a post's text.
+
+    Args:
+        text: Cleaned post text
+        pub_date: Publication date, which relative event dates count from
+
+    Returns:
+        RSSPost field names and values
+    """
+    price = price_range(text)
+    admission = detect_admission(text)
+    schedule = parse_schedule(text, pub_date)
+    deadline = parse_deadline(text, pub_date)
+    entities = extract_entities(text)
+    return {
+        "price_min": price.min if price else None,
+        "price_max": price.max if price else None,
+        "price_currency": price.currency if price else None,
+        "registration_required": admission.registration_required,
+        "tickets_required": admission.tickets_required,
+        "limited_capacity": admission.limited_capacity,
+        "event_format": classify_format(text),
+        "event_status": detect_status(text),
+        "event_start": schedule.start if schedule else None,
+        "event_end": schedule.end if schedule else None,
+        "event_sessions": schedule.sessions_json() if schedule else None,
+        "performers": entities.performers or None,
+        "organizations": entities.organizations or None,
+        "contacts": extract_contacts(text) or None,
+        "deadline": deadline.day if deadline else None,
+        "deadline_kind": deadline.kind if deadline else None,
+    }
+
+
+def comparable(fields: Dict[str, Any]) -> Dict[str, Any]:
+    """Extracted fields as JSON values, as they are saved in a baseline."""
+    values = {}
+    for name, value in fields.items():
+        if isinstance(value, Decimal):
+            value = str(value)
+        elif isinstance(value, (date, datetime)):
+            value = value.isoformat()
+        values[name] = value
+    return values
+
+
+def from_comparable(name: str, value: Any) -> Any:
+    """
+    Read the JSON form of an extracted field back, as comparable() wrote it.
+
+    Raises:
+        ValueError: If the field isn't extracted or the value doesn't fit it
+    """
+    kind = FIELD_TYPES.get(name)
+    if kind is None:
+        raise ValueError(f"'{name}' is not an extracted field")
+    if value is None:
+        return None
+    if kind is Decimal and isinstance(value, (int, float, str)) and not isinstance(value, bool):
+        try:
+            return Decimal(str(value))
+        except InvalidOperation:
+            pass
+    elif kind in (datetime, date) and isinstance(value, str):
+        try:
+            return kind.fromisoformat(value)
+        except ValueError:
+            pass
+    elif kind is list and isinstance(value, list) and all(isinstance(v, str) for v in value):
+        return value or None
+    elif kind in (bool, str) and isinstance(value, kind):
+        return value
+    raise ValueError(f"'{name}' must be a {kind.__name__}, not {value!r}")
+
+
+def save_baseline(directory: Path, extractions: Dict[str, Dict[str, Any]]) -> Path:
+    """
+    Save the extraction of each post, by link.
+
+    Returns:
+        Path of the written file
+    """
+    directory.mkdir(parents=True, exist_ok=True)
+    path = directory / BASELINE_FILE
+    with path.open("w", encoding="utf-8") as file:
+        for link, fields in extractions.items():
+            record = {"link": link, "fields": comparable(fields)}
+            file.write(json.dumps(record, ensure_ascii=False) + "\n")
+    return path
+
+
+def load_baseline(directory: Path) -> Dict[str, Dict[str, Any]]:
+    """
+    Extractions saved with save_baseline, by link.
+
+    Raises:
+        FileNotFoundError: If the directory holds no baseline
+    """
+    baseline = {}
+    with (directory / BASELINE_FILE).open(encoding="utf-8") as file:
+        for line in file:
+            if line.strip():
+                record = json.loads(line)
+                baseline[record["link"]] = record["fields"]
+    return baseline
+
+
+@dataclass
+class ExtractionDiff:
+    """Changes in extracted fields between a baseline and the current code."""
+
+    compared: int = 0
+    # Baseline links without a stored post anymore
+    missing: List[str] = field(default_factory=list)
+    # Field name to (link, baseline value, current value) of each changed post
+    changes: Dict[str, List[Tuple[str, Any, Any]]] = field(default_factory=dict)
+
+    @property
+    def changed_posts(self) -> int:
+        return len({link for changes in self.changes.values() for link, _, _ in changes})
+
+
+def diff_extractions(
+    baseline: Dict[str, Dict[str, Any]], current: Dict[str, Optional[Dict[str, Any]]]
+) -> ExtractionDiff:
+    """
+    Compare the baseline with the current extraction of the same posts.
+
+    Args:
+        baseline: Saved extractions by link
+        current: Current extractions by link, None for posts no longer stored
+
+    Returns:
+        ExtractionDiff; fields only one side knows compare against None
+    """
+    diff = ExtractionDiff()
+    for link, before in baseline.items():
+        fields = current.get(link)
+        if fields is None:
+            diff.missing.append(link)
+            continue
+        diff.compared += 1
+        after = comparable(fields)
+        for name in sorted(set(before) | set(after)):
+            if before.get(name) != after.get(name):
+                diff.changes.setdefault(name, []).append(
+                    (link, before.get(name), after.get(name))
+                )
+    return diff
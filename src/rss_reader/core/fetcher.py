@@ -1,21 +1,270 @@
 import requests
+import codecs
 import logging
+import os
+import re
+import threading
+import time
+import zlib
+from html import unescape
+from typing import Dict, Mapping, Optional
+
+from requests.adapters import HTTPAdapter
+from requests.exceptions import ContentDecodingError
+
+from .bandwidth import BandwidthMeter
+from .recordings import HTTPRecording, Recordings
 
 logger = logging.getLogger(__name__)
 
+USER_AGENT = "RSS-Parser/1.0"
+# Only what decompress_body can undo when a transport doesn't
+ACCEPT_ENCODING = "gzip, deflate"
+GZIP_MAGIC = b"\x1f\x8b"
+# Compressed feeds are a few hundred kilobytes; anything inflating past this is broken or hostile
+MAX_DECOMPRESSED_BYTES = 64 * 1024 * 1024
+# A proxy in front of rss-bridge may compress a body that is compressed already
+MAX_ENCODING_LAYERS = 3
+
+_session: Optional[requests.Session] = None
+_session_lock = threading.Lock()
+
+# URL -> ETag / Last-Modified of the last feed fetched from it, for conditional requests
+_validators: Dict[str, Dict[str, str]] = {}
+_validators_lock = threading.Lock()
+
+
+def shared_session() -> requests.Session:
+    """
+    Session shared by all feed fetchers.
+
+    Most feeds come from one rss-bridge host and are fetched in parallel, so
+    keeping FEED_POOL_SIZE connections to each host alive saves a TCP and TLS
+    handshake per channel and poll. Past the pool size, connections are still
+    opened but not kept.
+    """
+    global _session
+    with _session_lock:
+        if _session is None:
+            pool_size = int(os.getenv("FEED_POOL_SIZE", "32"))
+            session = requests.Session()
+            session.headers.update({"User-Agent": USER_AGENT, "Accept-Encoding": ACCEPT_ENCODING})
+            adapter = HTTPAdapter(pool_connections=pool_size, pool_maxsize=pool_size)
+            session.mount("http://", adapter)
+            session.mount("https://", adapter)
+            _session = session
+        return _session
+
+
+HTML_START = re.compile(
+    r"\s*(<!--.*?-->\s*)*<(!doctype\s+html|html[\s>])", re.IGNORECASE | re.DOTALL
+)
+FEED_START = re.compile(r"[\s\ufeff]*(<\?xml|<rss|<feed|<rdf)", re.IGNORECASE)
+HTML_TITLE = re.compile(r"<title[^>]*>(.*?)</title>", re.IGNORECASE | re.DOTALL)
+CHARSET_PARAM = re.compile(r";\s*charset\s*=\s*[\"']?([\w.:-]+)", re.IGNORECASE)
+XML_ENCODING = re.compile(rb"^<\?xml[^>]*?\sencoding\s*=\s*[\"']([\w.:-]+)[\"']")
+BOMS = (
+    (codecs.BOM_UTF8, "utf-8-sig"),
+    (codecs.BOM_UTF32_LE, "utf-32"),
+    (codecs.BOM_UTF32_BE, "utf-32"),
+    (codecs.BOM_UTF16_LE, "utf-16"),
+    (codecs.BOM_UTF16_BE, "utf-16"),
+)
+
+
+class FetchDeadlineExceeded(requests.Timeout):
+    """Raised when a feed takes longer than its deadline to download."""
+
+
+class FeedNotModified(Exception):
+    """Raised by conditional fetches when a feed hasn't changed since it was last fetched."""
+
+    def __init__(self, url: str):
+        super().__init__(f"Feed not modified since the last fetch: {url}")
+        self.url = url
+
+
+class NotAFeedError(ValueError):
+    """Raised when a feed URL answers with something else, like an HTML error page."""
+
+
+def check_feed_response(url: str, headers: Mapping[str, str], body: str) -> None:
+    """
+    Make sure a successful response is a feed.
+
+    rss-bridge answers with an HTML error page and status 200 when a bridge
+    fails, which would otherwise surface as a confusing XML parsing error.
+
+    Raises:
+        NotAFeedError: If the response is an HTML page
+    """
+    content_type = {k.lower(): v for k, v in headers.items()}.get("content-type", "")
+    if FEED_START.match(body):
+        return
+    if not HTML_START.match(body) and "text/html" not in content_type.lower():
+        return
+
+    title = HTML_TITLE.search(body)
+    summary = " ".join(unescape(title.group(1)).split()) if title else ""
+    raise NotAFeedError(
+        f"Expected a feed from {url}, got an HTML page ({content_type or 'no content type'})"
+        + (f": {summary[:200]}" if summary else "")
+    )
+
+
+def _codec(name: Optional[str]) -> Optional[str]:
+    try:
+        return codecs.lookup(name).name if name else None
+    except LookupError:
+        logger.warning(f"Unknown feed charset: {name}")
+        return None
+
+
+def decode_feed(body: bytes, headers: Optional[Mapping[str, str]] = None) -> str:
+    """
+    Decode a feed document.
+
+    Cyrillic feeds still come in windows-1251 and koi8-r, often served as
+    text/xml without a charset, which requests would decode as ISO-8859-1.
+    The encoding is taken from, in order: a byte order mark, the charset of
+    the Content-Type header, the XML declaration; UTF-8 otherwise.
+
+    Args:
+        body: Raw response body
+        headers: Response headers
+
+    Returns:
+        Decoded document; undecodable bytes are replaced
+    """
+    for bom, encoding in BOMS:
+        if body.startswith(bom):
+            return body.decode(encoding, errors="replace")
+
+    content_type = {k.lower(): v for k, v in (headers or {}).items()}.get("content-type", "")
+    charset = CHARSET_PARAM.search(content_type)
+    declaration = XML_ENCODING.match(body.lstrip())
+    encoding = (
+        _codec(charset.group(1) if charset else None)
+        or _codec(declaration.group(1).decode("ascii") if declaration else None)
+        or "utf-8"
+    )
+    return body.decode(encoding, errors="replace")
+
+
+def decompress_body(body: bytes, headers: Optional[Mapping[str, str]] = None) -> bytes:
+    """
+    Undo a gzip or deflate content encoding left in a response body.
+
+    requests decompresses bodies itself, but sessions with custom transports
+    (some self-hosted rss-bridge setups) hand them over as they arrived,
+    sometimes without a Content-Encoding header. Gzip is recognised by its
+    magic bytes, deflate (zlib-wrapped or raw) by the header; bodies that are
+    neither are returned unchanged.
+
+    Raises:
+        ContentDecodingError: If a compressed body is corrupt or inflates past
+            MAX_DECOMPRESSED_BYTES
+    """
+    encoding = {k.lower(): v for k, v in (headers or {}).items()}.get("content-encoding", "")
+    deflated = "deflate" in encoding.lower()
+    for _ in range(MAX_ENCODING_LAYERS):
+        if body.startswith(GZIP_MAGIC):
+            wbits = 16 + zlib.MAX_WBITS
+        elif deflated and body and body.lstrip()[:1] != b"<":
+            # A zlib header is a multiple of 31 with compression method 8
+            zlib_wrapped = (body[0] & 0x0F) == 8 and int.from_bytes(body[:2]) % 31 == 0
+            wbits = zlib.MAX_WBITS if zlib_wrapped else -zlib.MAX_WBITS
+        else:
+            return body
+        body = _inflate(body, wbits)
+    return body
+
+
+def _inflate(body: bytes, wbits: int) -> bytes:
+    decompressor = zlib.decompressobj(wbits)
+    try:
+        inflated = decompressor.decompress(body, MAX_DECOMPRESSED_BYTES)
+    except zlib.error as e:
+        raise ContentDecodingError(f"Corrupt compressed feed body: {e}") from e
+    if decompressor.unconsumed_tail:
+        raise ContentDecodingError(
+            f"Compressed feed body inflates past {MAX_DECOMPRESSED_BYTES} bytes"
+        )
+    if not decompressor.eof:
+        raise ContentDecodingError("Corrupt compressed feed body: truncated")
+    return inflated
+
 
 class FeedFetcher:
     """Handles HTTP requests for RSS feeds."""
 
-    def __init__(self, timeout: int = 10):
+    def __init__(
+        self,
+        timeout: float = 10,
+        recordings: Optional[Recordings] = None,
+        deadline: Optional[float] = None,
+        session: Optional[requests.Session] = None,
+        headers: Optional[Mapping[str, str]] = None,
+        user_agent: Optional[str] = None,
+        meter: Optional[BandwidthMeter] = None,
+        conditional: bool = False,
+    ):
+        """
+        Args:
+            timeout: Seconds to wait for the connection and for each read
+            recordings: Recordings to save responses to or replay them from
+            deadline: Seconds a whole fetch may take; a bridge that trickles its
+                response would otherwise never hit the read timeout
+            session: Session to fetch with, e.g. one with proxies or client
+                certificates (the shared one by default)
+            headers: Headers to send with every request
+            user_agent: User-Agent to send instead of the session's
+            meter: Meter to count the downloaded bytes with
+            conditional: Send the ETag and Last-Modified of the last fetch of a URL,
+                raising FeedNotModified if the server answers 304; the validators
+                are kept for the lifetime of the process
+        """
         self.timeout = timeout
-        self.session = requests.Session()
-        self.session.headers.update({"User-Agent": "RSS-Parser/1.0"})
+        self.conditional = conditional
+        self.deadline = deadline
+        self.recordings = recordings
+        self.session = session or shared_session()
+        self.meter = meter
+        self.headers = dict(headers or {})
+        if user_agent:
+            self.headers["User-Agent"] = user_agent
+
+    def _get(self, url: str, conditional: bool = True, **kwargs) -> requests.Response:
+        """GET a URL, with the validators of its last fetch unless conditional is False."""
+        headers = dict(self.headers)
+        if conditional and self._is_conditional():
+            with _validators_lock:
+                validators = _validators.get(url, {})
+            if "etag" in validators:
+                headers["If-None-Match"] = validators["etag"]
+            if "last-modified" in validators:
+                headers["If-Modified-Since"] = validators["last-modified"]
+        if headers:
+            kwargs["headers"] = headers
+        return self.session.get(url, timeout=self.timeout, **kwargs)
+
+    def _is_conditional(self) -> bool:
+        # Recordings need every response in full
+        return self.conditional and not self.recordings
+
+    def forget(self, url: str) -> None:
+        """Fetch a URL in full next time, e.g. because its items failed to save."""
+        with _validators_lock:
+            _validators.pop(url, None)
 
     def fetch(self, url: str) -> str:
         if not url:
             raise ValueError("URL cannot be empty")
 
+        if self.recordings and self.recordings.replaying:
+            logger.info(f"Replaying RSS feed from {url}")
+            return self._replay(url)
+
         logger.info(f"Fetching RSS feed from {url}")
 
         try:
@@ -26,6 +275,82 @@ class FeedFetcher:
 
     def _fetch_direct(self, url: str) -> str:
         """Direct HTTP fetch."""
-        response = self.session.get(url, timeout=self.timeout)
+        if self.deadline is None:
+            response = self._get(url)
+        else:
+            response = self._get_before_deadline(url, time.monotonic() + self.deadline)
+        if self.meter:
+            self.meter.record_url(url, len(response.content))
+        if response.status_code == 304 and self._is_conditional():
+            logger.info(f"Feed not modified: {url}")
+            raise FeedNotModified(url)
+        text = decode_feed(decompress_body(response.content, response.headers), response.headers)
+        if self.recordings:
+            # Error responses are recorded too, so replays fail the same way
+            self.recordings.save_http(
+                HTTPRecording(url, response.status_code, dict(response.headers), text)
+            )
+        response.raise_for_status()
+        check_feed_response(url, response.headers, text)
+        if self._is_conditional():
+            self._remember(url, response.headers)
+        return text
+
+    def fetch_page(self, url: str) -> str:
+        """
+        Fetch a web page, e.g. to find the feeds it links to.
+
+        Unlike fetch(), the response isn't checked to be a feed and isn't
+        recorded or fetched conditionally: no validators are sent, so the
+        page always comes in full.
+
+        Raises:
+            requests.RequestException: If the page can't be fetched
+        """
+        if self.deadline is None:
+            response = self._get(url, conditional=False)
+        else:
+            deadline = time.monotonic() + self.deadline
+            response = self._get_before_deadline(url, deadline, conditional=False)
+        if self.meter:
+            self.meter.record_url(url, len(response.content))
         response.raise_for_status()
-        return response.text
+        return decode_feed(decompress_body(response.content, response.headers), response.headers)
+
+    @staticmethod
+    def _remember(url: str, headers: Mapping[str, str]) -> None:
+        """Keep the validators of a response for the next fetch of its URL."""
+        lowered = {k.lower(): v for k, v in headers.items()}
+        validators = {k: lowered[k] for k in ("etag", "last-modified") if lowered.get(k)}
+        with _validators_lock:
+            if validators:
+                _validators[url] = validators
+            else:
+                _validators.pop(url, None)
+
+    def _get_before_deadline(
+        self, url: str, deadline: float, conditional: bool = True
+    ) -> requests.Response:
+        """GET a URL, giving up once the deadline (a time.monotonic() value) passes."""
+        response = self._get(url, conditional=conditional, stream=True)
+        chunks = []
+        try:
+            for chunk in response.iter_content(64 * 1024):
+                if time.monotonic() > deadline:
+                    raise FetchDeadlineExceeded(
+                        f"Fetching {url} took longer than {self.deadline:g}s"
+                    )
+                chunks.append(chunk)
+        finally:
+            response.close()
+        # Makes response.content and response.text return the downloaded body
+        response._content = b"".join(chunks)
+        return response
+
+    def _replay(self, url: str) -> str:
+        """Recorded response, raised as an HTTP error if it was one."""
+        recording = self.recordings.load_http(url)
+        if recording.status >= 400:
+            raise requests.HTTPError(f"{recording.status} Error (recorded) for url: {url}")
+        check_feed_response(url, recording.headers, recording.body)
+        return recording.body
@@ -0,0 +1,296 @@
+"""User-defined post filters compiled to WebAssembly.
+
+Tenants ship small WASI modules that decide whether a post is kept. Modules
+are dropped into FILTERS_DIR, either at the top level (applied to every
+source) or in a subdirectory named after a channel/source (applied only to
+that source):
+
+    filters/
+        no_ads.wasm              # global
+        mediarzn/concerts.wasm   # only for the 'mediarzn' channel
+
+Each module is executed in a sandboxed WASI runtime process per post. The
+post is written to stdin as JSON:
+
+    {"source": "mediarzn", "link": "...", "content": "...",
+     "pub_date": "...", "media_urls": [...], "categories": [...]}
+
+and the module must print a JSON decision to stdout:
+
+    {"action": "keep" | "drop", "tags": ["concert"]}
+
+Runs are limited by FILTER_TIMEOUT seconds and FILTER_MEMORY_MB of linear
+memory, enforced by the runtime itself (wasmtime's epoch timeout and memory
+limit) and by killing a run that outlives the timeout. A filter that crashes,
+times out or prints garbage is logged and treated as "keep", so a broken
+module never silently drops posts.
+"""
+
+import asyncio
+import json
+import logging
+import os
+import shlex
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Dict, List, Optional, Union
+
+from common.event_format import classify_format
+from common.models.feed import RSSItem
+from common.rules import RuleError, RuleSet, post_variables
+
+logger = logging.getLogger(__name__)
+
+# {module}, {memory_bytes} and {timeout_ms} are substituted per run. Linear
+# memory is capped, and only that much address space is reserved for it
+# instead of wasmtime's default of several GiB.
+DEFAULT_RUNTIME_COMMAND = (
+    "wasmtime run -W max-memory-size={memory_bytes} -O memory-reservation={memory_bytes}"
+    " -W timeout={timeout_ms}ms {module}"
+)
+
+
+class FilterError(Exception):
+    """Raised when a filter module fails to produce a decision."""
+
+
+@dataclass
+class FilterDecision:
+    """Outcome of running filters on a post."""
+
+    keep: bool = True
+    tags: List[str] = field(default_factory=list)
+    # Which filter or rule dropped the post
+    reason: Optional[str] = None
+
+
+@dataclass
+class WasmFilter:
+    """A single WASM filter module."""
+
+    path: Path
+    runtime_command: str = DEFAULT_RUNTIME_COMMAND
+    timeout: float = 2.0
+    memory_mb: int = 64
+
+    @property
+    def name(self) -> str:
+        return self.path.stem
+
+    def _command(self) -> List[str]:
+        command = self.runtime_command.format(
+            module=shlex.quote(str(self.path)),
+            memory_bytes=self.memory_mb * 1024 * 1024,
+            timeout_ms=int(self.timeout * 1000),
+        )
+        return shlex.split(command)
+
+    async def run(self, payload: dict) -> FilterDecision:
+        """
+        Run the module for one post.
+
+        Args:
+            payload: Post data passed on stdin
+
+        Returns:
+            FilterDecision from the module
+
+        Raises:
+            FilterError: If the module fails, times out or returns invalid output
+        """
+        try:
+            process = await asyncio.create_subprocess_exec(
+                *self._command(),
+                stdin=asyncio.subprocess.PIPE,
+                stdout=asyncio.subprocess.PIPE,
+                stderr=asyncio.subprocess.PIPE,
+            )
+        except OSError as e:
+            raise FilterError(f"Failed to start WASM runtime: {e}")
+
+        stdin = json.dumps(payload, ensure_ascii=False).encode("utf-8")
+        try:
+            stdout, stderr = await asyncio.wait_for(process.communicate(stdin), self.timeout)
+        except asyncio.TimeoutError:
+            process.kill()
+            await process.wait()
+            raise FilterError(f"Timed out after {self.timeout}s")
+
+        if process.returncode != 0:
+            message = stderr.decode("utf-8", errors="replace").strip()[:200]
+            raise FilterError(f"Exited with status {process.returncode}: {message}")
+
+        return parse_decision(stdout.decode("utf-8", errors="replace"))
+
+
+def parse_decision(output: str) -> FilterDecision:
+    """
+    Parse a filter module's stdout.
+
+    Args:
+        output: JSON decision printed by the module
+
+    Returns:
+        FilterDecision
+
+    Raises:
+        FilterError: If the output is not a valid decision
+    """
+    try:
+        data = json.loads(output)
+    except ValueError as e:
+        raise FilterError(f"Invalid JSON output: {e}")
+
+    if not isinstance(data, dict) or data.get("action") not in ("keep", "drop"):
+        raise FilterError("Output must be an object with action 'keep' or 'drop'")
+
+    tags = data.get("tags") or []
+    if not isinstance(tags, list):
+        raise FilterError("tags must be a list")
+
+    return FilterDecision(keep=data["action"] == "keep", tags=[str(tag) for tag in tags])
+
+
+class FilterChain:
+    """Global and per-source filters loaded from a directory."""
+
+    def __init__(self, global_filters: List[WasmFilter], scoped: Dict[str, List[WasmFilter]]):
+        self.global_filters = global_filters
+        self.scoped = scoped
+
+    def __len__(self) -> int:
+        return len(self.global_filters) + sum(len(f) for f in self.scoped.values())
+
+    def for_source(self, source_name: str) -> List[WasmFilter]:
+        """Filters that apply to a source, global ones first."""
+        return self.global_filters + self.scoped.get(source_name, [])
+
+    async def apply(self, source_name: str, item: RSSItem) -> FilterDecision:
+        """
+        Run all applicable filters on an item.
+
+        The post is dropped if any filter drops it; tags from all filters are merged.
+
+        Args:
+            source_name: Channel or external source name
+            item: Parsed feed item
+
+        Returns:
+            Combined FilterDecision
+        """
+        decision = FilterDecision()
+        payload = {
+            "source": source_name,
+            "link": item.link,
+            "content": item.description,
+            "pub_date": item.pub_date,
+            "media_urls": item.media_urls,
+            "categories": item.categories,
+        }
+
+        for wasm_filter in self.for_source(source_name):
+            try:
+                result = await wasm_filter.run(payload)
+            except FilterError as e:
+                logger.error(f"Filter {wasm_filter.name} failed on {item.link}: {e}")
+                continue
+
+            for tag in result.tags:
+                if tag not in decision.tags:
+                    decision.tags.append(tag)
+            if not result.keep:
+                logger.debug(f"Filter {wasm_filter.name} dropped {item.link}")
+                decision.keep = False
+                decision.reason = f"filter {wasm_filter.name}"
+                break
+
+        return decision
+
+
+def load_filters(directory: Optional[str] = None) -> FilterChain:
+    """
+    Load filter modules from a directory.
+
+    Args:
+        directory: Filters directory (default: FILTERS_DIR env var)
+
+    Returns:
+        FilterChain (empty if no directory is configured)
+    """
+    directory = directory or os.getenv("FILTERS_DIR", "")
+    runtime_command = os.getenv("FILTER_RUNTIME_COMMAND", DEFAULT_RUNTIME_COMMAND)
+    timeout = float(os.getenv("FILTER_TIMEOUT", "2"))
+    memory_mb = int(os.getenv("FILTER_MEMORY_MB", "64"))
+
+    def make(path: Path) -> WasmFilter:
+        return WasmFilter(path, runtime_command, timeout, memory_mb)
+
+    if not directory:
+        return FilterChain([], {})
+
+    root = Path(directory)
+    global_filters = [make(path) for path in sorted(root.glob("*.wasm"))]
+    scoped = {
+        subdir.name: [make(path) for path in sorted(subdir.glob("*.wasm"))]
+        for subdir in sorted(root.iterdir())
+        if subdir.is_dir()
+    }
+
+    chain = FilterChain(global_filters, {name: f for name, f in scoped.items() if f})
+    logger.info(f"Loaded {len(chain)} WASM filters from {directory}")
+    return chain
+
+
+class RuleFilter:
+    """Expression-based filter rules (see common.rules)."""
+
+    def __init__(self, rules: RuleSet):
+        self.rules = rules
+
+    def __len__(self) -> int:
+        return len(self.rules.filters)
+
+    async def apply(self, source_name: str, item: RSSItem) -> FilterDecision:
+        """
+        Evaluate filter rules on an item.
+
+        Args:
+            source_name: Channel or external source name
+            item: Parsed feed item
+
+        Returns:
+            Combined FilterDecision
+        """
+        decision = FilterDecision()
+        variables = post_variables(
+            source_name,
+            item.link,
+            item.description,
+            item.pub_date,
+            item.media_urls,
+            event_format=classify_format(item.description),
+            categories=item.categories,
+        )
+
+        for rule in self.rules.filters:
+            try:
+                matched = rule.when.matches(variables)
+            except RuleError as e:
+                logger.error(f"Rule {rule.name} failed on {item.link}: {e}")
+                continue
+
+            if not matched:
+                continue
+            if rule.action == "drop":
+                logger.debug(f"Rule {rule.name} dropped {item.link}")
+                decision.keep = False
+                decision.reason = f"rule {rule.name}"
+                break
+            for tag in rule.tags:
+                if tag not in decision.tags:
+                    decision.tags.append(tag)
+
+        return decision
+
+
+PostFilter = Union[FilterChain, RuleFilter]
@@ -0,0 +1,97 @@
+"""Turning feed items into stored posts.
+
+Shared by the reader and by events created by hand through the API, so both
+are filtered, enriched (prices, admission, format, dates, series) and stored
+the same way.
+"""
+
+import json
+import logging
+from typing import List, Optional
+
+from common.db.models import RSSPost
+from common.db.repository import FieldOverrideRepository, RSSPostRepository
+from common.models.feed import RSSItem
+from common.series import assign_series, parent_link
+from .extraction import extract_fields, from_comparable
+from .filters import FilterDecision, PostFilter
+
+logger = logging.getLogger(__name__)
+
+
+async def apply_filters(
+    source_name: str, item: RSSItem, filters: Optional[List[PostFilter]] = None
+) -> FilterDecision:
+    """
+    Run rule and user-defined filters on an item; any of them may drop it.
+
+    Returns:
+        Combined FilterDecision with the tags of all filters that ran
+    """
+    decision = FilterDecision()
+    for post_filter in filters or []:
+        result = await post_filter.apply(source_name, item)
+        decision.tags += [tag for tag in result.tags if tag not in decision.tags]
+        if not result.keep:
+            decision.keep = False
+            decision.reason = result.reason or "filter"
+            break
+    return decision
+
+
+async def build_post(
+    item: RSSItem, tags: Optional[List[str]] = None, source_name: Optional[str] = None
+) -> RSSPost:
+    """
+    Build a post from an item with everything detected in its text.
+
+    Fields a moderator corrected on a post stored before keep the correction.
+
+    Args:
+        item: Feed item
+        tags: Tags to store on the post
+        source_name: Source the item came from, to recognize it by its ID later
+
+    Returns:
+        RSSPost, not saved yet (its series is assigned already)
+    """
+    media_json = json.dumps(item.media_urls) if item.media_urls else None
+    post = RSSPost(
+        link=item.link,
+        content=item.description,
+        pub_date=item.pub_date,
+        media=media_json,
+        tags=tags or None,
+        categories=item.categories or None,
+        source_guid=source_guid(source_name, item) if source_name else None,
+    )
+    for name, value in extract_fields(post.content, post.pub_date).items():
+        setattr(post, name, value)
+    await apply_overrides(post)
+    post.series_key = await assign_series(post)
+    return post
+
+
+async def apply_overrides(post: RSSPost) -> None:
+    """Put the moderators' corrections of a post over the fields extracted from its text."""
+    for override in await FieldOverrideRepository.get_by_link(post.link):
+        try:
+            value = from_comparable(override.field, json.loads(override.value))
+        except ValueError as e:
+            logger.warning(f"Ignoring the correction of {override.field} of {post.link}: {e}")
+            continue
+        setattr(post, override.field, value)
+
+
+def source_guid(source_name: str, item: RSSItem) -> str:
+    """Key of an item that stays the same when its link changes (see RSSPost.source_guid)."""
+    return f"{source_name}:{item.id()}"
+
+
+async def store_post(post: RSSPost) -> None:
+    """Save a new post and apply a cancellation or new date to the announcement it links to."""
+    await RSSPostRepository.create(post)
+
+    parent = parent_link(post.link, post.content) if post.event_status else None
+    if parent and await RSSPostRepository.set_event_status(parent, post.event_status):
+        logger.info(f"Marked {parent} as {post.event_status} by {post.link}")
@@ -1,13 +1,233 @@
+import asyncio
+import html.entities
+import json
 import logging
+import re
+from dataclasses import dataclass
+from datetime import datetime
 from xml.etree import ElementTree as ET
-from typing import Optional
+from xml.parsers import expat
+from xml.sax.saxutils import escape
+from typing import IO, Any, Callable, Dict, Iterator, List, Optional, Tuple, Union
+from urllib.parse import urljoin, urlsplit
 
-from common.models.feed import RSSChannel, RSSItem
+import requests
+
+from common.models.feed import DAY_NAMES, ChannelImage, PodcastEpisode, RSSChannel, RSSItem
+from common.utils.dates import UnparseableDate, parse_feed_date
 from common.utils.html import clean_content, extract_media_urls
-from .fetcher import FeedFetcher
+from .bandwidth import BandwidthMeter
+from .fetcher import FeedFetcher, FeedNotModified, decode_feed
+from .recordings import Recordings
 
 logger = logging.getLogger(__name__)
 
+ITEM_PATTERN = re.compile(r"<(item|entry)(?:\s[^>]*)?>.*?</\1\s*>", re.DOTALL)
+ROOT_START = re.compile(r"<(?:rss|feed|rdf:RDF)(?:\s[^>]*)?>")
+NAMESPACE_DECLARATION = re.compile(r"""xmlns(?::[\w.-]+)?\s*=\s*("[^"]*"|'[^']*')""")
+# Ampersands and what they may start, with the sections where they are plain text
+REFERENCE = re.compile(
+    r"<!\[CDATA\[.*?\]\]>|<!--.*?-->|&(?:#[0-9]+;|#x[0-9a-fA-F]+;|([A-Za-z][\w.-]*);)?",
+    re.DOTALL,
+)
+# Entities XML knows without a DTD
+XML_ENTITIES = {"amp", "lt", "gt", "quot", "apos"}
+
+
+# Title of feeds that don't give one
+DEFAULT_TITLE = "Unknown Feed"
+# xml:lang, the language of an Atom feed
+XML_LANG = "{http://www.w3.org/XML/1998/namespace}lang"
+
+# Feeds are small; anything bigger is broken or hostile
+MAX_DOCUMENT_LENGTH = 10 * 1024 * 1024
+
+
+class UnsafeXMLError(ValueError):
+    """Raised for feeds that declare entities or are too large to parse safely."""
+
+
+@dataclass(frozen=True)
+class ParseOptions:
+    """Limits for parsing feeds of sources that aren't trusted."""
+
+    # Fail on any malformed item or date instead of skipping it with a warning
+    strict: bool = False
+    # Items to keep, the first ones in document order; 0 keeps all
+    max_items: int = 0
+    # Characters to keep of each text field, 0 for no limit. Items with a longer
+    # link, GUID or comments URL are skipped: cut short, it would name something else
+    max_field_length: int = 0
+
+
+class _RootReached(Exception):
+    pass
+
+
+def check_xml_safety(content: str) -> None:
+    """
+    Reject XML that could attack the parser.
+
+    Entity declarations are what both external entities (XXE) and entity
+    expansion bombs (billion laughs) need, and no real feed declares any, so
+    the prolog is scanned for them before the document is parsed.
+
+    Raises:
+        UnsafeXMLError: If the document declares entities or is too long
+    """
+    if len(content) > MAX_DOCUMENT_LENGTH:
+        raise UnsafeXMLError(f"Feed is too large: {len(content)} characters")
+
+    try:
+        _safety_scanner().Parse(content, True)
+    except (_RootReached, expat.ExpatError):
+        # Syntax errors are left to the parser to report
+        pass
+
+
+def _safety_scanner():
+    """Expat parser rejecting entity declarations, raising _RootReached at the root."""
+
+    def reject_entity(name, *args):
+        raise UnsafeXMLError(f"Feed declares the entity '{name}', which is not allowed")
+
+    def stop(*args):
+        raise _RootReached
+
+    scanner = expat.ParserCreate()
+    scanner.EntityDeclHandler = reject_entity
+    scanner.UnparsedEntityDeclHandler = reject_entity
+    # Declarations can only come before the root element
+    scanner.StartElementHandler = stop
+    return scanner
+
+
+def _read_events(
+    source: IO, first: Union[str, bytes], chunk_size: int
+) -> Iterator[Tuple[str, ET.Element]]:
+    """
+    Start and end events of an XML document read in chunks.
+
+    The prolog is checked like check_xml_safety does before it reaches the parser.
+
+    Raises:
+        UnsafeXMLError: If the document declares entities
+        ET.ParseError: If the document isn't well-formed
+    """
+    scanner = _safety_scanner()
+    pull = ET.XMLPullParser(events=("start", "end"))
+    chunk = first
+    while chunk:
+        if scanner is not None:
+            try:
+                scanner.Parse(chunk, False)
+            except (_RootReached, expat.ExpatError):
+                scanner = None
+        pull.feed(chunk)
+        yield from pull.read_events()
+        chunk = source.read(chunk_size)
+    pull.close()
+    yield from pull.read_events()
+
+
+def repair_references(content: str) -> Tuple[str, List[str]]:
+    """
+    Fix the ampersands that keep a document from being well-formed.
+
+    HTML entities (&nbsp;, &copy;) are replaced with their characters and
+    ampersands that start no reference are escaped; CDATA sections and
+    comments are left alone.
+
+    Returns:
+        The repaired document, and what was repaired
+    """
+    replaced, escaped = 0, 0
+
+    def repair(match: re.Match) -> str:
+        nonlocal replaced, escaped
+        text, name = match.group(0), match.group(1)
+        if not text.startswith("&") or text[1:2] == "#" or name in XML_ENTITIES:
+            return text
+        character = html.entities.html5.get(f"{name};") if name else None
+        if character is None:
+            escaped += 1
+            return "&amp;" + text[1:]
+        replaced += 1
+        return escape(character)
+
+    content = REFERENCE.sub(repair, content)
+    repairs = []
+    if replaced:
+        repairs.append(f"Replaced {replaced} HTML entities")
+    if escaped:
+        repairs.append(f"Escaped {escaped} bare ampersands")
+    return content, repairs
+
+
+def _drop_repeated_guids(feed: RSSChannel) -> None:
+    """Keep the first of the items sharing a GUID."""
+    seen = set()
+    items = []
+    for item in feed.items:
+        if item.guid in seen:
+            feed.warnings.append(f"Skipped an item repeating the guid {item.guid}")
+            continue
+        if item.guid:
+            seen.add(item.guid)
+        items.append(item)
+    feed.items = items
+
+
+def is_absolute(url: str) -> bool:
+    """Check whether a URL is an absolute http(s) URL."""
+    parsed = urlsplit(url)
+    return parsed.scheme in ("http", "https") and bool(parsed.netloc)
+
+
+def resolve_urls(feed: RSSChannel, base_url: Optional[str] = None) -> None:
+    """
+    Make the links and media URLs of a feed's items absolute.
+
+    Args:
+        feed: Parsed feed, updated in place
+        base_url: URL the feed was fetched from, used when the channel link isn't absolute
+    """
+    base = _resolve_base(feed, base_url)
+    if base:
+        for item in feed.items:
+            _resolve_item_urls(item, base)
+
+
+def _resolve_base(feed: RSSChannel, base_url: Optional[str]) -> Optional[str]:
+    """
+    Make the channel link and image absolute, returning the URL to resolve items against.
+
+    Returns:
+        The base URL, None if there's no absolute one
+    """
+    if base_url and feed.link and not is_absolute(feed.link):
+        feed.link = urljoin(base_url, feed.link)
+    base = feed.link if is_absolute(feed.link or "") else base_url
+    base = base if base and is_absolute(base) else None
+    if base and feed.image:
+        feed.image.url = urljoin(base, feed.image.url)
+        if feed.image.link:
+            feed.image.link = urljoin(base, feed.image.link)
+    return base
+
+
+def _resolve_item_urls(item: RSSItem, base: str) -> None:
+    if item.link:
+        item.link = urljoin(base, item.link.strip())
+    if item.comments:
+        item.comments = urljoin(base, item.comments.strip())
+    if item.podcast:
+        for name in ("image", "audio_url"):
+            url = getattr(item.podcast, name)
+            if url:
+                setattr(item.podcast, name, urljoin(base, url))
+    item.media_urls = list(dict.fromkeys(urljoin(base, url.strip()) for url in item.media_urls))
+
 
 class RSSParser:
     """Production-grade RSS parser with error handling."""
@@ -17,23 +237,75 @@ class RSSParser:
         "content": "http://purl.org/rss/1.0/modules/content/",
         "media": "http://search.yahoo.com/mrss/",
         "dc": "http://purl.org/dc/elements/1.1/",
+        "rdf": "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+        "itunes": "http://www.itunes.com/dtds/podcast-1.0.dtd",
     }
+    # Element namespaces of RSS 1.0 and of its predecessor RSS 0.90, both RDF documents
+    RDF_FEED_NAMESPACES = ("http://purl.org/rss/1.0/", "http://my.netscape.com/rdf/simple/0.9/")
 
-    def __init__(self, timeout: int = 10):
+    def __init__(
+        self,
+        timeout: float = 10,
+        recordings: Optional[Recordings] = None,
+        strict_dates: bool = True,
+        deadline: Optional[float] = None,
+        session: Optional[requests.Session] = None,
+        headers: Optional[Dict[str, str]] = None,
+        user_agent: Optional[str] = None,
+        meter: Optional[BandwidthMeter] = None,
+        conditional: bool = False,
+        lenient: bool = False,
+        options: Optional[ParseOptions] = None,
+    ):
         """
         Initialize RSS parser.
 
         Args:
             timeout: Request timeout in seconds
+            recordings: Recordings to save responses to or replay them from
+            strict_dates: Skip items whose date matches no known format; otherwise
+                they are kept without a date
+            deadline: Seconds a whole fetch may take (no limit if None)
+            session: requests session to fetch with (see FeedFetcher)
+            headers: Headers to send with every request
+            user_agent: User-Agent to send instead of the default one
+            meter: Meter to count the downloaded bytes with
+            conditional: Fetch with the ETag / Last-Modified of the last fetch, so
+                unchanged feeds raise FeedNotModified instead of being downloaded again
+            lenient: Recover what can be recovered, with a warning for each fix:
+                repair bare ampersands and HTML entities, keep items with unknown
+                dates (whatever strict_dates says) and skip items repeating a GUID
+            options: Limits for untrusted feeds; strict options can't be lenient
+
+        Raises:
+            ValueError: If both lenient and strict parsing are asked for
         """
-        self.fetcher = FeedFetcher(timeout=timeout)
+        self.options = options or ParseOptions()
+        if lenient and self.options.strict:
+            raise ValueError("A parser can't be both lenient and strict")
+        self.fetcher = FeedFetcher(
+            timeout=timeout,
+            recordings=recordings,
+            deadline=deadline,
+            session=session,
+            headers=headers,
+            user_agent=user_agent,
+            meter=meter,
+            conditional=conditional,
+        )
+        self.strict_dates = strict_dates and not lenient
+        self.lenient = lenient
 
-    def parse_url(self, url: str) -> RSSChannel:
+    def parse_url(
+        self, url: str, since: Optional[datetime] = None, limit: Optional[int] = None
+    ) -> RSSChannel:
         """
         Parse RSS feed from URL.
 
         Args:
             url: RSS feed URL
+            since: Keep only items published at or after this time
+            limit: Keep at most this many items (see RSSChannel.items_since)
 
         Returns:
             RSSChannel with parsed feed data
@@ -41,78 +313,508 @@ class RSSParser:
         Raises:
             ValueError: If URL is invalid or feed parsing fails
             requests.RequestException: If HTTP request fails
+            FeedNotModified: If the feed hasn't changed since the last conditional fetch
         """
         try:
             content = self.fetcher.fetch(url)
-            return self.parse_content(content)
+            return self.parse_content(content, base_url=url, since=since, limit=limit)
+        except FeedNotModified:
+            raise
         except Exception as e:
             logger.error(f"Failed to parse feed from {url}: {e}")
             raise ValueError(f"Failed to parse RSS feed: {e}")
 
-    def parse_content(self, xml_content: str) -> RSSChannel:
+    async def parse_url_async(
+        self, url: str, since: Optional[datetime] = None, limit: Optional[int] = None
+    ) -> RSSChannel:
+        """
+        Parse RSS feed from URL without blocking the event loop.
+
+        Cancelling the call (e.g. with asyncio.wait_for) stops waiting for it
+        right away; the fetch itself ends at the latest at the parser's deadline.
+
+        Raises:
+            ValueError: If URL is invalid or feed parsing fails
+        """
+        return await asyncio.to_thread(self.parse_url, url, since, limit)
+
+    def forget(self, url: str) -> None:
+        """Fetch a feed in full next time, even if it hasn't changed."""
+        self.fetcher.forget(url)
+
+    def parse_content(
+        self,
+        xml_content: Union[str, bytes],
+        base_url: Optional[str] = None,
+        since: Optional[datetime] = None,
+        limit: Optional[int] = None,
+    ) -> RSSChannel:
         """
         Parse RSS feed from XML string.
 
+        RSS 2.0, RSS 1.0 (RDF) and Atom documents are told apart by their root
+        element, JSON Feeds (jsonfeed.org) by their leading brace.
+        Relative item links and media URLs are resolved against the channel
+        link, or base_url if the channel has no absolute link.
+
         Args:
-            xml_content: XML content as string
+            xml_content: XML or JSON Feed content as string, or as bytes in the
+                encoding of their XML declaration (see decode_feed)
+            base_url: URL the feed was fetched from
+            since: Keep only items published at or after this time, so older
+                ones are dropped before anything else is done with them
+            limit: Keep at most this many of the first items
 
         Returns:
             RSSChannel with parsed feed data
         """
-        try:
-            root = ET.fromstring(xml_content)
-            logger.info("Successfully parsed XML content")
+        if isinstance(xml_content, bytes):
+            xml_content = decode_feed(xml_content)
+        feed = self._parse_document(xml_content)
+        if self.lenient:
+            _drop_repeated_guids(feed)
+        if since or limit is not None:
+            feed.items = feed.items_since(since, limit)
+        self._cap_channel(feed)
+        resolve_urls(feed, base_url)
+        return feed
 
-            if root.tag.endswith("rss"):
-                return self._parse_rss(root)
-            elif root.tag.endswith("feed"):
-                return self._parse_atom(root)
-            else:
-                raise ValueError(f"Unknown feed format: {root.tag}")
+    def parse_stream(
+        self,
+        source: IO,
+        handle: Callable[[RSSItem], Optional[bool]],
+        base_url: Optional[str] = None,
+        chunk_size: int = 64 * 1024,
+    ) -> RSSChannel:
+        """
+        Parse a feed item by item while it is read.
+
+        Each item is checked and resolved like parse_content does, handed to
+        handle and dropped, so memory stays flat however many items a feed
+        has; handle returning False stops the parsing. Unlike parse_content,
+        malformed items aren't recovered (a syntax error ends the parsing) and
+        there's no size limit. JSON Feeds can't be parsed incrementally and are
+        read in full.
+
+        Args:
+            source: Binary or text file-like object, like an open file or the
+                raw body of a streamed response
+            handle: Called with each item in document order
+            base_url: URL the feed was fetched from
+            chunk_size: Bytes or characters to read at a time
+
+        Returns:
+            RSSChannel with the channel data and warnings, but no items
 
+        Raises:
+            UnsafeXMLError: If the document declares entities
+            ValueError: If the document isn't a feed or isn't well-formed
+        """
+        first = source.read(chunk_size)
+        head = first.decode("utf-8", "replace") if isinstance(first, bytes) else first
+        if head.lstrip("\ufeff \t\r\n").startswith("{"):
+            feed = self.parse_content(first + source.read(), base_url=base_url)
+            items, feed.items = feed.items, []
+            for item in items:
+                if handle(item) is False:
+                    break
+            return feed
+
+        root, feed, base = None, None, None
+        guids = set()
+        item_parsers: Dict[str, Callable[[ET.Element], RSSItem]] = {}
+        item_depth = 1
+        # Open elements, innermost last
+        ancestors: List[ET.Element] = []
+        number = handled = 0
+        max_items = self.options.max_items
+        try:
+            for event, elem in _read_events(source, first, chunk_size):
+                if event == "start":
+                    if root is None:
+                        root = elem
+                        item_parsers = self._stream_item_parsers(root)
+                        item_depth = 2 if root.tag.endswith("rss") else 1
+                    ancestors.append(elem)
+                    continue
+
+                ancestors.pop()
+                if elem.tag not in item_parsers or len(ancestors) != item_depth:
+                    continue
+                # Channel elements before the first item are complete by now
+                ancestors[-1].remove(elem)
+                if feed is None:
+                    feed = self._parse_channel(root)
+                    base = _resolve_base(feed, base_url)
+                if max_items and handled >= max_items:
+                    feed.warnings.append(f"Kept the first {max_items} items")
+                    break
+                number += 1
+                item = self._checked_item(feed, number, item_parsers[elem.tag], elem)
+                if item is None:
+                    continue
+                if self.lenient and item.guid in guids:
+                    feed.warnings.append(f"Skipped an item repeating the guid {item.guid}")
+                    continue
+                if item.guid:
+                    guids.add(item.guid)
+                if base:
+                    _resolve_item_urls(item, base)
+                handled += 1
+                if handle(item) is False:
+                    break
         except ET.ParseError as e:
             logger.error(f"XML parsing error: {e}")
             raise ValueError(f"Invalid XML format: {e}")
 
+        # Channel elements after the items are picked up too
+        channel = self._parse_channel(root)
+        _resolve_base(channel, base_url)
+        self._cap_channel(channel)
+        channel.warnings = feed.warnings if feed else []
+        logger.info(f"Streamed feed: {channel.title} with {number} items")
+        return channel
+
+    def _stream_item_parsers(self, root: ET.Element) -> Dict[str, Callable[[ET.Element], RSSItem]]:
+        """Parsers of the items of a streamed document by their tag."""
+        if root.tag.endswith("rss"):
+            return {"item": self._parse_rss_item}
+        if root.tag.endswith("feed"):
+            return {f"{{{self.NAMESPACES['atom']}}}entry": self._parse_atom_entry}
+        if root.tag == f"{{{self.NAMESPACES['rdf']}}}RDF":
+            return {
+                f"{{{ns}}}item": lambda elem, ns=ns: self._parse_rdf_item(elem, ns)
+                for ns in self.RDF_FEED_NAMESPACES
+            }
+        raise ValueError(f"Unknown feed format: {root.tag}")
+
+    def _parse_channel(self, root: ET.Element) -> RSSChannel:
+        """Channel data of an RSS, RSS 1.0 or Atom document, without its items."""
+        if root.tag.endswith("rss"):
+            return self._rss_channel(root)[0]
+        if root.tag.endswith("feed"):
+            return self._atom_channel(root)
+        return self._rdf_channel(root)[0]
+
+    def _parse_document(self, xml_content: str) -> RSSChannel:
+        """Parse an RSS, RSS 1.0, Atom or JSON Feed document."""
+        if xml_content.lstrip("\ufeff \t\r\n").startswith("{"):
+            return self.parse_json_feed(xml_content)
+
+        check_xml_safety(xml_content)
+        warnings: List[str] = []
+        try:
+            root = ET.fromstring(xml_content)
+            logger.info("Successfully parsed XML content")
+        except ET.ParseError as e:
+            root, error = None, e
+            if self.lenient:
+                xml_content, warnings = repair_references(xml_content)
+                try:
+                    root = ET.fromstring(xml_content)
+                except ET.ParseError as e:
+                    error = e
+            if root is None and self.options.strict:
+                logger.error(f"XML parsing error: {error}")
+                raise ValueError(f"Invalid XML format: {error}")
+            if root is None:
+                root, skipped = self._parse_items_separately(xml_content, error)
+                warnings += skipped
+
+        if root.tag.endswith("rss"):
+            feed = self._parse_rss(root)
+        elif root.tag.endswith("feed"):
+            feed = self._parse_atom(root)
+        elif root.tag == f"{{{self.NAMESPACES['rdf']}}}RDF":
+            feed = self._parse_rdf(root)
+        else:
+            raise ValueError(f"Unknown feed format: {root.tag}")
+        feed.warnings[:0] = warnings
+        return feed
+
+    def _parse_items_separately(
+        self, xml_content: str, error: ET.ParseError
+    ) -> Tuple[ET.Element, List[str]]:
+        """
+        Parse a feed that isn't well-formed item by item, skipping the broken items.
+
+        A bad entity or a broken CDATA section in one item shouldn't lose the
+        whole feed, so the feed is parsed without its items, then each item on
+        its own.
+
+        Returns:
+            Feed root with the items that could be parsed, and why the others were skipped
+
+        Raises:
+            ValueError: If the feed is broken outside its items
+        """
+        items = list(ITEM_PATTERN.finditer(xml_content))
+        skeleton = ITEM_PATTERN.sub("", xml_content)
+        try:
+            root = ET.fromstring(skeleton)
+        except ET.ParseError:
+            logger.error(f"XML parsing error: {error}")
+            raise ValueError(f"Invalid XML format: {error}")
+
+        # Items use the prefixes declared on the root element
+        start = ROOT_START.search(skeleton)
+        declarations = " ".join(
+            m.group(0) for m in NAMESPACE_DECLARATION.finditer(start.group(0) if start else "")
+        )
+        parent = root.find("channel") if root.tag.endswith("rss") else root
+        if parent is None:
+            raise ValueError("Invalid RSS: no channel element found")
+
+        warnings = []
+        for number, match in enumerate(items, 1):
+            try:
+                wrapper = ET.fromstring(f"<wrapper {declarations}>{match.group(0)}</wrapper>")
+            except ET.ParseError as e:
+                warnings.append(f"Skipped malformed item {number}: {e}")
+                continue
+            parent.extend(wrapper)
+
+        logger.warning(f"Recovered malformed feed: skipped {len(warnings)} of {len(items)} items")
+        return root, warnings
+
+    def parse_json_feed(self, content: str) -> RSSChannel:
+        """
+        Parse a JSON Feed (versions 1 and 1.1).
+
+        Args:
+            content: JSON Feed document
+
+        Returns:
+            RSSChannel with parsed feed data
+
+        Raises:
+            ValueError: If the document isn't a JSON Feed
+        """
+        try:
+            data = json.loads(content)
+        except ValueError as e:
+            logger.error(f"JSON parsing error: {e}")
+            raise ValueError(f"Invalid JSON Feed: {e}")
+        if not isinstance(data, dict) or "jsonfeed.org/version/" not in str(data.get("version")):
+            raise ValueError("Invalid JSON Feed: no jsonfeed.org version")
+
+        feed = RSSChannel(
+            title=data.get("title") or DEFAULT_TITLE,
+            link=data.get("home_page_url") or data.get("feed_url") or "",
+            description=data.get("description") or "",
+            language=data.get("language"),
+            self_url=data.get("feed_url") or None,
+        )
+        icon = data.get("icon") or data.get("favicon")
+        if isinstance(icon, str) and icon.strip():
+            feed.image = ChannelImage(url=icon.strip(), title=feed.title)
+        hubs = data.get("hubs")
+        for hub in hubs if isinstance(hubs, list) else []:
+            url = hub.get("url") if isinstance(hub, dict) else None
+            if isinstance(url, str) and url.strip() and url.strip() not in feed.hubs:
+                feed.hubs.append(url.strip())
+
+        items = data.get("items")
+        for number, entry in enumerate(items if isinstance(items, list) else [], 1):
+            if isinstance(entry, dict) and not self._add_item(
+                feed, number, self._parse_json_item, entry
+            ):
+                break
+
+        logger.info(f"Parsed JSON Feed: {feed.title} with {len(feed.items)} items")
+        return feed
+
     def _parse_rss(self, root: ET.Element) -> RSSChannel:
         """Parse RSS 2.0 format."""
+        feed, channel = self._rss_channel(root)
+        for number, item_elem in enumerate(channel.findall("item"), 1):
+            if not self._add_item(feed, number, self._parse_rss_item, item_elem):
+                break
+
+        logger.info(f"Parsed RSS feed: {feed.title} with {len(feed.items)} items")
+        return feed
+
+    def _rss_channel(self, root: ET.Element) -> Tuple[RSSChannel, ET.Element]:
+        """Channel data of an RSS 2.0 document, and the element holding its items."""
         channel = root.find("channel")
         if channel is None:
             raise ValueError("Invalid RSS: no channel element found")
 
         feed = RSSChannel(
-            title=self._get_text(channel, "title", "Unknown Feed"),
+            title=self._get_text(channel, "title", DEFAULT_TITLE),
             link=self._get_text(channel, "link", ""),
             description=self._get_text(channel, "description", ""),
-            language=self._get_text(channel, "language"),
+            language=self._get_text(channel, "language").strip() or None,
             last_build_date=self._get_text(channel, "lastBuildDate"),
         )
-
-        for item_elem in channel.findall("item"):
-            item = self._parse_rss_item(item_elem)
-            feed.items.append(item)
-
-        logger.info(f"Parsed RSS feed: {feed.title} with {len(feed.items)} items")
-        return feed
+        self._add_websub_links(feed, channel)
+        self._add_poll_hints(feed, channel)
+        feed.image = self._rss_image(channel.find("image"))
+        if feed.image is None:
+            logo = self._get_attr(channel.find(f"{{{self.NAMESPACES['itunes']}}}image"), "href")
+            feed.image = ChannelImage(url=logo.strip()) if logo.strip() else None
+        return feed, channel
 
     def _parse_atom(self, root: ET.Element) -> RSSChannel:
         """Parse Atom format."""
         ns = self.NAMESPACES["atom"]
+        feed = self._atom_channel(root)
+        for number, entry in enumerate(root.findall(f"{{{ns}}}entry"), 1):
+            if not self._add_item(feed, number, self._parse_atom_entry, entry):
+                break
+
+        logger.info(f"Parsed Atom feed: {feed.title} with {len(feed.items)} items")
+        return feed
 
+    def _atom_channel(self, root: ET.Element) -> RSSChannel:
+        """Feed data of an Atom document."""
+        ns = self.NAMESPACES["atom"]
+        # The self and hub links often come before the one to the site
+        links = root.findall(f"{{{ns}}}link")
+        alternate = [link for link in links if link.get("rel", "alternate") == "alternate"]
         feed = RSSChannel(
-            title=self._get_text(root, f"{{{ns}}}title", "Unknown Feed"),
-            link=self._get_attr(root.find(f"{{{ns}}}link"), "href", ""),
+            title=self._get_text(root, f"{{{ns}}}title", DEFAULT_TITLE),
+            link=self._get_attr((alternate or links or [None])[0], "href", ""),
             description=self._get_text(root, f"{{{ns}}}subtitle", ""),
+            language=(root.get(XML_LANG) or "").strip() or None,
             last_build_date=self._get_text(root, f"{{{ns}}}updated"),
         )
+        self._add_websub_links(feed, root)
+        # The logo is the bigger one, the icon a favicon
+        for tag in ("logo", "icon"):
+            url = self._get_text(root, f"{{{ns}}}{tag}").strip()
+            if url:
+                feed.image = ChannelImage(url=url, title=feed.title)
+                break
+        return feed
 
-        for entry in root.findall(f"{{{ns}}}entry"):
-            item = self._parse_atom_entry(entry)
-            feed.items.append(item)
+    def _parse_rdf(self, root: ET.Element) -> RSSChannel:
+        """Parse RSS 1.0 (RDF) format, where items are siblings of the channel."""
+        feed, ns = self._rdf_channel(root)
+        for number, item_elem in enumerate(root.findall(f"{{{ns}}}item"), 1):
+            if not self._add_item(
+                feed, number, lambda elem: self._parse_rdf_item(elem, ns), item_elem
+            ):
+                break
 
-        logger.info(f"Parsed Atom feed: {feed.title} with {len(feed.items)} items")
+        logger.info(f"Parsed RSS 1.0 feed: {feed.title} with {len(feed.items)} items")
         return feed
 
+    def _rdf_channel(self, root: ET.Element) -> Tuple[RSSChannel, str]:
+        """Channel data of an RSS 1.0 document, and the namespace of its elements."""
+        channel, ns = None, None
+        for ns in self.RDF_FEED_NAMESPACES:
+            channel = root.find(f"{{{ns}}}channel")
+            if channel is not None:
+                break
+        if channel is None:
+            raise ValueError("Invalid RSS 1.0: no channel element found")
+
+        dc = self.NAMESPACES["dc"]
+        feed = RSSChannel(
+            title=self._get_text(channel, f"{{{ns}}}title", DEFAULT_TITLE),
+            link=self._get_text(channel, f"{{{ns}}}link", ""),
+            description=self._get_text(channel, f"{{{ns}}}description", ""),
+            language=self._get_text(channel, f"{{{dc}}}language") or None,
+            last_build_date=self._get_text(channel, f"{{{dc}}}date") or None,
+        )
+        self._add_websub_links(feed, channel)
+        # The channel points at an image element beside it, like it does at its items
+        image = root.find(f"{{{ns}}}image")
+        feed.image = self._rss_image(image, ns) if image is not None else None
+        if feed.image is None:
+            resource = f"{{{self.NAMESPACES['rdf']}}}resource"
+            url = self._get_attr(channel.find(f"{{{ns}}}image"), resource)
+            feed.image = ChannelImage(url=url.strip()) if url.strip() else None
+        return feed, ns
+
+    def _add_item(
+        self, feed: RSSChannel, number: int, parse: Callable[[Any], RSSItem], source: Any
+    ) -> bool:
+        """
+        Parse an item into a feed, skipping it with a warning if that fails.
+
+        Returns:
+            False once the feed holds max_items items and the rest are ignored
+        """
+        max_items = self.options.max_items
+        if max_items and len(feed.items) >= max_items:
+            feed.warnings.append(f"Kept the first {max_items} items")
+            return False
+        item = self._checked_item(feed, number, parse, source)
+        if item is not None:
+            feed.items.append(item)
+        return True
+
+    def _checked_item(
+        self, feed: RSSChannel, number: int, parse: Callable[[Any], RSSItem], source: Any
+    ) -> Optional[RSSItem]:
+        """Parse an item, or add a warning to the feed and return None if it's skipped."""
+        try:
+            item = parse(source)
+        except Exception as e:
+            self._skip(feed, number, e)
+            return None
+
+        if item.pub_date:
+            try:
+                parse_feed_date(item.pub_date)
+            except UnparseableDate as e:
+                if self.strict_dates or self.options.strict:
+                    self._skip(feed, number, e)
+                    return None
+                feed.warnings.append(f"Item {number} kept without a date: {e}")
+                item.pub_date = None
+        return self._cap_item(feed, number, item)
+
+    def _skip(self, feed: RSSChannel, number: int, problem: Any) -> None:
+        """
+        Note a skipped item.
+
+        Raises:
+            ValueError: Instead, when parsing strictly
+        """
+        if self.options.strict:
+            raise ValueError(f"Invalid item {number}: {problem}")
+        feed.warnings.append(f"Skipped item {number}: {problem}")
+
+    def _cap_item(self, feed: RSSChannel, number: int, item: RSSItem) -> Optional[RSSItem]:
+        """Cut the text fields of an item to max_field_length, None if its URLs are longer."""
+        limit = self.options.max_field_length
+        if not limit:
+            return item
+        for name in ("link", "guid", "comments"):
+            if len(getattr(item, name) or "") > limit:
+                self._skip(feed, number, f"{name} longer than {limit} characters")
+                return None
+
+        cut = []
+        for name in ("description", "title", "author"):
+            value = getattr(item, name)
+            if value and len(value) > limit:
+                setattr(item, name, value[:limit])
+                cut.append(name)
+        if cut:
+            feed.warnings.append(f"Cut the {', '.join(cut)} of item {number} to {limit} characters")
+        item.categories = [category[:limit] for category in item.categories]
+        item.media_urls = [url for url in item.media_urls if len(url) <= limit]
+        if item.podcast and len(item.podcast.audio_url or "") > limit:
+            item.podcast.audio_url = None
+        return item
+
+    def _cap_channel(self, feed: RSSChannel) -> None:
+        """Cut the text fields of a channel to max_field_length."""
+        limit = self.options.max_field_length
+        if not limit:
+            return
+        for name in ("title", "link", "description", "language", "last_build_date", "self_url"):
+            value = getattr(feed, name)
+            if value and len(value) > limit:
+                setattr(feed, name, value[:limit])
+        feed.hubs = [hub for hub in feed.hubs if len(hub) <= limit]
+        if feed.image and len(feed.image.url) > limit:
+            feed.image = None
+
     def _parse_rss_item(self, item_elem: ET.Element) -> RSSItem:
         """Parse individual RSS item."""
         description = self._get_text(item_elem, "description", "")
@@ -134,11 +836,93 @@ class RSSParser:
         # 2. Extract from HTML description (img src and video poster)
         media_urls.extend(extract_media_urls(description))
 
+        categories = [elem.text for elem in item_elem.findall("category")]
+        categories.extend(elem.text for elem in item_elem.findall(self._dc("subject")))
+
+        # isPermaLink defaults to true: the GUID is then the item's URL
+        guid_elem = item_elem.find("guid")
+        guid = (guid_elem.text or "").strip() if guid_elem is not None else ""
+        is_permalink = bool(guid) and guid_elem.get("isPermaLink", "true").lower() != "false"
+        link = self._get_text(item_elem, "link", "").strip()
+
+        podcast = self._parse_podcast(item_elem)
+        if podcast and podcast.image:
+            media_urls.insert(0, podcast.image)
+
+        # WordPress and others give the date and author only as Dublin Core elements
+        pub_date = self._get_text(item_elem, "pubDate") or self._get_text(
+            item_elem, self._dc("date")
+        )
+
+        return RSSItem(
+            link=link or (guid if is_permalink else ""),
+            description=clean_content(description),
+            pub_date=pub_date,
+            title=_clean_title(self._get_text(item_elem, "title")),
+            media_urls=media_urls,
+            categories=_unique_categories(categories),
+            guid=guid or None,
+            guid_is_permalink=is_permalink,
+            author=self._get_text(item_elem, "author").strip() or self._creators(item_elem),
+            comments=self._get_text(item_elem, "comments").strip() or None,
+            podcast=podcast,
+        )
+
+    def _parse_podcast(self, item_elem: ET.Element) -> Optional[PodcastEpisode]:
+        """Parse the iTunes fields and audio enclosure of an episode, None if it has neither."""
+        ns = self.NAMESPACES["itunes"]
+        fields = {
+            child.tag[len(ns) + 2 :]: (child.text or "").strip()
+            for child in item_elem
+            if child.tag.startswith(f"{{{ns}}}")
+        }
+        image = self._get_attr(item_elem.find(f"{{{ns}}}image"), "href").strip()
+        audio = [
+            enclosure.get("url")
+            for enclosure in item_elem.findall("enclosure")
+            if enclosure.get("type", "").startswith("audio/") and enclosure.get("url")
+        ]
+        if not fields and not audio:
+            return None
+
+        explicit = fields.get("explicit", "").lower()
+        return PodcastEpisode(
+            duration=_parse_duration(fields.get("duration", "")),
+            image=image or None,
+            season=_parse_number(fields.get("season", "")),
+            episode=_parse_number(fields.get("episode", "")),
+            episode_type=fields.get("episodeType", "").lower() or None,
+            title=fields.get("title") or None,
+            explicit=explicit in ("true", "yes", "explicit") if explicit else None,
+            audio_url=audio[0].strip() if audio else None,
+        )
+
+    def _parse_rdf_item(self, item_elem: ET.Element, ns: str) -> RSSItem:
+        """Parse individual RSS 1.0 item."""
+        description = self._get_text_with_ns(item_elem, "content", "encoded")
+        description = description or self._get_text(item_elem, f"{{{ns}}}description", "")
+
+        media_ns = self.NAMESPACES["media"]
+        media_urls = [
+            media_elem.get("url")
+            for media_elem in item_elem.findall(f"{{{media_ns}}}content")
+            if media_elem.get("url")
+        ]
+        media_urls.extend(extract_media_urls(description))
+
+        # rdf:about is the item's URI, usually the same as its link
+        about = item_elem.get(f"{{{self.NAMESPACES['rdf']}}}about", "")
         return RSSItem(
-            link=self._get_text(item_elem, "link", ""),
+            link=self._get_text(item_elem, f"{{{ns}}}link", "").strip() or about,
             description=clean_content(description),
-            pub_date=self._get_text(item_elem, "pubDate"),
+            pub_date=self._get_text(item_elem, self._dc("date")) or None,
+            title=_clean_title(self._get_text(item_elem, f"{{{ns}}}title")),
             media_urls=media_urls,
+            categories=_unique_categories(
+                elem.text for elem in item_elem.findall(self._dc("subject"))
+            ),
+            guid=about or None,
+            author=self._creators(item_elem),
         )
 
     def _parse_atom_entry(self, entry: ET.Element) -> RSSItem:
@@ -147,6 +931,8 @@ class RSSParser:
 
         link_elem = entry.find(f"{{{ns}}}link")
         link = self._get_attr(link_elem, "href", "") if link_elem is not None else ""
+        links = entry.findall(f"{{{ns}}}link")
+        replies = [elem.get("href") for elem in links if elem.get("rel") == "replies"]
 
         content = self._get_text(entry, f"{{{ns}}}content", "")
         if not content:
@@ -155,11 +941,53 @@ class RSSParser:
         # Extract media URLs from content
         media_urls = extract_media_urls(content)
 
+        # The label is meant for people, the term may be a slug
+        categories = [
+            elem.get("label") or elem.get("term") for elem in entry.findall(f"{{{ns}}}category")
+        ]
+
         return RSSItem(
             link=link,
             description=clean_content(content),
             pub_date=self._get_text(entry, f"{{{ns}}}published"),
+            title=_clean_title(self._get_text(entry, f"{{{ns}}}title")),
             media_urls=media_urls,
+            categories=_unique_categories(categories),
+            guid=self._get_text(entry, f"{{{ns}}}id").strip() or None,
+            author=self._get_text(entry.find(f"{{{ns}}}author"), f"{{{ns}}}name").strip() or None,
+            comments=replies[0] if replies else None,
+        )
+
+    @staticmethod
+    def _parse_json_item(entry: dict) -> RSSItem:
+        """Parse individual JSON Feed item."""
+        content = entry.get("content_html") or entry.get("content_text") or ""
+        content = content or entry.get("summary") or ""
+
+        media_urls = [entry[key] for key in ("image", "banner_image") if entry.get(key)]
+        for attachment in entry.get("attachments") or []:
+            mime_type = attachment.get("mime_type", "") if isinstance(attachment, dict) else ""
+            if mime_type.startswith(("image/", "video/")) and attachment.get("url"):
+                media_urls.append(attachment["url"])
+        media_urls.extend(extract_media_urls(content))
+
+        # Version 1.1 has a list of authors, 1.0 a single one
+        authors = entry.get("authors") or [entry.get("author")]
+        names = [author.get("name") for author in authors if isinstance(author, dict)]
+        guid = str(entry.get("id") or "").strip()
+
+        return RSSItem(
+            # The ID is often the permalink when there's no url
+            link=entry.get("url") or entry.get("external_url") or guid,
+            guid=guid or None,
+            author=", ".join(name for name in names if name) or None,
+            description=clean_content(content),
+            pub_date=entry.get("date_published") or entry.get("date_modified"),
+            title=_clean_title(str(entry.get("title") or "")),
+            media_urls=list(dict.fromkeys(media_urls)),
+            categories=_unique_categories(
+                tag for tag in entry.get("tags") or [] if isinstance(tag, str)
+            ),
         )
 
     @staticmethod
@@ -180,9 +1008,82 @@ class RSSParser:
         child = elem.find(f"{{{ns}}}{tag}") if ns else elem.find(tag)
         return child.text or default if child is not None else default
 
+    def _dc(self, tag: str) -> str:
+        """Qualified name of a Dublin Core element."""
+        return f"{{{self.NAMESPACES['dc']}}}{tag}"
+
+    def _add_websub_links(self, feed: RSSChannel, channel: ET.Element) -> None:
+        """Take the self URL and the WebSub hubs from the atom:link elements of a channel."""
+        for link in channel.findall(f"{{{self.NAMESPACES['atom']}}}link"):
+            href = (link.get("href") or "").strip()
+            rels = (link.get("rel") or "").split()
+            if href and "hub" in rels and href not in feed.hubs:
+                feed.hubs.append(href)
+            if href and "self" in rels and not feed.self_url:
+                feed.self_url = href
+
+    def _rss_image(self, image: Optional[ET.Element], ns: str = "") -> Optional[ChannelImage]:
+        """The <image> of an RSS 2.0 or RSS 1.0 channel, None without one or without its URL."""
+        prefix = f"{{{ns}}}" if ns else ""
+        url = self._get_text(image, f"{prefix}url").strip()
+        if not url:
+            return None
+        return ChannelImage(
+            url=url,
+            title=self._get_text(image, f"{prefix}title").strip() or None,
+            link=self._get_text(image, f"{prefix}link").strip() or None,
+            width=_parse_number(self._get_text(image, f"{prefix}width").strip()),
+            height=_parse_number(self._get_text(image, f"{prefix}height").strip()),
+        )
+
+    def _add_poll_hints(self, feed: RSSChannel, channel: ET.Element) -> None:
+        """Take the ttl, skipHours and skipDays of an RSS 2.0 channel, ignoring invalid values."""
+        ttl = self._get_text(channel, "ttl", "").strip()
+        if ttl.isdigit():
+            feed.ttl = int(ttl)
+        for hour in channel.findall("skipHours/hour"):
+            value = (hour.text or "").strip()
+            # Some feeds count hours from 1 to 24
+            if value.isdigit() and int(value) <= 24 and int(value) % 24 not in feed.skip_hours:
+                feed.skip_hours.append(int(value) % 24)
+        for day in channel.findall("skipDays/day"):
+            name = (day.text or "").strip().capitalize()
+            if name in DAY_NAMES and name not in feed.skip_days:
+                feed.skip_days.append(name)
+
+    def _creators(self, item_elem: ET.Element) -> Optional[str]:
+        """The dc:creator elements of an item, comma-separated."""
+        names = [(elem.text or "").strip() for elem in item_elem.findall(self._dc("creator"))]
+        return ", ".join(name for name in names if name) or None
+
     @staticmethod
     def _get_attr(elem: Optional[ET.Element], attr: str, default: str = "") -> str:
         """Safely get attribute from element."""
         if elem is None:
             return default
         return elem.get(attr, default)
+
+
+def _clean_title(value: str) -> Optional[str]:
+    return " ".join(clean_content(value).split()) or None
+
+
+def _unique_categories(values) -> List[str]:
+    """Category names with whitespace collapsed, without empty ones and duplicates."""
+    names = (" ".join((value or "").split()) for value in values)
+    return list(dict.fromkeys(name for name in names if name))
+
+
+def _parse_duration(value: str) -> Optional[int]:
+    """Seconds of an itunes:duration, given as seconds, MM:SS or HH:MM:SS."""
+    parts = value.split(":")
+    if not value or len(parts) > 3 or not all(part.strip().isdigit() for part in parts):
+        return None
+    seconds = 0
+    for part in parts:
+        seconds = seconds * 60 + int(part)
+    return seconds
+
+
+def _parse_number(value: str) -> Optional[int]:
+    return int(value) if value.isdigit() else None
@@ -0,0 +1,147 @@
+"""Telegram channels read from their public preview pages.
+
+Telegram shows the latest posts of a public channel at
+https://t.me/s/<channel>, the same widget markup rss-bridge turns into feeds.
+When the bridge fails (it rate-limits aggressively), the reader parses that
+page instead. Posts read either way are alike: links and GUIDs are the
+message URLs, descriptions are cleaned like the bridge's and the channel
+title has the bridge's form, so switching between them saves nothing twice.
+"""
+
+import html
+import re
+from dataclasses import dataclass, field
+from html.parser import HTMLParser
+from typing import List, Optional
+
+from common.models.feed import RSSChannel, RSSItem
+from common.utils.html import clean_content
+
+PREVIEW_URL = "https://t.me/s/{channel}"
+
+# Elements of a message in the widget markup
+MESSAGE_CLASS = "tgme_widget_message"
+TEXT_CLASS = "tgme_widget_message_text"
+# The text of the message a post replies to has the text class too
+REPLY_TEXT_CLASS = "js-message_reply_text"
+DATE_CLASS = "tgme_widget_message_date"
+# Photos and video thumbnails are background images of these
+MEDIA_CLASSES = ("tgme_widget_message_photo_wrap", "tgme_widget_message_video_thumb")
+
+BACKGROUND_URL_REGEX = re.compile(r"background-image:\s*url\(['\"]?([^'\")]+)['\"]?\)")
+# Elements without an end tag, which don't open a nesting level
+VOID_TAGS = {"br", "img", "hr", "input", "meta", "link", "source", "wbr"}
+
+
+def build_preview_url(channel_name: str) -> str:
+    """URL of the preview page of a public Telegram channel."""
+    return PREVIEW_URL.format(channel=channel_name)
+
+
+@dataclass
+class _Message:
+    link: str
+    text: List[str] = field(default_factory=list)
+    pub_date: Optional[str] = None
+    media_urls: List[str] = field(default_factory=list)
+
+
+class _PreviewParser(HTMLParser):
+    """Collects the messages of a preview page and the channel's name."""
+
+    def __init__(self):
+        super().__init__(convert_charrefs=True)
+        self.title: Optional[str] = None
+        self.messages: List[_Message] = []
+        self._message: Optional[_Message] = None
+        # Nesting level inside the message text, 0 outside of it
+        self._text_depth = 0
+        self._in_date = False
+
+    def handle_starttag(self, tag, attrs):
+        attributes = {name: value or "" for name, value in attrs}
+        classes = attributes.get("class", "").split()
+        if tag == "meta" and attributes.get("property") == "og:title":
+            self.title = attributes.get("content") or None
+        if self._text_depth:
+            self._message.text.append(self.get_starttag_text())
+            if tag not in VOID_TAGS:
+                self._text_depth += 1
+            return
+
+        if MESSAGE_CLASS in classes and attributes.get("data-post"):
+            self._message = _Message(link=f"https://t.me/{attributes['data-post']}")
+            self.messages.append(self._message)
+        if not self._message:
+            return
+        if TEXT_CLASS in classes and REPLY_TEXT_CLASS not in classes:
+            self._text_depth = 1
+        elif DATE_CLASS in classes:
+            self._in_date = True
+        elif tag == "time" and self._in_date and attributes.get("datetime"):
+            self._message.pub_date = self._message.pub_date or attributes["datetime"]
+        elif any(name in classes for name in MEDIA_CLASSES):
+            match = BACKGROUND_URL_REGEX.search(attributes.get("style", ""))
+            if match and match.group(1) not in self._message.media_urls:
+                self._message.media_urls.append(match.group(1))
+
+    def handle_startendtag(self, tag, attrs):
+        if self._text_depth:
+            self._message.text.append(self.get_starttag_text())
+        else:
+            self.handle_starttag(tag, attrs)
+
+    def handle_endtag(self, tag):
+        if self._text_depth:
+            self._text_depth -= 1
+            if self._text_depth:
+                self._message.text.append(f"</{tag}>")
+        elif tag == "a":
+            self._in_date = False
+
+    def handle_data(self, data):
+        if self._text_depth:
+            self._message.text.append(html.escape(data, quote=False))
+
+
+def parse_preview(page: str, channel_name: str) -> RSSChannel:
+    """
+    Read the posts of a channel's preview page.
+
+    Args:
+        page: HTML of https://t.me/s/<channel>
+        channel_name: Name of the channel
+
+    Returns:
+        RSSChannel with the posts, newest first like the bridge's feeds;
+        messages without text (e.g. only a sticker) are left out
+
+    Raises:
+        ValueError: If the page shows no messages of the channel
+    """
+    parser = _PreviewParser()
+    parser.feed(page)
+    parser.close()
+    if not parser.messages:
+        raise ValueError(f"No messages on the preview page of {channel_name}")
+
+    items = []
+    for message in reversed(parser.messages):
+        description = clean_content("".join(message.text))
+        if not description:
+            continue
+        items.append(
+            RSSItem(
+                link=message.link,
+                description=description,
+                pub_date=message.pub_date,
+                media_urls=message.media_urls,
+                guid=message.link,
+                guid_is_permalink=True,
+            )
+        )
+    name = " ".join((parser.title or channel_name).split())
+    title = f"{name} (@{channel_name}) - Telegram"
+    return RSSChannel(
+        title=title, link=build_preview_url(channel_name), description=title, items=items
+    )
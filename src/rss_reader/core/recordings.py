@@ -0,0 +1,166 @@
+"""Recordings of source fetches for offline replay.
+
+With FETCH_RECORDING_MODE=record every feed response (including error
+statuses) and every external source's output is saved as a JSON file in
+FETCH_RECORDING_DIR. With FETCH_RECORDING_MODE=replay nothing is fetched:
+responses come from the recordings, so an extraction bug seen in production
+can be reproduced locally, e.g. with the in-memory backend:
+
+    FETCH_RECORDING_MODE=replay FETCH_RECORDING_DIR=recordings \\
+        DATABASE_DSN=memory:// python -m src.pipeline
+
+A fetch without a recording fails in replay mode rather than going online.
+
+Recordings older than FETCH_RECORDING_COMPRESS_DAYS are compressed with
+zstd after each recording run (feeds are mostly markup, which compresses well);
+replays read compressed and plain recordings alike.
+"""
+
+import hashlib
+import json
+import logging
+import os
+import re
+from compression import zstd
+from dataclasses import dataclass
+from datetime import timedelta
+from pathlib import Path
+from typing import Dict, List, Optional
+
+from common import clock
+
+logger = logging.getLogger(__name__)
+
+OFF = "off"
+RECORD = "record"
+REPLAY = "replay"
+MODES = (OFF, RECORD, REPLAY)
+
+# Suffix added to the name of a compressed recording
+COMPRESSED = ".zst"
+
+
+class RecordingNotFound(Exception):
+    """Raised in replay mode when a fetch was never recorded."""
+
+
+@dataclass
+class HTTPRecording:
+    """Raw response to a feed request."""
+
+    url: str
+    status: int
+    headers: Dict[str, str]
+    body: str
+
+
+@dataclass
+class ProcessRecording:
+    """Output of an external source process."""
+
+    name: str
+    returncode: int
+    stdout: str
+    stderr: str
+
+
+class Recordings:
+    """Directory of recorded fetches."""
+
+    def __init__(self, directory: str, mode: str = RECORD):
+        if mode not in (RECORD, REPLAY):
+            raise ValueError(f"Unknown recording mode: {mode}")
+        self.directory = Path(directory)
+        self.mode = mode
+
+    @staticmethod
+    def from_env() -> Optional["Recordings"]:
+        """Recordings configured by FETCH_RECORDING_MODE, or None if recording is off."""
+        mode = os.getenv("FETCH_RECORDING_MODE", OFF).lower()
+        if mode not in MODES:
+            raise ValueError(f"FETCH_RECORDING_MODE must be one of {', '.join(MODES)}")
+        if mode == OFF:
+            return None
+        return Recordings(os.getenv("FETCH_RECORDING_DIR", "recordings"), mode)
+
+    @property
+    def replaying(self) -> bool:
+        return self.mode == REPLAY
+
+    def _http_path(self, url: str) -> Path:
+        return self.directory / f"http-{hashlib.sha256(url.encode()).hexdigest()[:16]}.json"
+
+    def _process_path(self, name: str) -> Path:
+        slug = re.sub(r"[^\w.-]+", "_", name)
+        return self.directory / f"process-{slug}.json"
+
+    def _write(self, path: Path, data: dict) -> None:
+        self.directory.mkdir(parents=True, exist_ok=True)
+        data["recorded_at"] = clock.now().isoformat()
+        path.write_text(json.dumps(data, ensure_ascii=False, indent=2), encoding="utf-8")
+        # The new recording replaces a compressed older one
+        _compressed(path).unlink(missing_ok=True)
+        logger.debug(f"Recorded {path}")
+
+    def _read(self, path: Path, what: str) -> dict:
+        if path.exists():
+            return json.loads(path.read_text(encoding="utf-8"))
+        if _compressed(path).exists():
+            return _load_compressed(_compressed(path))
+        raise RecordingNotFound(f"No recording of {what} in {self.directory}")
+
+    def save_http(self, recording: HTTPRecording) -> None:
+        """Record a feed response."""
+        self._write(self._http_path(recording.url), {"kind": "http", **vars(recording)})
+
+    def load_http(self, url: str) -> HTTPRecording:
+        """Recorded response to a feed request."""
+        data = self._read(self._http_path(url), url)
+        return HTTPRecording(data["url"], data["status"], data.get("headers") or {}, data["body"])
+
+    def save_process(self, recording: ProcessRecording) -> None:
+        """Record the output of an external source."""
+        self._write(self._process_path(recording.name), {"kind": "process", **vars(recording)})
+
+    def load_process(self, name: str) -> ProcessRecording:
+        """Recorded output of an external source."""
+        data = self._read(self._process_path(name), f"external source '{name}'")
+        return ProcessRecording(data["name"], data["returncode"], data["stdout"], data["stderr"])
+
+    def recorded_urls(self) -> List[str]:
+        """URLs of all recorded feed responses."""
+        urls = []
+        for path in sorted(self.directory.glob("http-*.json")):
+            urls.append(json.loads(path.read_text(encoding="utf-8"))["url"])
+        for path in sorted(self.directory.glob(f"http-*.json{COMPRESSED}")):
+            if not path.with_suffix("").exists():
+                urls.append(_load_compressed(path)["url"])
+        return urls
+
+    def compress_older_than(self, days: int) -> int:
+        """
+        Compress the recordings made more than some days ago.
+
+        Returns:
+            Number of recordings compressed
+        """
+        cutoff = (clock.now() - timedelta(days=days)).timestamp()
+        compressed = 0
+        for path in sorted(self.directory.glob("*.json")):
+            if path.stat().st_mtime > cutoff:
+                continue
+            target = _compressed(path)
+            target.write_bytes(zstd.compress(path.read_bytes()))
+            path.unlink()
+            compressed += 1
+        if compressed:
+            logger.info(f"Compressed {compressed} recordings older than {days} days")
+        return compressed
+
+
+def _compressed(path: Path) -> Path:
+    return path.with_name(path.name + COMPRESSED)
+
+
+def _load_compressed(path: Path) -> dict:
+    return json.loads(zstd.decompress(path.read_bytes()).decode("utf-8"))
@@ -0,0 +1,101 @@
+"""Per-item report of a poll run.
+
+Each run of the reader records what it did with every feed item, so "why
+didn't this post show up?" can be answered from the API (/poll-runs) instead
+of the logs:
+
+- new        saved as a post
+- updated    already stored, but the source has since edited it (the stored post is kept
+             and the edit saved as a revision, see common.revisions)
+- duplicate  already stored, with no edit since the last run
+- filtered   dropped by a rule or WASM filter (the reason names it)
+- empty      no text
+- error      failed to save or, with the feed URL as link, the whole source failed
+- warning    with the feed URL as link, the source was read but some of its items
+             were too malformed to parse or were cut
+
+A run can be retried under the same run ID after a crash: outcomes are stored
+as each source is done, keyed by run, source and link, and the retry takes
+them over instead of processing those items again.
+"""
+
+import logging
+from collections import Counter
+from typing import Dict, List, Optional, Set, Tuple
+
+from common.db.models import PollRunItem
+from common.db.repository import PollRunRepository
+
+logger = logging.getLogger(__name__)
+
+NEW = "new"
+UPDATED = "updated"
+DUPLICATE = "duplicate"
+FILTERED = "filtered"
+EMPTY = "empty"
+ERROR = "error"
+WARNING = "warning"
+OUTCOMES = (NEW, UPDATED, DUPLICATE, FILTERED, EMPTY, ERROR, WARNING)
+
+
+class PollReport:
+    """Outcomes collected over one run, one per source and item."""
+
+    def __init__(
+        self, run_id: Optional[str] = None, recorded: Optional[List[PollRunItem]] = None
+    ):
+        """
+        Args:
+            run_id: Run to store the outcomes in as sources are done (see checkpoint())
+            recorded: Items stored by an earlier attempt of the same run, which a retry
+                takes over instead of processing them again; errors are retried
+        """
+        self.run_id = run_id
+        self.items: List[PollRunItem] = []
+        self._keys: Set[Tuple[str, str]] = set()
+        recorded = recorded or []
+        self.recorded = {
+            (item.source, item.link): item for item in recorded if item.outcome != ERROR
+        }
+        failed = {item.source for item in recorded if item.outcome == ERROR}
+        # Sources are stored once they are done, so these need no fetch at all
+        self.completed_sources = {item.source for item in recorded} - failed
+
+    def add(self, source: str, link: str, outcome: str, reason: Optional[str] = None) -> None:
+        """Record what happened to an item; the first outcome of an item is the one kept."""
+        if outcome not in OUTCOMES:
+            raise ValueError(f"Unknown poll outcome: {outcome}")
+        if (source, link) in self._keys:
+            # A feed listing the same post twice
+            return
+        self._keys.add((source, link))
+        self.items.append(PollRunItem(source=source, link=link, outcome=outcome, reason=reason))
+
+    def recorded_outcome(self, source: str, link: str) -> Optional[PollRunItem]:
+        """What an earlier attempt of the run did with an item, unless it failed."""
+        return self.recorded.get((source, link))
+
+    def take_over(self, source: str) -> List[PollRunItem]:
+        """Add the items an earlier attempt of the run stored for a source."""
+        items = [item for item in self.recorded.values() if item.source == source]
+        for item in items:
+            self.add(item.source, item.link, item.outcome, item.reason)
+        return items
+
+    async def checkpoint(self, source: str) -> None:
+        """
+        Store the outcomes of a source that is done, so a retry of the run can skip it.
+
+        A failure is logged rather than raised: the run can go on without it.
+        """
+        if not self.run_id:
+            return
+        items = [item for item in self.items if item.source == source]
+        try:
+            await PollRunRepository.add_items(self.run_id, items)
+        except Exception as e:
+            logger.error(f"Failed to store the outcomes of {source} in poll run {self.run_id}: {e}")
+
+    def counts(self) -> Dict[str, int]:
+        """Number of items per outcome."""
+        return dict(Counter(item.outcome for item in self.items))
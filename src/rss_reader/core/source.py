@@ -0,0 +1,149 @@
+"""Sources the reader polls for posts.
+
+A source has a name, which its posts and poll run outcomes are recorded
+under, and fetches its current items. Telegram channels (through a pool of RSS
+bridges, or their preview pages when they all fail) and external executables
+are sources; a new kind of source only
+has to implement `fetch` to be polled, filtered, deduplicated and reported
+like the others.
+"""
+
+import asyncio
+import logging
+from datetime import datetime
+from typing import List, Optional
+
+from common.db.models import TelegramChannel
+from common.db.repository import TelegramChannelRepository
+from common.models.feed import RSSChannel, RSSItem
+from .bridges import BridgePool
+from .fetcher import FeedNotModified
+from .parser import DEFAULT_TITLE, RSSParser
+from .preview import build_preview_url, parse_preview
+
+logger = logging.getLogger(__name__)
+
+
+class Source:
+    """Something items are fetched from; subclasses set name and implement fetch."""
+
+    name: str
+
+    @property
+    def location(self) -> str:
+        """Where the items come from, the link of the source's fetch errors in reports."""
+        return self.name
+
+    @property
+    def warnings(self) -> List[str]:
+        """Problems of the last fetch that didn't fail it, like skipped items."""
+        return []
+
+    async def fetch(self) -> List[RSSItem]:
+        """
+        Fetch the source's current items.
+
+        Raises:
+            FeedNotModified: If nothing changed since the last fetch
+        """
+        raise NotImplementedError
+
+    def forget(self) -> None:
+        """Fetch everything again next time, the items couldn't all be saved."""
+
+
+class TelegramSource(Source):
+    """A Telegram channel, read through the RSS bridges or from its preview page."""
+
+    def __init__(
+        self,
+        channel: TelegramChannel,
+        parser: RSSParser,
+        since: Optional[datetime] = None,
+        fallback: bool = False,
+        bridges: Optional[BridgePool] = None,
+    ):
+        """
+        Args:
+            channel: Channel to read
+            parser: Parser to fetch the channel's feed with
+            since: Ignore items published before this time
+            fallback: Read the channel's t.me/s preview page when the bridges fail
+            bridges: Bridges to fetch the feed from (rss-bridge.org if None)
+        """
+        self.channel = channel
+        self.parser = parser
+        self.since = since
+        self.fallback = fallback
+        self.bridges = bridges or BridgePool()
+        self.name = channel.channel_name
+        self._warnings: List[str] = []
+        # Feed URL on the bridge tried last
+        self._location = self.bridges.feed_urls(self.name)[0]
+
+    @property
+    def location(self) -> str:
+        return self._location
+
+    @property
+    def warnings(self) -> List[str]:
+        return self._warnings
+
+    async def fetch(self) -> List[RSSItem]:
+        """Fetch the channel's feed, keeping its title up to date."""
+        try:
+            feed = await self._fetch_feed()
+        except FeedNotModified:
+            raise
+        except Exception as e:
+            if not self.fallback:
+                raise
+            logger.warning(f"RSS bridges failed for {self.name}, reading t.me/s instead: {e}")
+            feed = await asyncio.to_thread(self._fetch_preview)
+        logger.info(f"✓ Channel: {self.name} - Feed: {feed.title} - Items: {len(feed.items)}")
+        if feed.title and feed.title not in (DEFAULT_TITLE, self.channel.title):
+            await TelegramChannelRepository.set_title(self.channel.channel_id, feed.title)
+        self._warnings = list(feed.warnings)
+        return feed.items
+
+    async def _fetch_feed(self) -> RSSChannel:
+        """
+        Fetch the channel's feed from the first bridge that serves it.
+
+        Raises:
+            FeedNotModified: If the feed didn't change since the last fetch
+            Exception: The error of the last bridge, if none serves it
+        """
+        error: Optional[Exception] = None
+        for url in self.bridges.feed_urls(self.name):
+            self._location = url
+            logger.info(f"Processing channel: {self.name} ({url})")
+            try:
+                # Parsed in a thread, so channels are fetched in parallel
+                feed = await self.parser.parse_url_async(url, since=self.since)
+            except FeedNotModified:
+                self.bridges.record_success(url)
+                raise
+            except Exception as e:
+                self.bridges.record_failure(url, e)
+                error = e
+                continue
+            self.bridges.record_success(url)
+            return feed
+        raise error
+
+    def _fetch_preview(self) -> RSSChannel:
+        """
+        Read the channel's preview page.
+
+        Raises:
+            requests.RequestException: If the page can't be fetched
+            ValueError: If it shows no messages
+        """
+        page = self.parser.fetcher.fetch_page(build_preview_url(self.name))
+        feed = parse_preview(page, self.name)
+        feed.items = feed.items_since(self.since)
+        return feed
+
+    def forget(self) -> None:
+        self.parser.forget(self.location)
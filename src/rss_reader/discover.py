@@ -0,0 +1,64 @@
+"""Print the feeds a website links to.
+
+Run with:
+    uv run -m src.rss_reader.discover [--websub] URL [URL ...]
+
+With --websub each feed is fetched, and the WebSub hubs it announces and the
+URL it names as its own are printed below it.
+"""
+
+import argparse
+import sys
+
+import requests
+
+from .core.discovery import discover
+from .core.parser import RSSParser
+
+
+def parse_args():
+    """Parse command line arguments."""
+    parser = argparse.ArgumentParser(description="Find the RSS, Atom and JSON feeds of websites")
+    parser.add_argument("urls", metavar="URL", nargs="+", help="Page to look at")
+    parser.add_argument(
+        "--websub", action="store_true", help="Fetch the feeds and print their WebSub hubs"
+    )
+    return parser.parse_args()
+
+
+def print_websub(parser: RSSParser, url: str) -> bool:
+    """Print the WebSub hubs and self URL of a feed; False if it can't be read."""
+    try:
+        feed = parser.parse_url(url)
+    except ValueError as e:
+        print(f"{url}: {e}", file=sys.stderr)
+        return False
+    for hub in feed.hubs:
+        print(f"\thub\t{hub}")
+    if feed.self_url:
+        print(f"\tself\t{feed.self_url}")
+    return True
+
+
+def main() -> int:
+    args = parse_args()
+    parser = RSSParser() if args.websub else None
+    failed = False
+    for url in args.urls:
+        try:
+            links = discover(url)
+        except requests.RequestException as e:
+            print(f"{url}: {e}", file=sys.stderr)
+            failed = True
+            continue
+        if not links:
+            print(f"{url}: no feeds found", file=sys.stderr)
+        for link in links:
+            print("\t".join([link.url, link.format, link.title or ""]).rstrip())
+            if parser and not print_websub(parser, link.url):
+                failed = True
+    return 1 if failed else 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
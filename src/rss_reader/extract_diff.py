@@ -0,0 +1,104 @@
+"""Diff the fields extracted from stored posts between two code versions.
+
+Save a baseline with the deployed code, then compare the code to deploy:
+    uv run -m src.rss_reader.extract_diff --baseline DIR --save [--days 30]
+    uv run -m src.rss_reader.extract_diff --baseline DIR
+
+The comparison runs the current extractors over the baseline's posts again
+and summarizes the changed fields; it exits with 1 if any field changed.
+"""
+
+import argparse
+import asyncio
+import sys
+from datetime import timedelta
+from pathlib import Path
+from typing import Any, Dict, List, Optional
+
+from common import clock
+from common.db.repository import RSSPostRepository
+from common.db.session import db
+
+from .core.extraction import (
+    ExtractionDiff,
+    diff_extractions,
+    extract_fields,
+    load_baseline,
+    save_baseline,
+)
+
+
+def parse_args():
+    """Parse command line arguments."""
+    parser = argparse.ArgumentParser(description="Diff extraction output against a baseline")
+    parser.add_argument("--baseline", type=Path, required=True, help="Baseline directory")
+    parser.add_argument(
+        "--save", action="store_true", help="Save a baseline with this code instead of diffing"
+    )
+    parser.add_argument(
+        "--days", type=int, default=30, help="Save the posts published in these last days"
+    )
+    parser.add_argument("--limit", type=int, default=5000, help="Posts to save at most")
+    parser.add_argument(
+        "--examples", type=int, default=3, help="Changed posts to show for each field"
+    )
+    return parser.parse_args()
+
+
+async def save(directory: Path, days: int, limit: int) -> int:
+    """Save the current extraction of recent posts as a baseline."""
+    now = clock.now()
+    posts = await RSSPostRepository.get_by_date_range(
+        now - timedelta(days=days), now, limit=limit, only_unpublished=False
+    )
+    extractions = {post.link: extract_fields(post.content, post.pub_date) for post in posts}
+    path = save_baseline(directory, extractions)
+    print(f"Saved the extraction of {len(posts)} posts to {path}")
+    return len(posts)
+
+
+async def current_extractions(links: List[str]) -> Dict[str, Optional[Dict[str, Any]]]:
+    """Extract the stored posts again with the current code, None for deleted ones."""
+    current = {}
+    for link in links:
+        post = await RSSPostRepository.get_by_link(link)
+        current[link] = extract_fields(post.content, post.pub_date) if post else None
+    return current
+
+
+def format_diff(diff: ExtractionDiff, examples: int = 3) -> List[str]:
+    """Summary of a diff, one line per field and one per example."""
+    lines = [f"{diff.changed_posts} of {diff.compared} posts changed"]
+    if diff.missing:
+        lines.append(f"{len(diff.missing)} baseline posts are no longer stored")
+    for name, changes in sorted(diff.changes.items(), key=lambda entry: -len(entry[1])):
+        lines.append(f"{name}: {len(changes)} changed")
+        for link, before, after in changes[:examples]:
+            lines.append(f"  {link}: {before!r} -> {after!r}")
+    return lines
+
+
+async def main() -> int:
+    args = parse_args()
+    try:
+        if not db.pool:
+            await db.connect()
+        if args.save:
+            await save(args.baseline, args.days, args.limit)
+            return 0
+
+        try:
+            baseline = load_baseline(args.baseline)
+        except FileNotFoundError:
+            print(f"No baseline in {args.baseline}, save one with --save", file=sys.stderr)
+            return 1
+        diff = diff_extractions(baseline, await current_extractions(list(baseline)))
+        for line in format_diff(diff, args.examples):
+            print(line)
+        return 1 if diff.changes else 0
+    finally:
+        await db.disconnect()
+
+
+if __name__ == "__main__":
+    sys.exit(asyncio.run(main()))
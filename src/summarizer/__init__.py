@@ -0,0 +1 @@
+"""Summarizer Service - Writes a title and a one-sentence summary for each event."""
@@ -0,0 +1,194 @@
+"""Entry point for Summarizer service.
+
+Run with: python -m src.summarizer
+"""
+
+import asyncio
+import logging
+from datetime import timedelta
+from typing import Dict, List, Optional
+
+from openai import AsyncOpenAI
+
+from common import clock
+from common.db.session import db
+from common.db.repository import RSSPostRepository
+from common.db.models import RSSPost
+from common.features import feature_flags
+from common.quotas import tracking_http_client
+from common.translations import (
+    LibreTranslateTranslator,
+    OpenAITranslator,
+    Translator,
+    parse_languages,
+    translate_cached,
+)
+from common.utils.links import channel_from_link
+from .config import summarizer_settings
+from .llm_batch import load_budgets, summarize_with_llm
+from .summarize import LLMSummarizer, rule_summary
+from .titles import generate_title
+
+logging.basicConfig(
+    level=logging.INFO, format="%(asctime)s - %(name)s - %(levelname)s - %(message)s"
+)
+logger = logging.getLogger(__name__)
+
+
+async def summarize_posts(
+    posts: List[RSSPost], llm: Optional[LLMSummarizer]
+) -> Dict[str, str]:
+    """
+    Summarize posts with the backend enabled for their channel.
+
+    Posts of channels with the llm_summary flag are summarized by the LLM in
+    batches within the daily token budgets, the rest and any the LLM can't
+    summarize by the rules.
+
+    Args:
+        posts: Posts to summarize
+        llm: LLM summarizer, or None if no LLM is configured
+
+    Returns:
+        Link -> one-sentence summary
+    """
+    settings = summarizer_settings
+    summaries = {}
+    llm_posts = [
+        post
+        for post in posts
+        if llm and feature_flags.is_enabled("llm_summary", channel_from_link(post.link))
+    ]
+    if llm_posts:
+        budgets = await load_budgets(settings.daily_tokens, settings.tenant_tokens)
+        summaries = await summarize_with_llm(
+            llm, llm_posts, budgets, settings.max_length, settings.llm_batch_size
+        )
+    for post in posts:
+        if post.link not in summaries:
+            summaries[post.link] = rule_summary(post.content, settings.max_length)
+    return summaries
+
+
+def create_llm_client() -> Optional[AsyncOpenAI]:
+    """
+    Create the client of the configured LLM.
+
+    Returns:
+        Client of the LLM_BASE_URL server if set, else of OpenAI if OPENAI_API_KEY
+        is set, else None
+    """
+    settings = summarizer_settings
+    if settings.llm_base_url:
+        # Local servers accept any key, but the SDK insists on one
+        return AsyncOpenAI(
+            api_key=settings.openai_api_key or "local",
+            base_url=settings.llm_base_url,
+            timeout=settings.llm_timeout,
+            http_client=tracking_http_client("local-llm"),
+        )
+    if settings.openai_api_key:
+        return AsyncOpenAI(
+            api_key=settings.openai_api_key, http_client=tracking_http_client("openai")
+        )
+    return None
+
+
+def create_translator() -> Optional[Translator]:
+    """Create the configured translation provider, or None if translation is disabled."""
+    settings = summarizer_settings
+    if settings.translation_provider == "openai":
+        return OpenAITranslator(create_llm_client(), settings.translation_model)
+    if settings.translation_provider == "libretranslate":
+        return LibreTranslateTranslator(
+            settings.libretranslate_url, settings.libretranslate_api_key
+        )
+    return None
+
+
+async def translate_recent(translator: Translator) -> int:
+    """
+    Translate titles and summaries of recent posts into the configured languages.
+
+    Returns:
+        Number of newly translated texts
+    """
+    end_date = clock.now()
+    start_date = end_date - timedelta(days=summarizer_settings.translation_days_back)
+    posts = await RSSPostRepository.get_by_date_range(
+        start_date, end_date, limit=summarizer_settings.batch_size, only_unpublished=False
+    )
+    texts = [text for post in posts for text in (post.title, post.summary) if text]
+
+    translated = 0
+    for language in parse_languages(summarizer_settings.translation_languages):
+        translated += await translate_cached(translator, texts, language)
+    return translated
+
+
+async def main(links: Optional[List[str]] = None):
+    """
+    Main entry point for Summarizer service.
+
+    Args:
+        links: Summarize just these posts and skip translations (the priority lane)
+    """
+    logger.info("Starting Summarizer service...")
+
+    try:
+        summarizer_settings.validate()
+
+        if not db.pool:
+            await db.connect()
+            logger.info("Connected to database")
+
+        llm = None
+        client = create_llm_client()
+        if client:
+            llm = LLMSummarizer(
+                client, summarizer_settings.openai_model, summarizer_settings.openai_temperature
+            )
+
+        if links is None:
+            posts = await RSSPostRepository.get_without_summary(summarizer_settings.batch_size)
+        else:
+            posts = []
+            for link in links:
+                post = await RSSPostRepository.get_by_link(link)
+                if post and (post.summary is None or post.title is None):
+                    posts.append(post)
+        logger.info(f"Found {len(posts)} posts without a summary or title")
+
+        summaries = await summarize_posts([post for post in posts if post.summary is None], llm)
+        summarized = 0
+        for post in posts:
+            # Empty values are stored too, so posts without text aren't retried every run
+            summary = post.summary
+            if summary is None:
+                summary = summaries[post.link]
+            title = post.title
+            if title is None:
+                title = generate_title(post.content, summarizer_settings.title_max_length)
+            await RSSPostRepository.update_summary(post.link, summary, title)
+            summarized += 1
+
+        print(f"✓ Summarized {summarized} posts")
+
+        translated = 0
+        translator = create_translator() if links is None else None
+        if translator:
+            translated = await translate_recent(translator)
+            print(f"✓ Translated {translated} titles and summaries")
+
+        logger.info("Summarizer service completed successfully")
+
+        return {"summarized_count": summarized, "translated_count": translated}
+
+    except Exception as e:
+        logger.error(f"Error: {e}", exc_info=True)
+        print(f"Error: {e}")
+        raise
+
+
+if __name__ == "__main__":
+    asyncio.run(main())
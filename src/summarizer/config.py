@@ -0,0 +1,94 @@
+"""Configuration for Summarizer service."""
+
+import os
+from dataclasses import dataclass
+from pathlib import Path
+from dotenv import load_dotenv
+
+# Load .env file if it exists
+env_path = Path(__file__).parent.parent.parent / ".env"
+if env_path.exists():
+    load_dotenv(env_path)
+
+
+@dataclass
+class SummarizerConfig:
+    """Summarizer configuration settings."""
+
+    # Summary settings
+    max_length: int = int(os.getenv("SUMMARIZER_MAX_LENGTH", "160"))
+    title_max_length: int = int(os.getenv("SUMMARIZER_TITLE_MAX_LENGTH", "80"))
+    batch_size: int = int(os.getenv("SUMMARIZER_BATCH_SIZE", "200"))
+
+    # Optional LLM backend, enabled per channel with the llm_summary feature flag
+    openai_api_key: str = os.getenv("OPENAI_API_KEY", "")
+    openai_model: str = os.getenv("SUMMARIZER_MODEL", "gpt-4o-mini")
+    openai_temperature: float = float(os.getenv("SUMMARIZER_TEMPERATURE", "0.2"))
+    # OpenAI-compatible server to use instead of OpenAI, like a local ollama
+    # (http://localhost:11434/v1) or llama.cpp server (http://localhost:8080/v1)
+    llm_base_url: str = os.getenv("LLM_BASE_URL", "")
+    # Local models on CPUs take their time
+    llm_timeout: float = float(os.getenv("LLM_TIMEOUT_SECONDS", "120"))
+    # Posts per LLM request, and tokens per tenant (channel) and day; 0 means no limit
+    llm_batch_size: int = int(os.getenv("SUMMARIZER_LLM_BATCH_SIZE", "10"))
+    daily_tokens: int = int(os.getenv("SUMMARIZER_DAILY_TOKENS", "0"))
+    # Budgets of single tenants, like "mediarzn=50000,afisha=200000"
+    tenant_tokens: str = os.getenv("SUMMARIZER_TENANT_TOKENS", "")
+
+    # Optional translation of titles and summaries: openai, libretranslate or empty
+    translation_provider: str = os.getenv("TRANSLATION_PROVIDER", "")
+    translation_languages: str = os.getenv("TRANSLATION_LANGUAGES", "en")
+    translation_model: str = os.getenv("TRANSLATION_MODEL", "gpt-4o-mini")
+    translation_days_back: int = int(os.getenv("TRANSLATION_DAYS_BACK", "14"))
+    libretranslate_url: str = os.getenv("LIBRETRANSLATE_URL", "")
+    libretranslate_api_key: str = os.getenv("LIBRETRANSLATE_API_KEY", "")
+
+    def validate(self) -> bool:
+        """
+        Validate configuration.
+
+        Returns:
+            True if configuration is valid
+
+        Raises:
+            ValueError: If configuration is invalid
+        """
+        if self.max_length < 40:
+            raise ValueError("SUMMARIZER_MAX_LENGTH must be at least 40")
+
+        if not 20 <= self.title_max_length <= 200:
+            raise ValueError("SUMMARIZER_TITLE_MAX_LENGTH must be between 20 and 200")
+
+        if self.batch_size < 1:
+            raise ValueError("SUMMARIZER_BATCH_SIZE must be at least 1")
+
+        if self.llm_batch_size < 1:
+            raise ValueError("SUMMARIZER_LLM_BATCH_SIZE must be at least 1")
+
+        if self.daily_tokens < 0:
+            raise ValueError("SUMMARIZER_DAILY_TOKENS must not be negative")
+
+        if self.translation_provider not in ("", "openai", "libretranslate"):
+            raise ValueError(f"Unknown TRANSLATION_PROVIDER: {self.translation_provider}")
+
+        if self.translation_provider == "openai" and not self.has_llm():
+            raise ValueError(
+                "OPENAI_API_KEY or LLM_BASE_URL is required for TRANSLATION_PROVIDER=openai"
+            )
+
+        if self.llm_base_url and not self.llm_base_url.startswith(("http://", "https://")):
+            raise ValueError(f"LLM_BASE_URL must be an HTTP URL: {self.llm_base_url}")
+
+        if self.translation_provider == "libretranslate" and not self.libretranslate_url:
+            raise ValueError(
+                "LIBRETRANSLATE_URL is required for TRANSLATION_PROVIDER=libretranslate"
+            )
+
+        return True
+
+    def has_llm(self) -> bool:
+        """Whether an LLM is configured, OpenAI or a local server."""
+        return bool(self.openai_api_key or self.llm_base_url)
+
+
+summarizer_settings = SummarizerConfig()
@@ -0,0 +1,140 @@
+"""Batched LLM summaries with a result cache and daily token budgets.
+
+Posts of channels with the llm_summary feature flag are sent to the model
+SUMMARIZER_LLM_BATCH_SIZE at a time instead of one request each. Results
+are cached in llm_cache by a hash of the post text, so reposts, identical
+announcements in several channels and reruns cost nothing.
+
+Each request only holds posts of one tenant, the channel the posts come from
+(the scope of the llm_summary flag), and its tokens count against the
+tenant's budget for the day: SUMMARIZER_DAILY_TOKENS, or the tenant's own
+one from SUMMARIZER_TENANT_TOKENS ("mediarzn=50000,afisha=200000"); 0 means
+no limit. Once a budget is used up, the tenant's posts get rule-based
+summaries until the next day. A batch started just under the budget may go
+over it, by at most one request.
+"""
+
+import logging
+from dataclasses import dataclass, field
+from typing import Dict, List, Optional
+
+from common import clock
+from common.db.models import RSSPost
+from common.db.repository import LLMCacheRepository, LLMUsageRepository
+from common.translations import text_hash
+from common.utils.links import channel_from_link
+from .summarize import InvalidBatchReply, LLMSummarizer
+
+logger = logging.getLogger(__name__)
+
+
+def parse_budgets(spec: str) -> Dict[str, int]:
+    """
+    Parse per-tenant token budgets like 'mediarzn=50000,afisha=200000'.
+
+    Raises:
+        ValueError: If an entry isn't a name with a whole number of tokens
+    """
+    budgets = {}
+    for entry in spec.split(","):
+        if not entry.strip():
+            continue
+        tenant, _, tokens = entry.partition("=")
+        if not tenant.strip() or not tokens.strip().isdigit():
+            raise ValueError(f"Invalid token budget entry: {entry.strip()}")
+        budgets[tenant.strip()] = int(tokens)
+    return budgets
+
+
+@dataclass
+class TokenBudgets:
+    """Daily token budgets of the tenants and what they spent today."""
+
+    # Tokens per tenant and day, 0 for no limit
+    default: int = 0
+    tenants: Dict[str, int] = field(default_factory=dict)
+    used: Dict[str, int] = field(default_factory=dict)
+
+    def remaining(self, tenant: str) -> Optional[int]:
+        """Tokens a tenant has left today, None without a limit."""
+        limit = self.tenants.get(tenant, self.default)
+        if not limit:
+            return None
+        return max(limit - self.used.get(tenant, 0), 0)
+
+    def spend(self, tenant: str, tokens: int) -> None:
+        self.used[tenant] = self.used.get(tenant, 0) + tokens
+
+
+async def load_budgets(default: int, spec: str = "") -> TokenBudgets:
+    """Budgets with the tokens spent so far today."""
+    used = await LLMUsageRepository.get_day(clock.now().date())
+    return TokenBudgets(default=default, tenants=parse_budgets(spec), used=used)
+
+
+def tenant_of(post: RSSPost) -> str:
+    return channel_from_link(post.link)
+
+
+async def summarize_with_llm(
+    llm: LLMSummarizer,
+    posts: List[RSSPost],
+    budgets: TokenBudgets,
+    max_length: int = 160,
+    batch_size: int = 10,
+) -> Dict[str, str]:
+    """
+    Summarize posts with the LLM as far as the cache and the budgets allow.
+
+    Args:
+        llm: LLM summarizer
+        posts: Posts to summarize
+        budgets: Token budgets; the spent tokens are added to them and stored
+        max_length: Maximum summary length
+        batch_size: Posts per request
+
+    Returns:
+        Link -> summary for the posts the model summarized now or before;
+        the rest are left to the rule-based summarizer
+    """
+    purpose = f"summary:{max_length}"
+    hashes = {post.link: text_hash(post.content) for post in posts}
+    results = await LLMCacheRepository.get_many(list(set(hashes.values())), purpose)
+
+    # Tenant -> content hash -> text, one text per hash
+    pending: Dict[str, Dict[str, str]] = {}
+    for post in posts:
+        if hashes[post.link] not in results:
+            pending.setdefault(tenant_of(post), {})[hashes[post.link]] = post.content
+
+    today = clock.now().date()
+    for tenant, texts in pending.items():
+        batches = list(texts.items())
+        for start in range(0, len(batches), batch_size):
+            if budgets.remaining(tenant) == 0:
+                left = len(batches) - start
+                logger.warning(
+                    f"LLM token budget of {tenant or 'unknown'} used up, "
+                    f"{left} posts get rule-based summaries"
+                )
+                break
+            batch = batches[start : start + batch_size]
+            try:
+                summaries, tokens = await llm.summarize_batch(
+                    [text for _, text in batch], max_length
+                )
+            except InvalidBatchReply as e:
+                summaries, tokens = [], e.tokens
+                logger.warning(f"LLM summaries of {len(batch)} posts failed: {e}")
+            except Exception as e:
+                logger.warning(f"LLM summaries of {len(batch)} posts failed: {e}")
+                continue
+
+            budgets.spend(tenant, tokens)
+            await LLMUsageRepository.add(tenant, today, tokens)
+            for (content_hash, _), summary in zip(batch, summaries):
+                if summary:
+                    results[content_hash] = summary
+                    await LLMCacheRepository.save(content_hash, purpose, summary, llm.model)
+
+    return {link: results[digest] for link, digest in hashes.items() if digest in results}
@@ -0,0 +1,195 @@
+"""Summary generation.
+
+The rule-based summarizer needs no external services: it strips links,
+hashtags and decoration from the post and keeps its first informative
+sentence. Channels with the llm_summary feature flag are summarized by an
+OpenAI model instead, falling back to the rule-based summary on errors.
+"""
+
+import json
+import logging
+import re
+from typing import List, Tuple
+
+from openai import AsyncOpenAI
+
+logger = logging.getLogger(__name__)
+
+URL_REGEX = re.compile(r"https?://\S+|www\.\S+")
+HASHTAG_REGEX = re.compile(r"(?<!\w)#\w+")
+# Leading emoji, bullets and other decoration before the first letter, digit or quote
+LEADING_DECORATION_REGEX = re.compile(r"^[^\w«\"'(]+")
+SENTENCE_END_REGEX = re.compile(r"(?<=[.!?…])\s+")
+
+# Sentences shorter than this are joined with the next one
+MIN_SENTENCE_LENGTH = 40
+
+SYSTEM_PROMPT = (
+    "You summarize event announcements from Telegram channels. Reply with exactly one "
+    "sentence in the language of the post that says what the event is and, if stated, "
+    "when and where it takes place. No emoji, hashtags, links or quotes around the reply."
+)
+
+BATCH_SYSTEM_PROMPT = (
+    "You summarize event announcements from Telegram channels. You get a JSON array of "
+    "posts. Summarize each in exactly one sentence in the language of the post that says "
+    "what the event is and, if stated, when and where it takes place, without emoji, "
+    "hashtags or links. Reply with a JSON array of the summaries in the order of the posts "
+    "and nothing else."
+)
+
+
+class InvalidBatchReply(ValueError):
+    """Raised when the model's reply to a batch has no usable summaries."""
+
+    def __init__(self, message: str, tokens: int = 0):
+        super().__init__(message)
+        # Spent on the request all the same
+        self.tokens = tokens
+
+
+def sentences(content: str) -> List[str]:
+    """
+    Split post content into cleaned sentences.
+
+    Line breaks are treated as sentence boundaries, because Telegram posts
+    often put the title and each detail on its own line.
+
+    Args:
+        content: Post text
+
+    Returns:
+        Non-empty sentences without links, hashtags or leading decoration
+    """
+    result = []
+    for line in (content or "").splitlines():
+        line = HASHTAG_REGEX.sub("", URL_REGEX.sub("", line))
+        for sentence in SENTENCE_END_REGEX.split(line):
+            sentence = LEADING_DECORATION_REGEX.sub("", " ".join(sentence.split()))
+            if re.search(r"\w", sentence):
+                result.append(sentence)
+    return result
+
+
+def shorten(text: str, max_length: int) -> str:
+    """Cut text to max_length on a word boundary, ending it with '…'."""
+    if len(text) <= max_length:
+        return text
+    cut = text.rfind(" ", 0, max_length - 1)
+    return text[: cut if cut > max_length // 2 else max_length - 1].rstrip(" ,.;:—-") + "…"
+
+
+def rule_summary(content: str, max_length: int = 160) -> str:
+    """
+    Summarize a post as its first informative sentence.
+
+    Args:
+        content: Post text
+        max_length: Maximum summary length
+
+    Returns:
+        One-sentence summary, or an empty string for posts without text
+    """
+    parts = sentences(content)
+    if not parts:
+        return ""
+
+    summary = parts[0]
+    for part in parts[1:]:
+        if len(summary) >= MIN_SENTENCE_LENGTH:
+            break
+        # Titles rarely end with punctuation; join them to the next detail with a dash
+        separator = " " if summary[-1] in ".!?…:" else " — "
+        summary = f"{summary}{separator}{part}"
+
+    summary = shorten(summary, max_length)
+    if summary[-1] not in ".!?…":
+        summary += "."
+    return summary
+
+
+class LLMSummarizer:
+    """Summarize posts with an OpenAI chat model."""
+
+    def __init__(self, client: AsyncOpenAI, model: str, temperature: float = 0.2):
+        self.client = client
+        self.model = model
+        self.temperature = temperature
+
+    async def summarize(self, content: str, max_length: int = 160) -> str:
+        """
+        Summarize a post, falling back to the rule-based summary on errors.
+
+        Args:
+            content: Post text
+            max_length: Maximum summary length
+
+        Returns:
+            One-sentence summary
+        """
+        try:
+            response = await self.client.chat.completions.create(
+                model=self.model,
+                messages=[
+                    {"role": "system", "content": SYSTEM_PROMPT},
+                    {
+                        "role": "user",
+                        "content": f"At most {max_length} characters.\n\n{content[:3000]}",
+                    },
+                ],
+                max_tokens=max_length,
+                temperature=self.temperature,
+            )
+            text = (response.choices[0].message.content or "").strip()
+        except Exception as e:
+            logger.warning(f"LLM summary failed, using rule-based summary: {e}")
+            return rule_summary(content, max_length)
+
+        lines = [line for line in text.splitlines() if line.strip()]
+        if not lines:
+            return rule_summary(content, max_length)
+        return shorten(lines[0].strip().strip("\"«»"), max_length)
+
+    async def summarize_batch(
+        self, contents: List[str], max_length: int = 160
+    ) -> Tuple[List[str], int]:
+        """
+        Summarize several posts with one request.
+
+        Args:
+            contents: Post texts
+            max_length: Maximum length of each summary
+
+        Returns:
+            Summaries in the order of the posts (empty for posts the model had
+            nothing to say about) and the tokens the request used
+
+        Raises:
+            InvalidBatchReply: If the reply isn't a JSON array of one summary per post
+        """
+        response = await self.client.chat.completions.create(
+            model=self.model,
+            messages=[
+                {"role": "system", "content": BATCH_SYSTEM_PROMPT},
+                {
+                    "role": "user",
+                    "content": f"At most {max_length} characters per summary.\n\n"
+                    + json.dumps([content[:3000] for content in contents], ensure_ascii=False),
+                },
+            ],
+            max_tokens=max_length * len(contents),
+            temperature=self.temperature,
+        )
+        usage = getattr(response, "usage", None)
+        tokens = getattr(usage, "total_tokens", 0) or 0
+        text = (response.choices[0].message.content or "").strip()
+        # Models sometimes wrap the reply in a Markdown code block
+        text = text.removeprefix("```json").removeprefix("```").removesuffix("```")
+        try:
+            summaries = json.loads(text)
+        except ValueError:
+            raise InvalidBatchReply(f"Invalid JSON from {self.model}: {text[:100]}", tokens)
+        if not isinstance(summaries, list) or len(summaries) != len(contents):
+            raise InvalidBatchReply(f"Expected {len(contents)} summaries from {self.model}", tokens)
+        summaries = [str(summary).strip().strip("\"«»") for summary in summaries]
+        return [shorten(summary, max_length) for summary in summaries], tokens
@@ -0,0 +1,55 @@
+"""Title generation for posts without one.
+
+Telegram posts rarely have a separate title. The title is taken from the
+first sentence of the post, cut at a clause boundary when it is too long;
+posts without any usable sentence fall back to their hashtags as keywords.
+"""
+
+import re
+
+from .summarize import HASHTAG_REGEX, sentences, shorten
+
+# Clause boundaries to cut long first sentences at, in order of preference
+CLAUSE_SEPARATORS = (" — ", " – ", ": ", " (", ", ")
+
+# Shorter cuts lose too much meaning; truncate the sentence instead
+MIN_CLAUSE_LENGTH = 20
+
+
+def keywords_title(content: str) -> str:
+    """Build a title from the post's hashtags, e.g. '#концерт #джаз' -> 'Концерт, джаз'."""
+    words = []
+    for tag in HASHTAG_REGEX.findall(content or ""):
+        word = tag[1:].replace("_", " ")
+        if word.lower() not in (w.lower() for w in words):
+            words.append(word)
+    return ", ".join(words[:3])
+
+
+def generate_title(content: str, max_length: int = 80) -> str:
+    """
+    Generate a concise display title for a post.
+
+    Args:
+        content: Post text
+        max_length: Maximum title length
+
+    Returns:
+        Title, or an empty string if the post has no usable text
+    """
+    # Skip fragments like "Подробнее:" that can't stand on their own
+    parts = [part for part in sentences(content) if len(re.findall(r"\w+", part)) >= 2]
+    title = parts[0] if parts else keywords_title(content)
+    if not title:
+        return ""
+
+    title = title.rstrip(" .,;:")
+    if len(title) > max_length:
+        for separator in CLAUSE_SEPARATORS:
+            cut = title.find(separator, MIN_CLAUSE_LENGTH, max_length)
+            if cut != -1:
+                title = title[:cut]
+                break
+        title = shorten(title, max_length)
+
+    return title[0].upper() + title[1:]
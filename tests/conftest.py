@@ -1,4 +1,10 @@
 import os
+from dataclasses import fields
+from datetime import datetime
+
+import pytest
+
+from common.clock import FakeClock, set_clock
 
 # Use the main database for tests
 os.environ["DATABASE_DSN"] = os.getenv(
@@ -8,3 +14,35 @@ os.environ["DATABASE_DSN"] = os.getenv(
 
 # Configure pytest-asyncio to use function scope by default
 pytest_plugins = ("pytest_asyncio",)
+
+
+@pytest.fixture
+def clock_start():
+    """Time fake_clock starts at; a module overrides this fixture for another one."""
+    return datetime(2026, 3, 1, 12, 0)
+
+
+@pytest.fixture
+def fake_clock(clock_start):
+    """Make the shared clock a FakeClock for one test."""
+    fake = FakeClock(clock_start)
+    previous = set_clock(fake)
+    yield fake
+    set_clock(previous)
+
+
+@pytest.fixture
+def memory_storage(monkeypatch):
+    """Point the repositories at an empty in-memory store for one test."""
+    from common.db import memory
+    from common.db.memory import MEMORY
+    from common.db.storage import POSTGRES
+
+    memory.reset()
+    for repository in fields(POSTGRES):
+        if repository.name == "name":
+            continue
+        target, source = getattr(POSTGRES, repository.name), getattr(MEMORY, repository.name)
+        for method, value in vars(source).items():
+            if isinstance(value, staticmethod) and not method.startswith("_"):
+                monkeypatch.setattr(target, method, value)
@@ -0,0 +1,712 @@
+"""Conformance checks for storage backends.
+
+Every backend must behave like the Postgres repositories, so features can be
+developed against any of them. A backend passes when
+
+    await run_repository_tests(storage)
+
+succeeds on an empty store. The checks use their own links and keys, so they
+can share one store.
+"""
+
+from datetime import date, datetime, timedelta
+from decimal import Decimal
+
+from common.db.models import (
+    DigestDraft,
+    FieldOverride,
+    PollRunItem,
+    PostRevision,
+    Promotion,
+    PublishFailure,
+    Quota,
+    RSSPost,
+    Series,
+    Submission,
+    TelegramChannel,
+)
+from common.db.storage import Storage
+from common.ids import new_id
+
+
+def post(number: int, **fields) -> RSSPost:
+    fields.setdefault("pub_date", datetime(2026, 3, 1, 12, 0) + timedelta(hours=number))
+    return RSSPost(link=f"https://t.me/conformance/{number}", content=f"Post {number}", **fields)
+
+
+async def check_channels(storage: Storage) -> None:
+    channels = storage.channels
+    channel_id = await channels.create(TelegramChannel(channel_id=101, channel_name="zeta"))
+    assert channel_id == 101
+    await channels.create(
+        TelegramChannel(channel_id=102, channel_name="alpha", url="https://t.me/a")
+    )
+
+    assert [c.channel_name for c in await channels.get_all()] == ["alpha", "zeta"]
+    assert (await channels.get_by_name("alpha")).url == "https://t.me/a"
+    assert await channels.get_by_name("missing") is None
+
+    await channels.update(
+        TelegramChannel(channel_id=101, channel_name="zeta", description="Z", category="Музыка")
+    )
+    assert (await channels.get_by_id(101)).description == "Z"
+    await channels.set_title(101, "Зета")
+    updated = await channels.get_by_id(101)
+    assert (updated.title, updated.category) == ("Зета", "Музыка")
+
+    created_at = datetime(2025, 1, 1, 10, 0)
+    await channels.upsert(
+        TelegramChannel(channel_id=103, channel_name="beta", created_at=created_at)
+    )
+    await channels.upsert(TelegramChannel(channel_id=103, channel_name="gamma"))
+    upserted = await channels.get_by_id(103)
+    assert upserted.channel_name == "gamma"
+    assert upserted.created_at == created_at
+
+    for channel_id in (101, 102, 103):
+        await channels.delete(channel_id)
+    assert await channels.get_all() == []
+
+
+async def check_post_fields(storage: Storage) -> None:
+    posts = storage.posts
+    created = post(
+        1,
+        media='["https://cdn.example.com/1.jpg"]',
+        tags=["music", "jazz"],
+        price_min=Decimal("500.00"),
+        price_max=Decimal("1500.00"),
+        price_currency="RUB",
+        registration_required=True,
+        tickets_required=True,
+        limited_capacity=True,
+        event_format="offline",
+        event_start=date(2026, 3, 5),
+        event_end=date(2026, 3, 7),
+        event_sessions='[{"date": "2026-03-05", "time": "19:00"}]',
+        event_status="postponed",
+        categories=["Концерты", "Jazz"],
+        performers=["Анна Смирнова", "Иван Петров"],
+        organizations=["Гараж"],
+        contacts=["+79101234567", "info@club.ru"],
+        deadline=date(2026, 3, 1),
+        deadline_kind="applications",
+        source_guid="conformance:urn:post:1",
+    )
+    assert await posts.create(created) == created.link
+    assert await posts.exists_by_link(created.link)
+    assert not await posts.exists_by_link("https://t.me/conformance/missing")
+    assert await posts.get_by_link("https://t.me/conformance/missing") is None
+
+    stored = await posts.get_by_link(created.link)
+    for name in (
+        "content",
+        "pub_date",
+        "media",
+        "tags",
+        "price_min",
+        "price_max",
+        "price_currency",
+        "registration_required",
+        "tickets_required",
+        "limited_capacity",
+        "event_format",
+        "event_start",
+        "event_end",
+        "event_sessions",
+        "event_status",
+        "categories",
+        "performers",
+        "organizations",
+        "contacts",
+        "deadline",
+        "deadline_kind",
+        "source_guid",
+    ):
+        assert getattr(stored, name) == getattr(created, name), name
+    assert (await posts.get_by_source_guid("conformance:urn:post:1")).link == created.link
+    assert await posts.get_by_source_guid("conformance:urn:post:2") is None
+    assert stored.is_published is False
+    assert stored.created_at is not None
+    assert stored.updated_at is not None
+
+    await posts.delete(created.link)
+    assert await posts.get_by_link(created.link) is None
+
+
+async def check_post_upsert(storage: Storage) -> None:
+    posts = storage.posts
+    created_at = datetime(2026, 1, 1, 9, 0)
+    await posts.upsert(post(2, summary="Old", created_at=created_at, updated_at=created_at))
+    await posts.upsert(
+        post(
+            2,
+            summary="New",
+            title="Title",
+            is_published=True,
+            published_at=datetime(2026, 1, 2, 9, 0),
+            created_at=created_at,
+            updated_at=datetime(2026, 1, 2, 9, 0),
+        )
+    )
+    stored = await posts.get_by_link(post(2).link)
+    assert (stored.summary, stored.title, stored.is_published) == ("New", "Title", True)
+    assert stored.created_at == created_at
+    assert stored.updated_at == datetime(2026, 1, 2, 9, 0)
+    await posts.delete(stored.link)
+
+
+async def check_channel_activity(storage: Storage) -> None:
+    posts = storage.posts
+    for number in (40, 41, 42):
+        await posts.create(post(number, pub_date=datetime(2026, 6, number - 30, 12, 0)))
+    await posts.mark_as_published([post(40).link, post(41).link])
+    await posts.update_summary(post(41).link, "Summary", "Title 41")
+
+    activity = await posts.get_channel_activity(datetime(2026, 6, 11, 0, 0))
+    conformance = [row for row in activity if row["channel_name"] == "conformance"]
+    assert conformance == [
+        {
+            "channel_name": "conformance",
+            "count": 2,
+            "last_event_link": post(41).link,
+            "last_event_title": "Title 41",
+            "last_event_date": datetime(2026, 6, 11, 12, 0),
+        }
+    ]
+    for number in (40, 41, 42):
+        await posts.delete(post(number).link)
+
+
+async def check_publishing(storage: Storage) -> None:
+    posts = storage.posts
+    for number in (10, 11, 12):
+        await posts.create(post(number))
+    start, end = datetime(2026, 3, 1), datetime(2026, 3, 2)
+
+    unpublished = await posts.get_by_date_range(start, end)
+    assert [p.link for p in unpublished] == [post(n).link for n in (12, 11, 10)]
+    assert len(await posts.get_by_date_range(start, end, limit=2)) == 2
+    assert await posts.count_unpublished() == 3
+
+    assert await posts.mark_as_published([post(10).link, post(11).link]) == 2
+    assert await posts.count_unpublished() == 1
+    assert [p.link for p in await posts.get_by_date_range(start, end)] == [post(12).link]
+    everything = await posts.get_by_date_range(start, end, only_unpublished=False)
+    assert len(everything) == 3
+
+    published = await posts.get_published_since(start)
+    assert [p.link for p in published] == [post(11).link, post(10).link]
+    assert all(p.published_at is not None for p in published)
+
+    for number in (10, 11, 12):
+        await posts.delete(post(number).link)
+
+
+async def check_embargoes(storage: Storage) -> None:
+    posts = storage.posts
+    for number in (13, 14):
+        await posts.create(post(number, pub_date=datetime(2026, 1, 10, 12, 0)))
+    release = datetime(2026, 3, 5, 12, 0)
+    await posts.set_embargo(post(13).link, release)
+    assert (await posts.get_by_link(post(13).link)).embargo_until == release
+
+    start, end = datetime(2026, 3, 1), datetime(2026, 3, 8)
+    # Selected by the release time, not the publication date
+    assert [p.link for p in await posts.get_released(start, end)] == [post(13).link]
+    assert await posts.get_released(start, datetime(2026, 3, 5, 11, 59)) == []
+    await posts.mark_as_published([post(13).link])
+    assert await posts.get_released(start, end) == []
+    assert len(await posts.get_released(start, end, only_unpublished=False)) == 1
+
+    for number in (13, 14):
+        await posts.delete(post(number).link)
+
+
+async def check_summaries(storage: Storage) -> None:
+    posts = storage.posts
+    await posts.create(post(20))
+    await posts.create(post(21))
+
+    waiting = await posts.get_without_summary()
+    assert [p.link for p in waiting] == [post(21).link, post(20).link]
+
+    await posts.update_summary(post(21).link, "Summary", "Title")
+    stored = await posts.get_by_link(post(21).link)
+    assert (stored.summary, stored.title) == ("Summary", "Title")
+    assert [p.link for p in await posts.get_without_summary()] == [post(20).link]
+
+    await posts.delete(post(20).link)
+    await posts.delete(post(21).link)
+
+
+async def check_event_status(storage: Storage) -> None:
+    posts = storage.posts
+    await posts.create(post(30))
+    assert await posts.set_event_status(post(30).link, "cancelled") is True
+    assert (await posts.get_by_link(post(30).link)).event_status == "cancelled"
+    assert await posts.set_event_status(post(30).link, None) is True
+    assert (await posts.get_by_link(post(30).link)).event_status is None
+    assert await posts.set_event_status("https://t.me/conformance/missing", "cancelled") is False
+    await posts.delete(post(30).link)
+
+
+async def check_updated_since(storage: Storage) -> None:
+    posts = storage.posts
+    moment = datetime(2026, 2, 1, 12, 0)
+    for number in (42, 41, 40):
+        await posts.upsert(post(number, created_at=moment, updated_at=moment))
+    await posts.upsert(
+        post(43, created_at=moment, updated_at=moment + timedelta(minutes=1))
+    )
+
+    first = await posts.get_updated_since(None, limit=2)
+    assert [p.link for p in first] == [post(40).link, post(41).link]
+    rest = await posts.get_updated_since(first[-1].updated_at, first[-1].link)
+    assert [p.link for p in rest] == [post(42).link, post(43).link]
+    assert await posts.get_updated_since(rest[-1].updated_at, rest[-1].link) == []
+
+    for number in (40, 41, 42, 43):
+        await posts.delete(post(number).link)
+
+
+async def check_deliveries(storage: Storage) -> None:
+    await storage.posts.create(post(50))
+    await storage.posts.create(post(51))
+    target = "mastodon:@events@example.social"
+
+    await storage.deliveries.record(post(50).link, target, "1")
+    # Recording again only refreshes the delivery
+    await storage.deliveries.record(post(50).link, target, "2")
+    links = [post(50).link, post(51).link]
+    assert await storage.deliveries.get_delivered_links(target, links) == {post(50).link}
+    assert await storage.deliveries.get_delivered_links("other", links) == set()
+
+    await storage.deliveries.record(post(51).link, target)
+    assert await storage.deliveries.get_recent(target, datetime(2000, 1, 1)) == [
+        (post(50).link, "2")
+    ]
+    assert await storage.deliveries.get_recent(target, datetime(2100, 1, 1)) == []
+    await storage.deliveries.set_shares(post(50).link, target, 4)
+
+    await storage.posts.delete(post(50).link)
+    await storage.posts.delete(post(51).link)
+
+
+async def check_translations(storage: Storage) -> None:
+    translations = storage.translations
+    await translations.save("hash-1", "en", "Concert", "test")
+    await translations.save("hash-1", "en", "Jazz concert", "test")
+    await translations.save("hash-1", "de", "Konzert", "test")
+
+    cached = await translations.get_many(["hash-1", "hash-2"], "en")
+    assert cached == {"hash-1": "Jazz concert"}
+    assert await translations.get_many(["hash-2"], "en") == {}
+
+
+async def check_llm_cache(storage: Storage) -> None:
+    cache = storage.llm_cache
+    await cache.save("hash-1", "summary:160", "Concert", "model-a")
+    await cache.save("hash-1", "summary:160", "Jazz concert", "model-b")
+    await cache.save("hash-1", "summary:80", "Jazz", "model-b")
+
+    assert await cache.get_many(["hash-1", "hash-2"], "summary:160") == {"hash-1": "Jazz concert"}
+    assert await cache.get_many(["hash-1"], "summary:40") == {}
+
+    usage = storage.llm_usage
+    await usage.add("conformance", date(2026, 2, 18), 1200)
+    await usage.add("conformance", date(2026, 2, 18), 300)
+    await usage.add("other", date(2026, 2, 18), 50)
+    await usage.add("conformance", date(2026, 2, 19), 10)
+    assert await usage.get_day(date(2026, 2, 18)) == {"conformance": 1500, "other": 50}
+    assert await usage.get_day(date(2026, 2, 20)) == {}
+
+
+async def check_series(storage: Storage) -> None:
+    series = storage.series
+    await series.create(Series(key="#conformancefest", name="Conformancefest"))
+    # Creating an existing series keeps its name
+    await series.create(Series(key="#conformancefest", name="Renamed"))
+    await storage.posts.create(post(60, series_key="#conformancefest"))
+    await storage.posts.create(post(61))
+    await series.add_post(post(61).link, "#conformancefest")
+
+    await series.upsert(Series(key="#other", name="Other"))
+    # Posts already in a series stay there
+    await series.add_post(post(61).link, "#other")
+
+    found = await series.get_many(["#conformancefest", "#missing"])
+    assert list(found) == ["#conformancefest"]
+    assert found["#conformancefest"].name == "Conformancefest"
+    assert found["#conformancefest"].event_count == 2
+
+    await series.upsert(Series(key="#other", name="Other festival"))
+    counts = {s.key: (s.name, s.event_count) for s in await series.get_all()}
+    assert counts["#other"] == ("Other festival", 0)
+
+    await storage.posts.delete(post(60).link)
+    await storage.posts.delete(post(61).link)
+    assert (await series.get_many(["#conformancefest"]))["#conformancefest"].event_count == 0
+
+
+async def check_promotions(storage: Storage) -> None:
+    promotions = storage.promotions
+    await storage.posts.create(post(70))
+    starts_at = datetime(2026, 3, 1)
+    promotion_id = await promotions.create(
+        Promotion(
+            post_link=post(70).link,
+            sponsor="Club",
+            starts_at=starts_at,
+            ends_at=starts_at + timedelta(days=7),
+            max_placements=1,
+            price_per_placement=Decimal("1000.00"),
+        )
+    )
+    assert [p.id for p in await promotions.get_active(starts_at)] == [promotion_id]
+    assert await promotions.get_active(starts_at + timedelta(days=7)) == []
+
+    await promotions.record_placement(promotion_id, "telegram")
+    assert await promotions.get_active(starts_at) == []
+    assert (await promotions.get_all())[0].placements == 1
+
+    await promotions.delete(promotion_id)
+    assert await promotions.get_all() == []
+    await storage.posts.delete(post(70).link)
+
+
+async def check_interactions(storage: Storage) -> None:
+    interactions = storage.interactions
+    await storage.posts.create(post(80, tags=["music"]))
+    for kind in ("view", "view", "click"):
+        await interactions.record(post(80).link, kind)
+
+    today = date.today()
+    totals = await interactions.get_totals(today, today + timedelta(days=1))
+    assert totals == [{"link": post(80).link, "tags": ["music"], "views": 2, "clicks": 1}]
+    assert await interactions.get_totals(today + timedelta(days=1), today + timedelta(days=2)) == []
+    assert await interactions.get_daily(today) == [
+        {"link": post(80).link, "day": today, "kind": "click", "count": 1},
+        {"link": post(80).link, "day": today, "kind": "view", "count": 2},
+    ]
+    assert await interactions.get_daily(today + timedelta(days=1)) == []
+
+    target = "mastodon:@events@example.social"
+    await storage.deliveries.record(post(80).link, target, "80")
+    await storage.deliveries.set_shares(post(80).link, target, 5)
+    await storage.posts.create(post(81))
+    await storage.deliveries.record(post(81).link, target, "81")
+    assert await interactions.get_reach(datetime(2026, 1, 1)) == [
+        {"link": post(80).link, "posts": 1, "shares": 5, "views": 2, "clicks": 1, "reach": 7},
+        {"link": post(81).link, "posts": 1, "shares": 0, "views": 0, "clicks": 0, "reach": 0},
+    ]
+    assert len(await interactions.get_reach(datetime(2026, 1, 1), limit=1)) == 1
+    assert await interactions.get_reach(datetime(2026, 6, 1)) == []
+    await storage.posts.delete(post(81).link)
+
+    # Counters go away with the post
+    await storage.posts.delete(post(80).link)
+    assert await interactions.get_totals(today, today + timedelta(days=1)) == []
+
+
+async def check_poll_runs(storage: Storage) -> None:
+    poll_runs = storage.poll_runs
+    first = await poll_runs.start()
+    await poll_runs.finish(
+        first,
+        [
+            PollRunItem("conformance", post(90).link, "new"),
+            PollRunItem("conformance", post(91).link, "filtered", "rule spam"),
+            PollRunItem("conformance", post(92).link, "new"),
+        ],
+    )
+    second = await poll_runs.start()
+    assert second > first
+    await poll_runs.finish(second, [PollRunItem("conformance", post(90).link, "duplicate")])
+
+    runs = await poll_runs.get_recent(2)
+    assert [run.id for run in runs] == [second, first]
+    assert runs[1].counts == {"new": 2, "filtered": 1}
+    assert runs[1].finished_at is not None
+
+    items = await poll_runs.get_items(first)
+    assert [item.link for item in items] == [post(90).link, post(91).link, post(92).link]
+    filtered = await poll_runs.get_items(first, "filtered")
+    assert [(item.link, item.reason, item.run_id) for item in filtered] == [
+        (post(91).link, "rule spam", first)
+    ]
+    assert filtered[0].started_at == runs[1].started_at
+
+    history = await poll_runs.get_history(post(90).link)
+    assert [(item.run_id, item.outcome) for item in history] == [
+        (second, "duplicate"),
+        (first, "new"),
+    ]
+    assert len(await poll_runs.get_history(post(90).link, 1)) == 1
+
+    assert await poll_runs.delete_before(runs[0].started_at - timedelta(days=1)) == 0
+    assert await poll_runs.delete_before(runs[0].started_at + timedelta(days=1)) == 2
+    assert await poll_runs.get_items(first) == []
+    assert await poll_runs.get_history(post(90).link) == []
+
+    # A retried run replaces what it stored per item, and finishing drops what it didn't report
+    third = await poll_runs.start()
+    await poll_runs.add_items(
+        third,
+        [
+            PollRunItem("conformance", post(93).link, "error", "timeout"),
+            PollRunItem("conformance", post(94).link, "new"),
+            PollRunItem("other", post(93).link, "error", "timeout"),
+        ],
+    )
+    assert await poll_runs.start(third) == third
+    await poll_runs.add_items(third, [PollRunItem("conformance", post(93).link, "new")])
+    items = await poll_runs.get_items(third)
+    assert [(item.source, item.link, item.outcome, item.reason) for item in items] == [
+        ("conformance", post(93).link, "new", None),
+        ("conformance", post(94).link, "new", None),
+        ("other", post(93).link, "error", "timeout"),
+    ]
+    await poll_runs.finish(
+        third,
+        [
+            PollRunItem("conformance", post(93).link, "new"),
+            PollRunItem("conformance", post(94).link, "new"),
+        ],
+    )
+    assert [item.source for item in await poll_runs.get_items(third)] == ["conformance"] * 2
+    assert (await poll_runs.get_recent(1))[0].finished_at is not None
+    assert await poll_runs.delete_before(items[0].started_at + timedelta(days=1)) == 1
+
+
+async def check_submissions(storage: Storage) -> None:
+    submissions = storage.submissions
+    first = await submissions.create(
+        Submission(link=post(95).link, content="Concert", title="Gala", contact="org@example.com")
+    )
+    second = await submissions.create(
+        Submission(link=post(96).link, content="Lecture", partner="museum", status="approved")
+    )
+    assert second > first
+
+    created = await submissions.get_by_id(second)
+    assert (created.status, created.partner, created.reviewed_at) == ("pending", "museum", None)
+    assert created.created_at is not None
+    assert await submissions.get_by_id(new_id()) is None
+    assert [s.id for s in await submissions.get_by_status()] == [first, second]
+    assert len(await submissions.get_by_status(limit=1)) == 1
+    assert await submissions.is_pending(post(95).link)
+
+    assert await submissions.review(first, "rejected", "Not an event")
+    assert not await submissions.review(first, "approved")
+    rejected = await submissions.get_by_id(first)
+    assert (rejected.status, rejected.note) == ("rejected", "Not an event")
+    assert rejected.reviewed_at is not None
+    assert not await submissions.is_pending(post(95).link)
+    assert [s.id for s in await submissions.get_by_status("rejected")] == [first]
+    assert [s.id for s in await submissions.get_by_status()] == [second]
+
+
+async def check_digest_drafts(storage: Storage) -> None:
+    drafts = storage.digest_drafts
+    links = [post(97).link, post(98).link, post(99).link]
+    first = await drafts.create(DigestDraft("telegram", "@city", links, intro="Hello"))
+    second = await drafts.create(DigestDraft("slack", "", [post(97).link]))
+    assert second > first
+
+    draft = await drafts.get_by_id(first)
+    assert (draft.links, draft.intro, draft.status, draft.text) == (links, "Hello", "draft", None)
+    assert draft.created_at is not None and draft.sent_at is None
+    assert await drafts.get_by_id(new_id()) is None
+    assert [d.id for d in await drafts.get_by_status(["draft"])] == [second, first]
+    assert len(await drafts.get_by_status(["draft"], limit=1)) == 1
+
+    assert await drafts.update(first, [links[2], links[0]], None)
+    draft = await drafts.get_by_id(first)
+    assert (draft.links, draft.intro) == ([links[2], links[0]], None)
+
+    assert await drafts.set_status(first, "locked", "draft", text="<b>Digest</b>")
+    assert not await drafts.set_status(first, "locked", "draft", text="Other")
+    assert not await drafts.update(first, links, "Too late")
+    assert await drafts.set_status(first, "sent", "locked")
+    draft = await drafts.get_by_id(first)
+    assert (draft.status, draft.text) == ("sent", "<b>Digest</b>")
+    assert draft.links == [links[2], links[0]]
+    assert draft.sent_at is not None
+    assert [d.id for d in await drafts.get_by_status(["draft", "locked"])] == [second]
+
+
+
+async def check_publish_failures(storage: Storage) -> None:
+    failures = storage.publish_failures
+    due_at = datetime(2026, 2, 7, 12, 0)
+    later = due_at + timedelta(hours=1)
+    first = await failures.create(
+        PublishFailure(
+            "telegram",
+            "@city",
+            "<b>Digest</b>",
+            [post(97).link, post(98).link],
+            promotion_ids=[3],
+            error="Timed out",
+            next_attempt_at=due_at,
+        )
+    )
+    second = await failures.create(
+        PublishFailure("slack", "", "Digest", [post(99).link], next_attempt_at=later)
+    )
+    assert second > first
+
+    failure = await failures.get_by_id(first)
+    assert (failure.links, failure.promotion_ids) == ([post(97).link, post(98).link], [3])
+    assert (failure.error, failure.attempts, failure.status) == ("Timed out", 1, "pending")
+    assert failure.next_attempt_at == due_at and failure.created_at is not None
+    assert await failures.get_by_id(new_id()) is None
+    assert [f.id for f in await failures.get_by_status(["pending"])] == [second, first]
+    assert [f.id for f in await failures.get_due(due_at)] == [first]
+    assert [f.id for f in await failures.get_due(later)] == [first, second]
+
+    # Only a failure being sent records an attempt
+    assert not await failures.record_attempt(first, "sent")
+    assert await failures.set_status(first, "sending", ["pending", "failed"])
+    assert not await failures.set_status(first, "sending", ["pending", "failed"])
+    assert await failures.record_attempt(first, "failed", "Flood control")
+    failure = await failures.get_by_id(first)
+    assert (failure.status, failure.error, failure.attempts) == ("failed", "Flood control", 2)
+    assert failure.next_attempt_at is None
+    assert [f.id for f in await failures.get_due(later)] == [second]
+
+    assert await failures.set_status(first, "sending", ["pending", "failed"])
+    assert await failures.record_attempt(first, "sent")
+    failure = await failures.get_by_id(first)
+    assert (failure.status, failure.error, failure.attempts) == ("sent", "Flood control", 3)
+    assert await failures.set_status(second, "discarded", ["pending", "failed"])
+    assert await failures.get_by_status(["pending", "failed"]) == []
+
+
+async def check_quotas(storage: Storage) -> None:
+    quotas = storage.quotas
+    moment = datetime(2026, 2, 8, 12, 0)
+    later = moment + timedelta(hours=1)
+    await quotas.upsert(Quota("openai", "tokens", 200000, 150000, moment, moment))
+    await quotas.upsert(Quota("openai", "requests", 500, 499, moment, moment))
+    await quotas.upsert(Quota("discord", "requests", remaining=0, updated_at=moment))
+    await quotas.upsert(Quota("openai", "tokens", 200000, 1000, later, later))
+
+    assert [(q.api, q.name) for q in await quotas.get_all()] == [
+        ("discord", "requests"),
+        ("openai", "requests"),
+        ("openai", "tokens"),
+    ]
+    tokens = (await quotas.get_all())[2]
+    assert (tokens.limit, tokens.remaining, tokens.reset_at) == (200000, 1000, later)
+    assert tokens.updated_at == later
+    assert (await quotas.get_all())[0].limit is None
+
+
+async def check_bandwidth(storage: Storage) -> None:
+    bandwidth = storage.bandwidth
+    today, yesterday = date(2026, 2, 9), date(2026, 2, 8)
+    await bandwidth.add("club", yesterday, 5000)
+    await bandwidth.add("club", today, 1000)
+    await bandwidth.add("club", today, 1500)
+    await bandwidth.add("theatre", today, 90000, fetches=3)
+    await bandwidth.add("bar", today, 1000)
+
+    totals = await bandwidth.get_since(today)
+    assert [(t.source, t.bytes, t.fetches) for t in totals] == [
+        ("theatre", 90000, 3),
+        ("club", 2500, 2),
+        ("bar", 1000, 1),
+    ]
+    assert totals[0].day == today
+    club = await bandwidth.get_since(yesterday, "club")
+    assert [(t.day, t.bytes) for t in club] == [(today, 2500), (yesterday, 5000)]
+
+
+async def check_revisions(storage: Storage) -> None:
+    revisions = storage.revisions
+    await storage.posts.create(post(60))
+    link = post(60).link
+    for number in (2, 1):
+        revision = PostRevision(link, number, f"delta-{number}", f"hash-{number}")
+        assert await revisions.add(revision)
+    # Numbers are taken once; a concurrent run loses
+    assert not await revisions.add(PostRevision(link, 1, "other", "hash-x"))
+
+    stored = await revisions.get_by_link(link)
+    assert [(r.revision, r.delta, r.content_hash) for r in stored] == [
+        (1, "delta-1", "hash-1"),
+        (2, "delta-2", "hash-2"),
+    ]
+    assert stored[0].created_at is not None
+    assert await revisions.get_by_link(post(61).link) == []
+    assert await revisions.get_by_links([post(61).link, link]) == stored
+
+    await storage.posts.delete(link)
+    assert await revisions.get_by_link(link) == []
+
+
+
+async def check_overrides(storage: Storage) -> None:
+    overrides = storage.overrides
+    await storage.posts.create(post(62))
+    link = post(62).link
+    before = datetime(2026, 2, 21, 9, 0)
+    await overrides.set(FieldOverride(link, "price_min", '"500"', "null", before))
+    await overrides.set(FieldOverride(link, "event_format", '"online"', '"offline"'))
+    # A new correction of a field replaces the old one
+    await overrides.set(FieldOverride(link, "price_min", '"700"', "null", before))
+
+    stored = await overrides.get_by_link(link)
+    assert [(o.field, o.value, o.extracted) for o in stored] == [
+        ("event_format", '"online"', '"offline"'),
+        ("price_min", '"700"', "null"),
+    ]
+    assert stored[0].created_at is not None
+    recent = await overrides.get_since(before + timedelta(minutes=1))
+    assert [o.field for o in recent] == ["event_format"]
+    assert [o.field for o in await overrides.get_since(before)] == ["price_min", "event_format"]
+    assert await overrides.delete(link, "event_format")
+    assert not await overrides.delete(link, "event_format")
+    assert [o.field for o in await overrides.get_by_link(link)] == ["price_min"]
+    assert await overrides.get_by_links([link, post(63).link]) == await overrides.get_by_link(link)
+
+    await storage.posts.delete(link)
+    assert await overrides.get_by_link(link) == []
+
+
+CHECKS = [
+    check_channels,
+    check_post_fields,
+    check_post_upsert,
+    check_channel_activity,
+    check_publishing,
+    check_embargoes,
+    check_summaries,
+    check_event_status,
+    check_updated_since,
+    check_deliveries,
+    check_translations,
+    check_llm_cache,
+    check_series,
+    check_promotions,
+    check_interactions,
+    check_poll_runs,
+    check_submissions,
+    check_digest_drafts,
+    check_publish_failures,
+    check_quotas,
+    check_bandwidth,
+    check_revisions,
+    check_overrides,
+]
+
+
+async def run_repository_tests(storage: Storage) -> None:
+    """Run every conformance check against a backend, failing on the first mismatch."""
+    for check in CHECKS:
+        try:
+            await check(storage)
+        except AssertionError as error:
+            raise AssertionError(f"{storage.name}: {check.__name__} failed: {error}") from error
@@ -0,0 +1,39 @@
+"""Tests for admission requirement detection."""
+
+from common.admission import Admission, detect_admission
+from common.db.models import RSSPost
+from common.pages import SiteSettings, render_event_page
+
+
+def test_detect_registration():
+    """Test registration phrases and their negations."""
+    assert detect_admission("Вход свободный по регистрации").registration_required
+    assert detect_admission("Обязательна\nпредварительная регистрация").registration_required
+    assert detect_admission("Зарегистрироваться можно по ссылке").registration_required
+    assert not detect_admission("Вход без регистрации").registration_required
+    assert not detect_admission("Регистрация не требуется").registration_required
+
+
+def test_detect_tickets_and_capacity():
+    """Test ticket and limited capacity phrases."""
+    admission = detect_admission("Вход по билетам, количество мест ограничено!")
+    assert admission == Admission(tickets_required=True, limited_capacity=True)
+    assert detect_admission("Билеты в продаже на сайте").tickets_required
+    assert detect_admission("Осталось всего 5 мест").limited_capacity
+    assert detect_admission("Концерт в 19:00") == Admission()
+
+
+def test_warnings_on_event_page():
+    """Test that stored flags become warnings on the event page."""
+    post = RSSPost(
+        link="https://t.me/mediarzn/1",
+        content="Лекция",
+        registration_required=True,
+        limited_capacity=True,
+    )
+    assert Admission.from_post(post).warnings() == [
+        "нужна регистрация",
+        "количество мест ограничено",
+    ]
+    html = render_event_page(post, SiteSettings())
+    assert '<p class="notice">⚠️ Нужна регистрация, количество мест ограничено</p>' in html
@@ -0,0 +1,130 @@
+"""Tests for operator alert deduplication and cooldown."""
+
+import pytest
+
+from common.alerts import Alert, AlertManager
+
+
+class FakeSink:
+    def __init__(self, fail=False):
+        self.messages = []
+        self.fail = fail
+
+    def send(self, text):
+        if self.fail:
+            raise RuntimeError("webhook down")
+        self.messages.append(text)
+
+
+class FakeClock:
+    def __init__(self):
+        self.now = 1000.0
+
+    def __call__(self):
+        return self.now
+
+
+@pytest.mark.asyncio
+async def test_cooldown_suppresses_repeats():
+    """Test that repeats within the cooldown are counted, not sent."""
+    sink, clock = FakeSink(), FakeClock()
+    alerts = AlertManager([sink], cooldown_seconds=60, clock=clock)
+    alert = Alert("source_failure:mediarzn", "source failed")
+
+    assert await alerts.notify(alert)
+    assert not await alerts.notify(alert)
+    assert not await alerts.notify(alert)
+
+    clock.now += 61
+    assert await alerts.notify(alert)
+
+    assert len(sink.messages) == 2
+    assert sink.messages[0] == "🚨 source failed"
+    assert "+2 repeats" in sink.messages[1]
+
+
+@pytest.mark.asyncio
+async def test_keys_are_independent_and_resolvable():
+    """Test that different keys don't share a cooldown and resolve resets a key."""
+    sink = FakeSink()
+    alerts = AlertManager([sink], cooldown_seconds=60, clock=FakeClock())
+
+    assert await alerts.notify(Alert("a", "first"))
+    assert await alerts.notify(Alert("b", "second"))
+
+    alerts.resolve("a")
+    assert await alerts.notify(Alert("a", "again"))
+    assert len(sink.messages) == 3
+
+
+@pytest.mark.asyncio
+async def test_state_persists(tmp_path):
+    """Test that cooldowns survive a restart."""
+    state_file = str(tmp_path / "alerts.json")
+    clock = FakeClock()
+    first = AlertManager([FakeSink()], cooldown_seconds=60, state_file=state_file, clock=clock)
+    await first.notify(Alert("a", "failed"))
+
+    sink = FakeSink()
+    second = AlertManager([sink], cooldown_seconds=60, state_file=state_file, clock=clock)
+
+    assert not await second.notify(Alert("a", "failed"))
+    assert sink.messages == []
+
+
+@pytest.mark.asyncio
+async def test_delivery_failures_are_swallowed():
+    """Test that a broken sink doesn't raise or block other sinks."""
+    broken, working = FakeSink(fail=True), FakeSink()
+    alerts = AlertManager([broken, working], clock=FakeClock())
+
+    assert await alerts.notify(Alert("a", "failed", severity="warning"))
+    assert working.messages == ["⚠️ failed"]
+
+
+@pytest.mark.asyncio
+async def test_content_drift_alert(memory_storage):
+    """Test that leftover HTML in a share of recent posts raises an alert, then resolves."""
+    from datetime import datetime, timedelta
+
+    from common.clock import FakeClock, set_clock
+    from common.db.memory import MEMORY
+    from common.db.models import RSSPost
+    from common.drift import ALERT_KEY, check_content_drift, has_leftover_html
+
+    assert has_leftover_html('Концерт <div class="tgme_widget">в субботу')
+    assert has_leftover_html("Вход&nbsp;свободный")
+    assert not has_leftover_html("Скидка <50% для студентов & пенсионеров, 3<5")
+
+    now = datetime(2026, 3, 1, 12, 0)
+    previous = set_clock(FakeClock(now))
+    sink = FakeSink()
+    alerts = AlertManager([sink], cooldown_seconds=3600)
+    try:
+        assert await check_content_drift(alerts) is None
+
+        for number in range(30):
+            content = '<span class="emoji">🎷</span> Джаз' if number < 3 else "Джаз в парке"
+            await MEMORY.posts.create(
+                RSSPost(
+                    link=f"https://t.me/jazz/{number}",
+                    content=content,
+                    pub_date=now - timedelta(hours=1),
+                )
+            )
+        sample = await check_content_drift(alerts)
+        assert (sample.total, len(sample.marked)) == (30, 3)
+        [message] = sink.messages
+        assert "3 of 30 recent posts (10%)" in message
+        assert "mostly from jazz" in message
+
+        for number in range(3):
+            link = f"https://t.me/jazz/{number}"
+            await MEMORY.posts.delete(link)
+            await MEMORY.posts.create(
+                RSSPost(link=link, content="Джаз", pub_date=now - timedelta(hours=1))
+            )
+        await check_content_drift(alerts)
+        assert ALERT_KEY not in alerts._state
+    finally:
+        set_clock(previous)
@@ -0,0 +1,198 @@
+"""Tests for the ClickHouse analytics exporter."""
+
+import json
+from datetime import datetime
+from decimal import Decimal
+
+import pytest
+
+from analytics_exporter import __main__ as exporter
+from analytics_exporter.clickhouse import SCHEMA, ClickHouseClient
+from common.db.memory import MEMORY
+from common.db.models import RSSPost
+from common.utils.links import channel_from_link
+
+UPDATED = datetime(2026, 3, 1, 12, 0, 5)
+
+
+class FakeResponse:
+    def __init__(self, text=""):
+        self.text = text
+
+    def raise_for_status(self):
+        pass
+
+
+class FakeSession:
+    """Records the requests of a ClickHouseClient and answers them in order."""
+
+    def __init__(self, *answers):
+        self.answers = list(answers)
+        self.requests = []
+
+    def post(self, url, params=None, data=None, timeout=None):
+        self.requests.append((params, data.decode()))
+        return FakeResponse(self.answers.pop(0) if self.answers else "")
+
+
+def fake_client(*answers):
+    client = ClickHouseClient("http://clickhouse:8123", "analytics", user="u", password="p")
+    client.session = FakeSession(*answers)
+    return client
+
+
+def test_channel_from_link():
+    assert channel_from_link("https://t.me/centralbank_russia/3235") == "centralbank_russia"
+    assert channel_from_link("https://t.me/s/mediarzn/12") == "mediarzn"
+    assert channel_from_link("https://t.me/") == ""
+
+
+def test_post_to_row():
+    post = RSSPost(
+        link="https://t.me/mediarzn/1",
+        content="Концерт",
+        media='["https://a/1.jpg", "https://a/2.jpg"]',
+        price_min=Decimal("500.00"),
+        created_at=UPDATED,
+        updated_at=UPDATED,
+    )
+
+    row = exporter.post_to_row(post)
+
+    assert row["channel_name"] == "mediarzn"
+    assert (row["content_length"], row["media_count"], row["is_published"]) == (7, 2, 0)
+    assert (row["pub_date"], row["published_at"]) == (None, None)
+    assert row["updated_at"] == UPDATED
+    assert exporter.post_to_row(RSSPost(link="l", content="", media="a.jpg,b.jpg"))[
+        "media_count"
+    ] == 2
+
+
+def test_insert_sends_json_each_row():
+    client = fake_client()
+
+    rows = [
+        {"link": "a", "pub_date": None, "updated_at": UPDATED, "price": Decimal("500.50")},
+        {"link": "б", "pub_date": None, "updated_at": UPDATED, "price": None},
+    ]
+    assert client.insert("rss_posts", rows) == 2
+    assert client.insert("rss_posts", []) == 0
+
+    [(params, body)] = client.session.requests
+    assert params == {"query": "INSERT INTO analytics.rss_posts FORMAT JSONEachRow"}
+    first, second = [json.loads(line) for line in body.split("\n")]
+    assert first == {
+        "link": "a",
+        "pub_date": None,
+        "updated_at": "2026-03-01 12:00:05",
+        "price": "500.50",
+    }
+    assert second["link"] == "б"
+
+
+def test_get_watermark():
+    client = fake_client(
+        '{"max(updated_at)": "2026-03-01 12:00:05", "count()": "3"}\n',
+        '{"max(updated_at)": "1970-01-01 00:00:00", "count()": "0"}\n',
+        "",
+    )
+
+    assert client.get_watermark("rss_posts") == UPDATED
+    # max() of an empty table is the epoch, not NULL
+    assert client.get_watermark("rss_posts") is None
+    assert client.get_watermark("rss_posts") is None
+    assert client.session.requests[0] == (
+        None,
+        "SELECT max(updated_at), count() FROM analytics.rss_posts FORMAT JSONEachRow",
+    )
+
+
+def test_ensure_schema():
+    client = fake_client()
+
+    client.ensure_schema()
+
+    queries = [body for _, body in client.session.requests]
+    assert queries[0] == "CREATE DATABASE IF NOT EXISTS analytics"
+    assert len(queries) == 1 + len(SCHEMA)
+    assert all("IF NOT EXISTS analytics." in query for query in queries[1:])
+
+
+class FakeClickHouse:
+    """Keeps exported rows and answers watermarks from them."""
+
+    def __init__(self):
+        self.batches = []
+
+    def get_watermark(self, table, column="updated_at"):
+        rows = [row for batch in self.batches for row in batch]
+        latest = max((row[column] for row in rows), default=None)
+        # Parsed from JSON like ClickHouseClient.get_watermark, so dates come back as datetimes
+        return datetime.fromisoformat(str(latest)) if latest else None
+
+    def insert(self, table, rows):
+        self.batches.append(rows)
+        return len(rows)
+
+
+async def add_post(number, updated_at):
+    await MEMORY.posts.upsert(
+        RSSPost(link=f"https://t.me/club/{number}", content="Текст", updated_at=updated_at)
+    )
+
+
+@pytest.mark.asyncio
+async def test_export_posts_pages_by_watermark(memory_storage, monkeypatch):
+    monkeypatch.setattr(exporter.analytics_exporter_settings, "batch_size", 2)
+    # Posts 1-3 share a timestamp, keyset pagination still sends each once
+    for number in (1, 2, 3):
+        await add_post(number, UPDATED)
+    await add_post(4, datetime(2026, 3, 1, 13, 0))
+    clickhouse = FakeClickHouse()
+
+    assert await exporter.export_posts(clickhouse) == 4
+    links = [[row["link"][-1] for row in batch] for batch in clickhouse.batches]
+    assert links == [["1", "2"], ["3", "4"]]
+
+    # The next run starts at the watermark, ClickHouse keeps seconds only; posts
+    # updated in that second are sent again and replace their previous rows
+    await add_post(5, datetime(2026, 3, 2, 9, 0))
+    assert await exporter.export_posts(clickhouse) == 2
+    assert [row["link"][-1] for row in clickhouse.batches[-1]] == ["4", "5"]
+    assert await exporter.export_posts(clickhouse) == 1
+    assert [row["link"][-1] for row in clickhouse.batches[-1]] == ["5"]
+
+
+@pytest.mark.asyncio
+async def test_export_posts_stops_at_max_batches(memory_storage, monkeypatch):
+    monkeypatch.setattr(exporter.analytics_exporter_settings, "batch_size", 1)
+    monkeypatch.setattr(exporter.analytics_exporter_settings, "max_batches", 2)
+    for number in (1, 2, 3):
+        await add_post(number, datetime(2026, 3, 1, 12, number))
+    clickhouse = FakeClickHouse()
+
+    assert await exporter.export_posts(clickhouse) == 2
+    # The rest goes out with the next run, after the post at the watermark
+    assert await exporter.export_posts(clickhouse) == 2
+    assert [row["link"][-1] for row in clickhouse.batches[-1]] == ["3"]
+
+
+@pytest.mark.asyncio
+async def test_export_interactions_resends_last_day(memory_storage):
+    await add_post(1, UPDATED)
+    link = "https://t.me/club/1"
+    for kind in ("view", "view", "click"):
+        await MEMORY.interactions.record(link, kind)
+    clickhouse = FakeClickHouse()
+
+    assert await exporter.export_interactions(clickhouse) == 2
+    rows = clickhouse.batches[-1]
+    assert [(row["kind"], row["count"], row["channel_name"]) for row in rows] == [
+        ("click", 1, "club"),
+        ("view", 2, "club"),
+    ]
+
+    # Today's counters grew since; they are sent again
+    await MEMORY.interactions.record(link, "view")
+    assert await exporter.export_interactions(clickhouse) == 2
+    assert clickhouse.batches[-1][1]["count"] == 3
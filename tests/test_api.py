@@ -0,0 +1,396 @@
+"""Tests for the HTTP API service."""
+
+import asyncio
+import json
+from datetime import datetime
+from decimal import Decimal
+from types import SimpleNamespace
+
+import pytest
+
+from api import grafana, public, site
+from api.server import HTTPError, HTTPServer, Request, json_response
+from common.db.models import RSSPost
+
+
+def test_build_series_groups_by_channel():
+    """Test that daily counts are split into one series per channel."""
+    rows = [
+        {"day": datetime(2026, 1, 10), "channel_name": "mediarzn", "count": 3},
+        {"day": datetime(2026, 1, 11), "channel_name": "mediarzn", "count": 1},
+        {"day": datetime(2026, 1, 10), "channel_name": "centralbank_russia", "count": 2},
+    ]
+
+    series = grafana.build_series(rows)
+
+    assert [s["target"] for s in series] == ["centralbank_russia", "mediarzn"]
+    assert series[1]["datapoints"] == [[3, 1768003200000], [1, 1768089600000]]
+
+
+def test_build_series_channel_filter():
+    """Test scoping a series to a single channel."""
+    rows = [
+        {"day": datetime(2026, 1, 10), "channel_name": "mediarzn", "count": 3},
+        {"day": datetime(2026, 1, 10), "channel_name": "centralbank_russia", "count": 2},
+    ]
+
+    series = grafana.build_series(rows, channel="mediarzn")
+
+    assert len(series) == 1
+    assert series[0]["target"] == "mediarzn"
+
+
+def test_parse_grafana_time():
+    """Test parsing Grafana range timestamps into naive UTC datetimes."""
+    assert grafana.parse_grafana_time("2026-01-10T10:00:00.000Z") == datetime(2026, 1, 10, 10)
+    assert grafana.parse_grafana_time("2026-01-10T13:00:00+03:00") == datetime(2026, 1, 10, 10)
+    assert grafana.parse_grafana_time("2026-01-10T10:00:00") == datetime(2026, 1, 10, 10)
+
+    with pytest.raises(HTTPError):
+        grafana.parse_grafana_time("yesterday")
+
+
+@pytest.mark.asyncio
+async def test_dispatch_routes():
+    """Test routing, 404 and 405 handling."""
+    server = HTTPServer()
+    grafana.register(server)
+
+    response = await server.dispatch(Request(method="POST", path="/grafana/search"))
+    assert json.loads(response.body) == list(grafana.METRICS)
+
+    with pytest.raises(HTTPError) as exc_info:
+        await server.dispatch(Request(method="GET", path="/missing"))
+    assert exc_info.value.status == 404
+
+    with pytest.raises(HTTPError) as exc_info:
+        await server.dispatch(Request(method="GET", path="/grafana/query"))
+    assert exc_info.value.status == 405
+
+
+@pytest.mark.asyncio
+async def test_grafana_query_rejects_malformed_bodies():
+    """Test that bodies other than a query object are a 400, not a 500."""
+    server = HTTPServer()
+    grafana.register(server)
+
+    for body in (b"[1, 2]", b'"query"', b"{not json", b'{"range": []}', b'{"range": "x"}'):
+        with pytest.raises(HTTPError) as exc_info:
+            await server.dispatch(Request(method="POST", path="/grafana/query", body=body))
+        assert exc_info.value.status == 400
+
+    range_ = {"from": "2026-01-10T00:00:00Z", "to": "2026-01-11T00:00:00Z"}
+    for targets in ("posts_per_day", ["posts_per_day"], [{"target": 1}]):
+        body = json.dumps({"range": range_, "targets": targets}).encode()
+        with pytest.raises(HTTPError) as exc_info:
+            await server.dispatch(Request(method="POST", path="/grafana/query", body=body))
+        assert exc_info.value.status == 400
+
+
+async def read_request(server: HTTPServer, head: str, body: bytes = b"") -> Request:
+    reader = asyncio.StreamReader()
+    reader.feed_data(head.encode("latin-1") + b"\r\n\r\n" + body)
+    reader.feed_eof()
+    return await server._read_request(reader, "127.0.0.1")
+
+
+@pytest.mark.asyncio
+async def test_content_length_is_validated():
+    """Test that malformed, negative and oversized Content-Length headers are rejected."""
+    server = HTTPServer(max_body_bytes=10)
+
+    request = await read_request(server, "POST /x HTTP/1.1\r\nContent-Length: 2", b"{}")
+    assert request.body == b"{}"
+    assert (await read_request(server, "GET /x HTTP/1.1")).body == b""
+
+    for value, status in (("abc", 400), ("-1", 400), ("+2", 400), ("1.5", 400), ("11", 413)):
+        with pytest.raises(HTTPError) as exc_info:
+            await read_request(server, f"POST /x HTTP/1.1\r\nContent-Length: {value}", b"{}")
+        assert exc_info.value.status == status
+
+
+@pytest.mark.asyncio
+async def test_prefix_routes():
+    """Test that prefix routes match nested paths and exact routes win."""
+    server = HTTPServer()
+
+    async def exact(request):
+        return json_response("exact")
+
+    async def prefixed(request):
+        return json_response(request.path)
+
+    server.add_route("GET", "/events/index.html", exact)
+    server.add_prefix_route("GET", "/events/", prefixed)
+
+    response = await server.dispatch(Request(method="GET", path="/events/mediarzn-1.html"))
+    assert json.loads(response.body) == "/events/mediarzn-1.html"
+    response = await server.dispatch(Request(method="GET", path="/events/index.html"))
+    assert json.loads(response.body) == "exact"
+
+    with pytest.raises(HTTPError) as exc_info:
+        await server.dispatch(Request(method="POST", path="/events/mediarzn-1.html"))
+    assert exc_info.value.status == 405
+
+
+def test_site_public_paths_and_host_fallback():
+    """Test which pages skip auth and that URLs fall back to the Host header."""
+    assert site.is_public("/sitemap.xml")
+    assert site.is_public("/events/mediarzn-1.html")
+    assert not site.is_public("/grafana/query")
+
+    request = Request(
+        method="GET",
+        path="/",
+        headers={"host": "events.example.com", "x-forwarded-proto": "https"},
+    )
+    assert site.site_for(request).url("sitemap.xml") == "https://events.example.com/sitemap.xml"
+
+
+def test_public_rate_limiter_windows():
+    """Test that clients are limited per window and reset afterwards."""
+    clock = SimpleNamespace(now=0.0)
+    limiter = public.RateLimiter(2, window_seconds=60, clock=lambda: clock.now)
+
+    assert limiter.check("1.2.3.4") is None
+    assert limiter.check("1.2.3.4") is None
+    assert limiter.check("1.2.3.4") == 60
+    assert limiter.check("5.6.7.8") is None
+
+    clock.now = 61
+    assert limiter.check("1.2.3.4") is None
+
+
+def test_public_response_cache_expires():
+    """Test that cached responses expire after the TTL."""
+    clock = SimpleNamespace(now=0.0)
+    cache = public.ResponseCache(10, clock=lambda: clock.now)
+    response = public.cached_json({"events": []})
+
+    cache.set("key", response)
+    assert cache.get("key") is response
+    clock.now = 10
+    assert cache.get("key") is None
+
+
+def test_public_etag_not_modified():
+    """Test that a matching If-None-Match gets an empty 304."""
+    response = public.cached_json({"events": []})
+    etag = response.headers["ETag"]
+
+    request = Request(method="GET", path="/v1/events", headers={"if-none-match": etag})
+    cached = public.not_modified(request, response)
+    assert cached.status == 304
+    assert cached.body == b""
+
+    request = Request(method="GET", path="/v1/events", headers={"if-none-match": '"stale"'})
+    assert public.not_modified(request, response) is response
+
+
+def test_public_limit_and_filters():
+    """Test limit validation and channel/tag filtering."""
+    assert public.parse_limit(None) == public.DEFAULT_LIMIT
+    assert public.parse_limit("10") == 10
+    for value in ("0", "1000", "ten"):
+        with pytest.raises(HTTPError):
+            public.parse_limit(value)
+
+    posts = [
+        RSSPost(link="https://t.me/mediarzn/1", content="a", tags=["Concert"]),
+        RSSPost(link="https://t.me/mediarzn/2", content="b", tags=["lecture"]),
+        RSSPost(link="https://t.me/other/3", content="c", tags=["concert"]),
+    ]
+    assert [p.link for p in public.filter_events(posts, channel="mediarzn")] == [
+        "https://t.me/mediarzn/1",
+        "https://t.me/mediarzn/2",
+    ]
+    assert [p.link for p in public.filter_events(posts, tag="concert", limit=1)] == [
+        "https://t.me/mediarzn/1"
+    ]
+
+
+def test_public_event_fields():
+    """Test that only public fields are exposed."""
+    post = RSSPost(
+        link="https://t.me/mediarzn/1",
+        content="Концерт",
+        pub_date=datetime(2026, 1, 10, 19),
+        is_published=True,
+        title="Концерт",
+    )
+    data = public.event_to_dict(post)
+    assert data["id"] == "mediarzn-1"
+    assert data["channel"] == "mediarzn"
+    assert data["date"] == "2026-01-10T19:00:00"
+    assert "is_published" not in data
+
+
+def test_public_price_filters():
+    """Test min/max price filters and price query parsing."""
+    posts = [
+        RSSPost(link="https://t.me/a/1", content="", price_min=Decimal(0), price_max=Decimal(0)),
+        RSSPost(
+            link="https://t.me/a/2", content="", price_min=Decimal(500), price_max=Decimal(1500)
+        ),
+        RSSPost(link="https://t.me/a/3", content=""),
+    ]
+
+    def links(**kwargs):
+        return [post.link[-1] for post in public.filter_events(posts, **kwargs)]
+
+    assert links() == ["1", "2", "3"]
+    assert links(max_price=Decimal(0)) == ["1"]
+    assert links(max_price=Decimal(700)) == ["1", "2"]
+    assert links(min_price=Decimal(1000)) == ["2"]
+
+    assert public.parse_price("max_price", "1.5e3") == Decimal(1500)
+    for value in ("-1", "cheap", "NaN"):
+        with pytest.raises(HTTPError):
+            public.parse_price("max_price", value)
+    assert public.event_to_dict(posts[1])["price"] == {
+        "min": 500.0,
+        "max": 1500.0,
+        "currency": None,
+    }
+
+
+def test_public_online_filter():
+    """Test the online filter and boolean query parsing."""
+    posts = [
+        RSSPost(link="https://t.me/a/1", content="", event_format="online"),
+        RSSPost(link="https://t.me/a/2", content="", event_format="offline"),
+        RSSPost(link="https://t.me/a/3", content="", event_format="hybrid"),
+        RSSPost(link="https://t.me/a/4", content=""),
+    ]
+
+    def links(**kwargs):
+        return [post.link[-1] for post in public.filter_events(posts, **kwargs)]
+
+    assert links() == ["1", "2", "3", "4"]
+    assert links(online=True) == ["1", "3"]
+    assert links(online=False) == ["2", "3"]
+
+    assert public.parse_bool("online", None) is None
+    assert public.parse_bool("online", "TRUE") is True
+    assert public.parse_bool("online", "0") is False
+    with pytest.raises(HTTPError):
+        public.parse_bool("online", "maybe")
+    assert public.event_to_dict(posts[2])["format"] == "hybrid"
+
+
+def test_public_performer_and_organization_filters():
+    """Test filtering events by the people and organizations they name."""
+    posts = [
+        RSSPost(link="https://t.me/a/1", content="", performers=["Анна Смирнова"]),
+        RSSPost(
+            link="https://t.me/a/2",
+            content="",
+            performers=["Иван Петров", "Анна  Смирнова"],
+            organizations=["Гараж"],
+        ),
+        RSSPost(link="https://t.me/a/3", content=""),
+    ]
+
+    def links(**kwargs):
+        return [post.link[-1] for post in public.filter_events(posts, **kwargs)]
+
+    assert links(performer="смирнова") == ["1", "2"]
+    assert links(performer="Анна Смирнова") == ["1", "2"]
+    assert links(performer="Петров") == ["2"]
+    assert links(organization="гараж") == ["2"]
+    assert links(performer="Смирнова", organization="Эрмитаж") == []
+    data = public.event_to_dict(posts[1])
+    assert (data["performers"], data["organizations"]) == (
+        ["Иван Петров", "Анна  Смирнова"],
+        ["Гараж"],
+    )
+    assert public.event_to_dict(posts[2])["performers"] == []
+
+
+@pytest.mark.asyncio
+async def test_site_source_redirect_counts_click(monkeypatch):
+    """Test that /go/<slug> redirects to the post and counts a click."""
+    post = RSSPost(link="https://t.me/mediarzn/7", content="", pub_date=datetime(2026, 1, 10))
+    recorded = []
+
+    async def record(link, kind):
+        recorded.append((link, kind))
+
+    monkeypatch.setattr(site.InteractionRepository, "record", record)
+    monkeypatch.setattr(site.cache, "loaded_at", None)
+    site.cache.set([post])
+
+    response = await site.go(Request(method="GET", path="/go/mediarzn-7"))
+    assert response.status == 302
+    assert response.headers["Location"] == "https://t.me/mediarzn/7"
+    assert recorded == [("https://t.me/mediarzn/7", "click")]
+    assert site.is_public("/go/mediarzn-7")
+
+
+@pytest.mark.asyncio
+async def test_public_channel_directory(memory_storage, monkeypatch):
+    """Test that /v1/channels lists channels with their activity."""
+    from common.clock import FakeClock, set_clock
+    from common.db.memory import MEMORY
+    from common.db.models import TelegramChannel
+
+    previous = set_clock(FakeClock(datetime(2026, 3, 29, 12, 0)))
+    monkeypatch.setattr(public, "responses", public.ResponseCache(60))
+    try:
+        await MEMORY.channels.create(
+            TelegramChannel(
+                channel_id=1,
+                channel_name="mediarzn",
+                avatar_url="https://cdn.example/mediarzn.jpg",
+                category="Музыка",
+            )
+        )
+        await MEMORY.channels.set_title(1, "Медиа Рязань")
+        await MEMORY.channels.create(TelegramChannel(channel_id=2, channel_name="quiet"))
+        for number, day, published in ((1, 2, True), (2, 10, True), (3, 20, False), (4, 25, False)):
+            await MEMORY.posts.create(
+                RSSPost(
+                    link=f"https://t.me/mediarzn/{number}",
+                    content="",
+                    pub_date=datetime(2026, 3, day, 18, 0),
+                )
+            )
+            if published:
+                await MEMORY.posts.mark_as_published([f"https://t.me/mediarzn/{number}"])
+        await MEMORY.posts.update_summary("https://t.me/mediarzn/2", "Концерт", "Джаз в парке")
+
+        server = HTTPServer()
+        public.register(server)
+        response = await server.dispatch(Request(method="GET", path="/v1/channels"))
+        assert json.loads(response.body)["channels"] == [
+            {
+                "name": "mediarzn",
+                "title": "Медиа Рязань",
+                "avatar": "https://cdn.example/mediarzn.jpg",
+                "category": "Музыка",
+                "url": "https://t.me/mediarzn",
+                # Four posts in the last four weeks
+                "posts_per_week": 1.0,
+                "last_event": {
+                    "id": "mediarzn-2",
+                    "title": "Джаз в парке",
+                    "link": "https://t.me/mediarzn/2",
+                    "date": "2026-03-10T18:00:00",
+                },
+            },
+            {
+                "name": "quiet",
+                "title": "quiet",
+                "avatar": None,
+                "category": None,
+                "url": "https://t.me/quiet",
+                "posts_per_week": 0.0,
+                "last_event": None,
+            },
+        ]
+
+        request = Request(method="GET", path="/v1/channels", query={"category": "музыка"})
+        data = json.loads((await server.dispatch(request)).body)
+        assert [channel["name"] for channel in data["channels"]] == ["mediarzn"]
+    finally:
+        set_clock(previous)
@@ -0,0 +1,119 @@
+"""Tests for backup archives."""
+
+from datetime import date, datetime
+from decimal import Decimal
+
+import pytest
+
+from backup import __main__ as backup
+from backup.archive import BackupReader, BackupWriter, media_urls
+from common.db.memory import MEMORY
+from common.db.models import FieldOverride, PostRevision, RSSPost, Series, TelegramChannel
+
+
+def test_backup_roundtrip(tmp_path):
+    """Test that channels and posts survive a write/read cycle."""
+    path = str(tmp_path / "backup.tar.gz")
+    channel = TelegramChannel(
+        channel_id=1, channel_name="centralbank_russia", created_at=datetime(2026, 1, 1)
+    )
+    post = RSSPost(
+        link="https://t.me/centralbank_russia/3235",
+        content="Test content",
+        pub_date="2026-01-10T10:00:00Z",
+        media='["https://cdn4.telesco.pe/file/a.jpg"]',
+        is_published=True,
+        published_at=datetime(2026, 1, 11, 9, 30),
+        price_min=Decimal("500.00"),
+        price_max=Decimal("1500.00"),
+        price_currency="RUB",
+        event_start=date(2026, 1, 17),
+        event_end=date(2026, 1, 18),
+        deadline=date(2026, 1, 15),
+        deadline_kind="registration",
+        embargo_until=datetime(2026, 1, 12, 12, 0),
+        series_key="#джазфест",
+    )
+    series = Series(key="#джазфест", name="Джазфест", created_at=datetime(2026, 1, 2))
+    override = FieldOverride(
+        post.link, "price_min", '"500.00"', '"300.00"', created_at=datetime(2026, 1, 12, 8, 0)
+    )
+    revision = PostRevision(post.link, 1, "@@ -1 +1 @@", "ab12", created_at=datetime(2026, 1, 11))
+
+    writer = BackupWriter(path)
+    writer.add_channel(channel)
+    writer.add_series(series)
+    writer.add_post(post)
+    writer.add_override(override)
+    writer.add_revision(revision)
+    writer.close()
+
+    reader = BackupReader(path)
+    assert reader.manifest["counts"]["rss_posts.jsonl"] == 1
+    assert list(reader.channels()) == [channel]
+    assert list(reader.series()) == [series]
+    assert list(reader.posts()) == [post]
+    assert list(reader.overrides()) == [override]
+    assert list(reader.revisions()) == [revision]
+    reader.close()
+
+
+@pytest.mark.asyncio
+async def test_backup_and_restore_keep_corrections(memory_storage, tmp_path):
+    """Test that moderators' corrections and revisions are restored with their posts."""
+    path = str(tmp_path / "backup.tar.gz")
+    post = RSSPost(link="https://t.me/club/1", content="Концерт", pub_date=datetime(2026, 1, 10))
+    await MEMORY.posts.create(post)
+    await MEMORY.overrides.set(FieldOverride(post.link, "event_format", '"online"', '"offline"'))
+    await MEMORY.revisions.add(PostRevision(post.link, 1, "@@ -1 +1 @@", "ab12"))
+    overrides = await MEMORY.overrides.get_by_link(post.link)
+    revisions = await MEMORY.revisions.get_by_link(post.link)
+
+    counts = await backup.backup(path)
+    assert (counts["field_overrides.jsonl"], counts["post_revisions.jsonl"]) == (1, 1)
+
+    await MEMORY.posts.delete(post.link)
+    assert await backup.restore(path) == {
+        "channels": 0,
+        "series": 0,
+        "posts": 1,
+        "overrides": 1,
+        "revisions": 1,
+    }
+    assert await MEMORY.overrides.get_by_link(post.link) == overrides
+    assert await MEMORY.revisions.get_by_link(post.link) == revisions
+
+
+def test_manifest_uses_clock(tmp_path, fake_clock):
+    """Test that the creation time of an archive comes from the shared clock."""
+    path = str(tmp_path / "backup.tar.gz")
+    BackupWriter(path).close()
+
+    reader = BackupReader(path)
+    assert reader.manifest["created_at"] == "2026-03-01T12:00:00"
+    assert reader._tar.getmember("manifest.json").mtime == int(fake_clock.now().timestamp())
+    reader.close()
+
+
+def test_media_urls_formats():
+    """Test reading media stored as JSON list or comma-separated string."""
+    assert media_urls(RSSPost(link="a", content="", media='["x", "y"]')) == ["x", "y"]
+    assert media_urls(RSSPost(link="a", content="", media="x,y")) == ["x", "y"]
+    assert media_urls(RSSPost(link="a", content="")) == []
+
+
+def test_reader_rejects_unknown_version(tmp_path):
+    """Test that archives from an unknown format version are refused."""
+    import io
+    import json
+    import tarfile
+
+    path = str(tmp_path / "future.tar.gz")
+    manifest = json.dumps({"format_version": 99}).encode()
+    with tarfile.open(path, "w:gz") as tar:
+        info = tarfile.TarInfo("manifest.json")
+        info.size = len(manifest)
+        tar.addfile(info, io.BytesIO(manifest))
+
+    with pytest.raises(ValueError, match="format version"):
+        BackupReader(path)
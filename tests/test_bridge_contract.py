@@ -0,0 +1,79 @@
+"""Tests for the rss-bridge feed format contract.
+
+The live test fetches BRIDGE_CONTRACT_CHANNEL through the bridge at
+BRIDGE_CONTRACT_URL (rss-bridge.org by default); it is skipped unless the
+channel is set.
+"""
+
+import os
+from datetime import datetime
+from pathlib import Path
+
+import pytest
+
+from rss_reader.contract import DEFAULT_BRIDGE, last_result, save_result
+from rss_reader.core.contract import check_bridge_feed
+
+FIXTURE = Path(__file__).parent / "fixtures" / "centralbank_russia.xml"
+CHECKED_AT = datetime(2026, 2, 18, 3, 0)
+
+
+def test_fixture_meets_the_contract():
+    result = check_bridge_feed(FIXTURE.read_text(), "centralbank_russia", CHECKED_AT)
+    assert result.failures == {}
+    assert result.items == 20
+    assert result.passed
+
+
+def test_format_changes_break_expectations():
+    content = FIXTURE.read_text()
+    changed = (
+        content.replace("https://t.me/centralbank_russia/3235", "https://t.me/c/3235")
+        .replace("tgme_widget_message_text", "message-text")
+        .replace("Fri, 09 Jan 2026 10:15:06 +0000", "yesterday")
+    )
+    result = check_bridge_feed(changed, "centralbank_russia", CHECKED_AT)
+    assert sorted(result.failures) == ["item_links", "message_markup", "pub_dates"]
+    assert result.failures["item_links"] == "1 of 20 items, e.g. https://t.me/c/3235"
+
+    result = check_bridge_feed(content, "another_channel", CHECKED_AT)
+    assert sorted(result.failures) == ["channel_link", "channel_title", "item_links"]
+
+    result = check_bridge_feed("<html><body>Bridge error</body></html>", "x", CHECKED_AT)
+    assert list(result.failures) == ["rss_root"]
+    assert not result.passed
+
+
+def test_results_are_persisted_and_compared(tmp_path):
+    path = tmp_path / "results.jsonl"
+    assert last_result(path, "centralbank_russia") is None
+
+    content = FIXTURE.read_text()
+    first = check_bridge_feed(content, "centralbank_russia", CHECKED_AT)
+    save_result(path, first)
+    save_result(path, check_bridge_feed("<rss version='2.0'/>", "other", CHECKED_AT))
+    previous = last_result(path, "centralbank_russia")
+    assert previous == first
+
+    broken = check_bridge_feed(
+        content.replace("tgme_widget_message_text", "message-text"),
+        "centralbank_russia",
+        datetime(2026, 2, 19, 3, 0),
+    )
+    assert broken.new_failures(previous) == ["message_markup"]
+    save_result(path, broken)
+    # Failing again the next night is no new drift
+    assert broken.new_failures(last_result(path, "centralbank_russia")) == []
+
+
+def test_live_bridge_feed():
+    channel = os.getenv("BRIDGE_CONTRACT_CHANNEL")
+    if not channel:
+        pytest.skip("BRIDGE_CONTRACT_CHANNEL not set")
+    from common.utils.rss_bridge import build_rss_bridge_url
+    from rss_reader.core.fetcher import FeedFetcher
+
+    bridge = os.getenv("BRIDGE_CONTRACT_URL", DEFAULT_BRIDGE)
+    content = FeedFetcher(timeout=30).fetch(build_rss_bridge_url(channel, base_url=bridge))
+    result = check_bridge_feed(content, channel, datetime.now())
+    assert result.failures == {}
@@ -0,0 +1,127 @@
+"""Tests for failing over between rss-bridge instances."""
+
+from datetime import timedelta
+
+import pytest
+
+from common.db.memory import MEMORY
+from common.db.models import TelegramChannel
+from common.models.feed import RSSChannel, RSSItem
+from common.utils.rss_bridge import DEFAULT_BRIDGE_URL, build_rss_bridge_url
+from rss_reader.__main__ import process_source
+from rss_reader.core.bridges import BridgePool, reset_health
+from rss_reader.core.source import TelegramSource
+
+FIRST = "https://bridge.example/"
+SECOND = "https://bridge.self-hosted.example/"
+
+
+@pytest.fixture
+def health(fake_clock):
+    reset_health()
+    yield
+    reset_health()
+
+
+def test_pool_from_env(monkeypatch):
+    monkeypatch.setenv("RSS_BRIDGE_URLS", f" {FIRST}, ,{SECOND},{FIRST}")
+    monkeypatch.setenv("RSS_BRIDGE_COOLDOWN_SECONDS", "60")
+    monkeypatch.setenv("RSS_BRIDGE_ROTATE", "true")
+    pool = BridgePool.from_env()
+    assert (pool.base_urls, pool.cooldown, pool.rotate) == ([FIRST, SECOND], 60, True)
+
+    monkeypatch.delenv("RSS_BRIDGE_URLS")
+    assert BridgePool.from_env().base_urls == [DEFAULT_BRIDGE_URL]
+
+
+def test_failing_instance_cools_down(fake_clock, health):
+    pool = BridgePool([FIRST, SECOND], cooldown=60)
+    first, second = build_rss_bridge_url("club", FIRST), build_rss_bridge_url("club", SECOND)
+    assert pool.feed_urls("club") == [first, second]
+
+    pool.record_failure(first, ConnectionError("503"))
+    assert pool.feed_urls("club") == [second, first]
+    fake_clock.advance(timedelta(seconds=61))
+    assert pool.feed_urls("club") == [first, second]
+
+    # Each failure in a row doubles the cooldown
+    pool.record_failure(first, ConnectionError("503"))
+    fake_clock.advance(timedelta(seconds=61))
+    assert pool.feed_urls("club") == [second, first]
+    fake_clock.advance(timedelta(seconds=60))
+    assert pool.feed_urls("club") == [first, second]
+
+    # All down: the one back first goes first
+    pool.record_failure(second, ConnectionError("503"))
+    pool.record_failure(first, ConnectionError("503"))
+    assert pool.feed_urls("club") == [second, first]
+
+    pool.record_success(first)
+    health = {h.base_url: h for h in pool.report()}
+    assert (health[FIRST].failures, health[FIRST].failures_in_row) == (3, 0)
+    assert health[SECOND].last_error == "503"
+    assert pool.feed_urls("club") == [first, second]
+
+
+def test_instances_whose_urls_share_a_prefix(fake_clock, health):
+    pool = BridgePool(["http://bridge", "http://bridge2"], cooldown=60)
+    first, second = pool.feed_urls("club")
+    assert [pool.base_url_of(first), pool.base_url_of(second)] == pool.base_urls
+
+    pool.record_failure(second, ConnectionError("503"))
+    assert pool.feed_urls("club") == [first, second]
+    assert [h.failures for h in pool.report()] == [0, 1]
+    assert pool.base_url_of("http://bridge3?username=club") is None
+
+
+def test_rotation_keeps_each_channel_on_one_instance(fake_clock, health):
+    pool = BridgePool([FIRST, SECOND], rotate=True)
+    preferred = {name: pool.feed_urls(name)[0] for name in ("a", "b", "c", "d", "e", "f")}
+    assert {pool.base_url_of(url) for url in preferred.values()} == {FIRST, SECOND}
+    assert all(pool.feed_urls(name)[0] == url for name, url in preferred.items())
+
+
+class FlakyParser:
+    """Serves feeds from the instances that aren't down."""
+
+    def __init__(self, down):
+        self.down = set(down)
+        self.fetched = []
+
+    async def parse_url_async(self, url, since=None):
+        self.fetched.append(url)
+        if any(url.startswith(base_url) for base_url in self.down):
+            raise ValueError("Failed to parse RSS feed: 503 Server Error")
+        item = RSSItem(link="https://t.me/club/1", description="Джаз 12 марта")
+        return RSSChannel(title="Club", link=url, description="", items=[item])
+
+    def forget(self, url):
+        pass
+
+
+@pytest.mark.asyncio
+async def test_source_fails_over_to_the_next_bridge(memory_storage, fake_clock, health):
+    channel = TelegramChannel(channel_id=1, channel_name="club", title="Club")
+    await MEMORY.channels.create(channel)
+    pool = BridgePool([FIRST, SECOND], cooldown=60)
+    parser = FlakyParser(down=[FIRST])
+
+    source = TelegramSource(channel, parser, bridges=pool)
+    assert await process_source(source) == ("club", 1, 0, 0, 0, 0, 0)
+    assert parser.fetched == [build_rss_bridge_url("club", base_url) for base_url in pool.base_urls]
+    assert source.location == build_rss_bridge_url("club", SECOND)
+
+    # The failed instance isn't asked again until it cooled down
+    parser.fetched = []
+    assert await process_source(TelegramSource(channel, parser, bridges=pool)) == (
+        "club", 0, 1, 0, 0, 0, 0
+    )
+    assert parser.fetched == [build_rss_bridge_url("club", SECOND)]
+
+    # All failing: the error of the last one is reported
+    parser.down.add(SECOND)
+    parser.fetched = []
+    assert await process_source(TelegramSource(channel, parser, bridges=pool)) == (
+        "club", 0, 0, 0, 0, 1, 0
+    )
+    assert len(parser.fetched) == 2
@@ -0,0 +1,73 @@
+"""Tests for the shared time source."""
+
+from datetime import date, datetime, timedelta
+
+import pytest
+
+from common import clock
+from common.clock import FakeClock, set_clock
+from common.db import memory
+from common.db.memory import MEMORY
+from common.db.models import RSSPost
+from common.event_dates import parse_schedule
+from pipeline.config import PipelineConfig
+from pipeline.orchestrator import AgentStatus, PipelineOrchestrator
+
+START = datetime(2026, 3, 1, 12, 0)
+
+
+@pytest.mark.asyncio
+async def test_fake_clock_sleep_advances_time(fake_clock):
+    await clock.sleep(90)
+    fake_clock.advance(timedelta(hours=1))
+
+    assert clock.now() == datetime(2026, 3, 1, 13, 1, 30)
+    assert clock.today() == date(2026, 3, 1)
+    assert fake_clock.sleeps == [90]
+
+
+def test_set_clock_returns_previous():
+    fake = FakeClock(START)
+    previous = set_clock(fake)
+    try:
+        assert clock.get_clock() is fake
+    finally:
+        assert set_clock(previous) is fake
+    assert clock.now() != START
+
+
+def test_dates_without_reference_use_clock(fake_clock):
+    schedule = parse_schedule("Концерт 5 марта")
+
+    assert schedule.start == date(2026, 3, 5)
+
+
+@pytest.mark.asyncio
+async def test_retries_wait_on_clock(fake_clock):
+    config = PipelineConfig(max_retries=2, retry_delay_seconds=30)
+    calls = []
+
+    async def flaky():
+        calls.append(clock.now())
+        if len(calls) < 3:
+            raise RuntimeError("temporary")
+        return {}
+
+    result = await PipelineOrchestrator(config)._run_agent_with_retry("Flaky", flaky)
+
+    assert result.status == AgentStatus.SUCCESS
+    assert fake_clock.sleeps == [30, 30]
+    assert calls == [START, START + timedelta(seconds=30), START + timedelta(seconds=60)]
+    assert result.timestamp == START + timedelta(seconds=60)
+
+
+@pytest.mark.asyncio
+async def test_memory_timestamps_follow_clock(fake_clock):
+    memory.reset()
+    await MEMORY.posts.create(RSSPost(link="https://t.me/club/1", content="Text"))
+    fake_clock.advance(timedelta(days=1))
+    await MEMORY.posts.mark_as_published(["https://t.me/club/1"])
+
+    post = await MEMORY.posts.get_by_link("https://t.me/club/1")
+    assert post.created_at == START
+    assert post.published_at == START + timedelta(days=1)
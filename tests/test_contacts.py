@@ -0,0 +1,60 @@
+"""Tests for contact extraction."""
+
+from datetime import datetime
+
+from common.contacts import extract_contacts, format_phone, normalize_phone
+from common.db.models import RSSPost
+from common.ics import render_event
+from common.pages import SiteSettings, render_event_page
+
+
+def test_extract_phone_numbers():
+    """Test that the ways a number is written normalize to one contact."""
+    assert extract_contacts("Запись: 8 (910) 123-45-67 или +7 910 123 45 67") == [
+        "+79101234567"
+    ]
+    assert extract_contacts("89101234567 — администратор, 8 800 555-35-35") == [
+        "+79101234567",
+        "+78005553535",
+    ]
+    assert extract_contacts("Contact: +44 20 7946 0958") == ["+442079460958"]
+    # Prices, dates, times, short city numbers and tax IDs aren't phone numbers
+    assert extract_contacts("Билеты 1500 ₽, 12.03.2026 в 19:00-21:00, тел. 28-14-88") == []
+    assert extract_contacts("ИНН 6229012345, ОГРН 1026201270260") == []
+
+    assert normalize_phone("8 (910) 123-45-67") == "+79101234567"
+    assert format_phone("+79101234567") == "+7 910 123-45-67"
+    assert format_phone("+442079460958") == "+442079460958"
+
+
+def test_extract_emails():
+    """Test that emails are found and lowercased."""
+    assert extract_contacts("Пишите на Info@Club.ru. Звоните +79101234567") == [
+        "info@club.ru",
+        "+79101234567",
+    ]
+    assert extract_contacts("hello@example.co.uk, hello@example.co.uk") == [
+        "hello@example.co.uk"
+    ]
+    assert extract_contacts("Подписывайтесь на @club_news") == []
+
+
+def test_contacts_on_event_page_and_calendar():
+    """Test that stored contacts are linked on the page and become CONTACT lines."""
+    post = RSSPost(
+        link="https://t.me/mediarzn/1",
+        content="Лекция",
+        pub_date=datetime(2026, 3, 1, 19, 0),
+        contacts=["+79101234567", "info@club.ru"],
+    )
+    html = render_event_page(post, SiteSettings())
+    assert (
+        '<p class="meta">Контакты: <a href="tel:+79101234567">+7 910 123-45-67</a>, '
+        '<a href="mailto:info@club.ru">info@club.ru</a></p>'
+    ) in html
+    lines = render_event(post)
+    assert [line for line in lines if line.startswith("CONTACT")] == [
+        "CONTACT:+7 910 123-45-67",
+        "CONTACT:info@club.ru",
+    ]
+    assert "Контакты" not in render_event_page(RSSPost(link=post.link, content=""), SiteSettings())
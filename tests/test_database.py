@@ -2,8 +2,8 @@
 
 import pytest
 import pytest_asyncio
-from datetime import datetime
-from src.feed.db import db, RSSPost, RSSPostRepository
+from datetime import datetime, timedelta
+from common.db import db, RSSPost, RSSPostRepository
 
 
 @pytest_asyncio.fixture(autouse=True)
@@ -30,7 +30,7 @@ async def test_create_post():
     post = RSSPost(
         link="https://example.com/test-1",
         content="Test post content",
-        pub_date="2026-01-10T10:00:00Z",
+        pub_date=datetime(2026, 1, 10, 10, 0),
     )
 
     link = await RSSPostRepository.create(post)
@@ -95,127 +95,6 @@ async def test_exists_by_link():
     assert exists_after is True
 
 
-@pytest.mark.asyncio
-async def test_get_unprocessed():
-    """Test retrieving unprocessed posts."""
-    # Create some posts
-    for i in range(5):
-        post = RSSPost(
-            link=f"https://example.com/test-{i}",
-            content=f"Test {i}",
-        )
-        await RSSPostRepository.create(post)
-
-    # Mark some as processed
-    posts = await RSSPostRepository.get_all()
-    await RSSPostRepository.mark_as_processed(posts[0].link, is_event=True)
-    await RSSPostRepository.mark_as_processed(posts[1].link, is_event=False)
-
-    # Get unprocessed
-    unprocessed = await RSSPostRepository.get_unprocessed()
-    assert len(unprocessed) == 3
-
-
-@pytest.mark.asyncio
-async def test_mark_as_processed():
-    """Test marking a post as processed."""
-    post = RSSPost(
-        link="https://example.com/test-6",
-        content="Event announcement",
-    )
-
-    link = await RSSPostRepository.create(post)
-
-    # Mark as processed
-    classification_data = {"confidence": 0.95, "classifier": "test"}
-    await RSSPostRepository.mark_as_processed(
-        link,
-        is_event=True,
-        classification_data=classification_data,
-    )
-
-    # Verify
-    retrieved = await RSSPostRepository.get_by_link(link)
-    assert retrieved.is_processed is True
-    assert retrieved.is_event is True
-    assert retrieved.classification_data == classification_data
-    assert retrieved.classified_at is not None
-
-
-@pytest.mark.asyncio
-async def test_mark_as_unprocessed():
-    """Test marking a post as unprocessed."""
-    post = RSSPost(
-        link="https://example.com/test-7",
-        content="Test content",
-    )
-
-    link = await RSSPostRepository.create(post)
-    await RSSPostRepository.mark_as_processed(link, is_event=True)
-
-    # Mark as unprocessed
-    await RSSPostRepository.mark_as_unprocessed(link)
-
-    # Verify
-    retrieved = await RSSPostRepository.get_by_link(link)
-    assert retrieved.is_processed is False
-
-
-@pytest.mark.asyncio
-async def test_update_classification():
-    """Test updating classification data."""
-    post = RSSPost(
-        link="https://example.com/test-8",
-        content="Test content",
-    )
-
-    link = await RSSPostRepository.create(post)
-
-    # Update classification
-    new_classification = {"confidence": 0.88, "tags": ["event", "conference"]}
-    await RSSPostRepository.update_classification(
-        link,
-        is_event=True,
-        classification_data=new_classification,
-    )
-
-    # Verify
-    retrieved = await RSSPostRepository.get_by_link(link)
-    assert retrieved.is_event is True
-    assert retrieved.classification_data == new_classification
-
-
-@pytest.mark.asyncio
-async def test_get_all_with_filters():
-    """Test retrieving posts with filters."""
-    # Create various posts
-    posts_data = [
-        ("https://example.com/event-1", True, True),
-        ("https://example.com/event-2", True, True),
-        ("https://example.com/news-1", True, False),
-        ("https://example.com/unprocessed-1", False, None),
-    ]
-
-    for link, processed, is_event in posts_data:
-        post = RSSPost(link=link, content="Test")
-        created_link = await RSSPostRepository.create(post)
-        if processed:
-            await RSSPostRepository.mark_as_processed(created_link, is_event=is_event)
-
-    # Test filters
-    all_posts = await RSSPostRepository.get_all()
-    assert len(all_posts) == 4
-
-    processed = await RSSPostRepository.get_all(is_processed=True)
-    assert len(processed) == 3
-
-    events = await RSSPostRepository.get_all(is_event=True)
-    assert len(events) == 2
-
-    unprocessed = await RSSPostRepository.get_all(is_processed=False)
-    assert len(unprocessed) == 1
-
-
 @pytest.mark.asyncio
 async def test_get_all_pagination():
     """Test pagination in get_all."""
@@ -265,26 +144,20 @@ async def test_delete_post():
 @pytest.mark.asyncio
 async def test_get_stats():
     """Test getting database statistics."""
-    # Create various posts
+    # Create posts, some of them published this week
     for i in range(10):
         post = RSSPost(
             link=f"https://example.com/test-{i}",
             content=f"Test {i}",
+            pub_date=datetime.now() - timedelta(days=i * 2),
         )
-        link = await RSSPostRepository.create(post)
-
-        # Mark some as processed
-        if i < 7:
-            is_event = i < 4
-            await RSSPostRepository.mark_as_processed(link, is_event=is_event)
+        await RSSPostRepository.create(post)
 
     # Get stats
     stats = await RSSPostRepository.get_stats()
 
     assert stats["total"] == 10
-    assert stats["processed"] == 7
-    assert stats["unprocessed"] == 3
-    assert stats["events"] == 4
+    assert stats["recent"] == 4
 
 
 @pytest.mark.asyncio
@@ -307,15 +180,14 @@ async def test_post_dataclass_conversions():
         "content": "Test",
         "pub_date": "2026-01-10",
         "media": "test.jpg",
-        "is_processed": True,
-        "is_event": False,
-        "classification_data": {"confidence": 0.9},
+        "is_published": True,
+        "tags": ("concert",),
         "created_at": datetime.now(),
         "updated_at": datetime.now(),
-        "classified_at": None,
     }
 
     post_from_row = RSSPost.from_row(row_data)
     assert post_from_row.link == row_data["link"]
-    assert post_from_row.is_processed is True
+    assert post_from_row.is_published is True
+    assert post_from_row.tags == ["concert"]
     assert post_from_row.is_event is False
@@ -0,0 +1,27 @@
+"""Tests for the demo sample feed."""
+
+from datetime import datetime, timedelta
+
+from common.event_dates import parse_schedule
+from demo.sample import SAMPLE_POSTS, build_feed
+from rss_reader.core.parser import RSSParser
+
+NOW = datetime(2026, 12, 28, 12, 0)
+
+
+def test_sample_feed_parses():
+    feed = RSSParser().parse_content(build_feed(NOW).decode())
+
+    assert len(feed.items) == len(SAMPLE_POSTS)
+    assert feed.items[0].link == "https://t.me/demo_theatre/302"
+    assert "Вечер джаза" in feed.items[-1].description
+
+
+def test_sample_dates_are_upcoming():
+    feed = RSSParser().parse_content(build_feed(NOW).decode())
+    jazz = next(item for item in feed.items if item.link.endswith("/101"))
+
+    # Dates are relative to today, across the new year too
+    schedule = parse_schedule(jazz.description, NOW)
+    assert schedule.start == (NOW + timedelta(days=3)).date()
+    assert schedule.sessions[0].start.strftime("%H:%M") == "19:30"
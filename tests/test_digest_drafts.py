@@ -0,0 +1,184 @@
+"""Tests for digests curated by moderators."""
+
+import json
+from datetime import datetime
+
+import pytest
+
+from api import digests
+from api.server import HTTPError, HTTPServer, Request
+from common.clock import FakeClock, set_clock
+from common.db.memory import MEMORY
+from common.db.models import Promotion, RSSPost
+from common.ids import new_id
+from common.rules import RuleSet
+from digest_publisher import __main__ as digest_publisher
+from digest_publisher import drafts
+
+LINKS = [f"https://t.me/city/{number}" for number in (1, 2, 3)]
+
+
+class FakePublisher:
+    default_destination = "@city"
+
+    def __init__(self, fail: bool = False):
+        self.sent = []
+        self.fail = fail
+
+    def label(self, destination):
+        return f"telegram:{destination}"
+
+    async def publish(self, message, destination):
+        if self.fail:
+            raise RuntimeError("Telegram is down")
+        self.sent.append((destination, message))
+
+
+@pytest.fixture
+def publisher(monkeypatch):
+    previous = set_clock(FakeClock(datetime(2026, 3, 1, 12, 0)))
+    publisher = FakePublisher()
+    monkeypatch.setattr(digest_publisher, "get_publisher", lambda name: publisher)
+    monkeypatch.setattr(drafts, "get_publisher", lambda name: publisher)
+    yield publisher
+    set_clock(previous)
+
+
+@pytest.fixture
+def server():
+    server = HTTPServer()
+    digests.register(server)
+    return server
+
+
+async def create_posts():
+    posts = []
+    for number, link in enumerate(LINKS, 1):
+        post = RSSPost(link=link, content=f"Концерт {number}", title=f"Концерт {number}")
+        await MEMORY.posts.create(post)
+        posts.append(post)
+    return posts
+
+
+async def create_draft():
+    await drafts.create_drafts(await create_posts(), RuleSet())
+    [draft] = await MEMORY.digest_drafts.get_by_status(["draft"])
+    return draft.id
+
+
+async def post(server, path, body=None):
+    data = json.dumps(body).encode() if body is not None else b""
+    response = await server.dispatch(Request(method="POST", path=path, body=data))
+    return json.loads(response.body)
+
+
+@pytest.mark.asyncio
+async def test_drafts_leave_out_drafted_posts(memory_storage, publisher):
+    posts = await create_posts()
+    assert await drafts.create_drafts(posts[:2], RuleSet()) == 1
+    assert await drafts.create_drafts(posts, RuleSet()) == 1
+    assert await drafts.create_drafts(posts, RuleSet()) == 0
+
+    second, first = await MEMORY.digest_drafts.get_by_status(drafts.OPEN_STATUSES)
+    assert (first.publisher, first.destination, first.links) == ("telegram", "@city", LINKS[:2])
+    assert second.links == LINKS[2:]
+    assert publisher.sent == []
+
+
+@pytest.mark.asyncio
+async def test_curate_lock_and_send(memory_storage, publisher, server):
+    draft_id = await create_draft()
+    await MEMORY.promotions.create(
+        Promotion(
+            post_link="https://t.me/shop/1",
+            sponsor="Магазин",
+            starts_at=datetime(2026, 2, 1),
+            ends_at=datetime(2026, 4, 1),
+        )
+    )
+    await MEMORY.posts.create(RSSPost(link="https://t.me/shop/1", content="Скидки"))
+
+    body = {"links": [LINKS[2], LINKS[0]], "intro": "  Выбор редакции <3  "}
+    draft = await post(server, f"/digest-drafts/{draft_id}", body)
+    assert (draft["links"], draft["intro"]) == ([LINKS[2], LINKS[0]], "Выбор редакции <3")
+
+    response = await server.dispatch(Request(method="GET", path=f"/digest-drafts/{draft_id}"))
+    data = json.loads(response.body)
+    assert [p["link"] for p in data["posts"]] == [LINKS[2], LINKS[0]]
+    preview = data["preview"]
+    assert preview.startswith("Выбор редакции &lt;3\n\n⚡ <b>Концерт 3</b>")
+    assert preview.index(LINKS[2]) < preview.index(LINKS[0])
+    assert LINKS[1] not in preview
+
+    assert (await post(server, f"/digest-drafts/{draft_id}/lock"))["status"] == "locked"
+    with pytest.raises(HTTPError) as error:
+        await post(server, f"/digest-drafts/{draft_id}", {"intro": "Поздно"})
+    assert error.value.status == 409
+
+    assert (await post(server, f"/digest-drafts/{draft_id}/send"))["status"] == "sent"
+    [(destination, message)] = publisher.sent
+    assert destination == "@city"
+    assert message.startswith("📢 <b>Реклама</b> · Магазин\nСкидки")
+    assert message.index(LINKS[2]) < message.index(LINKS[0])
+
+    # Removed posts stay unpublished for the next digest
+    published = [p.link for p in await MEMORY.posts.get_all() if p.is_published]
+    assert sorted(published) == sorted([LINKS[0], LINKS[2], "https://t.me/shop/1"])
+    assert (await MEMORY.promotions.get_all())[0].placements == 1
+    response = await server.dispatch(Request(method="GET", path="/digest-drafts"))
+    assert json.loads(response.body) == []
+
+
+@pytest.mark.asyncio
+async def test_edits_only_reorder_or_remove(memory_storage, publisher, server):
+    draft_id = await create_draft()
+    for body in (
+        {"links": [LINKS[0], "https://t.me/other/1"]},
+        {"links": [LINKS[0], LINKS[0]]},
+        {"links": LINKS[0]},
+        {"intro": ["Привет"]},
+        {"intro": "x" * (drafts.MAX_INTRO_LENGTH + 1)},
+        [],
+    ):
+        with pytest.raises(HTTPError) as error:
+            await post(server, f"/digest-drafts/{draft_id}", body)
+        assert error.value.status == 400
+
+    for path in (
+        "/digest-drafts/x",
+        "/digest-drafts/2",
+        f"/digest-drafts/{new_id()}",
+        f"/digest-drafts/{draft_id}/publish",
+    ):
+        with pytest.raises(HTTPError) as error:
+            await post(server, path, {})
+        assert error.value.status == 404
+
+    # Nothing can be sent before the draft is locked, or locked without posts
+    with pytest.raises(HTTPError) as error:
+        await post(server, f"/digest-drafts/{draft_id}/send")
+    assert error.value.status == 409
+    await post(server, f"/digest-drafts/{draft_id}", {"links": []})
+    with pytest.raises(HTTPError) as error:
+        await post(server, f"/digest-drafts/{draft_id}/lock")
+    assert error.value.status == 422
+
+    assert (await post(server, f"/digest-drafts/{draft_id}/discard"))["status"] == "discarded"
+    assert publisher.sent == []
+
+
+@pytest.mark.asyncio
+async def test_failed_send_can_be_retried(memory_storage, publisher, server):
+    draft_id = await create_draft()
+    await post(server, f"/digest-drafts/{draft_id}/lock")
+
+    publisher.fail = True
+    with pytest.raises(HTTPError) as error:
+        await post(server, f"/digest-drafts/{draft_id}/send")
+    assert error.value.status == 502
+    assert (await MEMORY.digest_drafts.get_by_id(draft_id)).status == "locked"
+    assert not any(p.is_published for p in await MEMORY.posts.get_all())
+
+    publisher.fail = False
+    assert (await post(server, f"/digest-drafts/{draft_id}/send"))["status"] == "sent"
+    assert len(publisher.sent) == 1
@@ -0,0 +1,113 @@
+"""Tests for publish embargoes."""
+
+import json
+from datetime import datetime, timedelta
+
+import pytest
+
+from common.db.memory import MEMORY
+from common.db.models import RSSPost
+from common.embargo import embargoed_until, hold_embargoed, release_time
+from common.rules import Embargo, RuleError, RuleSet, load_rules
+from digest_publisher import __main__ as digest_publisher
+
+PUBLISHED = datetime(2026, 2, 20, 10, 0)
+NOW = datetime(2026, 3, 1, 9, 0)
+
+PRESALE = RSSPost(
+    link="https://t.me/philharmonic/10",
+    content="Концерт 20 апреля в 19:00.\nСтарт продаж 5 марта в 12:00 на сайте филармонии.",
+    pub_date=PUBLISHED,
+    tags=["presale"],
+)
+
+
+def presale_rules(**extra) -> RuleSet:
+    embargo = Embargo.from_dict({"name": "presales", "when": '"presale" in post.tags', **extra})
+    return RuleSet(embargoes=[embargo])
+
+
+def test_release_time():
+    """Test phrases announcing the start of ticket sales."""
+    assert release_time("Старт продаж 5 марта в 12:00", PUBLISHED) == datetime(2026, 3, 5, 12, 0)
+    assert release_time("Билеты в продаже с 3 марта, 10:00", PUBLISHED) == datetime(
+        2026, 3, 3, 10, 0
+    )
+    assert release_time("Предпродажа стартует 1 марта", PUBLISHED) == datetime(2026, 3, 1, 0, 0)
+    # January in a December post is next year's
+    december = datetime(2026, 12, 20, 10, 0)
+    assert release_time("Продажи начнутся 10 января в 11:00", december) == datetime(
+        2027, 1, 10, 11, 0
+    )
+    assert release_time("Концерт 20 апреля в 19:00, билеты в кассе", PUBLISHED) is None
+
+
+def test_event_date_before_the_phrase_is_ignored():
+    assert release_time(PRESALE.content, PUBLISHED) == datetime(2026, 3, 5, 12, 0)
+
+
+def test_custom_pattern():
+    rules = presale_rules(pattern=r"состав\s+объявим")
+    post = RSSPost(
+        link="https://t.me/fest/1",
+        content="Состав объявим 2 марта в 18:00",
+        pub_date=PUBLISHED,
+        tags=["presale"],
+    )
+    assert embargoed_until(post, rules, NOW) == datetime(2026, 3, 2, 18, 0)
+    assert embargoed_until(PRESALE, rules, NOW) is None
+
+
+def test_embargo_ends_at_release_time():
+    rules = presale_rules()
+    assert embargoed_until(PRESALE, rules, NOW) == datetime(2026, 3, 5, 12, 0)
+    assert embargoed_until(PRESALE, rules, datetime(2026, 3, 5, 12, 0)) is None
+
+
+def test_hold_embargoed_keeps_order():
+    other = RSSPost(link="https://t.me/club/1", content="Старт продаж 5 марта", pub_date=PUBLISHED)
+    ready, held = hold_embargoed([other, PRESALE], presale_rules(), NOW)
+    assert ready == [other]
+    assert [(post.link, post.embargo_until) for post in held] == [
+        (PRESALE.link, datetime(2026, 3, 5, 12, 0))
+    ]
+    assert hold_embargoed([PRESALE], RuleSet(), NOW) == ([PRESALE], [])
+
+
+def test_load_embargoes(tmp_path):
+    path = tmp_path / "rules.json"
+    path.write_text(json.dumps({"embargoes": [{"when": '"presale" in post.tags'}]}))
+    assert load_rules(str(path)).embargoes[0].name == '"presale" in post.tags'
+
+    path.write_text(json.dumps({"embargoes": [{"when": "post.tags", "pattern": "("}]}))
+    with pytest.raises(RuleError):
+        load_rules(str(path))
+
+
+@pytest.fixture
+def clock_start():
+    return NOW
+
+
+@pytest.mark.asyncio
+async def test_held_post_goes_out_after_the_window(
+    memory_storage, fake_clock, tmp_path, monkeypatch
+):
+    path = tmp_path / "rules.json"
+    path.write_text(json.dumps({"embargoes": [{"when": '"presale" in post.tags'}]}))
+    monkeypatch.setenv("RULES_FILE", str(path))
+    await MEMORY.posts.create(PRESALE)
+
+    # Held by the priority lane, with its release time stored
+    assert await digest_publisher.publish_now([PRESALE.link]) == {"published_count": 0}
+    stored = await MEMORY.posts.get_by_link(PRESALE.link)
+    assert stored.embargo_until == datetime(2026, 3, 5, 12, 0)
+
+    # Released 13 days after it was posted, well past the 7 days a digest looks back
+    fake_clock.advance(timedelta(days=5))
+    end_date = fake_clock.now()
+    start_date = end_date - timedelta(days=7)
+    assert PRESALE.pub_date < start_date
+    posts = await digest_publisher.get_unpublished_posts(start_date, end_date)
+    assert [post.link for post in posts] == [PRESALE.link]
+    assert hold_embargoed(posts, load_rules(), end_date)[1] == []
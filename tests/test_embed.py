@@ -0,0 +1,128 @@
+"""Tests for the embeddable event widget."""
+
+import json
+from datetime import date, datetime
+from decimal import Decimal
+from types import SimpleNamespace
+
+import pytest
+
+from api import embed
+from api.embed import WidgetParams, sign, upcoming_events, verify
+from api.public import ResponseCache
+from api.server import HTTPError, HTTPServer, Request
+from common.db.models import RSSPost
+
+SECRET = "embed-secret"
+
+
+def event(number: int, day: date, **fields) -> RSSPost:
+    return RSSPost(
+        link=f"https://t.me/club/{number}",
+        content=f"Концерт {number}",
+        pub_date=datetime(2026, 3, 1, 12, 0),
+        event_start=day,
+        event_end=day,
+        is_published=True,
+        **fields,
+    )
+
+
+POSTS = [
+    event(1, date(2026, 3, 20), tags=["concert"], price_min=Decimal(500), price_max=Decimal(500)),
+    event(2, date(2026, 3, 5), tags=["concert"]),
+    event(3, date(2026, 3, 12), tags=["lecture"]),
+    event(4, date(2026, 3, 15), tags=["concert"], price_min=Decimal(0), price_max=Decimal(0)),
+    RSSPost(link="https://t.me/club/5", content="Без даты", tags=["concert"]),
+]
+
+
+@pytest.fixture
+def clock_start():
+    return datetime(2026, 3, 10, 9, 0)
+
+
+def test_tokens_are_signed():
+    token = sign(WidgetParams(tag="concert", limit=5, title="Концерты"), SECRET)
+    assert verify(token, SECRET) == WidgetParams(tag="concert", limit=5, title="Концерты")
+
+    payload, _, signature = token.partition(".")
+    other = sign(WidgetParams(tag="concert", limit=50), SECRET).partition(".")[0]
+    assert verify(f"{other}.{signature}", SECRET) is None
+    assert verify(token, "other-secret") is None
+    assert verify(token, "") is None
+    assert verify("garbage", SECRET) is None
+    assert verify("токен.подпись", SECRET) is None
+
+
+def test_widget_params_validation():
+    for data in (
+        [],
+        {"limit": 0},
+        {"limit": 51},
+        {"limit": "5"},
+        {"online": "yes"},
+        {"max_price": -1},
+        {"title": "x" * 101},
+        {"sort": "price"},
+    ):
+        with pytest.raises(ValueError):
+            WidgetParams.from_dict(data)
+    assert WidgetParams.from_dict({"max_price": 0}).max_price == 0
+
+
+def test_upcoming_events(fake_clock):
+    def links(**params):
+        return [post.link[-1] for post in upcoming_events(POSTS, WidgetParams(**params))]
+
+    assert links() == ["3", "4", "1"]
+    assert links(tag="concert") == ["4", "1"]
+    assert links(tag="concert", limit=1) == ["4"]
+    assert links(max_price=0) == ["4"]
+    assert links(channel="theatre") == []
+
+
+@pytest.mark.asyncio
+async def test_widget_routes(fake_clock, monkeypatch):
+    monkeypatch.setattr(embed.api_settings, "embed_secret", SECRET)
+    monkeypatch.setattr(embed.api_settings, "embed_base_url", "https://api.example.com/")
+    monkeypatch.setattr(embed.api_settings, "site_enabled", False)
+    monkeypatch.setattr(embed, "responses", ResponseCache(60))
+
+    async def load_events():
+        return SimpleNamespace(posts=POSTS)
+
+    monkeypatch.setattr(embed, "load_events", load_events)
+    server = HTTPServer()
+    embed.register_public(server)
+    embed.register(server)
+
+    body = json.dumps({"tag": "concert", "title": "<Концерты>"}).encode()
+    response = await server.dispatch(Request(method="POST", path="/embed-tokens", body=body))
+    data = json.loads(response.body)
+    token = data["token"]
+    assert data["script"] == (
+        f'<script src="https://api.example.com/v1/embed.js?token={token}" async></script>'
+    )
+    assert 'title="&lt;Концерты&gt;"' in data["iframe"]
+
+    page = await server.dispatch(Request(method="GET", path="/v1/embed", query={"token": token}))
+    html = page.body.decode()
+    assert page.content_type.startswith("text/html")
+    assert "<h2>&lt;Концерты&gt;</h2>" in html
+    assert html.index("https://t.me/club/4") < html.index("https://t.me/club/1")
+    assert "https://t.me/club/3" not in html
+
+    script = await server.dispatch(
+        Request(method="GET", path="/v1/embed.js", query={"token": token})
+    )
+    assert f'"https://api.example.com/v1/embed?token={token}"' in script.body.decode()
+
+    for query in ({}, {"token": token + "x"}):
+        with pytest.raises(HTTPError) as error:
+            await server.dispatch(Request(method="GET", path="/v1/embed", query=query))
+        assert error.value.status == 403
+
+    with pytest.raises(HTTPError) as error:
+        await server.dispatch(Request(method="POST", path="/embed-tokens", body=b'{"limit": 0}'))
+    assert error.value.status == 400
@@ -0,0 +1,57 @@
+"""Tests for finding people and organizations in posts."""
+
+from common.db.models import RSSPost
+from common.entities import Entities, extract_entities
+from common.pages import SiteSettings, event_json_ld
+from rss_reader.core.extraction import extract_fields
+
+
+def test_extract_performers():
+    """Test names after roles, lists of names and groups."""
+    assert extract_entities("Спикер: Анна Смирнова. Вход свободный").performers == [
+        "Анна Смирнова"
+    ]
+    assert extract_entities("В субботу выступят Иван Петров и Ольга Белова").performers == [
+        "Иван Петров",
+        "Ольга Белова",
+    ]
+    assert extract_entities("Лекцию прочитает профессор И.И. Сидоров").performers == [
+        "И.И. Сидоров"
+    ]
+    assert extract_entities("Ведущий вечера — Алексей Римский-Корсаков").performers == [
+        "Алексей Римский-Корсаков"
+    ]
+    assert extract_entities("Speakers: John Smith, Jane Doe and John Smith").performers == [
+        "John Smith",
+        "Jane Doe",
+    ]
+    assert extract_entities("На сцене выступит группа «Кино»").performers == ["Кино"]
+    # Capitalized words without a role aren't taken for names
+    assert extract_entities("Концерт Петра Ильича Чайковского в Москве") == Entities()
+    assert extract_entities("Гости вечера узнают много нового") == Entities()
+
+
+def test_extract_organizations():
+    """Test quoted names of institutions and names after organizer phrases."""
+    entities = extract_entities(
+        "Организатор — Яндекс. Лекция пройдёт в музее «Гараж» при поддержке фонда «Культура»"
+    )
+    assert entities.organizations == ["Яндекс", "Гараж", "Культура"]
+    assert extract_entities("Выставка в ДК «Октябрь», вход бесплатный").organizations == [
+        "Октябрь"
+    ]
+    assert extract_entities('Мастер-класс проводит студия "Глина"').organizations == ["Глина"]
+    assert extract_entities("Концерт в парке Горького").organizations == []
+
+
+def test_entities_are_stored_and_published():
+    """Test that extracted names end up on posts and in structured data."""
+    text = "Спикер: Анна Смирнова. Организатор — «Гараж»"
+    fields = extract_fields(text)
+    assert (fields["performers"], fields["organizations"]) == (["Анна Смирнова"], ["Гараж"])
+    assert extract_fields("Концерт в субботу")["performers"] is None
+
+    post = RSSPost(link="https://t.me/mediarzn/1", content=text, performers=["Анна Смирнова"])
+    assert event_json_ld(post, SiteSettings())["performer"] == [
+        {"@type": "Person", "name": "Анна Смирнова"}
+    ]
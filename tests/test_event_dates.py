@@ -0,0 +1,162 @@
+"""Tests for event date parsing and multi-day rendering."""
+
+from datetime import date, datetime, time
+
+from common.db.models import RSSPost
+from common.event_dates import Deadline, EventSchedule, Session, parse_deadline, parse_schedule
+from common.ics import render_event
+from common.pages import SiteSettings, event_json_ld, event_when, render_event_page
+
+PUBLISHED = datetime(2026, 2, 20, 10, 0)
+
+
+def test_parse_single_date_with_time():
+    """Test a single day with a start time."""
+    schedule = parse_schedule("Концерт 15 марта в 19:00, вход 500 ₽", PUBLISHED)
+    assert schedule == EventSchedule(
+        start=date(2026, 3, 15),
+        end=date(2026, 3, 15),
+        sessions=[Session(date(2026, 3, 15), time(19))],
+    )
+
+
+def test_parse_ranges_and_lists():
+    """Test day ranges, ranges across months and lists of days."""
+    festival = parse_schedule("Фестиваль 5–7 марта, начало в 12:00", PUBLISHED)
+    assert (festival.start, festival.end) == (date(2026, 3, 5), date(2026, 3, 7))
+    assert [session.start for session in festival.sessions] == [time(12)] * 3
+
+    crossing = parse_schedule("с 30 марта по 2 апреля", PUBLISHED)
+    assert (crossing.start, crossing.end) == (date(2026, 3, 30), date(2026, 4, 2))
+    assert crossing.is_continuous and not crossing.has_times
+
+    days = parse_schedule("Спектакль 5, 6 и 8 марта", PUBLISHED)
+    assert [session.day.day for session in days.sessions] == [5, 6, 8]
+    assert not days.is_continuous
+
+
+def test_parse_per_day_times():
+    """Test that each date takes the time mentioned after it."""
+    schedule = parse_schedule("Лекция 14 мая в 18:30, повтор 15 мая в 20:00", PUBLISHED)
+    assert schedule.sessions == [
+        Session(date(2026, 5, 14), time(18, 30)),
+        Session(date(2026, 5, 15), time(20)),
+    ]
+
+
+def test_parse_year_and_long_ranges():
+    """Test year inference, long exhibitions and texts without dates."""
+    december = datetime(2026, 12, 20)
+    assert parse_schedule("Ёлка 10 января", december).start == date(2027, 1, 10)
+    new_year = parse_schedule("с 28 декабря по 3 января", december)
+    assert (new_year.start, new_year.end) == (date(2026, 12, 28), date(2027, 1, 3))
+
+    exhibition = parse_schedule("Выставка с 1 марта по 30 июня", PUBLISHED)
+    assert (exhibition.start, exhibition.end, exhibition.sessions) == (
+        date(2026, 3, 1),
+        date(2026, 6, 30),
+        [],
+    )
+
+    assert parse_schedule("Вход 500 р, 18+, в 19:00", PUBLISHED) is None
+    assert parse_schedule("31 апреля", PUBLISHED) is None
+
+
+def schedule_post(text: str) -> RSSPost:
+    """Post with the schedule parsed from its text, as the RSS Reader stores it."""
+    schedule = parse_schedule(text, PUBLISHED)
+    return RSSPost(
+        link="https://t.me/mediarzn/1",
+        content=text,
+        pub_date=PUBLISHED,
+        event_start=schedule.start,
+        event_end=schedule.end,
+        event_sessions=schedule.sessions_json(),
+    )
+
+
+def test_schedule_round_trip_and_formatting():
+    """Test stored sessions and the human-readable dates."""
+    post = schedule_post("Фестиваль 5–7 марта в 12:00")
+    assert EventSchedule.from_post(post) == parse_schedule(post.content, PUBLISHED)
+    assert event_when(post) == "5–7 марта 2026, 12:00"
+    assert event_when(schedule_post("с 30 марта по 2 апреля")) == "30 марта – 2 апреля 2026"
+    assert event_when(RSSPost(link=post.link, content="", pub_date=PUBLISHED)) == (
+        "20 февраля 2026, 10:00"
+    )
+
+    data = event_json_ld(post, SiteSettings())
+    assert (data["startDate"], data["endDate"]) == ("2026-03-05T12:00:00", "2026-03-07")
+
+
+def test_ics_multi_day_events():
+    """Test per-session VEVENTs and all-day ranges."""
+    sessions = render_event(schedule_post("5–6 марта в 19:00"))
+    assert sessions.count("BEGIN:VEVENT") == 2
+    assert "UID:mediarzn-1-20260305@event-platform" in sessions
+    assert "DTSTART:20260305T190000" in sessions
+    assert "DTSTART:20260306T190000" in sessions
+
+    lines = render_event(schedule_post("Выставка с 1 марта по 30 июня"))
+    assert lines.count("BEGIN:VEVENT") == 1
+    assert "DTSTART;VALUE=DATE:20260301" in lines
+    assert "DTEND;VALUE=DATE:20260701" in lines
+
+
+def test_parse_deadlines():
+    """Test registration and application deadlines, which aren't event dates."""
+    text = "Конференция 28 марта в 10:00. Регистрация до 20 марта"
+    assert parse_deadline(text, PUBLISHED) == Deadline(date(2026, 3, 20), "registration")
+    assert parse_schedule(text, PUBLISHED).sessions == [Session(date(2026, 3, 28), time(10, 0))]
+    # Only a deadline, no event dates
+    assert parse_schedule("Зарегистрируйтесь до 5 марта по ссылке", PUBLISHED) is None
+
+    assert parse_deadline("Заявки принимаются по 15.03.2026", PUBLISHED) == Deadline(
+        date(2026, 3, 15), "applications"
+    )
+    assert parse_deadline("Приём заявок продлится до 1 апреля включительно", PUBLISHED) == (
+        Deadline(date(2026, 4, 1), "applications")
+    )
+    assert parse_deadline("Call for papers! Дедлайн — 10.03", PUBLISHED) == Deadline(
+        date(2026, 3, 10), "applications"
+    )
+    # The earliest of several
+    assert parse_deadline(
+        "Регистрация участников до 25 марта, подать заявку на доклад до 1 марта", PUBLISHED
+    ) == Deadline(date(2026, 3, 1), "applications")
+    assert parse_deadline("Регистрация открыта. Концерт 20 марта", PUBLISHED) is None
+    assert parse_deadline("Выставка до 30 марта", PUBLISHED) is None
+    assert parse_deadline("Заявки до 31.02", PUBLISHED) is None
+
+
+def test_deadline_on_event_page_and_calendar():
+    """Test that the deadline is shown and gets a calendar entry and reminder of its own."""
+    post = RSSPost(
+        link="https://t.me/mediarzn/9",
+        content="Конференция",
+        pub_date=PUBLISHED,
+        event_start=date(2026, 3, 28),
+        deadline=date(2026, 3, 20),
+        deadline_kind="registration",
+    )
+    html = render_event_page(post, SiteSettings())
+    assert '<p class="notice">⏳ Регистрация до 20 марта 2026</p>' in html
+
+    lines = render_event(post)
+    assert lines.count("BEGIN:VEVENT") == 2
+    deadline = lines[lines.index("UID:mediarzn-9-deadline@event-platform") - 1 :]
+    assert deadline[3:6] == [
+        "DTSTART;VALUE=DATE:20260320",
+        "DTEND;VALUE=DATE:20260321",
+        "SUMMARY:Регистрация до 20 марта 2026: Конференция",
+    ]
+    assert deadline[-6:] == [
+        "BEGIN:VALARM",
+        "ACTION:DISPLAY",
+        "DESCRIPTION:Регистрация до 20 марта 2026",
+        "TRIGGER:-P1D",
+        "END:VALARM",
+        "END:VEVENT",
+    ]
+    # The event itself has no alarm
+    assert lines.index("BEGIN:VALARM") > lines.index("UID:mediarzn-9-deadline@event-platform")
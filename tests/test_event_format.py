@@ -0,0 +1,33 @@
+"""Tests for online/offline event classification."""
+
+from common.db.models import RSSPost
+from common.event_format import classify_format, is_offline, is_online
+from common.pages import SiteSettings, event_json_ld, render_event_page
+
+
+def test_classify_format():
+    """Test online, offline and hybrid hints."""
+    assert classify_format("Вебинар по ссылке https://zoom.us/j/123") == "online"
+    assert classify_format("Трансляция на youtu.be/abc") == "online"
+    assert classify_format("Концерт в клубе, ул. Ленина, д. 5") == "offline"
+    assert classify_format("Лекция на ул. Есенина и онлайн-трансляция") == "hybrid"
+    assert classify_format("Концерт в 19:00") is None
+    assert classify_format("") is None
+
+
+def test_is_online_offline():
+    """Test that hybrid events match both filters."""
+    assert is_online("online") and is_online("hybrid") and not is_online("offline")
+    assert is_offline("offline") and is_offline("hybrid") and not is_offline("online")
+    assert not is_online(None) and not is_offline(None)
+
+
+def test_format_on_event_page():
+    """Test the attendance mode in structured data and the page meta line."""
+    post = RSSPost(link="https://t.me/mediarzn/1", content="Лекция", event_format="online")
+    data = event_json_ld(post, SiteSettings())
+    assert data["eventAttendanceMode"] == "https://schema.org/OnlineEventAttendanceMode"
+    assert '<p class="meta">онлайн · mediarzn</p>' in render_event_page(post, SiteSettings())
+
+    unknown = RSSPost(link=post.link, content="")
+    assert "eventAttendanceMode" not in event_json_ld(unknown, SiteSettings())
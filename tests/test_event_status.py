@@ -0,0 +1,35 @@
+"""Tests for cancelled and postponed events."""
+
+from datetime import datetime
+
+from common.db.models import RSSPost
+from common.event_status import detect_status
+from common.ics import render_event
+from common.pages import SiteSettings, event_json_ld, render_event_page
+
+
+def test_detect_status():
+    """Test cancellation and postponement phrases."""
+    assert detect_status("Концерт отменяется по техническим причинам") == "cancelled"
+    assert detect_status("Спектакль 12 марта не состоится") == "cancelled"
+    assert detect_status("Лекция переносится на 20 марта") == "postponed"
+    assert detect_status("Концерт перенесён, билеты действительны") == "postponed"
+    assert detect_status("Отменный концерт в пятницу") is None
+    assert detect_status("Концерт в пятницу") is None
+
+
+def test_cancelled_event_rendering():
+    """Test that cancelled events are marked on pages and in calendars."""
+    post = RSSPost(
+        link="https://t.me/mediarzn/1",
+        content="Концерт",
+        pub_date=datetime(2026, 3, 5, 19, 0),
+        event_status="cancelled",
+    )
+
+    assert event_json_ld(post, SiteSettings())["eventStatus"] == "https://schema.org/EventCancelled"
+    assert '<p class="notice">❌ Событие отменено</p>' in render_event_page(post, SiteSettings())
+    assert "STATUS:CANCELLED" in render_event(post)
+
+    post.event_status = None
+    assert "STATUS:CONFIRMED" in render_event(post)
@@ -0,0 +1,64 @@
+"""Tests for external subprocess sources."""
+
+import sys
+
+import pytest
+
+from rss_reader.core.external import ExternalSource, ExternalSourceError, parse_output
+
+
+def test_parse_output():
+    """Test parsing JSON Lines into cleaned RSS items."""
+    output = (
+        '{"link": "https://example.com/1", "content": "<p>Hello &amp; welcome</p>",'
+        ' "pub_date": "2026-01-10T10:00:00Z"}\n'
+        "\n"
+        '{"link": "https://example.com/2", "content": "<img src=\\"https://x/a.jpg\\"/>Text"}\n'
+    )
+
+    items = parse_output(output)
+
+    assert [item.link for item in items] == ["https://example.com/1", "https://example.com/2"]
+    assert items[0].description == "Hello & welcome"
+    assert items[0].pub_date == "2026-01-10T10:00:00Z"
+    assert items[1].media_urls == ["https://x/a.jpg"]
+
+
+def test_parse_output_rejects_invalid_lines():
+    """Test that malformed lines fail with the line number."""
+    with pytest.raises(ExternalSourceError, match="Line 2"):
+        parse_output('{"link": "https://example.com/1", "content": "ok"}\nnot json\n')
+
+    with pytest.raises(ExternalSourceError, match="link"):
+        parse_output('{"content": "no link"}\n')
+
+
+@pytest.mark.asyncio
+async def test_fetch_runs_command():
+    """Test running a real subprocess source."""
+    script = 'print(\'{"link": "https://example.com/1", "content": "From subprocess"}\')'
+    source = ExternalSource(name="test", command=[sys.executable, "-c", script])
+
+    items = await source.fetch()
+
+    assert len(items) == 1
+    assert items[0].description == "From subprocess"
+
+
+@pytest.mark.asyncio
+async def test_fetch_failures():
+    """Test non-zero exit status, timeouts and output that is not UTF-8."""
+    failing = ExternalSource(name="fail", command=[sys.executable, "-c", "exit(3)"])
+    with pytest.raises(ExternalSourceError, match="status 3"):
+        await failing.fetch()
+
+    slow = ExternalSource(
+        name="slow", command=[sys.executable, "-c", "import time; time.sleep(5)"], timeout=1
+    )
+    with pytest.raises(ExternalSourceError, match="Timed out"):
+        await slow.fetch()
+
+    script = "import sys; sys.stdout.buffer.write(b'{\"link\": \"https://example.com/\\xff\"}')"
+    garbled = ExternalSource(name="garbled", command=[sys.executable, "-c", script])
+    with pytest.raises(ExternalSourceError, match="invalid UTF-8 at byte 30"):
+        await garbled.fetch()
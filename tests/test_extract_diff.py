@@ -0,0 +1,58 @@
+"""Tests for diffing extraction output against a baseline."""
+
+from datetime import datetime, timedelta
+
+import pytest
+
+from common.clock import FakeClock, set_clock
+from common.db.memory import MEMORY
+from common.db.models import RSSPost
+from rss_reader import extract_diff
+from rss_reader.core.extraction import diff_extractions, extract_fields, load_baseline
+
+
+@pytest.mark.asyncio
+async def test_extraction_diff(memory_storage, tmp_path, monkeypatch):
+    now = datetime(2026, 3, 1, 12, 0)
+    previous = set_clock(FakeClock(now))
+    try:
+        texts = {
+            "https://t.me/jazz/1": "Джаз в парке, билеты 500 ₽, вход по регистрации",
+            "https://t.me/jazz/2": "Онлайн-лекция о джазе, вход свободный",
+            "https://t.me/jazz/3": "Концерт перенесён",
+        }
+        for link, text in texts.items():
+            await MEMORY.posts.create(
+                RSSPost(link=link, content=text, pub_date=now - timedelta(days=1))
+            )
+        assert await extract_diff.save(tmp_path, days=30, limit=100) == 3
+        baseline = load_baseline(tmp_path)
+        assert baseline["https://t.me/jazz/1"]["price_min"] == "500"
+        assert baseline["https://t.me/jazz/1"]["registration_required"] is True
+
+        # Unchanged code extracts the same
+        diff = diff_extractions(baseline, await extract_diff.current_extractions(list(baseline)))
+        assert (diff.compared, diff.changes, diff.missing) == (3, {}, [])
+
+        def changed_extractor(text, pub_date=None):
+            fields = extract_fields(text, pub_date)
+            if "Онлайн" in text:
+                fields["event_format"] = "offline"
+            fields["price_currency"] = None
+            return fields
+
+        monkeypatch.setattr(extract_diff, "extract_fields", changed_extractor)
+        await MEMORY.posts.delete("https://t.me/jazz/3")
+        diff = diff_extractions(baseline, await extract_diff.current_extractions(list(baseline)))
+        assert diff.compared == 2
+        assert diff.missing == ["https://t.me/jazz/3"]
+        assert sorted(diff.changes) == ["event_format", "price_currency"]
+        assert diff.changes["event_format"] == [("https://t.me/jazz/2", "online", "offline")]
+        assert diff.changed_posts == 2
+
+        lines = extract_diff.format_diff(diff, examples=1)
+        assert lines[:2] == ["2 of 2 posts changed", "1 baseline posts are no longer stored"]
+        assert "event_format: 1 changed" in lines
+        assert "  https://t.me/jazz/2: 'online' -> 'offline'" in lines
+    finally:
+        set_clock(previous)
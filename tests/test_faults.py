@@ -0,0 +1,85 @@
+"""Tests for fault injection."""
+
+from datetime import datetime
+
+import pytest
+import requests
+
+from common.clock import FakeClock, set_clock
+from common.faults import Fault, FaultInjector, InjectedFault
+
+
+class FakeRequest:
+    def __init__(self, url):
+        self.url = url
+
+
+class FakeResponse:
+    status_code = 200
+    _content = b"<rss>0123456789</rss>"
+
+    @property
+    def content(self):
+        return self._content
+
+
+def fake_send(session, request, **kwargs):
+    return FakeResponse()
+
+
+def test_fault_entries_are_parsed():
+    injector = FaultInjector("http=delay:3s@20%, http[bridge.example]=error:503 ,db=partial")
+    assert injector.faults == [
+        Fault("http", "delay", arg=3.0, rate=20.0),
+        Fault("http", "error", scope="bridge.example", arg=503.0),
+        Fault("db", "partial"),
+    ]
+    assert injector.faults[1].describe() == "http[bridge.example]=error:503@100%"
+    assert injector.enabled
+    assert not FaultInjector("").enabled
+
+    for spec in ("smtp=error", "http=explode", "db=error@150%", "http=delay:soon"):
+        with pytest.raises(ValueError):
+            FaultInjector(spec)
+
+
+def test_http_faults():
+    send = FaultInjector("http[bridge.example]=error:503,http[other.example]=error").wrap_send(
+        fake_send
+    )
+    response = send(None, FakeRequest("https://bridge.example/feed"))
+    assert response.status_code == 503
+    with pytest.raises(requests.ConnectionError, match="Injected fault"):
+        send(None, FakeRequest("https://other.example/feed"))
+    assert send(None, FakeRequest("https://feeds.example/")).content == b"<rss>0123456789</rss>"
+
+    partial = FaultInjector("http=partial:0.25").wrap_send(fake_send)
+    assert partial(None, FakeRequest("https://feeds.example/")).content == b"<rss>"
+
+
+def test_rates_are_repeatable_with_a_seed():
+    def draws():
+        injector = FaultInjector("http=error@30%", seed=7)
+        return [bool(injector.pick("http", "bridge.example")) for _ in range(200)]
+
+    assert draws() == draws()
+    assert 30 < sum(draws()) < 90
+
+
+@pytest.mark.asyncio
+async def test_db_faults():
+    fake = FakeClock(datetime(2026, 3, 1, 12, 0))
+    previous = set_clock(fake)
+    try:
+        injector = FaultInjector("db=delay:2,db[fetch]=partial,db[execute]=error")
+
+        async def rows():
+            return [1, 2, 3, 4]
+
+        assert await injector.call_db("fetch", rows) == [1, 2]
+        assert await injector.call_db("fetchrow", rows) == [1, 2, 3, 4]
+        with pytest.raises(InjectedFault):
+            await injector.call_db("execute", rows)
+        assert fake.sleeps == [2, 2, 2]
+    finally:
+        set_clock(previous)
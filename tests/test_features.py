@@ -0,0 +1,50 @@
+"""Tests for feature flags."""
+
+import pytest
+
+from common.features import FeatureFlags, parse_value
+
+
+def test_parse_value():
+    """Test switch and percentage values."""
+    assert parse_value("on") == 100
+    assert parse_value("False") == 0
+    assert parse_value("25%") == 25
+
+    with pytest.raises(ValueError):
+        parse_value("sometimes")
+
+    with pytest.raises(ValueError):
+        parse_value("150%")
+
+
+def test_default_when_not_configured():
+    """Test that unknown flags fall back to the default."""
+    flags = FeatureFlags("")
+    assert flags.is_enabled("llm_summary") is False
+    assert flags.is_enabled("llm_summary", default=True) is True
+
+
+def test_scoped_override_takes_precedence():
+    """Test that scoped entries override the global value."""
+    flags = FeatureFlags("llm_summary=off, llm_summary[mediarzn]=on")
+    assert flags.is_enabled("llm_summary", scope="mediarzn") is True
+    assert flags.is_enabled("llm_summary", scope="other") is False
+    assert flags.is_enabled("llm_summary") is False
+
+
+def test_percentage_rollout_is_stable_and_monotonic():
+    """Test that rollouts are deterministic and grow with the percentage."""
+    scopes = [f"channel_{i}" for i in range(200)]
+    at_10 = {s for s in scopes if FeatureFlags("ocr=10%").is_enabled("ocr", scope=s)}
+    at_50 = {s for s in scopes if FeatureFlags("ocr=50%").is_enabled("ocr", scope=s)}
+
+    assert at_10 <= at_50
+    assert 0 < len(at_10) < len(at_50) < len(scopes)
+    assert at_10 == {s for s in scopes if FeatureFlags("ocr=10%").is_enabled("ocr", scope=s)}
+
+
+def test_invalid_entry():
+    """Test that malformed entries are rejected."""
+    with pytest.raises(ValueError):
+        FeatureFlags("no-value")
@@ -0,0 +1,118 @@
+"""Tests for rendering feeds."""
+
+from datetime import datetime
+
+from common.db.models import RSSPost
+from common.feed_writer import events_channel, render_atom, render_rss
+from common.models.feed import PodcastEpisode, RSSChannel, RSSItem
+
+CHANNEL = RSSChannel(
+    title="Афиша & друзья",
+    link="https://events.example.com/",
+    description="Концерты и лекции",
+    language="ru",
+    items=[
+        RSSItem(
+            link="https://events.example.com/events/mediarzn-1.html",
+            title="Концерт «Кино» & друзья",
+            description="Концерт «Кино» & друзья\nВход свободный",
+            pub_date="Sat, 14 Feb 2026 19:30:00 -0000",
+            media_urls=["https://cdn.example.com/poster.jpg"],
+            categories=["concert", "music"],
+            guid="https://t.me/mediarzn/1",
+            author="mediarzn",
+            comments="https://t.me/mediarzn/1?comment=1",
+        ),
+        RSSItem(
+            link="https://podcast.example/12",
+            description="Выпуск о фестивале",
+            pub_date="2026-02-13T10:00:00",
+            media_urls=["https://podcast.example/12.jpg"],
+            guid="episode-12",
+            podcast=PodcastEpisode(
+                duration=3723,
+                image="https://podcast.example/12.jpg",
+                episode=12,
+                explicit=False,
+                audio_url="https://podcast.example/12.mp3",
+            ),
+        ),
+    ],
+)
+
+
+def test_rss_round_trip():
+    """Test that the parser reads a rendered RSS feed back into the same items."""
+    from rss_reader.core.parser import RSSParser
+
+    body = render_rss(CHANNEL, "https://api.example.com/v1/events.rss")
+    assert body.startswith(b"<?xml version='1.0' encoding='utf-8'?>\n<rss")
+    assert b'<atom:link href="https://api.example.com/v1/events.rss" rel="self"' in body
+
+    feed = RSSParser().parse_content(body)
+    assert (feed.title, feed.link, feed.language) == (CHANNEL.title, CHANNEL.link, "ru")
+    first, second = feed.items
+    assert first == CHANNEL.items[0]
+    # Dates are rewritten as RFC 822
+    assert second.pub_date == "Fri, 13 Feb 2026 10:00:00 -0000"
+    assert second.podcast == CHANNEL.items[1].podcast
+    assert second.media_urls == ["https://podcast.example/12.jpg"]
+    assert (second.guid, second.guid_is_permalink) == ("episode-12", False)
+
+
+def test_atom_round_trip():
+    """Test that the parser reads a rendered Atom feed back into the same items."""
+    from rss_reader.core.parser import RSSParser
+
+    body = render_atom(CHANNEL, "https://api.example.com/v1/events.atom")
+    assert b'<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="ru">' in body
+    assert b"<id>https://api.example.com/v1/events.atom</id>" in body
+    assert b"<updated>2026-02-14T19:30:00-00:00</updated>" in body
+
+    feed = RSSParser().parse_content(body)
+    assert (feed.title, feed.link, feed.description) == (
+        CHANNEL.title,
+        CHANNEL.link,
+        CHANNEL.description,
+    )
+    first, second = feed.items
+    expected = CHANNEL.items[0]
+    assert (first.link, first.title, first.description) == (
+        expected.link,
+        expected.title,
+        expected.description,
+    )
+    assert (first.guid, first.author, first.comments) == (
+        expected.guid,
+        expected.author,
+        expected.comments,
+    )
+    assert first.categories == expected.categories
+    assert first.pub_date == "2026-02-14T19:30:00-00:00"
+    # Entries need a title; without one it is taken from the description
+    assert second.title == "Выпуск о фестивале"
+
+
+def test_events_channel():
+    """Test that stored events become items linking to their pages."""
+    post = RSSPost(
+        link="https://t.me/mediarzn/7",
+        content="Концерт в субботу\n\nВход свободный",
+        pub_date=datetime(2026, 2, 14, 19, 30),
+        tags=["concert"],
+        media='["https://cdn.example.com/poster.jpg"]',
+    )
+
+    [item] = events_channel([post], "Афиша", "https://events.example.com").items
+    assert item.link == "https://events.example.com/events/mediarzn-7.html"
+    assert (item.title, item.guid, item.author) == (
+        "Концерт в субботу",
+        "https://t.me/mediarzn/7",
+        "mediarzn",
+    )
+    assert item.pub_date == "Sat, 14 Feb 2026 19:30:00 -0000"
+    assert item.categories == ["concert"]
+    assert item.media_urls == ["https://cdn.example.com/poster.jpg"]
+
+    [item] = events_channel([post], "Афиша").items
+    assert item.link == "https://t.me/mediarzn/7"
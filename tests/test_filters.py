@@ -0,0 +1,166 @@
+"""Tests for user-defined WASM filters.
+
+A Python interpreter stands in for the WASM runtime, so the "modules" here are
+small Python scripts speaking the same stdin/stdout protocol. One test runs a
+real module with the default runtime command when wasmtime is installed.
+"""
+
+import shutil
+import sys
+
+import pytest
+
+from common.models.feed import RSSItem
+from common.rules import FilterRule, RuleSet
+from rss_reader.core.filters import FilterError, RuleFilter, load_filters, parse_decision
+
+DROP_ADS = """
+import json, sys
+post = json.load(sys.stdin)
+if "#ad" in post["content"]:
+    print(json.dumps({"action": "drop"}))
+else:
+    print(json.dumps({"action": "keep", "tags": ["checked"]}))
+"""
+
+TAG_CONCERTS = """
+import json, sys
+post = json.load(sys.stdin)
+tags = ["concert"] if "concert" in post["content"] else []
+print(json.dumps({"action": "keep", "tags": tags}))
+"""
+
+CRASH = "raise SystemExit(3)"
+
+SLEEP = "import time; time.sleep(5)"
+
+DROP_ALL = '{"action": "drop", "tags": ["wasm"]}'
+
+# WASI module in text format (wasmtime compiles it like a binary one) printing DROP_ALL
+DROP_ALL_WAT = f"""
+(module
+  (import "wasi_snapshot_preview1" "fd_write"
+    (func $fd_write (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 16) "{DROP_ALL.replace('"', '\\"')}")
+  (func (export "_start")
+    (i32.store (i32.const 0) (i32.const 16))
+    (i32.store (i32.const 4) (i32.const {len(DROP_ALL)}))
+    (drop (call $fd_write (i32.const 1) (i32.const 0) (i32.const 1) (i32.const 8)))))
+"""
+
+
+@pytest.fixture
+def filters_dir(tmp_path, monkeypatch):
+    """Filters directory with one global and one channel-scoped filter."""
+    (tmp_path / "no_ads.wasm").write_text(DROP_ADS)
+    (tmp_path / "mediarzn").mkdir()
+    (tmp_path / "mediarzn" / "concerts.wasm").write_text(TAG_CONCERTS)
+    monkeypatch.setenv("FILTER_RUNTIME_COMMAND", f"{sys.executable} {{module}}")
+    return tmp_path
+
+
+def test_parse_decision():
+    """Test parsing keep/drop decisions and tags."""
+    decision = parse_decision('{"action": "keep", "tags": ["a", "b"]}')
+    assert decision.keep is True
+    assert decision.tags == ["a", "b"]
+
+    assert parse_decision('{"action": "drop"}').keep is False
+
+    with pytest.raises(FilterError):
+        parse_decision("not json")
+    with pytest.raises(FilterError):
+        parse_decision('{"action": "maybe"}')
+
+
+def test_load_filters_scopes(filters_dir):
+    """Test that subdirectories become per-source filters."""
+    chain = load_filters(str(filters_dir))
+
+    assert len(chain) == 2
+    assert [f.name for f in chain.for_source("mediarzn")] == ["no_ads", "concerts"]
+    assert [f.name for f in chain.for_source("other")] == ["no_ads"]
+
+
+def test_load_filters_not_configured(monkeypatch):
+    """Test that no directory means no filters."""
+    monkeypatch.delenv("FILTERS_DIR", raising=False)
+    assert len(load_filters()) == 0
+
+
+@pytest.mark.asyncio
+async def test_apply_keeps_and_tags(filters_dir):
+    """Test that tags from all filters are merged."""
+    chain = load_filters(str(filters_dir))
+    item = RSSItem(link="https://t.me/mediarzn/1", description="Big concert tonight")
+
+    decision = await chain.apply("mediarzn", item)
+
+    assert decision.keep is True
+    assert decision.tags == ["checked", "concert"]
+
+
+@pytest.mark.asyncio
+async def test_apply_drops(filters_dir):
+    """Test that a drop from any filter drops the post."""
+    chain = load_filters(str(filters_dir))
+    item = RSSItem(link="https://t.me/mediarzn/2", description="Discount concert #ad")
+
+    decision = await chain.apply("mediarzn", item)
+
+    assert decision.keep is False
+
+
+@pytest.mark.asyncio
+async def test_failing_filters_keep_post(tmp_path, monkeypatch):
+    """Test that crashing and slow filters fail open."""
+    (tmp_path / "crash.wasm").write_text(CRASH)
+    (tmp_path / "slow.wasm").write_text(SLEEP)
+    monkeypatch.setenv("FILTER_RUNTIME_COMMAND", f"{sys.executable} {{module}}")
+    monkeypatch.setenv("FILTER_TIMEOUT", "0.5")
+    chain = load_filters(str(tmp_path))
+    item = RSSItem(link="https://t.me/test/1", description="Text")
+
+    decision = await chain.apply("test", item)
+
+    assert decision.keep is True
+    assert decision.tags == []
+
+
+@pytest.mark.asyncio
+@pytest.mark.skipif(not shutil.which("wasmtime"), reason="wasmtime not installed")
+async def test_default_runtime_command(tmp_path, monkeypatch):
+    """Test that a module runs under the default command and its limits."""
+    (tmp_path / "drop_all.wasm").write_text(DROP_ALL_WAT)
+    monkeypatch.delenv("FILTER_RUNTIME_COMMAND", raising=False)
+    chain = load_filters(str(tmp_path))
+    item = RSSItem(link="https://t.me/test/1", description="Text")
+
+    # A runtime that fails to start would keep the post
+    decision = await chain.apply("test", item)
+
+    assert decision.keep is False
+    assert decision.tags == ["wasm"]
+
+
+@pytest.mark.asyncio
+async def test_rule_filter():
+    """Test expression rules tagging and dropping posts."""
+    rules = RuleSet(
+        filters=[
+            FilterRule.from_dict(
+                {"when": "post.content.contains('concert')", "action": "tag", "tags": ["concert"]}
+            ),
+            FilterRule.from_dict({"when": "post.channel == 'spam'", "action": "drop"}),
+        ]
+    )
+    rule_filter = RuleFilter(rules)
+
+    kept = await rule_filter.apply("mediarzn", RSSItem(link="l1", description="concert"))
+    dropped = await rule_filter.apply("spam", RSSItem(link="l2", description="concert"))
+
+    assert kept.keep is True
+    assert kept.tags == ["concert"]
+    assert dropped.keep is False
+    assert dropped.reason == "rule post.channel == 'spam'"
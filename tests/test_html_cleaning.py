@@ -1,6 +1,6 @@
 """Tests for HTML content cleaning functionality."""
 
-from feed.utils.html import clean_content
+from common.utils.html import clean_content
 
 
 class TestCleanContent:
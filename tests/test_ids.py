@@ -0,0 +1,58 @@
+"""Tests for entity IDs."""
+
+from datetime import datetime, timedelta
+
+import pytest
+
+from common import ids
+from common.db.memory import MEMORY
+from common.db.models import Submission
+from common.ids import IdGenerator, UlidGenerator, encode_ulid, parse_id, set_generator
+
+START = datetime(2026, 3, 1, 12, 0)
+
+
+def test_ulids_sort_by_creation(fake_clock):
+    generator = UlidGenerator()
+    created = [generator.new() for _ in range(5)]
+    fake_clock.advance(timedelta(milliseconds=1))
+    created.append(generator.new())
+    created.append(generator.new(at=START + timedelta(days=1)))
+
+    assert created == sorted(created)
+    assert len(set(created)) == len(created)
+    assert all(parse_id(value) == value for value in created)
+    # The first 10 characters are the timestamp
+    assert created[0][:10] == encode_ulid(int(START.timestamp() * 1000), 0)[:10]
+
+
+def test_parse_id():
+    value = encode_ulid(1767225600000, 12345)
+
+    assert parse_id(value) == value
+    assert parse_id(f" {value.lower()} ") == value
+    for invalid in ("", "1", value[:-1], value + "0", value[:-1] + "U", "8" + value[1:]):
+        assert parse_id(invalid) is None, invalid
+
+
+class CountingGenerator(IdGenerator):
+    def __init__(self):
+        self.count = 0
+
+    def new(self, at=None):
+        self.count += 1
+        return f"ID{self.count}"
+
+
+@pytest.mark.asyncio
+async def test_storage_uses_installed_generator():
+    generator = CountingGenerator()
+    previous = set_generator(generator)
+    try:
+        first = await MEMORY.submissions.create(Submission(link="https://a.example", content="А"))
+        second = await MEMORY.submissions.create(Submission(link="https://b.example", content="Б"))
+        assert (first, second) == ("ID1", "ID2")
+        assert ids.new_id() == "ID3"
+    finally:
+        assert set_generator(previous) is generator
+    assert ids.get_generator() is previous
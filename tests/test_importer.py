@@ -0,0 +1,136 @@
+"""Tests for imports of partner event tables."""
+
+import io
+import json
+from datetime import date
+
+import pytest
+
+from common.db.memory import MEMORY
+from common.rules import FilterRule, RuleSet
+from importer.events import CREATED, FILTERED, INVALID, UNCHANGED, UPDATED, import_rows
+from importer.mapping import ColumnMapping, MappingError, load_mapping
+from importer.table import read_table
+from rss_reader.core.filters import RuleFilter
+
+MAPPING = {
+    "source": "philharmonic",
+    "external_id": "ID",
+    "link": "https://philharmonic.example/events/{external_id}",
+    "title": "Название",
+    "content": ["Название", "Когда", "Цена"],
+    "tags": "Жанр",
+    "extra_tags": ["partner"],
+    "delimiter": ";",
+}
+
+TABLE = """﻿ID;Название;Когда;Цена;Жанр
+101;Весенний гала-концерт;15 марта в 19:00;Билеты от 700 ₽;Concert, Classical
+102;Органный вечер;20 марта в 20:00;Вход свободный;
+"""
+
+
+def write_table(tmp_path, text: str):
+    path = tmp_path / "events.csv"
+    path.write_text(text, encoding="utf-8")
+    return read_table(str(path), ";")
+
+
+def test_mapping_validation(tmp_path):
+    mapping = ColumnMapping.from_dict({**MAPPING, "content": "Название"})
+    assert mapping.content == ["Название"]
+    assert mapping.missing_columns(["ID", "Название"]) == ["Жанр"]
+    assert mapping.event_link({"ID": "a/b 1"}) == "https://philharmonic.example/events/a%2Fb%201"
+
+    for data in (
+        [],
+        {**MAPPING, "external_id": ""},
+        {**MAPPING, "link": "https://philharmonic.example/events"},
+        {**MAPPING, "link": "https://philharmonic.example/{city}/{external_id}"},
+    ):
+        with pytest.raises(MappingError):
+            ColumnMapping.from_dict(data)
+
+    path = tmp_path / "mapping.json"
+    path.write_text("{")
+    with pytest.raises(MappingError):
+        load_mapping(str(path))
+
+
+@pytest.mark.asyncio
+async def test_import_and_reimport(memory_storage, tmp_path):
+    mapping = ColumnMapping.from_dict(MAPPING)
+    header, rows = write_table(tmp_path, TABLE)
+    assert header[0] == "ID"
+    assert mapping.missing_columns(header) == []
+
+    report = await import_rows(rows, mapping)
+    assert [row.status for row in report.rows] == [CREATED, CREATED]
+    post = await MEMORY.posts.get_by_link("https://philharmonic.example/events/101")
+    assert post.title == "Весенний гала-концерт"
+    assert post.tags == ["partner", "concert", "classical"]
+    assert post.event_start.month == 3 and post.event_start.day == 15
+    assert post.price_min == 700
+
+    report = await import_rows(rows, mapping)
+    assert [row.status for row in report.rows] == [UNCHANGED, UNCHANGED]
+
+    # Edited rows update their post and keep its publication state
+    await MEMORY.posts.mark_as_published([post.link])
+    await MEMORY.posts.update_summary(post.link, "Гала", "Весенний гала-концерт")
+    _, rows = write_table(tmp_path, TABLE.replace("15 марта", "16 марта"))
+    report = await import_rows(rows, mapping)
+    assert [row.status for row in report.rows] == [UPDATED, UNCHANGED]
+    post = await MEMORY.posts.get_by_link(post.link)
+    assert post.event_start.day == 16
+    assert post.is_published
+    assert post.summary is None
+    assert len(await MEMORY.posts.get_all()) == 2
+
+
+@pytest.mark.asyncio
+async def test_invalid_and_filtered_rows(memory_storage, tmp_path):
+    mapping = ColumnMapping.from_dict({**MAPPING, "pub_date": "Опубликовано"})
+    text = (
+        "ID;Название;Когда;Цена;Жанр;Опубликовано\n"
+        "201;Джаз в парке;1 июня;;;2026-05-01T10:00:00\n"
+        ";Без номера;2 июня;;;\n"
+        "202;;;;;\n"
+        "203;Лекция;3 июня;;;вчера\n"
+        "201;Повтор;4 июня;;;\n"
+        "204;Закрытый показ;5 июня;;;\n"
+    )
+    _, rows = write_table(tmp_path, text)
+    rule = FilterRule.from_dict({"name": "no_private", "when": "post.content.contains('Закрытый')"})
+    report = await import_rows(rows, mapping, [RuleFilter(RuleSet(filters=[rule]))])
+
+    assert [(row.line, row.status) for row in report.rows] == [
+        (2, CREATED),
+        (3, INVALID),
+        (4, INVALID),
+        (5, INVALID),
+        (6, INVALID),
+        (7, FILTERED),
+    ]
+    assert "line 2" in report.rows[4].message
+    assert "no_private" in report.rows[5].message
+    assert report.counts() == {CREATED: 1, INVALID: 4, FILTERED: 1}
+    assert len(report.rejected) == 5
+    post = await MEMORY.posts.get_by_link("https://philharmonic.example/events/201")
+    assert post.pub_date.date() == date(2026, 5, 1)
+    assert len(await MEMORY.posts.get_all()) == 1
+
+    out = io.StringIO()
+    report.write_csv(out)
+    lines = out.getvalue().splitlines()
+    assert lines[0] == "line,external_id,status,link,message"
+    assert lines[1] == "2,201,created,https://philharmonic.example/events/201,"
+
+
+@pytest.mark.asyncio
+async def test_dry_run_stores_nothing(memory_storage, tmp_path):
+    _, rows = write_table(tmp_path, TABLE)
+    report = await import_rows(rows, ColumnMapping.from_dict(MAPPING), dry_run=True)
+    assert [row.status for row in report.rows] == [CREATED, CREATED]
+    assert await MEMORY.posts.get_all() == []
+    assert json.dumps(report.counts()) == '{"created": 2}'
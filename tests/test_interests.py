@@ -0,0 +1,41 @@
+"""Tests for monthly interest reports."""
+
+import io
+from datetime import date
+
+from export.interests import month_range, previous_month, report_rows, write_csv
+
+
+def test_month_range():
+    """Test month boundaries, including December."""
+    assert month_range("2026-02") == (date(2026, 2, 1), date(2026, 3, 1))
+    assert month_range("2026-12") == (date(2026, 12, 1), date(2027, 1, 1))
+    assert previous_month(date(2026, 1, 15)) == "2025-12"
+    assert previous_month(date(2026, 3, 1)) == "2026-02"
+
+
+def test_report_rows_merge_small_categories():
+    """Test aggregation per category and merging of small groups into 'other'."""
+    totals = [
+        {"link": "https://t.me/club/1", "tags": ["music"], "views": 10, "clicks": 2},
+        {"link": "https://t.me/club/2", "tags": ["music"], "views": 30, "clicks": 3},
+        {"link": "https://t.me/club/3", "tags": ["music", "kids"], "views": 20, "clicks": None},
+        {"link": "https://t.me/club/4", "tags": None, "views": 5, "clicks": 1},
+    ]
+
+    rows = report_rows(totals, min_events=2)
+
+    assert rows[0] == {
+        "category": "music",
+        "channel": "club",
+        "events": 3,
+        "views": 60,
+        "clicks": 5,
+        "click_rate": "0.083",
+    }
+    assert rows[1]["category"] == "other"
+    assert (rows[1]["events"], rows[1]["views"]) == (2, 25)
+
+    out = io.StringIO()
+    write_csv(rows, out)
+    assert out.getvalue().splitlines()[0] == "category,channel,events,views,clicks,click_rate"
@@ -0,0 +1,79 @@
+"""Tests for events created by hand through the API."""
+
+import json
+from datetime import date, datetime
+
+import pytest
+
+from api import manual
+from api.server import HTTPError, HTTPServer, Request
+from common.db.memory import MEMORY
+
+EVENT = {
+    "link": "https://philharmonic.example/events/spring-gala",
+    "content": "Весенний гала-концерт 15 марта в 19:00. Билеты от 700 ₽.",
+    "title": "Весенний гала-концерт",
+    "pub_date": "2026-03-01T10:00:00",
+    "tags": ["concert"],
+}
+
+
+def create(body) -> Request:
+    return Request(method="POST", path="/manual-events", body=json.dumps(body).encode())
+
+
+@pytest.fixture
+def server():
+    server = HTTPServer()
+    manual.register(server)
+    return server
+
+
+@pytest.mark.asyncio
+async def test_create_event(memory_storage, server, monkeypatch):
+    monkeypatch.delenv("RULES_FILE", raising=False)
+    response = await server.dispatch(create(EVENT))
+    assert response.status == 201
+    assert json.loads(response.body)["title"] == "Весенний гала-концерт"
+
+    post = await MEMORY.posts.get_by_link(EVENT["link"])
+    assert post.tags == ["manual", "concert"]
+    assert post.pub_date == datetime(2026, 3, 1, 10, 0)
+    assert post.event_start == date(2026, 3, 15)
+    assert post.price_min == 700
+    assert not post.is_published
+    # Summarized with the others; the given title is kept
+    assert [p.link for p in await MEMORY.posts.get_without_summary(10)] == [EVENT["link"]]
+    assert post.title == "Весенний гала-концерт"
+
+    with pytest.raises(HTTPError) as error:
+        await server.dispatch(create(EVENT))
+    assert error.value.status == 409
+
+
+@pytest.mark.asyncio
+async def test_invalid_events_are_rejected(memory_storage, server):
+    for body in (
+        [],
+        {**EVENT, "link": "philharmonic/spring-gala"},
+        {**EVENT, "content": "  "},
+        {**EVENT, "pub_date": "15 марта"},
+        {**EVENT, "tags": "concert"},
+    ):
+        with pytest.raises(HTTPError) as error:
+            await server.dispatch(create(body))
+        assert error.value.status == 400
+    assert await MEMORY.posts.get_by_link(EVENT["link"]) is None
+
+
+@pytest.mark.asyncio
+async def test_rules_apply_to_manual_events(memory_storage, server, monkeypatch, tmp_path):
+    rule = {"name": "no_manual", "when": 'post.channel == "manual"'}
+    rules = tmp_path / "rules.json"
+    rules.write_text(json.dumps({"filters": [rule]}))
+    monkeypatch.setenv("RULES_FILE", str(rules))
+
+    with pytest.raises(HTTPError) as error:
+        await server.dispatch(create(EVENT))
+    assert error.value.status == 422
+    assert "no_manual" in error.value.message
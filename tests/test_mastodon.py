@@ -0,0 +1,87 @@
+"""Tests for Mastodon post selection and status text."""
+
+import pytest
+
+from common.db.models import RSSPost
+from common.rules import compile_expression
+from mastodon_publisher.__main__ import URL_LENGTH, build_status, select_posts
+from mastodon_publisher.config import MastodonPublisherConfig
+
+SENSITIVE = '"18+" in post.tags || post.content.contains("18+")'
+
+
+def test_select_posts_flags_sensitive():
+    """Test that only selected posts are kept and 18+ posts are flagged."""
+    posts = [
+        RSSPost(link="https://t.me/mediarzn/1", content="Концерт", tags=["concert"]),
+        RSSPost(link="https://t.me/mediarzn/2", content="Лекция"),
+        RSSPost(link="https://t.me/mediarzn/3", content="Вечеринка 18+", tags=["concert"]),
+    ]
+
+    selected = select_posts(
+        posts, compile_expression('"concert" in post.tags'), compile_expression(SENSITIVE)
+    )
+
+    assert [(post.link[-1], sensitive) for post, sensitive in selected] == [
+        ("1", False),
+        ("3", True),
+    ]
+
+
+def test_build_status_keeps_link_within_limit():
+    """Test that long content is truncated so the link always fits."""
+    link = "https://t.me/mediarzn/" + "1" * 100
+    status = build_status("слово " * 200, link, max_chars=500)
+
+    text, status_link = status.split("\n\n")
+    assert status_link == link
+    assert text.endswith("…")
+    assert len(text) + 2 + URL_LENGTH <= 500
+    assert build_status("", link) == link
+
+
+def test_config_requires_selection():
+    """Test that posting everything by accident is not possible."""
+    config = MastodonPublisherConfig(base_url="https://m.example", access_token="t", select="")
+
+    with pytest.raises(ValueError, match="MASTODON_SELECT"):
+        config.validate()
+
+
+def test_media_urls():
+    """Test media parsing from JSON and legacy comma-separated values."""
+    post = RSSPost(link="l", content="", media='["https://a/1.jpg", "https://a/2.jpg"]')
+
+    assert post.media_urls() == ["https://a/1.jpg", "https://a/2.jpg"]
+    assert RSSPost(link="l", content="", media="https://a/1.jpg, ").media_urls() == [
+        "https://a/1.jpg"
+    ]
+
+
+@pytest.mark.asyncio
+async def test_refresh_shares(memory_storage):
+    """Test that the reblog counts of recent statuses are stored, skipping deleted ones."""
+    from datetime import datetime
+
+    import requests
+
+    from common.db.memory import MEMORY
+    from mastodon_publisher.__main__ import refresh_shares
+
+    target = "mastodon:mastodon.example"
+    for number in (1, 2):
+        link = f"https://t.me/mediarzn/{number}"
+        await MEMORY.posts.create(
+            RSSPost(link=link, content="Концерт", pub_date=datetime(2026, 3, 1))
+        )
+        await MEMORY.deliveries.record(link, target, str(number))
+
+    class FakeClient:
+        def get_status(self, status_id):
+            if status_id == "2":
+                raise requests.HTTPError("404 Not Found")
+            return {"id": status_id, "reblogs_count": 3}
+
+    assert await refresh_shares(FakeClient(), target, datetime(2000, 1, 1)) == 1
+    [row] = await MEMORY.interactions.get_reach(datetime(2000, 1, 1), limit=1)
+    assert (row["link"], row["shares"], row["reach"]) == ("https://t.me/mediarzn/1", 3, 3)
@@ -0,0 +1,52 @@
+"""Tests for the in-memory storage backend."""
+
+import inspect
+from dataclasses import fields
+
+import pytest
+
+from common.db import memory
+from common.db.memory import MEMORY
+from common.db.models import RSSPost
+from common.db.storage import POSTGRES
+from tests.storagetest import run_repository_tests
+
+
+@pytest.mark.asyncio
+async def test_memory_conformance():
+    memory.reset()
+    await run_repository_tests(MEMORY)
+
+
+def test_memory_repositories_match_postgres():
+    for field in fields(POSTGRES):
+        if field.name == "name":
+            continue
+        postgres, in_memory = getattr(POSTGRES, field.name), getattr(MEMORY, field.name)
+        for method, value in vars(postgres).items():
+            if isinstance(value, staticmethod):
+                assert method in vars(in_memory), f"{in_memory.__name__}.{method}"
+                assert inspect.signature(getattr(in_memory, method)) == inspect.signature(
+                    getattr(postgres, method)
+                ), f"{in_memory.__name__}.{method}"
+
+
+@pytest.mark.asyncio
+async def test_returned_posts_are_copies():
+    memory.reset()
+    await MEMORY.posts.create(RSSPost(link="https://t.me/club/1", content="Text", tags=["music"]))
+
+    post = await MEMORY.posts.get_by_link("https://t.me/club/1")
+    post.tags.append("jazz")
+    post.content = "Changed"
+
+    stored = await MEMORY.posts.get_by_link("https://t.me/club/1")
+    assert (stored.content, stored.tags) == ("Text", ["music"])
+
+
+@pytest.mark.asyncio
+async def test_duplicate_post_is_rejected():
+    memory.reset()
+    await MEMORY.posts.create(RSSPost(link="https://t.me/club/1", content="Text"))
+    with pytest.raises(ValueError):
+        await MEMORY.posts.create(RSSPost(link="https://t.me/club/1", content="Again"))
@@ -0,0 +1,52 @@
+"""Tests for planning expand/contract migrations."""
+
+from types import SimpleNamespace
+
+import pytest
+import sqlalchemy as sa
+
+from common.migrations import CONTRACT, add_column, expand_target, pending_migrations
+
+
+class FakeScript:
+    """A linear migration history, oldest first: (revision, phase or None)."""
+
+    def __init__(self, history):
+        self.revisions = {}
+        down = None
+        for revision, phase in history:
+            module = SimpleNamespace(phase=phase) if phase else SimpleNamespace()
+            self.revisions[revision] = SimpleNamespace(
+                revision=revision, down_revision=down, module=module
+            )
+            down = revision
+        self.head = down
+
+    def get_current_head(self):
+        return self.head
+
+    def get_revision(self, revision):
+        return self.revisions[revision]
+
+
+def test_expand_stops_before_the_first_contract_migration():
+    script = FakeScript(
+        [("a1", None), ("b2", None), ("c3", CONTRACT), ("d4", None), ("e5", CONTRACT)]
+    )
+
+    assert [m.revision for m in pending_migrations(script, "a1")] == ["b2", "c3", "d4", "e5"]
+    assert [m.revision for m in pending_migrations(script, None)][0] == "a1"
+    assert pending_migrations(script, "e5") == []
+    with pytest.raises(ValueError):
+        pending_migrations(script, "zz")
+
+    assert expand_target(script, None) == "b2"
+    assert expand_target(script, "b2") == "b2"
+    # Applying the contract migration makes the next ones available
+    assert expand_target(script, "c3") == "d4"
+    assert expand_target(script, "e5") == "e5"
+
+
+def test_added_columns_must_not_break_old_inserts():
+    with pytest.raises(ValueError, match="nullable"):
+        add_column("rss_posts", sa.Column("source", sa.Text(), nullable=False))
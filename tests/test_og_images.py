@@ -0,0 +1,77 @@
+"""Tests for Open Graph image rendering."""
+
+import sys
+from datetime import datetime
+
+import pytest
+
+from common.db.models import RSSPost
+from common.og_images import OGImageError, OGImageRenderer, card_details, render_svg, wrap_text
+from common.pages import SiteSettings, render_event_page
+
+POST = RSSPost(
+    link="https://t.me/mediarzn/123",
+    content="Концерт <Kino> & друзья",
+    pub_date=datetime(2026, 2, 14, 19, 30),
+)
+
+# Stands in for rsvg-convert: copies the SVG to the output path
+COPY_COMMAND = (
+    f'{sys.executable} -c "import shutil, sys; shutil.copy(sys.argv[1], sys.argv[2])" '
+    "{input} {output}"
+)
+
+
+def test_wrap_text():
+    """Test wrapping, hard breaks for long words and the overflow ellipsis."""
+    assert wrap_text("Большой летний концерт в парке", 15, 3) == [
+        "Большой летний",
+        "концерт в парке",
+    ]
+    assert wrap_text("a" * 25, 10, 5) == ["aaaaaaaaa-", "aaaaaaaaa-", "aaaaaaa"]
+    assert wrap_text("one two three four", 9, 1) == ["one two…"]
+
+
+def test_render_svg_escapes_and_embeds_poster():
+    """Test that text is escaped and the poster is embedded as a data URI."""
+    svg = render_svg("Концерт <Kino> & друзья", ["14 февраля 2026"], (b"\x89PNG", "image/png"))
+
+    assert "Концерт &lt;Kino&gt; &amp; друзья" in svg
+    assert 'href="data:image/png;base64,iVBORw=="' in svg
+    assert "14 февраля 2026" in svg
+
+
+@pytest.mark.asyncio
+async def test_renderer_caches(tmp_path):
+    """Test that an image is rendered once and re-rendered when the post changes."""
+    renderer = OGImageRenderer(str(tmp_path), COPY_COMMAND)
+    details = card_details(POST, "14 февраля 2026, 19:30")
+
+    path = await renderer.render(POST, "Концерт", details)
+    assert path.parent == tmp_path / "og"
+    assert "@mediarzn" in path.read_text(encoding="utf-8")
+
+    renderer.render_command = "false"
+    assert await renderer.render(POST, "Концерт", details) == path
+
+    renderer.render_command = COPY_COMMAND
+    updated = await renderer.render(POST, "Концерт отменён", details)
+    assert updated != path
+    assert not path.exists()
+
+
+@pytest.mark.asyncio
+async def test_renderer_failure(tmp_path):
+    """Test that renderer failures raise OGImageError."""
+    renderer = OGImageRenderer(str(tmp_path), "false")
+
+    with pytest.raises(OGImageError):
+        await renderer.render(POST, "Концерт", [])
+
+
+def test_event_page_points_at_generated_image():
+    """Test og:image with generated cards enabled."""
+    site = SiteSettings(base_url="https://events.example.com", og_images=True)
+    page = render_event_page(POST, site)
+
+    assert '"og:image" content="https://events.example.com/og/mediarzn-123.png"' in page
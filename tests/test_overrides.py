@@ -0,0 +1,185 @@
+"""Tests for moderators' corrections of extracted fields."""
+
+import json
+from datetime import datetime, timedelta
+from decimal import Decimal
+
+import pytest
+
+from api import overrides
+from api.server import HTTPError, HTTPServer, Request
+from common.clock import FakeClock, set_clock
+from common.db.memory import MEMORY
+from common.db.models import FieldOverride, RSSPost
+from common.rules import compile_expression, post_variables
+from importer.events import UPDATED, import_rows
+from importer.mapping import ColumnMapping
+from rss_reader.core.corrections import suggest_keywords, summarize_corrections
+from rss_reader.core.extraction import from_comparable
+
+MAPPING = {
+    "source": "philharmonic",
+    "external_id": "ID",
+    "link": "https://philharmonic.example/events/{external_id}",
+    "content": ["Название", "Цена"],
+}
+LINK = "https://philharmonic.example/events/101"
+
+
+def rows(price: str):
+    return [{"ID": "101", "Название": "Органный вечер 20 марта в 20:00", "Цена": price}]
+
+
+def post_request(path: str, body: dict) -> Request:
+    return Request(method="POST", path=path, body=json.dumps(body).encode())
+
+
+def test_from_comparable():
+    assert from_comparable("price_min", "500") == Decimal("500")
+    assert from_comparable("price_min", 500) == Decimal("500")
+    assert from_comparable("deadline", "2026-03-20").day == 20
+    assert from_comparable("event_start", "2026-03-20T20:00:00").hour == 20
+    assert from_comparable("performers", ["Иван Петров"]) == ["Иван Петров"]
+    assert from_comparable("performers", []) is None
+    assert from_comparable("tickets_required", None) is None
+    for name, value in [
+        ("price_min", True),
+        ("price_min", "дёшево"),
+        ("deadline", "20 марта"),
+        ("tickets_required", "yes"),
+        ("contacts", "+79101234567"),
+        ("summary", "Органный вечер"),
+    ]:
+        with pytest.raises(ValueError):
+            from_comparable(name, value)
+
+
+@pytest.mark.asyncio
+async def test_corrections_survive_reprocessing(memory_storage):
+    mapping = ColumnMapping.from_dict(MAPPING)
+    await import_rows(rows("Билеты от 700 ₽"), mapping)
+    server = HTTPServer()
+    overrides.register(server)
+
+    corrected = post_request("/posts/fields", {"link": LINK, "field": "price_min", "value": 500})
+    data = json.loads((await server.dispatch(corrected)).body)
+    assert data["fields"]["price_min"] == {
+        "value": "500",
+        "extracted": "700",
+        "overridden": True,
+    }
+    assert data["fields"]["price_currency"]["overridden"] is False
+    assert (await MEMORY.posts.get_by_link(LINK)).price_min == 500
+    assert [(o.value, o.extracted) for o in await MEMORY.overrides.get_by_link(LINK)] == [
+        ('"500"', '"700"')
+    ]
+
+    # The text changes and the post is extracted again; the correction stays
+    report = await import_rows(rows("Билеты от 900 ₽, вход по регистрации"), mapping)
+    assert [row.status for row in report.rows] == [UPDATED]
+    post = await MEMORY.posts.get_by_link(LINK)
+    assert post.price_min == 500
+    assert post.registration_required is True
+
+    query = {"link": LINK}
+    data = json.loads(
+        (await server.dispatch(Request(method="GET", path="/posts/fields", query=query))).body
+    )
+    assert (data["fields"]["price_min"]["value"], data["fields"]["price_min"]["extracted"]) == (
+        "500",
+        "900",
+    )
+
+    revert = post_request("/posts/fields/revert", {"link": LINK, "field": "price_min"})
+    data = json.loads((await server.dispatch(revert)).body)
+    assert data["fields"]["price_min"] == {
+        "value": "900",
+        "extracted": "900",
+        "overridden": False,
+    }
+    assert (await MEMORY.posts.get_by_link(LINK)).price_min == 900
+    assert await MEMORY.overrides.get_by_link(LINK) == []
+
+    for request, status in [
+        (revert, 404),
+        (post_request("/posts/fields", {"link": LINK, "field": "price_min"}), 400),
+        (post_request("/posts/fields", {"link": LINK, "field": "title", "value": "x"}), 400),
+        (post_request("/posts/fields", {"link": LINK, "field": "deadline", "value": 1}), 400),
+        (post_request("/posts/fields", {"link": LINK + "0", "field": "price_min"}), 404),
+    ]:
+        with pytest.raises(HTTPError) as error:
+            await server.dispatch(request)
+        assert error.value.status == status
+
+
+ONLINE = [
+    "Лекция об архитектуре 12 марта, трансляция на канале",
+    "Разговор о джазе, ссылка на трансляцию и запись будут позже",
+    "Мастер-класс 3 апреля, трансляция в группе",
+]
+
+
+def test_summarize_corrections_and_suggest_keywords():
+    corrections = [
+        FieldOverride(f"https://t.me/lectures/{n}", "event_format", '"online"', '"offline"')
+        for n in range(len(ONLINE))
+    ]
+    corrections += [
+        FieldOverride("https://t.me/club/1", "price_min", '"500"', "null"),
+        FieldOverride("https://t.me/lectures/9", "price_min", "null", '"300"'),
+    ]
+
+    stats = summarize_corrections(corrections, examples=2)
+    assert [(s.field, s.channel, s.count) for s in stats] == [
+        ("event_format", "lectures", 3),
+        ("price_min", "club", 1),
+        ("price_min", "lectures", 1),
+    ]
+    assert stats[0].examples == [
+        ("https://t.me/lectures/2", "offline", "online"),
+        ("https://t.me/lectures/1", "offline", "online"),
+    ]
+
+    texts = {f"https://t.me/lectures/{n}": text for n, text in enumerate(ONLINE)}
+    background = [f"Концерт в клубе {n} марта, вход на канале свободный" for n in range(20)]
+    [suggestion] = suggest_keywords(corrections, texts, background)
+    assert (suggestion.field, suggestion.value, suggestion.posts) == ("event_format", "online", 3)
+    assert suggestion.keywords[0] == "трансл"
+    assert "канале" not in suggestion.keywords
+    rule = compile_expression(suggestion.when)
+    assert rule.matches(post_variables("lectures", LINK, "Идёт ТРАНСЛЯЦИЯ"))
+    assert not rule.matches(post_variables("club", LINK, background[0]))
+    # A single correction suggests nothing
+    assert suggest_keywords(corrections[:1], texts, background) == []
+
+
+@pytest.mark.asyncio
+async def test_correction_report(memory_storage):
+    now = datetime(2026, 3, 20, 12, 0)
+    previous = set_clock(FakeClock(now))
+    try:
+        for n, text in enumerate(ONLINE):
+            link = f"https://t.me/lectures/{n}"
+            await MEMORY.posts.create(RSSPost(link=link, content=text, pub_date=now))
+            created = now - timedelta(days=40 if n == 0 else 1)
+            await MEMORY.overrides.set(
+                FieldOverride(link, "event_format", '"online"', '"offline"', created)
+            )
+        server = HTTPServer()
+        overrides.register(server)
+
+        request = Request(method="GET", path="/posts/fields/report", query={"days": "60"})
+        data = json.loads((await server.dispatch(request)).body)
+        assert data["corrections"] == 3
+        assert [(f["field"], f["channel"], f["count"]) for f in data["fields"]] == [
+            ("event_format", "lectures", 3)
+        ]
+        assert data["suggestions"][0]["keywords"][0] == "трансл"
+
+        query = {"suggest": "false"}
+        request = Request(method="GET", path="/posts/fields/report", query=query)
+        data = json.loads((await server.dispatch(request)).body)
+        assert data["corrections"] == 2
+        assert "suggestions" not in data
+    finally:
+        set_clock(previous)
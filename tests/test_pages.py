@@ -0,0 +1,105 @@
+"""Tests for static event pages."""
+
+from datetime import datetime
+
+from common.db.models import RSSPost
+from common.pages import (
+    SiteSettings,
+    build_site,
+    event_json_ld,
+    event_title,
+    render_event_page,
+    render_index,
+    render_robots,
+    render_sitemap,
+)
+from common.utils.links import post_slug
+
+SITE = SiteSettings(title="Афиша", base_url="https://events.example.com/")
+
+POST = RSSPost(
+    link="https://t.me/mediarzn/123",
+    content="Концерт <Kino> & друзья\n\nВход свободный",
+    pub_date=datetime(2026, 2, 14, 19, 30),
+    media='["https://cdn.example.com/poster.jpg"]',
+)
+
+
+def test_post_slug():
+    """Test slugs for Telegram links and a fallback for odd links."""
+    assert post_slug("https://t.me/mediarzn/123") == "mediarzn-123"
+    assert post_slug("https://t.me/s/Mediarzn/123") == "mediarzn-123"
+    assert len(post_slug("https://example.com/")) == 16
+
+
+def test_event_page_open_graph():
+    """Test that event pages carry escaped Open Graph tags."""
+    page = render_event_page(POST, SITE)
+
+    assert '<meta property="og:title" content="Концерт &lt;Kino&gt; &amp; друзья">' in page
+    assert '"og:url" content="https://events.example.com/events/mediarzn-123.html"' in page
+    assert '"og:image" content="https://cdn.example.com/poster.jpg"' in page
+    assert '<link rel="canonical"' in page
+    assert "14 февраля 2026, 19:30" in page
+    assert "<Kino>" not in page
+
+
+def test_event_page_without_base_url():
+    """Test that pages without a base URL omit absolute links."""
+    page = render_event_page(POST, SiteSettings())
+
+    assert "og:url" not in page
+    assert "canonical" not in page
+
+
+def test_index_calendar_links_days_and_events():
+    """Test that the calendar links days with events to the event list."""
+    later = RSSPost(link="https://t.me/mediarzn/124", content="", pub_date=datetime(2026, 2, 20))
+    page = render_index([later, POST], SITE)
+
+    assert "<caption>Февраль 2026</caption>" in page
+    assert '<a href="#day-2026-02-14">14</a>' in page
+    assert page.index('id="day-2026-02-14"') < page.index('id="day-2026-02-20"')
+    assert '<a href="events/mediarzn-123.html">' in page
+    assert event_title(later) == "mediarzn"
+
+
+def test_build_site_files():
+    """Test the set of generated files."""
+    files = build_site([POST], SITE)
+
+    assert sorted(files) == [
+        "events/mediarzn-123.html",
+        "index.html",
+        "robots.txt",
+        "sitemap.xml",
+        "style.css",
+    ]
+
+
+def test_event_json_ld():
+    """Test schema.org Event data and that it can't break out of its script tag."""
+    post = RSSPost(
+        link="https://t.me/mediarzn/125",
+        content="Лекция </script><b>",
+        pub_date=datetime(2026, 2, 14, 19, 30),
+    )
+    page = render_event_page(post, SITE)
+    data = event_json_ld(post, SITE)
+
+    assert data["@type"] == "Event"
+    assert data["startDate"] == "2026-02-14T19:30:00"
+    assert data["url"] == "https://events.example.com/events/mediarzn-125.html"
+    assert data["organizer"]["name"] == "mediarzn"
+    assert '<script type="application/ld+json">' in page
+    assert page.count("</script>") == 1
+
+
+def test_sitemap_and_robots():
+    """Test sitemap entries and the robots.txt pointer."""
+    sitemap = render_sitemap([POST], SITE)
+
+    assert "<loc>https://events.example.com/</loc><lastmod>2026-02-14</lastmod>" in sitemap
+    assert "<loc>https://events.example.com/events/mediarzn-123.html</loc>" in sitemap
+    assert "Sitemap: https://events.example.com/sitemap.xml" in render_robots(SITE)
+    assert "sitemap.xml" not in build_site([POST], SiteSettings())
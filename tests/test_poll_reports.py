@@ -0,0 +1,239 @@
+"""Tests for per-item poll run reports."""
+
+import json
+from datetime import timedelta
+
+import pytest
+
+from api import polls
+from api.server import HTTPError, HTTPServer, Request
+from common import clock
+from common.alerts import AlertManager
+from common.db.memory import MEMORY
+from common.db.models import TelegramChannel
+from common.models.feed import RSSChannel, RSSItem
+from common.rules import FilterRule, RuleSet
+from rss_reader import __main__ as reader
+from rss_reader.__main__ import process_source, save_items, save_report, taken_over_result
+from rss_reader.core.bandwidth import BandwidthMeter
+from rss_reader.core.filters import RuleFilter
+from rss_reader.core.report import PollReport
+from rss_reader.core.source import Source, TelegramSource
+
+
+def item(number: int, text: str = "Концерт в клубе") -> RSSItem:
+    return RSSItem(link=f"https://t.me/club/{number}", description=text)
+
+
+def test_unknown_outcome_is_rejected():
+    with pytest.raises(ValueError):
+        PollReport().add("club", "https://t.me/club/1", "lost")
+
+
+@pytest.mark.asyncio
+async def test_save_items_reports_each_item(memory_storage):
+    rules = RuleSet(
+        filters=[
+            FilterRule.from_dict(
+                {"name": "ads", "when": "post.content.contains('скидка')", "action": "drop"}
+            )
+        ]
+    )
+    await save_items("club", [item(1), item(2)])
+
+    report = PollReport()
+    counts = await save_items(
+        "club",
+        [item(1), item(2, "Концерт перенесён"), item(3, "скидка 50%"), item(4, " "), item(5)],
+        [RuleFilter(rules)],
+        report,
+    )
+
+    assert counts == (1, 2, 1, 1, 0)
+    assert [(i.link.rsplit("/", 1)[1], i.outcome, i.reason) for i in report.items] == [
+        ("1", "duplicate", None),
+        ("2", "updated", "edited after it was saved (revision 1)"),
+        ("3", "filtered", "rule ads"),
+        ("4", "empty", None),
+        ("5", "new", None),
+    ]
+    assert report.counts() == {"duplicate": 1, "updated": 1, "filtered": 1, "empty": 1, "new": 1}
+
+
+@pytest.mark.asyncio
+async def test_failed_channel_is_reported(memory_storage):
+    class BrokenParser:
+        async def parse_url_async(self, url, since=None):
+            raise ConnectionError("bridge is down")
+
+    report = PollReport()
+    channel = TelegramChannel(channel_id=1, channel_name="club")
+    result = await process_source(TelegramSource(channel, BrokenParser()), report=report)
+
+    assert result == ("club", 0, 0, 0, 0, 1, 0)
+    assert [(i.source, i.outcome, i.reason) for i in report.items] == [
+        ("club", "error", "bridge is down")
+    ]
+    assert "club" in report.items[0].link
+
+
+@pytest.mark.asyncio
+async def test_any_source_is_processed_alike(memory_storage):
+    class ListSource(Source):
+        def __init__(self, items):
+            self.name = "list"
+            self.items = items
+            self.forgotten = False
+
+        @property
+        def warnings(self):
+            return ["Skipped item 3: no link"]
+
+        async def fetch(self):
+            return self.items
+
+        def forget(self):
+            self.forgotten = True
+
+    report = PollReport()
+    source = ListSource([item(1), item(2, " ")])
+    assert await process_source(source, report=report) == ("list", 1, 0, 1, 0, 0, 1)
+    assert not source.forgotten
+    assert [(i.link, i.outcome, i.reason) for i in report.items][-1] == (
+        "https://t.me/club/2",
+        "empty",
+        None,
+    )
+    assert [(i.link, i.outcome, i.reason) for i in report.items][0] == (
+        "list",
+        "warning",
+        "Skipped item 3: no link",
+    )
+
+
+@pytest.mark.asyncio
+async def test_warnings_dont_alert(monkeypatch):
+    class FakeSink:
+        def __init__(self):
+            self.messages = []
+
+        def send(self, text):
+            self.messages.append(text)
+
+    sink = FakeSink()
+    monkeypatch.setattr(reader.AlertManager, "from_settings", lambda: AlertManager([sink]))
+
+    # club was read with every item skipped as malformed, bar failed to fetch
+    await reader.alert_on_failures([("club", 0, 0, 0, 0, 0, 2), ("bar", 0, 0, 0, 0, 1, 0)])
+
+    assert len(sink.messages) == 1
+    assert "'bar' failed to fetch" in sink.messages[0]
+
+
+@pytest.mark.asyncio
+async def test_poll_run_endpoints(memory_storage):
+    report = PollReport()
+    await save_items("club", [item(1), item(2, " ")], report=report)
+    run_id = await MEMORY.poll_runs.start()
+    await save_report(run_id, report)
+
+    server = HTTPServer()
+    polls.register(server)
+
+    response = await server.dispatch(Request(method="GET", path="/poll-runs"))
+    runs = json.loads(response.body)
+    assert [(run["id"], run["counts"]) for run in runs] == [(run_id, {"new": 1, "empty": 1})]
+
+    query = {"run": str(run_id), "outcome": "empty"}
+    response = await server.dispatch(Request(method="GET", path="/poll-runs/items", query=query))
+    assert [i["link"] for i in json.loads(response.body)] == ["https://t.me/club/2"]
+
+    query = {"link": "https://t.me/club/1"}
+    response = await server.dispatch(Request(method="GET", path="/poll-runs/history", query=query))
+    assert [i["outcome"] for i in json.loads(response.body)] == ["new"]
+
+    for path, query in (("/poll-runs/items", {}), ("/poll-runs/history", {"link": ""})):
+        with pytest.raises(HTTPError):
+            await server.dispatch(Request(method="GET", path=path, query=query))
+
+
+@pytest.mark.asyncio
+async def test_bandwidth_per_source(memory_storage):
+    meter = BandwidthMeter({"https://bridge.example/club": "club"})
+    meter.record_url("https://bridge.example/club", 2000)
+    meter.record_url("https://bridge.example/club", 3000)
+    meter.record_url("https://bridge.example/unknown", 10)
+    meter.record("afisha", 700000)
+    assert meter.sources() == ["afisha", "club", "https://bridge.example/unknown"]
+
+    today = clock.now().date()
+    await meter.save(today)
+    await MEMORY.bandwidth.add("club", today - timedelta(days=10), 99)
+
+    server = HTTPServer()
+    polls.register(server)
+    response = await server.dispatch(Request(method="GET", path="/poll-runs/bandwidth"))
+    assert [(t["source"], t["bytes"], t["fetches"]) for t in json.loads(response.body)] == [
+        ("afisha", 700000, 1),
+        ("club", 5000, 2),
+        ("https://bridge.example/unknown", 10, 1),
+    ]
+
+    query = {"source": "club", "days": "30"}
+    request = Request(method="GET", path="/poll-runs/bandwidth", query=query)
+    assert [t["bytes"] for t in json.loads((await server.dispatch(request)).body)] == [5000, 99]
+
+
+@pytest.mark.asyncio
+async def test_retried_run_takes_over_what_it_did(memory_storage):
+    class FakeParser:
+        def __init__(self, items):
+            self.items = items
+
+        async def parse_url_async(self, url, since=None):
+            if self.items is None:
+                raise ConnectionError("bridge is down")
+            return RSSChannel(title="Feed", link=url, description="", items=self.items)
+
+        def forget(self, url):
+            pass
+
+    club = TelegramChannel(channel_id=1, channel_name="club")
+    bar = TelegramChannel(channel_id=2, channel_name="bar")
+    rules = RuleSet(
+        filters=[
+            FilterRule.from_dict(
+                {"name": "ads", "when": "post.content.contains('скидка')", "action": "drop"}
+            )
+        ]
+    )
+    filters = [RuleFilter(rules)]
+
+    # The first attempt finishes club, fails to fetch bar and crashes before saving the report
+    run_id = await MEMORY.poll_runs.start()
+    first = PollReport(run_id)
+    club_source = TelegramSource(club, FakeParser([item(1), item(2, "скидка 50%")]))
+    await process_source(club_source, filters, first)
+    await process_source(TelegramSource(bar, FakeParser(None)), filters, first)
+
+    retry = PollReport(run_id, await MEMORY.poll_runs.get_items(run_id))
+    assert retry.completed_sources == {"club"}
+    assert taken_over_result("club", retry) == ("club", 1, 0, 0, 1, 0, 0)
+    bar_items = [RSSItem(link="https://t.me/bar/1", description="Джаз"), item(1)]
+    result = await process_source(TelegramSource(bar, FakeParser(bar_items)), filters, retry)
+    assert result == ("bar", 1, 1, 0, 0, 0, 0)
+    await save_report(await MEMORY.poll_runs.start(run_id), retry)
+
+    items = await MEMORY.poll_runs.get_items(run_id)
+    assert [(i.source, i.link.rsplit("/", 1)[1], i.outcome) for i in items] == [
+        ("club", "1", "new"),
+        ("club", "2", "filtered"),
+        ("bar", "1", "new"),
+        ("bar", "1", "duplicate"),
+    ]
+    assert len(await MEMORY.posts.get_all()) == 2
+
+    # Items an interrupted source decided keep their outcome
+    again = PollReport(run_id, items)
+    assert await save_items("club", [item(1), item(3)], filters, again) == (2, 0, 0, 0, 0)
+    assert [i.outcome for i in again.items] == ["new", "new"]
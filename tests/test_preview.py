@@ -0,0 +1,110 @@
+"""Tests for reading Telegram channels from their t.me/s preview pages."""
+
+import pytest
+import requests
+
+from common.db.memory import MEMORY
+from common.db.models import TelegramChannel
+from rss_reader.__main__ import process_source
+from rss_reader.core.preview import build_preview_url, parse_preview
+from rss_reader.core.source import TelegramSource
+
+PAGE = """<!DOCTYPE html>
+<html><head>
+<meta property="og:title" content="Клуб  «Ночь»">
+</head><body>
+<div class="tgme_widget_message_wrap js-widget_message_wrap">
+  <div class="tgme_widget_message text_not_supported_wrap js-widget_message"
+       data-post="club/41">
+    <div class="tgme_widget_message_bubble">
+      <a class="tgme_widget_message_photo_wrap" href="https://t.me/club/41"
+         style="width:800px;background-image:url('https://cdn4.telesco.pe/file/poster.jpg')"></a>
+      <div class="tgme_widget_message_text js-message_text" dir="auto">Джаз 12 марта в 19:00<br>
+        Билеты от 500 &#8381; &amp; <a href="https://club.example/jazz">по ссылке</a></div>
+      <div class="tgme_widget_message_footer">
+        <a class="tgme_widget_message_date" href="https://t.me/club/41"><time
+           datetime="2026-03-01T10:15:06+00:00" class="time">10:15</time></a>
+      </div>
+    </div>
+  </div>
+</div>
+<div class="tgme_widget_message_wrap js-widget_message_wrap">
+  <div class="tgme_widget_message js-widget_message" data-post="club/42">
+    <div class="tgme_widget_message_reply">
+      <div class="tgme_widget_message_text js-message_reply_text">Джаз 12 марта</div>
+    </div>
+    <div class="tgme_widget_message_text js-message_text">Концерт <b>перенесён</b></div>
+    <a class="tgme_widget_message_date" href="https://t.me/club/42"><time
+       datetime="2026-03-02T09:00:00+00:00">09:00</time></a>
+  </div>
+</div>
+<div class="tgme_widget_message_wrap js-widget_message_wrap">
+  <div class="tgme_widget_message js-widget_message" data-post="club/43">
+    <div class="tgme_widget_message_sticker_wrap"></div>
+  </div>
+</div>
+</body></html>
+"""
+
+
+def test_parse_preview():
+    feed = parse_preview(PAGE, "club")
+
+    assert feed.title == "Клуб «Ночь» (@club) - Telegram"
+    assert feed.link == "https://t.me/s/club"
+    assert [item.link for item in feed.items] == ["https://t.me/club/42", "https://t.me/club/41"]
+    newest, oldest = feed.items
+    assert newest.description == "Концерт перенесён"
+    assert oldest.description == "Джаз 12 марта в 19:00\n\nБилеты от 500 ₽ & по ссылке"
+    assert oldest.pub_date == "2026-03-01T10:15:06+00:00"
+    assert oldest.media_urls == ["https://cdn4.telesco.pe/file/poster.jpg"]
+    assert (oldest.guid, oldest.guid_is_permalink) == ("https://t.me/club/41", True)
+
+    with pytest.raises(ValueError, match="No messages"):
+        parse_preview("<html><body>Channel not found</body></html>", "club")
+
+
+class FakeFetcher:
+    def __init__(self):
+        self.pages = []
+
+    def fetch_page(self, url):
+        self.pages.append(url)
+        return PAGE
+
+
+class RateLimitedParser:
+    def __init__(self):
+        self.fetcher = FakeFetcher()
+
+    async def parse_url_async(self, url, since=None):
+        raise ValueError("Failed to parse RSS feed: 429 Client Error: Too Many Requests")
+
+    def forget(self, url):
+        pass
+
+
+@pytest.mark.asyncio
+async def test_preview_fallback_when_the_bridge_fails(memory_storage):
+    channel = TelegramChannel(channel_id=1, channel_name="club")
+    await MEMORY.channels.create(channel)
+    parser = RateLimitedParser()
+
+    # Without the fallback the failure is reported as before
+    assert await process_source(TelegramSource(channel, parser)) == ("club", 0, 0, 0, 0, 1, 0)
+    assert parser.fetcher.pages == []
+
+    result = await process_source(TelegramSource(channel, parser, fallback=True))
+    assert result == ("club", 2, 0, 0, 0, 0, 0)
+    assert parser.fetcher.pages == [build_preview_url("club")]
+    assert (await MEMORY.channels.get_by_id(1)).title == "Клуб «Ночь» (@club) - Telegram"
+    post = await MEMORY.posts.get_by_link("https://t.me/club/41")
+    assert post.price_min == 500
+
+    class DownFetcher:
+        def fetch_page(self, url):
+            raise requests.ConnectionError("t.me is down too")
+
+    parser.fetcher = DownFetcher()
+    result = await process_source(TelegramSource(channel, parser, fallback=True))
+    assert result == ("club", 0, 0, 0, 0, 1, 0)
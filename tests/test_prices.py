@@ -0,0 +1,44 @@
+"""Tests for ticket price extraction."""
+
+from decimal import Decimal
+
+from common.prices import PriceRange, extract_prices, normalize_numbers, price_range
+
+
+def test_normalize_number_words():
+    """Test rewriting Russian number words into digits."""
+    assert normalize_numbers("полторы тысячи рублей") == "1500 рублей"
+    assert normalize_numbers("две с половиной тысячи ₽") == "2500 ₽"
+    assert normalize_numbers("тысяча двести рублей") == "1200 рублей"
+    assert normalize_numbers("пятьсот") == "500"
+    # Words that merely contain numerals are left alone
+    assert normalize_numbers("стол и сторож") == "стол и сторож"
+
+
+def test_extract_price_forms():
+    """Test amounts with currencies, multipliers and price keywords."""
+    assert extract_prices("Стоимость 1 500 руб.") == [(Decimal(1500), "RUB")]
+    assert extract_prices("Билеты: 1.5к ₽") == [(Decimal(1500), "RUB")]
+    assert extract_prices("Донат 2 тыс.") == [(Decimal(2000), "RUB")]
+    assert extract_prices("Вход 300") == [(Decimal(300), "RUB")]
+    assert extract_prices("Entry $15") == [(Decimal(15), "USD")]
+    assert extract_prices("Цена — 20 евро") == [(Decimal(20), "EUR")]
+
+
+def test_extract_ignores_times_dates_and_ages():
+    """Test that numbers that aren't prices are skipped."""
+    assert extract_prices("Начало в 19:00, вход с 18:00") == []
+    assert extract_prices("Билеты 25 декабря в кассах") == []
+    assert extract_prices("Вход 18+") == []
+
+
+def test_price_range():
+    """Test ranges, free entry and mixed currencies."""
+    assert price_range("Билеты от 700 до 1200 р.") == PriceRange(Decimal(700), Decimal(1200))
+    assert price_range("Танцпол 500–900 ₽, VIP 3000 ₽") == PriceRange(
+        Decimal(500), Decimal(3000)
+    )
+    assert price_range("Вход свободный, 18+") == PriceRange(Decimal(0), Decimal(0))
+    # A conversion into another currency doesn't widen the range
+    assert price_range("1200 ₽ / 1300 ₽ ($15)") == PriceRange(Decimal(1200), Decimal(1300))
+    assert price_range("Концерт в 19:00") is None
@@ -0,0 +1,89 @@
+"""Tests for the priority lane."""
+
+from datetime import datetime
+
+import pytest
+
+from common.db.memory import MEMORY
+from common.db.models import RSSPost
+from digest_publisher import __main__ as digest_publisher
+from pipeline.config import PipelineConfig
+from pipeline.orchestrator import PipelineOrchestrator
+
+
+def test_priority_source_names():
+    config = PipelineConfig(priority_sources=" city_emergency, ,vk_city")
+    assert config.priority_source_names == {"city_emergency", "vk_city"}
+    assert PipelineConfig().priority_source_names == set()
+
+
+def test_priority_interval_is_validated():
+    with pytest.raises(ValueError):
+        PipelineConfig(priority_interval_minutes=0)
+
+
+@pytest.mark.asyncio
+async def test_priority_lane_runs_between_scheduled_runs(fake_clock):
+    config = PipelineConfig(
+        run_interval_minutes=60, priority_sources="city_emergency", priority_interval_minutes=25
+    )
+    orchestrator = PipelineOrchestrator(config)
+    lanes = []
+
+    async def lane():
+        lanes.append(fake_clock.now())
+
+    orchestrator.run_priority_lane = lane
+    await orchestrator._wait_for_next_run()
+
+    assert fake_clock.sleeps == [1500, 1500, 600]
+    assert lanes == [datetime(2026, 3, 1, 12, 25), datetime(2026, 3, 1, 12, 50)]
+
+
+@pytest.mark.asyncio
+async def test_wait_without_priority_sources(fake_clock):
+    orchestrator = PipelineOrchestrator(PipelineConfig(run_interval_minutes=60))
+    await orchestrator._wait_for_next_run()
+    assert fake_clock.sleeps == [3600]
+
+
+def test_format_post_html_escapes():
+    post = RSSPost(
+        link="https://t.me/city/1?a=1&b=2",
+        content="Перекрытие <улиц>",
+        title="Перекрытие улиц & мостов",
+        summary="С 10:00 закрыт мост",
+        event_status="cancelled",
+    )
+    message = digest_publisher.format_post_html(post)
+
+    assert message.startswith("⚡ <b>Перекрытие улиц &amp; мостов</b>")
+    assert "С 10:00 закрыт мост" in message
+    assert '<a href="https://t.me/city/1?a=1&amp;b=2">' in message
+
+
+@pytest.mark.asyncio
+async def test_publish_now_publishes_each_post_once(memory_storage, monkeypatch):
+    sent = []
+
+    class FakePublisher:
+        default_destination = "@city"
+
+        def label(self, destination):
+            return f"telegram:{destination}"
+
+        async def publish(self, message, destination):
+            sent.append((destination, message))
+
+    monkeypatch.setattr(digest_publisher, "get_publisher", lambda name: FakePublisher())
+    for number in (1, 2):
+        await MEMORY.posts.create(RSSPost(link=f"https://t.me/city/{number}", content="Авария"))
+    await MEMORY.posts.mark_as_published(["https://t.me/city/2"])
+
+    links = ["https://t.me/city/1", "https://t.me/city/2", "https://t.me/city/3"]
+    assert await digest_publisher.publish_now(links) == {"published_count": 1}
+    assert len(sent) == 1 and "https://t.me/city/1" in sent[0][1]
+    assert (await MEMORY.posts.get_by_link("https://t.me/city/1")).is_published
+
+    # Already published now, so a repeated lane doesn't send it again
+    assert await digest_publisher.publish_now(links) == {"published_count": 0}
@@ -0,0 +1,88 @@
+"""Tests for sponsored post placement and billing reports."""
+
+import io
+from datetime import datetime
+from decimal import Decimal
+
+from common.db.models import Promotion, RSSPost
+from digest_publisher.promotions import apply_promotions, render_promotion, select_promotions
+from promotions.report import report_rows, totals_by_sponsor, write_csv
+
+
+def make_promotion(**overrides):
+    fields = {
+        "id": 1,
+        "post_link": "https://t.me/mediarzn/1",
+        "sponsor": "Club",
+        "starts_at": datetime(2026, 2, 1),
+        "ends_at": datetime(2026, 2, 8),
+    }
+    fields.update(overrides)
+    return Promotion(**fields)
+
+
+def test_is_active():
+    """Test the promotion period and placement limit."""
+    promotion = make_promotion(max_placements=2, placements=1)
+
+    assert promotion.is_active(datetime(2026, 2, 1))
+    assert not promotion.is_active(datetime(2026, 2, 8))
+    assert not make_promotion(max_placements=2, placements=2).is_active(datetime(2026, 2, 3))
+
+
+def test_select_promotions_by_target():
+    """Test that targeted promotions only go to their output."""
+    untargeted = make_promotion(id=1)
+    targeted = make_promotion(id=2, target="telegram:@concerts")
+    other = make_promotion(id=3, target="telegram:@lectures")
+
+    selected = select_promotions([untargeted, targeted, other], "telegram:@concerts", limit=5)
+
+    assert [p.id for p in selected] == [1, 2]
+    limited = select_promotions([untargeted, targeted], "telegram:@concerts", limit=1)
+    assert limited == [untargeted]
+
+
+def test_apply_promotions_placement():
+    """Test that sponsored blocks are labeled, escaped and placed."""
+    top = make_promotion(id=1, sponsor="A & B")
+    bottom = make_promotion(id=2, placement="bottom", post_link="https://t.me/x/2")
+    post = RSSPost(link="https://t.me/mediarzn/1", content="Concert <tonight>")
+    sale = RSSPost(link="https://t.me/x/2", content="Sale")
+
+    digest = apply_promotions("DIGEST", [(top, post), (bottom, sale)], "Ad")
+    blocks = digest.split("\n\n")
+
+    assert blocks[1] == "DIGEST"
+    assert blocks[0] == render_promotion(top, post, "Ad")
+    assert "<b>Ad</b> · A &amp; B" in blocks[0]
+    assert "Concert &lt;tonight&gt;" in blocks[0]
+    assert 'href="https://t.me/x/2"' in blocks[2]
+
+
+def test_billing_report():
+    """Test report normalization, totals and CSV export."""
+    rows = report_rows(
+        [
+            {
+                "id": 1,
+                "sponsor": "Club",
+                "post_link": "https://t.me/mediarzn/1",
+                "starts_at": datetime(2026, 2, 1),
+                "ends_at": datetime(2026, 2, 8),
+                "placements": 3,
+                "price_per_placement": Decimal("1500"),
+                "amount": Decimal("4500"),
+                "currency": "RUB",
+            }
+        ]
+    )
+
+    assert rows[0]["amount"] == "4500.00"
+    assert totals_by_sponsor(rows) == {"Club": {"RUB": Decimal("4500.00")}}
+
+    out = io.StringIO()
+    write_csv(rows, out)
+    lines = out.getvalue().splitlines()
+    assert lines[0].startswith("promotion_id,sponsor,post_link")
+    assert lines[1].endswith(",3,1500.00,4500.00,RUB")
@@ -0,0 +1,155 @@
+"""Tests for the recovery queue of failed publishes."""
+
+import json
+from datetime import datetime, timedelta
+
+import pytest
+
+from api import publish_failures
+from api.server import HTTPError, HTTPServer, Request
+from common.db.memory import MEMORY
+from common.db.models import RSSPost
+from digest_publisher import __main__ as digest_publisher
+from digest_publisher import recovery
+
+START = datetime(2026, 3, 1, 12, 0)
+LINKS = ["https://t.me/city/1", "https://t.me/city/2"]
+
+
+class FloodError(Exception):
+    """Like Telegram's RetryAfter."""
+
+    def __init__(self, seconds):
+        super().__init__(f"Flood control exceeded. Retry in {seconds} seconds")
+        self.retry_after = seconds
+
+
+class FakePublisher:
+    default_destination = "@city"
+
+    def __init__(self):
+        self.sent = []
+        self.errors = []
+
+    def label(self, destination):
+        return f"telegram:{destination}"
+
+    async def publish(self, message, destination):
+        if self.errors:
+            raise self.errors.pop(0)
+        self.sent.append((destination, message))
+
+
+@pytest.fixture
+def publisher(monkeypatch, fake_clock):
+    publisher = FakePublisher()
+    for module in (digest_publisher, recovery, publish_failures):
+        monkeypatch.setattr(module, "get_publisher", lambda name: publisher)
+    monkeypatch.setattr(recovery.digest_publisher_settings, "publish_retry_minutes", "5,30")
+    monkeypatch.delenv("RULES_FILE", raising=False)
+    return publisher
+
+
+async def create_posts():
+    for link in LINKS:
+        await MEMORY.posts.create(RSSPost(link=link, content="Перекрытие моста"))
+
+
+def test_next_attempt(monkeypatch):
+    monkeypatch.setattr(recovery.digest_publisher_settings, "publish_retry_minutes", "5,30")
+    for attempts, error, expected in (
+        (1, ConnectionError("reset"), START + timedelta(minutes=5)),
+        (2, ConnectionError("reset"), START + timedelta(minutes=30)),
+        (3, ConnectionError("reset"), None),
+        (1, FloodError(600), START + timedelta(minutes=10)),
+        (1, FloodError(timedelta(seconds=60)), START + timedelta(minutes=5)),
+    ):
+        assert recovery.next_attempt(attempts, error, START) == expected
+
+
+@pytest.mark.asyncio
+async def test_failed_posts_are_retried_on_schedule(memory_storage, publisher, fake_clock):
+    await create_posts()
+    publisher.errors = [FloodError(600)]
+    with pytest.raises(RuntimeError):
+        await digest_publisher.publish_now(LINKS)
+
+    [failure] = await MEMORY.publish_failures.get_by_status(["pending"])
+    assert failure.links == [LINKS[0]]
+    assert failure.next_attempt_at == START + timedelta(minutes=10)
+    assert failure.error == "Flood control exceeded. Retry in 600 seconds"
+
+    # The queued post isn't sent twice; the next one goes out
+    assert await digest_publisher.publish_now(LINKS) == {"published_count": 1}
+    assert len(publisher.sent) == 1 and LINKS[1] in publisher.sent[0][1]
+    assert not (await MEMORY.posts.get_by_link(LINKS[0])).is_published
+
+    fake_clock.advance(timedelta(minutes=10))
+    assert await recovery.retry_due() == 1
+    assert publisher.sent[1] == ("@city", failure.message)
+    assert (await MEMORY.posts.get_by_link(LINKS[0])).is_published
+    sent = await MEMORY.publish_failures.get_by_id(failure.id)
+    assert (sent.status, sent.attempts) == ("sent", 2)
+    assert await recovery.queued_links() == set()
+
+
+@pytest.mark.asyncio
+async def test_retries_run_out(memory_storage, publisher, fake_clock):
+    failure_id = await recovery.queue_failure(
+        "telegram", "@city", "Дайджест", LINKS, [], ConnectionError("reset")
+    )
+    for minutes, status in ((5, "pending"), (30, "failed")):
+        publisher.errors = [ConnectionError("timed out")]
+        fake_clock.advance(timedelta(minutes=minutes))
+        assert await recovery.retry_due() == 0
+        failure = await MEMORY.publish_failures.get_by_id(failure_id)
+        assert (failure.status, failure.error) == (status, "timed out")
+
+    fake_clock.advance(timedelta(days=1))
+    assert await recovery.retry_due() == 0
+    assert publisher.sent == []
+    assert await recovery.queued_links() == set(LINKS)
+
+
+@pytest.mark.asyncio
+async def test_manual_retry_and_discard(memory_storage, publisher):
+    await create_posts()
+    server = HTTPServer()
+    publish_failures.register(server)
+    first = await recovery.queue_failure(
+        "telegram", "@city", "Первый", [LINKS[0]], [], ConnectionError("reset")
+    )
+    second = await recovery.queue_failure(
+        "telegram", "@city", "Второй", [LINKS[1]], [], ConnectionError("reset")
+    )
+
+    response = await server.dispatch(Request(method="GET", path="/publish-failures"))
+    data = json.loads(response.body)
+    assert [f["id"] for f in data] == [second, first]
+    assert data[0]["target"] == "telegram:@city" and "destination" not in data[0]
+
+    publisher.errors = [ConnectionError("still down")]
+    path = f"/publish-failures/{first}/retry"
+    response = await server.dispatch(Request(method="POST", path=path))
+    assert response.status == 502
+    assert json.loads(response.body)["error"] == "still down"
+
+    response = await server.dispatch(Request(method="POST", path=path))
+    assert (response.status, json.loads(response.body)["status"]) == (200, "sent")
+    assert publisher.sent == [("@city", "Первый")]
+    with pytest.raises(HTTPError) as error:
+        await server.dispatch(Request(method="POST", path=path))
+    assert error.value.status == 409
+
+    path = f"/publish-failures/{second}/discard"
+    response = await server.dispatch(Request(method="POST", path=path))
+    assert json.loads(response.body)["status"] == "discarded"
+    assert await recovery.queued_links() == set()
+    assert not (await MEMORY.posts.get_by_link(LINKS[1])).is_published
+
+    request = Request(method="GET", path="/publish-failures", query={"status": "sent"})
+    assert [f["id"] for f in json.loads((await server.dispatch(request)).body)] == [first]
+    for path in ("/publish-failures/x/retry", "/publish-failures/99/retry", "/publish-failures/1"):
+        with pytest.raises(HTTPError) as error:
+            await server.dispatch(Request(method="POST", path=path))
+        assert error.value.status == 404
@@ -0,0 +1,122 @@
+"""Tests for digest formatting and publishers."""
+
+import pytest
+
+from digest_publisher.config import digest_publisher_settings
+from digest_publisher.formatting import (
+    discord_embeds,
+    html_to_discord,
+    html_to_plain,
+    html_to_slack,
+    slack_blocks,
+    split_text,
+)
+from digest_publisher.publishers import (
+    DiscordPublisher,
+    MatrixPublisher,
+    SlackPublisher,
+    TelegramPublisher,
+    get_publisher,
+)
+
+DIGEST = (
+    "📅 <b>Понедельник</b>\n"
+    '<i>Концерт</i> в клубе &amp; баре — <a href="https://t.me/mediarzn/1?a=1&amp;b=2">пост</a>'
+)
+
+
+def test_html_to_slack():
+    """Test conversion to Slack mrkdwn with Slack escaping."""
+    assert html_to_slack(DIGEST) == (
+        "📅 *Понедельник*\n_Концерт_ в клубе &amp; баре — <https://t.me/mediarzn/1?a=1&b=2|пост>"
+    )
+    assert html_to_slack("a <u>b</u> 1 &lt; 2") == "a b 1 &lt; 2"
+
+
+def test_html_to_discord():
+    """Test conversion to Discord markdown."""
+    assert html_to_discord(DIGEST) == (
+        "📅 **Понедельник**\n*Концерт* в клубе & баре — [пост](https://t.me/mediarzn/1?a=1&b=2)"
+    )
+
+
+def test_split_text_prefers_paragraphs():
+    """Test that chunks respect the limit and paragraph boundaries."""
+    text = "\n\n".join(["a" * 40, "b" * 40, "c" * 150])
+
+    chunks = split_text(text, 100)
+
+    assert chunks[0] == "a" * 40 + "\n\n" + "b" * 40
+    assert all(len(chunk) <= 100 for chunk in chunks)
+    assert "".join(chunks[1:]) == "c" * 150
+
+
+def test_slack_blocks():
+    """Test that the header comes first and sections are split."""
+    messages = slack_blocks("\n\n".join(["x" * 2000] * 3))
+
+    assert len(messages) == 1
+    assert messages[0][0]["type"] == "header"
+    assert [block["type"] for block in messages[0][1:]] == ["section"] * 3
+
+
+def test_discord_embeds_respect_message_limit():
+    """Test that embeds are grouped under Discord's per-message limit."""
+    messages = discord_embeds("\n\n".join(["y" * 4000] * 3))
+
+    assert [len(embeds) for embeds in messages] == [1, 1, 1]
+    assert messages[0][0]["title"] == "News Digest"
+    assert "title" not in messages[1][0]
+
+
+def test_publisher_registry_and_labels():
+    """Test publisher lookup and that webhook URLs are not exposed in labels."""
+    assert isinstance(get_publisher("slack"), SlackPublisher)
+    assert isinstance(get_publisher("discord"), DiscordPublisher)
+
+    assert TelegramPublisher().label("@concerts") == "telegram:@concerts"
+    label = SlackPublisher().label("https://hooks.slack.com/services/T/B/secret")
+    assert label.startswith("slack:")
+    assert "secret" not in label
+
+
+def test_webhook_payloads():
+    """Test Slack and Discord payload shapes."""
+    slack = SlackPublisher().build_payloads(DIGEST)
+    discord = DiscordPublisher().build_payloads(DIGEST)
+
+    assert slack[0]["text"] == "News Digest"
+    assert slack[0]["blocks"][1]["text"]["type"] == "mrkdwn"
+    assert discord[0]["embeds"][0]["description"].startswith("📅 **Понедельник**")
+
+
+def test_matrix_messages():
+    """Test Matrix event content with HTML and plain text bodies."""
+    content = MatrixPublisher().build_messages(DIGEST)[0]
+
+    assert content["msgtype"] == "m.text"
+    assert content["format"] == "org.matrix.custom.html"
+    assert content["formatted_body"].startswith("📅 <b>Понедельник</b><br>\n<i>")
+    assert content["body"] == html_to_plain(DIGEST)
+    assert content["body"].endswith("пост (https://t.me/mediarzn/1?a=1&b=2)")
+    assert MatrixPublisher().resolve_room("!room:matrix.org") == "!room:matrix.org"
+
+
+@pytest.mark.asyncio
+async def test_matrix_resends_use_the_same_transactions(monkeypatch):
+    """Test that sending a message again doesn't post its parts twice."""
+    monkeypatch.setattr(digest_publisher_settings, "matrix_homeserver_url", "https://matrix.org")
+    monkeypatch.setattr(digest_publisher_settings, "matrix_access_token", "token")
+    publisher = MatrixPublisher()
+    publisher.max_length = len(DIGEST) + 1
+    paths = []
+    publisher._request = lambda method, path, **kwargs: paths.append(path)
+    # Two parts with the same text
+    message = f"{DIGEST}\n{DIGEST}"
+
+    await publisher.publish(message, "!room:matrix.org")
+    assert len(paths) == len(set(paths)) == 2
+    await publisher.publish(message, "!room:matrix.org")
+    assert paths[2:] == paths[:2]
+    await publisher.publish(message, "!other:matrix.org")
+    assert not set(paths[4:]) & set(paths[:2])
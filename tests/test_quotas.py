@@ -0,0 +1,169 @@
+"""Tests for tracking the quotas of external APIs."""
+
+import json
+from datetime import datetime, timedelta
+
+import pytest
+from telegram.error import RetryAfter
+
+from api import quotas as quotas_api
+from api.server import HTTPServer, Request
+from common import quotas
+from common.alerts import AlertManager
+from common.db.memory import MEMORY
+from common.db.models import Quota
+from digest_publisher import publishers
+
+START = datetime(2026, 3, 1, 12, 0)
+
+
+class FakeSink:
+    def __init__(self):
+        self.messages = []
+
+    def send(self, text):
+        self.messages.append(text)
+
+
+class FakeResponse:
+    def __init__(self, headers):
+        self.headers = headers
+
+    def raise_for_status(self):
+        pass
+
+
+@pytest.fixture
+def tracker(fake_clock):
+    quotas.tracker.reset()
+    yield quotas.tracker
+    quotas.tracker.reset()
+
+
+def test_parse_headers():
+    openai = {
+        "x-ratelimit-limit-requests": "500",
+        "x-ratelimit-remaining-requests": "499",
+        "x-ratelimit-reset-requests": "120ms",
+        "x-ratelimit-limit-tokens": "30000",
+        "x-ratelimit-remaining-tokens": "1200",
+        "x-ratelimit-reset-tokens": "6m0s",
+        "content-type": "application/json",
+    }
+    requests, tokens = quotas.parse_headers("openai", openai, START)
+    assert (requests.name, requests.limit, requests.remaining) == ("requests", 500, 499)
+    assert requests.reset_at == START + timedelta(milliseconds=120)
+    assert (tokens.name, tokens.remaining, tokens.reset_at) == (
+        "tokens",
+        1200,
+        START + timedelta(minutes=6),
+    )
+
+    discord = {
+        "X-RateLimit-Limit": "5",
+        "X-RateLimit-Remaining": "0",
+        "X-RateLimit-Reset-After": "2.5",
+    }
+    [quota] = quotas.parse_headers("discord", discord, START)
+    assert quota == Quota("discord", "requests", 5, 0, START + timedelta(seconds=2.5), START)
+
+    assert quotas.parse_headers("slack", {"Retry-After": "30"}, START) == []
+
+
+def test_parse_reset():
+    for value, expected in (
+        ("30", START + timedelta(seconds=30)),
+        ("1m30s", START + timedelta(seconds=90)),
+        ("1h2m", START + timedelta(minutes=62)),
+        (str(START.timestamp() + 60), START + timedelta(seconds=60)),
+        ("2026-03-01T12:05:00", START + timedelta(minutes=5)),
+        ("soon", None),
+    ):
+        assert quotas.parse_reset(value, START) == expected
+
+
+@pytest.mark.asyncio
+async def test_low_quotas_are_paced(fake_clock, tracker):
+    headers = {
+        "X-RateLimit-Limit": "100",
+        "X-RateLimit-Remaining": "50",
+        "X-RateLimit-Reset": "60",
+    }
+    quotas.tracker.observe("mastodon", headers)
+    await quotas.throttle("mastodon")
+    assert fake_clock.sleeps == []
+
+    quotas.tracker.observe("mastodon", {**headers, "X-RateLimit-Remaining": "5"})
+    [quota] = quotas.tracker.get("mastodon")
+    assert quotas.is_low(quota, START) and not quotas.is_low(quota, START, ratio=0.01)
+    await quotas.throttle("mastodon")
+    assert fake_clock.sleeps == [10.0]
+
+    # Used up: waits for the reset, unless that takes too long
+    quotas.tracker.block("mastodon", 30)
+    await quotas.throttle("mastodon")
+    assert fake_clock.sleeps[-1] == 30.0
+    quotas.tracker.block("mastodon", timedelta(minutes=5))
+    with pytest.raises(quotas.QuotaExhausted) as error:
+        await quotas.throttle("mastodon")
+    assert error.value.retry_after == 300.0
+
+    fake_clock.advance(timedelta(minutes=5))
+    await quotas.throttle("mastodon")
+    assert len(fake_clock.sleeps) == 2
+
+
+@pytest.mark.asyncio
+async def test_publishers_record_quotas(fake_clock, tracker, monkeypatch):
+    class FloodedBot:
+        def __init__(self, token=None):
+            pass
+
+        async def send_message(self, **kwargs):
+            raise RetryAfter(40)
+
+    monkeypatch.setattr(publishers, "Bot", FloodedBot)
+    monkeypatch.setattr(publishers.digest_publisher_settings, "telegram_bot_token", "token")
+    with pytest.raises(RetryAfter):
+        await publishers.TelegramPublisher().publish("Дайджест", "@city")
+    assert quotas.tracker.wait_seconds("telegram") == 40.0
+
+    headers = {"X-RateLimit-Remaining": "0", "X-RateLimit-Reset-After": "3"}
+    monkeypatch.setattr(publishers.requests, "post", lambda *args, **kwargs: FakeResponse(headers))
+    await publishers.DiscordPublisher().publish("Дайджест", "https://discord.test/hook")
+    assert quotas.tracker.wait_seconds("discord") == 3.0
+
+
+@pytest.mark.asyncio
+async def test_saved_quotas_and_alerts(memory_storage, fake_clock, tracker):
+    sink = FakeSink()
+    alerts = AlertManager([sink], cooldown_seconds=60)
+    quotas.tracker.observe(
+        "openai",
+        {
+            "x-ratelimit-limit-tokens": "30000",
+            "x-ratelimit-remaining-tokens": "1000",
+            "x-ratelimit-reset-tokens": "1h",
+        },
+    )
+    quotas.tracker.block("telegram", 20)
+    fake_clock.advance(timedelta(seconds=30))
+
+    [low] = await quotas.save_quotas(alerts)
+    assert (low.api, low.name) == ("openai", "tokens")
+    assert sink.messages == ["⚠️ Quota: 1000 of 30000 openai tokens left until 2026-03-01 13:00"]
+
+    server = HTTPServer()
+    quotas_api.register(server)
+    response = await server.dispatch(Request(method="GET", path="/quotas"))
+    data = json.loads(response.body)
+    assert [(q["api"], q["name"], q["low"]) for q in data] == [
+        ("openai", "tokens", True),
+        ("telegram", "requests", False),
+    ]
+
+    # Once the quota resets, the next run resolves the alert
+    fake_clock.advance(timedelta(hours=1))
+    assert await quotas.save_quotas(alerts) == []
+    assert "quota_low:openai:tokens" not in alerts._state
+    assert len(await MEMORY.quotas.get_all()) == 2
@@ -0,0 +1,319 @@
+"""Tests for recording and replaying source fetches."""
+
+import gzip
+import os
+import sys
+import zlib
+from datetime import datetime, timedelta
+
+import pytest
+import requests
+from requests.exceptions import ContentDecodingError
+
+from common.clock import FakeClock, set_clock
+from common.db.models import TelegramChannel
+from common.utils.rss_bridge import build_rss_bridge_url
+from rss_reader.__main__ import recorded_channels
+from rss_reader.core.external import ExternalSource, ExternalSourceError
+from rss_reader.core import fetcher
+from rss_reader.core.discovery import FeedLink, discover
+from rss_reader.core.fetcher import FeedFetcher, FetchDeadlineExceeded
+from rss_reader.core.recordings import (
+    RECORD,
+    REPLAY,
+    HTTPRecording,
+    ProcessRecording,
+    RecordingNotFound,
+    Recordings,
+)
+
+FEED_URL = build_rss_bridge_url("club")
+FEED = "<rss><channel><title>Club</title></channel></rss>"
+
+
+class FakeResponse:
+    def __init__(self, status, text, headers=None):
+        self.status_code, self.content = status, text.encode()
+        self.headers = headers or {"Content-Type": "text/xml"}
+
+    def raise_for_status(self):
+        if self.status_code >= 400:
+            raise requests.HTTPError(f"{self.status_code} Error")
+
+
+class FakeSession:
+    def __init__(self, status=200, text=FEED):
+        self.headers = {}
+        self.status, self.text = status, text
+        self.calls = 0
+
+    def get(self, url, timeout=None):
+        self.calls += 1
+        return FakeResponse(self.status, self.text)
+
+
+def test_recorded_feed_is_replayed(tmp_path):
+    recorder = FeedFetcher(recordings=Recordings(str(tmp_path), RECORD))
+    recorder.session = FakeSession()
+    assert recorder.fetch(FEED_URL) == FEED
+
+    player = FeedFetcher(recordings=Recordings(str(tmp_path), REPLAY))
+    player.session = FakeSession(text="should not be fetched")
+    assert player.fetch(FEED_URL) == FEED
+    assert player.session.calls == 0
+
+
+def test_recorded_error_is_replayed(tmp_path):
+    recorder = FeedFetcher(recordings=Recordings(str(tmp_path), RECORD))
+    recorder.session = FakeSession(status=503, text="Unavailable")
+    with pytest.raises(requests.HTTPError):
+        recorder.fetch(FEED_URL)
+
+    player = FeedFetcher(recordings=Recordings(str(tmp_path), REPLAY))
+    with pytest.raises(requests.HTTPError, match="503"):
+        player.fetch(FEED_URL)
+
+
+def test_replay_without_recording_fails(tmp_path):
+    player = FeedFetcher(recordings=Recordings(str(tmp_path), REPLAY))
+    with pytest.raises(RecordingNotFound):
+        player.fetch(FEED_URL)
+
+
+@pytest.mark.asyncio
+async def test_external_source_output_is_replayed(tmp_path):
+    script = (
+        "import sys; print('{\"link\": \"https://example.com/1\", \"content\": \"Hi\"}'); "
+        "print('warming up', file=sys.stderr)"
+    )
+    source = ExternalSource(name="vk city", command=[sys.executable, "-c", script])
+    items = await source.fetch(Recordings(str(tmp_path), RECORD))
+    assert [item.link for item in items] == ["https://example.com/1"]
+
+    offline = ExternalSource(name="vk city", command=["/nonexistent/source"])
+    replayed = await offline.fetch(Recordings(str(tmp_path), REPLAY))
+    assert [item.description for item in replayed] == ["Hi"]
+
+
+@pytest.mark.asyncio
+async def test_replayed_failure_still_fails(tmp_path):
+    source = ExternalSource(name="broken", command=[sys.executable, "-c", "exit(3)"])
+    with pytest.raises(ExternalSourceError, match="status 3"):
+        await source.fetch(Recordings(str(tmp_path), RECORD))
+
+    with pytest.raises(ExternalSourceError, match="status 3"):
+        await source.fetch(Recordings(str(tmp_path), REPLAY))
+
+
+def test_recorded_channels_adds_missing_ones(tmp_path):
+    recordings = Recordings(str(tmp_path), REPLAY)
+    for name in ("club", "theatre"):
+        recordings.save_http(HTTPRecording(build_rss_bridge_url(name), 200, {}, FEED))
+
+    known = [TelegramChannel(channel_id=1, channel_name="club")]
+    assert [c.channel_name for c in recorded_channels(recordings, known)] == ["theatre"]
+
+
+def test_old_recordings_are_compressed(tmp_path):
+    previous = set_clock(FakeClock(datetime(2026, 3, 1, 12, 0)))
+    try:
+        recordings = Recordings(str(tmp_path), RECORD)
+        big = FEED.replace("Club", "Club " * 2000)
+        recordings.save_http(HTTPRecording(FEED_URL, 200, {}, big))
+        recordings.save_process(ProcessRecording("vk city", 0, "{}", ""))
+        [old, recent] = sorted(tmp_path.glob("*.json"))
+        size = old.stat().st_size
+        week_ago = (datetime(2026, 3, 1, 12, 0) - timedelta(days=8)).timestamp()
+        os.utime(old, (week_ago, week_ago))
+
+        assert recordings.compress_older_than(7) == 1
+        assert sorted(p.name for p in tmp_path.iterdir()) == [old.name + ".zst", recent.name]
+        assert (tmp_path / (old.name + ".zst")).stat().st_size < size / 10
+
+        player = Recordings(str(tmp_path), REPLAY)
+        assert player.load_http(FEED_URL).body == big
+        assert player.recorded_urls() == [FEED_URL]
+
+        # Recording again replaces the compressed recording
+        recordings.save_http(HTTPRecording(FEED_URL, 200, {}, FEED))
+        assert not (tmp_path / (old.name + ".zst")).exists()
+        assert player.load_http(FEED_URL).body == FEED
+    finally:
+        set_clock(previous)
+
+
+def test_mode_from_env(monkeypatch, tmp_path):
+    monkeypatch.setenv("FETCH_RECORDING_MODE", "replay")
+    monkeypatch.setenv("FETCH_RECORDING_DIR", str(tmp_path))
+    recordings = Recordings.from_env()
+    assert recordings.replaying and recordings.directory == tmp_path
+
+    monkeypatch.setenv("FETCH_RECORDING_MODE", "off")
+    assert Recordings.from_env() is None
+
+    monkeypatch.setenv("FETCH_RECORDING_MODE", "later")
+    with pytest.raises(ValueError):
+        Recordings.from_env()
+    monkeypatch.delenv("FETCH_RECORDING_MODE")
+
+
+def test_slow_feed_hits_the_deadline(monkeypatch):
+    class StreamingResponse(FakeResponse):
+        def __init__(self):
+            self.status_code, self.headers = 200, {"Content-Type": "text/xml"}
+
+        @property
+        def content(self):
+            return self._content
+
+        def iter_content(self, chunk_size):
+            for part in ("<rss><channel>", "<title>Club</title>", "</channel></rss>"):
+                yield part.encode()
+
+        def close(self):
+            pass
+
+    class StreamingSession(FakeSession):
+        def get(self, url, timeout=None, stream=False):
+            assert stream
+            return StreamingResponse()
+
+    times = iter([0.0, 1.0, 2.0, 3.0])
+    monkeypatch.setattr(fetcher.time, "monotonic", lambda: next(times))
+    slow = FeedFetcher(deadline=1.5)
+    slow.session = StreamingSession()
+    with pytest.raises(FetchDeadlineExceeded, match="longer than 1.5s"):
+        slow.fetch(FEED_URL)
+
+    times = iter([0.0, 0.1, 0.2, 0.3])
+    assert slow.fetch(FEED_URL) == FEED
+
+
+def test_fetchers_share_a_pooled_session():
+    first, second = FeedFetcher(), FeedFetcher(timeout=30)
+    assert first.session is second.session
+    adapter = first.session.adapters["https://"]
+    assert adapter is first.session.adapters["http://"]
+    assert adapter._pool_maxsize == adapter._pool_connections >= 10
+
+
+def test_injected_session_and_headers():
+    session = FakeSession()
+    requests_seen = []
+
+    def get(url, timeout=None, headers=None):
+        requests_seen.append((url, timeout, headers))
+        return FakeResponse(200, FEED)
+
+    session.get = get
+    custom = FeedFetcher(
+        timeout=5, session=session, headers={"X-Token": "secret"}, user_agent="EventsBot/2.0"
+    )
+    assert custom.fetch(FEED_URL) == FEED
+    assert requests_seen == [(FEED_URL, 5, {"X-Token": "secret", "User-Agent": "EventsBot/2.0"})]
+    assert FeedFetcher().session is not session
+
+
+def test_unchanged_feed_is_not_downloaded_again():
+    session = FakeSession()
+    sent = []
+
+    def get(url, timeout=None, headers=None):
+        sent.append(headers or {})
+        if (headers or {}).get("If-None-Match") == '"v1"':
+            return FakeResponse(304, "")
+        response = FakeResponse(200, FEED)
+        response.headers["ETag"] = '"v1"'
+        response.headers["Last-Modified"] = "Sat, 10 Jan 2026 12:00:00 GMT"
+        return response
+
+    session.get = get
+    conditional = FeedFetcher(session=session, conditional=True)
+    url = FEED_URL + "&conditional"
+    assert conditional.fetch(url) == FEED
+    with pytest.raises(fetcher.FeedNotModified):
+        conditional.fetch(url)
+    assert sent[1] == {
+        "If-None-Match": '"v1"',
+        "If-Modified-Since": "Sat, 10 Jan 2026 12:00:00 GMT",
+    }
+
+    # Forgotten validators, and fetchers without conditional requests, get the feed in full
+    conditional.forget(url)
+    assert conditional.fetch(url) == FEED
+    assert FeedFetcher(session=session).fetch(url) == FEED
+    assert sent[2] == sent[3] == {}
+    conditional.forget(url)
+
+
+def test_pages_are_fetched_without_validators():
+    session = FakeSession()
+    sent = []
+
+    def get(url, timeout=None, headers=None):
+        sent.append(headers or {})
+        response = FakeResponse(200, FEED)
+        response.headers["ETag"] = '"v1"'
+        return response
+
+    session.get = get
+    conditional = FeedFetcher(session=session, conditional=True)
+    url = FEED_URL + "&page"
+    assert conditional.fetch(url) == FEED
+    # The validators fetch() remembered aren't sent, a page is never answered with a 304
+    assert conditional.fetch_page(url) == FEED
+    assert sent[1] == {}
+    conditional.forget(url)
+
+
+def test_compressed_feed_is_decompressed():
+    """Test that bodies a transport left compressed are inflated before parsing."""
+    body = FEED.encode()
+    raw = zlib.compressobj(wbits=-zlib.MAX_WBITS)
+    cases = [
+        (gzip.compress(body), {"Content-Encoding": "gzip"}),
+        # No header at all, and compressed twice by a proxy
+        (gzip.compress(gzip.compress(body)), {}),
+        (zlib.compress(body), {"Content-Encoding": "deflate"}),
+        (raw.compress(body) + raw.flush(), {"Content-Encoding": "deflate"}),
+        # Already decompressed by requests, the header left in place
+        (body, {"Content-Encoding": "gzip"}),
+    ]
+    for compressed, headers in cases:
+        response = FakeResponse(200, "", headers={"Content-Type": "text/xml", **headers})
+        response.content = compressed
+        session = FakeSession()
+        session.get = lambda url, timeout=None, response=response: response
+        assert FeedFetcher(session=session).fetch(FEED_URL) == FEED, headers
+
+    assert fetcher.shared_session().headers["Accept-Encoding"] == "gzip, deflate"
+    with pytest.raises(ContentDecodingError, match="Corrupt"):
+        fetcher.decompress_body(gzip.compress(body)[:-12])
+
+
+def test_feeds_are_discovered_from_page():
+    page = """<!doctype html><html><head>
+    <base href="https://club.example/ru/">
+    <link rel="stylesheet" href="/style.css">
+    <link rel="alternate" type="application/rss+xml" title="Афиша
+        клуба" href="afisha.rss">
+    <link rel="ALTERNATE" type="application/atom+xml; charset=utf-8" href="/atom.xml">
+    <link rel="alternate" type="application/feed+json" href="https://club.example/feed.json">
+    <link rel="alternate" type="application/rss+xml" href="afisha.rss">
+    <link rel="alternate" type="application/json" href="/wp-json/wp/v2/pages/1">
+    <link rel="alternate" type="application/rss+xml" href="javascript:void(0)">
+    <link rel="alternate" hreflang="en" href="/en/">
+    </head><body></body></html>"""
+    response = FakeResponse(200, page, headers={"Content-Type": "text/html"})
+    session = FakeSession()
+    session.get = lambda url, timeout=None: response
+
+    assert discover("https://club.example/", FeedFetcher(session=session)) == [
+        FeedLink("https://club.example/ru/afisha.rss", "rss", "Афиша клуба"),
+        FeedLink("https://club.example/atom.xml", "atom"),
+        FeedLink("https://club.example/feed.json", "json"),
+    ]
+
+    # A feed URL is its own feed
+    session.get = lambda url, timeout=None: FakeResponse(200, FEED)
+    assert discover(FEED_URL, FeedFetcher(session=session)) == [FeedLink(FEED_URL, "rss")]
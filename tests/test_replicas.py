@@ -0,0 +1,76 @@
+"""Tests for reads served by database replicas."""
+
+from contextlib import asynccontextmanager
+from datetime import datetime, timedelta
+
+import pytest
+
+from common.clock import FakeClock, set_clock
+from common.db.session import REPLICA_RETRY_SECONDS, Database, reads_from_replicas
+
+
+class FakeConnection:
+    def __init__(self, name):
+        self.name = name
+
+    async def fetchval(self, query, *args):
+        return self.name
+
+    async def execute(self, query, *args):
+        return f"{self.name} {query}"
+
+
+class FakePool:
+    def __init__(self, name, reachable=True):
+        self.name = name
+        self.reachable = reachable
+
+    @asynccontextmanager
+    async def acquire(self):
+        if not self.reachable:
+            raise ConnectionRefusedError(f"{self.name} refused the connection")
+        yield FakeConnection(self.name)
+
+
+@pytest.mark.asyncio
+async def test_reads_prefer_the_first_reachable_replica():
+    fake = FakeClock(datetime(2026, 3, 1, 12, 0))
+    previous = set_clock(fake)
+    try:
+        database = Database()
+        nearby = FakePool("nearby", reachable=False)
+        database.pool = FakePool("primary")
+        database.replicas = [nearby, FakePool("remote")]
+
+        assert await database.fetchval("SELECT 1") == "primary"
+        with database.prefer_replicas():
+            assert await database.fetchval("SELECT 1") == "remote"
+            # Writes never go to a replica
+            assert await database.execute("DELETE") == "primary DELETE"
+
+            # The unreachable replica is left alone for a while, then tried again
+            nearby.reachable = True
+            assert await database.fetchval("SELECT 1") == "remote"
+            fake.advance(timedelta(seconds=REPLICA_RETRY_SECONDS + 1))
+            assert await database.fetchval("SELECT 1") == "nearby"
+
+            database.replicas = []
+            assert await database.fetchval("SELECT 1") == "primary"
+        assert await database.fetchval("SELECT 1") == "primary"
+    finally:
+        set_clock(previous)
+
+
+@pytest.mark.asyncio
+async def test_wrapped_functions_read_from_replicas(monkeypatch):
+    from common.db import session
+
+    monkeypatch.setattr(session.db, "pool", FakePool("primary"))
+    monkeypatch.setattr(session.db, "replicas", [FakePool("replica")])
+
+    async def handler(query):
+        return await session.db.fetchval(query)
+
+    assert await handler("SELECT 1") == "primary"
+    assert await reads_from_replicas(handler)("SELECT 1") == "replica"
+    assert await handler("SELECT 1") == "primary"
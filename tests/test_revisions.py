@@ -0,0 +1,86 @@
+"""Tests for storing the edits of posts as diffs."""
+
+import json
+
+import pytest
+
+from api import revisions as revisions_api
+from api.server import HTTPError, HTTPServer, Request
+from common import revisions
+from common.db.memory import MEMORY
+from common.models.feed import RSSItem
+from rss_reader.__main__ import save_items
+
+LINK = "https://t.me/club/1"
+PROGRAMME = "\n\nВ программе: " + ", ".join(f"пьеса №{n}" for n in range(1, 30))
+EDITS = [
+    "Концерт в клубе 12 марта в 19:00. Вход свободный" + PROGRAMME,
+    "Концерт в клубе перенесён на 14 марта, 20:00. Вход свободный" + PROGRAMME,
+    "Концерт в клубе перенесён на 14 марта, 20:00. Вход по регистрации" + PROGRAMME,
+    "Концерт отменён",
+    "",
+]
+
+
+def test_diff_and_patch():
+    for old in EDITS:
+        for new in EDITS:
+            delta = revisions.diff(old, new)
+            assert revisions.patch(old, delta) == new
+
+    delta = revisions.diff(EDITS[0], EDITS[1])
+    assert len(delta) < len(EDITS[1]) / 4 and "В программе" not in delta
+    assert revisions.diff(EDITS[0], EDITS[0]) == "[]"
+    for bad in ('[100]', '[-100]', '[1.5]', '{"keep": 1}'):
+        with pytest.raises(ValueError):
+            revisions.patch("short", bad)
+
+
+@pytest.mark.asyncio
+async def test_edits_are_stored_as_revisions(memory_storage):
+    for text in EDITS[:3] + [EDITS[2]]:
+        await save_items("club", [RSSItem(link=LINK, description=text)])
+    stored = await MEMORY.revisions.get_by_link(LINK)
+    assert [r.revision for r in stored] == [1, 2]
+    assert all(len(r.delta) < len(PROGRAMME) for r in stored)
+
+    # Going back to the stored text is an edit too
+    await save_items("club", [RSSItem(link=LINK, description=EDITS[0])])
+    post = await MEMORY.posts.get_by_link(LINK)
+    assert post.content == EDITS[0]
+    for number, text in enumerate(EDITS[:3] + [EDITS[0]]):
+        assert revisions.rebuild(post, await MEMORY.revisions.get_by_link(LINK), number) == text
+
+    server = HTTPServer()
+    revisions_api.register(server)
+    request = Request(method="GET", path="/posts/revisions", query={"link": LINK})
+    data = json.loads((await server.dispatch(request)).body)
+    assert [r["revision"] for r in data] == [0, 1, 2, 3]
+    assert data[0]["content_hash"] == data[3]["content_hash"] == revisions.content_hash(EDITS[0])
+
+    request.query["revision"] = "2"
+    data = json.loads((await server.dispatch(request)).body)
+    assert data == {"link": LINK, "revision": 2, "content": EDITS[2]}
+    for query, status in (
+        ({"link": LINK, "revision": "4"}, 404),
+        ({"link": LINK, "revision": "last"}, 400),
+        ({"link": "https://t.me/club/2"}, 404),
+        ({}, 400),
+    ):
+        with pytest.raises(HTTPError) as error:
+            await server.dispatch(Request(method="GET", path="/posts/revisions", query=query))
+        assert error.value.status == status
+
+
+@pytest.mark.asyncio
+async def test_items_are_recognized_by_guid_after_their_link_changes(memory_storage):
+    await save_items("club", [RSSItem(link=LINK, description=EDITS[0], guid="post-1")])
+    moved = RSSItem(link="https://club.example/afisha/1", description=EDITS[1], guid="post-1")
+    await save_items("club", [moved])
+
+    assert not await MEMORY.posts.exists_by_link(moved.link)
+    assert [r.revision for r in await MEMORY.revisions.get_by_link(LINK)] == [1]
+
+    # GUIDs are only unique within a source
+    await save_items("bar", [moved])
+    assert (await MEMORY.posts.get_by_link(moved.link)).source_guid == "bar:post-1"
@@ -0,0 +1,146 @@
+"""Tests for the pipeline rule expression language."""
+
+import json
+
+import pytest
+
+from common.rules import RuleError, compile_expression, load_rules, post_variables
+
+
+def make_post(**overrides):
+    fields = {"channel": "mediarzn", "link": "https://t.me/mediarzn/1", "content": "Текст"}
+    fields.update(overrides)
+    return post_variables(**fields)
+
+
+def test_channel_and_contains():
+    """Test the canonical channel + content rule."""
+    rule = compile_expression('post.channel == "mediarzn" && post.content.contains("концерт")')
+
+    assert rule.matches(make_post(content="Большой концерт в субботу"))
+    assert not rule.matches(make_post(content="Выставка"))
+    assert not rule.matches(make_post(channel="other", content="концерт"))
+
+
+def test_event_format():
+    """Test routing on the detected event format."""
+    rule = compile_expression('post.format == "online" || post.format == "hybrid"')
+
+    assert rule.matches(make_post(event_format="online"))
+    assert not rule.matches(make_post(event_format="offline"))
+    assert not rule.matches(make_post())
+
+
+def test_operators_and_precedence():
+    """Test boolean, arithmetic and comparison operators."""
+    variables = make_post(tags=["music", "free"], media_urls=["a", "b"])
+
+    assert compile_expression("1 + 2 * 3 == 7").evaluate(variables)
+    assert compile_expression("!(1 > 2) || false").evaluate(variables)
+    assert compile_expression('"free" in post.tags && size(post.media_urls) == 2').evaluate(
+        variables
+    )
+    ternary = compile_expression("post.tags.size() > 1 ? 'many' : 'few'")
+    assert ternary.evaluate(variables) == "many"
+    assert compile_expression('post.link.startsWith("https://t.me/")').evaluate(variables)
+    assert compile_expression('post.content.matches("^Т")').evaluate(variables)
+    assert compile_expression("post.tags[0] == 'music'").evaluate(variables)
+    assert not compile_expression("has(post.pub_date)").evaluate(variables)
+
+
+def test_compile_time_errors():
+    """Test that invalid expressions are rejected when compiled."""
+    invalid = [
+        "",
+        'post.channel == "a" &&',
+        "post.chanel == 'a'",
+        "user.id == 1",
+        "post.content.includes('x')",
+        "post.content.contains()",
+        "(1 + 2",
+        "post.content @ 'x'",
+        "__import__('os')",
+    ]
+    for source in invalid:
+        with pytest.raises(RuleError):
+            compile_expression(source)
+
+
+def test_runtime_errors_are_rule_errors():
+    """Test that type errors surface as RuleError."""
+    rule = compile_expression("post.content > 1")
+
+    with pytest.raises(RuleError):
+        rule.evaluate(make_post())
+
+
+def test_no_python_attributes():
+    """Test that fields are looked up in the variables only."""
+    for source in ("post.content.__class__.__name__ == 'str'", "post.tags.count"):
+        with pytest.raises(RuleError, match="has no field"):
+            compile_expression(source).evaluate(make_post(tags=["a"]))
+
+
+def test_lower_ascii():
+    """Test that lowerAscii() only lowercases A-Z, like CEL."""
+    rule = compile_expression("post.content.lowerAscii()")
+
+    assert rule.evaluate(make_post(content="ÀB Концерт")) == "Àb Концерт"
+
+
+def test_load_rules(tmp_path):
+    """Test loading filters and reporting the broken rule's name."""
+    path = tmp_path / "rules.json"
+    path.write_text(
+        json.dumps(
+            {
+                "filters": [
+                    {"name": "no_ads", "when": "post.content.contains('#ad')", "action": "drop"},
+                    {"name": "tag", "when": "true", "action": "tag", "tags": ["all"]},
+                ]
+            }
+        )
+    )
+
+    rules = load_rules(str(path))
+
+    assert [rule.name for rule in rules.filters] == ["no_ads", "tag"]
+    assert rules.filters[1].tags == ["all"]
+
+    path.write_text(json.dumps({"filters": [{"name": "broken", "when": "post.x"}]}))
+    with pytest.raises(RuleError, match="broken"):
+        load_rules(str(path))
+
+
+def test_routes(tmp_path):
+    """Test that the first matching route wins."""
+    path = tmp_path / "rules.json"
+    path.write_text(
+        json.dumps(
+            {
+                "routes": [
+                    {
+                        "name": "concerts",
+                        "when": "'concert' in post.tags",
+                        "publisher": "telegram",
+                        "destination": "@concerts",
+                    },
+                    {
+                        "name": "lectures",
+                        "when": "post.content.contains('лекция')",
+                        "publisher": "telegram",
+                        "destination": "@lectures",
+                    },
+                ]
+            }
+        )
+    )
+    rules = load_rules(str(path))
+
+    assert rules.route(make_post(tags=["concert"], content="лекция")).name == "concerts"
+    assert rules.route(make_post(content="Открытая лекция")).destination == "@lectures"
+    assert rules.route(make_post()) is None
+
+    path.write_text(json.dumps({"routes": [{"name": "bad", "when": "true"}]}))
+    with pytest.raises(RuleError, match="publisher"):
+        load_rules(str(path))
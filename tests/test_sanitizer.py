@@ -0,0 +1,54 @@
+"""Tests for profanity masking of public output."""
+
+from common.features import FeatureFlags
+from common.sanitizer import DEFAULT_WORDS, Sanitizer, sanitize_for
+
+
+def test_masks_words_and_stems():
+    """Test exact words, stem entries and case handling."""
+    sanitizer = Sanitizer(["сука", "бля*"])
+    assert sanitizer.sanitize("Сука, блядский концерт!") == "С***, б******* концерт!"
+    # Exact entries don't match inside longer words
+    assert sanitizer.sanitize("сукахари") == "сукахари"
+
+
+def test_yo_and_ye_are_interchangeable():
+    """Test that 'ё' and 'е' spellings both match and keep the original letters."""
+    sanitizer = Sanitizer(["ёбан*"])
+    assert sanitizer.sanitize("ебаный и ёбаный") == "е***** и ё*****"
+
+
+def test_sanitize_html_keeps_markup():
+    """Test that tags, attributes and entities are never masked."""
+    sanitizer = Sanitizer(["shit*"], mask_char="#")
+    html = '<a href="https://example.com/shit">Shitty &amp; loud</a>'
+    assert sanitizer.sanitize_html(html) == (
+        '<a href="https://example.com/shit">S##### &amp; loud</a>'
+    )
+
+
+def test_from_file_skips_comments(tmp_path):
+    """Test loading a word list with comments and blank lines."""
+    path = tmp_path / "words.txt"
+    path.write_text("# local slang\nблин\n\nчерт*  # mild\n", encoding="utf-8")
+    sanitizer = Sanitizer.from_file(str(path))
+    assert sanitizer.sanitize("Блин, чертовски хорошо") == "Б***, ч******** хорошо"
+
+
+def test_empty_word_list_changes_nothing():
+    """Test that a sanitizer without words returns the text unchanged."""
+    assert Sanitizer([]).sanitize("anything") == "anything"
+
+
+def test_sanitize_for_follows_target_policy():
+    """Test that masking is applied only to targets with the flag enabled."""
+    flags = FeatureFlags("profanity_filter=on,profanity_filter[telegram:@night]=off")
+    text = "<b>Fucking</b> great"
+    assert sanitize_for("telegram:@city", text, html=True, flags=flags) == "<b>F******</b> great"
+    assert sanitize_for("telegram:@night", text, html=True, flags=flags) == text
+    assert sanitize_for("mastodon:example.org", text, flags=FeatureFlags("")) == text
+
+
+def test_default_words_are_valid():
+    """Test that the built-in list compiles and masks a common word."""
+    assert Sanitizer(DEFAULT_WORDS).sanitize("ну пиздец") == "ну п*****"
@@ -0,0 +1,112 @@
+"""Tests for festival and series grouping."""
+
+from datetime import datetime
+
+import pytest
+
+from common.db.models import RSSPost, Series
+from common.db.repository import RSSPostRepository, SeriesRepository
+from common.pages import SiteSettings, render_event_page, render_index
+from common.series import assign_series, detect_series, group_by_series, plural_events
+
+FESTIVAL = Series(key="#джазфест", name="Джазфест", event_count=12)
+
+
+def event(number: int, day: int, series_key=None) -> RSSPost:
+    return RSSPost(
+        link=f"https://t.me/club/{number}",
+        content=f"Событие {number}",
+        pub_date=datetime(2026, 3, day, 19, 0),
+        title=f"Событие {number}",
+        series_key=series_key,
+    )
+
+
+def test_detect_series():
+    """Test festival hashtags, parent announcements and unrelated links."""
+    series = detect_series("https://t.me/club/5", "Концерт #джаз #ДжазФест2026")
+    assert (series.key, series.name) == ("#джазфест2026", "ДжазФест2026")
+    assert detect_series("https://t.me/club/5", "#неделя_науки").name == "Неделя науки"
+    assert detect_series("https://t.me/club/5", "Концерт #джаз #концерт") is None
+
+    parent = detect_series("https://t.me/club/5", "Программа: https://t.me/club/120")
+    assert (parent.key, parent.parent_link) == ("https://t.me/club/120", "https://t.me/club/120")
+    assert detect_series("https://t.me/club/5", "Репост t.me/other/120") is None
+    assert detect_series("https://t.me/club/5", "Ссылка на себя t.me/club/5") is None
+
+
+@pytest.fixture
+def series_table(monkeypatch):
+    """In-memory series table and posts."""
+    created = {}
+    added = {}
+    posts = {
+        "https://t.me/club/120": RSSPost(
+            link="https://t.me/club/120", content="Фестиваль «Весна»\nПрограмма"
+        )
+    }
+
+    async def create(series):
+        created.setdefault(series.key, series)
+
+    async def add_post(link, key):
+        added[link] = key
+
+    async def get_by_link(link):
+        return posts.get(link)
+
+    monkeypatch.setattr(SeriesRepository, "create", create)
+    monkeypatch.setattr(SeriesRepository, "add_post", add_post)
+    monkeypatch.setattr(RSSPostRepository, "get_by_link", get_by_link)
+    return created, added
+
+
+@pytest.mark.asyncio
+async def test_assign_series_with_parent(series_table):
+    """Test that a parent announcement names the series and joins it."""
+    created, added = series_table
+    post = RSSPost(link="https://t.me/club/121", content="День 1, подробнее t.me/club/120")
+
+    assert await assign_series(post) == "https://t.me/club/120"
+    assert created["https://t.me/club/120"].name == "Фестиваль «Весна»"
+    assert added == {"https://t.me/club/120": "https://t.me/club/120"}
+
+    unknown = RSSPost(link="https://t.me/club/122", content="См. t.me/club/99")
+    assert await assign_series(unknown) is None
+
+
+def test_group_by_series():
+    """Test that single-event series stay in the flat list."""
+    posts = [
+        event(1, 5, "#джазфест"),
+        event(2, 5),
+        event(3, 6, "#джазфест"),
+        event(4, 7, "#other"),
+    ]
+    other = Series(key="#other", name="Other")
+    groups, standalone = group_by_series(posts, {"#джазфест": FESTIVAL, "#other": other})
+
+    assert [(group.key, [p.link[-1] for p in items]) for group, items in groups] == [
+        ("#джазфест", ["1", "3"])
+    ]
+    assert [post.link[-1] for post in standalone] == ["2", "4"]
+    assert [plural_events(n) for n in (1, 3, 12, 21, 111)] == [
+        "1 событие",
+        "3 события",
+        "12 событий",
+        "21 событие",
+        "111 событий",
+    ]
+
+
+def test_series_on_index_and_event_page():
+    """Test the series list on the index and the note on event pages."""
+    posts = [event(1, 5, "#джазфест"), event(2, 5, "#джазфест"), event(3, 5)]
+    html = render_index(posts, SiteSettings(), {"#джазфест": FESTIVAL})
+
+    assert '<li id="series-1"><b>Джазфест</b>: 2 события' in html
+    assert '<li><a href="#series-1">Джазфест</a>: 2 события</li>' in html
+    assert html.count('href="events/club-1.html"') == 1
+
+    page = render_event_page(posts[0], SiteSettings(), FESTIVAL)
+    assert "Часть серии «Джазфест» · 12 событий" in page
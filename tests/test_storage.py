@@ -0,0 +1,31 @@
+"""Storage conformance tests for the Postgres backend.
+
+Needs a migrated database at TEST_DATABASE_DSN; skipped when it isn't reachable.
+"""
+
+import pytest
+
+from common.db.session import db
+from common.db.storage import POSTGRES
+from tests.storagetest import run_repository_tests
+
+TABLES = (
+    "event_interactions, promotion_placements, promotions, post_deliveries, translations, "
+    "rss_posts, series, telegram_channels, poll_run_items, poll_runs, submissions, "
+    "digest_drafts, publish_failures, api_quotas, source_bandwidth, post_revisions, "
+    "llm_cache, llm_usage, field_overrides"
+)
+
+
+@pytest.mark.asyncio
+async def test_postgres_conformance():
+    try:
+        await db.connect()
+    except Exception as error:
+        # Refused connection, missing database or wrong credentials
+        pytest.skip(f"Database not available: {error}")
+    try:
+        await db.execute(f"TRUNCATE TABLE {TABLES} RESTART IDENTITY CASCADE")
+        await run_repository_tests(POSTGRES)
+    finally:
+        await db.disconnect()
@@ -0,0 +1,133 @@
+"""Tests for events submitted by organizers."""
+
+import json
+
+import pytest
+
+from api import submissions
+from api.public import RateLimiter
+from api.server import HTTPError, HTTPServer, Request
+from common.db.memory import MEMORY
+
+EVENT = {
+    "link": "https://club.example/events/jazz",
+    "content": "Джаз в клубе 1 июня в 20:00. Вход свободный.",
+    "title": "Джаз в клубе",
+    "contact": "org@club.example",
+}
+
+
+def submit(body, token: str = "", client: str = "1.2.3.4") -> Request:
+    headers = {"x-submission-token": token} if token else {}
+    return Request(
+        method="POST",
+        path="/v1/submissions",
+        headers=headers,
+        body=json.dumps(body).encode(),
+        client=client,
+    )
+
+
+@pytest.fixture
+def server(monkeypatch):
+    monkeypatch.setattr(submissions.api_settings, "submission_tokens", "club:s3cret, museum:m")
+    monkeypatch.setattr(submissions.api_settings, "captcha_secret", "captcha-secret")
+    monkeypatch.setattr(submissions, "limiter", RateLimiter(2, window_seconds=3600))
+    monkeypatch.delenv("RULES_FILE", raising=False)
+    server = HTTPServer()
+    submissions.register_public(server)
+    submissions.register(server)
+    return server
+
+
+@pytest.mark.asyncio
+async def test_partner_submission_is_moderated(memory_storage, server):
+    response = await server.dispatch(submit(EVENT, token="s3cret"))
+    assert response.status == 202
+    submission_id = json.loads(response.body)["id"]
+
+    # Nothing is posted before a moderator approves
+    assert await MEMORY.posts.get_by_link(EVENT["link"]) is None
+    with pytest.raises(HTTPError) as error:
+        await server.dispatch(submit(EVENT, token="s3cret"))
+    assert error.value.status == 409
+
+    response = await server.dispatch(Request(method="GET", path="/submissions"))
+    queue = json.loads(response.body)
+    assert [(s["id"], s["partner"], s["contact"]) for s in queue] == [
+        (submission_id, "club", "org@club.example")
+    ]
+
+    path = f"/submissions/{submission_id}/approve"
+    response = await server.dispatch(Request(method="POST", path=path))
+    assert response.status == 201
+    post = await MEMORY.posts.get_by_link(EVENT["link"])
+    assert post.tags == ["submitted"]
+    assert post.title == "Джаз в клубе"
+    assert (await MEMORY.submissions.get_by_id(submission_id)).status == "approved"
+
+    with pytest.raises(HTTPError) as error:
+        await server.dispatch(Request(method="POST", path=path))
+    assert error.value.status == 409
+
+
+@pytest.mark.asyncio
+async def test_reject_submission(memory_storage, server):
+    response = await server.dispatch(submit(EVENT, token="m"))
+    submission_id = json.loads(response.body)["id"]
+
+    body = json.dumps({"note": "Not in the city"}).encode()
+    path = f"/submissions/{submission_id}/reject"
+    response = await server.dispatch(Request(method="POST", path=path, body=body))
+    assert json.loads(response.body) == {"id": submission_id, "status": "rejected"}
+    assert (await MEMORY.submissions.get_by_id(submission_id)).note == "Not in the city"
+    assert await MEMORY.posts.get_by_link(EVENT["link"]) is None
+
+    request = Request(method="GET", path="/submissions", query={"status": "rejected"})
+    assert [s["id"] for s in json.loads((await server.dispatch(request)).body)] == [submission_id]
+    response = await server.dispatch(Request(method="GET", path="/submissions"))
+    assert json.loads(response.body) == []
+
+    for path in ("/submissions/x/approve", "/submissions/99/approve", "/submissions/1/delete"):
+        with pytest.raises(HTTPError) as error:
+            await server.dispatch(Request(method="POST", path=path))
+        assert error.value.status == 404
+
+
+@pytest.mark.asyncio
+async def test_submissions_need_token_or_captcha(memory_storage, server, monkeypatch):
+    checked = []
+
+    async def verify_captcha(response, client):
+        checked.append((response, client))
+        return response == "solved"
+
+    monkeypatch.setattr(submissions, "verify_captcha", verify_captcha)
+
+    for request in (
+        submit(EVENT),
+        submit({**EVENT, "captcha": "wrong"}),
+        submit(EVENT, token="guessed"),
+    ):
+        with pytest.raises(HTTPError) as error:
+            await server.dispatch(request)
+        assert error.value.status == 403
+
+    response = await server.dispatch(submit({**EVENT, "captcha": "solved"}, client="5.6.7.8"))
+    assert response.status == 202
+    assert checked == [("wrong", "1.2.3.4"), ("solved", "5.6.7.8")]
+    assert (await MEMORY.submissions.get_by_status())[0].partner is None
+
+
+@pytest.mark.asyncio
+async def test_submissions_are_rate_limited_and_validated(memory_storage, server):
+    for body in ({**EVENT, "link": "club/jazz"}, {**EVENT, "content": " "}):
+        with pytest.raises(HTTPError) as error:
+            await server.dispatch(submit(body, token="s3cret"))
+        assert error.value.status == 400
+
+    response = await server.dispatch(submit(EVENT, token="s3cret"))
+    assert response.status == 429
+    assert response.headers["Retry-After"] == "3600"
+    # Other partners have their own limit
+    assert (await server.dispatch(submit(EVENT, token="m"))).status == 202
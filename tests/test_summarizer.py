@@ -0,0 +1,170 @@
+"""Tests for event summaries and titles."""
+
+from types import SimpleNamespace
+
+import pytest
+
+from summarizer.summarize import LLMSummarizer, rule_summary, sentences
+from summarizer.titles import generate_title
+
+POST = """🎸 Концерт группы «Кино» в клубе Podval
+Начало в 19:00, вход свободный. Подробнее: https://example.com/kino
+#концерт #рязань"""
+
+
+def test_sentences_are_cleaned():
+    """Test that links, hashtags and leading emoji are removed."""
+    assert sentences(POST) == [
+        "Концерт группы «Кино» в клубе Podval",
+        "Начало в 19:00, вход свободный.",
+        "Подробнее:",
+    ]
+
+
+def test_rule_summary_uses_first_sentences():
+    """Test that the title and first detail become the summary."""
+    assert rule_summary(POST) == (
+        "Концерт группы «Кино» в клубе Podval — Начало в 19:00, вход свободный."
+    )
+
+    lecture = "Лекция о современном искусстве в библиотеке Есенина\nВход 300 ₽"
+    assert rule_summary(lecture) == "Лекция о современном искусстве в библиотеке Есенина."
+
+
+def test_rule_summary_joins_short_titles_and_truncates():
+    """Test joining a short title with the next detail and the length limit."""
+    assert rule_summary("🎭 Спектакль\nСегодня в 19:00 в драмтеатре") == (
+        "Спектакль — Сегодня в 19:00 в драмтеатре."
+    )
+
+    summary = rule_summary("очень " * 100, max_length=50)
+    assert len(summary) <= 50
+    assert summary.endswith("…")
+    assert rule_summary("#реклама https://example.com") == ""
+
+
+class FakeCompletions:
+    def __init__(self, reply=None, error=None, tokens=0):
+        self.reply = reply
+        self.error = error
+        self.tokens = tokens
+        self.requests = []
+
+    async def create(self, **kwargs):
+        self.requests.append(kwargs)
+        if self.error:
+            raise self.error
+        reply = self.reply(kwargs) if callable(self.reply) else self.reply
+        message = SimpleNamespace(content=reply)
+        return SimpleNamespace(
+            choices=[SimpleNamespace(message=message)],
+            usage=SimpleNamespace(total_tokens=self.tokens),
+        )
+
+
+def fake_client(**kwargs):
+    return SimpleNamespace(chat=SimpleNamespace(completions=FakeCompletions(**kwargs)))
+
+
+@pytest.mark.asyncio
+async def test_llm_summary_and_fallback():
+    """Test LLM reply cleanup and falling back to the rules on errors."""
+    llm = LLMSummarizer(fake_client(reply='"Концерт «Кино» в Podval в 19:00."\n'), "model")
+    assert await llm.summarize(POST) == "Концерт «Кино» в Podval в 19:00."
+
+    failing = LLMSummarizer(fake_client(error=RuntimeError("rate limited")), "model")
+    assert await failing.summarize(POST) == rule_summary(POST)
+
+
+@pytest.mark.asyncio
+async def test_llm_batches_cache_and_budgets(memory_storage):
+    """Test batched requests, cached results and per-tenant daily token budgets."""
+    import json
+    from datetime import date, datetime
+
+    from common.clock import FakeClock, set_clock
+    from common.db.memory import MEMORY
+    from common.db.models import RSSPost
+    from summarizer.llm_batch import TokenBudgets, parse_budgets, summarize_with_llm
+
+    def numbered(request):
+        posts = json.loads(request["messages"][1]["content"].split("\n\n", 1)[1])
+        return "```json\n" + json.dumps([f"Итог: {post}" for post in posts]) + "\n```"
+
+    previous = set_clock(FakeClock(datetime(2026, 2, 18, 12, 0)))
+    try:
+        client = fake_client(reply=numbered, tokens=100)
+        llm = LLMSummarizer(client, "model")
+        posts = [RSSPost(link=f"https://t.me/jazz/{n}", content=f"Концерт {n}") for n in range(5)]
+        # A repost of the same text is summarized once
+        posts.append(RSSPost(link="https://t.me/rock/1", content="Концерт 0"))
+        posts.append(RSSPost(link="https://t.me/rock/2", content="Рок-фестиваль"))
+
+        budgets = TokenBudgets(default=0, tenants=parse_budgets("jazz=150"))
+        summaries = await summarize_with_llm(llm, posts, budgets, batch_size=2)
+        # jazz: two batches before its budget ran out; rock: one batch of its new text
+        assert len(client.chat.completions.requests) == 3
+        assert summaries == {
+            "https://t.me/jazz/0": "Итог: Концерт 0",
+            "https://t.me/jazz/1": "Итог: Концерт 1",
+            "https://t.me/jazz/2": "Итог: Концерт 2",
+            "https://t.me/jazz/3": "Итог: Концерт 3",
+            "https://t.me/rock/1": "Итог: Концерт 0",
+            "https://t.me/rock/2": "Итог: Рок-фестиваль",
+        }
+        assert budgets.remaining("jazz") == 0 and budgets.remaining("rock") is None
+        usage = await MEMORY.llm_usage.get_day(date(2026, 2, 18))
+        assert usage == {"jazz": 200, "rock": 100}
+
+        # Cached results cost nothing, even once the budget is used up
+        again = await summarize_with_llm(llm, posts[:4], budgets, batch_size=2)
+        assert len(again) == 4 and len(client.chat.completions.requests) == 3
+
+        # A reply with too few summaries leaves the batch to the rules, its tokens spent
+        broken = LLMSummarizer(fake_client(reply='["Только один"]', tokens=40), "model")
+        budgets = TokenBudgets()
+        new = [RSSPost(link=f"https://t.me/rock/{n}", content=f"Рок {n}") for n in (3, 4)]
+        assert await summarize_with_llm(broken, new, budgets, batch_size=2) == {}
+        assert budgets.used == {"rock": 40}
+
+        with pytest.raises(ValueError):
+            parse_budgets("jazz=lots")
+    finally:
+        set_clock(previous)
+
+
+def test_generate_title():
+    """Test titles from the first sentence, clause cuts and hashtag keywords."""
+    assert generate_title(POST) == "Концерт группы «Кино» в клубе Podval"
+    assert generate_title("Подробнее:\nбольшой фестиваль уличной еды.") == (
+        "Большой фестиваль уличной еды"
+    )
+
+    long_sentence = "Фестиваль уличной еды и музыки — три дня, сорок фудтраков и две сцены в парке"
+    assert generate_title(long_sentence, max_length=60) == "Фестиваль уличной еды и музыки"
+    assert len(generate_title("слово " * 50, max_length=40)) <= 40
+
+    assert generate_title("📸 https://example.com #Концерт #джаз #концерт") == "Концерт, джаз"
+    assert generate_title("") == ""
+
+
+def test_local_llm_client(monkeypatch):
+    """Test that LLM_BASE_URL points the LLM client at a local server, even without a key."""
+    from summarizer.__main__ import create_llm_client
+    from summarizer.config import summarizer_settings
+
+    monkeypatch.setattr(summarizer_settings, "openai_api_key", "")
+    monkeypatch.setattr(summarizer_settings, "llm_base_url", "")
+    assert create_llm_client() is None
+    assert not summarizer_settings.has_llm()
+
+    monkeypatch.setattr(summarizer_settings, "llm_base_url", "http://localhost:11434/v1")
+    client = create_llm_client()
+    assert str(client.base_url).rstrip("/") == "http://localhost:11434/v1"
+    assert client.timeout == summarizer_settings.llm_timeout
+    monkeypatch.setattr(summarizer_settings, "translation_provider", "openai")
+    assert summarizer_settings.validate()
+
+    monkeypatch.setattr(summarizer_settings, "llm_base_url", "localhost:11434")
+    with pytest.raises(ValueError, match="LLM_BASE_URL"):
+        summarizer_settings.validate()
@@ -0,0 +1,136 @@
+"""Tests for translations and the iCalendar export."""
+
+from datetime import datetime
+from types import SimpleNamespace
+
+import pytest
+
+from common.db.models import RSSPost
+from common.db.repository import TranslationRepository
+from common.ics import fold_line, render_calendar
+from common.translations import (
+    OpenAITranslator,
+    TranslationError,
+    Translator,
+    load_translations,
+    parse_languages,
+    text_hash,
+    translate_cached,
+)
+
+
+class FakeCompletions:
+    def __init__(self, reply):
+        self.reply = reply
+
+    async def create(self, **kwargs):
+        message = SimpleNamespace(content=self.reply)
+        return SimpleNamespace(choices=[SimpleNamespace(message=message)])
+
+
+def fake_client(reply):
+    return SimpleNamespace(chat=SimpleNamespace(completions=FakeCompletions(reply)))
+
+
+class UpperTranslator(Translator):
+    name = "upper"
+
+    def __init__(self):
+        self.calls = []
+
+    async def translate(self, texts, language):
+        self.calls.append(list(texts))
+        return [text.upper() for text in texts]
+
+
+@pytest.fixture
+def cache(monkeypatch):
+    """In-memory translations table."""
+    rows = {}
+
+    async def get_many(source_hashes, language):
+        return {h: rows[(h, language)] for h in source_hashes if (h, language) in rows}
+
+    async def save(source_hash, language, text, provider):
+        rows[(source_hash, language)] = text
+
+    monkeypatch.setattr(TranslationRepository, "get_many", get_many)
+    monkeypatch.setattr(TranslationRepository, "save", save)
+    return rows
+
+
+def test_parse_languages():
+    """Test parsing comma-separated language codes."""
+    assert parse_languages(" en, DE ,") == ["en", "de"]
+    assert parse_languages("") == []
+
+
+@pytest.mark.asyncio
+async def test_openai_translator_parses_json_reply():
+    """Test that a fenced JSON array reply is accepted and length is checked."""
+    translator = OpenAITranslator(fake_client('```json\n["Jazz concert", "Free entry"]\n```'), "m")
+    assert await translator.translate(["Джазовый концерт", "Вход свободный"], "en") == [
+        "Jazz concert",
+        "Free entry",
+    ]
+
+    with pytest.raises(TranslationError):
+        await translator.translate(["Один", "Два", "Три"], "en")
+
+
+@pytest.mark.asyncio
+async def test_translate_cached_skips_cached_texts(cache):
+    """Test that each text is translated once per language."""
+    translator = UpperTranslator()
+    assert await translate_cached(translator, ["концерт", "", "концерт", "лекция"], "en") == 2
+    assert await translate_cached(translator, ["концерт", "выставка"], "en") == 1
+    assert translator.calls == [["концерт", "лекция"], ["выставка"]]
+    assert cache[(text_hash("выставка"), "en")] == "ВЫСТАВКА"
+
+
+@pytest.mark.asyncio
+async def test_load_translations_per_post(cache):
+    """Test looking up translated titles and summaries of posts."""
+    cache[(text_hash("Концерт"), "en")] = "Concert"
+    posts = [
+        RSSPost(link="https://t.me/a/1", content="", title="Концерт", summary="Не переведено."),
+        RSSPost(link="https://t.me/a/2", content="", title="Лекция"),
+    ]
+    translations = await load_translations(posts, ["en"])
+    assert translations == {
+        "https://t.me/a/1": {"en": {"title": "Concert", "summary": ""}},
+        "https://t.me/a/2": {},
+    }
+
+
+def test_fold_line_keeps_utf8_characters():
+    """Test folding long lines without splitting multi-byte characters."""
+    folded = fold_line("DESCRIPTION:" + "концерт " * 20)
+    parts = folded.split("\r\n ")
+    assert len(parts) > 1
+    assert all(len(part.encode()) <= 75 for part in parts)
+    assert "".join(parts) == "DESCRIPTION:" + "концерт " * 20
+
+
+def test_render_calendar_with_translation():
+    """Test VEVENT fields, escaping and the translated summary."""
+    post = RSSPost(
+        link="https://t.me/mediarzn/1",
+        content="Концерт; вход свободный",
+        pub_date=datetime(2026, 1, 10, 19, 0),
+        title="Концерт",
+        tags=["music"],
+    )
+    translations = {post.link: {"en": {"title": "Concert", "summary": "Free entry."}}}
+
+    original = render_calendar([post], "Афиша")
+    assert original.startswith("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n")
+    assert "UID:mediarzn-1@event-platform\r\n" in original
+    assert "DTSTART:20260110T190000\r\nDTEND:20260110T200000\r\n" in original
+    assert "SUMMARY:Концерт\r\n" in original
+    assert "DESCRIPTION:Концерт\\; вход свободный\r\n" in original
+
+    english = render_calendar([post], "Афиша", translations, "en")
+    assert "SUMMARY;LANGUAGE=en:Concert\r\n" in english
+    assert "DESCRIPTION:Free entry.\\n\\nКонцерт\\; вход свободный\r\n" in english
+    assert english.endswith("END:VCALENDAR\r\n")
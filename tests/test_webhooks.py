@@ -0,0 +1,96 @@
+"""Tests for posts pushed by external collectors."""
+
+import json
+
+import pytest
+
+from api import webhooks
+from api.__main__ import require_token
+from api.server import HTTPError, HTTPServer, Request
+from common.db.memory import MEMORY
+
+POSTS = [
+    {
+        "link": "https://vk.com/wall-1_10",
+        "content": "<p>Концерт 15 марта в 19:00</p>",
+        "pub_date": "2026-03-01T10:00:00Z",
+    },
+    {"link": "https://vk.com/wall-1_11", "content": "  "},
+]
+
+
+def push(source: str, body, token: str = "vk-token") -> Request:
+    return Request(
+        method="POST",
+        path=f"/webhooks/{source}",
+        headers={"x-webhook-token": token},
+        body=json.dumps(body).encode(),
+    )
+
+
+@pytest.fixture
+def server(monkeypatch):
+    monkeypatch.setattr(webhooks.api_settings, "webhook_tokens", "vk_city:vk-token,tg:tg-token")
+    monkeypatch.setattr(webhooks.api_settings, "webhook_max_items", 3)
+    monkeypatch.delenv("RULES_FILE", raising=False)
+    server = HTTPServer()
+    webhooks.register(server)
+    return server
+
+
+@pytest.mark.asyncio
+async def test_pushed_posts_are_saved(memory_storage, server):
+    response = await server.dispatch(push("vk_city", POSTS))
+    data = json.loads(response.body)
+    assert data["counts"] == {"new": 1, "empty": 1}
+    assert [item["outcome"] for item in data["items"]] == ["new", "empty"]
+
+    post = await MEMORY.posts.get_by_link("https://vk.com/wall-1_10")
+    assert post.content == "Концерт 15 марта в 19:00"
+    assert post.event_start.day == 15
+
+    # A single object works too, and the push shows up as a poll run
+    response = await server.dispatch(push("vk_city", POSTS[0]))
+    assert json.loads(response.body)["counts"] == {"duplicate": 1}
+    runs = await MEMORY.poll_runs.get_recent()
+    assert [run.counts for run in runs] == [{"duplicate": 1}, {"new": 1, "empty": 1}]
+
+
+@pytest.mark.asyncio
+async def test_tokens_are_per_source(memory_storage, server):
+    for request in (
+        push("vk_city", POSTS, token="tg-token"),
+        push("vk_city", POSTS, token="wrong"),
+        push("vk_city", POSTS, token=""),
+        push("", POSTS),
+    ):
+        with pytest.raises(HTTPError) as error:
+            await server.dispatch(request)
+        assert error.value.status == 401
+    assert await MEMORY.posts.get_all() == []
+
+
+@pytest.mark.asyncio
+async def test_invalid_pushes_are_rejected(memory_storage, server):
+    for body, status in (
+        ([], 400),
+        ([{"content": "Без ссылки"}], 400),
+        ([{"link": "vk.com/wall-1_12", "content": "x"}], 400),
+        ([{"link": "https://vk.com/wall-1_12", "content": ["x"]}], 400),
+        ([{"link": "https://vk.com/wall-1_12", "content": "x", "media_urls": "a.jpg"}], 400),
+        ([POSTS[0]] * 4, 413),
+    ):
+        with pytest.raises(HTTPError) as error:
+            await server.dispatch(push("vk_city", body))
+        assert error.value.status == status
+    assert await MEMORY.posts.get_all() == []
+
+
+def test_webhooks_skip_the_admin_token(monkeypatch):
+    monkeypatch.setattr(webhooks.api_settings, "api_token", "admin")
+    monkeypatch.setattr(webhooks.api_settings, "webhook_tokens", "vk_city:vk-token")
+    assert require_token(push("vk_city", POSTS)) is None
+    assert require_token(Request(method="GET", path="/poll-runs")).status == 401
+
+    monkeypatch.setattr(webhooks.api_settings, "webhook_tokens", "")
+    assert require_token(push("vk_city", POSTS)).status == 401